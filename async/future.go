@@ -0,0 +1,172 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// ErrTimeout 等待超时
+var ErrTimeout = errors.New("async: await timeout")
+
+// PanicError 任务panic时返回的错误
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("async: panic: %v", e.Value)
+}
+
+// Future 异步任务的结果, 任务结束后结果不再变化, 可以被多次、并发地等待
+type Future[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// Go 在新的goroutine中执行fn, 返回其结果的Future; fn中的panic会被转换为*PanicError
+func Go[T any](fn func() (T, error)) *Future[T] {
+	f := &Future[T]{done: make(chan struct{})}
+	go func() {
+		defer close(f.done)
+		defer func() {
+			if r := recover(); r != nil {
+				f.err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+		f.val, f.err = fn()
+	}()
+	return f
+}
+
+// GoCtx 同Go, fn接收ctx以便响应取消
+func GoCtx[T any](ctx context.Context, fn func(ctx context.Context) (T, error)) *Future[T] {
+	return Go(func() (T, error) { return fn(ctx) })
+}
+
+// Resolved 返回已完成的Future
+func Resolved[T any](val T, err error) *Future[T] {
+	f := &Future[T]{done: make(chan struct{}), val: val, err: err}
+	close(f.done)
+	return f
+}
+
+// Done 任务结束时关闭
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Await 等待任务结束或ctx结束; ctx结束时任务本身不会被中断
+func (f *Future[T]) Await(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.val, f.err
+	default:
+	}
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// AwaitTimeout 最多等待d, 超时返回ErrTimeout
+func (f *Future[T]) AwaitTimeout(d time.Duration) (T, error) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-timer.C:
+		var zero T
+		return zero, ErrTimeout
+	}
+}
+
+// AwaitAll 等待全部任务完成, 结果顺序与futures一致; 任一任务失败时立即返回该错误
+func AwaitAll[T any](ctx context.Context, futures ...*Future[T]) ([]T, error) {
+	results := make([]T, len(futures))
+	completed, stop := completions(futures)
+	defer stop()
+	// 按完成顺序处理, 避免一个慢任务掩盖其它任务的失败
+	for range futures {
+		i, err := next(ctx, completed)
+		if err != nil {
+			return nil, err
+		}
+		f := futures[i]
+		if f.err != nil {
+			return nil, f.err
+		}
+		results[i] = f.val
+	}
+	return results, nil
+}
+
+// AwaitAny 返回第一个成功的任务结果及其下标; 全部失败时返回最后一个错误
+func AwaitAny[T any](ctx context.Context, futures ...*Future[T]) (T, int, error) {
+	var zero T
+	if len(futures) == 0 {
+		return zero, -1, errors.New("async: no futures")
+	}
+	completed, stop := completions(futures)
+	defer stop()
+	var lastErr error
+	for range futures {
+		i, err := next(ctx, completed)
+		if err != nil {
+			return zero, -1, err
+		}
+		f := futures[i]
+		if f.err == nil {
+			return f.val, i, nil
+		}
+		lastErr = f.err
+	}
+	return zero, -1, lastErr
+}
+
+// completions 任务完成时向返回的channel发送其下标, 每个未完成的任务只启动一个goroutine;
+// 调用stop后这些goroutine立即退出, 不必等任务完成
+func completions[T any](futures []*Future[T]) (<-chan int, func()) {
+	// 容量足够容纳所有下标, 发送不会阻塞
+	ch := make(chan int, len(futures))
+	stop := make(chan struct{})
+	for i, f := range futures {
+		select {
+		case <-f.done:
+			ch <- i
+			continue
+		default:
+		}
+		go func(i int, done <-chan struct{}) {
+			select {
+			case <-done:
+				ch <- i
+			case <-stop:
+			}
+		}(i, f.done)
+	}
+	return ch, func() { close(stop) }
+}
+
+// next 返回下一个完成的任务下标, 已有完成的任务时优先于ctx结束
+func next(ctx context.Context, completed <-chan int) (int, error) {
+	select {
+	case i := <-completed:
+		return i, nil
+	default:
+	}
+	select {
+	case i := <-completed:
+		return i, nil
+	case <-ctx.Done():
+		return -1, ctx.Err()
+	}
+}
@@ -0,0 +1,112 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func delayed(d time.Duration, v int, err error) *Future[int] {
+	return Go(func() (int, error) {
+		time.Sleep(d)
+		return v, err
+	})
+}
+
+func TestAwait(t *testing.T) {
+	errBoom := errors.New("boom")
+	tests := []struct {
+		name    string
+		future  *Future[int]
+		timeout time.Duration
+		want    int
+		wantErr error
+	}{
+		{name: "value", future: delayed(0, 1, nil), timeout: time.Second, want: 1},
+		{name: "error", future: delayed(0, 0, errBoom), timeout: time.Second, wantErr: errBoom},
+		{name: "timeout", future: delayed(time.Second, 1, nil), timeout: 10 * time.Millisecond, wantErr: context.DeadlineExceeded},
+		{name: "resolved", future: Resolved(7, nil), timeout: time.Second, want: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			got, err := tt.future.Await(ctx)
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Await() = %d, %v, want %d, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPanic(t *testing.T) {
+	f := Go(func() (string, error) { panic("oops") })
+	_, err := f.AwaitTimeout(time.Second)
+	var pe *PanicError
+	if !errors.As(err, &pe) || pe.Value != "oops" {
+		t.Errorf("AwaitTimeout() error = %v, want PanicError", err)
+	}
+	if _, err := delayed(time.Second, 1, nil).AwaitTimeout(time.Millisecond); err != ErrTimeout {
+		t.Errorf("AwaitTimeout() error = %v, want ErrTimeout", err)
+	}
+}
+
+func TestAwaitAll(t *testing.T) {
+	ctx := context.Background()
+	got, err := AwaitAll(ctx, delayed(20*time.Millisecond, 1, nil), delayed(0, 2, nil), delayed(10*time.Millisecond, 3, nil))
+	if err != nil || len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Errorf("AwaitAll() = %v, %v", got, err)
+	}
+
+	errBoom := errors.New("boom")
+	start := time.Now()
+	_, err = AwaitAll(ctx, delayed(time.Second, 1, nil), delayed(10*time.Millisecond, 0, errBoom))
+	if err != errBoom || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("AwaitAll() error = %v after %v, want fast %v", err, time.Since(start), errBoom)
+	}
+}
+
+func TestAwaitAny(t *testing.T) {
+	ctx := context.Background()
+	errBoom := errors.New("boom")
+
+	got, idx, err := AwaitAny(ctx, delayed(time.Second, 1, nil), delayed(0, 0, errBoom), delayed(10*time.Millisecond, 3, nil))
+	if err != nil || got != 3 || idx != 2 {
+		t.Errorf("AwaitAny() = %d, %d, %v, want 3, 2, nil", got, idx, err)
+	}
+
+	_, idx, err = AwaitAny(ctx, delayed(0, 0, errBoom), delayed(5*time.Millisecond, 0, errBoom))
+	if err != errBoom || idx != -1 {
+		t.Errorf("AwaitAny() all failed = %d, %v", idx, err)
+	}
+}
+
+// TestAwaitWatchers 等待的goroutine在返回后退出, 不随任务数量和完成次数累积
+func TestAwaitWatchers(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	base := runtime.NumGoroutine()
+	futures := make([]*Future[int], 100)
+	for i := range futures {
+		futures[i] = Go(func() (int, error) {
+			<-block
+			return 0, nil
+		})
+	}
+	// 逐个失败的任务会让AwaitAny多次进入等待
+	for i := 0; i < 10; i++ {
+		futures = append(futures, delayed(time.Duration(i)*time.Millisecond, 0, errors.New("boom")))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, _, err := AwaitAny(ctx, futures...); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("AwaitAny() error = %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := runtime.NumGoroutine() - base; n > 100+5 {
+		t.Errorf("goroutines after AwaitAny = %d, want about 100", n)
+	}
+}
@@ -0,0 +1,165 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/ChangSZ/golib/copy"
+	"github.com/ChangSZ/golib/mask"
+	"github.com/ChangSZ/golib/meta"
+)
+
+// TagName 审计标签, `audit:"-"`的字段不记录
+const TagName = "audit"
+
+// Action 操作类型
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change 一个字段的变化, 带mask标签的字段已脱敏
+type Change struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Entry 一条审计记录: 谁在什么时候对哪个资源做了什么改动
+type Entry struct {
+	ID         string    `json:"id"`
+	Time       time.Time `json:"time"`
+	Actor      string    `json:"actor"`
+	Action     Action    `json:"action"`
+	Resource   string    `json:"resource"`
+	ResourceID string    `json:"resourceId"`
+	Changes    []Change  `json:"changes"`
+	RequestID  string    `json:"requestId,omitempty"`
+	TenantID   string    `json:"tenantId,omitempty"`
+}
+
+// Sink 审计记录的写入目标
+type Sink interface {
+	Write(ctx context.Context, e *Entry) error
+}
+
+// SinkFunc 函数形式的Sink
+type SinkFunc func(ctx context.Context, e *Entry) error
+
+func (f SinkFunc) Write(ctx context.Context, e *Entry) error {
+	return f(ctx, e)
+}
+
+// Option Recorder的可选参数
+type Option func(*Recorder)
+
+// WithActor 自定义从ctx获取操作人的方式, 默认为meta.UserID
+func WithActor(fn func(ctx context.Context) string) Option {
+	return func(r *Recorder) {
+		r.actor = fn
+	}
+}
+
+// WithIgnoreFields 不记录的字段, 规则同copy.WithIgnoreFields, 常用于UpdatedAt、Version等
+func WithIgnoreFields(fields ...string) Option {
+	return func(r *Recorder) {
+		r.ignore = append(r.ignore, fields...)
+	}
+}
+
+// WithClock 替换时钟, 用于测试
+func WithClock(now func() time.Time) Option {
+	return func(r *Recorder) {
+		r.now = now
+	}
+}
+
+// Recorder 比较实体修改前后的值生成审计记录并写入Sink
+//
+//	rec := audit.New(audit.NewDBSink(db))
+//	err := rec.Record(ctx, audit.ActionUpdate, "user", "42", oldUser, newUser)
+type Recorder struct {
+	sink   Sink
+	actor  func(ctx context.Context) string
+	ignore []string
+	now    func() time.Time
+}
+
+// New new a Recorder.
+func New(sink Sink, opts ...Option) *Recorder {
+	r := &Recorder{
+		sink:  sink,
+		actor: meta.UserID,
+		now:   time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Record 比较before和after并写入一条审计记录, 返回写入的记录
+//
+// - 创建时before传nil, 删除时after传nil
+// - 更新时没有字段变化则不写入, 返回nil
+func (r *Recorder) Record(ctx context.Context, action Action, resource, resourceID string, before, after interface{}) (*Entry, error) {
+	changes, err := r.Changes(before, after)
+	if err != nil {
+		return nil, err
+	}
+	if action == ActionUpdate && len(changes) == 0 {
+		return nil, nil
+	}
+	m := meta.FromContext(ctx)
+	e := &Entry{
+		ID:         meta.NewRequestID(),
+		Time:       r.now(),
+		Actor:      r.actor(ctx),
+		Action:     action,
+		Resource:   resource,
+		ResourceID: resourceID,
+		Changes:    changes,
+		RequestID:  m.RequestID,
+		TenantID:   m.TenantID,
+	}
+	if err := r.sink.Write(ctx, e); err != nil {
+		return nil, fmt.Errorf("audit: write %s %s/%s: %w", action, resource, resourceID, err)
+	}
+	return e, nil
+}
+
+// Changes 计算脱敏后的字段变化, 不写入Sink
+func (r *Recorder) Changes(before, after interface{}) ([]Change, error) {
+	diff, err := copy.Diff(before, after, r.ignore...)
+	if err != nil {
+		return nil, fmt.Errorf("audit: %w", err)
+	}
+	changes := make([]Change, 0, len(diff))
+	for _, d := range diff {
+		if d.Field.Tag.Get(TagName) == "-" {
+			continue
+		}
+		c := Change{Field: d.Path, Before: d.Before, After: d.After}
+		if fn, ok := mask.FieldFunc(d.Field); ok {
+			c.Before, c.After = redact(fn, c.Before), redact(fn, c.After)
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// redact 字符串按mask规则脱敏, 零值保持不变以区分"设置"和"清空", 其他类型整体替换为占位符
+func redact(fn mask.Func, v interface{}) interface{} {
+	if v == nil || reflect.ValueOf(v).IsZero() {
+		return v
+	}
+	if s, ok := v.(string); ok {
+		return fn(s)
+	}
+	return mask.Placeholder
+}
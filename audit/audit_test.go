@@ -0,0 +1,172 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ChangSZ/golib/meta"
+	"github.com/ChangSZ/golib/queue"
+)
+
+type profile struct {
+	Avatar string
+}
+
+type user struct {
+	ID        int
+	Name      string
+	Phone     string `mask:"phone"`
+	Password  string `audit:"-"`
+	Token     []byte `mask:"secret"`
+	Profile   profile
+	UpdatedAt time.Time
+}
+
+func TestRecord(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	old := &user{ID: 1, Name: "jack", Phone: "13812345678", Password: "a", Token: []byte("t1"), UpdatedAt: now}
+
+	tests := []struct {
+		name   string
+		action Action
+		before interface{}
+		after  interface{}
+		want   []Change
+	}{
+		{
+			name:   "update",
+			action: ActionUpdate,
+			before: old,
+			after: &user{ID: 1, Name: "rose", Phone: "13887654321", Password: "b", Token: []byte("t2"),
+				Profile: profile{Avatar: "a.png"}, UpdatedAt: now.Add(time.Hour)},
+			want: []Change{
+				{Field: "Name", Before: "jack", After: "rose"},
+				{Field: "Phone", Before: "138****5678", After: "138****4321"},
+				{Field: "Token", Before: "******", After: "******"},
+				{Field: "Profile.Avatar", Before: "", After: "a.png"},
+			},
+		},
+		{
+			name:   "create",
+			action: ActionCreate,
+			before: nil,
+			after:  &user{ID: 2, Phone: "13812345678"},
+			want: []Change{
+				{Field: "ID", Before: 0, After: 2},
+				{Field: "Phone", Before: "", After: "138****5678"},
+			},
+		},
+		{
+			name:   "delete",
+			action: ActionDelete,
+			before: &user{ID: 3},
+			after:  nil,
+			want:   []Change{{Field: "ID", Before: 3, After: 0}},
+		},
+		{
+			name:   "no change",
+			action: ActionUpdate,
+			before: old,
+			after:  &user{ID: 1, Name: "jack", Phone: "13812345678", Password: "changed", Token: []byte("t1"), UpdatedAt: now},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := NewMemorySink()
+			rec := New(sink, WithIgnoreFields("UpdatedAt"), WithClock(func() time.Time { return now }))
+			ctx := meta.NewContext(context.Background(), meta.Meta{UserID: "u1", RequestID: "r1", TenantID: "t1"})
+
+			e, err := rec.Record(ctx, tt.action, "user", "1", tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("Record() error = %v", err)
+			}
+			if tt.want == nil {
+				if e != nil || len(sink.Entries()) != 0 {
+					t.Errorf("Record() = %+v, want nothing written", e)
+				}
+				return
+			}
+			got := sink.Entries()
+			if len(got) != 1 || got[0].Actor != "u1" || got[0].RequestID != "r1" || got[0].TenantID != "t1" ||
+				got[0].Action != tt.action || !got[0].Time.Equal(now) || got[0].ID == "" {
+				t.Fatalf("Entries() = %+v", got)
+			}
+			if !reflect.DeepEqual(got[0].Changes, tt.want) {
+				t.Errorf("Changes = %+v, want %+v", got[0].Changes, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordError(t *testing.T) {
+	errDown := errors.New("down")
+	rec := New(SinkFunc(func(context.Context, *Entry) error { return errDown }))
+	if _, err := rec.Record(context.Background(), ActionCreate, "user", "1", nil, &user{ID: 1}); !errors.Is(err, errDown) {
+		t.Errorf("Record() error = %v, want %v", err, errDown)
+	}
+	if _, err := rec.Record(context.Background(), ActionUpdate, "user", "1", &user{}, &profile{}); err == nil {
+		t.Errorf("Record() want error for different types")
+	}
+}
+
+type execRecorder struct {
+	query string
+	args  []interface{}
+}
+
+func (r *execRecorder) ExecContext(_ context.Context, query string, args ...interface{}) (sql.Result, error) {
+	r.query, r.args = query, args
+	return nil, nil
+}
+
+func TestSinks(t *testing.T) {
+	ctx := context.Background()
+	e := &Entry{ID: "1", Actor: "u1", Action: ActionUpdate, Resource: "user", ResourceID: "42",
+		Changes: []Change{{Field: "Name", Before: "jack", After: "rose"}}}
+
+	t.Run("db", func(t *testing.T) {
+		db := &execRecorder{}
+		if err := NewDBSink(db, "").Write(ctx, e); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if len(db.args) != 9 || db.args[6] != `[{"field":"Name","before":"jack","after":"rose"}]` {
+			t.Errorf("ExecContext() args = %v", db.args)
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit", "audit.log")
+		s, err := OpenFileSink(path)
+		if err != nil {
+			t.Fatalf("OpenFileSink() error = %v", err)
+		}
+		_ = s.Write(ctx, e)
+		_ = s.Write(ctx, e)
+		if err := s.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		b, _ := os.ReadFile(path)
+		var got Entry
+		if err := json.Unmarshal(b[:len(b)/2], &got); err != nil || got.ResourceID != "42" {
+			t.Errorf("file content = %s, err = %v", b, err)
+		}
+	})
+
+	t.Run("queue", func(t *testing.T) {
+		q := queue.NewFakeQueue()
+		if err := NewQueueSink(q, queue.PublishOptions{}).Write(ctx, e); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		var got Entry
+		if msgs := q.Published(); len(msgs) != 1 || json.Unmarshal([]byte(msgs[0]), &got) != nil || got.Actor != "u1" {
+			t.Errorf("Published() = %v", msgs)
+		}
+	})
+}
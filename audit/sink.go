@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/ChangSZ/golib/file"
+	"github.com/ChangSZ/golib/ndjson"
+	"github.com/ChangSZ/golib/queue"
+)
+
+var (
+	_ Sink = (*DBSink)(nil)
+	_ Sink = (*FileSink)(nil)
+	_ Sink = (*QueueSink)(nil)
+	_ Sink = (*MemorySink)(nil)
+)
+
+// Execer *sql.DB、*sql.Tx、*sql.Conn都满足该接口
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// DBSink 写入数据库表, changes以JSON存储
+//
+// 建表示例(MySQL):
+//
+//	CREATE TABLE audit_log (
+//	    id          VARCHAR(32) PRIMARY KEY,
+//	    time        DATETIME(3) NOT NULL,
+//	    actor       VARCHAR(64) NOT NULL,
+//	    action      VARCHAR(16) NOT NULL,
+//	    resource    VARCHAR(64) NOT NULL,
+//	    resource_id VARCHAR(64) NOT NULL,
+//	    changes     JSON        NOT NULL,
+//	    request_id  VARCHAR(64) NOT NULL,
+//	    tenant_id   VARCHAR(64) NOT NULL,
+//	    KEY idx_resource (resource, resource_id)
+//	);
+type DBSink struct {
+	db    Execer
+	query string
+}
+
+// NewDBSink new a DBSink, table为空时使用audit_log; 在业务事务中记录时可以传入*sql.Tx
+func NewDBSink(db Execer, table string) *DBSink {
+	if table == "" {
+		table = "audit_log"
+	}
+	return &DBSink{
+		db: db,
+		query: "INSERT INTO " + table +
+			" (id, time, actor, action, resource, resource_id, changes, request_id, tenant_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+	}
+}
+
+func (s *DBSink) Write(ctx context.Context, e *Entry) error {
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, s.query,
+		e.ID, e.Time, e.Actor, string(e.Action), e.Resource, e.ResourceID, string(changes), e.RequestID, e.TenantID)
+	return err
+}
+
+// FileSink 以NDJSON追加写入文件, 每条记录写入后立即Flush
+type FileSink struct {
+	mu sync.Mutex
+	f  *os.File
+	w  *ndjson.Writer
+}
+
+// OpenFileSink 打开或创建文件
+func OpenFileSink(path string) (*FileSink, error) {
+	if err := file.MakeDirByFile(path); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	w, err := ndjson.NewWriter(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &FileSink{f: f, w: w}, nil
+}
+
+func (s *FileSink) Write(_ context.Context, e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.w.Encode(e); err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
+
+// Close 关闭文件
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.w.Flush(); err != nil {
+		_ = s.f.Close()
+		return err
+	}
+	return s.f.Close()
+}
+
+// QueueSink 以JSON发布到消息队列, 由下游统一落库或归档
+type QueueSink struct {
+	q    queue.Queue
+	opts queue.PublishOptions
+}
+
+// NewQueueSink new a QueueSink.
+func NewQueueSink(q queue.Queue, opts queue.PublishOptions) *QueueSink {
+	return &QueueSink{q: q, opts: opts}
+}
+
+func (s *QueueSink) Write(ctx context.Context, e *Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.q.ProduceWithCtx(ctx, string(b), s.opts)
+}
+
+// MemorySink 保存在内存中, 用于测试
+type MemorySink struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewMemorySink new a MemorySink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Write(_ context.Context, e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, *e)
+	return nil
+}
+
+// Entries 已写入的记录
+func (s *MemorySink) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Entry, len(s.entries))
+	copy(out, s.entries)
+	return out
+}
@@ -0,0 +1,144 @@
+package authz
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Authorizer 基于角色的权限判断, 可以并发使用, Update可以热更新策略
+//
+//	a, err := authz.New(policy)
+//	a.Evaluate("alice", "read", "order:123")
+type Authorizer struct {
+	mu       sync.RWMutex
+	perms    map[string][]pattern // 角色 -> 权限, 已展开继承
+	subjects map[string][]string
+}
+
+// New new a Authorizer.
+func New(p Policy) (*Authorizer, error) {
+	a := &Authorizer{}
+	if err := a.Update(p); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Update 替换策略, 策略无效时保留原策略并返回错误
+func (a *Authorizer) Update(p Policy) error {
+	perms, err := compile(p)
+	if err != nil {
+		return err
+	}
+	subjects := make(map[string][]string, len(p.Subjects))
+	for s, roles := range p.Subjects {
+		for _, r := range roles {
+			if _, ok := perms[r]; !ok {
+				return fmt.Errorf("authz: subject %s has unknown role %s", s, r)
+			}
+		}
+		subjects[s] = append([]string(nil), roles...)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.perms = perms
+	a.subjects = subjects
+	return nil
+}
+
+// compile 解析权限并展开角色继承, 检查未知角色和循环继承
+func compile(p Policy) (map[string][]pattern, error) {
+	for role, parents := range p.Inherits {
+		if _, ok := p.Roles[role]; !ok {
+			return nil, fmt.Errorf("authz: unknown role %s in inherits", role)
+		}
+		for _, parent := range parents {
+			if _, ok := p.Roles[parent]; !ok {
+				return nil, fmt.Errorf("authz: role %s inherits unknown role %s", role, parent)
+			}
+		}
+	}
+
+	perms := make(map[string][]pattern, len(p.Roles))
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(p.Roles))
+	var visit func(role string, path []string) error
+	visit = func(role string, path []string) error {
+		switch state[role] {
+		case visiting:
+			return fmt.Errorf("authz: cyclic inherits %s", strings.Join(append(path, role), " -> "))
+		case done:
+			return nil
+		}
+		state[role] = visiting
+		var list []pattern
+		for _, s := range p.Roles[role] {
+			pt, err := parsePattern(s)
+			if err != nil {
+				return fmt.Errorf("%w in role %s", err, role)
+			}
+			list = append(list, pt)
+		}
+		for _, parent := range p.Inherits[role] {
+			if err := visit(parent, append(path, role)); err != nil {
+				return err
+			}
+			list = append(list, perms[parent]...)
+		}
+		perms[role] = list
+		state[role] = done
+		return nil
+	}
+
+	roles := make([]string, 0, len(p.Roles))
+	for role := range p.Roles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles) // 出错时信息稳定
+	for _, role := range roles {
+		if err := visit(role, nil); err != nil {
+			return nil, err
+		}
+	}
+	return perms, nil
+}
+
+// Roles 策略中为subject配置的角色
+func (a *Authorizer) Roles(subject string) []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return append([]string(nil), a.subjects[subject]...)
+}
+
+// Evaluate subject是否可以对resource执行action, 角色来自策略中的subjects
+func (a *Authorizer) Evaluate(subject, action, resource string) bool {
+	a.mu.RLock()
+	roles := a.subjects[subject]
+	a.mu.RUnlock()
+	return a.EvaluateRoles(roles, action, resource)
+}
+
+// EvaluateRoles 拥有roles的主体是否可以对resource执行action, 用于角色来自令牌等外部来源的场景, 未知角色被忽略
+func (a *Authorizer) EvaluateRoles(roles []string, action, resource string) bool {
+	if action == "" || resource == "" {
+		return false
+	}
+	target := append(strings.Split(resource, ":"), action)
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, role := range roles {
+		for _, p := range a.perms[role] {
+			if p.match(target) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package authz
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	a, err := New(Policy{
+		Roles: map[string][]string{
+			"viewer": {"order:*:read"},
+			"editor": {"order:*:write", "report:daily:*"},
+			"admin":  {"*"},
+		},
+		Inherits: map[string][]string{"editor": {"viewer"}},
+		Subjects: map[string][]string{
+			"alice": {"editor"},
+			"bob":   {"viewer"},
+			"root":  {"admin"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		subject  string
+		action   string
+		resource string
+		want     bool
+	}{
+		{subject: "bob", action: "read", resource: "order:1", want: true},
+		{subject: "bob", action: "write", resource: "order:1", want: false},
+		{subject: "bob", action: "read", resource: "order", want: false},
+		{subject: "bob", action: "read", resource: "order:1:items", want: false},
+		{subject: "alice", action: "read", resource: "order:1", want: true},
+		{subject: "alice", action: "write", resource: "order:1", want: true},
+		{subject: "alice", action: "export", resource: "report:daily", want: true},
+		{subject: "alice", action: "export", resource: "report:weekly", want: false},
+		{subject: "root", action: "delete", resource: "user:1:profile", want: true},
+		{subject: "nobody", action: "read", resource: "order:1", want: false},
+		{subject: "root", action: "", resource: "order:1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subject+" "+tt.action+" "+tt.resource, func(t *testing.T) {
+			if got := a.Evaluate(tt.subject, tt.action, tt.resource); got != tt.want {
+				t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !a.EvaluateRoles([]string{"unknown", "viewer"}, "read", "order:9") {
+		t.Errorf("EvaluateRoles() = false, want true")
+	}
+}
+
+func TestInvalidPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr string
+	}{
+		{
+			name: "cycle",
+			policy: Policy{
+				Roles:    map[string][]string{"a": nil, "b": nil},
+				Inherits: map[string][]string{"a": {"b"}, "b": {"a"}},
+			},
+			wantErr: "cyclic",
+		},
+		{
+			name:    "unknown parent",
+			policy:  Policy{Roles: map[string][]string{"a": nil}, Inherits: map[string][]string{"a": {"x"}}},
+			wantErr: "unknown role x",
+		},
+		{
+			name:    "unknown subject role",
+			policy:  Policy{Roles: map[string][]string{"a": nil}, Subjects: map[string][]string{"u": {"x"}}},
+			wantErr: "unknown role x",
+		},
+		{
+			name:    "empty segment",
+			policy:  Policy{Roles: map[string][]string{"a": {"order::read"}}},
+			wantErr: "invalid permission",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.policy)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("New() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.yaml")
+	content := "roles:\n  viewer: [\"order:*:read\"]\nsubjects:\n  bob: [viewer]\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatalf("LoadPolicy() error = %v", err)
+	}
+	a, err := New(p)
+	if err != nil || !a.Evaluate("bob", "read", "order:1") {
+		t.Errorf("loaded policy does not grant bob read, err = %v", err)
+	}
+
+	// 更新失败时保留原策略
+	if err := a.Update(Policy{Subjects: map[string][]string{"bob": {"x"}}}); err == nil {
+		t.Errorf("Update() want error")
+	}
+	if !a.Evaluate("bob", "read", "order:1") {
+		t.Errorf("failed Update() replaced the policy")
+	}
+}
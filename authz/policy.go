@@ -0,0 +1,82 @@
+package authz
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy 权限策略, 可以从配置文件加载
+//
+// 权限格式为"资源:操作", 资源可以有多段, 以":"分隔, 如"order:123:read";
+// "*"匹配任意一段, 最后一段为"*"时匹配剩余所有段, 如"order:*"包含订单的所有权限, "*"为超级权限
+//
+//	roles:
+//	  viewer: ["order:*:read"]
+//	  editor: ["order:*:write"]
+//	inherits:
+//	  editor: [viewer]
+//	subjects:
+//	  alice: [editor]
+type Policy struct {
+	Roles    map[string][]string `json:"roles" yaml:"roles" toml:"roles"`          // 角色 -> 权限
+	Inherits map[string][]string `json:"inherits" yaml:"inherits" toml:"inherits"` // 角色 -> 继承的角色
+	Subjects map[string][]string `json:"subjects" yaml:"subjects" toml:"subjects"` // 主体(用户) -> 角色, 也可以在请求时由调用方提供角色
+}
+
+// LoadPolicy 从.yaml、.yml或.json文件加载策略
+func LoadPolicy(path string) (Policy, error) {
+	var p Policy
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &p)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &p)
+	default:
+		return p, fmt.Errorf("authz: unsupported policy file %s", path)
+	}
+	if err != nil {
+		return p, fmt.Errorf("authz: parse %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// pattern 解析后的权限
+type pattern []string
+
+func parsePattern(s string) (pattern, error) {
+	if s == "" {
+		return nil, fmt.Errorf("authz: empty permission")
+	}
+	segs := strings.Split(s, ":")
+	for _, seg := range segs {
+		if seg == "" {
+			return nil, fmt.Errorf("authz: invalid permission %q", s)
+		}
+	}
+	return segs, nil
+}
+
+// match 判断权限是否包含target
+func (p pattern) match(target []string) bool {
+	for i, seg := range p {
+		if i >= len(target) {
+			return false
+		}
+		if seg == "*" && i == len(p)-1 {
+			return true
+		}
+		if seg != "*" && seg != target[i] {
+			return false
+		}
+	}
+	return len(p) == len(target)
+}
@@ -0,0 +1,54 @@
+package bufpool
+
+import (
+	"bufio"
+	"io"
+	"sync"
+)
+
+// DefaultBufioSize 池化的bufio.Reader/Writer的缓冲区大小, 与bufio默认值一致
+const DefaultBufioSize = 4096
+
+var (
+	readerPool = sync.Pool{New: func() interface{} { return bufio.NewReaderSize(nil, DefaultBufioSize) }}
+	writerPool = sync.Pool{New: func() interface{} { return bufio.NewWriterSize(nil, DefaultBufioSize) }}
+)
+
+// GetReader 获取读取r的bufio.Reader
+func GetReader(r io.Reader) *bufio.Reader {
+	br := readerPool.Get().(*bufio.Reader)
+	br.Reset(r)
+	return br
+}
+
+// PutReader 归还bufio.Reader, 未读取的缓冲数据会被丢弃
+func PutReader(br *bufio.Reader) {
+	if br == nil {
+		return
+	}
+	br.Reset(nil)
+	readerPool.Put(br)
+}
+
+// GetWriter 获取写入w的bufio.Writer
+func GetWriter(w io.Writer) *bufio.Writer {
+	bw := writerPool.Get().(*bufio.Writer)
+	bw.Reset(w)
+	return bw
+}
+
+// PutWriter 归还bufio.Writer, 调用方需先Flush, 未Flush的数据会被丢弃
+func PutWriter(bw *bufio.Writer) {
+	if bw == nil {
+		return
+	}
+	bw.Reset(nil)
+	writerPool.Put(bw)
+}
+
+// Copy 使用池化的缓冲区执行io.CopyBuffer
+func Copy(dst io.Writer, src io.Reader) (int64, error) {
+	buf := GetBytes(32 * 1024)
+	defer PutBytes(buf)
+	return io.CopyBuffer(dst, src, *buf)
+}
@@ -0,0 +1,149 @@
+package bufpool
+
+import (
+	"bytes"
+	"math/bits"
+	"sync"
+)
+
+// 默认的尺寸分级范围, 超过最大分级的缓冲区用完后直接丢弃, 避免池中长期持有大块内存
+const (
+	DefaultMinSize = 512
+	DefaultMaxSize = 1 << 20
+)
+
+// Pool 按容量分级的缓冲池, 每一级容量为2的幂, Get时按sizeHint选择能容纳它的最小分级
+//
+// 与单个sync.Pool相比, 偶尔出现的大缓冲区不会被小请求拿走后长期占用内存
+type Pool struct {
+	minShift int
+	buffers  []sync.Pool // *bytes.Buffer
+	bytes    []sync.Pool // *[]byte
+}
+
+// NewPool new a Pool, minSize和maxSize会向上取整为2的幂
+func NewPool(minSize, maxSize int) *Pool {
+	if minSize <= 0 {
+		minSize = DefaultMinSize
+	}
+	if maxSize < minSize {
+		maxSize = minSize
+	}
+	minShift := ceilShift(minSize)
+	classes := ceilShift(maxSize) - minShift + 1
+	p := &Pool{
+		minShift: minShift,
+		buffers:  make([]sync.Pool, classes),
+		bytes:    make([]sync.Pool, classes),
+	}
+	for i := range p.buffers {
+		size := 1 << (minShift + i)
+		p.buffers[i].New = func() interface{} {
+			return bytes.NewBuffer(make([]byte, 0, size))
+		}
+		p.bytes[i].New = func() interface{} {
+			b := make([]byte, size)
+			return &b
+		}
+	}
+	return p
+}
+
+// ceilShift 返回使1<<shift >= n的最小shift
+func ceilShift(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	return bits.Len(uint(n - 1))
+}
+
+// getClass sizeHint对应的分级, 超出最大分级时返回-1
+func (p *Pool) getClass(sizeHint int) int {
+	c := ceilShift(sizeHint) - p.minShift
+	if c < 0 {
+		c = 0
+	}
+	if c >= len(p.buffers) {
+		return -1
+	}
+	return c
+}
+
+// putClass 容量为capacity的缓冲区应归还的分级, 即容量不小于该分级的最大分级
+func (p *Pool) putClass(capacity int) int {
+	if capacity < 1<<p.minShift {
+		return -1
+	}
+	c := bits.Len(uint(capacity)) - 1 - p.minShift
+	if c >= len(p.buffers) {
+		return -1
+	}
+	return c
+}
+
+// Get 获取一个已清空的bytes.Buffer, 容量至少为sizeHint
+func (p *Pool) Get(sizeHint int) *bytes.Buffer {
+	c := p.getClass(sizeHint)
+	if c < 0 {
+		return bytes.NewBuffer(make([]byte, 0, sizeHint))
+	}
+	buf := p.buffers[c].Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// Put 归还bytes.Buffer, 归还后不能再使用buf及其Bytes()返回的切片
+func (p *Pool) Put(buf *bytes.Buffer) {
+	if buf == nil {
+		return
+	}
+	if c := p.putClass(buf.Cap()); c >= 0 {
+		buf.Reset()
+		p.buffers[c].Put(buf)
+	}
+}
+
+// GetBytes 获取长度为size的[]byte, 内容未清零
+func (p *Pool) GetBytes(size int) *[]byte {
+	c := p.getClass(size)
+	if c < 0 {
+		b := make([]byte, size)
+		return &b
+	}
+	b := p.bytes[c].Get().(*[]byte)
+	*b = (*b)[:size]
+	return b
+}
+
+// PutBytes 归还GetBytes获取的[]byte
+func (p *Pool) PutBytes(b *[]byte) {
+	if b == nil {
+		return
+	}
+	if c := p.putClass(cap(*b)); c >= 0 {
+		*b = (*b)[:cap(*b)]
+		p.bytes[c].Put(b)
+	}
+}
+
+var defaultPool = NewPool(DefaultMinSize, DefaultMaxSize)
+
+// Get 从默认池获取bytes.Buffer
+func Get(sizeHint int) *bytes.Buffer {
+	return defaultPool.Get(sizeHint)
+}
+
+// Put 归还bytes.Buffer到默认池
+func Put(buf *bytes.Buffer) {
+	defaultPool.Put(buf)
+}
+
+// GetBytes 从默认池获取[]byte
+func GetBytes(size int) *[]byte {
+	return defaultPool.GetBytes(size)
+}
+
+// PutBytes 归还[]byte到默认池
+func PutBytes(b *[]byte) {
+	defaultPool.PutBytes(b)
+}
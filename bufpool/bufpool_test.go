@@ -0,0 +1,93 @@
+package bufpool
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestClass(t *testing.T) {
+	p := NewPool(512, 4096)
+	tests := []struct {
+		name     string
+		hint     int
+		wantGet  int
+		capacity int
+		wantPut  int
+	}{
+		{name: "zero", hint: 0, wantGet: 0, capacity: 100, wantPut: -1},
+		{name: "min", hint: 512, wantGet: 0, capacity: 512, wantPut: 0},
+		{name: "round up", hint: 513, wantGet: 1, capacity: 1500, wantPut: 1},
+		{name: "max", hint: 4096, wantGet: 3, capacity: 4096, wantPut: 3},
+		{name: "too large", hint: 4097, wantGet: -1, capacity: 8192, wantPut: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.getClass(tt.hint); got != tt.wantGet {
+				t.Errorf("getClass(%d) = %d, want %d", tt.hint, got, tt.wantGet)
+			}
+			if got := p.putClass(tt.capacity); got != tt.wantPut {
+				t.Errorf("putClass(%d) = %d, want %d", tt.capacity, got, tt.wantPut)
+			}
+		})
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	p := NewPool(512, 4096)
+	buf := p.Get(1000)
+	if buf.Cap() < 1000 || buf.Len() != 0 {
+		t.Fatalf("Get() cap = %d len = %d", buf.Cap(), buf.Len())
+	}
+	buf.WriteString("dirty")
+	p.Put(buf)
+	if again := p.Get(1000); again.Len() != 0 {
+		t.Errorf("Get() after Put not reset: %q", again.String())
+	}
+
+	b := p.GetBytes(700)
+	if len(*b) != 700 || cap(*b) < 700 {
+		t.Errorf("GetBytes() len = %d cap = %d", len(*b), cap(*b))
+	}
+	p.PutBytes(b)
+
+	big := p.GetBytes(10000)
+	if len(*big) != 10000 {
+		t.Errorf("GetBytes() oversize len = %d", len(*big))
+	}
+	p.PutBytes(big)
+}
+
+func TestBufio(t *testing.T) {
+	br := GetReader(strings.NewReader("line1\nline2\n"))
+	line, err := br.ReadString('\n')
+	PutReader(br)
+	if err != nil || line != "line1\n" {
+		t.Errorf("ReadString() = %q, %v", line, err)
+	}
+
+	var out bytes.Buffer
+	bw := GetWriter(&out)
+	bw.WriteString("hello")
+	bw.Flush()
+	PutWriter(bw)
+	if out.String() != "hello" {
+		t.Errorf("writer output = %q", out.String())
+	}
+
+	out.Reset()
+	n, err := Copy(&out, io.LimitReader(strings.NewReader(strings.Repeat("x", 100000)), 70000))
+	if err != nil || n != 70000 || out.Len() != 70000 {
+		t.Errorf("Copy() = %d, %v", n, err)
+	}
+}
+
+func BenchmarkGetPut(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf := Get(2048)
+		buf.WriteString("payload")
+		Put(buf)
+	}
+}
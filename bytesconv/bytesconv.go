@@ -0,0 +1,18 @@
+package bytesconv
+
+// StringToBytes 将string转换为[]byte, 默认零拷贝
+//
+// 返回的切片与s共享内存, 调用方绝对不能修改它, 否则会破坏string的不可变性,
+// 对字符串常量修改会直接导致程序崩溃. 使用purego构建标签编译时退化为普通拷贝.
+func StringToBytes(s string) []byte {
+	return stringToBytes(s)
+}
+
+// BytesToString 将[]byte转换为string, 默认零拷贝
+//
+// 返回的string与b共享内存, 在string使用期间调用方不能再修改b,
+// 例如不能对复用的缓冲区(bytes.Buffer, sync.Pool)调用此函数后继续写入.
+// 使用purego构建标签编译时退化为普通拷贝.
+func BytesToString(b []byte) string {
+	return bytesToString(b)
+}
@@ -0,0 +1,64 @@
+package bytesconv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+	}{
+		{name: "empty", s: ""},
+		{name: "ascii", s: "hello"},
+		{name: "utf8", s: "你好, world"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := StringToBytes(tt.s)
+			if !bytes.Equal(b, []byte(tt.s)) {
+				t.Errorf("StringToBytes() = %v, want %v", b, []byte(tt.s))
+			}
+			if got := BytesToString([]byte(tt.s)); got != tt.s {
+				t.Errorf("BytesToString() = %q, want %q", got, tt.s)
+			}
+		})
+	}
+}
+
+var (
+	benchStr   = strings.Repeat("golib", 200)
+	benchBytes = []byte(benchStr)
+	sinkBytes  []byte
+	sinkStr    string
+)
+
+func BenchmarkStringToBytes(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sinkBytes = StringToBytes(benchStr)
+	}
+}
+
+func BenchmarkStringToBytesStd(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sinkBytes = []byte(benchStr)
+	}
+}
+
+func BenchmarkBytesToString(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sinkStr = BytesToString(benchBytes)
+	}
+}
+
+func BenchmarkBytesToStringStd(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sinkStr = string(benchBytes)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build purego
+// +build purego
+
+package bytesconv
+
+func stringToBytes(s string) []byte {
+	if s == "" {
+		return nil
+	}
+	return []byte(s)
+}
+
+func bytesToString(b []byte) string {
+	return string(b)
+}
@@ -0,0 +1,383 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	c := NewLRU[string, int](2)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1, 0)
+	c.Set("b", 2, 0)
+	c.Get("a") // a变为最近使用
+	c.Set("c", 3, time.Second)
+
+	tests := []struct {
+		key    string
+		want   int
+		wantOK bool
+	}{
+		{key: "a", want: 1, wantOK: true},
+		{key: "b", wantOK: false},
+		{key: "c", want: 3, wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, ok := c.Get(tt.key)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Get(%q) = %d, %v, want %d, %v", tt.key, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	now = now.Add(2 * time.Second)
+	if _, ok := c.Get("c"); ok || c.Len() != 1 {
+		t.Errorf("expired entry still present, Len() = %d", c.Len())
+	}
+}
+
+type memRemote struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	gets int
+}
+
+func newMemRemote() *memRemote { return &memRemote{data: map[string][]byte{}} }
+
+func (m *memRemote) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gets++
+	b, ok := m.data[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return b, nil
+}
+
+func (m *memRemote) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	return nil
+}
+
+func (m *memRemote) Del(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+func (m *memRemote) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return 0, nil
+}
+
+type bus struct {
+	mu   sync.Mutex
+	subs []func(string)
+}
+
+type busInvalidator struct{ b *bus }
+
+func (i busInvalidator) Publish(ctx context.Context, key string) error {
+	i.b.mu.Lock()
+	defer i.b.mu.Unlock()
+	for _, fn := range i.b.subs {
+		fn(key)
+	}
+	return nil
+}
+
+func (i busInvalidator) Subscribe(ctx context.Context, fn func(string)) error {
+	i.b.mu.Lock()
+	i.b.subs = append(i.b.subs, fn)
+	i.b.mu.Unlock()
+	return nil
+}
+
+func TestTieredGetOrLoad(t *testing.T) {
+	remote := newMemRemote()
+	c := NewTiered(remote, Config{})
+	ctx := context.Background()
+
+	var loads int32
+	loader := func(ctx context.Context) ([]byte, error) {
+		atomic.AddInt32(&loads, 1)
+		time.Sleep(10 * time.Millisecond)
+		return []byte("jack"), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if b, err := c.GetOrLoad(ctx, "user:1", loader); err != nil || string(b) != "jack" {
+				t.Errorf("GetOrLoad() = %q, %v", b, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if loads != 1 {
+		t.Errorf("loader called %d times, want 1", loads)
+	}
+	if string(remote.data["user:1"]) != "jack" {
+		t.Errorf("remote not written through")
+	}
+
+	gets := remote.gets
+	c.Get(ctx, "user:1")
+	if remote.gets != gets {
+		t.Errorf("Get() should hit local cache")
+	}
+}
+
+func TestTieredNegative(t *testing.T) {
+	remote := newMemRemote()
+	c := NewTiered(remote, Config{})
+	ctx := context.Background()
+
+	var loads int
+	loader := func(ctx context.Context) ([]byte, error) {
+		loads++
+		return nil, ErrNotFound
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetOrLoad(ctx, "user:404", loader); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("GetOrLoad() error = %v, want ErrNotFound", err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("loader called %d times, want 1", loads)
+	}
+
+	// 另一个实例通过远程的空值缓存也不会穿透
+	other := NewTiered(remote, Config{})
+	if _, err := other.GetOrLoad(ctx, "user:404", loader); !errors.Is(err, ErrNotFound) || loads != 1 {
+		t.Errorf("other instance GetOrLoad() error = %v, loads = %d", err, loads)
+	}
+}
+
+func TestTieredInvalidation(t *testing.T) {
+	remote := newMemRemote()
+	b := &bus{}
+	a := NewTiered(remote, Config{}, WithInvalidator(busInvalidator{b}))
+	other := NewTiered(remote, Config{}, WithInvalidator(busInvalidator{b}))
+	ctx := context.Background()
+	a.Start(ctx)
+	other.Start(ctx)
+
+	a.Set(ctx, "cfg", []byte("v1"))
+	if v, _ := other.Get(ctx, "cfg"); string(v) != "v1" {
+		t.Fatalf("other.Get() = %q", v)
+	}
+	a.Set(ctx, "cfg", []byte("v2"))
+	if v, _ := other.Get(ctx, "cfg"); string(v) != "v2" {
+		t.Errorf("other.Get() after invalidation = %q, want v2", v)
+	}
+	a.Del(ctx, "cfg")
+	if _, err := other.Get(ctx, "cfg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("other.Get() after Del error = %v", err)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	c := NewTiered(newMemRemote(), Config{Jitter: 0.5})
+	for i := 0; i < 100; i++ {
+		if d := c.jitter(time.Minute); d < time.Minute || d >= 90*time.Second {
+			t.Fatalf("jitter() = %v out of range", d)
+		}
+	}
+}
+
+func TestTieredEarlyRefresh(t *testing.T) {
+	remote := newMemRemote()
+	c := NewTiered(remote, Config{RemoteTTL: time.Minute, LocalTTL: time.Hour, EarlyRefreshBeta: 1})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	c.local.now = c.now
+
+	var loads atomic.Int32
+	loader := func(ctx context.Context) ([]byte, error) {
+		loads.Add(1)
+		return []byte("v"), nil
+	}
+	if _, err := c.GetOrLoad(context.Background(), "k", loader); err != nil {
+		t.Fatalf("GetOrLoad() error = %v", err)
+	}
+
+	// 远程值带有过期时间, 不带元数据的旧值仍可读取
+	if e := decodeEnvelope(remote.data["k"]); string(e.value) != "v" || e.expireAt.IsZero() {
+		t.Errorf("envelope = %+v", e)
+	}
+	if e := decodeEnvelope([]byte("raw")); string(e.value) != "raw" || !e.expireAt.IsZero() {
+		t.Errorf("decodeEnvelope(raw) = %+v", e)
+	}
+
+	// 已过远程有效期时必然触发刷新, 同时返回当前值
+	now = now.Add(2 * time.Minute)
+	b, err := c.GetOrLoad(context.Background(), "k", loader)
+	if err != nil || string(b) != "v" {
+		t.Fatalf("GetOrLoad() = %s, %v", b, err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for loads.Load() != 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if loads.Load() != 2 || c.Stats().EarlyRefreshes != 1 {
+		t.Errorf("loads = %d, stats = %+v", loads.Load(), c.Stats())
+	}
+}
+
+func TestShouldRefresh(t *testing.T) {
+	c := NewTiered(newMemRemote(), Config{EarlyRefreshBeta: 1})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	e := localEntry{expireAt: now.Add(time.Minute), delta: 10 * time.Second}
+
+	tests := []struct {
+		name   string
+		random float64
+		want   bool
+	}{
+		{name: "zero", random: 0, want: false},
+		{name: "typical", random: 0.5, want: false},
+		{name: "max", random: 1 - 1.0/(1<<53), want: true}, // rand.Float64的最大值
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.random = func() float64 { return tt.random }
+			if got := c.shouldRefresh(e); got != tt.want {
+				t.Errorf("shouldRefresh() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTieredServeStale(t *testing.T) {
+	remote := newMemRemote()
+	c := NewTiered(remote, Config{LocalTTL: time.Second, StaleTTL: time.Minute})
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	c.local.now = c.now
+
+	if err := c.Set(context.Background(), "k", []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+	_ = remote.Del(context.Background(), "k")
+	now = now.Add(2 * time.Second)
+
+	b, err := c.GetOrLoad(context.Background(), "k", func(ctx context.Context) ([]byte, error) {
+		return nil, errors.New("db down")
+	})
+	if err != nil || string(b) != "old" {
+		t.Errorf("GetOrLoad() = %s, %v, want stale value", b, err)
+	}
+	if s := c.Stats(); s.StaleServed != 1 || s.LoadErrors != 1 {
+		t.Errorf("Stats() = %+v", s)
+	}
+}
+
+type memLocker struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+func (l *memLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held[key] {
+		return nil, false, nil
+	}
+	l.held[key] = true
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.held, key)
+	}, true, nil
+}
+
+func TestTieredLocker(t *testing.T) {
+	remote := newMemRemote()
+	locker := &memLocker{held: map[string]bool{"k": true}} // 模拟其他实例正在重建
+	c := NewTiered(remote, Config{LockWait: 500 * time.Millisecond}, WithLocker(locker))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = remote.Set(context.Background(), "k", []byte("from-peer"), time.Minute)
+	}()
+	b, err := c.GetOrLoad(context.Background(), "k", func(ctx context.Context) ([]byte, error) {
+		t.Error("loader should not run while peer holds the lock")
+		return nil, nil
+	})
+	if err != nil || string(b) != "from-peer" {
+		t.Errorf("GetOrLoad() = %s, %v", b, err)
+	}
+	if s := c.Stats(); s.LockContended != 1 || s.Loads != 0 {
+		t.Errorf("Stats() = %+v", s)
+	}
+}
+
+func TestTieredMaxLoaders(t *testing.T) {
+	c := NewTiered(newMemRemote(), Config{MaxLoaders: 2})
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _ = c.GetOrLoad(context.Background(), string(rune('a'+i)), func(ctx context.Context) ([]byte, error) {
+				n := running.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				running.Add(-1)
+				return []byte("v"), nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	if peak.Load() > 2 {
+		t.Errorf("peak concurrent loaders = %d, want <= 2", peak.Load())
+	}
+	if s := c.Stats(); s.Loads != 10 || s.LoaderWaits == 0 {
+		t.Errorf("Stats() = %+v", s)
+	}
+}
+
+// TestTieredSharedBusy 有过期数据的调用方发起的加载没有等待时, 加入该加载的其他调用方拿到的是真实的加载结果
+func TestTieredSharedBusy(t *testing.T) {
+	c := NewTiered(newMemRemote(), Config{})
+	release := make(chan struct{})
+	go c.group.Do("k", func() (interface{}, error) {
+		// 模拟有过期数据的调用方: 并发加载数已满, 放弃加载
+		<-release
+		return loadResult{busy: true}, nil
+	})
+	time.Sleep(20 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.GetOrLoad(context.Background(), "k", func(ctx context.Context) ([]byte, error) {
+			return nil, errors.New("db down")
+		})
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	if err := <-done; err == nil || err.Error() != "db down" {
+		t.Errorf("GetOrLoad() error = %v, want loader error", err)
+	}
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/ChangSZ/golib/meta"
+)
+
+// Locker 跨实例的重建锁, 同一key同时只有一个实例执行loader
+type Locker interface {
+	// TryLock 尝试加锁, 成功时返回解锁函数
+	TryLock(ctx context.Context, key string, ttl time.Duration) (unlock func(), ok bool, err error)
+}
+
+var _ Locker = (*RedisLocker)(nil)
+
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// RedisLocker 基于SET NX的重建锁, 解锁时校验持有者, 不会误删其他实例的锁
+type RedisLocker struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewRedisLocker new a RedisLocker, prefix会加在key之前, 如"lock:"
+func NewRedisLocker(client redis.Cmdable, prefix string) *RedisLocker {
+	return &RedisLocker{client: client, prefix: prefix}
+}
+
+func (l *RedisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (func(), bool, error) {
+	key = l.prefix + key
+	token := meta.NewRequestID()
+	ok, err := l.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return func() {
+		_ = unlockScript.Run(context.Background(), l.client, []string{key}, token).Err()
+	}, true, nil
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrNotFound 缓存不存在, loader返回该错误时结果会被作为空值缓存
+var ErrNotFound = errors.New("cache: not found")
+
+// Remote 远程缓存, 通常由Redis实现
+type Remote interface {
+	// Get key不存在时返回ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Del(ctx context.Context, key string) error
+	// TTL key不存在时返回ErrNotFound, 不过期时返回0
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// Invalidator 多实例间的本地缓存失效通知
+type Invalidator interface {
+	// Publish 通知其他实例key已变化
+	Publish(ctx context.Context, key string) error
+	// Subscribe 订阅失效通知, 阻塞到ctx结束
+	Subscribe(ctx context.Context, fn func(key string)) error
+}
+
+var (
+	_ Remote      = (*RedisRemote)(nil)
+	_ Invalidator = (*RedisInvalidator)(nil)
+)
+
+// RedisRemote 基于Redis的Remote
+type RedisRemote struct {
+	client redis.Cmdable
+}
+
+// NewRedisRemote new a RedisRemote.
+func NewRedisRemote(client redis.Cmdable) *RedisRemote {
+	return &RedisRemote{client: client}
+}
+
+func (r *RedisRemote) Get(ctx context.Context, key string) ([]byte, error) {
+	b, err := r.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	return b, err
+}
+
+func (r *RedisRemote) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (r *RedisRemote) Del(ctx context.Context, key string) error {
+	return r.client.Del(ctx, key).Err()
+}
+
+func (r *RedisRemote) TTL(ctx context.Context, key string) (time.Duration, error) {
+	d, err := r.client.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	switch d {
+	case -2:
+		return 0, ErrNotFound
+	case -1:
+		return 0, nil
+	}
+	return d, nil
+}
+
+// RedisInvalidator 基于Redis Pub/Sub的失效通知, 消息格式为"实例ID|key", 自己发出的通知会被忽略
+type RedisInvalidator struct {
+	client  redis.UniversalClient
+	channel string
+	id      string
+}
+
+// NewRedisInvalidator new a RedisInvalidator, id用于区分实例, 同一组实例使用相同的channel
+func NewRedisInvalidator(client redis.UniversalClient, channel, id string) *RedisInvalidator {
+	return &RedisInvalidator{client: client, channel: channel, id: id}
+}
+
+func (r *RedisInvalidator) Publish(ctx context.Context, key string) error {
+	return r.client.Publish(ctx, r.channel, r.id+"|"+key).Err()
+}
+
+func (r *RedisInvalidator) Subscribe(ctx context.Context, fn func(key string)) error {
+	sub := r.client.Subscribe(ctx, r.channel)
+	defer sub.Close()
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			from, key, found := strings.Cut(msg.Payload, "|")
+			if found && from != r.id {
+				fn(key)
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package cache
+
+import "sync/atomic"
+
+// Stats 缓存统计, 用于上报监控和调整参数
+type Stats struct {
+	LocalHits      uint64 // 本地缓存命中
+	RemoteHits     uint64 // 远程缓存命中
+	NegativeHits   uint64 // 命中空值缓存
+	Misses         uint64 // 两级都未命中
+	Loads          uint64 // 调用loader的次数
+	LoadErrors     uint64 // loader返回错误(不含ErrNotFound)的次数
+	EarlyRefreshes uint64 // 提前刷新的次数
+	StaleServed    uint64 // 返回过期数据的次数
+	LoaderWaits    uint64 // 并发加载数达到上限需要等待的次数
+	LockContended  uint64 // 重建锁被其他实例持有的次数
+}
+
+// Hits 命中总数
+func (s Stats) Hits() uint64 {
+	return s.LocalHits + s.RemoteHits + s.NegativeHits
+}
+
+type counters struct {
+	localHits, remoteHits, negativeHits, misses    atomic.Uint64
+	loads, loadErrors, earlyRefreshes, staleServed atomic.Uint64
+	loaderWaits, lockContended                     atomic.Uint64
+}
+
+func (c *counters) snapshot() Stats {
+	return Stats{
+		LocalHits:      c.localHits.Load(),
+		RemoteHits:     c.remoteHits.Load(),
+		NegativeHits:   c.negativeHits.Load(),
+		Misses:         c.misses.Load(),
+		Loads:          c.loads.Load(),
+		LoadErrors:     c.loadErrors.Load(),
+		EarlyRefreshes: c.earlyRefreshes.Load(),
+		StaleServed:    c.staleServed.Load(),
+		LoaderWaits:    c.loaderWaits.Load(),
+		LockContended:  c.lockContended.Load(),
+	}
+}
@@ -0,0 +1,438 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/ChangSZ/golib/log"
+)
+
+// negativeValue 远程缓存中表示"不存在"的值
+var negativeValue = []byte("\x00golib:cache:nil")
+
+// envelopeMagic 开启提前刷新时远程缓存的值带有过期时间和加载耗时, 格式为magic + expireAt(8字节) + delta(8字节) + 数据
+var envelopeMagic = []byte("\x00gc1")
+
+const envelopeSize = 4 + 8 + 8
+
+// loadResult load的结果, 通过group.Do共享给同一key的所有调用方;
+// busy表示发起方不等待并发加载数或重建锁而放弃了加载, 是否返回过期数据由各调用方自己决定
+type loadResult struct {
+	value []byte
+	busy  bool
+}
+
+type Config struct {
+	LocalSize   int           `toml:"localSize"`   // 本地缓存最大条目数, 默认10000
+	LocalTTL    time.Duration `toml:"localTTL"`    // 本地缓存有效期, 默认1m, 应明显短于RemoteTTL
+	RemoteTTL   time.Duration `toml:"remoteTTL"`   // 远程缓存有效期, 默认10m
+	NegativeTTL time.Duration `toml:"negativeTTL"` // 空值缓存有效期, 默认30s, <0表示不缓存空值
+	Jitter      float64       `toml:"jitter"`      // 有效期随机浮动比例, 避免大量key同时过期, 默认0.1
+
+	// EarlyRefreshBeta 提前刷新(XFetch)系数, 越大越早刷新, 一般取1; 0表示关闭
+	// 开启后远程缓存的值会带上过期时间和加载耗时, 其他直接读取Redis的服务需要注意
+	EarlyRefreshBeta float64       `toml:"earlyRefreshBeta"`
+	StaleTTL         time.Duration `toml:"staleTTL"`   // 本地数据过期后仍可作为兜底返回的时间, 0表示不返回过期数据
+	MaxLoaders       int           `toml:"maxLoaders"` // 本实例同时执行的loader上限, 0表示不限制
+	LockTTL          time.Duration `toml:"lockTTL"`    // 重建锁的有效期, 默认10s
+	LockWait         time.Duration `toml:"lockWait"`   // 未抢到重建锁时等待其他实例写入的最长时间, 默认1s
+}
+
+// Option Tiered的可选参数
+type Option func(*Tiered)
+
+// WithInvalidator 设置多实例间的本地缓存失效通知, 需调用Start订阅
+func WithInvalidator(inv Invalidator) Option {
+	return func(t *Tiered) {
+		t.invalidator = inv
+	}
+}
+
+// WithLocker 设置跨实例的重建锁, 缓存失效时只有抢到锁的实例执行loader, 其他实例等待或返回过期数据
+func WithLocker(l Locker) Option {
+	return func(t *Tiered) {
+		t.locker = l
+	}
+}
+
+type localEntry struct {
+	value      []byte
+	negative   bool
+	freshUntil time.Time     // 本地数据的有效期, 之后到StaleTTL为止只作为兜底
+	expireAt   time.Time     // 远程数据的过期时间, 用于提前刷新, 零值表示未知
+	delta      time.Duration // 上次加载的耗时
+}
+
+// Tiered 本地LRU + 远程缓存的两级缓存, 写操作同时写两级(write-through)
+//
+//	c := cache.NewTiered(cache.NewRedisRemote(client), cache.Config{},
+//		cache.WithInvalidator(cache.NewRedisInvalidator(client, "cache:invalidate", instanceID)))
+//	go c.Start(ctx)
+//	b, err := c.GetOrLoad(ctx, "user:1", func(ctx context.Context) ([]byte, error) { ... })
+type Tiered struct {
+	cfg         Config
+	local       *LRU[string, localEntry]
+	remote      Remote
+	invalidator Invalidator
+	locker      Locker
+	loaders     *semaphore.Weighted
+	group       singleflight.Group
+	stats       counters
+	now         func() time.Time
+
+	rndMu  sync.Mutex
+	rnd    *rand.Rand
+	random func() float64 // [0, 1)内的随机数, 测试时替换
+}
+
+// NewTiered new a Tiered.
+func NewTiered(remote Remote, cfg Config, opts ...Option) *Tiered {
+	if cfg.LocalSize <= 0 {
+		cfg.LocalSize = 10000
+	}
+	if cfg.LocalTTL <= 0 {
+		cfg.LocalTTL = time.Minute
+	}
+	if cfg.RemoteTTL <= 0 {
+		cfg.RemoteTTL = 10 * time.Minute
+	}
+	if cfg.NegativeTTL == 0 {
+		cfg.NegativeTTL = 30 * time.Second
+	}
+	if cfg.Jitter <= 0 {
+		cfg.Jitter = 0.1
+	}
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = 10 * time.Second
+	}
+	if cfg.LockWait <= 0 {
+		cfg.LockWait = time.Second
+	}
+	t := &Tiered{
+		cfg:    cfg,
+		local:  NewLRU[string, localEntry](cfg.LocalSize),
+		remote: remote,
+		now:    time.Now,
+		rnd:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	t.random = t.randFloat
+	if cfg.MaxLoaders > 0 {
+		t.loaders = semaphore.NewWeighted(int64(cfg.MaxLoaders))
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Stats 当前的统计数据
+func (t *Tiered) Stats() Stats {
+	return t.stats.snapshot()
+}
+
+// jitter 在d的基础上随机增加[0, d*Jitter)
+func (t *Tiered) jitter(d time.Duration) time.Duration {
+	t.rndMu.Lock()
+	defer t.rndMu.Unlock()
+	if n := int64(float64(d) * t.cfg.Jitter); n > 0 {
+		return d + time.Duration(t.rnd.Int63n(n))
+	}
+	return d
+}
+
+func (t *Tiered) randFloat() float64 {
+	t.rndMu.Lock()
+	defer t.rndMu.Unlock()
+	return t.rnd.Float64()
+}
+
+// Get 先查本地再查远程, 不存在(包括空值缓存)时返回ErrNotFound
+func (t *Tiered) Get(ctx context.Context, key string) ([]byte, error) {
+	e, err := t.get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return e.value, nil
+}
+
+func (t *Tiered) get(ctx context.Context, key string) (localEntry, error) {
+	if e, ok := t.local.Get(key); ok && t.now().Before(e.freshUntil) {
+		if e.negative {
+			t.stats.negativeHits.Add(1)
+			return e, ErrNotFound
+		}
+		t.stats.localHits.Add(1)
+		return e, nil
+	}
+
+	b, err := t.remote.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			t.stats.misses.Add(1)
+		}
+		return localEntry{}, err
+	}
+	if bytes.Equal(b, negativeValue) {
+		t.stats.negativeHits.Add(1)
+		e := t.setLocal(key, localEntry{negative: true})
+		return e, ErrNotFound
+	}
+	t.stats.remoteHits.Add(1)
+	e := decodeEnvelope(b)
+	return t.setLocal(key, e), nil
+}
+
+func (t *Tiered) setLocal(key string, e localEntry) localEntry {
+	ttl := t.cfg.LocalTTL
+	if e.negative && t.cfg.NegativeTTL < ttl {
+		ttl = t.cfg.NegativeTTL
+	}
+	ttl = t.jitter(ttl)
+	e.freshUntil = t.now().Add(ttl)
+	t.local.Set(key, e, ttl+t.cfg.StaleTTL)
+	return e
+}
+
+// stale 本地的过期数据
+func (t *Tiered) stale(key string) ([]byte, bool) {
+	if t.cfg.StaleTTL <= 0 {
+		return nil, false
+	}
+	e, ok := t.local.Get(key)
+	if !ok || e.negative {
+		return nil, false
+	}
+	return e.value, true
+}
+
+// shouldRefresh XFetch: 越接近过期、加载越慢, 提前刷新的概率越大
+func (t *Tiered) shouldRefresh(e localEntry) bool {
+	if t.cfg.EarlyRefreshBeta <= 0 || e.expireAt.IsZero() || e.negative {
+		return false
+	}
+	// random()可能返回0, 取1-r避免-log(0)为+Inf
+	gap := time.Duration(float64(e.delta) * t.cfg.EarlyRefreshBeta * -math.Log(1-t.random()))
+	return !t.now().Add(gap).Before(e.expireAt)
+}
+
+// GetOrLoad 缓存不存在时调用loader加载并写入缓存, 同一实例内同一key的并发加载只执行一次
+//
+// loader返回ErrNotFound时缓存空值NegativeTTL, 防止缓存穿透; 根据配置还会:
+//   - 在过期前按概率提前在后台刷新(EarlyRefreshBeta)
+//   - 加载失败、并发加载数达到上限或重建锁被其他实例持有时返回过期数据(StaleTTL)
+func (t *Tiered) GetOrLoad(ctx context.Context, key string, loader func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	e, err := t.get(ctx, key)
+	if err == nil {
+		if t.shouldRefresh(e) {
+			t.stats.earlyRefreshes.Add(1)
+			go t.group.Do(key, func() (interface{}, error) {
+				return t.load(context.WithoutCancel(ctx), key, loader, true)
+			})
+		}
+		return e.value, nil
+	}
+	if errors.Is(err, ErrNotFound) && e.negative {
+		return nil, err
+	}
+	if !errors.Is(err, ErrNotFound) {
+		// 远程缓存故障时降级为直接加载
+		log.Warnw("msg", "cache: remote get failed", "key", key, "err", err)
+	}
+
+	stale, hasStale := t.stale(key)
+	r, err := t.share(ctx, key, loader, !hasStale)
+	if err == nil && r.busy && !hasStale {
+		// 加入的是有过期数据的调用方发起的加载, 它没有等待; 本调用方没有兜底数据, 重新发起等待的加载
+		r, err = t.share(ctx, key, loader, true)
+	}
+	if hasStale && (r.busy || err != nil && !errors.Is(err, ErrNotFound)) {
+		t.stats.staleServed.Add(1)
+		return stale, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.value, nil
+}
+
+// share 同一key的并发加载只执行一次, wait由实际执行加载的调用方决定
+func (t *Tiered) share(ctx context.Context, key string, loader func(ctx context.Context) ([]byte, error), wait bool) (loadResult, error) {
+	v, err, _ := t.group.Do(key, func() (interface{}, error) {
+		return t.load(ctx, key, loader, wait)
+	})
+	r, _ := v.(loadResult)
+	return r, err
+}
+
+// load 执行loader并写入缓存; wait为false时, 需要等待并发加载数或重建锁的情况下返回busy, 不执行loader
+func (t *Tiered) load(ctx context.Context, key string, loader func(ctx context.Context) ([]byte, error), wait bool) (loadResult, error) {
+	if t.loaders != nil {
+		if !t.loaders.TryAcquire(1) {
+			t.stats.loaderWaits.Add(1)
+			if !wait {
+				return loadResult{busy: true}, nil
+			}
+			if err := t.loaders.Acquire(ctx, 1); err != nil {
+				return loadResult{}, err
+			}
+		}
+		defer t.loaders.Release(1)
+	}
+
+	if t.locker != nil {
+		unlock, ok, err := t.locker.TryLock(ctx, key, t.cfg.LockTTL)
+		switch {
+		case err != nil:
+			log.Warnw("msg", "cache: rebuild lock failed", "key", key, "err", err)
+		case ok:
+			defer unlock()
+		default:
+			t.stats.lockContended.Add(1)
+			if !wait {
+				return loadResult{busy: true}, nil
+			}
+			if b, err := t.waitRemote(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+				return loadResult{value: b}, err
+			}
+			// 等待超时后自己加载, 避免持锁实例异常时一直不可用
+		}
+	}
+
+	t.stats.loads.Add(1)
+	start := t.now()
+	b, err := loader(ctx)
+	delta := t.now().Sub(start)
+	if errors.Is(err, ErrNotFound) {
+		if t.cfg.NegativeTTL > 0 {
+			t.setNegative(ctx, key)
+		}
+		return loadResult{}, ErrNotFound
+	}
+	if err != nil {
+		t.stats.loadErrors.Add(1)
+		return loadResult{}, err
+	}
+	if err := t.setBoth(ctx, key, b, delta); err != nil {
+		log.Warnw("msg", "cache: remote set failed", "key", key, "err", err)
+	}
+	return loadResult{value: b}, nil
+}
+
+// waitRemote 等待持有重建锁的实例写入远程缓存, 超时返回context.DeadlineExceeded
+func (t *Tiered) waitRemote(ctx context.Context, key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.cfg.LockWait)
+	defer cancel()
+	ticker := time.NewTicker(t.cfg.LockWait / 20)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+		b, err := t.remote.Get(ctx, key)
+		if err == nil {
+			if bytes.Equal(b, negativeValue) {
+				t.setLocal(key, localEntry{negative: true})
+				return nil, ErrNotFound
+			}
+			return t.setLocal(key, decodeEnvelope(b)).value, nil
+		}
+	}
+}
+
+func (t *Tiered) setNegative(ctx context.Context, key string) {
+	t.setLocal(key, localEntry{negative: true})
+	if err := t.remote.Set(ctx, key, negativeValue, t.jitter(t.cfg.NegativeTTL)); err != nil {
+		log.Warnw("msg", "cache: remote set negative failed", "key", key, "err", err)
+	}
+}
+
+func (t *Tiered) setBoth(ctx context.Context, key string, value []byte, delta time.Duration) error {
+	ttl := t.jitter(t.cfg.RemoteTTL)
+	e := localEntry{value: value}
+	remoteValue := value
+	if t.cfg.EarlyRefreshBeta > 0 {
+		e.expireAt, e.delta = t.now().Add(ttl), delta
+		remoteValue = encodeEnvelope(e)
+	}
+	t.setLocal(key, e)
+	return t.remote.Set(ctx, key, remoteValue, ttl)
+}
+
+func encodeEnvelope(e localEntry) []byte {
+	b := make([]byte, envelopeSize+len(e.value))
+	copy(b, envelopeMagic)
+	binary.BigEndian.PutUint64(b[4:12], uint64(e.expireAt.UnixNano()))
+	binary.BigEndian.PutUint64(b[12:20], uint64(e.delta))
+	copy(b[envelopeSize:], e.value)
+	return b
+}
+
+// decodeEnvelope 解析带元数据的值, 普通的值原样返回
+func decodeEnvelope(b []byte) localEntry {
+	if len(b) < envelopeSize || !bytes.HasPrefix(b, envelopeMagic) {
+		return localEntry{value: b}
+	}
+	return localEntry{
+		value:    b[envelopeSize:],
+		expireAt: time.Unix(0, int64(binary.BigEndian.Uint64(b[4:12]))),
+		delta:    time.Duration(binary.BigEndian.Uint64(b[12:20])),
+	}
+}
+
+// Set 写入两级缓存并通知其他实例失效本地缓存
+func (t *Tiered) Set(ctx context.Context, key string, value []byte) error {
+	if err := t.setBoth(ctx, key, value, 0); err != nil {
+		t.local.Delete(key)
+		return err
+	}
+	t.publish(ctx, key)
+	return nil
+}
+
+// Del 删除两级缓存并通知其他实例失效本地缓存
+func (t *Tiered) Del(ctx context.Context, key string) error {
+	t.local.Delete(key)
+	if err := t.remote.Del(ctx, key); err != nil {
+		return err
+	}
+	t.publish(ctx, key)
+	return nil
+}
+
+// TTL 远程缓存的剩余有效期
+func (t *Tiered) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return t.remote.TTL(ctx, key)
+}
+
+// Invalidate 只删除本地缓存, 收到其他实例的失效通知时调用
+func (t *Tiered) Invalidate(key string) {
+	t.local.Delete(key)
+}
+
+func (t *Tiered) publish(ctx context.Context, key string) {
+	if t.invalidator == nil {
+		return
+	}
+	if err := t.invalidator.Publish(ctx, key); err != nil {
+		log.Warnw("msg", "cache: publish invalidation failed", "key", key, "err", err)
+	}
+}
+
+// Start 订阅失效通知, 阻塞到ctx结束; 未设置Invalidator时直接返回
+func (t *Tiered) Start(ctx context.Context) error {
+	if t.invalidator == nil {
+		return nil
+	}
+	return t.invalidator.Subscribe(ctx, t.Invalidate)
+}
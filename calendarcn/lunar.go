@@ -0,0 +1,213 @@
+package calendarcn
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrOutOfRange 超出支持的日期范围(公历1900-01-31至2100-12-31)
+var ErrOutOfRange = errors.New("calendarcn: date out of range")
+
+const (
+	minYear = 1900
+	maxYear = 2100
+)
+
+// lunarInfo 1900-2100年的农历数据
+//
+// 低4位为闰月月份(0表示无闰月), 第5-16位依次为正月至腊月是否为大月(30天), 第17位为闰月是否为大月
+var lunarInfo = [...]int{
+	0x04bd8, 0x04ae0, 0x0a570, 0x054d5, 0x0d260, 0x0d950, 0x16554, 0x056a0, 0x09ad0, 0x055d2, // 1900-1909
+	0x04ae0, 0x0a5b6, 0x0a4d0, 0x0d250, 0x1d255, 0x0b540, 0x0d6a0, 0x0ada2, 0x095b0, 0x14977, // 1910-1919
+	0x04970, 0x0a4b0, 0x0b4b5, 0x06a50, 0x06d40, 0x1ab54, 0x02b60, 0x09570, 0x052f2, 0x04970, // 1920-1929
+	0x06566, 0x0d4a0, 0x0ea50, 0x16a95, 0x05ad0, 0x02b60, 0x186e3, 0x092e0, 0x1c8d7, 0x0c950, // 1930-1939
+	0x0d4a0, 0x1d8a6, 0x0b550, 0x056a0, 0x1a5b4, 0x025d0, 0x092d0, 0x0d2b2, 0x0a950, 0x0b557, // 1940-1949
+	0x06ca0, 0x0b550, 0x15355, 0x04da0, 0x0a5b0, 0x14573, 0x052b0, 0x0a9a8, 0x0e950, 0x06aa0, // 1950-1959
+	0x0aea6, 0x0ab50, 0x04b60, 0x0aae4, 0x0a570, 0x05260, 0x0f263, 0x0d950, 0x05b57, 0x056a0, // 1960-1969
+	0x096d0, 0x04dd5, 0x04ad0, 0x0a4d0, 0x0d4d4, 0x0d250, 0x0d558, 0x0b540, 0x0b6a0, 0x195a6, // 1970-1979
+	0x095b0, 0x049b0, 0x0a974, 0x0a4b0, 0x0b27a, 0x06a50, 0x06d40, 0x0af46, 0x0ab60, 0x09570, // 1980-1989
+	0x04af5, 0x04970, 0x064b0, 0x074a3, 0x0ea50, 0x06b58, 0x05ac0, 0x0ab60, 0x096d5, 0x092e0, // 1990-1999
+	0x0c960, 0x0d954, 0x0d4a0, 0x0da50, 0x07552, 0x056a0, 0x0abb7, 0x025d0, 0x092d0, 0x0cab5, // 2000-2009
+	0x0a950, 0x0b4a0, 0x0baa4, 0x0ad50, 0x055d9, 0x04ba0, 0x0a5b0, 0x15176, 0x052b0, 0x0a930, // 2010-2019
+	0x07954, 0x06aa0, 0x0ad50, 0x05b52, 0x04b60, 0x0a6e6, 0x0a4e0, 0x0d260, 0x0ea65, 0x0d530, // 2020-2029
+	0x05aa0, 0x076a3, 0x096d0, 0x04afb, 0x04ad0, 0x0a4d0, 0x1d0b6, 0x0d250, 0x0d520, 0x0dd45, // 2030-2039
+	0x0b5a0, 0x056d0, 0x055b2, 0x049b0, 0x0a577, 0x0a4b0, 0x0aa50, 0x1b255, 0x06d20, 0x0ada0, // 2040-2049
+	0x14b63, 0x09370, 0x049f8, 0x04970, 0x064b0, 0x168a6, 0x0ea50, 0x06b20, 0x1a6c4, 0x0aae0, // 2050-2059
+	0x092e0, 0x0d2e3, 0x0c960, 0x0d557, 0x0d4a0, 0x0da50, 0x05d55, 0x056a0, 0x0a6d0, 0x055d4, // 2060-2069
+	0x052d0, 0x0a9b8, 0x0a950, 0x0b4a0, 0x0b6a6, 0x0ad50, 0x055a0, 0x0aba4, 0x0a5b0, 0x052b0, // 2070-2079
+	0x0b273, 0x06930, 0x07337, 0x06aa0, 0x0ad50, 0x14b55, 0x04b60, 0x0a570, 0x054e4, 0x0d160, // 2080-2089
+	0x0e968, 0x0d520, 0x0daa0, 0x16aa6, 0x056d0, 0x04ae0, 0x0a9d4, 0x0a2d0, 0x0d150, 0x0f252, // 2090-2099
+	0x0d520, // 2100
+}
+
+// baseDate 农历1900年正月初一
+var baseDate = time.Date(1900, 1, 31, 0, 0, 0, 0, time.UTC)
+
+// LeapMonth 农历year年的闰月月份, 没有闰月时返回0
+func LeapMonth(year int) int {
+	if year < minYear || year > maxYear {
+		return 0
+	}
+	return lunarInfo[year-minYear] & 0xf
+}
+
+func leapDays(year int) int {
+	if LeapMonth(year) == 0 {
+		return 0
+	}
+	if lunarInfo[year-minYear]&0x10000 != 0 {
+		return 30
+	}
+	return 29
+}
+
+// MonthDays 农历year年month月的天数, leap为true时返回闰月的天数
+func MonthDays(year, month int, leap bool) int {
+	if leap {
+		return leapDays(year)
+	}
+	if lunarInfo[year-minYear]&(0x10000>>month) != 0 {
+		return 30
+	}
+	return 29
+}
+
+// YearDays 农历year年的总天数
+func YearDays(year int) int {
+	sum := 348
+	for mask := 0x8000; mask > 0x8; mask >>= 1 {
+		if lunarInfo[year-minYear]&mask != 0 {
+			sum++
+		}
+	}
+	return sum + leapDays(year)
+}
+
+// Lunar 农历日期
+type Lunar struct {
+	Year   int
+	Month  int
+	Day    int
+	IsLeap bool // 是否为闰月
+}
+
+// SolarToLunar 公历转农历, 只使用t的年月日
+func SolarToLunar(t time.Time) (Lunar, error) {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := int(date.Sub(baseDate).Hours() / 24)
+	if offset < 0 || t.Year() > maxYear {
+		return Lunar{}, ErrOutOfRange
+	}
+
+	year := minYear
+	for ; year <= maxYear; year++ {
+		days := YearDays(year)
+		if offset < days {
+			break
+		}
+		offset -= days
+	}
+	if year > maxYear {
+		return Lunar{}, ErrOutOfRange
+	}
+
+	leap := LeapMonth(year)
+	for month := 1; month <= 12; month++ {
+		days := MonthDays(year, month, false)
+		if offset < days {
+			return Lunar{Year: year, Month: month, Day: offset + 1}, nil
+		}
+		offset -= days
+		if month == leap {
+			days = leapDays(year)
+			if offset < days {
+				return Lunar{Year: year, Month: month, Day: offset + 1, IsLeap: true}, nil
+			}
+			offset -= days
+		}
+	}
+	return Lunar{}, ErrOutOfRange
+}
+
+// LunarToSolar 农历转公历, 返回time.Local时区的零点
+func LunarToSolar(year, month, day int, leap bool) (time.Time, error) {
+	if year < minYear || year > maxYear {
+		return time.Time{}, ErrOutOfRange
+	}
+	if month < 1 || month > 12 || (leap && LeapMonth(year) != month) {
+		return time.Time{}, fmt.Errorf("calendarcn: invalid lunar month %d(leap=%v) in %d", month, leap, year)
+	}
+	if day < 1 || day > MonthDays(year, month, leap) {
+		return time.Time{}, fmt.Errorf("calendarcn: invalid lunar day %d", day)
+	}
+
+	offset := 0
+	for y := minYear; y < year; y++ {
+		offset += YearDays(y)
+	}
+	leapMonth := LeapMonth(year)
+	for m := 1; m < month; m++ {
+		offset += MonthDays(year, m, false)
+		if m == leapMonth {
+			offset += leapDays(year)
+		}
+	}
+	if leap {
+		offset += MonthDays(year, month, false)
+	}
+	offset += day - 1
+
+	d := baseDate.AddDate(0, 0, offset)
+	if d.Year() > maxYear {
+		return time.Time{}, ErrOutOfRange
+	}
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.Local), nil
+}
+
+var (
+	gan         = []string{"甲", "乙", "丙", "丁", "戊", "己", "庚", "辛", "壬", "癸"}
+	zhi         = []string{"子", "丑", "寅", "卯", "辰", "巳", "午", "未", "申", "酉", "戌", "亥"}
+	zodiacs     = []string{"鼠", "牛", "虎", "兔", "龙", "蛇", "马", "羊", "猴", "鸡", "狗", "猪"}
+	monthNames  = []string{"正", "二", "三", "四", "五", "六", "七", "八", "九", "十", "冬", "腊"}
+	dayTens     = []string{"初", "十", "廿", "三"}
+	chineseNums = []string{"日", "一", "二", "三", "四", "五", "六", "七", "八", "九", "十"}
+)
+
+// GanZhiYear 农历年的干支, 如2024年为"甲辰"
+func (l Lunar) GanZhiYear() string {
+	i := (l.Year - 4) % 60
+	return gan[i%10] + zhi[i%12]
+}
+
+// Zodiac 生肖
+func (l Lunar) Zodiac() string {
+	return zodiacs[(l.Year-4)%12]
+}
+
+// MonthName 月份名称, 如"正月"、"闰四月"、"腊月"
+func (l Lunar) MonthName() string {
+	name := monthNames[l.Month-1] + "月"
+	if l.IsLeap {
+		return "闰" + name
+	}
+	return name
+}
+
+// DayName 日期名称, 如"初一"、"十五"、"廿三"、"三十"
+func (l Lunar) DayName() string {
+	switch l.Day {
+	case 10:
+		return "初十"
+	case 20:
+		return "二十"
+	case 30:
+		return "三十"
+	}
+	return dayTens[l.Day/10] + chineseNums[l.Day%10]
+}
+
+// String 如"甲辰年正月初一"
+func (l Lunar) String() string {
+	return l.GanZhiYear() + "年" + l.MonthName() + l.DayName()
+}
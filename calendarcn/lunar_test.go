@@ -0,0 +1,103 @@
+package calendarcn
+
+import (
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func TestSpringFestival(t *testing.T) {
+	// 春节日期校验每一年的总天数
+	tests := []struct {
+		year int
+		want time.Time
+	}{
+		{1900, date(1900, 1, 31)}, {1949, date(1949, 1, 29)}, {1970, date(1970, 2, 6)},
+		{1980, date(1980, 2, 16)}, {1990, date(1990, 1, 27)}, {2000, date(2000, 2, 5)},
+		{2001, date(2001, 1, 24)}, {2002, date(2002, 2, 12)}, {2003, date(2003, 2, 1)},
+		{2004, date(2004, 1, 22)}, {2005, date(2005, 2, 9)}, {2006, date(2006, 1, 29)},
+		{2007, date(2007, 2, 18)}, {2008, date(2008, 2, 7)}, {2009, date(2009, 1, 26)},
+		{2010, date(2010, 2, 14)}, {2011, date(2011, 2, 3)}, {2012, date(2012, 1, 23)},
+		{2013, date(2013, 2, 10)}, {2014, date(2014, 1, 31)}, {2015, date(2015, 2, 19)},
+		{2016, date(2016, 2, 8)}, {2017, date(2017, 1, 28)}, {2018, date(2018, 2, 16)},
+		{2019, date(2019, 2, 5)}, {2020, date(2020, 1, 25)}, {2021, date(2021, 2, 12)},
+		{2022, date(2022, 2, 1)}, {2023, date(2023, 1, 22)}, {2024, date(2024, 2, 10)},
+		{2025, date(2025, 1, 29)}, {2026, date(2026, 2, 17)},
+	}
+	for _, tt := range tests {
+		got, err := LunarToSolar(tt.year, 1, 1, false)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("LunarToSolar(%d, 1, 1) = %v, %v, want %v", tt.year, got.Format("2006-01-02"), err, tt.want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestSolarToLunar(t *testing.T) {
+	tests := []struct {
+		solar  time.Time
+		want   Lunar
+		wantS  string
+		zodiac string
+	}{
+		{solar: date(2024, 2, 10), want: Lunar{2024, 1, 1, false}, wantS: "甲辰年正月初一", zodiac: "龙"},
+		{solar: date(2024, 9, 17), want: Lunar{2024, 8, 15, false}, wantS: "甲辰年八月十五", zodiac: "龙"},
+		{solar: date(2023, 3, 22), want: Lunar{2023, 2, 1, true}, wantS: "癸卯年闰二月初一", zodiac: "兔"},
+		{solar: date(2020, 6, 20), want: Lunar{2020, 4, 29, true}, wantS: "庚子年闰四月廿九", zodiac: "鼠"},
+		{solar: date(2025, 1, 28), want: Lunar{2024, 12, 29, false}, wantS: "甲辰年腊月廿九", zodiac: "龙"},
+		{solar: date(2025, 10, 6), want: Lunar{2025, 8, 15, false}, wantS: "乙巳年八月十五", zodiac: "蛇"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantS, func(t *testing.T) {
+			got, err := SolarToLunar(tt.solar)
+			if err != nil || got != tt.want || got.String() != tt.wantS || got.Zodiac() != tt.zodiac {
+				t.Errorf("SolarToLunar() = %+v %s %s, %v, want %+v %s", got, got, got.Zodiac(), err, tt.want, tt.wantS)
+			}
+			back, err := LunarToSolar(got.Year, got.Month, got.Day, got.IsLeap)
+			if err != nil || !back.Equal(tt.solar) {
+				t.Errorf("LunarToSolar() = %v, %v, want %v", back, err, tt.solar)
+			}
+		})
+	}
+
+	if _, err := SolarToLunar(date(1900, 1, 1)); err != ErrOutOfRange {
+		t.Errorf("SolarToLunar() before range error = %v", err)
+	}
+	if _, err := LunarToSolar(2024, 4, 1, true); err == nil {
+		t.Errorf("LunarToSolar() with nonexistent leap month should fail")
+	}
+}
+
+func TestRoundTripAllDays(t *testing.T) {
+	for d := date(1900, 1, 31); d.Year() <= 2100; d = d.AddDate(0, 0, 1) {
+		l, err := SolarToLunar(d)
+		if err != nil {
+			t.Fatalf("SolarToLunar(%v) error = %v", d, err)
+		}
+		back, err := LunarToSolar(l.Year, l.Month, l.Day, l.IsLeap)
+		if err != nil || !back.Equal(d) {
+			t.Fatalf("round trip %v => %+v => %v, %v", d, l, back, err)
+		}
+	}
+}
+
+func TestFestivals(t *testing.T) {
+	// 端午(五月初五)和中秋(八月十五)校验月份的大小
+	tests := []struct {
+		month, day int
+		solar      time.Time
+	}{
+		{5, 5, date(2019, 6, 7)}, {5, 5, date(2021, 6, 14)}, {5, 5, date(2022, 6, 3)},
+		{5, 5, date(2023, 6, 22)}, {5, 5, date(2024, 6, 10)}, {5, 5, date(2025, 5, 31)},
+		{8, 15, date(2019, 9, 13)}, {8, 15, date(2020, 10, 1)}, {8, 15, date(2021, 9, 21)},
+		{8, 15, date(2022, 9, 10)}, {8, 15, date(2023, 9, 29)},
+	}
+	for _, tt := range tests {
+		got, err := SolarToLunar(tt.solar)
+		if err != nil || got.Month != tt.month || got.Day != tt.day || got.IsLeap {
+			t.Errorf("SolarToLunar(%s) = %+v, %v, want %d-%d", tt.solar.Format("2006-01-02"), got, err, tt.month, tt.day)
+		}
+	}
+}
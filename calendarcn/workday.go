@@ -0,0 +1,173 @@
+package calendarcn
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// Day 法定节假日安排中的一天
+type Day struct {
+	Date    string `json:"date"`    // 2006-01-02
+	Holiday bool   `json:"holiday"` // true为放假, false为调休上班
+	Name    string `json:"name"`    // 节日名称
+}
+
+// Calendar 工作日历, 默认周一至周五为工作日, 节假日安排表中的日期优先
+//
+// 内置2024-2025年的国务院节假日安排, 之后的年份在公布后通过Load或Set更新
+type Calendar struct {
+	mu   sync.RWMutex
+	days map[string]Day
+}
+
+// NewCalendar new a Calendar, 包含内置的节假日安排
+func NewCalendar() *Calendar {
+	c := &Calendar{days: make(map[string]Day)}
+	c.SetDays(builtinDays()...)
+	return c
+}
+
+// SetDays 添加或覆盖节假日安排
+func (c *Calendar) SetDays(days ...Day) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, d := range days {
+		c.days[d.Date] = d
+	}
+}
+
+// Set 设置某天为放假(holiday=true)或调休上班(holiday=false)
+func (c *Calendar) Set(date time.Time, holiday bool, name string) {
+	c.SetDays(Day{Date: date.Format(dateLayout), Holiday: holiday, Name: name})
+}
+
+// Load 从JSON数组加载节假日安排, 格式为[{"date":"2026-01-01","holiday":true,"name":"元旦"}]
+func (c *Calendar) Load(r io.Reader) error {
+	var days []Day
+	if err := json.NewDecoder(r).Decode(&days); err != nil {
+		return fmt.Errorf("calendarcn: load days: %w", err)
+	}
+	for _, d := range days {
+		if _, err := time.Parse(dateLayout, d.Date); err != nil {
+			return fmt.Errorf("calendarcn: invalid date %q", d.Date)
+		}
+	}
+	c.SetDays(days...)
+	return nil
+}
+
+// Lookup 返回节假日安排表中的记录
+func (c *Calendar) Lookup(t time.Time) (Day, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	d, ok := c.days[t.Format(dateLayout)]
+	return d, ok
+}
+
+// IsWorkday 是否为工作日(含调休上班)
+func (c *Calendar) IsWorkday(t time.Time) bool {
+	if d, ok := c.Lookup(t); ok {
+		return !d.Holiday
+	}
+	wd := t.Weekday()
+	return wd != time.Saturday && wd != time.Sunday
+}
+
+// IsHoliday 是否为法定节假日(不含普通周末)
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	d, ok := c.Lookup(t)
+	return ok && d.Holiday
+}
+
+// NextWorkday t之后的第一个工作日, 时分秒与t相同
+func (c *Calendar) NextWorkday(t time.Time) time.Time {
+	return c.AddWorkdays(t, 1)
+}
+
+// AddWorkdays t之后第n个工作日, n为负数时向前查找, n为0时返回t
+func (c *Calendar) AddWorkdays(t time.Time, n int) time.Time {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for n > 0 {
+		t = t.AddDate(0, 0, step)
+		if c.IsWorkday(t) {
+			n--
+		}
+	}
+	return t
+}
+
+// WorkdaysBetween [from, to)之间的工作日天数
+func (c *Calendar) WorkdaysBetween(from, to time.Time) int {
+	n := 0
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		if c.IsWorkday(d) {
+			n++
+		}
+	}
+	return n
+}
+
+// Default 默认工作日历
+var Default = NewCalendar()
+
+// IsWorkday 使用Default判断是否为工作日
+func IsWorkday(t time.Time) bool { return Default.IsWorkday(t) }
+
+// IsHoliday 使用Default判断是否为法定节假日
+func IsHoliday(t time.Time) bool { return Default.IsHoliday(t) }
+
+// NextWorkday 使用Default查找下一个工作日
+func NextWorkday(t time.Time) time.Time { return Default.NextWorkday(t) }
+
+// AddWorkdays 使用Default查找之后第n个工作日
+func AddWorkdays(t time.Time, n int) time.Time { return Default.AddWorkdays(t, n) }
+
+func builtinDays() []Day {
+	var days []Day
+	holiday := func(name, from, to string) {
+		start, _ := time.Parse(dateLayout, from)
+		end, _ := time.Parse(dateLayout, to)
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			days = append(days, Day{Date: d.Format(dateLayout), Holiday: true, Name: name})
+		}
+	}
+	workday := func(name string, dates ...string) {
+		for _, d := range dates {
+			days = append(days, Day{Date: d, Name: name})
+		}
+	}
+
+	// 2024年
+	holiday("元旦", "2024-01-01", "2024-01-01")
+	holiday("春节", "2024-02-10", "2024-02-17")
+	workday("春节", "2024-02-04", "2024-02-18")
+	holiday("清明节", "2024-04-04", "2024-04-06")
+	workday("清明节", "2024-04-07")
+	holiday("劳动节", "2024-05-01", "2024-05-05")
+	workday("劳动节", "2024-04-28", "2024-05-11")
+	holiday("端午节", "2024-06-10", "2024-06-10")
+	holiday("中秋节", "2024-09-15", "2024-09-17")
+	workday("中秋节", "2024-09-14")
+	holiday("国庆节", "2024-10-01", "2024-10-07")
+	workday("国庆节", "2024-09-29", "2024-10-12")
+
+	// 2025年
+	holiday("元旦", "2025-01-01", "2025-01-01")
+	holiday("春节", "2025-01-28", "2025-02-04")
+	workday("春节", "2025-01-26", "2025-02-08")
+	holiday("清明节", "2025-04-04", "2025-04-06")
+	holiday("劳动节", "2025-05-01", "2025-05-05")
+	workday("劳动节", "2025-04-27")
+	holiday("端午节", "2025-05-31", "2025-06-02")
+	holiday("国庆节、中秋节", "2025-10-01", "2025-10-08")
+	workday("国庆节、中秋节", "2025-09-28", "2025-10-11")
+	return days
+}
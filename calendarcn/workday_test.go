@@ -0,0 +1,68 @@
+package calendarcn
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsWorkday(t *testing.T) {
+	tests := []struct {
+		name string
+		day  time.Time
+		want bool
+	}{
+		{name: "normal monday", day: date(2024, 3, 4), want: true},
+		{name: "normal saturday", day: date(2024, 3, 9), want: false},
+		{name: "spring festival weekday", day: date(2024, 2, 14), want: false},
+		{name: "adjusted sunday", day: date(2024, 2, 18), want: true},
+		{name: "national day", day: date(2025, 10, 8), want: false},
+		{name: "adjusted saturday", day: date(2025, 10, 11), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsWorkday(tt.day); got != tt.want {
+				t.Errorf("IsWorkday(%s) = %v, want %v", tt.day.Format(dateLayout), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddWorkdays(t *testing.T) {
+	c := NewCalendar()
+	tests := []struct {
+		name string
+		from time.Time
+		n    int
+		want time.Time
+	}{
+		{name: "before spring festival", from: date(2025, 1, 27), n: 1, want: date(2025, 2, 5)},
+		{name: "over weekend", from: date(2024, 3, 8), n: 1, want: date(2024, 3, 11)},
+		{name: "backwards", from: date(2024, 10, 8), n: -1, want: date(2024, 9, 30)},
+		{name: "zero", from: date(2024, 10, 1), n: 0, want: date(2024, 10, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.AddWorkdays(tt.from, tt.n); !got.Equal(tt.want) {
+				t.Errorf("AddWorkdays() = %s, want %s", got.Format(dateLayout), tt.want.Format(dateLayout))
+			}
+		})
+	}
+	if got := c.WorkdaysBetween(date(2024, 10, 1), date(2024, 10, 14)); got != 5 {
+		t.Errorf("WorkdaysBetween() = %d, want 5", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	c := NewCalendar()
+	err := c.Load(strings.NewReader(`[{"date":"2030-01-01","holiday":true,"name":"元旦"},{"date":"2030-01-05","holiday":false,"name":"元旦"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.IsWorkday(date(2030, 1, 1)) || !c.IsWorkday(date(2030, 1, 5)) || !c.IsHoliday(date(2030, 1, 1)) {
+		t.Errorf("loaded days not applied")
+	}
+	if err := c.Load(strings.NewReader(`[{"date":"2030/01/01"}]`)); err == nil {
+		t.Errorf("Load() should reject invalid dates")
+	}
+}
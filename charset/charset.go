@@ -0,0 +1,272 @@
+package charset
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/transform"
+)
+
+// Charset 字符集名称
+type Charset string
+
+const (
+	UTF8    Charset = "UTF-8"
+	GBK     Charset = "GBK"
+	GB2312  Charset = "GB2312"
+	GB18030 Charset = "GB18030"
+	Big5    Charset = "Big5"
+	Unknown Charset = ""
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// detectSize 自动识别时最多读取的字节数
+const detectSize = 4096
+
+// ErrUnsupportedRune 要编码的字符不在目标字符集中, 如GBK新增的"镕"不在GB2312中
+var ErrUnsupportedRune = errors.New("charset: rune not supported by charset")
+
+// encodingOf 返回字符集对应的编码
+//
+// GBK/GB2312的解码统一使用GB18030(超集), 编码则按各自字符集进行, 超出字符集的字符会报错
+func encodingOf(cs Charset, decode bool) (encoding.Encoding, error) {
+	switch cs {
+	case GBK, GB2312:
+		if decode {
+			return simplifiedchinese.GB18030, nil
+		}
+		if cs == GB2312 {
+			return gb2312{}, nil
+		}
+		return simplifiedchinese.GBK, nil
+	case GB18030:
+		return simplifiedchinese.GB18030, nil
+	case Big5:
+		return traditionalchinese.Big5, nil
+	}
+	return nil, fmt.Errorf("charset: unsupported charset %q", cs)
+}
+
+// gb2312 GB2312(EUC-CN)编码; GB2312是GBK的子集, 先按GBK编码, 再检查每个双字节字符是否落在GB2312的编码区
+type gb2312 struct{}
+
+func (gb2312) NewDecoder() *encoding.Decoder {
+	return simplifiedchinese.GB18030.NewDecoder()
+}
+
+func (gb2312) NewEncoder() *encoding.Encoder {
+	return &encoding.Encoder{Transformer: transform.Chain(simplifiedchinese.GBK.NewEncoder(), &gb2312Checker{})}
+}
+
+// gb2312Checker 原样输出GBK编码的字节, 遇到GB2312编码区之外的字符时返回ErrUnsupportedRune
+type gb2312Checker struct {
+	transform.NopResetter
+}
+
+func (*gb2312Checker) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		size := 1
+		if c := src[nSrc]; c >= 0x80 {
+			if nSrc+1 >= len(src) {
+				if atEOF {
+					return nDst, nSrc, ErrUnsupportedRune
+				}
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			// 符号区0xA1-0xA9, 汉字区0xB0-0xF7, 第二字节0xA1-0xFE
+			t := src[nSrc+1]
+			if !(c >= 0xA1 && c <= 0xA9 || c >= 0xB0 && c <= 0xF7) || t < 0xA1 || t > 0xFE {
+				return nDst, nSrc, ErrUnsupportedRune
+			}
+			size = 2
+		}
+		if nDst+size > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], src[nSrc:nSrc+size])
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
+
+// Detect 识别字节串的字符集, 支持UTF-8、GBK(GB2312/GB18030)、Big5
+//
+// 合法UTF-8直接判定为UTF-8; 否则按双字节编码规则校验,
+// 同时满足GBK与Big5时按常用汉字区分布打分, 分数相同优先GBK
+func Detect(b []byte) Charset {
+	if bytes.HasPrefix(b, utf8BOM) || utf8.Valid(b) {
+		return UTF8
+	}
+	// 截断在多字节字符中间时, 仍可判定为UTF-8
+	if len(b) > utf8.UTFMax && utf8.Valid(trimIncomplete(b)) {
+		return UTF8
+	}
+
+	gbOK, gbScore := scanGBK(b)
+	big5OK, big5Score := scanBig5(b)
+	switch {
+	case gbOK && big5OK:
+		if big5Score > gbScore {
+			return Big5
+		}
+		return GBK
+	case gbOK:
+		return GBK
+	case big5OK:
+		return Big5
+	}
+	return Unknown
+}
+
+// ToUTF8 自动识别字符集并转换为UTF-8, 已是UTF-8时去掉BOM后原样返回
+func ToUTF8(b []byte) ([]byte, error) {
+	cs := Detect(b)
+	if cs == Unknown {
+		return nil, fmt.Errorf("charset: unable to detect charset")
+	}
+	return Decode(b, cs)
+}
+
+// Decode 将指定字符集的字节串转换为UTF-8
+func Decode(b []byte, from Charset) ([]byte, error) {
+	if from == UTF8 {
+		return bytes.TrimPrefix(b, utf8BOM), nil
+	}
+	enc, err := encodingOf(from, true)
+	if err != nil {
+		return nil, err
+	}
+	out, _, err := transform.Bytes(enc.NewDecoder(), b)
+	return out, err
+}
+
+// FromUTF8 将UTF-8字节串转换为指定字符集, 常用于生成给银行、老系统的文件
+func FromUTF8(b []byte, to Charset) ([]byte, error) {
+	if to == UTF8 {
+		return b, nil
+	}
+	enc, err := encodingOf(to, false)
+	if err != nil {
+		return nil, err
+	}
+	out, _, err := transform.Bytes(enc.NewEncoder(), b)
+	return out, err
+}
+
+// NewUTF8Reader 读取开头部分识别字符集, 返回转换为UTF-8的Reader, 适合处理上传的CSV/TXT
+func NewUTF8Reader(r io.Reader) (io.Reader, Charset, error) {
+	br := bufio.NewReaderSize(r, detectSize)
+	head, err := br.Peek(detectSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, Unknown, err
+	}
+	cs := Detect(head)
+	if cs == Unknown {
+		return nil, Unknown, fmt.Errorf("charset: unable to detect charset")
+	}
+	if cs == UTF8 {
+		if bytes.HasPrefix(head, utf8BOM) {
+			_, _ = br.Discard(len(utf8BOM))
+		}
+		return br, cs, nil
+	}
+	enc, _ := encodingOf(cs, true)
+	return transform.NewReader(br, enc.NewDecoder()), cs, nil
+}
+
+// NewWriter 返回将UTF-8写入转换为指定字符集的Writer
+func NewWriter(w io.Writer, to Charset) (io.Writer, error) {
+	if to == UTF8 {
+		return w, nil
+	}
+	enc, err := encodingOf(to, false)
+	if err != nil {
+		return nil, err
+	}
+	return transform.NewWriter(w, enc.NewEncoder()), nil
+}
+
+// trimIncomplete 去掉末尾不完整的UTF-8字符
+func trimIncomplete(b []byte) []byte {
+	for i := 1; i <= utf8.UTFMax && i <= len(b); i++ {
+		if utf8.RuneStart(b[len(b)-i]) {
+			if !utf8.FullRune(b[len(b)-i:]) {
+				return b[:len(b)-i]
+			}
+			break
+		}
+	}
+	return b
+}
+
+// scanGBK 校验GBK/GB18030编码规则, 返回是否合法以及落在GB2312汉字区的字符数
+func scanGBK(b []byte) (bool, int) {
+	score := 0
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if c < 0x80 {
+			continue
+		}
+		if c == 0x80 || c == 0xFF {
+			return false, 0
+		}
+		// 截断在末尾的不完整字符忽略
+		if i+1 >= len(b) {
+			break
+		}
+		t := b[i+1]
+		switch {
+		case t >= 0x30 && t <= 0x39: // GB18030四字节
+			if i+3 >= len(b) {
+				return true, score
+			}
+			if b[i+2] < 0x81 || b[i+2] > 0xFE || b[i+3] < 0x30 || b[i+3] > 0x39 {
+				return false, 0
+			}
+			i += 3
+		case t >= 0x40 && t <= 0xFE && t != 0x7F:
+			if c >= 0xB0 && c <= 0xF7 && t >= 0xA1 {
+				score++
+			}
+			i++
+		default:
+			return false, 0
+		}
+	}
+	return true, score
+}
+
+// scanBig5 校验Big5编码规则, 返回是否合法以及落在常用字区的字符数
+func scanBig5(b []byte) (bool, int) {
+	score := 0
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if c < 0x80 {
+			continue
+		}
+		if c < 0x81 || c == 0xFF {
+			return false, 0
+		}
+		if i+1 >= len(b) {
+			break
+		}
+		t := b[i+1]
+		if !(t >= 0x40 && t <= 0x7E) && !(t >= 0xA1 && t <= 0xFE) {
+			return false, 0
+		}
+		// 常用字区 0xA440-0xC67E
+		if c >= 0xA4 && c <= 0xC6 {
+			score++
+		}
+		i++
+	}
+	return true, score
+}
@@ -0,0 +1,113 @@
+package charset
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+const sample = "户名,账号,金额\n张三,6222020200012345678,100.00\n李四,6222020200087654321,2,000.50\n"
+
+func TestDetect(t *testing.T) {
+	gbk, err := FromUTF8([]byte(sample), GBK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	big5, err := FromUTF8([]byte("中華民國的銀行對帳單與傳統檔案"), Big5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		b    []byte
+		want Charset
+	}{
+		{name: "ascii", b: []byte("hello"), want: UTF8},
+		{name: "utf8", b: []byte(sample), want: UTF8},
+		{name: "utf8_bom", b: append([]byte{0xEF, 0xBB, 0xBF}, sample...), want: UTF8},
+		{name: "utf8_truncated", b: []byte(sample)[:len(sample)-40], want: UTF8},
+		{name: "gbk", b: gbk, want: GBK},
+		{name: "big5", b: big5, want: Big5},
+		{name: "binary", b: []byte{0xFF, 0xFE, 0x00, 0x80}, want: Unknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.b); got != tt.want {
+				t.Errorf("Detect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToUTF8(t *testing.T) {
+	gbk, _ := FromUTF8([]byte(sample), GB2312)
+	got, err := ToUTF8(gbk)
+	if err != nil || string(got) != sample {
+		t.Errorf("ToUTF8() = %s, %v, want %s", got, err, sample)
+	}
+
+	if _, err := FromUTF8([]byte("😀"), GBK); err == nil {
+		t.Errorf("FromUTF8() expect error for characters outside GBK")
+	}
+}
+
+func TestFromUTF8(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		to      Charset
+		want    []byte
+		wantErr error
+	}{
+		{name: "gb2312", s: "a中文", to: GB2312, want: []byte{'a', 0xD6, 0xD0, 0xCE, 0xC4}},
+		{name: "gbk only rune", s: "朱镕基", to: GBK, want: []byte{0xD6, 0xEC, 0xE9, 0x46, 0xBB, 0xF9}},
+		{name: "gbk only rune in gb2312", s: "朱镕基", to: GB2312, wantErr: ErrUnsupportedRune},
+		{name: "euro in gb2312", s: "€", to: GB2312, wantErr: ErrUnsupportedRune},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromUTF8([]byte(tt.s), tt.to)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("FromUTF8() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !bytes.Equal(got, tt.want) {
+				t.Errorf("FromUTF8() = % X, %v, want % X", got, err, tt.want)
+			}
+		})
+	}
+
+	// 通过Writer分多次写入时, 双字节字符跨越缓冲区边界也能正确处理
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, GB2312)
+	for _, r := range sample {
+		if _, err := io.WriteString(w, string(r)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want, _ := FromUTF8([]byte(sample), GBK); !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("NewWriter(GB2312) = % X, want % X", buf.Bytes(), want)
+	}
+}
+
+func TestNewUTF8Reader(t *testing.T) {
+	gbk, _ := FromUTF8([]byte(sample), GBK)
+	r, cs, err := NewUTF8Reader(bytes.NewReader(gbk))
+	if err != nil || cs != GBK {
+		t.Fatalf("NewUTF8Reader() = %v, %v", cs, err)
+	}
+	got, _ := io.ReadAll(r)
+	if string(got) != sample {
+		t.Errorf("ReadAll() = %s, want %s", got, sample)
+	}
+
+	r, cs, _ = NewUTF8Reader(bytes.NewReader(append([]byte{0xEF, 0xBB, 0xBF}, sample...)))
+	got, _ = io.ReadAll(r)
+	if cs != UTF8 || string(got) != sample {
+		t.Errorf("ReadAll() = %s, want %s", got, sample)
+	}
+}
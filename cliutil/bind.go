@@ -0,0 +1,181 @@
+package cliutil
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ChangSZ/golib/decode"
+	"github.com/ChangSZ/golib/reflectutil"
+	"github.com/ChangSZ/golib/scanutil"
+	"github.com/ChangSZ/golib/tagparse"
+)
+
+const (
+	TagFlag    = "flag"    // `flag:"name,short=n"`, 为"-"时跳过; 未指定名称时使用字段名的kebab-case
+	TagEnv     = "env"     // `env:"APP_PORT"`, 为"-"时不读环境变量
+	TagDefault = "default" // `default:"8080"`, 切片以逗号分隔
+	TagUsage   = "usage"   // 帮助信息中的说明
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// valueDecoder 与配置、导入等场景一致, 字符串按弱类型解码, 支持time.Duration、RFC3339时间和逗号分隔的切片
+var valueDecoder = decode.NewDecoder(
+	decode.WithWeaklyTyped(),
+	decode.WithHooks(
+		decode.StringToDurationHook(),
+		decode.StringToTimeHook(time.RFC3339),
+		decode.StringToSliceHook(","),
+	),
+)
+
+// option 一个绑定到结构体字段的flag
+type option struct {
+	name     string
+	short    string
+	env      string
+	def      string
+	usage    string
+	required bool
+	value    *fieldValue
+}
+
+// fieldValue 实现flag.Value, 将字符串解码到字段
+type fieldValue struct {
+	v        reflect.Value
+	fromFlag bool // 切片字段在命令行中第一次出现时丢弃默认值和环境变量的值, 之后重复出现时追加
+}
+
+func (f *fieldValue) String() string {
+	if f == nil || !f.v.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(f.v.Interface())
+}
+
+func (f *fieldValue) Set(s string) error {
+	if f.v.Kind() != reflect.Slice {
+		return valueDecoder.Decode(s, f.v.Addr().Interface())
+	}
+	tmp := reflect.New(f.v.Type())
+	if err := valueDecoder.Decode(s, tmp.Interface()); err != nil {
+		return err
+	}
+	if !f.fromFlag {
+		f.v.Set(tmp.Elem())
+		return nil
+	}
+	f.v.Set(reflect.AppendSlice(f.v, tmp.Elem()))
+	return nil
+}
+
+// IsBoolFlag bool字段可以只写"-verbose"
+func (f *fieldValue) IsBoolFlag() bool {
+	return f.v.Kind() == reflect.Bool
+}
+
+// flagValue 命令行中设置的值, 与fieldValue区分以便处理切片的追加
+type flagValue struct {
+	*fieldValue
+}
+
+func (f flagValue) Set(s string) error {
+	if err := f.fieldValue.Set(s); err != nil {
+		return err
+	}
+	f.fromFlag = true
+	return nil
+}
+
+// parseOptions 解析cfg的字段, cfg必须是结构体指针
+//
+// 内嵌结构体的字段被提升, 具名的结构体字段以"name."为前缀展开, 如DB.Host => db.host
+func parseOptions(cfg interface{}, envPrefix string) ([]*option, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cliutil: flags must be a non-nil struct pointer, got %T", cfg)
+	}
+	var opts []*option
+	collectOptions(v.Elem(), "", envPrefix, &opts)
+	return opts, nil
+}
+
+func collectOptions(v reflect.Value, prefix, envPrefix string, opts *[]*option) {
+	for _, f := range reflectutil.Fields(v.Type()) {
+		tag, ok := tagparse.Lookup(f.StructField, TagFlag)
+		if tag.Skip {
+			continue
+		}
+		fv := reflectutil.FieldByIndex(v, f.Index, true)
+		name := prefix + tag.NameOr(kebab(f.Name))
+		env := envName(f.StructField, envPrefix)
+
+		if ft := indirectType(fv.Type()); ft.Kind() == reflect.Struct && ft != timeType && !ok {
+			collectOptions(reflectutil.Indirect(fv, true), name+".", env, opts)
+			continue
+		}
+		short, _ := tag.Param("short")
+		binding := tagparse.Get(f.StructField, "binding")
+		*opts = append(*opts, &option{
+			name:     name,
+			short:    short,
+			env:      env,
+			def:      f.Tag.Get(TagDefault),
+			usage:    f.Tag.Get(TagUsage),
+			required: binding.Name == "required" || binding.Has("required"),
+			value:    &fieldValue{v: fv},
+		})
+	}
+}
+
+// envName env标签优先, 否则在设置了前缀时由前缀和字段名生成, 如APP_DB_HOST
+func envName(field reflect.StructField, prefix string) string {
+	if tag, ok := field.Tag.Lookup(TagEnv); ok {
+		if tag == "-" {
+			return ""
+		}
+		return tag
+	}
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "_" + strings.ToUpper(scanutil.Snake(field.Name))
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func kebab(name string) string {
+	return strings.ReplaceAll(scanutil.Snake(name), "_", "-")
+}
+
+// bind 依次应用默认值、环境变量, 并把flag注册到fs, 优先级为 命令行 > 环境变量 > 默认值
+func bind(fs *flag.FlagSet, opts []*option) error {
+	for _, o := range opts {
+		if o.def != "" {
+			if err := o.value.Set(o.def); err != nil {
+				return fmt.Errorf("cliutil: default of --%s: %w", o.name, err)
+			}
+		}
+		if o.env != "" {
+			if s, ok := os.LookupEnv(o.env); ok {
+				if err := o.value.Set(s); err != nil {
+					return &UsageError{Err: fmt.Errorf("invalid value %q for $%s: %w", s, o.env, err)}
+				}
+			}
+		}
+		fs.Var(flagValue{o.value}, o.name, o.usage)
+		if o.short != "" {
+			fs.Var(flagValue{o.value}, o.short, o.usage)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package cliutil
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/ChangSZ/golib/validator"
+)
+
+// ErrHelp 命令行中出现-h或--help, 帮助信息已输出
+var ErrHelp = flag.ErrHelp
+
+// UsageError 命令行用法错误, 如未知的flag、命令或校验失败, Main遇到时会输出帮助信息
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// RunFunc 命令的执行函数, args为flag之后剩余的参数
+type RunFunc func(ctx context.Context, args []string) error
+
+// Option New的可选参数
+type Option func(*Command)
+
+// WithEnvPrefix 没有env标签的字段也从环境变量读取, 变量名为 前缀_字段名, 如APP_LOG_LEVEL
+func WithEnvPrefix(prefix string) Option {
+	return func(c *Command) {
+		c.envPrefix = prefix
+	}
+}
+
+// WithOutput 帮助信息和错误的输出, 默认os.Stderr
+func WithOutput(w io.Writer) Option {
+	return func(c *Command) {
+		c.out = w
+	}
+}
+
+// Command 命令, New返回根命令, 通过Command添加子命令
+//
+// flag绑定到结构体字段, 优先级为 命令行 > 环境变量 > default标签, 解析后使用binding标签校验:
+//
+//	type ServeFlags struct {
+//		Addr    string        `flag:"addr,short=a" default:":8080" usage:"监听地址"`
+//		Timeout time.Duration `default:"5s" usage:"请求超时"`
+//		DSN     string        `env:"DB_DSN" binding:"required" usage:"数据库连接"`
+//	}
+//
+//	var global struct{ Verbose bool `flag:"verbose,short=v"` }
+//	app := cliutil.New("tool", "内部运维工具", cliutil.WithEnvPrefix("TOOL")).Flags(&global)
+//	var serve ServeFlags
+//	app.Command("serve", "启动服务", &serve, func(ctx context.Context, args []string) error { ... })
+//	app.Main()
+//
+// 与标准库flag一致, 遇到第一个非flag参数时停止解析, 因此父命令的flag需写在子命令名之前
+type Command struct {
+	name      string
+	usage     string
+	cfg       interface{}
+	run       RunFunc
+	parent    *Command
+	subs      []*Command
+	envPrefix string
+	out       io.Writer
+}
+
+// New new a Command.
+func New(name, usage string, opts ...Option) *Command {
+	c := &Command{name: name, usage: usage, out: os.Stderr}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Flags 设置命令的flag结构体, cfg必须是结构体指针
+func (c *Command) Flags(cfg interface{}) *Command {
+	c.cfg = cfg
+	return c
+}
+
+// Action 设置命令的执行函数; 有子命令时, 只有未指定子命令才会执行
+func (c *Command) Action(run RunFunc) *Command {
+	c.run = run
+	return c
+}
+
+// Command 添加子命令, cfg可以为nil
+func (c *Command) Command(name, usage string, cfg interface{}, run RunFunc) *Command {
+	sub := &Command{name: name, usage: usage, cfg: cfg, run: run, parent: c}
+	c.subs = append(c.subs, sub)
+	return sub
+}
+
+// Name 命令的完整名称, 如"tool serve"
+func (c *Command) Name() string {
+	if c.parent == nil {
+		return c.name
+	}
+	return c.parent.Name() + " " + c.name
+}
+
+func (c *Command) root() *Command {
+	if c.parent == nil {
+		return c
+	}
+	return c.parent.root()
+}
+
+// Run 解析args并执行命令, args不包含程序名
+func (c *Command) Run(ctx context.Context, args []string) error {
+	opts, err := c.options()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	if err := bind(fs, opts); err != nil {
+		return err
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			c.printUsage(opts)
+			return ErrHelp
+		}
+		return &UsageError{Err: err}
+	}
+	if c.cfg != nil {
+		if err := binding.Validator.ValidateStruct(c.cfg); err != nil {
+			return &UsageError{Err: validator.GetValidationError(err)}
+		}
+	}
+
+	rest := fs.Args()
+	if len(c.subs) == 0 || (len(rest) == 0 && c.run != nil) {
+		if c.run == nil {
+			return &UsageError{Err: errors.New("no action")}
+		}
+		return c.run(ctx, rest)
+	}
+	if len(rest) == 0 {
+		return &UsageError{Err: errors.New("missing command")}
+	}
+	if rest[0] == "help" {
+		if len(rest) > 1 {
+			if sub := c.find(rest[1]); sub != nil {
+				return sub.Run(ctx, []string{"-h"})
+			}
+		}
+		c.printUsage(opts)
+		return ErrHelp
+	}
+	sub := c.find(rest[0])
+	if sub == nil {
+		return &UsageError{Err: fmt.Errorf("unknown command %q", rest[0])}
+	}
+	return sub.Run(ctx, rest[1:])
+}
+
+func (c *Command) find(name string) *Command {
+	for _, sub := range c.subs {
+		if sub.name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+func (c *Command) options() ([]*option, error) {
+	if c.cfg == nil {
+		return nil, nil
+	}
+	return parseOptions(c.cfg, c.root().envPrefix)
+}
+
+func (c *Command) printUsage(opts []*option) {
+	_, _ = io.WriteString(c.root().out, usageText(c, opts))
+}
+
+// Usage 帮助信息
+func (c *Command) Usage() string {
+	opts, err := c.options()
+	if err != nil {
+		return err.Error()
+	}
+	return usageText(c, opts)
+}
+
+// Main 以os.Args执行根命令并退出进程, SIGINT、SIGTERM时取消ctx
+//
+// 退出码: 成功或-h为0, 执行失败为1, 用法错误为2
+func (c *Command) Main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	err := c.Run(ctx, os.Args[1:])
+	stop()
+	os.Exit(c.exitCode(err))
+}
+
+func (c *Command) exitCode(err error) int {
+	out := c.root().out
+	var ue *UsageError
+	switch {
+	case err == nil, errors.Is(err, ErrHelp):
+		return 0
+	case errors.As(err, &ue):
+		fmt.Fprintf(out, "%s: %v\nRun '%s -h' for usage.\n", c.name, err, c.name)
+		return 2
+	default:
+		fmt.Fprintf(out, "%s: %v\n", c.name, err)
+		return 1
+	}
+}
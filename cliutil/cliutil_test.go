@@ -0,0 +1,139 @@
+package cliutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type dbFlags struct {
+	Host string `default:"localhost" usage:"数据库地址"`
+	Port int    `default:"3306"`
+}
+
+type serveFlags struct {
+	Addr    string        `flag:"addr,short=a" default:":8080" usage:"监听地址"`
+	Timeout time.Duration `default:"5s"`
+	Tags    []string      `default:"a,b"`
+	Debug   bool          `flag:"debug,short=d"`
+	Token   string        `env:"TEST_CLI_TOKEN" binding:"required"`
+	DB      dbFlags
+	Ignored string `flag:"-"`
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		want    serveFlags
+		wantErr bool
+	}{
+		{
+			name: "defaults and env",
+			env:  map[string]string{"TEST_CLI_TOKEN": "t1", "APP_DB_PORT": "3307"},
+			args: []string{"serve"},
+			want: serveFlags{Addr: ":8080", Timeout: 5 * time.Second, Tags: []string{"a", "b"}, Token: "t1",
+				DB: dbFlags{Host: "localhost", Port: 3307}},
+		},
+		{
+			name: "flags override",
+			env:  map[string]string{"TEST_CLI_TOKEN": "t1", "APP_DB_PORT": "3307"},
+			args: []string{"serve", "-a", ":9090", "--timeout=1m", "-tags", "x", "-tags", "y,z", "-d",
+				"--token", "t2", "--db.port", "5432", "rest"},
+			want: serveFlags{Addr: ":9090", Timeout: time.Minute, Tags: []string{"x", "y", "z"}, Debug: true, Token: "t2",
+				DB: dbFlags{Host: "localhost", Port: 5432}},
+		},
+		{
+			name:    "required",
+			args:    []string{"serve"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			args:    []string{"serve", "--token", "t", "--timeout", "abc"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown command",
+			args:    []string{"deploy"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			var got serveFlags
+			var gotArgs []string
+			app := New("tool", "", WithEnvPrefix("APP"), WithOutput(&bytes.Buffer{}))
+			app.Command("serve", "启动服务", &got, func(ctx context.Context, args []string) error {
+				gotArgs = args
+				return nil
+			})
+
+			err := app.Run(context.Background(), tt.args)
+			var ue *UsageError
+			if tt.wantErr {
+				if !errors.As(err, &ue) {
+					t.Errorf("Run() error = %v, want UsageError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("flags = %+v, want %+v", got, tt.want)
+			}
+			if tt.name == "flags override" && (len(gotArgs) != 1 || gotArgs[0] != "rest") {
+				t.Errorf("args = %v", gotArgs)
+			}
+		})
+	}
+}
+
+func TestGlobalFlagsAndHelp(t *testing.T) {
+	var global struct {
+		Verbose bool `flag:"verbose,short=v" usage:"输出详细日志"`
+	}
+	var out bytes.Buffer
+	app := New("tool", "内部运维工具", WithOutput(&out)).Flags(&global)
+	var ran bool
+	var serve serveFlags
+	app.Command("serve", "启动服务", &serve, nil)
+	app.Command("version", "输出版本", nil, func(ctx context.Context, args []string) error {
+		ran = global.Verbose
+		return nil
+	})
+
+	if err := app.Run(context.Background(), []string{"-v", "version"}); err != nil || !ran {
+		t.Errorf("Run() error = %v, ran = %v", err, ran)
+	}
+
+	if err := app.Run(context.Background(), []string{"help", "serve"}); !errors.Is(err, ErrHelp) {
+		t.Errorf("Run() error = %v, want ErrHelp", err)
+	}
+	for _, want := range []string{
+		"Usage: tool serve [flags] [args]",
+		`-a, --addr string`,
+		`监听地址 (default ":8080")`,
+		"--timeout duration",
+		"--tags strings",
+		"(required) [$TEST_CLI_TOKEN]",
+		"--db.host string",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("usage missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if usage := app.Usage(); !strings.Contains(usage, "  serve     启动服务") || !strings.Contains(usage, "-v, --verbose ") {
+		t.Errorf("Usage() = \n%s", usage)
+	}
+}
@@ -0,0 +1,155 @@
+package cliutil
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"abc", 3},
+		{"中文", 4},
+		{"ｈｉ", 4},
+		{"\x1b[31mred\x1b[0m", 3},
+		{"é", 1},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := Width(tt.s); got != tt.want {
+			t.Errorf("Width(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+	if got := Truncate("中文名称", 7); got != "中文..." {
+		t.Errorf("Truncate() = %q", got)
+	}
+}
+
+func TestTable(t *testing.T) {
+	tb := NewTable("ID", "名称", "大小")
+	tb.SetAlign(2, AlignRight)
+	tb.Append(1, "日志", "1.5 KB")
+	tb.Append(20, "backup", "10 MB")
+	want := "" +
+		"ID  名称      大小\n" +
+		"--  ------  ------\n" +
+		"1   日志    1.5 KB\n" +
+		"20  backup   10 MB\n"
+	if got := tb.String(); got != want {
+		t.Errorf("String() = \n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPrompter(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		run   func(p *Prompter) (interface{}, error)
+		want  interface{}
+	}{
+		{
+			name:  "confirm default",
+			input: "\n",
+			run:   func(p *Prompter) (interface{}, error) { return p.Confirm("继续?", true) },
+			want:  true,
+		},
+		{
+			name:  "confirm retry",
+			input: "maybe\nN\n",
+			run:   func(p *Prompter) (interface{}, error) { return p.Confirm("继续?", true) },
+			want:  false,
+		},
+		{
+			name:  "input validate",
+			input: "ab\nabc\n",
+			run: func(p *Prompter) (interface{}, error) {
+				return p.Input("名称", "", func(s string) error {
+					if len(s) < 3 {
+						return errors.New("too short")
+					}
+					return nil
+				})
+			},
+			want: "abc",
+		},
+		{
+			name:  "input default",
+			input: "\n",
+			run:   func(p *Prompter) (interface{}, error) { return p.Input("环境", "dev", nil) },
+			want:  "dev",
+		},
+		{
+			name:  "select",
+			input: "9\n2\n",
+			run:   func(p *Prompter) (interface{}, error) { return p.Select("环境", []string{"dev", "prod"}, -1) },
+			want:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPrompter(strings.NewReader(tt.input), &bytes.Buffer{})
+			got, err := tt.run(p)
+			if err != nil || got != tt.want {
+				t.Errorf("got %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	p := NewPrompter(strings.NewReader(""), &bytes.Buffer{})
+	if _, err := p.Confirm("继续?", false); !errors.Is(err, ErrNoInput) {
+		t.Errorf("Confirm() error = %v, want ErrNoInput", err)
+	}
+}
+
+func TestBar(t *testing.T) {
+	var out bytes.Buffer
+	bar := NewBar(&out, 100, "导出", WithBarWidth(10), WithTerminal(true), WithInterval(0))
+	now := bar.start
+	bar.now = func() time.Time { return now }
+
+	now = now.Add(time.Second)
+	bar.Add(50)
+	if got := out.String(); got != "\r导出 [=====>    ]  50% 50/100 50/s eta 1s" {
+		t.Errorf("draw = %q", got)
+	}
+	out.Reset()
+	now = now.Add(time.Second)
+	_, _ = bar.Write(make([]byte, 50))
+	bar.Finish()
+	bar.Finish()
+	if got := out.String(); !strings.HasSuffix(got, "\r导出 [==========] 100% 100/100 50/s 2s\n") {
+		t.Errorf("finish = %q", got)
+	}
+
+	out.Reset()
+	plain := NewBar(&out, 0, "", WithBytes())
+	plain.Add(2048)
+	plain.Finish()
+	if got := out.String(); !strings.HasPrefix(got, "2 KB ") || strings.Count(got, "\n") != 1 {
+		t.Errorf("non-terminal output = %q", got)
+	}
+}
+
+func TestSpinner(t *testing.T) {
+	var out bytes.Buffer
+	sp := NewSpinner(&out, "部署", WithTerminal(true), WithInterval(time.Millisecond))
+	sp.Start()
+	time.Sleep(10 * time.Millisecond)
+	sp.Stop("完成")
+	if got := out.String(); !strings.Contains(got, "⠋ 部署") || !strings.HasSuffix(got, "完成  \n") {
+		t.Errorf("spinner output = %q", got)
+	}
+
+	out.Reset()
+	sp = NewSpinner(&out, "部署")
+	sp.Start()
+	sp.Stop("完成")
+	if got := out.String(); got != "部署...\n完成\n" {
+		t.Errorf("non-terminal output = %q", got)
+	}
+}
@@ -0,0 +1,302 @@
+package cliutil
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ChangSZ/golib/humanize"
+)
+
+// ProgressOption Bar和Spinner的可选参数
+type ProgressOption func(*progressConfig)
+
+type progressConfig struct {
+	width    int
+	bytes    bool
+	terminal bool
+	interval time.Duration
+}
+
+// WithBarWidth 进度条的宽度(列数), 默认30
+func WithBarWidth(n int) ProgressOption {
+	return func(c *progressConfig) {
+		c.width = n
+	}
+}
+
+// WithBytes 进度按字节数显示, 如"1.5 MB/10 MB 2 MB/s"
+func WithBytes() ProgressOption {
+	return func(c *progressConfig) {
+		c.bytes = true
+	}
+}
+
+// WithTerminal 指定输出是否为终端, 默认按输出是否为字符设备判断
+//
+// 终端中原地刷新; 否则(如重定向到文件、CI日志)只在开始和结束时各输出一行
+func WithTerminal(terminal bool) ProgressOption {
+	return func(c *progressConfig) {
+		c.terminal = terminal
+	}
+}
+
+// WithInterval 刷新间隔, 默认100ms
+func WithInterval(d time.Duration) ProgressOption {
+	return func(c *progressConfig) {
+		c.interval = d
+	}
+}
+
+func newProgressConfig(w io.Writer, opts []ProgressOption) progressConfig {
+	c := progressConfig{width: 30, terminal: isTerminal(w), interval: 100 * time.Millisecond}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Bar 进度条, 可以并发调用; 实现了io.Writer, 可用于统计io.Copy等写入的字节数
+//
+//	bar := cliutil.NewBar(os.Stderr, size, "下载", cliutil.WithBytes())
+//	_, err := io.Copy(io.MultiWriter(f, bar), resp.Body)
+//	bar.Finish()
+type Bar struct {
+	mu       sync.Mutex
+	w        io.Writer
+	cfg      progressConfig
+	desc     string
+	total    int64
+	current  int64
+	start    time.Time
+	lastDraw time.Time
+	lastLen  int
+	done     bool
+	now      func() time.Time
+}
+
+// NewBar new a Bar, total小于等于0时表示总量未知, 只显示已完成的数量和速率
+func NewBar(w io.Writer, total int64, desc string, opts ...ProgressOption) *Bar {
+	return &Bar{
+		w:     w,
+		cfg:   newProgressConfig(w, opts),
+		desc:  desc,
+		total: total,
+		start: time.Now(),
+		now:   time.Now,
+	}
+}
+
+// Add 增加进度
+func (b *Bar) Add(n int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current += n
+	b.draw(false)
+}
+
+// Set 设置当前进度
+func (b *Bar) Set(n int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current = n
+	b.draw(false)
+}
+
+// SetTotal 修改总量, 如下载时从响应头得知大小
+func (b *Bar) SetTotal(total int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.total = total
+}
+
+func (b *Bar) Write(p []byte) (int, error) {
+	b.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// Finish 输出最终状态并换行, 重复调用无效
+func (b *Bar) Finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		return
+	}
+	b.draw(true)
+	b.done = true
+}
+
+// draw 终端中按间隔原地刷新, 非终端只输出最终状态, 调用方需持有锁
+func (b *Bar) draw(final bool) {
+	if b.done {
+		return
+	}
+	now := b.now()
+	if !final && (!b.cfg.terminal || now.Sub(b.lastDraw) < b.cfg.interval) {
+		return
+	}
+	b.lastDraw = now
+	line := b.line(now)
+	if !b.cfg.terminal {
+		fmt.Fprintln(b.w, line)
+		return
+	}
+	// 新的一行比上一次短时用空格覆盖残留的字符
+	pad := max(b.lastLen-Width(line), 0)
+	b.lastLen = Width(line)
+	fmt.Fprint(b.w, "\r"+line+strings.Repeat(" ", pad))
+	if final {
+		fmt.Fprintln(b.w)
+	}
+}
+
+func (b *Bar) line(now time.Time) string {
+	elapsed := now.Sub(b.start)
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(b.current) / elapsed.Seconds()
+	}
+
+	var parts []string
+	if b.desc != "" {
+		parts = append(parts, b.desc)
+	}
+	if b.total > 0 {
+		ratio := min(float64(b.current)/float64(b.total), 1)
+		filled := int(ratio * float64(b.cfg.width))
+		bar := strings.Repeat("=", filled)
+		if filled < b.cfg.width {
+			bar += ">" + strings.Repeat(" ", b.cfg.width-filled-1)
+		}
+		parts = append(parts, "["+bar+"]", fmt.Sprintf("%3d%%", int(ratio*100)),
+			b.amount(b.current)+"/"+b.amount(b.total))
+	} else {
+		parts = append(parts, b.amount(b.current))
+	}
+	parts = append(parts, b.amount(int64(rate))+"/s")
+	if b.total > 0 && rate > 0 && b.current < b.total {
+		eta := time.Duration(float64(b.total-b.current) / rate * float64(time.Second))
+		parts = append(parts, "eta "+humanize.Duration(eta.Round(time.Second)))
+	} else if b.current >= b.total && b.total > 0 {
+		parts = append(parts, humanize.Duration(elapsed.Round(time.Second)))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (b *Bar) amount(n int64) string {
+	if b.cfg.bytes {
+		return humanize.Bytes(uint64(max(n, 0)))
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Spinner 总量未知的耗时操作的等待提示
+//
+//	sp := cliutil.NewSpinner(os.Stderr, "正在部署")
+//	sp.Start()
+//	err := deploy()
+//	sp.Stop("部署完成")
+type Spinner struct {
+	mu      sync.Mutex
+	w       io.Writer
+	cfg     progressConfig
+	msg     string
+	lastLen int
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// NewSpinner new a Spinner.
+func NewSpinner(w io.Writer, msg string, opts ...ProgressOption) *Spinner {
+	return &Spinner{w: w, cfg: newProgressConfig(w, opts), msg: msg}
+}
+
+// Start 开始刷新, 非终端时只输出一次消息
+func (s *Spinner) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.stop, s.stopped = make(chan struct{}), make(chan struct{})
+	if !s.cfg.terminal {
+		fmt.Fprintln(s.w, s.msg+"...")
+		close(s.stopped)
+		return
+	}
+	go s.loop()
+}
+
+func (s *Spinner) loop() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(s.cfg.interval)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		s.mu.Lock()
+		s.print(spinnerFrames[i%len(spinnerFrames)] + " " + s.msg)
+		s.mu.Unlock()
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// print 原地覆盖当前行, 调用方需持有锁
+func (s *Spinner) print(line string) {
+	pad := max(s.lastLen-Width(line), 0)
+	s.lastLen = Width(line)
+	fmt.Fprint(s.w, "\r"+line+strings.Repeat(" ", pad))
+}
+
+// SetMessage 修改提示信息
+func (s *Spinner) SetMessage(msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msg = msg
+}
+
+// Stop 停止刷新并输出final, final为空时清除提示行
+func (s *Spinner) Stop(final string) {
+	s.mu.Lock()
+	if s.stop == nil {
+		s.mu.Unlock()
+		return
+	}
+	close(s.stop)
+	stopped := s.stopped
+	s.mu.Unlock()
+	<-stopped
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stop = nil
+	if !s.cfg.terminal {
+		if final != "" {
+			fmt.Fprintln(s.w, final)
+		}
+		return
+	}
+	s.print(final)
+	if final != "" {
+		fmt.Fprintln(s.w)
+	} else {
+		fmt.Fprint(s.w, "\r")
+	}
+}
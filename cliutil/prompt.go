@@ -0,0 +1,126 @@
+package cliutil
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrNoInput 输入已结束(如Ctrl+D或管道读完), 无法继续询问
+var ErrNoInput = errors.New("cliutil: no more input")
+
+// Prompter 交互式询问, 从in逐行读取回答
+//
+//	p := cliutil.NewPrompter(os.Stdin, os.Stderr)
+//	if ok, _ := p.Confirm("确认删除?", false); !ok {
+//		return nil
+//	}
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewPrompter new a Prompter.
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewReader(in), out: out}
+}
+
+// DefaultPrompter 使用标准输入和标准错误, 提示不会混入标准输出中的数据
+func DefaultPrompter() *Prompter {
+	return NewPrompter(os.Stdin, os.Stderr)
+}
+
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			_, _ = fmt.Fprintln(p.out)
+			return "", ErrNoInput
+		}
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Confirm 询问是否确认, 直接回车时返回def, 回答无法识别时重新询问
+func (p *Prompter) Confirm(question string, def bool) (bool, error) {
+	hint := "[y/N]"
+	if def {
+		hint = "[Y/n]"
+	}
+	for {
+		fmt.Fprintf(p.out, "%s %s ", question, hint)
+		line, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(line) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "请输入 y 或 n")
+	}
+}
+
+// Input 询问输入, 直接回车时返回def; validate不为nil时校验失败会输出原因并重新询问
+func (p *Prompter) Input(question, def string, validate func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s (%s): ", question, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+		line, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		if line == "" {
+			line = def
+		}
+		if validate == nil {
+			return line, nil
+		}
+		if err := validate(line); err != nil {
+			fmt.Fprintf(p.out, "%v\n", err)
+			continue
+		}
+		return line, nil
+	}
+}
+
+// Select 从选项中选择一项, 按序号作答, 返回选项下标; def小于0表示没有默认值
+func (p *Prompter) Select(question string, options []string, def int) (int, error) {
+	if len(options) == 0 {
+		return -1, errors.New("cliutil: select without options")
+	}
+	fmt.Fprintln(p.out, question)
+	for i, opt := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, opt)
+	}
+	for {
+		if def >= 0 && def < len(options) {
+			fmt.Fprintf(p.out, "请选择 [1-%d] (%d): ", len(options), def+1)
+		} else {
+			fmt.Fprintf(p.out, "请选择 [1-%d]: ", len(options))
+		}
+		line, err := p.readLine()
+		if err != nil {
+			return -1, err
+		}
+		if line == "" && def >= 0 && def < len(options) {
+			return def, nil
+		}
+		if n, err := strconv.Atoi(line); err == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		fmt.Fprintf(p.out, "请输入 1 到 %d 之间的序号\n", len(options))
+	}
+}
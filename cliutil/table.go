@@ -0,0 +1,128 @@
+package cliutil
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Align 列的对齐方式
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignRight
+)
+
+// Table 按终端列宽对齐的表格, 中文等宽字符按两列计算, 忽略颜色转义序列
+//
+//	t := cliutil.NewTable("ID", "名称", "大小")
+//	t.SetAlign(2, cliutil.AlignRight)
+//	t.Append(1, "日志", humanize.Bytes(1536))
+//	t.Render(os.Stdout)
+type Table struct {
+	headers  []string
+	rows     [][]string
+	aligns   map[int]Align
+	maxWidth map[int]int
+}
+
+// NewTable new a Table.
+func NewTable(headers ...string) *Table {
+	return &Table{headers: headers}
+}
+
+// SetAlign 设置第col列(从0开始)的对齐方式, 默认左对齐
+func (t *Table) SetAlign(col int, align Align) *Table {
+	if t.aligns == nil {
+		t.aligns = make(map[int]Align)
+	}
+	t.aligns[col] = align
+	return t
+}
+
+// SetMaxWidth 第col列超过width列时截断
+func (t *Table) SetMaxWidth(col, width int) *Table {
+	if t.maxWidth == nil {
+		t.maxWidth = make(map[int]int)
+	}
+	t.maxWidth[col] = width
+	return t
+}
+
+// Append 追加一行, 值使用fmt.Sprint格式化
+func (t *Table) Append(cells ...interface{}) {
+	row := make([]string, len(cells))
+	for i, c := range cells {
+		row[i] = fmt.Sprint(c)
+	}
+	t.rows = append(t.rows, row)
+}
+
+// Len 行数, 不含表头
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+// Render 输出表格, 列之间以两个空格分隔, 表头下方有分隔线
+func (t *Table) Render(w io.Writer) error {
+	_, err := io.WriteString(w, t.String())
+	return err
+}
+
+func (t *Table) String() string {
+	cols := len(t.headers)
+	for _, row := range t.rows {
+		cols = max(cols, len(row))
+	}
+	if cols == 0 {
+		return ""
+	}
+
+	cell := func(row []string, i int) string {
+		if i >= len(row) {
+			return ""
+		}
+		s := strings.ReplaceAll(row[i], "\n", " ")
+		if mw, ok := t.maxWidth[i]; ok {
+			s = Truncate(s, mw)
+		}
+		return s
+	}
+	widths := make([]int, cols)
+	for _, row := range append([][]string{t.headers}, t.rows...) {
+		for i := 0; i < cols; i++ {
+			widths[i] = max(widths[i], Width(cell(row, i)))
+		}
+	}
+
+	var b strings.Builder
+	writeRow := func(row []string) {
+		var line strings.Builder
+		for i := 0; i < cols; i++ {
+			if i > 0 {
+				line.WriteString("  ")
+			}
+			if t.aligns[i] == AlignRight {
+				line.WriteString(PadLeft(cell(row, i), widths[i]))
+			} else {
+				line.WriteString(PadRight(cell(row, i), widths[i]))
+			}
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+	if len(t.headers) > 0 {
+		writeRow(t.headers)
+		sep := make([]string, cols)
+		for i, w := range widths {
+			sep[i] = strings.Repeat("-", w)
+		}
+		b.WriteString(strings.Join(sep, "  "))
+		b.WriteByte('\n')
+	}
+	for _, row := range t.rows {
+		writeRow(row)
+	}
+	return b.String()
+}
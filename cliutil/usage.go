@@ -0,0 +1,96 @@
+package cliutil
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// usageText 生成帮助信息:
+//
+//	Usage: tool serve [flags] [args]
+//
+//	启动服务
+//
+//	Flags:
+//	  -a, --addr string       监听地址 (default ":8080")
+//	      --dsn string        数据库连接 (required) [$DB_DSN]
+func usageText(c *Command, opts []*option) string {
+	var b strings.Builder
+	b.WriteString("Usage: " + c.Name())
+	if len(opts) > 0 {
+		b.WriteString(" [flags]")
+	}
+	if len(c.subs) > 0 {
+		b.WriteString(" <command>")
+	}
+	b.WriteString(" [args]\n")
+	if c.usage != "" {
+		b.WriteString("\n" + c.usage + "\n")
+	}
+
+	if len(c.subs) > 0 {
+		b.WriteString("\nCommands:\n")
+		tw := tabwriter.NewWriter(&b, 0, 4, 3, ' ', 0)
+		for _, sub := range c.subs {
+			fmt.Fprintf(tw, "  %s\t%s\n", sub.name, sub.usage)
+		}
+		_ = tw.Flush()
+	}
+
+	if len(opts) > 0 {
+		b.WriteString("\nFlags:\n")
+		tw := tabwriter.NewWriter(&b, 0, 4, 3, ' ', 0)
+		for _, o := range opts {
+			names := "    --" + o.name
+			if o.short != "" {
+				names = "-" + o.short + ", --" + o.name
+			}
+			if t := typeName(o.value.v.Type()); t != "" {
+				names += " " + t
+			}
+			desc := o.usage
+			if o.def != "" {
+				desc += fmt.Sprintf(" (default %q)", o.def)
+			}
+			if o.required {
+				desc += " (required)"
+			}
+			if o.env != "" {
+				desc += " [$" + o.env + "]"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\n", names, strings.TrimSpace(desc))
+		}
+		_ = tw.Flush()
+	}
+	if len(c.subs) > 0 {
+		fmt.Fprintf(&b, "\nRun '%s help <command>' for more information on a command.\n", c.Name())
+	}
+	return b.String()
+}
+
+// typeName flag值的类型提示, bool不需要值因此为空
+func typeName(t reflect.Type) string {
+	switch {
+	case t.Kind() == reflect.Bool:
+		return ""
+	case t == timeType:
+		return "time"
+	case t.String() == "time.Duration":
+		return "duration"
+	case t.Kind() == reflect.Slice:
+		return typeName(t.Elem()) + "s"
+	case t.Kind() == reflect.Ptr:
+		return typeName(t.Elem())
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	}
+	return "string"
+}
@@ -0,0 +1,109 @@
+package cliutil
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// wideRanges 东亚宽字符(East Asian Wide/Fullwidth)与常见emoji的码点范围, 终端中占两列
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115F},   // 韩文字母
+	{0x2E80, 0x303E},   // CJK部首、标点
+	{0x3041, 0x33FF},   // 日文假名、CJK符号
+	{0x3400, 0x4DBF},   // CJK扩展A
+	{0x4E00, 0x9FFF},   // CJK统一汉字
+	{0xA000, 0xA4CF},   // 彝文
+	{0xAC00, 0xD7A3},   // 韩文音节
+	{0xF900, 0xFAFF},   // CJK兼容汉字
+	{0xFE30, 0xFE4F},   // CJK兼容形式
+	{0xFF00, 0xFF60},   // 全角ASCII、全角标点
+	{0xFFE0, 0xFFE6},   // 全角符号
+	{0x1F300, 0x1F64F}, // emoji
+	{0x1F900, 0x1F9FF}, // emoji
+	{0x20000, 0x3FFFD}, // CJK扩展B及以后
+}
+
+// RuneWidth 字符在终端中占用的列数, 控制字符和组合字符为0, 宽字符为2
+func RuneWidth(r rune) int {
+	switch {
+	case r == 0 || r < 32 || (r >= 0x7F && r < 0xA0):
+		return 0
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || r == 0x200B:
+		return 0
+	case r < 0x1100:
+		return 1
+	}
+	for _, rg := range wideRanges {
+		if r >= rg.lo && r <= rg.hi {
+			return 2
+		}
+	}
+	return 1
+}
+
+// Width 字符串在终端中占用的列数, 忽略ANSI颜色等转义序列, 如color包的输出
+func Width(s string) int {
+	n := 0
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b {
+			i += escapeLen(s[i:])
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		n += RuneWidth(r)
+		i += size
+	}
+	return n
+}
+
+// escapeLen 以ESC开头的CSI序列(如"\x1b[31m")的长度
+func escapeLen(s string) int {
+	if len(s) < 2 || s[1] != '[' {
+		return 1
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7E {
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// PadRight 在右侧补空格到width列
+func PadRight(s string, width int) string {
+	if n := width - Width(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// PadLeft 在左侧补空格到width列
+func PadLeft(s string, width int) string {
+	if n := width - Width(s); n > 0 {
+		return strings.Repeat(" ", n) + s
+	}
+	return s
+}
+
+// Truncate 截断到不超过width列, 被截断时以"..."结尾
+func Truncate(s string, width int) string {
+	if Width(s) <= width {
+		return s
+	}
+	const tail = "..."
+	if width <= len(tail) {
+		return tail[:max(width, 0)]
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		w := RuneWidth(r)
+		if n+w > width-len(tail) {
+			break
+		}
+		b.WriteRune(r)
+		n += w
+	}
+	return b.String() + tail
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGenerateExample 生成结果与提交的internal/example/user_to_user_dto_gen.go一致, 修改生成器后需重新生成
+func TestGenerateExample(t *testing.T) {
+	dir := filepath.Join("internal", "example")
+	const output = "user_to_user_dto_gen.go"
+	got, err := generate(dir, "User", "UserDTO", output, "AssignUserToUserDTO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join(dir, output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("generated code is stale, run go generate ./cmd/copygen/internal/example\n%s", got)
+	}
+}
+
+func TestGenerateError(t *testing.T) {
+	dir := t.TempDir()
+	src := `package p
+
+type Inner struct{ A int }
+
+type From struct {
+	Inner Inner
+	Ch    chan int
+}
+
+type To struct {
+	Inner string
+	Ch    chan string
+}
+
+type Ok struct{ A int }
+
+type Name string
+`
+	if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		src, dst string
+		wantErr  string
+	}{
+		{name: "struct to string", src: "From", dst: "To", wantErr: "field Inner: unsupported"},
+		{name: "unknown type", src: "Missing", dst: "Ok", wantErr: "not found"},
+		{name: "not struct", src: "Ok", dst: "Name", wantErr: "is not a struct"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := generate(dir, tt.src, tt.dst, "gen.go", "Assign")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("generate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	_, err := generate(dir, "From", "To", "gen.go", "Assign")
+	if !errors.Is(err, errUnsupported) {
+		t.Errorf("generate() error = %v, want errUnsupported", err)
+	}
+}
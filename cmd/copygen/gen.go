@@ -0,0 +1,481 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	gcopy "github.com/ChangSZ/golib/copy"
+	"github.com/ChangSZ/golib/tagparse"
+)
+
+// loadPackage 解析并类型检查dir中的包, exclude为要忽略的文件名(通常是上一次生成的文件, 避免其编译错误影响生成)
+func loadPackage(dir, exclude string) (*types.Package, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		if name == exclude {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	var firstErr error
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error: func(err error) {
+			if firstErr == nil {
+				firstErr = err
+			}
+		},
+	}
+	pkg, _ := conf.Check(bp.ImportPath, fset, files, nil)
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return pkg, nil
+}
+
+// helper 一对结构体类型的赋值函数
+type helper struct {
+	name     string
+	src, dst types.Type
+	doc      string
+	body     bytes.Buffer
+}
+
+// generator 为结构体类型对生成与copy.AssignStruct默认行为一致的赋值函数
+type generator struct {
+	pkg     *types.Package
+	imports map[string]string // path -> name
+	helpers []*helper
+	seq     int
+}
+
+func newGenerator(pkg *types.Package) *generator {
+	return &generator{pkg: pkg, imports: make(map[string]string)}
+}
+
+// lookupStruct 查找包中名为name的结构体类型
+func (g *generator) lookupStruct(name string) (types.Type, error) {
+	obj, ok := g.pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s not found in package %s", name, g.pkg.Path())
+	}
+	if _, ok := obj.Type().Underlying().(*types.Struct); !ok {
+		return nil, fmt.Errorf("type %s is not a struct", name)
+	}
+	return obj.Type(), nil
+}
+
+// generate 生成名为funcName的函数, 将src赋值到dst
+func (g *generator) generate(src, dst types.Type, funcName string) ([]byte, error) {
+	doc := fmt.Sprintf("// %s 将src中有值的字段赋值到dst中, 与copy.AssignStruct(src, dst)的默认行为一致, 不使用反射", funcName)
+	if _, err := g.helperFor(src, dst, funcName, doc); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by copygen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", g.pkg.Name())
+	if len(g.imports) > 0 {
+		paths := make([]string, 0, len(g.imports))
+		for path := range g.imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		out.WriteString("import (\n")
+		for _, path := range paths {
+			fmt.Fprintf(&out, "%q\n", path)
+		}
+		out.WriteString(")\n\n")
+	}
+	for _, h := range g.helpers {
+		if h.doc != "" {
+			out.WriteString(h.doc + "\n")
+		}
+		fmt.Fprintf(&out, "func %s(src *%s, dst *%s) {\n", h.name, g.typeString(h.src), g.typeString(h.dst))
+		out.Write(h.body.Bytes())
+		out.WriteString("}\n\n")
+	}
+	b, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w\n%s", err, out.Bytes())
+	}
+	return b, nil
+}
+
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if p == g.pkg {
+			return ""
+		}
+		g.imports[p.Path()] = p.Name()
+		return p.Name()
+	})
+}
+
+// tmp 生成不重复的局部变量名
+func (g *generator) tmp(base string) string {
+	g.seq++
+	return fmt.Sprintf("%s%d", base, g.seq)
+}
+
+// helperFor 返回结构体类型对的赋值函数名, 第一次遇到时生成; 递归类型通过函数调用自然终止
+func (g *generator) helperFor(src, dst types.Type, name, doc string) (string, error) {
+	for _, h := range g.helpers {
+		if types.Identical(h.src, src) && types.Identical(h.dst, dst) {
+			return h.name, nil
+		}
+	}
+	if name == "" {
+		name = g.helperName(src, dst)
+	}
+	h := &helper{name: name, src: src, dst: dst, doc: doc}
+	g.helpers = append(g.helpers, h)
+	if err := g.structFields(&h.body, src, dst); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func (g *generator) helperName(src, dst types.Type) string {
+	base := "assign" + typeName(src) + "To" + typeName(dst)
+	name := base
+	for i := 2; g.hasHelper(name); i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	return name
+}
+
+func (g *generator) hasHelper(name string) bool {
+	for _, h := range g.helpers {
+		if h.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func typeName(t types.Type) string {
+	if n, ok := t.(*types.Named); ok {
+		name := n.Obj().Name()
+		return strings.ToUpper(name[:1]) + name[1:]
+	}
+	return "Anon"
+}
+
+// structFields 对应copy.assignStructFields: 按src的字段逐个赋值, copy标签指定dst字段名或跳过
+func (g *generator) structFields(w *bytes.Buffer, src, dst types.Type) error {
+	st := src.Underlying().(*types.Struct)
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := tagparse.Parse(reflect.StructTag(st.Tag(i)).Get(gcopy.TagName))
+		if tag.Skip {
+			continue
+		}
+		dstName := tag.NameOr(field.Name())
+		srcExpr := "src." + field.Name()
+
+		obj, index, _ := types.LookupFieldOrMethod(dst, true, g.pkg, dstName)
+		dstField, ok := obj.(*types.Var)
+		if !ok || !dstField.IsField() {
+			// 内嵌结构体在dst中不存在时, 将其字段展开赋值到dst
+			if field.Anonymous() {
+				if _, ok := field.Type().Underlying().(*types.Struct); ok {
+					name, err := g.helperFor(field.Type(), dst, "", "")
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(w, "%s(&%s, dst)\n", name, srcExpr)
+				}
+			}
+			continue
+		}
+		if tagparse.Parse(reflect.StructTag(fieldTag(dst, index)).Get(gcopy.TagName)).Skip {
+			continue
+		}
+		if !field.Exported() || !dstField.Exported() {
+			fmt.Fprintf(w, "// %s: 未导出字段, copy.AssignStruct无法赋值, 跳过\n", field.Name())
+			continue
+		}
+
+		var body bytes.Buffer
+		cond := g.notZero(srcExpr, field.Type())
+		nonNil := strings.HasPrefix(cond, srcExpr+" != nil")
+		if err := g.assign(&body, srcExpr, "dst."+dstName, field.Type(), dstField.Type(), nonNil); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name(), err)
+		}
+		if body.Len() == 0 {
+			continue
+		}
+		if cond != "" {
+			fmt.Fprintf(w, "if %s {\n%s}\n", cond, body.Bytes())
+		} else {
+			w.Write(body.Bytes())
+		}
+	}
+	return nil
+}
+
+// fieldTag 按LookupFieldOrMethod返回的下标路径找到字段所在的结构体并返回字段标签
+func fieldTag(t types.Type, index []int) string {
+	for i, idx := range index {
+		if p, ok := t.Underlying().(*types.Pointer); ok {
+			t = p.Elem()
+		}
+		st := t.Underlying().(*types.Struct)
+		if i == len(index)-1 {
+			return st.Tag(idx)
+		}
+		t = st.Field(idx).Type()
+	}
+	return ""
+}
+
+// notZero 对应reflectutil.IsZero的非零判断表达式, 为空时不需要判断
+//
+// 类型有IsZero() bool方法时使用该方法(如time.Time); 不可比较的结构体不判断,
+// 其字段在赋值时会逐个跳过零值
+func (g *generator) notZero(expr string, t types.Type) string {
+	var conds []string
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		conds = append(conds, expr+" != nil")
+	}
+	if hasIsZero(t) {
+		return strings.Join(append(conds, "!"+expr+".IsZero()"), " && ")
+	}
+	if len(conds) > 0 {
+		return conds[0]
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return expr
+		case u.Info()&types.IsString != 0:
+			return expr + ` != ""`
+		case u.Info()&types.IsNumeric != 0:
+			return expr + " != 0"
+		case u.Kind() == types.UnsafePointer:
+			return expr + " != nil"
+		}
+	case *types.Struct, *types.Array:
+		if types.Comparable(t) {
+			return fmt.Sprintf("%s != (%s{})", expr, g.typeString(t))
+		}
+	}
+	return ""
+}
+
+func hasIsZero(t types.Type) bool {
+	sel := types.NewMethodSet(t).Lookup(nil, "IsZero")
+	if sel == nil {
+		return false
+	}
+	sig, ok := sel.Type().(*types.Signature)
+	if !ok || sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return false
+	}
+	b, ok := sig.Results().At(0).Type().(*types.Basic)
+	return ok && b.Kind() == types.Bool
+}
+
+var errUnsupported = errors.New("unsupported")
+
+// assign 对应copy.assignValue, 生成将src表达式赋值到dst表达式的语句; 类型不兼容时不生成任何语句.
+// nonNil表示调用方已经判断过src不为nil
+func (g *generator) assign(w *bytes.Buffer, src, dst string, st, dt types.Type, nonNil bool) error {
+	identical := types.Identical(st, dt)
+
+	// 类型不同时指针与值自动桥接
+	if sp, ok := st.Underlying().(*types.Pointer); ok && !identical {
+		var body bytes.Buffer
+		if err := g.assign(&body, "(*"+src+")", dst, sp.Elem(), dt, false); err != nil {
+			return err
+		}
+		if nonNil {
+			w.Write(body.Bytes())
+		} else if body.Len() > 0 {
+			fmt.Fprintf(w, "if %s != nil {\n%s}\n", src, body.Bytes())
+		}
+		return nil
+	}
+	if dp, ok := dt.Underlying().(*types.Pointer); ok && !identical {
+		v := g.tmp("p")
+		var body bytes.Buffer
+		if err := g.assign(&body, src, "(*"+v+")", st, dp.Elem(), nonNil); err != nil {
+			return err
+		}
+		if body.Len() == 0 {
+			return nil
+		}
+		// 在副本上合并, 不修改dst原来指向的对象
+		fmt.Fprintf(w, "%s := new(%s)\nif %s != nil {\n*%s = *%s\n}\n%s%s = %s\n",
+			v, g.typeString(dp.Elem()), dst, v, dst, body.Bytes(), dst, v)
+		return nil
+	}
+
+	if isTime(st) {
+		if isTime(dt) {
+			fmt.Fprintf(w, "%s = %s\n", plain(dst), plain(src))
+		}
+		return nil
+	}
+
+	switch su := st.Underlying().(type) {
+	case *types.Struct:
+		if _, ok := dt.Underlying().(*types.Struct); !ok {
+			return fmt.Errorf("%w: struct %s to %s", errUnsupported, g.typeString(st), g.typeString(dt))
+		}
+		name, err := g.helperFor(st, dt, "", "")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s(%s, %s)\n", name, addr(src), addr(dst))
+		return nil
+
+	case *types.Slice:
+		du, ok := dt.Underlying().(*types.Slice)
+		if !ok {
+			return nil
+		}
+		_, srcElemStruct := su.Elem().Underlying().(*types.Struct)
+		_, dstElemStruct := du.Elem().Underlying().(*types.Struct)
+		if srcElemStruct && dstElemStruct {
+			// 结构体切片按元素赋值, 长度不一致时分配新的切片, 保留dst中已有的元素作为合并的基础
+			name, err := g.helperFor(su.Elem(), du.Elem(), "", "")
+			if err != nil {
+				return err
+			}
+			grown, i := g.tmp("grown"), g.tmp("i")
+			fmt.Fprintf(w, "if len(%s) != len(%s) {\n%s := make(%s, len(%s))\ncopy(%s, %s)\n%s = %s\n}\n",
+				dst, src, grown, g.typeString(dt), src, grown, dst, dst, grown)
+			fmt.Fprintf(w, "for %s := range %s {\n%s(&%s[%s], &%s[%s])\n}\n", i, src, name, src, i, dst, i)
+			return nil
+		}
+		return g.set(w, src, dst, st, dt)
+
+	case *types.Map:
+		du, ok := dt.Underlying().(*types.Map)
+		if !ok {
+			return nil
+		}
+		if !types.ConvertibleTo(su.Key(), du.Key()) {
+			return nil
+		}
+		// 分配新的map, 先复制dst原有的元素再合并src的元素
+		merged, k, v, elem := g.tmp("merged"), g.tmp("k"), g.tmp("v"), g.tmp("elem")
+		key := k
+		if !types.Identical(su.Key(), du.Key()) {
+			key = fmt.Sprintf("%s(%s)", g.typeString(du.Key()), k)
+		}
+		var body bytes.Buffer
+		if isInterface(su.Elem()) && isInterface(du.Elem()) {
+			fmt.Fprintf(&body, "%s = %s\n", elem, v)
+		} else if err := g.assign(&body, v, elem, su.Elem(), du.Elem(), false); err != nil {
+			return err
+		}
+		if !nonNil {
+			fmt.Fprintf(w, "if %s != nil {\n", src)
+		}
+		fmt.Fprintf(w, "%s := make(%s, len(%s)+len(%s))\n", merged, g.typeString(dt), src, dst)
+		fmt.Fprintf(w, "for %s, %s := range %s {\n%s[%s] = %s\n}\n", k, v, dst, merged, k, v)
+		fmt.Fprintf(w, "for %s, %s := range %s {\nvar %s %s\n%s%s[%s] = %s\n}\n",
+			k, v, src, elem, g.typeString(du.Elem()), body.Bytes(), merged, key, elem)
+		fmt.Fprintf(w, "%s = %s\n", plain(dst), merged)
+		if !nonNil {
+			w.WriteString("}\n")
+		}
+		return nil
+	}
+
+	// 数值类型不同时按Go的转换规则转换
+	if isNumber(st) && isNumber(dt) {
+		if identical {
+			fmt.Fprintf(w, "%s = %s\n", plain(dst), plain(src))
+		} else {
+			fmt.Fprintf(w, "%s = %s(%s)\n", plain(dst), g.typeString(dt), plain(src))
+		}
+		return nil
+	}
+	if kind(st) == kind(dt) {
+		return g.set(w, src, dst, st, dt)
+	}
+	return nil
+}
+
+// set 对应reflect.Value.Set: 类型不同但可转换时先转换(如type UserID string), 不可赋值时报错
+func (g *generator) set(w *bytes.Buffer, src, dst string, st, dt types.Type) error {
+	switch {
+	case types.Identical(st, dt):
+		fmt.Fprintf(w, "%s = %s\n", plain(dst), plain(src))
+	case types.ConvertibleTo(st, dt):
+		fmt.Fprintf(w, "%s = %s(%s)\n", plain(dst), g.typeString(dt), plain(src))
+	default:
+		return fmt.Errorf("%w: cannot assign %s to %s", errUnsupported, g.typeString(st), g.typeString(dt))
+	}
+	return nil
+}
+
+// plain 去掉解引用表达式外层的括号, 用于单独作为赋值语句一侧的表达式, 如(*p) -> *p
+func plain(expr string) string {
+	if strings.HasPrefix(expr, "(*") && strings.HasSuffix(expr, ")") {
+		return expr[1 : len(expr)-1]
+	}
+	return expr
+}
+
+// addr 取地址表达式, 解引用表达式直接返回指针, 如(*p) -> p
+func addr(expr string) string {
+	if strings.HasPrefix(expr, "(*") && strings.HasSuffix(expr, ")") {
+		return expr[2 : len(expr)-1]
+	}
+	return "&" + expr
+}
+
+func isTime(t types.Type) bool {
+	n, ok := t.(*types.Named)
+	return ok && n.Obj().Pkg() != nil && n.Obj().Pkg().Path() == "time" && n.Obj().Name() == "Time"
+}
+
+func isInterface(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Interface)
+	return ok
+}
+
+func isNumber(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&(types.IsInteger|types.IsFloat) != 0
+}
+
+// kind 对应reflect.Kind, 只用于判断两个类型的种类是否相同
+func kind(t types.Type) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Name()
+	default:
+		return fmt.Sprintf("%T", u)
+	}
+}
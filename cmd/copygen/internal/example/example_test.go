@@ -0,0 +1,92 @@
+package example
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ChangSZ/golib/copy"
+)
+
+func newUser() *User {
+	zip, nick := "100000", "nick"
+	return &User{
+		Base:     Base{ID: 1, Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		Name:     "a",
+		Password: "secret",
+		Age:      20,
+		Score:    1.5,
+		Active:   true,
+		Owner:    "o",
+		Tags:     []string{"x"},
+		Home:     Address{City: "bj", Zip: &zip},
+		Work:     &Address{City: "sh"},
+		Previous: []Address{{City: "gz"}, {Zip: &zip}},
+		Labels:   map[string]int32{"a": 1},
+		Extra:    map[string]interface{}{"k": "v"},
+		Nickname: &nick,
+		Version:  3,
+		secret:   "s",
+	}
+}
+
+// TestGenerated 生成的函数与copy.AssignStruct的结果一致
+func TestGenerated(t *testing.T) {
+	version := 1
+	tests := []struct {
+		name string
+		src  func() *User
+		dst  func() *UserDTO
+	}{
+		{name: "full", src: newUser, dst: func() *UserDTO { return &UserDTO{} }},
+		{name: "zero src", src: func() *User { return &User{} }, dst: func() *UserDTO {
+			return &UserDTO{UserName: "keep", Tags: []string{"keep"}}
+		}},
+		{name: "merge", src: newUser, dst: func() *UserDTO {
+			return &UserDTO{
+				Password: "keep",
+				Work:     &AddressDTO{Zip: "keep"},
+				Previous: []AddressDTO{{Zip: "keep"}, {City: "keep"}, {City: "dropped"}},
+				Labels:   map[string]int64{"b": 2},
+				Version:  &version,
+				Updated:  time.Unix(1, 0),
+			}
+		}},
+		{name: "partial", src: func() *User {
+			u := &User{Name: "b", Home: Address{City: "x"}}
+			u.ID = 2
+			return u
+		}, dst: func() *UserDTO { return &UserDTO{Home: AddressDTO{Zip: "keep"}} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.dst()
+			if err := copy.AssignStructE(tt.src(), want); err != nil {
+				t.Fatal(err)
+			}
+			got := tt.dst()
+			AssignUserToUserDTO(tt.src(), got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("AssignUserToUserDTO() = %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func BenchmarkAssign(b *testing.B) {
+	src := newUser()
+	b.Run("reflect", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var dst UserDTO
+			_ = copy.AssignStructE(src, &dst)
+		}
+	})
+	b.Run("generated", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var dst UserDTO
+			AssignUserToUserDTO(src, &dst)
+		}
+	})
+}
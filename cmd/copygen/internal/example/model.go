@@ -0,0 +1,61 @@
+package example
+
+import "time"
+
+//go:generate go run github.com/ChangSZ/golib/cmd/copygen -src User -dst UserDTO
+
+type UserID string
+
+type Base struct {
+	ID      int64
+	Created time.Time
+}
+
+type Address struct {
+	City string
+	Zip  *string
+}
+
+type AddressDTO struct {
+	City string
+	Zip  string
+}
+
+type User struct {
+	Base
+	Name     string `copy:"UserName"`
+	Password string `copy:"-"`
+	Age      int32
+	Score    float64
+	Active   bool
+	Owner    string
+	Tags     []string
+	Home     Address
+	Work     *Address
+	Previous []Address
+	Labels   map[string]int32
+	Extra    map[string]interface{}
+	Nickname *string
+	Version  int
+	secret   string
+}
+
+type UserDTO struct {
+	ID       int64
+	Created  time.Time
+	UserName string
+	Password string
+	Age      int64
+	Score    float32
+	Active   bool
+	Owner    UserID
+	Tags     []string
+	Home     AddressDTO
+	Work     *AddressDTO
+	Previous []AddressDTO
+	Labels   map[string]int64
+	Extra    map[string]interface{}
+	Nickname string
+	Version  *int
+	Updated  time.Time `copy:"-"`
+}
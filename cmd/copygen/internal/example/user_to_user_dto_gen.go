@@ -0,0 +1,100 @@
+// Code generated by copygen. DO NOT EDIT.
+
+package example
+
+// AssignUserToUserDTO 将src中有值的字段赋值到dst中, 与copy.AssignStruct(src, dst)的默认行为一致, 不使用反射
+func AssignUserToUserDTO(src *User, dst *UserDTO) {
+	assignBaseToUserDTO(&src.Base, dst)
+	if src.Name != "" {
+		dst.UserName = src.Name
+	}
+	if src.Age != 0 {
+		dst.Age = int64(src.Age)
+	}
+	if src.Score != 0 {
+		dst.Score = float32(src.Score)
+	}
+	if src.Active {
+		dst.Active = src.Active
+	}
+	if src.Owner != "" {
+		dst.Owner = UserID(src.Owner)
+	}
+	if src.Tags != nil {
+		dst.Tags = src.Tags
+	}
+	if src.Home != (Address{}) {
+		assignAddressToAddressDTO(&src.Home, &dst.Home)
+	}
+	if src.Work != nil {
+		p1 := new(AddressDTO)
+		if dst.Work != nil {
+			*p1 = *dst.Work
+		}
+		assignAddressToAddressDTO(src.Work, p1)
+		dst.Work = p1
+	}
+	if src.Previous != nil {
+		if len(dst.Previous) != len(src.Previous) {
+			grown2 := make([]AddressDTO, len(src.Previous))
+			copy(grown2, dst.Previous)
+			dst.Previous = grown2
+		}
+		for i3 := range src.Previous {
+			assignAddressToAddressDTO(&src.Previous[i3], &dst.Previous[i3])
+		}
+	}
+	if src.Labels != nil {
+		merged4 := make(map[string]int64, len(src.Labels)+len(dst.Labels))
+		for k5, v6 := range dst.Labels {
+			merged4[k5] = v6
+		}
+		for k5, v6 := range src.Labels {
+			var elem7 int64
+			elem7 = int64(v6)
+			merged4[k5] = elem7
+		}
+		dst.Labels = merged4
+	}
+	if src.Extra != nil {
+		merged8 := make(map[string]interface{}, len(src.Extra)+len(dst.Extra))
+		for k9, v10 := range dst.Extra {
+			merged8[k9] = v10
+		}
+		for k9, v10 := range src.Extra {
+			var elem11 interface{}
+			elem11 = v10
+			merged8[k9] = elem11
+		}
+		dst.Extra = merged8
+	}
+	if src.Nickname != nil {
+		dst.Nickname = *src.Nickname
+	}
+	if src.Version != 0 {
+		p12 := new(int)
+		if dst.Version != nil {
+			*p12 = *dst.Version
+		}
+		*p12 = src.Version
+		dst.Version = p12
+	}
+}
+
+func assignBaseToUserDTO(src *Base, dst *UserDTO) {
+	if src.ID != 0 {
+		dst.ID = src.ID
+	}
+	if !src.Created.IsZero() {
+		dst.Created = src.Created
+	}
+}
+
+func assignAddressToAddressDTO(src *Address, dst *AddressDTO) {
+	if src.City != "" {
+		dst.City = src.City
+	}
+	if src.Zip != nil {
+		dst.Zip = *src.Zip
+	}
+}
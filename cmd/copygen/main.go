@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ChangSZ/golib/scanutil"
+)
+
+const usage = `copygen 为两个结构体类型生成不使用反射的赋值函数, 行为与copy.AssignStruct的默认选项一致, 用于高QPS的热点路径
+
+用法(两个类型需在同一个包中):
+
+	//go:generate go run github.com/ChangSZ/golib/cmd/copygen -src User -dst UserDTO
+
+生成的文件默认为{src}_to_{dst}_gen.go, 函数名默认为Assign{Src}To{Dst}(src *Src, dst *Dst).
+支持的规则: 跳过零值、copy标签、内嵌结构体展开、嵌套结构体、结构体切片按元素合并、map合并、
+数值转换、指针与值桥接、底层类型相同的命名类型转换.
+
+不支持AssignStruct的可选参数(WithOverwriteZero、WithIgnoreFields、WithFieldHook等)和
+运行时注册的转换函数(RegisterConverter)、database/sql的Null类型与值类型互转; 遇到AssignStruct会在运行时报错的字段类型时生成失败.
+
+参数:
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	var (
+		src      = flag.String("src", "", "源结构体类型名")
+		dst      = flag.String("dst", "", "目标结构体类型名")
+		dir      = flag.String("dir", ".", "类型所在包的目录")
+		output   = flag.String("o", "", "输出文件名, 默认为{src}_to_{dst}_gen.go")
+		funcName = flag.String("func", "", "生成的函数名, 默认为Assign{Src}To{Dst}")
+	)
+	flag.Parse()
+	if *src == "" || *dst == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*dir, *src, *dst, *output, *funcName); err != nil {
+		fmt.Fprintln(os.Stderr, "copygen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, src, dst, output, funcName string) error {
+	if output == "" {
+		output = scanutil.Snake(src) + "_to_" + scanutil.Snake(dst) + "_gen.go"
+	}
+	if funcName == "" {
+		funcName = "Assign" + src + "To" + dst
+	}
+	code, err := generate(dir, src, dst, output, funcName)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, output), code, 0o644)
+}
+
+// generate 生成代码, output为输出文件名, 加载包时忽略该文件
+func generate(dir, src, dst, output, funcName string) ([]byte, error) {
+	pkg, err := loadPackage(dir, output)
+	if err != nil {
+		return nil, err
+	}
+	g := newGenerator(pkg)
+	srcType, err := g.lookupStruct(src)
+	if err != nil {
+		return nil, err
+	}
+	dstType, err := g.lookupStruct(dst)
+	if err != nil {
+		return nil, err
+	}
+	return g.generate(srcType, dstType, funcName)
+}
@@ -0,0 +1,396 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ChangSZ/golib/log"
+)
+
+// ErrRunning Run已经在执行
+var ErrRunning = errors.New("consumer: already running")
+
+// Message 驱动拉取到的一条消息
+type Message struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       string
+	Value     []byte
+	Headers   map[string]string
+	Timestamp time.Time
+	Attempts  int         // 本次投递中已尝试处理的次数, 由Consumer维护
+	Raw       interface{} // 驱动的原始消息, 供驱动在Ack/Nack中使用
+}
+
+// Driver 消息队列客户端的适配层, Kafka、NSQ等各自实现
+type Driver interface {
+	// Fetch 拉取下一条消息, 阻塞直到有消息或ctx结束
+	Fetch(ctx context.Context) (*Message, error)
+	// Ack 确认消息已处理, 如Kafka提交offset、NSQ发送FIN
+	Ack(ctx context.Context, msg *Message) error
+	// Nack 放弃处理, 由驱动在delay后重新投递, 如NSQ发送REQ; 不支持延迟的驱动可以忽略delay
+	Nack(ctx context.Context, msg *Message, delay time.Duration) error
+}
+
+// Rebalancer 支持消费组重平衡的驱动可以实现该接口
+//
+// Revoked返回的channel收到信号时, Consumer停止拉取并等待已拉取的消息处理完成、确认后再继续拉取,
+// 避免分区被分配给其他实例后重复处理
+type Rebalancer interface {
+	Revoked() <-chan struct{}
+}
+
+// Handler 单条消息的处理函数, 返回错误时按配置重试
+type Handler func(ctx context.Context, msg *Message) error
+
+// BatchHandler 批量处理函数, 返回错误时整批重试
+type BatchHandler func(ctx context.Context, msgs []*Message) error
+
+// DeadLetter 重试次数用完或遇到Permanent错误时调用, 返回nil时确认原消息, 否则交还驱动重新投递
+type DeadLetter func(ctx context.Context, msg *Message, err error) error
+
+// Permanent 包装后的错误不再重试, 直接转入死信
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+type Config struct {
+	Concurrency    int           `toml:"concurrency"`    // 并发处理的worker数, 默认1
+	OrderByKey     bool          `toml:"orderByKey"`     // Key相同的消息由同一个worker按顺序处理
+	MaxAttempts    int           `toml:"maxAttempts"`    // 每次投递的最大尝试次数, 默认3
+	Backoff        time.Duration `toml:"backoff"`        // 首次重试间隔, 之后每次翻倍, 默认1s
+	MaxBackoff     time.Duration `toml:"maxBackoff"`     // 最大重试间隔, 默认30s
+	HandlerTimeout time.Duration `toml:"handlerTimeout"` // 单次处理的超时时间, 默认不限制
+	RequeueDelay   time.Duration `toml:"requeueDelay"`   // 放弃处理后重新投递的延迟, 默认5s
+	BatchSize      int           `toml:"batchSize"`      // 批量模式每批最多的消息数, 默认100
+	BatchWait      time.Duration `toml:"batchWait"`      // 批量模式凑批的最长等待时间, 默认1s
+	DrainTimeout   time.Duration `toml:"drainTimeout"`   // 停止或重平衡时等待处理中消息的最长时间, 超时后取消处理的ctx, 默认30s
+}
+
+// Option Consumer的可选参数
+type Option func(*Consumer)
+
+// WithDeadLetter 设置死信处理, 未设置时放弃处理的消息交还驱动重新投递
+func WithDeadLetter(fn DeadLetter) Option {
+	return func(c *Consumer) {
+		c.deadLetter = fn
+	}
+}
+
+// Consumer 消费组的通用外壳: 并发控制、至少一次处理、重试与死信、批量消费、重平衡和停止时的优雅排空
+//
+//	c := consumer.New(driver, func(ctx context.Context, msg *consumer.Message) error {
+//		return handleOrder(ctx, msg.Value)
+//	}, consumer.Config{Concurrency: 8}, consumer.WithDeadLetter(toDLQ))
+//	go c.Run(ctx)
+type Consumer struct {
+	driver     Driver
+	handler    Handler
+	batch      BatchHandler
+	cfg        Config
+	deadLetter DeadLetter
+	stats      counters
+	running    atomic.Bool
+	next       atomic.Uint64
+}
+
+// New new a Consumer.
+func New(driver Driver, handler Handler, cfg Config, opts ...Option) *Consumer {
+	return newConsumer(driver, handler, nil, cfg, opts)
+}
+
+// NewBatch new a Consumer in batch mode.
+func NewBatch(driver Driver, handler BatchHandler, cfg Config, opts ...Option) *Consumer {
+	return newConsumer(driver, nil, handler, cfg, opts)
+}
+
+func newConsumer(driver Driver, handler Handler, batch BatchHandler, cfg Config, opts []Option) *Consumer {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 30 * time.Second
+	}
+	if cfg.RequeueDelay <= 0 {
+		cfg.RequeueDelay = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.BatchWait <= 0 {
+		cfg.BatchWait = time.Second
+	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = 30 * time.Second
+	}
+	c := &Consumer{driver: driver, handler: handler, batch: batch, cfg: cfg}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Stats 返回统计数据的快照
+func (c *Consumer) Stats() Stats {
+	return c.stats.snapshot()
+}
+
+// Run 持续消费直到ctx结束, 返回前等待已拉取的消息处理完成
+func (c *Consumer) Run(ctx context.Context) error {
+	if !c.running.CompareAndSwap(false, true) {
+		return ErrRunning
+	}
+	defer c.running.Store(false)
+
+	var revoked <-chan struct{}
+	if r, ok := c.driver.(Rebalancer); ok {
+		revoked = r.Revoked()
+	}
+	for ctx.Err() == nil {
+		c.session(ctx, revoked)
+	}
+	return nil
+}
+
+// session 拉取消息直到ctx结束或发生重平衡, 然后排空所有worker
+func (c *Consumer) session(ctx context.Context, revoked <-chan struct{}) {
+	fetchCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	go func() {
+		select {
+		case <-revoked:
+			c.stats.rebalances.Add(1)
+			log.Context(ctx).Infow("msg", "consumer: partitions revoked, draining")
+			stop()
+		case <-fetchCtx.Done():
+		}
+	}()
+
+	// 处理中的消息不随ctx取消, 排空超时后才取消
+	handleCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	workers := make([]chan *Message, c.cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i := range workers {
+		workers[i] = make(chan *Message)
+		wg.Add(1)
+		go func(ch <-chan *Message) {
+			defer wg.Done()
+			if c.batch != nil {
+				c.batchWorker(handleCtx, ch)
+				return
+			}
+			for msg := range ch {
+				c.process(handleCtx, []*Message{msg})
+			}
+		}(workers[i])
+	}
+
+	c.fetchLoop(fetchCtx, handleCtx, workers)
+
+	for _, ch := range workers {
+		close(ch)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(c.cfg.DrainTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		log.Context(ctx).Warnw("msg", "consumer: drain timeout, canceling handlers", "inFlight", c.stats.inFlight.Load())
+		cancel()
+		<-done
+	}
+}
+
+func (c *Consumer) fetchLoop(ctx, handleCtx context.Context, workers []chan *Message) {
+	for {
+		msg, err := c.driver.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Context(ctx).Warnw("msg", "consumer: fetch failed", "err", err)
+			timer := time.NewTimer(c.cfg.Backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+		c.stats.received.Add(1)
+
+		select {
+		case workers[c.dispatch(msg)] <- msg:
+		case <-ctx.Done():
+			// 已拉取但未处理的消息交还驱动
+			c.requeue(handleCtx, msg, 0)
+			return
+		}
+	}
+}
+
+// dispatch 选择worker, OrderByKey时Key相同的消息总是交给同一个worker
+func (c *Consumer) dispatch(msg *Message) int {
+	n := uint64(c.cfg.Concurrency)
+	if c.cfg.OrderByKey && msg.Key != "" {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(msg.Key))
+		return int(uint64(h.Sum32()) % n)
+	}
+	return int(c.next.Add(1) % n)
+}
+
+// batchWorker 凑满BatchSize或等待BatchWait后处理一批, ch关闭时处理剩余的消息
+func (c *Consumer) batchWorker(ctx context.Context, ch <-chan *Message) {
+	var batch []*Message
+	timer := time.NewTimer(c.cfg.BatchWait)
+	timer.Stop()
+	var wait <-chan time.Time
+	flush := func() {
+		if len(batch) > 0 {
+			c.process(ctx, batch)
+			batch = nil
+		}
+		timer.Stop()
+		wait = nil
+	}
+	for {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, msg)
+			if len(batch) >= c.cfg.BatchSize {
+				flush()
+			} else if len(batch) == 1 {
+				timer.Reset(c.cfg.BatchWait)
+				wait = timer.C
+			}
+		case <-wait:
+			wait = nil
+			flush()
+		}
+	}
+}
+
+// process 处理msgs并重试, 成功后逐条确认, 失败后转入死信或交还驱动
+func (c *Consumer) process(ctx context.Context, msgs []*Message) {
+	n := int64(len(msgs))
+	c.stats.inFlight.Add(n)
+	defer c.stats.inFlight.Add(-n)
+
+	var err error
+	backoff := c.cfg.Backoff
+	for attempt := 1; ; attempt++ {
+		for _, msg := range msgs {
+			msg.Attempts = attempt
+		}
+		if err = c.call(ctx, msgs); err == nil {
+			c.stats.succeeded.Add(uint64(n))
+			for _, msg := range msgs {
+				c.ack(ctx, msg)
+			}
+			return
+		}
+		c.stats.failed.Add(1)
+
+		var perm *permanentError
+		if errors.As(err, &perm) || attempt >= c.cfg.MaxAttempts || ctx.Err() != nil {
+			break
+		}
+		c.stats.retried.Add(1)
+		log.Context(ctx).Warnw("msg", "consumer: handle failed, retrying", "topic", msgs[0].Topic,
+			"size", n, "attempt", attempt, "err", err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		backoff = min(backoff*2, c.cfg.MaxBackoff)
+	}
+
+	for _, msg := range msgs {
+		c.giveUp(ctx, msg, err)
+	}
+}
+
+// call 调用处理函数, 处理panic和超时
+func (c *Consumer) call(ctx context.Context, msgs []*Message) (err error) {
+	if c.cfg.HandlerTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.HandlerTimeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("consumer: handler panic: %v", r)
+		}
+	}()
+	if c.batch != nil {
+		return c.batch(ctx, msgs)
+	}
+	return c.handler(ctx, msgs[0])
+}
+
+// giveUp 处理被取消(排空超时)时直接交还驱动, 否则先尝试死信
+func (c *Consumer) giveUp(ctx context.Context, msg *Message, err error) {
+	if c.deadLetter != nil && ctx.Err() == nil {
+		dlErr := c.deadLetter(ctx, msg, err)
+		if dlErr == nil {
+			c.stats.deadLettered.Add(1)
+			c.ack(ctx, msg)
+			return
+		}
+		log.Context(ctx).Errorw("msg", "consumer: dead letter failed", "topic", msg.Topic,
+			"offset", msg.Offset, "err", dlErr)
+	}
+	log.Context(ctx).Errorw("msg", "consumer: give up message", "topic", msg.Topic,
+		"offset", msg.Offset, "attempts", msg.Attempts, "err", err)
+	c.requeue(ctx, msg, c.cfg.RequeueDelay)
+}
+
+func (c *Consumer) ack(ctx context.Context, msg *Message) {
+	if err := c.driver.Ack(context.WithoutCancel(ctx), msg); err != nil {
+		log.Context(ctx).Warnw("msg", "consumer: ack failed", "topic", msg.Topic, "offset", msg.Offset, "err", err)
+	}
+}
+
+func (c *Consumer) requeue(ctx context.Context, msg *Message, delay time.Duration) {
+	c.stats.requeued.Add(1)
+	if err := c.driver.Nack(context.WithoutCancel(ctx), msg, delay); err != nil {
+		log.Context(ctx).Warnw("msg", "consumer: nack failed", "topic", msg.Topic, "offset", msg.Offset, "err", err)
+	}
+}
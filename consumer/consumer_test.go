@@ -0,0 +1,266 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memDriver 基于channel的内存驱动
+type memDriver struct {
+	msgs      chan *Message
+	revoked   chan struct{}
+	redeliver bool // Nack的消息重新入队
+
+	mu     sync.Mutex
+	acked  []int64
+	nacked []int64
+}
+
+func newMemDriver(n int) *memDriver {
+	d := &memDriver{msgs: make(chan *Message, n), revoked: make(chan struct{}, 1)}
+	for i := 0; i < n; i++ {
+		d.msgs <- &Message{Topic: "order", Offset: int64(i), Key: string(rune('a' + i%3))}
+	}
+	return d
+}
+
+func (d *memDriver) Fetch(ctx context.Context) (*Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case msg := <-d.msgs:
+		return msg, nil
+	}
+}
+
+func (d *memDriver) Ack(_ context.Context, msg *Message) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.acked = append(d.acked, msg.Offset)
+	return nil
+}
+
+func (d *memDriver) Nack(_ context.Context, msg *Message, _ time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.nacked = append(d.nacked, msg.Offset)
+	if d.redeliver {
+		d.msgs <- msg
+	}
+	return nil
+}
+
+func (d *memDriver) Revoked() <-chan struct{} { return d.revoked }
+
+func (d *memDriver) result() (acked, nacked []int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	acked = append(acked, d.acked...)
+	nacked = append(nacked, d.nacked...)
+	sort.Slice(acked, func(i, j int) bool { return acked[i] < acked[j] })
+	return acked, nacked
+}
+
+// runUntil 运行Consumer直到cond满足
+func runUntil(t *testing.T, c *Consumer, cond func() bool) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = c.Run(ctx)
+		close(done)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met, stats = %+v", c.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+}
+
+func TestConsumer(t *testing.T) {
+	errBiz := errors.New("biz")
+	tests := []struct {
+		name       string
+		handler    Handler
+		deadLetter DeadLetter
+		wantAcked  int
+		wantNacked int
+		wantStats  Stats
+	}{
+		{
+			name:      "success",
+			handler:   func(ctx context.Context, msg *Message) error { return nil },
+			wantAcked: 6,
+			wantStats: Stats{Received: 6, Succeeded: 6},
+		},
+		{
+			name: "retry then success",
+			handler: func(ctx context.Context, msg *Message) error {
+				if msg.Attempts < 2 {
+					return errBiz
+				}
+				return nil
+			},
+			wantAcked: 6,
+			wantStats: Stats{Received: 6, Succeeded: 6, Failed: 6, Retried: 6},
+		},
+		{
+			name: "dead letter",
+			handler: func(ctx context.Context, msg *Message) error {
+				if msg.Offset == 0 {
+					return Permanent(errBiz)
+				}
+				return nil
+			},
+			deadLetter: func(ctx context.Context, msg *Message, err error) error { return nil },
+			wantAcked:  6,
+			wantStats:  Stats{Received: 6, Succeeded: 5, Failed: 1, DeadLettered: 1},
+		},
+		{
+			name: "requeue without dead letter",
+			handler: func(ctx context.Context, msg *Message) error {
+				if msg.Offset == 0 {
+					panic("boom")
+				}
+				return nil
+			},
+			wantAcked:  5,
+			wantNacked: 1,
+			wantStats:  Stats{Received: 6, Succeeded: 5, Failed: 2, Retried: 1, Requeued: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newMemDriver(6)
+			var opts []Option
+			if tt.deadLetter != nil {
+				opts = append(opts, WithDeadLetter(tt.deadLetter))
+			}
+			c := New(d, tt.handler, Config{Concurrency: 3, MaxAttempts: 2, Backoff: time.Millisecond}, opts...)
+			runUntil(t, c, func() bool {
+				acked, nacked := d.result()
+				return len(acked)+len(nacked) == 6
+			})
+			acked, nacked := d.result()
+			if len(acked) != tt.wantAcked || len(nacked) != tt.wantNacked {
+				t.Errorf("acked = %v, nacked = %v", acked, nacked)
+			}
+			if got := c.Stats(); got != tt.wantStats {
+				t.Errorf("Stats() = %+v, want %+v", got, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestConsumerOrderByKey(t *testing.T) {
+	d := newMemDriver(30)
+	var mu sync.Mutex
+	seen := make(map[string][]int64)
+	c := New(d, func(ctx context.Context, msg *Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[msg.Key] = append(seen[msg.Key], msg.Offset)
+		return nil
+	}, Config{Concurrency: 4, OrderByKey: true})
+	runUntil(t, c, func() bool { return c.Stats().Succeeded == 30 })
+
+	for key, offsets := range seen {
+		if !sort.SliceIsSorted(offsets, func(i, j int) bool { return offsets[i] < offsets[j] }) {
+			t.Errorf("key %s out of order: %v", key, offsets)
+		}
+	}
+}
+
+func TestConsumerBatch(t *testing.T) {
+	d := newMemDriver(25)
+	var sizes []int
+	var mu sync.Mutex
+	c := NewBatch(d, func(ctx context.Context, msgs []*Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sizes = append(sizes, len(msgs))
+		return nil
+	}, Config{BatchSize: 10, BatchWait: 20 * time.Millisecond})
+	runUntil(t, c, func() bool { return c.Stats().Succeeded == 25 })
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sizes) != 3 || sizes[0] != 10 || sizes[1] != 10 || sizes[2] != 5 {
+		t.Errorf("batch sizes = %v, want [10 10 5]", sizes)
+	}
+}
+
+func TestConsumerRebalanceDrain(t *testing.T) {
+	d := newMemDriver(4)
+	d.redeliver = true
+	var handled atomic.Int32
+	release := make(chan struct{})
+	c := New(d, func(ctx context.Context, msg *Message) error {
+		<-release
+		handled.Add(1)
+		return nil
+	}, Config{Concurrency: 2})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = c.Run(ctx) }()
+	for c.Stats().InFlight != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// 重平衡时处理中的消息完成并确认后才继续拉取
+	d.revoked <- struct{}{}
+	time.Sleep(20 * time.Millisecond)
+	if acked, _ := d.result(); len(acked) != 0 {
+		t.Fatalf("acked before handlers finished: %v", acked)
+	}
+	close(release)
+	for c.Stats().Succeeded != 4 {
+		time.Sleep(time.Millisecond)
+	}
+	if got := c.Stats(); got.Rebalances != 1 || handled.Load() != 4 {
+		t.Errorf("Stats() = %+v, want 1 rebalance", got)
+	}
+}
+
+func TestConsumerDrainTimeout(t *testing.T) {
+	d := newMemDriver(1)
+	c := New(d, func(ctx context.Context, msg *Message) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, Config{DrainTimeout: 20 * time.Millisecond}, WithDeadLetter(func(ctx context.Context, msg *Message, err error) error {
+		t.Errorf("dead letter called for canceled handler")
+		return nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = c.Run(ctx)
+		close(done)
+	}()
+	for c.Stats().InFlight != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run() did not return after drain timeout")
+	}
+	if _, nacked := d.result(); len(nacked) != 1 {
+		t.Errorf("nacked = %v, want 1", nacked)
+	}
+	if err := c.Run(ctx); err != nil {
+		t.Errorf("Run() after stop error = %v", err)
+	}
+}
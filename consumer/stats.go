@@ -0,0 +1,34 @@
+package consumer
+
+import "sync/atomic"
+
+// Stats 消费统计, 用于上报监控
+type Stats struct {
+	Received     uint64 // 拉取到的消息数
+	Succeeded    uint64 // 处理成功并确认的消息数
+	Failed       uint64 // 处理失败的次数, 每次尝试计一次
+	Retried      uint64 // 重试的次数
+	DeadLettered uint64 // 转入死信的消息数
+	Requeued     uint64 // 放弃处理并交还给驱动重新投递的消息数
+	Rebalances   uint64 // 发生重平衡的次数
+	InFlight     int64  // 正在处理的消息数
+}
+
+type counters struct {
+	received, succeeded, failed, retried atomic.Uint64
+	deadLettered, requeued, rebalances   atomic.Uint64
+	inFlight                             atomic.Int64
+}
+
+func (c *counters) snapshot() Stats {
+	return Stats{
+		Received:     c.received.Load(),
+		Succeeded:    c.succeeded.Load(),
+		Failed:       c.failed.Load(),
+		Retried:      c.retried.Load(),
+		DeadLettered: c.deadLettered.Load(),
+		Requeued:     c.requeued.Load(),
+		Rebalances:   c.rebalances.Load(),
+		InFlight:     c.inFlight.Load(),
+	}
+}
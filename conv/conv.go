@@ -0,0 +1,233 @@
+package conv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrUnconvertible 无法转换
+var ErrUnconvertible = errors.New("conv: unconvertible type")
+
+// Convert 将src转换为dst类型的值
+//
+// 转换顺序:
+//   - 已注册的转换函数(见Register)
+//   - 可直接赋值
+//   - 数值类型之间互转(int/uint/float)、同为字符串的具名类型互转
+//   - weak为true时, 额外支持 string<=>数值、string<=>bool、bool<=>数值、[]byte<=>string
+func Convert(src reflect.Value, dst reflect.Type, weak bool) (reflect.Value, error) {
+	if !src.IsValid() {
+		return reflect.Zero(dst), nil
+	}
+	if f, ok := Lookup(src.Type(), dst); ok {
+		return f(src)
+	}
+	if src.Type().AssignableTo(dst) {
+		return src, nil
+	}
+
+	// json.Number 视为数值
+	if n, ok := src.Interface().(json.Number); ok && isNumber(dst.Kind()) {
+		return parseNumber(string(n), dst)
+	}
+
+	sk, dk := src.Kind(), dst.Kind()
+	switch {
+	case isNumber(sk) && isNumber(dk):
+		return src.Convert(dst), nil
+	case sk == reflect.String && dk == reflect.String:
+		return src.Convert(dst), nil
+	case sk == reflect.Bool && dk == reflect.Bool:
+		return src.Convert(dst), nil
+	}
+
+	if !weak {
+		return reflect.Value{}, fmt.Errorf("%w: %v => %v", ErrUnconvertible, src.Type(), dst)
+	}
+
+	switch {
+	case dk == reflect.String:
+		if isBytes(src.Type()) {
+			return reflect.ValueOf(string(src.Bytes())).Convert(dst), nil
+		}
+		s, err := ToString(src.Interface())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(s).Convert(dst), nil
+	case isNumber(dk) && sk == reflect.String:
+		return parseNumber(src.String(), dst)
+	case isNumber(dk) && sk == reflect.Bool:
+		if src.Bool() {
+			return reflect.ValueOf(1).Convert(dst), nil
+		}
+		return reflect.Zero(dst), nil
+	case dk == reflect.Bool:
+		b, err := ToBool(src.Interface())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b).Convert(dst), nil
+	case isBytes(dst) && sk == reflect.String:
+		return reflect.ValueOf([]byte(src.String())).Convert(dst), nil
+	}
+	return reflect.Value{}, fmt.Errorf("%w: %v => %v", ErrUnconvertible, src.Type(), dst)
+}
+
+// ToString 宽松地转换为字符串
+func ToString(v interface{}) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return val, nil
+	case []byte:
+		return string(val), nil
+	case fmt.Stringer:
+		return val.String(), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf("%w: %T => string", ErrUnconvertible, v)
+}
+
+// ToInt64 宽松地转换为int64
+func ToInt64(v interface{}) (int64, error) {
+	out, err := convertTo(v, reflect.TypeOf(int64(0)))
+	if err != nil {
+		return 0, err
+	}
+	return out.Int(), nil
+}
+
+// ToUint64 宽松地转换为uint64
+func ToUint64(v interface{}) (uint64, error) {
+	out, err := convertTo(v, reflect.TypeOf(uint64(0)))
+	if err != nil {
+		return 0, err
+	}
+	return out.Uint(), nil
+}
+
+// ToFloat64 宽松地转换为float64
+func ToFloat64(v interface{}) (float64, error) {
+	out, err := convertTo(v, reflect.TypeOf(float64(0)))
+	if err != nil {
+		return 0, err
+	}
+	return out.Float(), nil
+}
+
+// ToBool 宽松地转换为bool, 数值非0为true, 字符串支持 1/t/true/yes/on 等写法, 空字符串为false
+func ToBool(v interface{}) (bool, error) {
+	if v == nil {
+		return false, nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.String:
+		switch strings.ToLower(strings.TrimSpace(rv.String())) {
+		case "", "0", "f", "false", "no", "off", "n":
+			return false, nil
+		case "1", "t", "true", "yes", "on", "y":
+			return true, nil
+		}
+		return false, fmt.Errorf("%w: invalid bool %q", ErrUnconvertible, rv.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() != 0, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint() != 0, nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float() != 0, nil
+	}
+	return false, fmt.Errorf("%w: %T => bool", ErrUnconvertible, v)
+}
+
+func convertTo(v interface{}, t reflect.Type) (reflect.Value, error) {
+	if v == nil {
+		return reflect.Zero(t), nil
+	}
+	return Convert(reflect.ValueOf(v), t, true)
+}
+
+func parseNumber(s string, dst reflect.Type) (reflect.Value, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return reflect.Zero(dst), nil
+	}
+
+	out := reflect.New(dst).Elem()
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 0, 64)
+		if errors.Is(err, strconv.ErrSyntax) {
+			// 兼容 "1.0" 这种整数值的浮点写法, 超出int64范围时转换结果未定义, 需先判断
+			f, ferr := strconv.ParseFloat(s, 64)
+			if ferr != nil || f != math.Trunc(f) || f < math.MinInt64 || f >= -math.MinInt64 {
+				return reflect.Value{}, fmt.Errorf("conv: parse %q as %v: %w", s, dst, err)
+			}
+			i, err = int64(f), nil
+		}
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("conv: parse %q as %v: %w", s, dst, err)
+		}
+		if out.OverflowInt(i) {
+			return reflect.Value{}, fmt.Errorf("conv: parse %q as %v: %w", s, dst, strconv.ErrRange)
+		}
+		out.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(s, 0, 64)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("conv: parse %q as %v: %w", s, dst, err)
+		}
+		if out.OverflowUint(u) {
+			return reflect.Value{}, fmt.Errorf("conv: parse %q as %v: %w", s, dst, strconv.ErrRange)
+		}
+		out.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("conv: parse %q as %v: %w", s, dst, err)
+		}
+		if out.OverflowFloat(f) {
+			return reflect.Value{}, fmt.Errorf("conv: parse %q as %v: %w", s, dst, strconv.ErrRange)
+		}
+		out.SetFloat(f)
+	}
+	return out, nil
+}
+
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
@@ -0,0 +1,135 @@
+package conv
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type celsius float64
+
+func TestConvert(t *testing.T) {
+	type args struct {
+		src  interface{}
+		dst  reflect.Type
+		weak bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "int_to_float",
+			args: args{src: int32(3), dst: reflect.TypeOf(float64(0))},
+			want: float64(3),
+		},
+		{
+			name: "named_numeric",
+			args: args{src: 36.5, dst: reflect.TypeOf(celsius(0))},
+			want: celsius(36.5),
+		},
+		{
+			name: "json_number",
+			args: args{src: json.Number("42"), dst: reflect.TypeOf(int64(0))},
+			want: int64(42),
+		},
+		{
+			name:    "strict_string_to_int",
+			args:    args{src: "42", dst: reflect.TypeOf(0)},
+			wantErr: true,
+		},
+		{
+			name: "weak_string_to_int",
+			args: args{src: "42", dst: reflect.TypeOf(0), weak: true},
+			want: 42,
+		},
+		{
+			name: "weak_float_string_to_int",
+			args: args{src: "42.0", dst: reflect.TypeOf(0), weak: true},
+			want: 42,
+		},
+		{
+			name:    "weak_string_overflow_int8",
+			args:    args{src: "300", dst: reflect.TypeOf(int8(0)), weak: true},
+			wantErr: true,
+		},
+		{
+			name:    "weak_negative_string_to_uint8",
+			args:    args{src: "-1", dst: reflect.TypeOf(uint8(0)), weak: true},
+			wantErr: true,
+		},
+		{
+			name:    "weak_float_string_overflow_int64",
+			args:    args{src: "1e20", dst: reflect.TypeOf(int64(0)), weak: true},
+			wantErr: true,
+		},
+		{
+			name: "weak_exp_string_to_int64",
+			args: args{src: "1e3", dst: reflect.TypeOf(int64(0)), weak: true},
+			want: int64(1000),
+		},
+		{
+			name:    "weak_string_overflow_float32",
+			args:    args{src: "1e40", dst: reflect.TypeOf(float32(0)), weak: true},
+			wantErr: true,
+		},
+		{
+			name: "weak_int_to_string",
+			args: args{src: 42, dst: reflect.TypeOf(""), weak: true},
+			want: "42",
+		},
+		{
+			name: "weak_string_to_bool",
+			args: args{src: "yes", dst: reflect.TypeOf(false), weak: true},
+			want: true,
+		},
+		{
+			name: "weak_bool_to_uint",
+			args: args{src: true, dst: reflect.TypeOf(uint8(0)), weak: true},
+			want: uint8(1),
+		},
+		{
+			name: "weak_bytes_to_string",
+			args: args{src: []byte("hi"), dst: reflect.TypeOf(""), weak: true},
+			want: "hi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(reflect.ValueOf(tt.args.src), tt.args.dst, tt.args.weak)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Convert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.Interface(), tt.want) {
+				t.Errorf("Convert() = %#v, want %#v", got.Interface(), tt.want)
+			}
+		})
+	}
+}
+
+type upper string
+
+func TestRegister(t *testing.T) {
+	errBad := errors.New("bad")
+	Register(func(s string) (upper, error) {
+		if s == "" {
+			return "", errBad
+		}
+		return upper(s + "!"), nil
+	})
+	defer Unregister(reflect.TypeOf(""), reflect.TypeOf(upper("")))
+
+	got, err := Convert(reflect.ValueOf("hi"), reflect.TypeOf(upper("")), false)
+	if err != nil || got.Interface() != upper("hi!") {
+		t.Errorf("Convert() = %v, %v, want hi!", got, err)
+	}
+	if _, err := Convert(reflect.ValueOf(""), reflect.TypeOf(upper("")), false); !errors.Is(err, errBad) {
+		t.Errorf("Convert() error = %v, want %v", err, errBad)
+	}
+}
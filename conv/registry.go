@@ -0,0 +1,68 @@
+package conv
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Func 类型转换函数, 入参与返回值的类型由注册时的函数签名决定
+type Func func(src reflect.Value) (reflect.Value, error)
+
+type pair struct {
+	src reflect.Type
+	dst reflect.Type
+}
+
+// registry 全局转换器注册表, decode和copy包共用
+var registry sync.Map // pair => Func
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Register 注册任意两种类型之间的转换函数
+//
+// fn 的签名必须是 func(S) (D, error) 或 func(S) D, 例如:
+//
+//	conv.Register(func(s string) (uuid.UUID, error) { return uuid.Parse(s) })
+//
+// 同一类型对重复注册时, 后注册的覆盖先注册的
+func Register(fn interface{}) {
+	src, dst, f := MakeFunc(fn)
+	registry.Store(pair{src: src, dst: dst}, f)
+}
+
+// MakeFunc 将 func(S) (D, error) 或 func(S) D 包装为Func, 返回S、D的类型; 签名不符时panic
+//
+// 用于在全局注册表之外维护自己的转换函数, 如copy.WithConverter
+func MakeFunc(fn interface{}) (src, dst reflect.Type, f Func) {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func || t.NumIn() != 1 ||
+		(t.NumOut() != 1 && t.NumOut() != 2) ||
+		(t.NumOut() == 2 && t.Out(1) != errorType) {
+		panic(fmt.Sprintf("conv: converter must be func(S) (D, error) or func(S) D, got %v", t))
+	}
+
+	withErr := t.NumOut() == 2
+	return t.In(0), t.Out(0), func(src reflect.Value) (reflect.Value, error) {
+		out := v.Call([]reflect.Value{src})
+		if withErr && !out[1].IsNil() {
+			return reflect.Value{}, out[1].Interface().(error)
+		}
+		return out[0], nil
+	}
+}
+
+// Unregister 删除某个类型对的转换函数
+func Unregister(src, dst reflect.Type) {
+	registry.Delete(pair{src: src, dst: dst})
+}
+
+// Lookup 查找 src => dst 的转换函数
+func Lookup(src, dst reflect.Type) (Func, bool) {
+	f, ok := registry.Load(pair{src: src, dst: dst})
+	if !ok {
+		return nil, false
+	}
+	return f.(Func), true
+}
@@ -0,0 +1,35 @@
+package copy
+
+import "reflect"
+
+// Allocator DeepCopyWithAllocator中指针和切片的分配方式, 如memarena.Arena从大块内存中批量分配后统一释放
+//
+// map和接口中的值仍然在堆上分配
+type Allocator interface {
+	// New 返回指向t零值的指针, 类型为*t
+	New(t reflect.Type) reflect.Value
+	// MakeSlice 返回类型为t(切片类型)的切片
+	MakeSlice(t reflect.Type, len, cap int) reflect.Value
+}
+
+// heapAllocator 默认的分配方式, 与reflect.New、reflect.MakeSlice一致
+type heapAllocator struct{}
+
+func (heapAllocator) New(t reflect.Type) reflect.Value {
+	return reflect.New(t)
+}
+
+func (heapAllocator) MakeSlice(t reflect.Type, len, cap int) reflect.Value {
+	return reflect.MakeSlice(t, len, cap)
+}
+
+// DeepCopyWithAllocator 同DeepCopy, 副本中的指针和切片由alloc分配, alloc为nil时与DeepCopy相同
+//
+// 用于请求内对大对象图的大量拷贝, 减少小对象分配带来的GC压力:
+//
+//	a := memarena.New()
+//	defer a.Release()
+//	cpy := copy.DeepCopyWithAllocator(order, a).(*Order)
+func DeepCopyWithAllocator(src interface{}, alloc Allocator) interface{} {
+	return DeepCopyWith(src, WithAllocator(alloc))
+}
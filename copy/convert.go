@@ -0,0 +1,80 @@
+package copy
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// convertNumber 将数值src转换后赋值给dst, strict为true时溢出、负数转无符号或丢失小数部分返回错误
+func convertNumber(src, dst reflect.Value, strict bool) error {
+	if strict {
+		if err := checkNumber(src, dst.Type()); err != nil {
+			return err
+		}
+	}
+	dst.Set(src.Convert(dst.Type()))
+	return nil
+}
+
+func checkNumber(src reflect.Value, dt reflect.Type) error {
+	overflow := func() error {
+		return fmt.Errorf("%v overflows %s", src.Interface(), dt)
+	}
+	zero := reflect.New(dt).Elem()
+	switch src.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := src.Int()
+		switch {
+		case zero.CanInt():
+			if zero.OverflowInt(n) {
+				return overflow()
+			}
+		case zero.CanUint():
+			if n < 0 || zero.OverflowUint(uint64(n)) {
+				return overflow()
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := src.Uint()
+		switch {
+		case zero.CanInt():
+			if n > math.MaxInt64 || zero.OverflowInt(int64(n)) {
+				return overflow()
+			}
+		case zero.CanUint():
+			if zero.OverflowUint(n) {
+				return overflow()
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		f := src.Float()
+		switch {
+		case zero.CanFloat():
+			if zero.OverflowFloat(f) {
+				return overflow()
+			}
+		case math.IsNaN(f) || math.IsInf(f, 0) || f != math.Trunc(f):
+			return fmt.Errorf("%v is not an integer", f)
+		case zero.CanInt():
+			if f < math.MinInt64 || f >= math.MaxInt64 || zero.OverflowInt(int64(f)) {
+				return overflow()
+			}
+		case zero.CanUint():
+			if f < 0 || f >= math.MaxUint64 || zero.OverflowUint(uint64(f)) {
+				return overflow()
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package copy
+
+import (
+	"reflect"
+
+	"github.com/ChangSZ/golib/conv"
+)
+
+// RegisterConverter 注册全局的类型转换函数, 字段类型为S、D时使用fn转换, 不再要求类型或Kind一致
+//
+// fn的签名为 func(S) (D, error) 或 func(S) D, 与conv.Register共用注册表, decode包同样生效:
+//
+//	copy.RegisterConverter(func(s string) (uuid.UUID, error) { return uuid.Parse(s) })
+//	copy.RegisterConverter(func(t time.Time) int64 { return t.Unix() })
+func RegisterConverter(fn interface{}) {
+	conv.Register(fn)
+}
+
+// WithConverter 只在本次调用中生效的转换函数, 优先于RegisterConverter注册的; 签名不符时panic
+func WithConverter(fn interface{}) AssignOption {
+	src, dst, f := conv.MakeFunc(fn)
+	return func(c *assignConfig) {
+		if c.converters == nil {
+			c.converters = make(map[[2]reflect.Type]conv.Func)
+		}
+		c.converters[[2]reflect.Type{src, dst}] = f
+	}
+}
+
+// converter 查找src => dst的转换函数, 本次调用的优先
+func (c *assignConfig) converter(src, dst reflect.Type) (conv.Func, bool) {
+	if f, ok := c.converters[[2]reflect.Type{src, dst}]; ok {
+		return f, true
+	}
+	return conv.Lookup(src, dst)
+}
@@ -1,101 +1,587 @@
 package copy
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/ChangSZ/golib/conv"
 )
 
+// TagName 字段映射使用的标签, `copy:"Name"`表示src字段赋值到dst中名为Name的字段,
+// src或dst字段标记为`copy:"-"`时跳过该字段
+const TagName = "copy"
+
+// AssignOption AssignStruct的可选参数
+type AssignOption func(*assignConfig)
+
+type assignConfig struct {
+	overwriteZero  bool
+	deepCopySlices bool
+	strictNumeric  bool
+	deepMergeMaps  bool
+	appendSlices   bool
+	flatten        bool
+	strict         bool
+	strategy       MergeStrategy
+	maxDepth       int
+	ignore         map[string]bool
+	exclude        map[string]bool
+	only           map[string]bool
+	onlyParents    map[string]bool
+	hooks          map[string]FieldHook
+	converters     map[[2]reflect.Type]conv.Func
+
+	// 遍历状态, 每次AssignStructE调用独立
+	depth     int
+	visiting  map[visit]bool
+	unmatched []string // WithStrict时未能赋值的字段, 按路径去重
+}
+
+// visit 当前路径上正在处理的指针或map, 再次遇到说明存在环
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// DefaultMaxDepth AssignStruct默认的最大嵌套层数
+const DefaultMaxDepth = 64
+
+var (
+	// ErrMaxDepth 结构体或map的嵌套层数超过WithMaxDepth的限制
+	ErrMaxDepth = errors.New("copy: max depth exceeded")
+	// ErrCycle src中存在循环引用, 如链表的尾节点指回头节点
+	ErrCycle = errors.New("copy: cycle detected")
+	// ErrUnmatched WithStrict时src中存在没有对应dst字段或类型无法转换的字段
+	ErrUnmatched = errors.New("copy: unmatched fields")
+)
+
+// WithOverwriteZero src中的零值字段也赋值到dst中, 默认跳过零值
+func WithOverwriteZero() AssignOption {
+	return func(c *assignConfig) {
+		c.overwriteZero = true
+	}
+}
+
+// WithDeepCopySlices 切片深拷贝后赋值, 默认dst与src共享底层数组; 结构体切片总是按元素赋值, 不受影响
+func WithDeepCopySlices() AssignOption {
+	return func(c *assignConfig) {
+		c.deepCopySlices = true
+	}
+}
+
+// WithIgnoreFields 跳过指定字段, 可以是字段名(任意层级都跳过)或以"."连接的路径, 如"ID"、"Profile.Avatar"
+func WithIgnoreFields(fields ...string) AssignOption {
+	return func(c *assignConfig) {
+		if c.ignore == nil {
+			c.ignore = make(map[string]bool, len(fields))
+		}
+		for _, f := range fields {
+			c.ignore[f] = true
+		}
+	}
+}
+
+// WithOnlyFields 只赋值指定的字段, 以"."连接的完整路径, 如"Name"、"Address.City", 与WithIgnoreFields的路径一致; 多次使用时取并集
+//
+// 指定嵌套字段时只赋值该子字段, 同一结构体的其他字段不变; 指定结构体字段时赋值其全部子字段.
+// 切片和map中结构体元素的字段路径不含下标和key, 如"Contacts.City"
+//
+//	// PATCH接口只允许修改昵称和城市
+//	copy.AssignStructE(req, &user, copy.WithOnlyFields("Name", "Address.City"))
+func WithOnlyFields(paths ...string) AssignOption {
+	return func(c *assignConfig) {
+		if c.only == nil {
+			c.only = make(map[string]bool, len(paths))
+			c.onlyParents = make(map[string]bool)
+		}
+		for _, p := range paths {
+			c.only[p] = true
+			for i := strings.LastIndexByte(p, '.'); i > 0; i = strings.LastIndexByte(p[:i], '.') {
+				c.onlyParents[p[:i]] = true
+			}
+		}
+	}
+}
+
+// WithExcludeFields 跳过指定路径的字段及其子字段, 路径规则同WithOnlyFields;
+// 与WithIgnoreFields不同, 字段名只匹配顶层字段, 不会跳过其他层级的同名字段
+func WithExcludeFields(paths ...string) AssignOption {
+	return func(c *assignConfig) {
+		if c.exclude == nil {
+			c.exclude = make(map[string]bool, len(paths))
+		}
+		for _, p := range paths {
+			c.exclude[p] = true
+		}
+	}
+}
+
+// WithStrict src中的字段在dst中没有对应字段, 或类型无法转换时返回ErrUnmatched, 错误信息中列出所有这样的字段;
+// 默认静默跳过, 字段改名后容易漏拷贝
+//
+// dst中没有对应字段按类型检查, 与src的值无关; 类型无法转换在赋值时发现, src字段为零值跳过时不会报告.
+// 标记为`copy:"-"`和被WithIgnoreFields等选项过滤的字段不算.
+// 其余字段照常赋值, 返回ErrUnmatched时dst已被修改
+func WithStrict() AssignOption {
+	return func(c *assignConfig) {
+		c.strict = true
+	}
+}
+
+// WithStrictNumeric 数值类型转换会溢出或丢失小数部分时返回错误, 默认按Go的转换规则截断
+func WithStrictNumeric() AssignOption {
+	return func(c *assignConfig) {
+		c.strictNumeric = true
+	}
+}
+
+// WithDeepMergeMaps dst中已存在的key与src的值合并(结构体按字段合并, map递归合并), 默认用src的值整体替换
+func WithDeepMergeMaps() AssignOption {
+	return func(c *assignConfig) {
+		c.deepMergeMaps = true
+	}
+}
+
+// FieldHook 字段赋值前对src的值做转换, 返回值的类型可以与src不同, 按AssignStruct的规则赋值到dst;
+// 返回无效值(reflect.Value{})时跳过该字段
+type FieldHook func(src reflect.Value) (reflect.Value, error)
+
+// WithFieldHook 为字段注册转换函数, path规则同WithIgnoreFields, 路径优先于字段名; 同一字段重复注册时后注册的生效
+//
+// 在拷贝的同时完成清洗, 不需要再遍历一次结构体:
+//
+//	copy.WithFieldHook("Name", func(v reflect.Value) (reflect.Value, error) {
+//		return reflect.ValueOf(strings.TrimSpace(v.String())), nil
+//	})
+//
+// 与其他字段一样, src为零值时默认跳过, 不会调用hook
+func WithFieldHook(path string, fn FieldHook) AssignOption {
+	return func(c *assignConfig) {
+		if c.hooks == nil {
+			c.hooks = make(map[string]FieldHook)
+		}
+		c.hooks[path] = fn
+	}
+}
+
+func (c *assignConfig) hook(name, path string) (FieldHook, bool) {
+	if fn, ok := c.hooks[path]; ok {
+		return fn, true
+	}
+	fn, ok := c.hooks[name]
+	return fn, ok
+}
+
+// WithMaxDepth 结构体和map最多嵌套n层(顶层结构体为第1层), 超过时返回ErrMaxDepth; n<=0时使用DefaultMaxDepth
+func WithMaxDepth(n int) AssignOption {
+	return func(c *assignConfig) {
+		if n > 0 {
+			c.maxDepth = n
+		}
+	}
+}
+
+func (c *assignConfig) ignored(name, path string) bool {
+	return c.ignore[name] || c.ignore[path]
+}
+
+// selected path是否通过WithOnlyFields和WithExcludeFields的筛选, 父字段被排除时不会递归到子字段, 只需判断path本身
+func (c *assignConfig) selected(path string) bool {
+	if c.exclude == nil && c.only == nil {
+		return true
+	}
+	path = stripKeys(path)
+	if c.exclude[path] {
+		return false
+	}
+	if c.only == nil || c.only[path] || c.onlyParents[path] {
+		return true
+	}
+	// 父字段在白名单中时其子字段都赋值
+	for i := strings.LastIndexByte(path, '.'); i > 0; i = strings.LastIndexByte(path[:i], '.') {
+		if c.only[path[:i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// unmatch 记录WithStrict时未能赋值的字段, reason为空表示dst中没有对应字段
+func (c *assignConfig) unmatch(path, reason string) {
+	if !c.strict {
+		return
+	}
+	entry := path
+	if reason != "" {
+		entry += " (" + reason + ")"
+	}
+	for _, e := range c.unmatched {
+		if e == entry {
+			return
+		}
+	}
+	c.unmatched = append(c.unmatched, entry)
+}
+
+func mismatch(src, dst reflect.Type) string {
+	return "cannot assign " + src.String() + " to " + dst.String()
+}
+
+// stripKeys 去掉路径中map的key, 如"Labels[a].Name" -> "Labels.Name"
+func stripKeys(path string) string {
+	if !strings.Contains(path, "[") {
+		return path
+	}
+	var b strings.Builder
+	depth := 0
+	for _, r := range path {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// enter 进入一层结构体或map, 返回的函数用于退出
+func (c *assignConfig) enter(path string) (func(), error) {
+	if c.depth >= c.maxDepth {
+		return nil, fmt.Errorf("field %s: %w (%d)", path, ErrMaxDepth, c.maxDepth)
+	}
+	c.depth++
+	return func() { c.depth-- }, nil
+}
+
+// mark 记录当前路径上的指针或map, 已在路径上时返回ErrCycle; 返回的函数用于移除记录
+//
+// 只检查当前路径, 多个字段共享同一个对象(非环)时不受影响
+func (c *assignConfig) mark(v reflect.Value, path string) (func(), error) {
+	key := visit{ptr: v.Pointer(), typ: v.Type()}
+	if c.visiting[key] {
+		return nil, fmt.Errorf("field %s: %w", path, ErrCycle)
+	}
+	if c.visiting == nil {
+		c.visiting = make(map[visit]bool)
+	}
+	c.visiting[key] = true
+	return func() { delete(c.visiting, key) }, nil
+}
+
 // AssignStruct 将src中有值的字段赋值到dst中
 //
-// - 是将相同字段名中src值赋给dst中对应字段
+// - 是将相同字段名中src值赋给dst中对应字段, src字段的copy标签可以指定dst中的字段名, 如`copy:"UserName"`
 // - 入参必须是结构体对象引用
-// - 若结构体中存在切片, 请先初始化至src\dst一致
+// - 结构体切片按元素赋值, dst长度调整为与src一致, 已有的元素在原值上合并; 其他切片与src共享, 见WithDeepCopySlices
+// - src或dst中标记为`copy:"-"`的字段不赋值, 如密码、审计时间
 // - 如果存在内联, 保证内联结构体名称一致
-func AssignStruct(src, dst interface{}) {
+// - 数值类型不同时(如int32与int64)自动转换
+// - 类型不同且注册了转换函数时使用该函数转换, 见RegisterConverter、WithConverter
+// - 指针与值自动桥接, 如*string与string
+// - database/sql的Null类型(如sql.NullString、sql.NullTime)与对应的值类型及其指针互转, Valid为false视为零值
+// - map字段在dst中分配新的map, 保留dst原有的key并合并src的key, 不与src共享
+// - 嵌套层数超过WithMaxDepth或src存在循环引用时报错, 见ErrMaxDepth、ErrCycle
+// - dst中没有对应字段或类型无法转换的字段默认跳过, 见WithStrict
+func AssignStruct(src, dst interface{}, opts ...AssignOption) {
+	if err := AssignStructE(src, dst, opts...); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// AssignStructE 同AssignStruct, 出错时返回错误而不是打印, 遇到第一个错误即停止
+func AssignStructE(src, dst interface{}, opts ...AssignOption) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Println("Recovered from panic:", r)
+			err = fmt.Errorf("recovered from panic: %v", r)
 		}
 	}()
 	if src == nil || reflect.ValueOf(src).IsNil() ||
 		dst == nil || reflect.ValueOf(dst).IsNil() {
-		fmt.Println("src or dst is nil")
-		return
+		return errors.New("src or dst is nil")
+	}
+	cfg := &assignConfig{maxDepth: DefaultMaxDepth}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if err := assignStructFields(reflect.ValueOf(src).Elem(), reflect.ValueOf(dst).Elem(), cfg, ""); err != nil {
+		return err
 	}
-	assignStructFields(reflect.ValueOf(src).Elem(), reflect.ValueOf(dst).Elem())
+	if len(cfg.unmatched) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnmatched, strings.Join(cfg.unmatched, ", "))
+	}
+	return nil
 }
 
-// assignStructFields 将src中有值的字段赋值到dst中, 递归至成员变量最小类型
-func assignStructFields(src, dst reflect.Value) {
-	srcType := src.Type()
-	for i := 0; i < srcType.NumField(); i++ {
-		field := srcType.Field(i)
-		fieldName := field.Name
+// assignStructFields 将src中有值的字段赋值到dst中, 递归至成员变量最小类型, prefix为当前结构体的字段路径
+func assignStructFields(src, dst reflect.Value, cfg *assignConfig, prefix string) error {
+	leave, err := cfg.enter(prefix)
+	if err != nil {
+		return err
+	}
+	defer leave()
 
-		srcFieldValue := src.FieldByName(fieldName)
-		dstFieldValue := dst.FieldByName(fieldName)
+	for _, plan := range assignPlan(src.Type(), dst.Type()) {
+		fieldName := plan.name
+		path := fieldName
+		if prefix != "" {
+			path = prefix + "." + fieldName
+		}
+		if cfg.ignored(fieldName, path) {
+			continue
+		}
 
-		// 如果字段是匿名的（内嵌的），但在 dst 中不存在，则尝试将 src 内嵌字段的子字段拷贝到 dst 中
-		if field.Anonymous && !dstFieldValue.IsValid() {
-			// 如果 srcFieldValue 是结构体，则直接将其字段拷贝到 dst 中
-			if srcFieldValue.Kind() == reflect.Struct {
-				assignStructFields(srcFieldValue, dst)
+		srcFieldValue := src.Field(plan.index)
+		if plan.inline {
+			if err := assignStructFields(srcFieldValue, dst, cfg, prefix); err != nil {
+				return err
 			}
 			continue
 		}
+		if !cfg.selected(path) {
+			continue
+		}
+		if plan.missing {
+			cfg.unmatch(path, "")
+			continue
+		}
+		dstFieldValue := dst.FieldByIndex(plan.dstIndex)
 
-		// 检查字段是否有效
-		if srcFieldValue.IsValid() && dstFieldValue.IsValid() {
-			// 检查 srcFieldValue 是否为 nil 指针
-			if srcFieldValue.Kind() == reflect.Ptr && srcFieldValue.IsNil() {
-				continue
+		// 如果字段值为零值或 nil，则跳过
+		if !cfg.overwriteZero && isZero(srcFieldValue) {
+			continue
+		}
+		if fn, ok := cfg.hook(fieldName, path); ok {
+			v, err := fn(srcFieldValue)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", path, err)
 			}
-			// 如果字段值为零值，则跳过
-			if srcFieldValue.IsZero() {
+			if !v.IsValid() {
 				continue
 			}
-
-			// 对于 time.Time 类型特殊处理
-			if field.Type == reflect.TypeOf(time.Time{}) {
-				dstFieldValue.Set(srcFieldValue)
-				continue
+			srcFieldValue = v
+		}
+		if skip, err := cfg.resolve(srcFieldValue, dstFieldValue, path); err != nil || skip {
+			if err != nil {
+				return err
 			}
+			continue
+		}
+		if err := assignValue(srcFieldValue, dstFieldValue, cfg, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-			// 如果字段是结构体，则递归处理
-			if srcFieldValue.Kind() == reflect.Struct {
-				assignStructFields(srcFieldValue, dstFieldValue)
-				continue
-			}
+var timeType = reflect.TypeOf(time.Time{})
 
-			// 如果字段是 slice，则调用相应的处理函数
-			if srcFieldValue.Kind() == reflect.Slice {
-				assignSliceFields(srcFieldValue, dstFieldValue)
-				continue
+// assignValue 将src赋值到dst, path为字段路径
+func assignValue(src, dst reflect.Value, cfg *assignConfig, path string) error {
+	// 注册的转换函数优先, 如copy/protoconv注册的protobuf常用类型
+	if src.Type() != dst.Type() && src.CanInterface() {
+		if fn, ok := cfg.converter(src.Type(), dst.Type()); ok {
+			v, err := fn(src)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", path, err)
 			}
+			dst.Set(v)
+			return nil
+		}
+	}
+	// 类型不同时指针与值自动桥接: *T赋给T时取值, T赋给*T时分配新的指针; 类型相同的指针直接赋值
+	if src.Type() != dst.Type() && src.Kind() == reflect.Ptr {
+		if src.IsNil() {
+			// 只有WithOverwriteZero时会走到这里
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		unmark, err := cfg.mark(src, path)
+		if err != nil {
+			return err
+		}
+		defer unmark()
+		return assignValue(src.Elem(), dst, cfg, path)
+	}
+	// database/sql的Null类型与对应的值类型互转, 在指针桥接之前处理, 使NULL赋给*T时为nil
+	if ok, err := assignNull(src, dst, cfg, path); ok {
+		return err
+	}
+	if src.Type() != dst.Type() && dst.Kind() == reflect.Ptr {
+		elem := reflect.New(dst.Type().Elem())
+		// 在副本上合并, 不修改dst原来指向的对象
+		if !dst.IsNil() {
+			elem.Elem().Set(dst.Elem())
+		}
+		if err := assignValue(src, elem.Elem(), cfg, path); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
 
-			// 如果类型匹配，则直接设置
-			if srcFieldValue.Kind() == dstFieldValue.Kind() {
-				dstFieldValue.Set(srcFieldValue)
-			}
+	// 对于 time.Time 类型特殊处理
+	if src.Type() == timeType {
+		if dst.Type() == timeType {
+			dst.Set(src)
+		} else {
+			cfg.unmatch(path, mismatch(src.Type(), dst.Type()))
+		}
+		return nil
+	}
+
+	// 如果字段是结构体，则递归处理
+	if src.Kind() == reflect.Struct {
+		if dst.Kind() != reflect.Struct && cfg.strict {
+			cfg.unmatch(path, mismatch(src.Type(), dst.Type()))
+			return nil
+		}
+		return assignStructFields(src, dst, cfg, path)
+	}
+
+	// 如果字段是 slice，则调用相应的处理函数
+	if src.Kind() == reflect.Slice {
+		return assignSliceFields(src, dst, cfg, path)
+	}
+
+	// map分配新的对象后合并
+	if src.Kind() == reflect.Map && dst.Kind() == reflect.Map {
+		return assignMap(src, dst, cfg, path)
+	}
+
+	// 数值类型不同时转换
+	if isNumber(src.Kind()) && isNumber(dst.Kind()) {
+		if err := convertNumber(src, dst, cfg.strictNumeric); err != nil {
+			return fmt.Errorf("field %s: %w", path, err)
+		}
+		return nil
+	}
+
+	// 如果类型匹配，则直接设置; 底层类型相同的命名类型(如type UserID string)先转换
+	if src.Kind() == dst.Kind() {
+		if src.Type() != dst.Type() && src.Type().ConvertibleTo(dst.Type()) {
+			src = src.Convert(dst.Type())
 		}
+		dst.Set(src)
+		return nil
 	}
+	cfg.unmatch(path, mismatch(src.Type(), dst.Type()))
+	return nil
 }
 
 // assignSliceFields 复制切片
-func assignSliceFields(src, dst reflect.Value) {
+func assignSliceFields(src, dst reflect.Value, cfg *assignConfig, path string) error {
+	// DeepMerge时类型相同的切片追加到dst之后, 分配新的底层数组, 不修改dst原来的切片
+	if cfg.appendSlices && src.Type() == dst.Type() && dst.Len() > 0 && !src.IsNil() {
+		merged := reflect.MakeSlice(dst.Type(), 0, dst.Len()+src.Len())
+		merged = reflect.AppendSlice(merged, dst)
+		dst.Set(reflect.AppendSlice(merged, src))
+		return nil
+	}
 	elemType := src.Type().Elem()
-	// 若元素类型是结构体且源切片元素个数等于目标切片元素个数时, 依次递归复制
-	if elemType.Kind() == reflect.Struct && src.Len() == dst.Len() {
-		// 依次处理每个元素
+	// 元素类型是结构体时依次递归复制, 长度不一致时分配新的切片, 保留dst中已有的元素作为合并的基础
+	if elemType.Kind() == reflect.Struct && dst.Kind() == reflect.Slice && dst.Type().Elem().Kind() == reflect.Struct {
+		if src.IsNil() {
+			// 只有WithOverwriteZero时会走到这里
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		if src.Len() != dst.Len() {
+			grown := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+			reflect.Copy(grown, dst)
+			dst.Set(grown)
+		}
 		for j := 0; j < src.Len(); j++ {
-			assignStructFields(src.Index(j), dst.Index(j))
+			if err := assignStructFields(src.Index(j), dst.Index(j), cfg, path); err != nil {
+				return err
+			}
 		}
-	} else {
-		if src.Kind() == dst.Kind() {
-			dst.Set(src)
+		return nil
+	}
+	if src.Kind() != dst.Kind() {
+		cfg.unmatch(path, mismatch(src.Type(), dst.Type()))
+		return nil
+	}
+	if cfg.deepCopySlices && src.Type() == dst.Type() {
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
 		}
+		dst.Set(reflect.ValueOf(DeepCopy(src.Interface())))
+		return nil
 	}
+	dst.Set(src)
+	return nil
+}
+
+// assignMap 分配新的map, 先复制dst原有的元素再合并src的元素, key类型不可转换时跳过
+func assignMap(src, dst reflect.Value, cfg *assignConfig, path string) error {
+	if src.IsNil() {
+		// 只有WithOverwriteZero时会走到这里
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	dstType := dst.Type()
+	if !src.Type().Key().ConvertibleTo(dstType.Key()) {
+		return nil
+	}
+	leave, err := cfg.enter(path)
+	if err != nil {
+		return err
+	}
+	defer leave()
+	unmark, err := cfg.mark(src, path)
+	if err != nil {
+		return err
+	}
+	defer unmark()
+
+	merged := reflect.MakeMapWithSize(dstType, src.Len()+dst.Len())
+	iter := dst.MapRange()
+	for iter.Next() {
+		merged.SetMapIndex(iter.Key(), iter.Value())
+	}
+	iter = src.MapRange()
+	for iter.Next() {
+		key := iter.Key().Convert(dstType.Key())
+		elem := reflect.New(dstType.Elem()).Elem()
+		if old := merged.MapIndex(key); cfg.deepMergeMaps && old.IsValid() {
+			elem.Set(old)
+		}
+		if err := assignMapElem(iter.Value(), elem, cfg, fmt.Sprintf("%s[%v]", path, key)); err != nil {
+			return err
+		}
+		merged.SetMapIndex(key, elem)
+	}
+	dst.Set(merged)
+	return nil
+}
+
+// assignMapElem 将src赋值到map元素的副本elem, elem为替换时的零值或合并时的原值
+func assignMapElem(src, elem reflect.Value, cfg *assignConfig, path string) error {
+	if src.Kind() != reflect.Interface || elem.Kind() != reflect.Interface {
+		return assignValue(src, elem, cfg, path)
+	}
+	// interface{}类型的值(如map[string]interface{})只有双方都是map时才递归合并
+	if !src.IsNil() && !elem.IsNil() && src.Elem().Kind() == reflect.Map && elem.Elem().Kind() == reflect.Map {
+		merged := reflect.New(elem.Elem().Type()).Elem()
+		merged.Set(elem.Elem())
+		if err := assignMap(src.Elem(), merged, cfg, path); err != nil {
+			return err
+		}
+		elem.Set(merged)
+		return nil
+	}
+	elem.Set(src)
+	return nil
 }
 
 // DeepCopy creates a deep copy of whatever is passed to it and returns the copy
@@ -113,12 +599,77 @@ func DeepCopy(src interface{}) interface{} {
 	cpy := reflect.New(original.Type()).Elem()
 
 	// Recursively copy the original.
-	copyRecursive(original, cpy)
+	copyRecursive(original, cpy, &deepCopier{alloc: heapAllocator{}})
 
 	// Return the copy as an interface.
 	return cpy.Interface()
 }
 
+// DeepCopyInto 将src深拷贝到调用方提供的dst中, 可以配合对象池复用dst, 避免DeepCopy每次分配顶层对象
+//
+// dst必须是非nil指针, 类型为*T, src为T或*T; dst原有的内容会先被清空, 不会残留上一次使用的值
+//
+//	u := pool.Get().(*User)
+//	if err := copy.DeepCopyInto(src, u); err != nil {
+//		return err
+//	}
+func DeepCopyInto(src, dst interface{}) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return fmt.Errorf("copy: dst must be a non-nil pointer, got %T", dst)
+	}
+	sv := reflect.ValueOf(src)
+	if !sv.IsValid() {
+		return errors.New("copy: src is nil")
+	}
+	target := dv.Elem()
+	switch {
+	case sv.Type() == target.Type():
+	case sv.Type() == dv.Type():
+		if sv.IsNil() {
+			return errors.New("copy: src is nil")
+		}
+		if sv.Pointer() == dv.Pointer() {
+			return nil
+		}
+		sv = sv.Elem()
+	default:
+		return fmt.Errorf("copy: cannot copy %T into %T", src, dst)
+	}
+
+	target.Set(reflect.Zero(target.Type()))
+	copyRecursive(sv, target, &deepCopier{alloc: heapAllocator{}})
+	return nil
+}
+
+// Clone 返回v的深拷贝, 是DeepCopy的泛型版本, 不需要再做类型断言
+//
+// 基本类型和常见的切片、map类型直接拷贝, 不经过反射
+func Clone[T any](v T) T {
+	switch x := any(v).(type) {
+	case string, bool, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, float32, float64, time.Time:
+		return v
+	case []byte:
+		return any(bytes.Clone(x)).(T)
+	case []string:
+		return any(slices.Clone(x)).(T)
+	case []int:
+		return any(slices.Clone(x)).(T)
+	case []int64:
+		return any(slices.Clone(x)).(T)
+	case map[string]string:
+		return any(maps.Clone(x)).(T)
+	}
+	cpy, ok := DeepCopy(v).(T)
+	if !ok {
+		// v为nil接口时DeepCopy返回nil
+		var zero T
+		return zero
+	}
+	return cpy
+}
+
 // Interface for delegating copy process to type
 type Interface interface {
 	DeepCopy() interface{}
@@ -126,7 +677,7 @@ type Interface interface {
 
 // copyRecursive does the actual copying of the interface. It currently has
 // limited support for what it can handle. Add as needed.
-func copyRecursive(original, cpy reflect.Value) {
+func copyRecursive(original, cpy reflect.Value, c *deepCopier) {
 	// check for implement deepcopy.Interface
 	if original.CanInterface() {
 		if copier, ok := original.Interface().(Interface); ok {
@@ -145,8 +696,8 @@ func copyRecursive(original, cpy reflect.Value) {
 		if !originalValue.IsValid() {
 			return
 		}
-		cpy.Set(reflect.New(originalValue.Type()))
-		copyRecursive(originalValue, cpy.Elem())
+		cpy.Set(c.alloc.New(originalValue.Type()))
+		copyRecursive(originalValue, cpy.Elem(), c)
 
 	case reflect.Interface:
 		// If this is a nil, don't do anything
@@ -158,24 +709,32 @@ func copyRecursive(original, cpy reflect.Value) {
 
 		// Get the value by calling Elem().
 		copyValue := reflect.New(originalValue.Type()).Elem()
-		copyRecursive(originalValue, copyValue)
+		copyRecursive(originalValue, copyValue, c)
 		cpy.Set(copyValue)
 
 	case reflect.Struct:
-		t, ok := original.Interface().(time.Time)
-		if ok {
-			cpy.Set(reflect.ValueOf(t))
+		if original.Type() == timeType {
+			cpy.Set(original)
 			return
 		}
+		if c.unexported && !original.CanAddr() {
+			// 读取未导出字段需要可寻址的值, 先浅拷贝一份
+			addressable := reflect.New(original.Type()).Elem()
+			addressable.Set(original)
+			original = addressable
+		}
 		// Go through each field of the struct and copy it.
-		for i := 0; i < original.NumField(); i++ {
+		for i, ok := range exported(original.Type()) {
 			// The Type's StructField for a given field is checked to see if StructField.PkgPath
 			// is set to determine if the field is exported or not because CanSet() returns false
 			// for settable fields.  I'm not sure why.  -mohae
-			if original.Type().Field(i).PkgPath != "" {
+			if !ok {
+				if c.unexported {
+					copyRecursive(exposeField(original.Field(i)), exposeField(cpy.Field(i)), c)
+				}
 				continue
 			}
-			copyRecursive(original.Field(i), cpy.Field(i))
+			copyRecursive(original.Field(i), cpy.Field(i), c)
 		}
 
 	case reflect.Slice:
@@ -183,9 +742,9 @@ func copyRecursive(original, cpy reflect.Value) {
 			return
 		}
 		// Make a new slice and copy each element.
-		cpy.Set(reflect.MakeSlice(original.Type(), original.Len(), original.Cap()))
+		cpy.Set(c.alloc.MakeSlice(original.Type(), original.Len(), original.Cap()))
 		for i := 0; i < original.Len(); i++ {
-			copyRecursive(original.Index(i), cpy.Index(i))
+			copyRecursive(original.Index(i), cpy.Index(i), c)
 		}
 
 	case reflect.Map:
@@ -196,9 +755,10 @@ func copyRecursive(original, cpy reflect.Value) {
 		for _, key := range original.MapKeys() {
 			originalValue := original.MapIndex(key)
 			copyValue := reflect.New(originalValue.Type()).Elem()
-			copyRecursive(originalValue, copyValue)
-			copyKey := DeepCopy(key.Interface())
-			cpy.SetMapIndex(reflect.ValueOf(copyKey), copyValue)
+			copyRecursive(originalValue, copyValue, c)
+			copyKey := reflect.New(key.Type()).Elem()
+			copyRecursive(key, copyKey, c)
+			cpy.SetMapIndex(copyKey, copyValue)
 		}
 
 	default:
@@ -1,8 +1,16 @@
 package copy
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ChangSZ/golib/conv"
 )
 
 type Source struct {
@@ -106,6 +114,584 @@ func TestAssignStruct(t *testing.T) {
 	}
 }
 
+type optProfile struct {
+	Avatar string
+	Bio    string
+}
+
+type optUser struct {
+	ID      int
+	Name    string
+	Age     int
+	Tags    []string
+	Items   []optProfile
+	Profile optProfile
+}
+
+func TestAssignStructOptions(t *testing.T) {
+	newSrc := func() *optUser {
+		return &optUser{
+			ID:      1,
+			Name:    "jack",
+			Tags:    []string{"a"},
+			Items:   []optProfile{{Bio: "x"}, {Bio: "y"}},
+			Profile: optProfile{Avatar: "new.png"},
+		}
+	}
+	newDst := func() *optUser {
+		return &optUser{ID: 9, Age: 18, Profile: optProfile{Avatar: "old.png", Bio: "hi"}}
+	}
+
+	tests := []struct {
+		name string
+		opts []AssignOption
+		want *optUser
+	}{
+		{
+			name: "ignore fields",
+			opts: []AssignOption{WithIgnoreFields("ID", "Profile.Avatar"), WithDeepCopySlices()},
+			want: &optUser{
+				ID: 9, Name: "jack", Age: 18, Tags: []string{"a"},
+				Items:   []optProfile{{Bio: "x"}, {Bio: "y"}},
+				Profile: optProfile{Avatar: "old.png", Bio: "hi"},
+			},
+		},
+		{
+			name: "overwrite zero",
+			opts: []AssignOption{WithOverwriteZero(), WithIgnoreFields("Items")},
+			want: &optUser{ID: 1, Name: "jack", Tags: []string{"a"}, Profile: optProfile{Avatar: "new.png"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := newDst()
+			AssignStruct(newSrc(), dst, tt.opts...)
+			if !reflect.DeepEqual(dst, tt.want) {
+				t.Errorf("AssignStruct() = %+v, want %+v", dst, tt.want)
+			}
+		})
+	}
+
+	t.Run("deep copy slices", func(t *testing.T) {
+		src, shared, copied := newSrc(), newDst(), newDst()
+		AssignStruct(src, shared)
+		AssignStruct(src, copied, WithDeepCopySlices())
+		src.Tags[0] = "changed"
+		src.Items[0].Bio = "changed"
+		if shared.Tags[0] != "changed" || copied.Tags[0] != "a" {
+			t.Errorf("Tags shared = %v, copied = %v", shared.Tags, copied.Tags)
+		}
+		// 结构体切片总是按元素赋值
+		if shared.Items[0].Bio != "x" || copied.Items[0].Bio != "x" {
+			t.Errorf("Items shared = %v, copied = %v", shared.Items, copied.Items)
+		}
+	})
+}
+
+type tagSrc struct {
+	Name    string `copy:"UserName"`
+	Mobile  string `copy:"Phone"`
+	Comment string
+}
+
+type tagDst struct {
+	UserName  string
+	Phone     string
+	Comment   string
+	Name      string
+	Password  string
+	CreatedAt time.Time `copy:"-"`
+}
+
+type skipSrc struct {
+	Name      string
+	Password  string `copy:"-"`
+	CreatedAt time.Time
+}
+
+func TestAssignStructTag(t *testing.T) {
+	dst := &tagDst{Name: "keep"}
+	AssignStruct(&tagSrc{Name: "jack", Mobile: "13800000000", Comment: "hi"}, dst)
+	want := &tagDst{UserName: "jack", Phone: "13800000000", Comment: "hi", Name: "keep"}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("AssignStruct() = %+v, want %+v", dst, want)
+	}
+
+	created := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	dst = &tagDst{Password: "old", CreatedAt: created}
+	AssignStruct(&skipSrc{Name: "jack", Password: "new", CreatedAt: time.Now()}, dst)
+	want = &tagDst{Name: "jack", Password: "old", CreatedAt: created}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("AssignStruct() skip = %+v, want %+v", dst, want)
+	}
+}
+
+type userID string
+
+type convSrc struct {
+	ID      string
+	Created time.Time
+}
+
+type convDst struct {
+	ID      userID
+	Created int64
+}
+
+func TestAssignStructConverter(t *testing.T) {
+	RegisterConverter(func(t time.Time) int64 { return t.Unix() })
+	defer conv.Unregister(reflect.TypeOf(time.Time{}), reflect.TypeOf(int64(0)))
+
+	created := time.Unix(1700000000, 0)
+	tests := []struct {
+		name    string
+		opts    []AssignOption
+		want    convDst
+		wantErr bool
+	}{
+		{
+			name: "global",
+			want: convDst{ID: "u1", Created: 1700000000}, // string => userID按底层类型转换
+		},
+		{
+			name: "per call overrides global",
+			opts: []AssignOption{
+				WithConverter(func(s string) userID { return userID("user:" + s) }),
+				WithConverter(func(t time.Time) int64 { return t.UnixMilli() }),
+			},
+			want: convDst{ID: "user:u1", Created: 1700000000000},
+		},
+		{
+			name: "error",
+			opts: []AssignOption{
+				WithConverter(func(s string) (userID, error) { return "", errors.New("bad id") }),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst convDst
+			err := AssignStructE(&convSrc{ID: "u1", Created: created}, &dst, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AssignStructE() error = %v", err)
+			}
+			if !tt.wantErr && dst != tt.want {
+				t.Errorf("AssignStructE() = %+v, want %+v", dst, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignStructSliceResize(t *testing.T) {
+	type item struct {
+		Name string
+		Qty  int
+	}
+	type order struct {
+		Items []item
+	}
+	type orderDTO struct {
+		Items []struct{ Name string }
+	}
+
+	tests := []struct {
+		name string
+		src  interface{}
+		dst  *order
+		want []item
+	}{
+		{
+			name: "grow from nil",
+			src:  &order{Items: []item{{Name: "a", Qty: 1}, {Name: "b", Qty: 2}}},
+			dst:  &order{},
+			want: []item{{Name: "a", Qty: 1}, {Name: "b", Qty: 2}},
+		},
+		{
+			name: "grow keeps existing",
+			src:  &orderDTO{Items: []struct{ Name string }{{Name: "a"}, {Name: "b"}}},
+			dst:  &order{Items: []item{{Name: "x", Qty: 5}}},
+			want: []item{{Name: "a", Qty: 5}, {Name: "b"}},
+		},
+		{
+			name: "shrink",
+			src:  &order{Items: []item{{Name: "a"}}},
+			dst:  &order{Items: []item{{Name: "x", Qty: 5}, {Name: "y"}}},
+			want: []item{{Name: "a", Qty: 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := AssignStructE(tt.src, tt.dst); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.dst.Items, tt.want) {
+				t.Errorf("Items = %+v, want %+v", tt.dst.Items, tt.want)
+			}
+		})
+	}
+
+	src := &order{Items: []item{{Name: "a"}}}
+	dst := &order{}
+	AssignStruct(src, dst)
+	src.Items[0].Name = "changed"
+	if dst.Items[0].Name != "a" {
+		t.Error("dst shares backing array with src")
+	}
+}
+
+type numSrc struct {
+	A int32
+	B float32
+	C int64
+	D float64
+	E uint8
+}
+
+type numDst struct {
+	A int64
+	B float64
+	C int8
+	D int
+	E int16
+}
+
+func TestAssignStructNumeric(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     numSrc
+		opts    []AssignOption
+		want    numDst
+		wantErr string
+	}{
+		{
+			name: "widen",
+			src:  numSrc{A: 7, B: 1.5, C: 100, D: 3, E: 200},
+			want: numDst{A: 7, B: 1.5, C: 100, D: 3, E: 200},
+		},
+		{
+			name: "truncate by default",
+			src:  numSrc{C: 300, D: 2.7},
+			want: numDst{C: 44, D: 2},
+		},
+		{
+			name:    "strict overflow",
+			src:     numSrc{C: 300},
+			opts:    []AssignOption{WithStrictNumeric()},
+			wantErr: "field C: 300 overflows int8",
+		},
+		{
+			name:    "strict fraction",
+			src:     numSrc{D: 2.7},
+			opts:    []AssignOption{WithStrictNumeric()},
+			wantErr: "field D: 2.7 is not an integer",
+		},
+		{
+			name: "strict ok",
+			src:  numSrc{C: -128, D: 42},
+			opts: []AssignOption{WithStrictNumeric()},
+			want: numDst{C: -128, D: 42},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst numDst
+			err := AssignStructE(&tt.src, &dst, tt.opts...)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("AssignStructE() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || dst != tt.want {
+				t.Errorf("AssignStructE() = %+v, %v, want %+v", dst, err, tt.want)
+			}
+		})
+	}
+}
+
+type apiAddress struct {
+	City *string
+}
+
+type apiUser struct {
+	Name    *string
+	Age     *int32
+	Nick    string
+	Score   int
+	Address *apiAddress
+}
+
+type domainAddress struct {
+	City string
+}
+
+type domainUser struct {
+	Name    string
+	Age     int64
+	Nick    *string
+	Score   *int64
+	Address domainAddress
+}
+
+func TestAssignStructPointer(t *testing.T) {
+	name, city := "jack", "shanghai"
+	age := int32(18)
+
+	t.Run("pointer to value", func(t *testing.T) {
+		src := &apiUser{Name: &name, Age: &age, Address: &apiAddress{City: &city}}
+		dst := &domainUser{Name: "old"}
+		if err := AssignStructE(src, dst); err != nil {
+			t.Fatal(err)
+		}
+		want := &domainUser{Name: "jack", Age: 18, Address: domainAddress{City: "shanghai"}}
+		if !reflect.DeepEqual(dst, want) {
+			t.Errorf("AssignStructE() = %+v, want %+v", dst, want)
+		}
+	})
+
+	t.Run("value to pointer", func(t *testing.T) {
+		oldNick := "old"
+		src := &domainUser{Name: "rose", Nick: &oldNick}
+		dst := &apiUser{Nick: "x"}
+		back := &domainUser{}
+		if err := AssignStructE(&apiUser{Nick: "rn", Score: 7}, back); err != nil {
+			t.Fatal(err)
+		}
+		if back.Nick == nil || *back.Nick != "rn" || back.Score == nil || *back.Score != 7 {
+			t.Errorf("Nick = %v, Score = %v", back.Nick, back.Score)
+		}
+		if err := AssignStructE(src, dst); err != nil {
+			t.Fatal(err)
+		}
+		if dst.Name == nil || *dst.Name != "rose" || dst.Nick != "old" {
+			t.Errorf("AssignStructE() = %+v", dst)
+		}
+	})
+
+	t.Run("nil pointer skipped unless overwrite zero", func(t *testing.T) {
+		dst := &domainUser{Name: "keep"}
+		_ = AssignStructE(&apiUser{}, dst, WithIgnoreFields("Address"))
+		if dst.Name != "keep" {
+			t.Errorf("Name = %q, want keep", dst.Name)
+		}
+		_ = AssignStructE(&apiUser{}, dst, WithOverwriteZero(), WithIgnoreFields("Address"))
+		if dst.Name != "" {
+			t.Errorf("Name = %q, want empty", dst.Name)
+		}
+	})
+}
+
+type cycleSrc struct {
+	Name string
+	Next *cycleSrc
+}
+
+type cycleDst struct {
+	Name string
+	Next cycleDstNext
+}
+
+type cycleDstNext struct {
+	Name string
+	Next *cycleDstNext
+}
+
+func TestAssignStructGuard(t *testing.T) {
+	loop := &cycleSrc{Name: "a"}
+	loop.Next = &cycleSrc{Name: "b", Next: loop}
+	chain := &cycleSrc{Name: "a"}
+	for i, n := 0, chain; i < 5; i, n = i+1, n.Next {
+		n.Next = &cycleSrc{Name: fmt.Sprint(i)}
+	}
+	shared := &cycleSrc{Name: "s"}
+
+	tests := []struct {
+		name    string
+		src     interface{}
+		dst     interface{}
+		opts    []AssignOption
+		wantErr error
+	}{
+		{name: "cycle", src: loop, dst: &cycleDst{}, wantErr: ErrCycle},
+		{name: "max depth", src: chain, dst: &cycleDst{}, opts: []AssignOption{WithMaxDepth(3)}, wantErr: ErrMaxDepth},
+		{name: "within depth", src: chain, dst: &cycleDst{}, opts: []AssignOption{WithMaxDepth(10)}},
+		{name: "shared pointer is not a cycle", src: &struct{ A, B *cycleSrc }{shared, shared},
+			dst: &struct{ A, B cycleSrc }{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := AssignStructE(tt.src, tt.dst, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AssignStructE() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type hookProfile struct {
+	Phone string
+}
+
+type hookForm struct {
+	Name     string
+	Password string
+	Age      string
+	Profile  hookProfile
+	Nick     string
+}
+
+type hookUser struct {
+	Name     string
+	Password string
+	Age      int
+	Profile  hookProfile
+	Nick     string
+}
+
+func TestAssignStructFieldHook(t *testing.T) {
+	trim := func(v reflect.Value) (reflect.Value, error) {
+		return reflect.ValueOf(strings.TrimSpace(v.String())), nil
+	}
+	errBad := errors.New("bad age")
+
+	tests := []struct {
+		name    string
+		opts    []AssignOption
+		want    hookUser
+		wantErr error
+	}{
+		{
+			name: "transform",
+			opts: []AssignOption{
+				WithFieldHook("Name", trim),
+				WithFieldHook("Password", func(v reflect.Value) (reflect.Value, error) {
+					return reflect.ValueOf("hash:" + v.String()), nil
+				}),
+				WithFieldHook("Age", func(v reflect.Value) (reflect.Value, error) {
+					n, err := strconv.Atoi(v.String())
+					return reflect.ValueOf(n), err
+				}),
+				WithFieldHook("Profile.Phone", func(v reflect.Value) (reflect.Value, error) {
+					return reflect.ValueOf(strings.TrimPrefix(v.String(), "+86")), nil
+				}),
+				WithFieldHook("Nick", func(v reflect.Value) (reflect.Value, error) {
+					return reflect.Value{}, nil
+				}),
+			},
+			want: hookUser{Name: "jack", Password: "hash:123", Age: 18, Profile: hookProfile{Phone: "13800000000"}, Nick: "keep"},
+		},
+		{
+			name: "error",
+			opts: []AssignOption{WithFieldHook("Age", func(v reflect.Value) (reflect.Value, error) {
+				return reflect.Value{}, errBad
+			})},
+			wantErr: errBad,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &hookForm{Name: " jack ", Password: "123", Age: "18", Profile: hookProfile{Phone: "+8613800000000"}, Nick: "new"}
+			dst := &hookUser{Nick: "keep"}
+			err := AssignStructE(src, dst, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AssignStructE() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(*dst, tt.want) {
+				t.Errorf("AssignStructE() = %+v, want %+v", *dst, tt.want)
+			}
+		})
+	}
+}
+
+type mapProfile struct {
+	Avatar string
+	Bio    string
+}
+
+type mapSrc struct {
+	Labels   map[string]string
+	Profiles map[string]mapProfile
+	Extra    map[string]interface{}
+	Scores   map[string]int32
+}
+
+type mapDst struct {
+	Labels   map[string]string
+	Profiles map[string]mapProfile
+	Extra    map[string]interface{}
+	Scores   map[string]int64
+}
+
+func TestAssignStructMap(t *testing.T) {
+	newSrc := func() *mapSrc {
+		return &mapSrc{
+			Labels:   map[string]string{"env": "prod"},
+			Profiles: map[string]mapProfile{"jack": {Avatar: "new.png"}},
+			Extra:    map[string]interface{}{"db": map[string]interface{}{"host": "10.0.0.1"}},
+			Scores:   map[string]int32{"math": 90},
+		}
+	}
+	newDst := func() *mapDst {
+		return &mapDst{
+			Labels:   map[string]string{"env": "dev", "team": "infra"},
+			Profiles: map[string]mapProfile{"jack": {Avatar: "old.png", Bio: "hi"}},
+			Extra:    map[string]interface{}{"db": map[string]interface{}{"port": 3306}},
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts []AssignOption
+		want *mapDst
+	}{
+		{
+			name: "replace",
+			want: &mapDst{
+				Labels:   map[string]string{"env": "prod", "team": "infra"},
+				Profiles: map[string]mapProfile{"jack": {Avatar: "new.png"}},
+				Extra:    map[string]interface{}{"db": map[string]interface{}{"host": "10.0.0.1"}},
+				Scores:   map[string]int64{"math": 90},
+			},
+		},
+		{
+			name: "deep merge",
+			opts: []AssignOption{WithDeepMergeMaps()},
+			want: &mapDst{
+				Labels:   map[string]string{"env": "prod", "team": "infra"},
+				Profiles: map[string]mapProfile{"jack": {Avatar: "new.png", Bio: "hi"}},
+				Extra:    map[string]interface{}{"db": map[string]interface{}{"host": "10.0.0.1", "port": 3306}},
+				Scores:   map[string]int64{"math": 90},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, dst := newSrc(), newDst()
+			if err := AssignStructE(src, dst, tt.opts...); err != nil {
+				t.Fatalf("AssignStructE() error = %v", err)
+			}
+			if !reflect.DeepEqual(dst, tt.want) {
+				t.Errorf("AssignStructE() = %+v, want %+v", dst, tt.want)
+			}
+			// dst分配了新的map, 修改src不影响dst
+			src.Labels["env"] = "changed"
+			if dst.Labels["env"] != "prod" {
+				t.Errorf("dst shares map with src")
+			}
+		})
+	}
+
+	t.Run("keep dst map untouched", func(t *testing.T) {
+		dst := newDst()
+		old := dst.Labels
+		AssignStruct(newSrc(), dst)
+		if old["env"] != "dev" || len(old) != 2 {
+			t.Errorf("original dst map modified: %v", old)
+		}
+	})
+}
+
 func TestDeepCopy(t *testing.T) {
 	type args struct {
 		value interface{}
@@ -152,3 +738,795 @@ func TestDeepCopy(t *testing.T) {
 		})
 	}
 }
+
+type mapAddress struct {
+	City string
+	Zip  int
+}
+
+type mapBase struct {
+	ID int64
+}
+
+type mapUser struct {
+	mapBase
+	Name     string `copy:"user_name"`
+	Age      int
+	Score    float32
+	Address  mapAddress
+	Backup   *mapAddress
+	Tags     []string
+	Contacts []mapAddress
+	Extra    interface{}
+	Secret   string `copy:"-"`
+}
+
+func TestAssignMap(t *testing.T) {
+	src := map[string]interface{}{
+		"id":        float64(7),
+		"user_name": "jack",
+		"age":       float64(0),
+		"score":     float64(9.5),
+		"address":   map[string]interface{}{"city": "sh"},
+		"backup":    map[string]interface{}{"City": "bj", "Zip": float64(100000)},
+		"tags":      []interface{}{"a", "b"},
+		"contacts":  []interface{}{map[string]interface{}{"city": "gz"}, nil},
+		"extra":     map[string]interface{}{"k": "v"},
+		"secret":    "x",
+	}
+
+	tests := []struct {
+		name string
+		opts []AssignOption
+		want mapUser
+	}{
+		{
+			name: "default",
+			want: mapUser{
+				mapBase: mapBase{ID: 7}, Name: "jack", Age: 18, Score: 9.5,
+				Address:  mapAddress{City: "sh", Zip: 200000},
+				Backup:   &mapAddress{City: "bj", Zip: 100000},
+				Tags:     []string{"a", "b"},
+				Contacts: []mapAddress{{City: "gz"}, {}},
+				Extra:    map[string]interface{}{"k": "v"},
+				Secret:   "keep",
+			},
+		},
+		{
+			name: "overwrite zero and ignore",
+			opts: []AssignOption{WithOverwriteZero(), WithIgnoreFields("Address.Zip", "Contacts")},
+			want: mapUser{
+				mapBase: mapBase{ID: 7}, Name: "jack", Age: 0, Score: 9.5,
+				Address: mapAddress{City: "sh", Zip: 200000},
+				Backup:  &mapAddress{City: "bj", Zip: 100000},
+				Tags:    []string{"a", "b"},
+				Extra:   map[string]interface{}{"k": "v"},
+				Secret:  "keep",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := mapUser{Age: 18, Address: mapAddress{Zip: 200000}, Secret: "keep"}
+			err := AssignMapE(src, &dst, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(dst, tt.want) {
+				t.Errorf("AssignMapE() = %+v, want %+v", dst, tt.want)
+			}
+		})
+	}
+
+	var dst mapUser
+	if err := AssignMapE(map[string]interface{}{"age": 1.5}, &dst, WithStrictNumeric()); err == nil {
+		t.Error("AssignMapE() strict numeric error = nil")
+	}
+	if err := AssignMapE(src, dst); err == nil {
+		t.Error("AssignMapE() non-pointer dst error = nil")
+	}
+}
+
+func TestStructToMap(t *testing.T) {
+	created := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	shared := &mapAddress{City: "bj"}
+	type payload struct {
+		mapBase
+		Name    string `copy:"user_name"`
+		Age     int
+		Secret  string `copy:"-"`
+		Created time.Time
+		Address mapAddress
+		Backup  *mapAddress
+		Same    *mapAddress
+	}
+	src := &payload{
+		mapBase: mapBase{ID: 7}, Name: "jack", Secret: "x", Created: created,
+		Address: mapAddress{City: "sh"}, Backup: shared, Same: shared,
+	}
+
+	tests := []struct {
+		name string
+		opts []AssignOption
+		want map[string]interface{}
+	}{
+		{
+			name: "nested",
+			want: map[string]interface{}{
+				"ID": int64(7), "user_name": "jack", "Created": created,
+				"Address": map[string]interface{}{"City": "sh"},
+				"Backup":  map[string]interface{}{"City": "bj"},
+				"Same":    map[string]interface{}{"City": "bj"},
+			},
+		},
+		{
+			name: "flatten and ignore",
+			opts: []AssignOption{WithFlatten(), WithIgnoreFields("ID", "Same")},
+			want: map[string]interface{}{
+				"user_name": "jack", "Created": created,
+				"Address.City": "sh", "Backup.City": "bj",
+			},
+		},
+		{
+			name: "overwrite zero",
+			opts: []AssignOption{WithFlatten(), WithOverwriteZero(), WithIgnoreFields("Backup", "Same")},
+			want: map[string]interface{}{
+				"ID": int64(7), "user_name": "jack", "Age": 0, "Created": created,
+				"Address.City": "sh", "Address.Zip": 0,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := StructToMap(src, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StructToMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := StructToMap(map[string]int{}); err == nil {
+		t.Error("StructToMap() non-struct error = nil")
+	}
+}
+
+type privateState struct {
+	Name  string
+	cache map[string]*Destination
+	hits  []int
+	inner struct{ n int }
+}
+
+func TestDeepCopyUnexported(t *testing.T) {
+	newSrc := func() privateState {
+		src := privateState{Name: "a", cache: map[string]*Destination{"k": {Field2: "v"}}, hits: []int{1}}
+		src.inner.n = 3
+		return src
+	}
+
+	t.Run("default skips", func(t *testing.T) {
+		got := DeepCopy(newSrc()).(privateState)
+		if got.Name != "a" || got.cache != nil || got.hits != nil {
+			t.Errorf("DeepCopy() = %+v", got)
+		}
+	})
+
+	tests := []struct {
+		name string
+		src  interface{}
+		get  func(interface{}) privateState
+	}{
+		{name: "value", src: newSrc(), get: func(v interface{}) privateState { return v.(privateState) }},
+		{name: "pointer", src: func() *privateState { s := newSrc(); return &s }(), get: func(v interface{}) privateState { return *v.(*privateState) }},
+		{name: "in map", src: map[string]privateState{"x": newSrc()}, get: func(v interface{}) privateState { return v.(map[string]privateState)["x"] }},
+		{name: "in interface", src: []interface{}{newSrc()}, get: func(v interface{}) privateState { return v.([]interface{})[0].(privateState) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.get(DeepCopyWith(tt.src, WithUnexported()))
+			want := newSrc()
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("DeepCopyWith() = %+v, want %+v", got, want)
+			}
+			orig := tt.get(tt.src)
+			if got.cache["k"] == orig.cache["k"] || &got.hits[0] == &orig.hits[0] {
+				t.Errorf("DeepCopyWith() shares memory with src")
+			}
+		})
+	}
+}
+
+func TestClone(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		src := &Source{Field1: 1, Field4: &Destination{Field2: "a"}}
+		got := Clone(src)
+		src.Field4.Field2 = "changed"
+		if got == src || got.Field4 == src.Field4 || got.Field4.Field2 != "a" {
+			t.Errorf("Clone() = %+v, shares memory with src", got)
+		}
+	})
+
+	t.Run("fast path", func(t *testing.T) {
+		b := []byte("abc")
+		cb := Clone(b)
+		b[0] = 'x'
+		m := map[string]string{"k": "v"}
+		cm := Clone(m)
+		m["k"] = "changed"
+		if string(cb) != "abc" || cm["k"] != "v" || Clone("s") != "s" {
+			t.Errorf("Clone() = %s, %v", cb, cm)
+		}
+		if Clone([]string(nil)) != nil {
+			t.Errorf("Clone(nil slice) should be nil")
+		}
+	})
+
+	t.Run("nil interface", func(t *testing.T) {
+		var v interface{}
+		if got := Clone(v); got != nil {
+			t.Errorf("Clone() = %v, want nil", got)
+		}
+	})
+}
+
+func TestDeepCopyInto(t *testing.T) {
+	src := Source{Field1: 1, Field4: &Destination{Field2: "a"}}
+	tests := []struct {
+		name    string
+		src     interface{}
+		dst     interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "value", src: src, dst: &Source{Field2: "stale"}, want: &src},
+		{name: "pointer", src: &src, dst: &Source{Field5: "stale"}, want: &src},
+		{name: "nil pointer src", src: (*Source)(nil), dst: &Source{}, wantErr: true},
+		{name: "dst not pointer", src: src, dst: Source{}, wantErr: true},
+		{name: "type mismatch", src: src, dst: &Destination{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DeepCopyInto(tt.src, tt.dst)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeepCopyInto() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(tt.dst, tt.want) {
+				t.Errorf("DeepCopyInto() = %+v, want %+v", tt.dst, tt.want)
+			}
+			if tt.dst.(*Source).Field4 == src.Field4 {
+				t.Errorf("DeepCopyInto() shares pointer with src")
+			}
+		})
+	}
+}
+
+type diffProfile struct {
+	Avatar string
+	Bio    string
+}
+
+type diffBase struct {
+	ID int
+}
+
+type diffUser struct {
+	diffBase
+	Name      string
+	Phone     string `mask:"phone"`
+	Tags      []string
+	Profile   diffProfile
+	Parent    *diffUser
+	Birthday  *time.Time
+	UpdatedAt time.Time
+	Password  string `copy:"-"`
+	Addrs     []diffProfile
+}
+
+func TestDiff(t *testing.T) {
+	day := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+	sameDay := day.In(time.FixedZone("CST", 8*3600))
+	before := &diffUser{diffBase: diffBase{ID: 1}, Name: "jack", Phone: "13800000000",
+		Tags: []string{"a"}, Profile: diffProfile{Avatar: "a.png"}, Birthday: &day, UpdatedAt: day}
+
+	tests := []struct {
+		name   string
+		before interface{}
+		after  interface{}
+		ignore []string
+		want   []string
+	}{
+		{
+			name:   "update",
+			before: before,
+			after: &diffUser{diffBase: diffBase{ID: 1}, Name: "rose", Phone: "13800000000",
+				Tags: []string{"a", "b"}, Profile: diffProfile{Avatar: "b.png"}, Birthday: &sameDay, UpdatedAt: day.Add(time.Hour)},
+			ignore: []string{"UpdatedAt"},
+			want:   []string{"Name", "Tags", "Profile.Avatar"},
+		},
+		{
+			name:   "create",
+			before: nil,
+			after:  &diffUser{diffBase: diffBase{ID: 2}, Name: "tom", Parent: &diffUser{Name: "p"}},
+			want:   []string{"Name", "Parent.Name", "ID"},
+		},
+		{
+			name:   "delete value",
+			before: diffUser{Name: "tom"},
+			after:  (*diffUser)(nil),
+			want:   []string{"Name"},
+		},
+		{
+			name:   "no change",
+			before: before,
+			after:  before,
+		},
+		{
+			name:   "skip tag",
+			before: &diffUser{Password: "a"},
+			after:  &diffUser{Password: "b"},
+		},
+		{
+			name:   "struct slice",
+			before: &diffUser{Addrs: []diffProfile{{Avatar: "a"}, {Avatar: "b"}}},
+			after:  &diffUser{Addrs: []diffProfile{{Avatar: "a"}, {Avatar: "c"}, {Avatar: "d"}}},
+			want:   []string{"Addrs[1].Avatar", "Addrs[2]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := Diff(tt.before, tt.after, tt.ignore...)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			var paths []string
+			for _, c := range changes {
+				paths = append(paths, c.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("Diff() paths = %v, want %v", paths, tt.want)
+			}
+		})
+	}
+
+	changes, _ := Diff(&diffUser{Phone: "1"}, &diffUser{Phone: "2"})
+	if len(changes) != 1 || changes[0].Before != "1" || changes[0].After != "2" || changes[0].Field.Tag.Get("mask") != "phone" {
+		t.Errorf("Diff() = %+v", changes)
+	}
+	if _, err := Diff(&diffUser{}, &diffProfile{}); err == nil {
+		t.Errorf("Diff() want error for different types")
+	}
+
+	removed, _ := Diff(&diffUser{Addrs: []diffProfile{{Avatar: "a"}}}, &diffUser{})
+	if len(removed) != 1 || removed[0].Path != "Addrs[0]" || removed[0].Before != (diffProfile{Avatar: "a"}) || removed[0].After != nil {
+		t.Errorf("Diff() removed element = %+v", removed)
+	}
+
+	a, b := &diffUser{Name: "a"}, &diffUser{Name: "b"}
+	a.Parent, b.Parent = b, a
+	if _, err := Diff(a, b); !errors.Is(err, ErrMaxDepth) {
+		t.Errorf("Diff() cycle error = %v, want ErrMaxDepth", err)
+	}
+
+	type nullUser struct {
+		Nick  sql.NullString
+		Email sql.NullString
+		Phone sql.NullString
+	}
+	changes, err := Diff(
+		&nullUser{Nick: sql.NullString{String: "a", Valid: true}, Email: sql.NullString{String: "stale"}},
+		&nullUser{Nick: sql.NullString{String: "b", Valid: true}, Phone: sql.NullString{String: "138", Valid: true}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{
+		{Path: "Nick", Before: "a", After: "b"},
+		{Path: "Phone", Before: nil, After: "138"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Diff() sql.Null = %+v", changes)
+	}
+	for i, c := range changes {
+		if c.Path != want[i].Path || c.Before != want[i].Before || c.After != want[i].After {
+			t.Errorf("Diff() sql.Null change = %+v, want %+v", c, want[i])
+		}
+	}
+}
+
+type mergeInner struct {
+	Host string
+	Port int
+}
+
+type mergeConf struct {
+	Name   string
+	Level  int64
+	Inner  mergeInner
+	Labels map[string]string
+	Tags   []string
+}
+
+type mergeOverride struct {
+	Name   string
+	Level  int32
+	Inner  *mergeInner
+	Labels map[string]string
+	Tags   []string
+}
+
+func TestMerge(t *testing.T) {
+	base := func() *mergeConf {
+		return &mergeConf{
+			Name: "base", Inner: mergeInner{Host: "localhost"},
+			Labels: map[string]string{"a": "1"}, Tags: []string{"x"},
+		}
+	}
+	override := &mergeOverride{
+		Name: "override", Level: 2, Inner: &mergeInner{Host: "db", Port: 3306},
+		Labels: map[string]string{"b": "2"}, Tags: []string{"y"},
+	}
+
+	tests := []struct {
+		name     string
+		strategy MergeStrategy
+		srcs     []interface{}
+		want     *mergeConf
+		wantErr  error
+	}{
+		{
+			name: "src wins", strategy: SrcWins, srcs: []interface{}{override},
+			want: &mergeConf{
+				Name: "override", Level: 2, Inner: mergeInner{Host: "db", Port: 3306},
+				Labels: map[string]string{"a": "1", "b": "2"}, Tags: []string{"y"},
+			},
+		},
+		{
+			name: "dst wins", strategy: DstWins, srcs: []interface{}{override},
+			want: &mergeConf{
+				Name: "base", Level: 2, Inner: mergeInner{Host: "localhost", Port: 3306},
+				Labels: map[string]string{"a": "1"}, Tags: []string{"x"},
+			},
+		},
+		{
+			name: "dst wins keeps first src", strategy: DstWins,
+			srcs: []interface{}{nil, &mergeConf{Level: 1}, &mergeConf{Level: 2, Name: "ignored"}},
+			want: &mergeConf{
+				Name: "base", Level: 1, Inner: mergeInner{Host: "localhost"},
+				Labels: map[string]string{"a": "1"}, Tags: []string{"x"},
+			},
+		},
+		{
+			name: "deep merge", strategy: DeepMerge, srcs: []interface{}{override},
+			want: &mergeConf{
+				Name: "override", Level: 2, Inner: mergeInner{Host: "db", Port: 3306},
+				Labels: map[string]string{"a": "1", "b": "2"}, Tags: []string{"x", "y"},
+			},
+		},
+		{
+			name: "no conflict", strategy: ErrorOnConflict,
+			srcs: []interface{}{&mergeOverride{Name: "base", Level: 3, Inner: &mergeInner{Port: 80}}},
+			want: &mergeConf{
+				Name: "base", Level: 3, Inner: mergeInner{Host: "localhost", Port: 80},
+				Labels: map[string]string{"a": "1"}, Tags: []string{"x"},
+			},
+		},
+		{
+			name: "conflict", strategy: ErrorOnConflict,
+			srcs:    []interface{}{&mergeOverride{Inner: &mergeInner{Host: "db"}}},
+			wantErr: ErrConflict,
+		},
+		{
+			name: "conflict between srcs", strategy: ErrorOnConflict,
+			srcs:    []interface{}{&mergeConf{Level: 1}, &mergeOverride{Level: 2}},
+			wantErr: ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := base()
+			err := MergeWith(tt.strategy, got, tt.srcs...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("MergeWith() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeWith() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	err := MergeWith(ErrorOnConflict, base(), &mergeConf{Inner: mergeInner{Host: "db"}})
+	if err == nil || !strings.Contains(err.Error(), "Inner.Host") {
+		t.Errorf("MergeWith() error = %v, want field path", err)
+	}
+	if err := Merge(mergeConf{}, override); err == nil {
+		t.Error("Merge() non-pointer dst error = nil")
+	}
+}
+
+func TestAssignStructSelectFields(t *testing.T) {
+	src := &mapUser{
+		mapBase: mapBase{ID: 1}, Name: "new", Age: 30,
+		Address: mapAddress{City: "sh", Zip: 200000}, Backup: &mapAddress{City: "gz", Zip: 510000},
+		Contacts: []mapAddress{{City: "hz", Zip: 310000}},
+	}
+	dst := func() *mapUser {
+		return &mapUser{
+			mapBase: mapBase{ID: 9}, Name: "old", Age: 20,
+			Address: mapAddress{City: "bj", Zip: 100000},
+			Backup:  &mapAddress{City: "wh"}, Contacts: []mapAddress{{City: "cd"}},
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts []AssignOption
+		want func(u *mapUser)
+	}{
+		{
+			name: "only",
+			opts: []AssignOption{WithOnlyFields("Age", "Address.City", "mapBase.ID")},
+			want: func(u *mapUser) { u.ID, u.Age, u.Address.City = 1, 30, "sh" },
+		},
+		{
+			name: "only whole struct",
+			opts: []AssignOption{WithOnlyFields("Address"), WithOnlyFields("Backup")},
+			want: func(u *mapUser) { u.Address, u.Backup = src.Address, src.Backup },
+		},
+		{
+			name: "only slice elem field",
+			opts: []AssignOption{WithOnlyFields("Contacts.Zip")},
+			want: func(u *mapUser) { u.Contacts[0].Zip = 310000 },
+		},
+		{
+			name: "exclude",
+			opts: []AssignOption{WithExcludeFields("Age", "Address.City", "Backup", "Contacts")},
+			want: func(u *mapUser) { u.ID, u.Address.Zip = 1, 200000 },
+		},
+		{
+			name: "exclude wins",
+			opts: []AssignOption{WithOnlyFields("Address"), WithExcludeFields("Address.Zip")},
+			want: func(u *mapUser) { u.Address.City = "sh" },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, want := dst(), dst()
+			tt.want(want)
+			if err := AssignStructE(src, got, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("AssignStructE() = %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	m, err := StructToMap(src, WithFlatten(), WithOnlyFields("Name", "Address.Zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"user_name": "new", "Address.Zip": 200000}; !reflect.DeepEqual(m, want) {
+		t.Errorf("StructToMap() = %v, want %v", m, want)
+	}
+}
+
+func TestAssignStructStrict(t *testing.T) {
+	type address struct {
+		City    string
+		Street  string
+		Created time.Time
+	}
+	type from struct {
+		ID       int64
+		Name     string `copy:"UserName"`
+		Nickname string
+		Secret   string `copy:"-"`
+		Address  address
+		Tags     []string
+		Score    string
+		internal int
+	}
+	type toAddress struct {
+		City    string
+		Created string
+	}
+	type to struct {
+		ID       int32
+		UserName string
+		Address  toAddress
+		Tags     string
+		Score    int
+	}
+
+	src := &from{
+		ID: 1, Name: "a", Address: address{City: "bj", Created: time.Unix(1, 0)},
+		Tags: []string{"x"}, Score: "1", internal: 1,
+	}
+
+	tests := []struct {
+		name    string
+		opts    []AssignOption
+		wantErr string
+	}{
+		{
+			name:    "unmatched",
+			wantErr: "Nickname, Address.Street, Address.Created (cannot assign time.Time to string), Tags (cannot assign []string to string), Score (cannot assign string to int)",
+		},
+		{
+			name: "filtered",
+			opts: []AssignOption{WithIgnoreFields("Nickname", "Tags"), WithExcludeFields("Address", "Score")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst to
+			err := AssignStructE(src, &dst, append(tt.opts, WithStrict())...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else if !errors.Is(err, ErrUnmatched) || !strings.HasSuffix(err.Error(), tt.wantErr) {
+				t.Fatalf("AssignStructE() error = %v, want %q", err, tt.wantErr)
+			}
+			if dst.ID != 1 {
+				t.Errorf("AssignStructE() ID = %d, want 1", dst.ID)
+			}
+		})
+	}
+
+	if err := AssignStructE(src, &to{}); err != nil {
+		t.Errorf("AssignStructE() without strict error = %v", err)
+	}
+}
+
+type nullModel struct {
+	Name    sql.NullString
+	Age     sql.NullInt64
+	Score   sql.NullFloat64
+	Created sql.NullTime
+	Nick    sql.NullString
+	Level   sql.Null[int32]
+}
+
+type nullDTO struct {
+	Name    string
+	Age     int32
+	Score   *float64
+	Created time.Time
+	Nick    *string
+	Level   int
+}
+
+func TestAssignStructSQLNull(t *testing.T) {
+	created := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	score := 9.5
+
+	t.Run("null to value", func(t *testing.T) {
+		src := &nullModel{
+			Name:    sql.NullString{String: "a", Valid: true},
+			Age:     sql.NullInt64{Int64: 20, Valid: true},
+			Score:   sql.NullFloat64{Float64: score, Valid: true},
+			Created: sql.NullTime{Time: created, Valid: true},
+			Nick:    sql.NullString{String: "stale", Valid: false},
+			Level:   sql.Null[int32]{V: 3, Valid: true},
+		}
+		nick := "keep"
+		got := &nullDTO{Nick: &nick}
+		if err := AssignStructE(src, got); err != nil {
+			t.Fatal(err)
+		}
+		want := &nullDTO{Name: "a", Age: 20, Score: &score, Created: created, Nick: &nick, Level: 3}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("AssignStructE() = %+v, want %+v", got, want)
+		}
+
+		if err := AssignStructE(src, got, WithOverwriteZero()); err != nil {
+			t.Fatal(err)
+		}
+		if got.Nick != nil {
+			t.Errorf("AssignStructE() Nick = %v, want nil", *got.Nick)
+		}
+	})
+
+	t.Run("value to null", func(t *testing.T) {
+		src := &nullDTO{Name: "a", Score: &score, Created: created, Level: 3}
+		got := &nullModel{Nick: sql.NullString{String: "keep", Valid: true}}
+		if err := AssignStructE(src, got); err != nil {
+			t.Fatal(err)
+		}
+		want := &nullModel{
+			Name:    sql.NullString{String: "a", Valid: true},
+			Score:   sql.NullFloat64{Float64: score, Valid: true},
+			Created: sql.NullTime{Time: created, Valid: true},
+			Nick:    sql.NullString{String: "keep", Valid: true},
+			Level:   sql.Null[int32]{V: 3, Valid: true},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("AssignStructE() = %+v, want %+v", got, want)
+		}
+
+		if err := AssignStructE(src, got, WithOverwriteZero()); err != nil {
+			t.Fatal(err)
+		}
+		if got.Age.Valid || got.Nick.Valid {
+			t.Errorf("AssignStructE() Age = %+v, Nick = %+v, want invalid", got.Age, got.Nick)
+		}
+	})
+
+	t.Run("to map", func(t *testing.T) {
+		m, err := StructToMap(&nullModel{Name: sql.NullString{String: "a", Valid: true}, Nick: sql.NullString{String: "x"}}, WithOnlyFields("Name", "Nick"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := map[string]interface{}{"Name": "a"}; !reflect.DeepEqual(m, want) {
+			t.Errorf("StructToMap() = %v, want %v", m, want)
+		}
+	})
+}
+
+type benchProfile struct {
+	Avatar string
+	Tags   []string
+}
+
+type benchUser struct {
+	ID       int64
+	Name     string
+	Email    string
+	Age      int32
+	Created  time.Time
+	Profile  benchProfile
+	Settings map[string]string
+	Manager  *benchProfile
+}
+
+type benchUserDTO struct {
+	ID       int64
+	Name     string
+	Email    string
+	Age      int64
+	Created  time.Time
+	Profile  benchProfile
+	Settings map[string]string
+	Manager  *benchProfile
+}
+
+func newBenchUser() *benchUser {
+	return &benchUser{
+		ID: 1, Name: "a", Email: "a@b.com", Age: 20, Created: time.Now(),
+		Profile:  benchProfile{Avatar: "x", Tags: []string{"t"}},
+		Settings: map[string]string{"k": "v"},
+		Manager:  &benchProfile{Avatar: "m"},
+	}
+}
+
+func BenchmarkAssignStruct(b *testing.B) {
+	src := newBenchUser()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var dst benchUserDTO
+		if err := AssignStructE(src, &dst); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDeepCopy(b *testing.B) {
+	src := newBenchUser()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = DeepCopy(src)
+	}
+}
@@ -0,0 +1,198 @@
+package copy
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/ChangSZ/golib/reflectutil"
+	"github.com/ChangSZ/golib/tagparse"
+)
+
+// Change 一个字段的变化
+type Change struct {
+	Path   string              // 字段路径, 嵌套结构体以"."连接, 如"Profile.Avatar"; 内嵌结构体的字段不带内嵌类型名
+	Field  reflect.StructField // 叶子字段, 可以读取标签, 如mask.FieldFunc(c.Field)
+	Before interface{}
+	After  interface{}
+}
+
+// FieldChange 同Change
+type FieldChange = Change
+
+// Diff 比较同一结构体类型的两个值, 返回发生变化的叶子字段
+//
+// - before、after可以是结构体或结构体指针, 其中一个为nil时视为零值, 用于记录创建和删除
+// - 遍历规则与AssignStruct一致: 嵌套结构体和结构体指针递归比较, 标记为`copy:"-"`的字段跳过
+// - 结构体切片按元素比较, 路径如"Items[1].Name", 新增或删除的元素整体作为一个变化
+// - time.Time按Equal比较, 其他切片、map等作为整体比较
+// - database/sql的Null类型作为一个值比较, Before、After为其值, NULL为nil
+// - ignore为跳过的字段, 规则同WithIgnoreFields
+// - 嵌套层数超过DefaultMaxDepth(如存在循环引用)时返回ErrMaxDepth
+func Diff(before, after interface{}, ignore ...string) ([]Change, error) {
+	bv, av := reflect.ValueOf(before), reflect.ValueOf(after)
+	if !bv.IsValid() && !av.IsValid() {
+		return nil, nil
+	}
+	var t reflect.Type
+	if bv.IsValid() {
+		t = bv.Type()
+	} else {
+		t = av.Type()
+	}
+	t = indirectType(t)
+	if bv.IsValid() && av.IsValid() && indirectType(bv.Type()) != indirectType(av.Type()) {
+		return nil, fmt.Errorf("copy: diff %v with %v", bv.Type(), av.Type())
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("copy: diff non-struct type %v", t)
+	}
+	cfg := &assignConfig{maxDepth: DefaultMaxDepth}
+	WithIgnoreFields(ignore...)(cfg)
+
+	var changes []Change
+	if err := diffStruct(t, indirectOrZero(bv, t), indirectOrZero(av, t), cfg, "", &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// indirectOrZero 解引用指针, nil时返回t的零值
+func indirectOrZero(v reflect.Value, t reflect.Type) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return reflect.Zero(t)
+	}
+	return v
+}
+
+func diffStruct(t reflect.Type, before, after reflect.Value, cfg *assignConfig, prefix string, changes *[]Change) error {
+	leave, err := cfg.enter(prefix)
+	if err != nil {
+		return err
+	}
+	defer leave()
+
+	for _, f := range reflectutil.Fields(t) {
+		ft := f.Type
+		// 内嵌结构体的字段已被提升
+		if f.Anonymous && indirectType(ft).Kind() == reflect.Struct && indirectType(ft) != timeType {
+			continue
+		}
+		path := f.Name
+		if prefix != "" {
+			path = prefix + "." + f.Name
+		}
+		if cfg.ignored(f.Name, path) || tagparse.Get(f.StructField, TagName).Skip {
+			continue
+		}
+		bf := reflectutil.FieldByIndex(before, f.Index, false)
+		af := reflectutil.FieldByIndex(after, f.Index, false)
+
+		if et := indirectType(ft); et.Kind() == reflect.Struct && et != timeType && !isNull(et) {
+			// 两边都是nil指针或指向同一个对象时不再展开, 避免自引用类型无限递归
+			if ft.Kind() == reflect.Ptr && (isNil(bf) && isNil(af) || samePointer(bf, af)) {
+				continue
+			}
+			if err := diffStruct(et, indirectOrZero(bf, et), indirectOrZero(af, et), cfg, path, changes); err != nil {
+				return err
+			}
+			continue
+		}
+		if ft.Kind() == reflect.Slice && isStructElem(ft.Elem()) {
+			if err := diffSlice(ft, bf, af, cfg, f.StructField, path, changes); err != nil {
+				return err
+			}
+			continue
+		}
+		bi, ai := fieldInterface(bf, ft), fieldInterface(af, ft)
+		if !equalValue(bi, ai) {
+			*changes = append(*changes, Change{Path: path, Field: f.StructField, Before: bi, After: ai})
+		}
+	}
+	return nil
+}
+
+// diffSlice 按元素比较结构体切片, 与AssignStruct按元素合并一致; 只存在于一侧的元素整体作为一个变化
+func diffSlice(t reflect.Type, before, after reflect.Value, cfg *assignConfig, field reflect.StructField, path string, changes *[]Change) error {
+	if !before.IsValid() {
+		before = reflect.Zero(t)
+	}
+	if !after.IsValid() {
+		after = reflect.Zero(t)
+	}
+	et := indirectType(t.Elem())
+	for i := 0; i < before.Len() || i < after.Len(); i++ {
+		elemPath := fmt.Sprintf("%s[%d]", path, i)
+		if i >= before.Len() || i >= after.Len() {
+			c := Change{Path: elemPath, Field: field}
+			if i < before.Len() {
+				c.Before = fieldInterface(before.Index(i), t.Elem())
+			} else {
+				c.After = fieldInterface(after.Index(i), t.Elem())
+			}
+			*changes = append(*changes, c)
+			continue
+		}
+		bi, ai := before.Index(i), after.Index(i)
+		if bi.Kind() == reflect.Ptr && (isNil(bi) && isNil(ai) || samePointer(bi, ai)) {
+			continue
+		}
+		if err := diffStruct(et, indirectOrZero(bi, et), indirectOrZero(ai, et), cfg, elemPath, changes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isStructElem 切片元素是否按结构体逐字段比较
+func isStructElem(t reflect.Type) bool {
+	t = indirectType(t)
+	return t.Kind() == reflect.Struct && t != timeType && !isNull(t)
+}
+
+func samePointer(a, b reflect.Value) bool {
+	return a.IsValid() && b.IsValid() && a.Kind() == reflect.Ptr && b.Kind() == reflect.Ptr && a.Pointer() == b.Pointer()
+}
+
+// fieldInterface 路径上存在nil内嵌指针时取零值, 指针字段取指向的值; Null类型取其值, NULL为nil
+func fieldInterface(v reflect.Value, t reflect.Type) interface{} {
+	if !v.IsValid() {
+		v = reflect.Zero(t)
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if isNull(v.Type()) {
+		if !v.Field(1).Bool() {
+			return nil
+		}
+		v = v.Field(0)
+	}
+	return v.Interface()
+}
+
+func isNil(v reflect.Value) bool {
+	return !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil())
+}
+
+func equalValue(a, b interface{}) bool {
+	if at, ok := a.(interface{ Equal(time.Time) bool }); ok {
+		if bt, ok := b.(time.Time); ok {
+			return at.Equal(bt)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
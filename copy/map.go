@@ -0,0 +1,283 @@
+package copy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ChangSZ/golib/reflectutil"
+	"github.com/ChangSZ/golib/tagparse"
+)
+
+var (
+	looseMapType   = reflect.TypeOf(map[string]interface{}(nil))
+	looseSliceType = reflect.TypeOf([]interface{}(nil))
+)
+
+// AssignMap 将src中有值的key赋值到dst的对应字段, 用于将JSON解码得到的map转换为结构体
+//
+// - key与字段名或copy标签中的名称匹配, 优先精确匹配, 其次忽略大小写, 如"userName"匹配UserName
+// - 值为零值或nil时跳过, 见WithOverwriteZero
+// - 嵌套的map赋值到结构体(或结构体指针)字段, []interface{}按元素赋值到切片字段
+// - JSON中的数值(float64)按AssignStruct的规则转换为字段的数值类型, 见WithStrictNumeric
+// - 其他选项(WithIgnoreFields、WithFieldHook、WithConverter等)与AssignStruct一致
+func AssignMap(src map[string]interface{}, dst interface{}, opts ...AssignOption) {
+	if err := AssignMapE(src, dst, opts...); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// AssignMapE 同AssignMap, 出错时返回错误而不是打印, 遇到第一个错误即停止
+func AssignMapE(src map[string]interface{}, dst interface{}, opts ...AssignOption) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recovered from panic: %v", r)
+		}
+	}()
+	if dst == nil || reflect.ValueOf(dst).Kind() != reflect.Ptr || reflect.ValueOf(dst).IsNil() {
+		return errors.New("dst must be a non-nil pointer to struct")
+	}
+	v := reflect.ValueOf(dst).Elem()
+	if v.Kind() != reflect.Struct {
+		return errors.New("dst must be a non-nil pointer to struct")
+	}
+	cfg := &assignConfig{maxDepth: DefaultMaxDepth}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return assignMapFields(src, v, cfg, "")
+}
+
+// lookupKey 先精确匹配key, 再忽略大小写匹配; lower为小写key到原key的索引, 按需构建
+func lookupKey(src map[string]interface{}, lower *map[string]string, key string) (interface{}, bool) {
+	if v, ok := src[key]; ok {
+		return v, true
+	}
+	if *lower == nil {
+		*lower = make(map[string]string, len(src))
+		for k := range src {
+			lk := strings.ToLower(k)
+			if _, ok := (*lower)[lk]; !ok {
+				(*lower)[lk] = k
+			}
+		}
+	}
+	k, ok := (*lower)[strings.ToLower(key)]
+	if !ok {
+		return nil, false
+	}
+	return src[k], true
+}
+
+// assignMapFields 将src赋值到结构体dst, 内嵌结构体的字段与外层字段一样从src中读取
+func assignMapFields(src map[string]interface{}, dst reflect.Value, cfg *assignConfig, prefix string) error {
+	leave, err := cfg.enter(prefix)
+	if err != nil {
+		return err
+	}
+	defer leave()
+
+	var lower map[string]string
+	for _, f := range reflectutil.Fields(dst.Type()) {
+		tag := tagparse.Get(f.StructField, TagName)
+		path := f.Name
+		if prefix != "" {
+			path = prefix + "." + f.Name
+		}
+		if tag.Skip || cfg.ignored(f.Name, path) || !cfg.selected(path) {
+			continue
+		}
+		val, ok := lookupKey(src, &lower, tag.NameOr(f.Name))
+		if !ok {
+			continue
+		}
+		sv := reflect.ValueOf(val)
+		if !cfg.overwriteZero && isZero(sv) {
+			continue
+		}
+		fv := reflectutil.FieldByIndex(dst, f.Index, true)
+		if !fv.IsValid() {
+			continue
+		}
+		if !sv.IsValid() {
+			// 只有WithOverwriteZero时会走到这里
+			fv.Set(reflect.Zero(fv.Type()))
+			continue
+		}
+		if fn, ok := cfg.hook(f.Name, path); ok {
+			if sv, err = fn(sv); err != nil {
+				return fmt.Errorf("field %s: %w", path, err)
+			}
+			if !sv.IsValid() {
+				continue
+			}
+		}
+		if err := assignLoose(sv, fv, cfg, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignLoose 在assignValue的基础上支持map[string]interface{}到结构体、[]interface{}到切片的赋值
+func assignLoose(src, dst reflect.Value, cfg *assignConfig, path string) error {
+	if src.Type() != looseMapType && src.Type() != looseSliceType || src.Type() == dst.Type() {
+		return assignValue(src, dst, cfg, path)
+	}
+	switch dst.Kind() {
+	case reflect.Interface:
+		if src.Type().AssignableTo(dst.Type()) {
+			dst.Set(src)
+		}
+		return nil
+	case reflect.Ptr:
+		elem := reflect.New(dst.Type().Elem())
+		// 在副本上合并, 不修改dst原来指向的对象
+		if !dst.IsNil() {
+			elem.Elem().Set(dst.Elem())
+		}
+		if err := assignLoose(src, elem.Elem(), cfg, path); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	case reflect.Struct:
+		if m, ok := src.Interface().(map[string]interface{}); ok {
+			return assignMapFields(m, dst, cfg, path)
+		}
+	case reflect.Slice:
+		if src.Type() != looseSliceType {
+			break
+		}
+		s := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			ev := src.Index(i).Elem()
+			if !ev.IsValid() {
+				continue
+			}
+			if err := assignLoose(ev, s.Index(i), cfg, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(s)
+		return nil
+	}
+	return assignValue(src, dst, cfg, path)
+}
+
+// WithFlatten StructToMap输出一层map, 嵌套结构体的字段使用以"."连接的key, 如"Address.City"; 默认输出嵌套的map
+func WithFlatten() AssignOption {
+	return func(c *assignConfig) {
+		c.flatten = true
+	}
+}
+
+// StructToMap 将src中有值的字段输出为map, src为结构体或结构体指针, 用于构造部分更新的参数:
+//
+// - key为字段名或copy标签中的名称, 标记为copy:"-"的字段不输出
+// - 零值字段默认跳过, 见WithOverwriteZero
+// - 嵌套结构体(含指针)输出为嵌套的map, 或使用WithFlatten输出"Address.City"形式的key;
+// 没有可导出字段的结构体(如time.Time)作为值输出
+// - 内嵌结构体的字段提升到外层
+// - 切片和map作为值输出, 与src共享
+// - 支持WithIgnoreFields、WithFieldHook, path为字段名路径而不是输出的key
+//
+//	update, _ := copy.StructToMap(req, copy.WithFlatten())
+//	coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": update})
+func StructToMap(src interface{}, opts ...AssignOption) (m map[string]interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recovered from panic: %v", r)
+		}
+	}()
+	v := reflectutil.Indirect(reflect.ValueOf(src), false)
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return nil, errors.New("src must be a struct or a non-nil pointer to struct")
+	}
+	cfg := &assignConfig{maxDepth: DefaultMaxDepth}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	m = make(map[string]interface{})
+	if err := structToMap(v, cfg, "", "", m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// structToMap 将v的字段写入out, prefix为字段路径, keyPrefix为WithFlatten时key的前缀
+func structToMap(v reflect.Value, cfg *assignConfig, prefix, keyPrefix string, out map[string]interface{}) error {
+	leave, err := cfg.enter(prefix)
+	if err != nil {
+		return err
+	}
+	defer leave()
+
+	for _, f := range reflectutil.Fields(v.Type()) {
+		tag := tagparse.Get(f.StructField, TagName)
+		path := f.Name
+		if prefix != "" {
+			path = prefix + "." + f.Name
+		}
+		if tag.Skip || cfg.ignored(f.Name, path) || !cfg.selected(path) {
+			continue
+		}
+		fv := reflectutil.FieldByIndex(v, f.Index, false)
+		if !cfg.overwriteZero && isZero(fv) {
+			continue
+		}
+		key := keyPrefix + tag.NameOr(f.Name)
+		if !fv.IsValid() {
+			// 路径上的内嵌指针为nil, 只有WithOverwriteZero时会走到这里
+			out[key] = reflect.Zero(f.Type).Interface()
+			continue
+		}
+		if fn, ok := cfg.hook(f.Name, path); ok {
+			if fv, err = fn(fv); err != nil {
+				return fmt.Errorf("field %s: %w", path, err)
+			}
+			if !fv.IsValid() {
+				continue
+			}
+		}
+		if err := fieldToMap(fv, cfg, path, key, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldToMap 将字段值fv写入out[key], 嵌套结构体按WithFlatten展开或输出为嵌套的map
+func fieldToMap(fv reflect.Value, cfg *assignConfig, path, key string, out map[string]interface{}) error {
+	sv := fv
+	if sv.Kind() == reflect.Ptr && !sv.IsNil() {
+		unmark, err := cfg.mark(sv, path)
+		if err != nil {
+			return err
+		}
+		defer unmark()
+		sv = sv.Elem()
+	}
+	// Null类型输出其值, NULL输出nil
+	if isNull(sv.Type()) {
+		out[key] = nil
+		if sv.Field(1).Bool() {
+			out[key] = sv.Field(0).Interface()
+		}
+		return nil
+	}
+	if sv.Kind() != reflect.Struct || len(reflectutil.Fields(sv.Type())) == 0 {
+		out[key] = fv.Interface()
+		return nil
+	}
+	if cfg.flatten {
+		return structToMap(sv, cfg, path, key+".", out)
+	}
+	nested := make(map[string]interface{})
+	if err := structToMap(sv, cfg, path, "", nested); err != nil {
+		return err
+	}
+	out[key] = nested
+	return nil
+}
@@ -0,0 +1,113 @@
+package copy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrConflict ErrorOnConflict策略下同一字段在dst和src中都有值且不相等
+var ErrConflict = errors.New("copy: merge conflict")
+
+// MergeStrategy Merge处理字段冲突的策略, 冲突指dst和src中同一个字段都不是零值
+//
+// 嵌套结构体总是按字段递归合并, 策略作用于其中的叶子字段; DstWins和ErrorOnConflict下map、切片作为整体比较
+type MergeStrategy int
+
+const (
+	// SrcWins src的非零字段覆盖dst, 多个src时后面的优先, 与依次调用AssignStruct相同
+	SrcWins MergeStrategy = iota
+	// DstWins 只填充dst中为零值的字段, 已有的值保留; 多个src时前面的优先
+	DstWins
+	// ErrorOnConflict 冲突且值不相等时返回ErrConflict, 错误中包含字段路径; 值相等时不算冲突
+	ErrorOnConflict
+	// DeepMerge 在SrcWins的基础上, map中已存在的key递归合并(同WithDeepMergeMaps), 类型相同的切片追加到dst之后
+	DeepMerge
+)
+
+func (s MergeStrategy) String() string {
+	switch s {
+	case SrcWins:
+		return "SrcWins"
+	case DstWins:
+		return "DstWins"
+	case ErrorOnConflict:
+		return "ErrorOnConflict"
+	case DeepMerge:
+		return "DeepMerge"
+	}
+	return fmt.Sprintf("MergeStrategy(%d)", int(s))
+}
+
+// Merge 依次将srcs合并到dst, 冲突时src优先, 见MergeWith
+func Merge(dst interface{}, srcs ...interface{}) error {
+	return MergeWith(SrcWins, dst, srcs...)
+}
+
+// MergeWith 按strategy依次将srcs合并到dst, 字段的对应和转换规则与AssignStruct相同, src中的零值字段总是跳过
+//
+// dst必须是非nil结构体指针; srcs为结构体指针, 类型可以与dst不同, 为nil的src跳过.
+// 返回错误时dst可能已被部分修改
+//
+//	// 配置优先级: 命令行 > 环境变量 > 配置文件 > 默认值
+//	err := copy.MergeWith(copy.DstWins, &cfg, flagCfg, envCfg, fileCfg, defaultCfg)
+func MergeWith(strategy MergeStrategy, dst interface{}, srcs ...interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recovered from panic: %v", r)
+		}
+	}()
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() || dv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("copy: dst must be a non-nil struct pointer, got %T", dst)
+	}
+	for i, src := range srcs {
+		sv := reflect.ValueOf(src)
+		if !sv.IsValid() || sv.Kind() == reflect.Ptr && sv.IsNil() {
+			continue
+		}
+		if sv.Kind() != reflect.Ptr || sv.Elem().Kind() != reflect.Struct {
+			return fmt.Errorf("copy: src %d must be a struct pointer, got %T", i, src)
+		}
+		cfg := &assignConfig{maxDepth: DefaultMaxDepth, strategy: strategy}
+		if strategy == DeepMerge {
+			cfg.deepMergeMaps = true
+			cfg.appendSlices = true
+		}
+		if err := assignStructFields(sv.Elem(), dv.Elem(), cfg, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve 按合并策略处理叶子字段的冲突, skip为true时保留dst的值
+func (c *assignConfig) resolve(src, dst reflect.Value, path string) (skip bool, err error) {
+	if c.strategy != DstWins && c.strategy != ErrorOnConflict || recursesInto(src, dst) {
+		return false, nil
+	}
+	if isZero(dst) {
+		return false, nil
+	}
+	if c.strategy == DstWins {
+		return true, nil
+	}
+	// 先赋值到临时变量, 按dst的类型比较, 如int32(1)与int64(1)不算冲突
+	tmp := reflect.New(dst.Type()).Elem()
+	if err := assignValue(src, tmp, c, path); err != nil {
+		return false, err
+	}
+	if !equalValue(tmp.Interface(), dst.Interface()) {
+		return false, fmt.Errorf("field %s: %w: %v != %v", path, ErrConflict, dst.Interface(), tmp.Interface())
+	}
+	return true, nil
+}
+
+// recursesInto assignValue是否会按字段递归合并src, 与assignValue的判断一致
+func recursesInto(src, dst reflect.Value) bool {
+	t := src.Type()
+	if t != dst.Type() && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType && !isNull(t)
+}
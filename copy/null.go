@@ -0,0 +1,53 @@
+package copy
+
+import (
+	"reflect"
+
+	"github.com/ChangSZ/golib/reflectutil"
+)
+
+// isNull t是否为database/sql的Null类型, 如sql.NullString、sql.NullTime、sql.Null[T];
+// 这些类型的第一个字段为值, 第二个字段Valid为false时表示NULL
+func isNull(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.PkgPath() == "database/sql" && t.NumField() == 2 &&
+		t.Field(1).Name == "Valid" && t.Field(1).Type.Kind() == reflect.Bool
+}
+
+// isZero 同reflectutil.IsZero, Valid为false的Null类型也视为零值
+func isZero(v reflect.Value) bool {
+	if reflectutil.IsZero(v) {
+		return true
+	}
+	return isNull(v.Type()) && !v.Field(1).Bool()
+}
+
+// assignNull 处理Null类型与普通类型之间的赋值, src和dst类型相同时按普通结构体处理, ok为false表示不涉及Null类型
+//
+//   - sql.NullString => string、*string等: Valid时按值赋值, 否则视为零值
+//   - string等 => sql.NullString: 零值转换为Valid为false, 否则Valid为true
+func assignNull(src, dst reflect.Value, cfg *assignConfig, path string) (ok bool, err error) {
+	switch {
+	case src.Type() == dst.Type():
+		return false, nil
+	case isNull(src.Type()):
+		// 只有WithOverwriteZero时会遇到无效值
+		if !src.Field(1).Bool() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return true, nil
+		}
+		return true, assignValue(src.Field(0), dst, cfg, path)
+	case isNull(dst.Type()):
+		if isZero(src) {
+			dst.Set(reflect.Zero(dst.Type()))
+			return true, nil
+		}
+		v := reflect.New(dst.Type()).Elem()
+		if err := assignValue(src, v.Field(0), cfg, path); err != nil {
+			return true, err
+		}
+		v.Field(1).SetBool(true)
+		dst.Set(v)
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,77 @@
+package copy
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/ChangSZ/golib/tagparse"
+)
+
+// fieldPlan AssignStruct中src的一个字段与dst字段的对应关系, 只与类型有关, 按类型缓存
+type fieldPlan struct {
+	index    int    // src字段下标
+	name     string // src字段名, 用于WithIgnoreFields、WithFieldHook和错误信息
+	dstIndex []int  // dst中对应字段的下标路径, 可能经过内嵌结构体
+	inline   bool   // src的内嵌结构体在dst中不存在, 将其字段展开赋值到dst
+	missing  bool   // dst中没有对应字段, 只在WithStrict时报告
+}
+
+type planKey struct {
+	src, dst reflect.Type
+}
+
+// assignPlans planKey -> []fieldPlan
+var assignPlans sync.Map
+
+// assignPlan 返回srcType到dstType的字段对应关系; 标签解析和按名称查找dst字段只在第一次执行,
+// 与配置有关的规则(忽略字段、hook、转换函数)仍在每次赋值时判断
+func assignPlan(srcType, dstType reflect.Type) []fieldPlan {
+	key := planKey{src: srcType, dst: dstType}
+	if plans, ok := assignPlans.Load(key); ok {
+		return plans.([]fieldPlan)
+	}
+	plans := make([]fieldPlan, 0, srcType.NumField())
+	for i := 0; i < srcType.NumField(); i++ {
+		field := srcType.Field(i)
+		tag := tagparse.Get(field, TagName)
+		if tag.Skip {
+			continue
+		}
+		plan := fieldPlan{index: i, name: field.Name}
+		df, ok := dstType.FieldByName(tag.NameOr(field.Name))
+		switch {
+		case ok:
+			// dst字段标记为copy:"-"时同样跳过, 如dst中由数据库维护的创建时间
+			if tagparse.Get(df, TagName).Skip {
+				continue
+			}
+			plan.dstIndex = df.Index
+		case field.Anonymous && field.Type.Kind() == reflect.Struct:
+			// 字段是内嵌的结构体但在dst中不存在, 将其子字段拷贝到dst中
+			plan.inline = true
+		case field.IsExported():
+			plan.missing = true
+		default:
+			continue
+		}
+		plans = append(plans, plan)
+	}
+	actual, _ := assignPlans.LoadOrStore(key, plans)
+	return actual.([]fieldPlan)
+}
+
+// exportedFields reflect.Type -> []bool, 结构体各字段是否导出
+var exportedFields sync.Map
+
+// exported 返回结构体类型t各字段是否导出, DeepCopy默认只拷贝导出字段
+func exported(t reflect.Type) []bool {
+	if flags, ok := exportedFields.Load(t); ok {
+		return flags.([]bool)
+	}
+	flags := make([]bool, t.NumField())
+	for i := range flags {
+		flags[i] = t.Field(i).IsExported()
+	}
+	actual, _ := exportedFields.LoadOrStore(t, flags)
+	return actual.([]bool)
+}
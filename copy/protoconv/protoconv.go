@@ -0,0 +1,98 @@
+package protoconv
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/ChangSZ/golib/conv"
+)
+
+var once sync.Once
+
+// Register 通过conv.Register注册protobuf常用类型(well-known types)与Go类型之间的转换,
+// copy.AssignStruct和decode在proto消息与领域结构体之间赋值时即可处理这些字段; 重复调用无副作用
+//
+//   - *timestamppb.Timestamp <=> time.Time、*time.Time
+//   - *durationpb.Duration <=> time.Duration
+//   - wrapperspb的各类型 <=> 对应的Go类型及其指针, 如*wrapperspb.StringValue <=> string、*string
+//
+// nil的proto值转换为零值或nil指针, 零值的time.Time转换为nil, 指针为nil时转换为nil的包装类型
+//
+//	func init() { protoconv.Register() }
+func Register() {
+	once.Do(register)
+}
+
+func register() {
+	conv.Register(func(ts *timestamppb.Timestamp) time.Time {
+		if ts == nil {
+			return time.Time{}
+		}
+		return ts.AsTime()
+	})
+	conv.Register(func(ts *timestamppb.Timestamp) *time.Time {
+		if ts == nil {
+			return nil
+		}
+		t := ts.AsTime()
+		return &t
+	})
+	conv.Register(func(t time.Time) *timestamppb.Timestamp {
+		if t.IsZero() {
+			return nil
+		}
+		return timestamppb.New(t)
+	})
+	conv.Register(func(t *time.Time) *timestamppb.Timestamp {
+		if t == nil || t.IsZero() {
+			return nil
+		}
+		return timestamppb.New(*t)
+	})
+
+	conv.Register(func(d *durationpb.Duration) time.Duration {
+		if d == nil {
+			return 0
+		}
+		return d.AsDuration()
+	})
+	conv.Register(func(d time.Duration) *durationpb.Duration {
+		return durationpb.New(d)
+	})
+
+	registerWrapper(wrapperspb.String, (*wrapperspb.StringValue).GetValue)
+	registerWrapper(wrapperspb.Bool, (*wrapperspb.BoolValue).GetValue)
+	registerWrapper(wrapperspb.Int32, (*wrapperspb.Int32Value).GetValue)
+	registerWrapper(wrapperspb.Int64, (*wrapperspb.Int64Value).GetValue)
+	registerWrapper(wrapperspb.UInt32, (*wrapperspb.UInt32Value).GetValue)
+	registerWrapper(wrapperspb.UInt64, (*wrapperspb.UInt64Value).GetValue)
+	registerWrapper(wrapperspb.Float, (*wrapperspb.FloatValue).GetValue)
+	registerWrapper(wrapperspb.Double, (*wrapperspb.DoubleValue).GetValue)
+	registerWrapper(wrapperspb.Bytes, (*wrapperspb.BytesValue).GetValue)
+}
+
+// registerWrapper 注册包装类型W与T、*T之间的双向转换, wrap为wrapperspb的构造函数, get为GetValue方法
+//
+// GetValue对nil的包装类型返回零值, 因此W => T不需要判断nil
+func registerWrapper[T any, W comparable](wrap func(T) W, get func(W) T) {
+	var nilW W
+	conv.Register(get)
+	conv.Register(wrap)
+	conv.Register(func(w W) *T {
+		if w == nilW {
+			return nil
+		}
+		v := get(w)
+		return &v
+	})
+	conv.Register(func(p *T) W {
+		if p == nil {
+			return nilW
+		}
+		return wrap(*p)
+	})
+}
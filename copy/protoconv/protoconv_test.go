@@ -0,0 +1,110 @@
+package protoconv
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/ChangSZ/golib/copy"
+)
+
+// pbUser 模拟生成的proto消息中的字段类型
+type pbUser struct {
+	Name      string
+	Nick      *wrapperspb.StringValue
+	Age       *wrapperspb.Int32Value
+	Score     *wrapperspb.DoubleValue
+	Vip       *wrapperspb.BoolValue
+	Avatar    *wrapperspb.BytesValue
+	CreatedAt *timestamppb.Timestamp
+	DeletedAt *timestamppb.Timestamp
+	Timeout   *durationpb.Duration
+}
+
+type domainUser struct {
+	Name      string
+	Nick      *string
+	Age       int32
+	Score     float64
+	Vip       *bool
+	Avatar    []byte
+	CreatedAt time.Time
+	DeletedAt *time.Time
+	Timeout   time.Duration
+}
+
+func TestAssignStruct(t *testing.T) {
+	Register()
+	Register()
+	created := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	nick, vip := "jj", true
+
+	t.Run("proto to domain", func(t *testing.T) {
+		src := &pbUser{
+			Name:      "jack",
+			Nick:      wrapperspb.String("jj"),
+			Age:       wrapperspb.Int32(18),
+			Score:     wrapperspb.Double(9.5),
+			Vip:       wrapperspb.Bool(true),
+			Avatar:    wrapperspb.Bytes([]byte{1}),
+			CreatedAt: timestamppb.New(created),
+			Timeout:   durationpb.New(time.Minute),
+		}
+		var dst domainUser
+		if err := copy.AssignStructE(src, &dst); err != nil {
+			t.Fatalf("AssignStructE() error = %v", err)
+		}
+		if dst.Name != "jack" || dst.Nick == nil || *dst.Nick != "jj" || dst.Age != 18 || dst.Score != 9.5 ||
+			dst.Vip == nil || !*dst.Vip || len(dst.Avatar) != 1 || !dst.CreatedAt.Equal(created) ||
+			dst.DeletedAt != nil || dst.Timeout != time.Minute {
+			t.Errorf("AssignStructE() = %+v", dst)
+		}
+	})
+
+	t.Run("domain to proto", func(t *testing.T) {
+		src := &domainUser{Name: "jack", Nick: &nick, Age: 18, Vip: &vip, CreatedAt: created, DeletedAt: &created}
+		var dst pbUser
+		if err := copy.AssignStructE(src, &dst); err != nil {
+			t.Fatalf("AssignStructE() error = %v", err)
+		}
+		want := &pbUser{
+			Name:      "jack",
+			Nick:      wrapperspb.String("jj"),
+			Age:       wrapperspb.Int32(18),
+			Vip:       wrapperspb.Bool(true),
+			CreatedAt: timestamppb.New(created),
+			DeletedAt: timestamppb.New(created),
+		}
+		for _, c := range []struct {
+			name      string
+			got, want proto.Message
+		}{
+			{"Nick", dst.Nick, want.Nick},
+			{"Age", dst.Age, want.Age},
+			{"Vip", dst.Vip, want.Vip},
+			{"CreatedAt", dst.CreatedAt, want.CreatedAt},
+			{"DeletedAt", dst.DeletedAt, want.DeletedAt},
+		} {
+			if !proto.Equal(c.got, c.want) {
+				t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+			}
+		}
+		if dst.Score != nil || dst.Timeout != nil {
+			t.Errorf("zero fields should be skipped: %+v", dst)
+		}
+	})
+
+	t.Run("overwrite zero", func(t *testing.T) {
+		dst := &domainUser{Nick: &nick, CreatedAt: created}
+		if err := copy.AssignStructE(&pbUser{}, dst, copy.WithOverwriteZero()); err != nil {
+			t.Fatalf("AssignStructE() error = %v", err)
+		}
+		if dst.Nick != nil || !dst.CreatedAt.IsZero() {
+			t.Errorf("AssignStructE() = %+v", dst)
+		}
+	})
+}
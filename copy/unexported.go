@@ -0,0 +1,59 @@
+package copy
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// DeepCopyOption DeepCopyWith的可选参数
+type DeepCopyOption func(*deepCopier)
+
+type deepCopier struct {
+	alloc      Allocator
+	unexported bool
+}
+
+// WithAllocator 副本中的指针和切片由alloc分配, 见DeepCopyWithAllocator
+func WithAllocator(alloc Allocator) DeepCopyOption {
+	return func(c *deepCopier) {
+		if alloc != nil {
+			c.alloc = alloc
+		}
+	}
+}
+
+// WithUnexported 同时拷贝未导出字段, 默认跳过未导出字段, 副本中对应字段为零值
+//
+// 未导出字段通过unsafe读写, 要求字段所在的值可寻址; 不可寻址的结构体(如直接传入的结构体值、
+// map的value、接口中保存的结构体)会先浅拷贝到临时变量再读取, 额外多一次分配.
+//
+// 未导出字段中的锁、通道、函数、unsafe.Pointer等不会被深拷贝: 锁按值复制(包括加锁状态),
+// 通道、函数与原值共享. 这类类型应实现Interface自行决定如何拷贝, 实现了Interface的类型
+// 不再逐字段拷贝
+func WithUnexported() DeepCopyOption {
+	return func(c *deepCopier) {
+		c.unexported = true
+	}
+}
+
+// DeepCopyWith 同DeepCopy, 可以通过opts指定分配方式、是否拷贝未导出字段
+//
+//	cpy := copy.DeepCopyWith(cache, copy.WithUnexported()).(*Cache)
+func DeepCopyWith(src interface{}, opts ...DeepCopyOption) interface{} {
+	if src == nil {
+		return nil
+	}
+	c := &deepCopier{alloc: heapAllocator{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	original := reflect.ValueOf(src)
+	cpy := reflect.New(original.Type()).Elem()
+	copyRecursive(original, cpy, c)
+	return cpy.Interface()
+}
+
+// exposeField 返回可读写的未导出字段, v必须可寻址
+func exposeField(v reflect.Value) reflect.Value {
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
@@ -0,0 +1,87 @@
+package ctxutil
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Detach 返回保留ctx中的值但不继承取消和截止时间的新context, 用于请求结束后仍需执行的异步任务
+func Detach(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
+
+// Merge 合并两个context, 任意一个结束时返回的context随之结束
+//
+// 取值时优先从a中查找, 截止时间取两者中较早的一个, 结束原因可以通过context.Cause获取
+func Merge(a, b context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(a)
+	stop := context.AfterFunc(b, func() {
+		cancel(context.Cause(b))
+	})
+	return &mergedCtx{Context: ctx, other: b}, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
+
+type mergedCtx struct {
+	context.Context
+	other context.Context
+}
+
+func (c *mergedCtx) Deadline() (time.Time, bool) {
+	d1, ok1 := c.Context.Deadline()
+	d2, ok2 := c.other.Deadline()
+	switch {
+	case !ok1:
+		return d2, ok2
+	case !ok2:
+		return d1, ok1
+	case d2.Before(d1):
+		return d2, true
+	}
+	return d1, true
+}
+
+func (c *mergedCtx) Value(key interface{}) interface{} {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.other.Value(key)
+}
+
+// TimeoutError 超时原因, 同时满足errors.Is(err, context.DeadlineExceeded)
+type TimeoutError struct {
+	Op      string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Op == "" {
+		return fmt.Sprintf("timeout after %s", e.Timeout)
+	}
+	return fmt.Sprintf("%s: timeout after %s", e.Op, e.Timeout)
+}
+
+func (e *TimeoutError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
+
+// WithTimeoutCause 同context.WithTimeoutCause, cause为nil时使用不带操作名的TimeoutError
+func WithTimeoutCause(ctx context.Context, timeout time.Duration, cause error) (context.Context, context.CancelFunc) {
+	if cause == nil {
+		cause = &TimeoutError{Timeout: timeout}
+	}
+	return context.WithTimeoutCause(ctx, timeout, cause)
+}
+
+// WithOpTimeout 超时后context.Cause返回带op名称的TimeoutError, 便于定位是哪一步超时
+func WithOpTimeout(ctx context.Context, op string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(ctx, timeout, &TimeoutError{Op: op, Timeout: timeout})
+}
+
+// Cause 返回ctx结束的原因, 未结束时返回nil
+func Cause(ctx context.Context) error {
+	return context.Cause(ctx)
+}
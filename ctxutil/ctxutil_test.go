@@ -0,0 +1,93 @@
+package ctxutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	userKey := NewKey[string]("user")
+	otherKey := NewKey[string]("user")
+	ctx := Set(context.Background(), userKey, "jack")
+
+	tests := []struct {
+		name   string
+		key    Key[string]
+		want   string
+		wantOk bool
+	}{
+		{name: "hit", key: userKey, want: "jack", wantOk: true},
+		{name: "same name different key", key: otherKey, want: "", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Get(ctx, tt.key)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("Get() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+	if got := GetOr(ctx, otherKey, "anon"); got != "anon" {
+		t.Errorf("GetOr() = %q, want anon", got)
+	}
+}
+
+func TestDetach(t *testing.T) {
+	key := NewKey[int]("id")
+	parent, cancel := context.WithCancel(Set(context.Background(), key, 7))
+	detached := Detach(parent)
+	cancel()
+
+	if detached.Err() != nil {
+		t.Errorf("detached Err() = %v, want nil", detached.Err())
+	}
+	if v, _ := Get(detached, key); v != 7 {
+		t.Errorf("detached value = %d, want 7", v)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	keyA, keyB := NewKey[string]("a"), NewKey[string]("b")
+	a := Set(context.Background(), keyA, "a")
+	b, cancelB := context.WithCancelCause(Set(context.Background(), keyB, "b"))
+	deadline := time.Now().Add(time.Hour)
+	b, cancelDeadline := context.WithDeadline(b, deadline)
+	defer cancelDeadline()
+
+	ctx, cancel := Merge(a, b)
+	defer cancel()
+	if GetOr(ctx, keyA, "") != "a" || GetOr(ctx, keyB, "") != "b" {
+		t.Errorf("Merge() lost values")
+	}
+	if d, ok := ctx.Deadline(); !ok || !d.Equal(deadline) {
+		t.Errorf("Deadline() = %v, %v", d, ok)
+	}
+
+	errStop := errors.New("shutdown")
+	cancelB(errStop)
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("merged ctx not canceled by b")
+	}
+	if !errors.Is(context.Cause(ctx), errStop) {
+		t.Errorf("Cause() = %v, want %v", context.Cause(ctx), errStop)
+	}
+}
+
+func TestWithOpTimeout(t *testing.T) {
+	ctx, cancel := WithOpTimeout(context.Background(), "query user", time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	err := Cause(ctx)
+	var te *TimeoutError
+	if !errors.As(err, &te) || te.Op != "query user" || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Cause() = %v", err)
+	}
+	if err.Error() != "query user: timeout after 1ms" {
+		t.Errorf("Error() = %s", err.Error())
+	}
+}
@@ -0,0 +1,53 @@
+package ctxutil
+
+import "context"
+
+// Key 带类型的context key, 不同Key即使name相同也互不冲突
+//
+//	var userKey = ctxutil.NewKey[*User]("user")
+//	ctx = ctxutil.Set(ctx, userKey, u)
+//	u, ok := ctxutil.Get(ctx, userKey)
+type Key[T any] struct {
+	name *string
+}
+
+// NewKey new a Key, name仅用于调试输出
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: &name}
+}
+
+// String 返回key的名称
+func (k Key[T]) String() string {
+	if k.name == nil {
+		return "<nil>"
+	}
+	return *k.name
+}
+
+// Set 将v存入ctx
+func Set[T any](ctx context.Context, key Key[T], v T) context.Context {
+	return context.WithValue(ctx, key, v)
+}
+
+// Get 从ctx取值, 不存在时返回T的零值和false
+func Get[T any](ctx context.Context, key Key[T]) (T, bool) {
+	v, ok := ctx.Value(key).(T)
+	return v, ok
+}
+
+// MustGet 从ctx取值, 不存在时panic
+func MustGet[T any](ctx context.Context, key Key[T]) T {
+	v, ok := Get(ctx, key)
+	if !ok {
+		panic("ctxutil: missing value for key " + key.String())
+	}
+	return v
+}
+
+// GetOr 从ctx取值, 不存在时返回def
+func GetOr[T any](ctx context.Context, key Key[T], def T) T {
+	if v, ok := Get(ctx, key); ok {
+		return v
+	}
+	return def
+}
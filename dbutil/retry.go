@@ -0,0 +1,59 @@
+package dbutil
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+var (
+	classifierMu sync.RWMutex
+	classifiers  []func(error) bool
+)
+
+// RegisterRetryable 注册额外的可重试错误判断, 用于驱动特有的错误类型
+func RegisterRetryable(fn func(err error) bool) {
+	classifierMu.Lock()
+	defer classifierMu.Unlock()
+	classifiers = append(classifiers, fn)
+}
+
+// retryableStates 可以整体重试事务的SQLSTATE: 序列化失败和死锁
+var retryableStates = map[string]bool{
+	"40001": true, // serialization_failure, MySQL的死锁也使用该状态码
+	"40P01": true, // PostgreSQL deadlock_detected
+}
+
+// retryableMessages 驱动未暴露SQLSTATE时按错误信息判断
+var retryableMessages = []string{
+	"deadlock found",             // MySQL 1213
+	"lock wait timeout exceeded", // MySQL 1205
+	"deadlock detected",          // PostgreSQL
+	"could not serialize access", // PostgreSQL
+	"database is locked",         // SQLite
+}
+
+// IsRetryable 是否为可以通过重试整个事务解决的错误, 如死锁、序列化失败、锁等待超时
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) && retryableStates[state.SQLState()] {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range retryableMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	classifierMu.RLock()
+	defer classifierMu.RUnlock()
+	for _, fn := range classifiers {
+		if fn(err) {
+			return true
+		}
+	}
+	return false
+}
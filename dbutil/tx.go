@@ -0,0 +1,137 @@
+package dbutil
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ChangSZ/golib/log"
+)
+
+// TxBeginner 可以开启事务的对象, 通常是*sql.DB或*sql.Conn
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Option WithTx的可选参数
+type Option func(*txOptions)
+
+type txOptions struct {
+	txOpts      *sql.TxOptions
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// WithTxOptions 设置隔离级别和只读
+func WithTxOptions(opts *sql.TxOptions) Option {
+	return func(o *txOptions) {
+		o.txOpts = opts
+	}
+}
+
+// WithRetry 可重试错误(见IsRetryable)的总尝试次数和首次重试间隔, 间隔每次翻倍; 默认3次、10ms
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(o *txOptions) {
+		o.maxAttempts = attempts
+		o.backoff = backoff
+	}
+}
+
+type txKey struct{}
+
+type txState struct {
+	tx    *sql.Tx
+	depth int
+}
+
+// TxFromContext 当前ctx所在的事务, 用于repo层在调用方开启的事务中执行
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	s, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		return nil, false
+	}
+	return s.tx, true
+}
+
+// WithTx 在事务中执行fn, fn返回nil时提交, 返回错误或panic时回滚(panic会继续向上抛出)
+//
+// ctx已在事务中时使用SAVEPOINT实现嵌套事务, 内层失败只回滚到保存点;
+// 最外层遇到死锁等可重试错误时会重新执行fn, 因此fn应当只包含数据库操作, 不要有其他副作用
+//
+//	err := dbutil.WithTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+//		if _, err := tx.ExecContext(ctx, "UPDATE account SET balance = balance - ? WHERE id = ?", amount, from); err != nil {
+//			return err
+//		}
+//		return transfer(ctx, tx) // 内部再次调用WithTx时为嵌套事务
+//	})
+func WithTx(ctx context.Context, db TxBeginner, fn func(ctx context.Context, tx *sql.Tx) error, opts ...Option) error {
+	o := &txOptions{maxAttempts: 3, backoff: 10 * time.Millisecond}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if parent, ok := ctx.Value(txKey{}).(*txState); ok {
+		return withSavepoint(ctx, parent, fn)
+	}
+
+	backoff := o.backoff
+	for attempt := 1; ; attempt++ {
+		err := runTx(ctx, db, o.txOpts, fn)
+		if err == nil || attempt >= o.maxAttempts || !IsRetryable(err) {
+			return err
+		}
+		log.Context(ctx).Warnw("msg", "dbutil: retry transaction", "attempt", attempt, "err", err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+func runTx(ctx context.Context, db TxBeginner, opts *sql.TxOptions, fn func(ctx context.Context, tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, &txState{tx: tx}), tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			log.Context(ctx).Warnw("msg", "dbutil: rollback failed", "err", rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+func withSavepoint(ctx context.Context, parent *txState, fn func(ctx context.Context, tx *sql.Tx) error) (err error) {
+	state := &txState{tx: parent.tx, depth: parent.depth + 1}
+	name := fmt.Sprintf("sp_%d", state.depth)
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			_, _ = state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(r)
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, state), state.tx); err != nil {
+		if _, rbErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			log.Context(ctx).Warnw("msg", "dbutil: rollback to savepoint failed", "savepoint", name, "err", rbErr)
+		}
+		return err
+	}
+	_, err = state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
@@ -0,0 +1,199 @@
+package dbutil
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder 记录fake驱动执行的语句
+type recorder struct {
+	mu    sync.Mutex
+	stmts []string
+}
+
+func (r *recorder) add(s string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stmts = append(r.stmts, s)
+}
+
+func (r *recorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.stmts, ";")
+}
+
+type fakeDriver struct{ rec *recorder }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return fakeConn(d), nil }
+
+type fakeConn struct{ rec *recorder }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { c.rec.add("BEGIN"); return fakeTx(c), nil }
+
+func (c fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.rec.add(query)
+	return driver.RowsAffected(1), nil
+}
+
+type fakeTx struct{ rec *recorder }
+
+func (t fakeTx) Commit() error   { t.rec.add("COMMIT"); return nil }
+func (t fakeTx) Rollback() error { t.rec.add("ROLLBACK"); return nil }
+
+var driverSeq int
+
+func openFake(t *testing.T) (*sql.DB, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	driverSeq++
+	name := fmt.Sprintf("dbutil-fake-%d", driverSeq)
+	sql.Register(name, fakeDriver{rec: rec})
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	return db, rec
+}
+
+type pgError struct{ code string }
+
+func (e *pgError) Error() string    { return "pq: error " + e.code }
+func (e *pgError) SQLState() string { return e.code }
+
+func TestWithTx(t *testing.T) {
+	errBiz := errors.New("biz")
+	tests := []struct {
+		name    string
+		fn      func(ctx context.Context, db *sql.DB) error
+		wantErr error
+		want    string
+	}{
+		{
+			name: "commit",
+			fn: func(ctx context.Context, db *sql.DB) error {
+				return WithTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+					_, err := tx.ExecContext(ctx, "INSERT 1")
+					return err
+				})
+			},
+			want: "BEGIN;INSERT 1;COMMIT",
+		},
+		{
+			name: "rollback",
+			fn: func(ctx context.Context, db *sql.DB) error {
+				return WithTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+					return errBiz
+				})
+			},
+			wantErr: errBiz,
+			want:    "BEGIN;ROLLBACK",
+		},
+		{
+			name: "nested",
+			fn: func(ctx context.Context, db *sql.DB) error {
+				return WithTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+					_ = WithTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+						_, _ = tx.ExecContext(ctx, "INSERT 1")
+						return errBiz
+					})
+					return WithTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+						if inner, ok := TxFromContext(ctx); !ok || inner != tx {
+							return errors.New("tx not in context")
+						}
+						_, err := tx.ExecContext(ctx, "INSERT 2")
+						return err
+					})
+				})
+			},
+			want: "BEGIN;SAVEPOINT sp_1;INSERT 1;ROLLBACK TO SAVEPOINT sp_1;" +
+				"SAVEPOINT sp_1;INSERT 2;RELEASE SAVEPOINT sp_1;COMMIT",
+		},
+		{
+			name: "retry",
+			fn: func(ctx context.Context, db *sql.DB) error {
+				attempts := 0
+				return WithTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+					if attempts++; attempts < 3 {
+						return &pgError{code: "40P01"}
+					}
+					return nil
+				}, WithRetry(3, time.Millisecond))
+			},
+			want: "BEGIN;ROLLBACK;BEGIN;ROLLBACK;BEGIN;COMMIT",
+		},
+		{
+			name: "retry exhausted",
+			fn: func(ctx context.Context, db *sql.DB) error {
+				return WithTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+					return errors.New("Error 1213: Deadlock found when trying to get lock")
+				}, WithRetry(2, time.Millisecond))
+			},
+			wantErr: errors.New("Error 1213: Deadlock found when trying to get lock"),
+			want:    "BEGIN;ROLLBACK;BEGIN;ROLLBACK",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := openFake(t)
+			err := tt.fn(context.Background(), db)
+			if (err == nil) != (tt.wantErr == nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Errorf("WithTx() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := rec.String(); got != tt.want {
+				t.Errorf("statements = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithTxPanic(t *testing.T) {
+	db, rec := openFake(t)
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("recover() = %v, want boom", r)
+		}
+		if got := rec.String(); got != "BEGIN;ROLLBACK" {
+			t.Errorf("statements = %s", got)
+		}
+	}()
+	_ = WithTx(context.Background(), db, func(ctx context.Context, tx *sql.Tx) error {
+		panic("boom")
+	})
+}
+
+func TestIsRetryable(t *testing.T) {
+	errCustom := errors.New("custom conflict")
+	RegisterRetryable(func(err error) bool { return errors.Is(err, errCustom) })
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "serialization", err: &pgError{code: "40001"}, want: true},
+		{name: "unique violation", err: &pgError{code: "23505"}, want: false},
+		{name: "mysql lock wait", err: errors.New("Error 1205: Lock wait timeout exceeded"), want: true},
+		{name: "registered", err: errCustom, want: true},
+		{name: "other", err: sql.ErrNoRows, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
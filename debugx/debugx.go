@@ -0,0 +1,169 @@
+package debugx
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ChangSZ/golib/dump"
+)
+
+// Option Handler的可选参数
+type Option func(*handler)
+
+// WithConfig 在/debug/config输出配置, 按mask标签脱敏, 格式同dump.Sdump
+//
+// cfg一般传入配置结构体的指针, 每次请求时读取, 可以看到热更新后的值
+func WithConfig(cfg interface{}) Option {
+	return func(h *handler) {
+		h.config = cfg
+	}
+}
+
+// WithMaxSeconds CPU profile和trace最长的采集时间, 默认60秒
+func WithMaxSeconds(n int) Option {
+	return func(h *handler) {
+		h.maxSeconds = n
+	}
+}
+
+type handler struct {
+	token      string
+	config     interface{}
+	maxSeconds int
+	mux        *http.ServeMux
+}
+
+// Handler 调试接口, 需要挂载在根路径上, 建议只监听内网端口:
+//
+//	/debug/            接口列表
+//	/debug/pprof/      pprof, 如/debug/pprof/profile?seconds=30、/debug/pprof/heap
+//	/debug/trace       采集执行trace, 如?seconds=5, 使用go tool trace查看
+//	/debug/stats       运行时统计: goroutine、内存、GC、容器资源限制
+//	/debug/build       构建信息: Go版本、VCS版本、依赖
+//	/debug/leak        间隔seconds(默认10)采集两次快照, 输出goroutine和堆内存按位置的增长, 见Diff
+//	/debug/config      脱敏后的配置, 需WithConfig
+//
+// 请求需携带token, 通过"Authorization: Bearer <token>"或查询参数token传递;
+// token为空时只允许本机(loopback)访问
+//
+//	go http.ListenAndServe("127.0.0.1:6060", debugx.Handler(os.Getenv("DEBUG_TOKEN"), debugx.WithConfig(&conf)))
+func Handler(token string, opts ...Option) http.Handler {
+	h := &handler{token: token, maxSeconds: 60, mux: http.NewServeMux()}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.mux.HandleFunc("/debug/", h.index)
+	h.mux.HandleFunc("/debug/pprof/", pprof.Index)
+	h.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	h.mux.HandleFunc("/debug/pprof/profile", h.limitSeconds(pprof.Profile))
+	h.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	h.mux.HandleFunc("/debug/pprof/trace", h.limitSeconds(pprof.Trace))
+	h.mux.HandleFunc("/debug/trace", h.limitSeconds(pprof.Trace))
+	h.mux.HandleFunc("/debug/stats", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, ReadStats())
+	})
+	h.mux.HandleFunc("/debug/build", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, ReadBuildInfo())
+	})
+	h.mux.HandleFunc("/debug/leak", h.limitSeconds(h.leak))
+	h.mux.HandleFunc("/debug/config", h.dumpConfig)
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *handler) authorized(r *http.Request) bool {
+	if h.token == "" {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && ip.IsLoopback()
+	}
+	got := r.URL.Query().Get("token")
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		got = strings.TrimPrefix(auth, "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) == 1
+}
+
+// limitSeconds 限制采集时间, 避免误操作长时间占用CPU
+func (h *handler) limitSeconds(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s := r.URL.Query().Get("seconds"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n <= 0 || n > h.maxSeconds {
+				http.Error(w, fmt.Sprintf("seconds must be in [1, %d]", h.maxSeconds), http.StatusBadRequest)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+func (h *handler) leak(w http.ResponseWriter, r *http.Request) {
+	seconds := 10
+	if s := r.URL.Query().Get("seconds"); s != "" {
+		seconds, _ = strconv.Atoi(s)
+	}
+	if seconds > h.maxSeconds {
+		seconds = h.maxSeconds
+	}
+	before := TakeSnapshot()
+	select {
+	case <-time.After(time.Duration(seconds) * time.Second):
+	case <-r.Context().Done():
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = Diff(before, TakeSnapshot()).WriteTo(w)
+}
+
+func (h *handler) dumpConfig(w http.ResponseWriter, r *http.Request) {
+	if h.config == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = dump.Fdump(w, h.config, dump.WithRedact(true))
+}
+
+func (h *handler) index(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/debug/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, line := range []string{
+		"/debug/pprof/    pprof profiles",
+		"/debug/trace     execution trace, ?seconds=N",
+		"/debug/stats     runtime stats",
+		"/debug/build     build info",
+		"/debug/leak      goroutine and heap growth by site, ?seconds=N",
+		"/debug/config    config with secrets masked",
+	} {
+		fmt.Fprintln(w, line)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
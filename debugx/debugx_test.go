@@ -0,0 +1,83 @@
+package debugx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type dbConfig struct {
+	Host     string
+	Password string `mask:"secret"`
+}
+
+type appConfig struct {
+	Name string
+	DB   dbConfig
+}
+
+func TestHandler(t *testing.T) {
+	h := Handler("s3cret", WithConfig(&appConfig{Name: "demo", DB: dbConfig{Host: "db", Password: "p@ss"}}), WithMaxSeconds(5))
+
+	tests := []struct {
+		name       string
+		path       string
+		auth       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "no token", path: "/debug/stats", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", path: "/debug/stats", auth: "Bearer x", wantStatus: http.StatusUnauthorized},
+		{name: "stats", path: "/debug/stats", auth: "Bearer s3cret", wantStatus: http.StatusOK, wantBody: `"goroutines"`},
+		{name: "query token", path: "/debug/build?token=s3cret", wantStatus: http.StatusOK, wantBody: `"goVersion"`},
+		{name: "pprof", path: "/debug/pprof/goroutine?debug=1", auth: "Bearer s3cret", wantStatus: http.StatusOK, wantBody: "goroutine profile"},
+		{name: "seconds limit", path: "/debug/pprof/profile?seconds=30", auth: "Bearer s3cret", wantStatus: http.StatusBadRequest},
+		{name: "leak seconds limit", path: "/debug/leak?seconds=30", auth: "Bearer s3cret", wantStatus: http.StatusBadRequest},
+		{name: "config masked", path: "/debug/config", auth: "Bearer s3cret", wantStatus: http.StatusOK, wantBody: `Host: "db"`},
+		{name: "index", path: "/debug/", auth: "Bearer s3cret", wantStatus: http.StatusOK, wantBody: "/debug/pprof/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want contains %s", rec.Body, tt.wantBody)
+			}
+			if strings.Contains(rec.Body.String(), "p@ss") {
+				t.Errorf("secret leaked: %s", rec.Body)
+			}
+		})
+	}
+}
+
+func TestLoopbackWithoutToken(t *testing.T) {
+	h := Handler("")
+	for remote, want := range map[string]int{
+		"127.0.0.1:1234": http.StatusOK,
+		"[::1]:1234":     http.StatusOK,
+		"10.0.0.8:1234":  http.StatusUnauthorized,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/debug/stats", nil)
+		req.RemoteAddr = remote
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("%s status = %d, want %d", remote, rec.Code, want)
+		}
+		if want == http.StatusOK {
+			var s Stats
+			if err := json.Unmarshal(rec.Body.Bytes(), &s); err != nil || s.Goroutines == 0 {
+				t.Errorf("stats = %s, err = %v", rec.Body, err)
+			}
+		}
+	}
+}
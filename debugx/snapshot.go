@@ -0,0 +1,254 @@
+package debugx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshot 某一时刻的goroutine和堆内存概况, 用TakeSnapshot采集, 两次采集的结果用Diff比较
+type Snapshot struct {
+	Time       time.Time            `json:"time"`
+	Goroutines int                  `json:"goroutines"`
+	GoSites    map[string]int       `json:"goSites"`   // goroutine创建位置 => 数量
+	HeapSites  map[string]HeapUsage `json:"heapSites"` // 分配位置 => 仍在使用的内存
+	HeapInuse  HeapUsage            `json:"heapInuse"` // 所有采样的合计
+}
+
+// HeapUsage 仍在使用(已分配未释放)的堆内存, 来自runtime.MemProfile的采样, 未按采样率放大, 只适合比较相对大小
+type HeapUsage struct {
+	Bytes   int64 `json:"bytes"`
+	Objects int64 `json:"objects"`
+}
+
+// TakeSnapshot 采集goroutine栈和堆内存采样, 按goroutine创建位置和内存分配位置汇总
+//
+// 会先执行一次runtime.GC, 使堆采样反映当前仍在使用的内存; 采集goroutine栈会stop the world,
+// goroutine数量多时耗时较长, 不要高频调用
+func TakeSnapshot() *Snapshot {
+	runtime.GC()
+	s := &Snapshot{
+		Time:      time.Now(),
+		GoSites:   make(map[string]int),
+		HeapSites: make(map[string]HeapUsage),
+	}
+	for _, site := range goroutineSites(allStacks()) {
+		s.GoSites[site]++
+		s.Goroutines++
+	}
+
+	records := memProfile()
+	for _, r := range records {
+		site := allocSite(r.Stack())
+		u := s.HeapSites[site]
+		u.Bytes += r.InUseBytes()
+		u.Objects += r.InUseObjects()
+		s.HeapSites[site] = u
+		s.HeapInuse.Bytes += r.InUseBytes()
+		s.HeapInuse.Objects += r.InUseObjects()
+	}
+	for site, u := range s.HeapSites {
+		if u.Objects == 0 {
+			delete(s.HeapSites, site)
+		}
+	}
+	return s
+}
+
+// SnapshotDiff 两次快照的差异, 按增长量从大到小排序
+type SnapshotDiff struct {
+	Duration   time.Duration `json:"duration"`
+	Goroutines int           `json:"goroutines"` // goroutine数量的变化
+	GoSites    []GoSiteDiff  `json:"goSites"`
+	HeapInuse  HeapUsage     `json:"heapInuse"` // 堆采样合计的变化
+	HeapSites  []HeapDiff    `json:"heapSites"`
+}
+
+// GoSiteDiff 一个创建位置的goroutine数量变化
+type GoSiteDiff struct {
+	Site   string `json:"site"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+	Delta  int    `json:"delta"`
+}
+
+// HeapDiff 一个分配位置仍在使用的内存变化
+type HeapDiff struct {
+	Site   string    `json:"site"`
+	Before HeapUsage `json:"before"`
+	After  HeapUsage `json:"after"`
+	Delta  HeapUsage `json:"delta"`
+}
+
+// Diff 比较两次快照, 只包含有变化的位置
+//
+// 对长期运行的服务间隔一段时间采集两次, 持续增长的位置通常就是泄漏点:
+//
+//	before := debugx.TakeSnapshot()
+//	time.Sleep(10 * time.Minute)
+//	debugx.Diff(before, debugx.TakeSnapshot()).WriteTo(os.Stderr)
+func Diff(before, after *Snapshot) *SnapshotDiff {
+	d := &SnapshotDiff{
+		Duration:   after.Time.Sub(before.Time),
+		Goroutines: after.Goroutines - before.Goroutines,
+		HeapInuse: HeapUsage{
+			Bytes:   after.HeapInuse.Bytes - before.HeapInuse.Bytes,
+			Objects: after.HeapInuse.Objects - before.HeapInuse.Objects,
+		},
+	}
+	for _, site := range unionKeys(before.GoSites, after.GoSites) {
+		b, a := before.GoSites[site], after.GoSites[site]
+		if a != b {
+			d.GoSites = append(d.GoSites, GoSiteDiff{Site: site, Before: b, After: a, Delta: a - b})
+		}
+	}
+	sort.SliceStable(d.GoSites, func(i, j int) bool { return d.GoSites[i].Delta > d.GoSites[j].Delta })
+
+	for _, site := range unionKeys(before.HeapSites, after.HeapSites) {
+		b, a := before.HeapSites[site], after.HeapSites[site]
+		if a != b {
+			delta := HeapUsage{Bytes: a.Bytes - b.Bytes, Objects: a.Objects - b.Objects}
+			d.HeapSites = append(d.HeapSites, HeapDiff{Site: site, Before: b, After: a, Delta: delta})
+		}
+	}
+	sort.SliceStable(d.HeapSites, func(i, j int) bool { return d.HeapSites[i].Delta.Bytes > d.HeapSites[j].Delta.Bytes })
+	return d
+}
+
+// WriteTo 以文本输出差异, 每类最多输出增长最多的20个位置
+func (d *SnapshotDiff) WriteTo(w io.Writer) (int64, error) {
+	const top = 20
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "duration %s\n\ngoroutines %+d\n", d.Duration.Truncate(time.Millisecond), d.Goroutines)
+	for i, s := range d.GoSites {
+		if i == top {
+			fmt.Fprintf(&b, "  ... %d more\n", len(d.GoSites)-top)
+			break
+		}
+		fmt.Fprintf(&b, "  %+6d (%d -> %d) %s\n", s.Delta, s.Before, s.After, s.Site)
+	}
+	fmt.Fprintf(&b, "\nheap inuse %+d bytes, %+d objects (sampled)\n", d.HeapInuse.Bytes, d.HeapInuse.Objects)
+	for i, s := range d.HeapSites {
+		if i == top {
+			fmt.Fprintf(&b, "  ... %d more\n", len(d.HeapSites)-top)
+			break
+		}
+		fmt.Fprintf(&b, "  %+10d bytes %+6d objects %s\n", s.Delta.Bytes, s.Delta.Objects, s.Site)
+	}
+	n, err := w.Write(b.Bytes())
+	return int64(n), err
+}
+
+// String 同WriteTo
+func (d *SnapshotDiff) String() string {
+	var b strings.Builder
+	_, _ = d.WriteTo(&b)
+	return b.String()
+}
+
+// allStacks 返回所有goroutine的栈, 缓冲区不够时翻倍重试
+func allStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// goroutineSites 解析runtime.Stack的输出, 返回每个goroutine的创建位置, 如
+// "github.com/x/consumer.(*Consumer).Start at /src/consumer.go:42";
+// 没有创建者的goroutine(main)使用栈底的函数
+func goroutineSites(stacks []byte) []string {
+	var sites []string
+	for _, g := range strings.Split(strings.TrimSpace(string(stacks)), "\n\n") {
+		lines := strings.Split(g, "\n")
+		site := ""
+		for i, line := range lines {
+			if !strings.HasPrefix(line, "created by ") {
+				continue
+			}
+			site = strings.TrimPrefix(line, "created by ")
+			if j := strings.Index(site, " in goroutine "); j >= 0 {
+				site = site[:j]
+			}
+			if i+1 < len(lines) {
+				site += " at " + frameLocation(lines[i+1])
+			}
+			break
+		}
+		if site == "" && len(lines) >= 3 {
+			// 栈的最后两行为最外层的函数和位置
+			fn := lines[len(lines)-2]
+			if j := strings.LastIndexByte(fn, '('); j > 0 {
+				fn = fn[:j]
+			}
+			site = fn + " at " + frameLocation(lines[len(lines)-1])
+		}
+		if site != "" {
+			sites = append(sites, site)
+		}
+	}
+	return sites
+}
+
+// frameLocation "\t/src/a.go:42 +0x1d" -> "/src/a.go:42"
+func frameLocation(line string) string {
+	line = strings.TrimSpace(line)
+	if i := strings.LastIndex(line, " +0x"); i >= 0 {
+		line = line[:i]
+	}
+	return line
+}
+
+// memProfile 返回包含已释放记录的所有堆采样
+func memProfile() []runtime.MemProfileRecord {
+	n, _ := runtime.MemProfile(nil, true)
+	for {
+		records := make([]runtime.MemProfileRecord, n+50)
+		var ok bool
+		n, ok = runtime.MemProfile(records, true)
+		if ok {
+			return records[:n]
+		}
+	}
+}
+
+// allocSite 分配位置, 跳过runtime内部的栈帧, 如make、append所在的调用方
+func allocSite(stack []uintptr) string {
+	frames := runtime.CallersFrames(stack)
+	first := ""
+	for {
+		f, more := frames.Next()
+		site := fmt.Sprintf("%s at %s:%d", f.Function, f.File, f.Line)
+		if first == "" {
+			first = site
+		}
+		if !strings.HasPrefix(f.Function, "runtime.") {
+			return site
+		}
+		if !more {
+			return first
+		}
+	}
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
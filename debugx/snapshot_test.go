@@ -0,0 +1,70 @@
+package debugx
+
+import (
+	"strings"
+	"testing"
+)
+
+var retained [][]byte
+
+func leakGoroutines(n int, stop chan struct{}) {
+	for i := 0; i < n; i++ {
+		go func() { <-stop }()
+	}
+}
+
+func retain(n int) {
+	for i := 0; i < n; i++ {
+		retained = append(retained, make([]byte, 64<<10))
+	}
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	before := TakeSnapshot()
+	leakGoroutines(5, stop)
+	retain(64)
+	d := Diff(before, TakeSnapshot())
+	retained = nil
+
+	if d.Goroutines < 5 {
+		t.Errorf("Goroutines = %d, want >= 5", d.Goroutines)
+	}
+	if len(d.GoSites) == 0 || !strings.Contains(d.GoSites[0].Site, "debugx.leakGoroutines") || d.GoSites[0].Delta != 5 {
+		t.Errorf("GoSites = %+v", d.GoSites)
+	}
+	found := false
+	for _, s := range d.HeapSites {
+		if strings.Contains(s.Site, "debugx.retain") && s.Delta.Bytes > 0 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("HeapSites = %+v", d.HeapSites)
+	}
+	if out := d.String(); !strings.Contains(out, "debugx.leakGoroutines") {
+		t.Errorf("String() = %s", out)
+	}
+}
+
+func TestGoroutineSites(t *testing.T) {
+	stacks := `goroutine 1 [running]:
+main.work()
+	/src/main.go:10 +0x1d
+main.main()
+	/src/main.go:5 +0x25
+
+goroutine 7 [chan receive]:
+app/consumer.(*C).loop(0xc000010000)
+	/src/consumer.go:30 +0x45
+created by app/consumer.(*C).Start in goroutine 1
+	/src/consumer.go:20 +0x65
+`
+	got := goroutineSites([]byte(stacks))
+	want := []string{"main.main at /src/main.go:5", "app/consumer.(*C).Start at /src/consumer.go:20"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("goroutineSites() = %q, want %q", got, want)
+	}
+}
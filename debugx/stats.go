@@ -0,0 +1,132 @@
+package debugx
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/ChangSZ/golib/sysutil"
+)
+
+var startTime = time.Now()
+
+// Stats 运行时统计
+type Stats struct {
+	Time        time.Time `json:"time"`
+	Uptime      string    `json:"uptime"`
+	Goroutines  int       `json:"goroutines"`
+	NumCPU      int       `json:"numCPU"`
+	GOMAXPROCS  int       `json:"gomaxprocs"`
+	CPUQuota    float64   `json:"cpuQuota,omitempty"`    // 容器的CPU核数限制
+	MemoryLimit uint64    `json:"memoryLimit,omitempty"` // 容器的内存限制(字节)
+	Mem         MemStats  `json:"mem"`
+	GC          GCStats   `json:"gc"`
+}
+
+// MemStats 内存统计, 单位字节
+type MemStats struct {
+	Alloc       uint64 `json:"alloc"`
+	TotalAlloc  uint64 `json:"totalAlloc"`
+	Sys         uint64 `json:"sys"`
+	HeapAlloc   uint64 `json:"heapAlloc"`
+	HeapInuse   uint64 `json:"heapInuse"`
+	HeapIdle    uint64 `json:"heapIdle"`
+	HeapObjects uint64 `json:"heapObjects"`
+	StackInuse  uint64 `json:"stackInuse"`
+}
+
+// GCStats GC统计
+type GCStats struct {
+	NumGC         uint32        `json:"numGC"`
+	NextGC        uint64        `json:"nextGC"`
+	LastGC        time.Time     `json:"lastGC"`
+	LastPause     time.Duration `json:"lastPause"`
+	PauseTotal    time.Duration `json:"pauseTotal"`
+	GCCPUFraction float64       `json:"gcCPUFraction"`
+}
+
+// ReadStats 读取运行时统计, 会短暂地stop the world, 不要高频调用
+func ReadStats() Stats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	s := Stats{
+		Time:       time.Now(),
+		Uptime:     time.Since(startTime).Truncate(time.Second).String(),
+		Goroutines: runtime.NumGoroutine(),
+		NumCPU:     runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		Mem: MemStats{
+			Alloc:       m.Alloc,
+			TotalAlloc:  m.TotalAlloc,
+			Sys:         m.Sys,
+			HeapAlloc:   m.HeapAlloc,
+			HeapInuse:   m.HeapInuse,
+			HeapIdle:    m.HeapIdle,
+			HeapObjects: m.HeapObjects,
+			StackInuse:  m.StackInuse,
+		},
+		GC: GCStats{
+			NumGC:         m.NumGC,
+			NextGC:        m.NextGC,
+			PauseTotal:    time.Duration(m.PauseTotalNs),
+			GCCPUFraction: m.GCCPUFraction,
+		},
+	}
+	if m.NumGC > 0 {
+		s.GC.LastGC = time.Unix(0, int64(m.LastGC))
+		s.GC.LastPause = time.Duration(m.PauseNs[(m.NumGC+255)%256])
+	}
+	if q, ok := sysutil.CPUQuota(); ok {
+		s.CPUQuota = q
+	}
+	if limit, ok := sysutil.MemoryLimit(); ok {
+		s.MemoryLimit = limit
+	}
+	return s
+}
+
+// BuildInfo 构建信息, 来自runtime/debug.ReadBuildInfo
+type BuildInfo struct {
+	GoVersion string            `json:"goVersion"`
+	Path      string            `json:"path"`
+	Version   string            `json:"version"`
+	Revision  string            `json:"revision,omitempty"`
+	BuildTime string            `json:"buildTime,omitempty"`
+	Modified  bool              `json:"modified,omitempty"`
+	Settings  map[string]string `json:"settings,omitempty"`
+	Deps      map[string]string `json:"deps,omitempty"` // 依赖模块路径 => 版本
+}
+
+// ReadBuildInfo 读取构建信息, 未使用module构建时只有GoVersion
+func ReadBuildInfo() BuildInfo {
+	info := BuildInfo{GoVersion: runtime.Version()}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Path = bi.Path
+	info.Version = bi.Main.Version
+	info.Settings = make(map[string]string, len(bi.Settings))
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.BuildTime = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		default:
+			info.Settings[s.Key] = s.Value
+		}
+	}
+	info.Deps = make(map[string]string, len(bi.Deps))
+	for _, d := range bi.Deps {
+		version := d.Version
+		if d.Replace != nil {
+			version = d.Replace.Path + " " + d.Replace.Version
+		}
+		info.Deps[d.Path] = version
+	}
+	return info
+}
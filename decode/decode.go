@@ -0,0 +1,300 @@
+package decode
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ChangSZ/golib/conv"
+	"github.com/ChangSZ/golib/reflectutil"
+	"github.com/ChangSZ/golib/tagparse"
+)
+
+// DefaultTagName 默认读取的struct tag
+const DefaultTagName = "json"
+
+// HookFunc 解码钩子, 在字段解码前调用, 可以对data做预处理后返回
+//
+// from为data的原始类型, to为目标字段类型, 不处理时原样返回data即可
+type HookFunc func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error)
+
+// Option is Decoder option.
+type Option func(*Decoder)
+
+// WithTagName 指定读取的struct tag, 默认json
+func WithTagName(name string) Option {
+	return func(d *Decoder) {
+		d.tagName = name
+	}
+}
+
+// WithWeaklyTyped 开启弱类型转换, 如 "1" => 1, 1 => true, 1.5 => "1.5"
+func WithWeaklyTyped() Option {
+	return func(d *Decoder) {
+		d.weak = true
+	}
+}
+
+// WithHooks 添加解码钩子, 按添加顺序依次执行
+func WithHooks(hooks ...HookFunc) Option {
+	return func(d *Decoder) {
+		d.hooks = append(d.hooks, hooks...)
+	}
+}
+
+// WithErrorUnused map中存在结构体没有的key时报错
+func WithErrorUnused() Option {
+	return func(d *Decoder) {
+		d.errorUnused = true
+	}
+}
+
+// Decoder 将map[string]interface{}等弱类型数据解码到结构体
+type Decoder struct {
+	tagName     string
+	weak        bool
+	errorUnused bool
+	hooks       []HookFunc
+}
+
+// NewDecoder new a Decoder.
+func NewDecoder(opts ...Option) *Decoder {
+	d := &Decoder{
+		tagName: DefaultTagName,
+	}
+	for _, o := range opts {
+		o(d)
+	}
+	return d
+}
+
+// Decode 将input解码到output中, output必须是指针
+//
+// 支持:
+//   - tag重命名: `json:"user_name"`, 未命中时按字段名忽略大小写匹配
+//   - `json:"-"` 跳过字段
+//   - 内嵌结构体默认展开(squash), 也可以通过 `json:",squash"` 展开具名结构体字段
+//   - 类型转换使用conv包, 与copy包共用conv.Register注册的转换函数
+func Decode(input, output interface{}, opts ...Option) error {
+	return NewDecoder(opts...).Decode(input, output)
+}
+
+// Decode 将input解码到output中, output必须是指针
+func (d *Decoder) Decode(input, output interface{}) error {
+	out := reflect.ValueOf(output)
+	if out.Kind() != reflect.Ptr || out.IsNil() {
+		return fmt.Errorf("decode: output must be a non-nil pointer, got %T", output)
+	}
+	return d.decode("", input, out.Elem())
+}
+
+func (d *Decoder) decode(name string, data interface{}, out reflect.Value) error {
+	var err error
+	for _, hook := range d.hooks {
+		if data == nil {
+			break
+		}
+		if data, err = hook(reflect.TypeOf(data), out.Type(), data); err != nil {
+			return fieldError(name, err)
+		}
+	}
+	if data == nil {
+		return nil
+	}
+
+	in := reflect.ValueOf(data)
+	// 已注册转换函数或可直接赋值时不再拆解
+	if _, ok := conv.Lookup(in.Type(), out.Type()); ok || in.Type().AssignableTo(out.Type()) {
+		v, err := conv.Convert(in, out.Type(), d.weak)
+		if err != nil {
+			return fieldError(name, err)
+		}
+		out.Set(v)
+		return nil
+	}
+
+	switch out.Kind() {
+	case reflect.Ptr:
+		if out.IsNil() {
+			out.Set(reflect.New(out.Type().Elem()))
+		}
+		return d.decode(name, data, out.Elem())
+	case reflect.Interface:
+		if !in.Type().AssignableTo(out.Type()) {
+			return fieldError(name, fmt.Errorf("%v does not implement %v", in.Type(), out.Type()))
+		}
+		out.Set(in)
+		return nil
+	case reflect.Struct:
+		return d.decodeStruct(name, in, out)
+	case reflect.Map:
+		return d.decodeMap(name, in, out)
+	case reflect.Slice, reflect.Array:
+		return d.decodeSlice(name, in, out)
+	}
+
+	v, err := conv.Convert(in, out.Type(), d.weak)
+	if err != nil {
+		return fieldError(name, err)
+	}
+	out.Set(v)
+	return nil
+}
+
+func (d *Decoder) decodeStruct(name string, in, out reflect.Value) error {
+	in = reflect.Indirect(in)
+	if in.Kind() != reflect.Map || in.Type().Key().Kind() != reflect.String {
+		return fieldError(name, fmt.Errorf("expected a map with string keys, got %v", in.Type()))
+	}
+
+	used := make(map[string]bool, in.Len())
+	if err := d.decodeFields(name, in, out, used); err != nil {
+		return err
+	}
+
+	if d.errorUnused {
+		var unused []string
+		for _, k := range in.MapKeys() {
+			if !used[k.String()] {
+				unused = append(unused, join(name, k.String()))
+			}
+		}
+		if len(unused) > 0 {
+			return fmt.Errorf("decode: unused keys: %s", strings.Join(unused, ", "))
+		}
+	}
+	return nil
+}
+
+// decodeFields 按字段依次解码, 内嵌结构体与squash字段直接使用同一个map
+func (d *Decoder) decodeFields(name string, in, out reflect.Value, used map[string]bool) error {
+	t := out.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		key, squash, skip := d.parseTag(field)
+		if skip {
+			continue
+		}
+		fieldValue := out.Field(i)
+
+		if squash {
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Ptr && embedded.CanSet() {
+				embedded = reflectutil.Indirect(embedded, true)
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := d.decodeFields(name, in, embedded, used); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+
+		mapKey, ok := findKey(in, key)
+		if !ok {
+			continue
+		}
+		used[mapKey.String()] = true
+		if err := d.decode(join(name, key), in.MapIndex(mapKey).Interface(), fieldValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Decoder) decodeMap(name string, in, out reflect.Value) error {
+	in = reflect.Indirect(in)
+	if in.Kind() != reflect.Map {
+		return fieldError(name, fmt.Errorf("expected a map, got %v", in.Type()))
+	}
+
+	t := out.Type()
+	if out.IsNil() {
+		out.Set(reflect.MakeMapWithSize(t, in.Len()))
+	}
+	for _, k := range in.MapKeys() {
+		key := reflect.New(t.Key()).Elem()
+		if err := d.decode(name, k.Interface(), key); err != nil {
+			return err
+		}
+		val := reflect.New(t.Elem()).Elem()
+		if err := d.decode(join(name, fmt.Sprint(k.Interface())), in.MapIndex(k).Interface(), val); err != nil {
+			return err
+		}
+		out.SetMapIndex(key, val)
+	}
+	return nil
+}
+
+func (d *Decoder) decodeSlice(name string, in, out reflect.Value) error {
+	in = reflect.Indirect(in)
+	if in.Kind() != reflect.Slice && in.Kind() != reflect.Array {
+		if !d.weak {
+			return fieldError(name, fmt.Errorf("expected a slice, got %v", in.Type()))
+		}
+		// 弱类型模式下单个值视为只有一个元素的切片
+		single := reflect.MakeSlice(reflect.SliceOf(in.Type()), 1, 1)
+		single.Index(0).Set(in)
+		in = single
+	}
+
+	n := in.Len()
+	if out.Kind() == reflect.Array {
+		if n > out.Len() {
+			return fieldError(name, fmt.Errorf("expected at most %d elements, got %d", out.Len(), n))
+		}
+	} else {
+		out.Set(reflect.MakeSlice(out.Type(), n, n))
+	}
+	for i := 0; i < n; i++ {
+		if err := d.decode(fmt.Sprintf("%s[%d]", name, i), in.Index(i).Interface(), out.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTag 返回字段对应的key, 是否展开, 是否跳过
+func (d *Decoder) parseTag(field reflect.StructField) (string, bool, bool) {
+	tag := tagparse.Get(field, d.tagName)
+	if tag.Skip {
+		return "", false, true
+	}
+	squash := (field.Anonymous && tag.Name == "") || tag.Has("squash") || tag.Has("inline")
+	return tag.NameOr(field.Name), squash, false
+}
+
+// findKey 先精确匹配, 再忽略大小写匹配
+func findKey(m reflect.Value, key string) (reflect.Value, bool) {
+	k := reflect.ValueOf(key).Convert(m.Type().Key())
+	if m.MapIndex(k).IsValid() {
+		return k, true
+	}
+	for _, mk := range m.MapKeys() {
+		if strings.EqualFold(mk.String(), key) {
+			return mk, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func fieldError(name string, err error) error {
+	if name == "" {
+		return fmt.Errorf("decode: %w", err)
+	}
+	return fmt.Errorf("decode: field '%s': %w", name, err)
+}
@@ -0,0 +1,146 @@
+package decode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChangSZ/golib/conv"
+)
+
+type Base struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Address struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type User struct {
+	Base
+	Name     string            `json:"user_name"`
+	Age      int               `json:"age"`
+	Admin    bool              `json:"admin"`
+	Score    *float64          `json:"score"`
+	Tags     []string          `json:"tags"`
+	Address  Address           `json:"address"`
+	Contact  Address           `json:",squash"`
+	Labels   map[string]int    `json:"labels"`
+	Timeout  time.Duration     `json:"timeout"`
+	Ignored  string            `json:"-"`
+	Extra    map[string]string `json:"extra"`
+	Nickname Nick              `json:"nickname"`
+}
+
+type Nick struct {
+	Value string
+}
+
+func TestDecode(t *testing.T) {
+	score := 99.5
+	tests := []struct {
+		name    string
+		input   map[string]interface{}
+		opts    []Option
+		want    User
+		wantErr string
+	}{
+		{
+			name: "strict",
+			input: map[string]interface{}{
+				"id":        float64(7),
+				"user_name": "jack",
+				"AGE":       float64(18),
+				"admin":     true,
+				"score":     99.5,
+				"tags":      []interface{}{"a", "b"},
+				"address":   map[string]interface{}{"city": "shanghai"},
+				"zip":       "200000",
+				"labels":    map[string]interface{}{"x": float64(1)},
+				"Ignored":   "ignored",
+			},
+			want: User{
+				Base:    Base{ID: 7},
+				Name:    "jack",
+				Age:     18,
+				Admin:   true,
+				Score:   &score,
+				Tags:    []string{"a", "b"},
+				Address: Address{City: "shanghai"},
+				Contact: Address{Zip: "200000"},
+				Labels:  map[string]int{"x": 1},
+			},
+		},
+		{
+			name: "weak_and_hooks",
+			input: map[string]interface{}{
+				"id":         "7",
+				"created_at": "2024-01-02 03:04:05",
+				"age":        "18",
+				"admin":      "1",
+				"score":      "99.5",
+				"tags":       "a,b",
+				"timeout":    "1m30s",
+				"extra":      map[string]interface{}{"k": 1},
+			},
+			opts: []Option{
+				WithWeaklyTyped(),
+				WithHooks(
+					StringToTimeHook("2006-01-02 15:04:05"),
+					StringToDurationHook(),
+					StringToSliceHook(","),
+				),
+			},
+			want: User{
+				Base:    Base{ID: 7, CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)},
+				Age:     18,
+				Admin:   true,
+				Score:   &score,
+				Tags:    []string{"a", "b"},
+				Timeout: 90 * time.Second,
+				Extra:   map[string]string{"k": "1"},
+			},
+		},
+		{
+			name:    "strict_type_mismatch",
+			input:   map[string]interface{}{"age": "18"},
+			wantErr: "field 'age'",
+		},
+		{
+			name:    "error_unused",
+			input:   map[string]interface{}{"unknown": 1},
+			opts:    []Option{WithErrorUnused()},
+			wantErr: "unused keys: unknown",
+		},
+		{
+			name:  "registered_converter",
+			input: map[string]interface{}{"nickname": "jj"},
+			want:  User{Nickname: Nick{Value: "jj"}},
+		},
+	}
+
+	conv.Register(func(s string) Nick { return Nick{Value: s} })
+	defer conv.Unregister(reflect.TypeOf(""), reflect.TypeOf(Nick{}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got User
+			err := Decode(tt.input, &got, tt.opts...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
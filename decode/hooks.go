@@ -0,0 +1,47 @@
+package decode
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// StringToTimeHook 按layout将字符串解析为time.Time, 使用time.Local时区
+func StringToTimeHook(layout string) HookFunc {
+	timeType := reflect.TypeOf(time.Time{})
+	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		if from.Kind() != reflect.String || to != timeType {
+			return data, nil
+		}
+		s := reflect.ValueOf(data).String()
+		if s == "" {
+			return time.Time{}, nil
+		}
+		return time.ParseInLocation(layout, s, time.Local)
+	}
+}
+
+// StringToDurationHook 将 "1h30m" 这类字符串解析为time.Duration
+func StringToDurationHook() HookFunc {
+	durationType := reflect.TypeOf(time.Duration(0))
+	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		if from.Kind() != reflect.String || to != durationType {
+			return data, nil
+		}
+		return time.ParseDuration(reflect.ValueOf(data).String())
+	}
+}
+
+// StringToSliceHook 按分隔符将字符串拆分为切片, 如 "a,b,c" => []string{"a", "b", "c"}
+func StringToSliceHook(sep string) HookFunc {
+	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		if from.Kind() != reflect.String || to.Kind() != reflect.Slice || to.Elem().Kind() == reflect.Uint8 {
+			return data, nil
+		}
+		s := reflect.ValueOf(data).String()
+		if s == "" {
+			return []string{}, nil
+		}
+		return strings.Split(s, sep), nil
+	}
+}
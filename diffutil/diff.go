@@ -0,0 +1,199 @@
+package diffutil
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Op 编辑操作类型
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// Edit 一段编辑, 行级diff中Text为一行(不含换行符), 词级diff中为一个词或一段空白
+type Edit struct {
+	Op   Op
+	Text string
+}
+
+// Lines 行级diff
+func Lines(a, b string) []Edit {
+	return myers(splitLines(a), splitLines(b))
+}
+
+// Words 词级diff, 连续的字母数字为一个词, 空白和标点单独成词, 汉字等按单字切分
+func Words(a, b string) []Edit {
+	return merge(myers(splitWords(a), splitWords(b)))
+}
+
+// Changed 是否存在差异
+func Changed(edits []Edit) bool {
+	for _, e := range edits {
+		if e.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+func splitWords(s string) []string {
+	var tokens []string
+	start := -1 // 当前词的起始位置, -1表示不在词中
+	for i, r := range s {
+		if isWordRune(r) && !isCJK(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, s[start:i])
+			start = -1
+		}
+		tokens = append(tokens, s[i:i+utf8.RuneLen(r)])
+	}
+	if start >= 0 {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+// merge 合并相邻的同类编辑, 使词级diff的输出更紧凑
+func merge(edits []Edit) []Edit {
+	var out []Edit
+	for _, e := range edits {
+		if n := len(out); n > 0 && out[n-1].Op == e.Op {
+			out[n-1].Text += e.Text
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// Unified 生成统一格式(unified diff)的行级差异, context为变更前后保留的上下文行数; 无差异时返回空字符串
+func Unified(nameA, nameB, a, b string, context int) string {
+	edits := Lines(a, b)
+	if !Changed(edits) {
+		return ""
+	}
+	if context < 0 {
+		context = 0
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", nameA, nameB)
+
+	// lineA/lineB为每个edit开始时在a、b中的行号(从0开始)
+	lineA := make([]int, len(edits)+1)
+	lineB := make([]int, len(edits)+1)
+	for i, e := range edits {
+		lineA[i+1], lineB[i+1] = lineA[i], lineB[i]
+		if e.Op != Insert {
+			lineA[i+1]++
+		}
+		if e.Op != Delete {
+			lineB[i+1]++
+		}
+	}
+
+	for i := 0; i < len(edits); {
+		if edits[i].Op == Equal {
+			i++
+			continue
+		}
+		// 找到hunk的范围: 相邻变更之间的相同行不超过2*context时合并为一个hunk
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(edits) {
+			if edits[end].Op != Equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].Op == Equal {
+				run++
+			}
+			if run == len(edits) || run-end > 2*context {
+				end += min(context, run-end)
+				break
+			}
+			end = run
+		}
+
+		countA, countB := lineA[end]-lineA[start], lineB[end]-lineB[start]
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(lineA[start], countA), hunkRange(lineB[start], countB))
+		for _, e := range edits[start:end] {
+			switch e.Op {
+			case Equal:
+				sb.WriteString(" ")
+			case Insert:
+				sb.WriteString("+")
+			case Delete:
+				sb.WriteString("-")
+			}
+			sb.WriteString(e.Text)
+			sb.WriteString("\n")
+		}
+		i = end
+	}
+	return sb.String()
+}
+
+// hunkRange 行号从1开始, 行数为0时起始行为前一行
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// HTML 将diff渲染为HTML, 删除的内容包裹在<del>中, 新增的包裹在<ins>中, 文本会被转义;
+// 行级diff的每一行以换行符结尾
+func HTML(edits []Edit, lines bool) string {
+	var sb strings.Builder
+	for _, e := range edits {
+		text := html.EscapeString(e.Text)
+		if lines {
+			text += "\n"
+		}
+		switch e.Op {
+		case Equal:
+			sb.WriteString(text)
+		case Insert:
+			sb.WriteString("<ins>" + text + "</ins>")
+		case Delete:
+			sb.WriteString("<del>" + text + "</del>")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,81 @@
+package diffutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{name: "equal", a: "a\nb\n", b: "a\nb\n", context: 3, want: ""},
+		{
+			name:    "modify middle",
+			a:       "port: 80\nhost: a\ndebug: false\n",
+			b:       "port: 80\nhost: b\ndebug: false\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1,3 +1,3 @@\n port: 80\n-host: a\n+host: b\n debug: false\n",
+		},
+		{
+			name:    "two hunks",
+			a:       "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:       "0\n2\n3\n4\n5\n6\n7\n9\n",
+			context: 1,
+			want:    "--- old\n+++ new\n@@ -1,2 +1,2 @@\n-1\n+0\n 2\n@@ -7,2 +7,2 @@\n 7\n-8\n+9\n",
+		},
+		{
+			name:    "from empty",
+			a:       "",
+			b:       "x\n",
+			context: 3,
+			want:    "--- old\n+++ new\n@@ -0,0 +1 @@\n+x\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("old", "new", tt.a, tt.b, tt.context); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "english", a: "the quick fox", b: "the slow fox", want: "the <del>quick</del><ins>slow</ins> fox"},
+		{name: "chinese", a: "今天天气好", b: "今天天气很好", want: "今天天气<ins>很</ins>好"},
+		{name: "escape", a: "a<b", b: "a>b", want: "a<del>&lt;</del><ins>&gt;</ins>b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTML(Words(tt.a, tt.b), false); got != tt.want {
+				t.Errorf("HTML(Words()) = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinesRoundTrip(t *testing.T) {
+	a := strings.Repeat("same\n", 50) + "old\n" + strings.Repeat("tail\n", 50)
+	b := "head\n" + strings.Repeat("same\n", 50) + "new\n" + strings.Repeat("tail\n", 49)
+	var gotA, gotB []string
+	for _, e := range Lines(a, b) {
+		if e.Op != Insert {
+			gotA = append(gotA, e.Text)
+		}
+		if e.Op != Delete {
+			gotB = append(gotB, e.Text)
+		}
+	}
+	if strings.Join(gotA, "\n")+"\n" != a || strings.Join(gotB, "\n")+"\n" != b {
+		t.Errorf("Lines() edits do not reproduce inputs")
+	}
+}
@@ -0,0 +1,78 @@
+package diffutil
+
+// myers 计算a到b的最短编辑脚本(Myers O(ND)算法)
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	total := n + m
+	if total == 0 {
+		return nil
+	}
+	offset := total
+	v := make([]int, 2*total+2)
+	var trace [][]int
+
+	for d := 0; d <= total; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, offset, d)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack 根据每一步的v回溯出编辑脚本
+func backtrack(a, b []string, trace [][]int, offset, d int) []Edit {
+	x, y := len(a), len(b)
+	var rev []Edit
+	for ; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			rev = append(rev, Edit{Op: Equal, Text: a[x]})
+		}
+		if x == prevX {
+			y--
+			rev = append(rev, Edit{Op: Insert, Text: b[y]})
+		} else {
+			x--
+			rev = append(rev, Edit{Op: Delete, Text: a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		rev = append(rev, Edit{Op: Equal, Text: a[x]})
+	}
+
+	edits := make([]Edit, len(rev))
+	for i := range rev {
+		edits[i] = rev[len(rev)-1-i]
+	}
+	return edits
+}
@@ -0,0 +1,335 @@
+package dump
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ChangSZ/golib/mask"
+)
+
+// 默认限制
+const (
+	DefaultMaxDepth = 10
+	DefaultMaxItems = 100
+)
+
+// Option Sdump的可选参数
+type Option func(*printer)
+
+// WithMaxDepth 最大展开层数, 超过的部分输出为"..."
+func WithMaxDepth(n int) Option {
+	return func(p *printer) {
+		p.maxDepth = n
+	}
+}
+
+// WithMaxItems 切片、数组、map最多输出的元素个数
+func WithMaxItems(n int) Option {
+	return func(p *printer) {
+		p.maxItems = n
+	}
+}
+
+// WithRedact 是否按mask标签脱敏, 默认开启
+func WithRedact(redact bool) Option {
+	return func(p *printer) {
+		p.redact = redact
+	}
+}
+
+// WithIndent 缩进字符串, 默认两个空格
+func WithIndent(indent string) Option {
+	return func(p *printer) {
+		p.indent = indent
+	}
+}
+
+// Sdump 以带类型信息的多行格式输出v, 用于调试日志, 比%+v更安全:
+//
+//   - 循环引用输出为<cycle *T>, 不会死循环
+//   - 超过最大层数和元素个数的部分被截断
+//   - 带mask标签的字段按标签脱敏
+//   - 不调用被输出值的任何方法(time.Time除外), 不会因为String()等方法panic
+func Sdump(v interface{}, opts ...Option) string {
+	p := &printer{
+		maxDepth: DefaultMaxDepth,
+		maxItems: DefaultMaxItems,
+		redact:   true,
+		indent:   "  ",
+		visiting: make(map[visit]bool),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.print(reflect.ValueOf(v), 0, nil)
+	return p.b.String()
+}
+
+// Fdump 将Sdump的结果写入w
+func Fdump(w io.Writer, v interface{}, opts ...Option) error {
+	_, err := io.WriteString(w, Sdump(v, opts...)+"\n")
+	return err
+}
+
+// Dump 将Sdump的结果输出到标准输出
+func Dump(v interface{}, opts ...Option) {
+	_ = Fdump(os.Stdout, v, opts...)
+}
+
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+type printer struct {
+	b        strings.Builder
+	maxDepth int
+	maxItems int
+	redact   bool
+	indent   string
+	visiting map[visit]bool
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (p *printer) newline(depth int) {
+	p.b.WriteByte('\n')
+	p.b.WriteString(strings.Repeat(p.indent, depth))
+}
+
+// print 输出v, maskFn不为nil时对字符串脱敏
+func (p *printer) print(v reflect.Value, depth int, maskFn mask.Func) {
+	if !v.IsValid() {
+		p.b.WriteString("nil")
+		return
+	}
+	t := v.Type()
+	if maskFn != nil && !isContainer(v.Kind()) {
+		p.printMasked(v, maskFn)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			fmt.Fprintf(&p.b, "(%v)(nil)", t)
+			return
+		}
+		key := visit{ptr: v.Pointer(), typ: t}
+		if p.visiting[key] {
+			fmt.Fprintf(&p.b, "<cycle %v>", t)
+			return
+		}
+		p.visiting[key] = true
+		defer delete(p.visiting, key)
+		p.b.WriteByte('&')
+		p.print(v.Elem(), depth, maskFn)
+	case reflect.Interface:
+		if v.IsNil() {
+			fmt.Fprintf(&p.b, "%v(nil)", t)
+			return
+		}
+		p.print(v.Elem(), depth, maskFn)
+	case reflect.Struct:
+		if t == timeType && v.CanInterface() {
+			fmt.Fprintf(&p.b, "time.Time(%s)", v.Interface().(time.Time).Format(time.RFC3339Nano))
+			return
+		}
+		p.printStruct(v, depth)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			fmt.Fprintf(&p.b, "%v(nil)", t)
+			return
+		}
+		if t.Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+			fmt.Fprintf(&p.b, "%v(%q)", t, truncateBytes(v.Bytes(), p.maxItems))
+			return
+		}
+		if v.Kind() == reflect.Slice {
+			key := visit{ptr: v.Pointer(), typ: t}
+			if p.visiting[key] {
+				fmt.Fprintf(&p.b, "<cycle %v>", t)
+				return
+			}
+			p.visiting[key] = true
+			defer delete(p.visiting, key)
+		}
+		p.printList(v, depth, maskFn)
+	case reflect.Map:
+		if v.IsNil() {
+			fmt.Fprintf(&p.b, "%v(nil)", t)
+			return
+		}
+		key := visit{ptr: v.Pointer(), typ: t}
+		if p.visiting[key] {
+			fmt.Fprintf(&p.b, "<cycle %v>", t)
+			return
+		}
+		p.visiting[key] = true
+		defer delete(p.visiting, key)
+		p.printMap(v, depth, maskFn)
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if v.IsNil() {
+			fmt.Fprintf(&p.b, "%v(nil)", t)
+			return
+		}
+		fmt.Fprintf(&p.b, "%v(%#x)", t, v.Pointer())
+	default:
+		p.printScalar(v)
+	}
+}
+
+func isContainer(k reflect.Kind) bool {
+	switch k {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+func (p *printer) printMasked(v reflect.Value, maskFn mask.Func) {
+	if v.Kind() == reflect.String {
+		p.b.WriteString(strconv.Quote(maskFn(v.String())))
+		return
+	}
+	p.b.WriteString(strconv.Quote(mask.Placeholder))
+}
+
+func (p *printer) printScalar(v reflect.Value) {
+	t := v.Type()
+	var s string
+	switch v.Kind() {
+	case reflect.String:
+		s = strconv.Quote(v.String())
+		if t.Name() == "string" && t.PkgPath() == "" {
+			p.b.WriteString(s)
+			return
+		}
+	case reflect.Bool:
+		s = strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		s = strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		s = strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.Complex64, reflect.Complex128:
+		s = strconv.FormatComplex(v.Complex(), 'g', -1, 128)
+	default:
+		s = "?"
+	}
+	if t == reflect.TypeOf(time.Duration(0)) {
+		s = time.Duration(v.Int()).String()
+	}
+	// 内置类型直接输出值, 具名类型附带类型名
+	if t.PkgPath() == "" && t.Name() == v.Kind().String() {
+		p.b.WriteString(s)
+		return
+	}
+	fmt.Fprintf(&p.b, "%v(%s)", t, s)
+}
+
+func (p *printer) printStruct(v reflect.Value, depth int) {
+	t := v.Type()
+	fmt.Fprintf(&p.b, "%v{", t)
+	if v.NumField() == 0 {
+		p.b.WriteByte('}')
+		return
+	}
+	if depth >= p.maxDepth {
+		p.b.WriteString("...}")
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := t.Field(i)
+		p.newline(depth + 1)
+		p.b.WriteString(field.Name)
+		p.b.WriteString(": ")
+		var maskFn mask.Func
+		if p.redact {
+			maskFn, _ = mask.FieldFunc(field)
+		}
+		p.print(v.Field(i), depth+1, maskFn)
+		p.b.WriteByte(',')
+	}
+	p.newline(depth)
+	p.b.WriteByte('}')
+}
+
+func (p *printer) printList(v reflect.Value, depth int, maskFn mask.Func) {
+	fmt.Fprintf(&p.b, "%v{", v.Type())
+	if v.Len() == 0 {
+		p.b.WriteByte('}')
+		return
+	}
+	if depth >= p.maxDepth {
+		p.b.WriteString("...}")
+		return
+	}
+	n := v.Len()
+	for i := 0; i < n && i < p.maxItems; i++ {
+		p.newline(depth + 1)
+		p.print(v.Index(i), depth+1, maskFn)
+		p.b.WriteByte(',')
+	}
+	if n > p.maxItems {
+		p.newline(depth + 1)
+		fmt.Fprintf(&p.b, "... %d more", n-p.maxItems)
+	}
+	p.newline(depth)
+	p.b.WriteByte('}')
+}
+
+func (p *printer) printMap(v reflect.Value, depth int, maskFn mask.Func) {
+	fmt.Fprintf(&p.b, "%v{", v.Type())
+	if v.Len() == 0 {
+		p.b.WriteByte('}')
+		return
+	}
+	if depth >= p.maxDepth {
+		p.b.WriteString("...}")
+		return
+	}
+
+	// 按输出的key排序, 保证结果稳定
+	type entry struct {
+		key string
+		val reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		kp := &printer{maxDepth: 1, maxItems: p.maxItems, indent: "", visiting: map[visit]bool{}}
+		kp.print(iter.Key(), p.maxDepth, nil)
+		entries = append(entries, entry{key: kp.b.String(), val: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	for i, e := range entries {
+		if i >= p.maxItems {
+			p.newline(depth + 1)
+			fmt.Fprintf(&p.b, "... %d more", len(entries)-p.maxItems)
+			break
+		}
+		p.newline(depth + 1)
+		p.b.WriteString(e.key)
+		p.b.WriteString(": ")
+		p.print(e.val, depth+1, maskFn)
+		p.b.WriteByte(',')
+	}
+	p.newline(depth)
+	p.b.WriteByte('}')
+}
+
+func truncateBytes(b []byte, n int) []byte {
+	if len(b) > n {
+		return b[:n]
+	}
+	return b
+}
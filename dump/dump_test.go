@@ -0,0 +1,97 @@
+package dump
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type node struct {
+	Name string
+	Next *node
+}
+
+type account struct {
+	User     string
+	Password string   `mask:"secret"`
+	Phone    string   `mask:"phone"`
+	Tokens   []string `mask:""`
+	PIN      int      `mask:""`
+	Timeout  time.Duration
+	Created  time.Time
+	Labels   map[string]int
+	Err      error
+	private  int
+}
+
+func TestSdump(t *testing.T) {
+	acc := &account{
+		User:     "jack",
+		Password: "p@ss",
+		Phone:    "13812345678",
+		Tokens:   []string{"t1"},
+		PIN:      1234,
+		Timeout:  3 * time.Second,
+		Created:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Labels:   map[string]int{"b": 2, "a": 1},
+		Err:      errors.New("boom"),
+		private:  7,
+	}
+	out := Sdump(acc)
+	for _, want := range []string{
+		"&dump.account{",
+		`User: "jack",`,
+		`Password: "******",`,
+		`Phone: "138****5678",`,
+		`"******",`,
+		`PIN: "******",`,
+		"Timeout: time.Duration(3s),",
+		"Created: time.Time(2024-01-02T03:04:05Z),",
+		"\"a\": 1,\n    \"b\": 2,",
+		"private: 7,",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Sdump() missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "p@ss") || strings.Contains(out, "t1") {
+		t.Errorf("Sdump() leaked secret:\n%s", out)
+	}
+	if !strings.Contains(Sdump(acc, WithRedact(false)), `"p@ss"`) {
+		t.Errorf("WithRedact(false) should keep secrets")
+	}
+}
+
+func TestLimits(t *testing.T) {
+	a := &node{Name: "a"}
+	a.Next = &node{Name: "b", Next: a}
+
+	tests := []struct {
+		name string
+		v    interface{}
+		opts []Option
+		want string
+	}{
+		{name: "cycle", v: a, want: "Next: <cycle *dump.node>"},
+		{name: "depth", v: a, opts: []Option{WithMaxDepth(1)}, want: "Next: &dump.node{...}"},
+		{name: "items", v: []int{1, 2, 3}, opts: []Option{WithMaxItems(2)}, want: "... 1 more"},
+		{name: "nil", v: nil, want: "nil"},
+		{name: "nil ptr", v: (*node)(nil), want: "(*dump.node)(nil)"},
+		{name: "bytes", v: []byte("hi"), want: `[]uint8("hi")`},
+		{name: "self map", v: selfMap(), want: "<cycle map[string]interface {}>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sdump(tt.v, tt.opts...); !strings.Contains(got, tt.want) {
+				t.Errorf("Sdump() = \n%s\nwant containing %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func selfMap() map[string]interface{} {
+	m := map[string]interface{}{}
+	m["self"] = m
+	return m
+}
@@ -0,0 +1,113 @@
+package encodingx
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Crockford Crockford Base32, 不区分大小写, 去掉了 I L O U, 解码时 I/L 视为1, O视为0, 忽略连字符
+var Crockford = newCrockfordEncoding()
+
+// CrockfordEncoding Crockford Base32编码, 按5bit分组, 无填充
+type CrockfordEncoding struct {
+	decodeMap [256]int8
+}
+
+func newCrockfordEncoding() *CrockfordEncoding {
+	e := &CrockfordEncoding{}
+	for i := range e.decodeMap {
+		e.decodeMap[i] = -1
+	}
+	for i := 0; i < len(crockfordAlphabet); i++ {
+		c := crockfordAlphabet[i]
+		e.decodeMap[c] = int8(i)
+		e.decodeMap[strings.ToLower(string(c))[0]] = int8(i)
+	}
+	for _, c := range "oO" {
+		e.decodeMap[c] = 0
+	}
+	for _, c := range "iIlL" {
+		e.decodeMap[c] = 1
+	}
+	return e
+}
+
+// EncodeToString 编码字节串
+func (e *CrockfordEncoding) EncodeToString(src []byte) string {
+	out := make([]byte, 0, (len(src)*8+4)/5)
+	var buf uint32
+	bits := 0
+	for _, b := range src {
+		buf = buf<<8 | uint32(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out = append(out, crockfordAlphabet[(buf>>uint(bits))&0x1f])
+		}
+	}
+	if bits > 0 {
+		out = append(out, crockfordAlphabet[(buf<<uint(5-bits))&0x1f])
+	}
+	return string(out)
+}
+
+// DecodeString 解码字符串
+func (e *CrockfordEncoding) DecodeString(s string) ([]byte, error) {
+	s = strings.ReplaceAll(s, "-", "")
+	out := make([]byte, 0, len(s)*5/8)
+	var buf uint32
+	bits := 0
+	for i := 0; i < len(s); i++ {
+		d := e.decodeMap[s[i]]
+		if d < 0 {
+			return nil, fmt.Errorf("encodingx: illegal character %q at offset %d", s[i], i)
+		}
+		buf = buf<<5 | uint32(d)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			out = append(out, byte(buf>>uint(bits)))
+		}
+	}
+	return out, nil
+}
+
+// EncodeInt64 将非负整数编码为字符串
+func (e *CrockfordEncoding) EncodeInt64(n int64) (string, error) {
+	if n < 0 {
+		return "", ErrNegative
+	}
+	if n == 0 {
+		return "0", nil
+	}
+	var buf [13]byte
+	i := len(buf)
+	for u := uint64(n); u > 0; u >>= 5 {
+		i--
+		buf[i] = crockfordAlphabet[u&0x1f]
+	}
+	return string(buf[i:]), nil
+}
+
+// DecodeInt64 EncodeInt64的逆操作
+func (e *CrockfordEncoding) DecodeInt64(s string) (int64, error) {
+	s = strings.ReplaceAll(s, "-", "")
+	if s == "" {
+		return 0, fmt.Errorf("encodingx: empty string")
+	}
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		d := e.decodeMap[s[i]]
+		if d < 0 {
+			return 0, fmt.Errorf("encodingx: illegal character %q at offset %d", s[i], i)
+		}
+		if n > (math.MaxInt64-uint64(d))>>5 {
+			return 0, ErrOverflow
+		}
+		n = n<<5 | uint64(d)
+	}
+	return int64(n), nil
+}
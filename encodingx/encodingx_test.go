@@ -0,0 +1,92 @@
+package encodingx
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestBase58(t *testing.T) {
+	tests := []struct {
+		name string
+		src  []byte
+		want string
+	}{
+		{name: "empty", src: []byte{}, want: ""},
+		{name: "hello", src: []byte("Hello World!"), want: "2NEpo7TZRRrLZSi2U"},
+		{name: "leading_zero", src: []byte{0, 0, 0x28, 0x7f, 0xb4, 0xcd}, want: "11233QC4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Base58.EncodeToString(tt.src)
+			if got != tt.want {
+				t.Errorf("EncodeToString() = %v, want %v", got, tt.want)
+			}
+			decoded, err := Base58.DecodeString(got)
+			if err != nil || !bytes.Equal(decoded, tt.src) {
+				t.Errorf("DecodeString() = %v, %v, want %v", decoded, err, tt.src)
+			}
+		})
+	}
+
+	if _, err := Base58.DecodeString("0OIl"); err == nil {
+		t.Errorf("DecodeString() expect error for illegal characters")
+	}
+}
+
+func TestInt64(t *testing.T) {
+	type codec interface {
+		EncodeInt64(n int64) (string, error)
+		DecodeInt64(s string) (int64, error)
+	}
+	tests := []struct {
+		name  string
+		codec codec
+		n     int64
+		want  string
+	}{
+		{name: "base62_zero", codec: Base62, n: 0, want: "0"},
+		{name: "base62", codec: Base62, n: 125, want: "21"},
+		{name: "base62_max", codec: Base62, n: math.MaxInt64, want: "AzL8n0Y58m7"},
+		{name: "base58", codec: Base58, n: 57, want: "z"},
+		{name: "crockford", codec: Crockford, n: 1234, want: "16J"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.codec.EncodeInt64(tt.n)
+			if err != nil || got != tt.want {
+				t.Fatalf("EncodeInt64() = %v, %v, want %v", got, err, tt.want)
+			}
+			n, err := tt.codec.DecodeInt64(got)
+			if err != nil || n != tt.n {
+				t.Errorf("DecodeInt64() = %v, %v, want %v", n, err, tt.n)
+			}
+		})
+	}
+
+	if _, err := Base62.DecodeInt64("AzL8n0Y58m8"); err != ErrOverflow {
+		t.Errorf("DecodeInt64() error = %v, want %v", err, ErrOverflow)
+	}
+	if _, err := Base62.EncodeInt64(-1); err != ErrNegative {
+		t.Errorf("EncodeInt64() error = %v, want %v", err, ErrNegative)
+	}
+}
+
+func TestCrockford(t *testing.T) {
+	src := []byte("foobar")
+	got := Crockford.EncodeToString(src)
+	if got != "CSQPYRK1E8" {
+		t.Errorf("EncodeToString() = %v, want CSQPYRK1E8", got)
+	}
+
+	// 小写、连字符、易混淆字符均可解码
+	for _, s := range []string{"CSQPYRK1E8", "csqp-yrk1-e8", "CSQPYRKIE8", "CSQPYRKlE8"} {
+		decoded, err := Crockford.DecodeString(s)
+		if err != nil || !bytes.Equal(decoded, src) {
+			t.Errorf("DecodeString(%q) = %s, %v, want %s", s, decoded, err, src)
+		}
+	}
+	if n, err := Crockford.DecodeInt64("1O"); err != nil || n != 32 {
+		t.Errorf("DecodeInt64() = %v, %v, want 32", n, err)
+	}
+}
@@ -0,0 +1,151 @@
+package encodingx
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+var (
+	// Base58 比特币字母表, 去掉了易混淆的 0OIl
+	Base58 = NewRadixEncoding(base58Alphabet)
+
+	// Base62 0-9A-Za-z, 适合短链接
+	Base62 = NewRadixEncoding(base62Alphabet)
+)
+
+// ErrNegative 不支持负数
+var ErrNegative = errors.New("encodingx: negative number")
+
+// ErrOverflow 解码结果超出int64范围
+var ErrOverflow = errors.New("encodingx: value overflows int64")
+
+// RadixEncoding 将字节串视为大整数进行进制转换的编码, 前导0字节编码为字母表首字符
+type RadixEncoding struct {
+	alphabet  string
+	base      int
+	decodeMap [256]int16
+}
+
+// NewRadixEncoding 使用给定字母表创建编码, 字母表长度即进制
+func NewRadixEncoding(alphabet string) *RadixEncoding {
+	if len(alphabet) < 2 || len(alphabet) > 256 {
+		panic("encodingx: invalid alphabet length")
+	}
+	e := &RadixEncoding{alphabet: alphabet, base: len(alphabet)}
+	for i := range e.decodeMap {
+		e.decodeMap[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		if e.decodeMap[alphabet[i]] != -1 {
+			panic("encodingx: duplicate character in alphabet")
+		}
+		e.decodeMap[alphabet[i]] = int16(i)
+	}
+	return e
+}
+
+// EncodeToString 编码字节串
+func (e *RadixEncoding) EncodeToString(src []byte) string {
+	zeros := 0
+	for zeros < len(src) && src[zeros] == 0 {
+		zeros++
+	}
+
+	// log(256)/log(base) 估算输出长度
+	size := int(float64(len(src)-zeros)*math.Log(256)/math.Log(float64(e.base))) + 1
+	digits := make([]byte, 0, size)
+	for _, b := range src[zeros:] {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % e.base)
+			carry /= e.base
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%e.base))
+			carry /= e.base
+		}
+	}
+
+	out := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		out[i] = e.alphabet[0]
+	}
+	for i, d := range digits {
+		out[len(out)-1-i] = e.alphabet[d]
+	}
+	return string(out)
+}
+
+// DecodeString 解码字符串
+func (e *RadixEncoding) DecodeString(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == e.alphabet[0] {
+		zeros++
+	}
+
+	bytes := make([]byte, 0, len(s))
+	for i := zeros; i < len(s); i++ {
+		carry := int(e.decodeMap[s[i]])
+		if carry < 0 {
+			return nil, fmt.Errorf("encodingx: illegal character %q at offset %d", s[i], i)
+		}
+		for j := range bytes {
+			carry += int(bytes[j]) * e.base
+			bytes[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			bytes = append(bytes, byte(carry))
+			carry >>= 8
+		}
+	}
+
+	out := make([]byte, zeros+len(bytes))
+	for i, b := range bytes {
+		out[len(out)-1-i] = b
+	}
+	return out, nil
+}
+
+// EncodeInt64 将非负整数编码为字符串, 常用于短链接: 125 => "21"(Base62)
+func (e *RadixEncoding) EncodeInt64(n int64) (string, error) {
+	if n < 0 {
+		return "", ErrNegative
+	}
+	if n == 0 {
+		return e.alphabet[:1], nil
+	}
+	var buf [64]byte
+	i := len(buf)
+	for u := uint64(n); u > 0; u /= uint64(e.base) {
+		i--
+		buf[i] = e.alphabet[u%uint64(e.base)]
+	}
+	return string(buf[i:]), nil
+}
+
+// DecodeInt64 EncodeInt64的逆操作
+func (e *RadixEncoding) DecodeInt64(s string) (int64, error) {
+	if s == "" {
+		return 0, errors.New("encodingx: empty string")
+	}
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		d := e.decodeMap[s[i]]
+		if d < 0 {
+			return 0, fmt.Errorf("encodingx: illegal character %q at offset %d", s[i], i)
+		}
+		if n > (math.MaxInt64-uint64(d))/uint64(e.base) {
+			return 0, ErrOverflow
+		}
+		n = n*uint64(e.base) + uint64(d)
+	}
+	return int64(n), nil
+}
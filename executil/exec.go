@@ -0,0 +1,195 @@
+package executil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultMaxOutput stdout/stderr 默认各最多保留1MB, 超出部分丢弃
+const DefaultMaxOutput = 1 << 20
+
+// ErrTimeout 命令执行超时
+var ErrTimeout = errors.New("executil: command timed out")
+
+// Result 命令执行结果
+type Result struct {
+	ExitCode        int           // 退出码, 未能启动或被信号杀死时为-1
+	Stdout          []byte        // 标准输出, 最多MaxOutput字节
+	Stderr          []byte        // 标准错误, 最多MaxOutput字节
+	StdoutTruncated bool          // 标准输出是否被截断
+	StderrTruncated bool          // 标准错误是否被截断
+	Duration        time.Duration // 执行耗时
+	TimedOut        bool          // 是否因超时被杀死
+}
+
+// Option is Run option.
+type Option func(*options)
+
+type options struct {
+	timeout   time.Duration
+	dir       string
+	env       []string
+	cleanEnv  bool
+	stdin     io.Reader
+	maxOutput int
+}
+
+// WithTimeout 超时时间, 超时后杀死整个进程组
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithDir 工作目录
+func WithDir(dir string) Option {
+	return func(o *options) {
+		o.dir = dir
+	}
+}
+
+// WithEnv 追加环境变量, 格式为 "KEY=value", 默认继承当前进程的环境变量
+func WithEnv(env ...string) Option {
+	return func(o *options) {
+		o.env = append(o.env, env...)
+	}
+}
+
+// WithCleanEnv 不继承当前进程的环境变量, 只使用WithEnv指定的
+func WithCleanEnv() Option {
+	return func(o *options) {
+		o.cleanEnv = true
+	}
+}
+
+// WithStdin 标准输入
+func WithStdin(r io.Reader) Option {
+	return func(o *options) {
+		o.stdin = r
+	}
+}
+
+// WithMaxOutput stdout/stderr 各最多保留的字节数
+func WithMaxOutput(n int) Option {
+	return func(o *options) {
+		o.maxOutput = n
+	}
+}
+
+// Run 执行外部命令并收集输出
+//
+// - 不经过shell, args原样传给命令, 避免注入
+// - ctx取消或超时时杀死整个进程组, 子进程不会成为孤儿继续运行
+// - 退出码非0时返回error, 此时Result依然有效
+func Run(ctx context.Context, name string, args []string, opts ...Option) (*Result, error) {
+	o := &options{maxOutput: DefaultMaxOutput}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	stdout := &cappedBuffer{max: o.maxOutput}
+	stderr := &cappedBuffer{max: o.maxOutput}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = o.dir
+	cmd.Stdin = o.stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if o.cleanEnv {
+		cmd.Env = o.env
+	} else if len(o.env) > 0 {
+		cmd.Env = append(os.Environ(), o.env...)
+	}
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		return killProcessGroup(cmd)
+	}
+	// 孙进程持有输出管道时, 避免Wait一直阻塞
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	res := &Result{
+		ExitCode:        -1,
+		Stdout:          stdout.buf.Bytes(),
+		Stderr:          stderr.buf.Bytes(),
+		StdoutTruncated: stdout.truncated,
+		StderrTruncated: stderr.truncated,
+		Duration:        time.Since(start),
+	}
+	if cmd.ProcessState != nil {
+		res.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil && cmd.ProcessState != nil && !cmd.ProcessState.Success() {
+		if errors.Is(ctxErr, context.DeadlineExceeded) {
+			res.TimedOut = true
+			return res, fmt.Errorf("%w after %v: %s", ErrTimeout, res.Duration, commandLine(name, args))
+		}
+		return res, fmt.Errorf("executil: %s: %w", commandLine(name, args), ctxErr)
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return res, fmt.Errorf("executil: %s exited with code %d: %s",
+				commandLine(name, args), res.ExitCode, lastLine(res.Stderr))
+		}
+		return res, fmt.Errorf("executil: %s: %w", commandLine(name, args), err)
+	}
+	return res, nil
+}
+
+// Output 执行命令并返回去掉首尾空白的标准输出
+func Output(ctx context.Context, name string, args []string, opts ...Option) (string, error) {
+	res, err := Run(ctx, name, args, opts...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(res.Stdout)), nil
+}
+
+func commandLine(name string, args []string) string {
+	return strings.TrimSpace(name + " " + strings.Join(args, " "))
+}
+
+func lastLine(b []byte) string {
+	s := strings.TrimSpace(string(b))
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
+
+// cappedBuffer 最多保留max字节, 超出部分丢弃但仍返回写入成功, 防止子进程因管道写失败退出
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	remain := b.max - b.buf.Len()
+	if remain <= 0 {
+		b.truncated = b.truncated || len(p) > 0
+		return len(p), nil
+	}
+	if len(p) > remain {
+		b.buf.Write(p[:remain])
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
@@ -0,0 +1,93 @@
+//go:build !windows
+// +build !windows
+
+package executil
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		cmd       string
+		args      []string
+		opts      []Option
+		wantOut   string
+		wantCode  int
+		wantErr   bool
+		truncated bool
+	}{
+		{
+			name:    "stdout",
+			cmd:     "echo",
+			args:    []string{"hello", "world"},
+			wantOut: "hello world\n",
+		},
+		{
+			name:    "stdin_env_dir",
+			cmd:     "sh",
+			args:    []string{"-c", `read x; echo "$x $GREETING $(pwd)"`},
+			opts:    []Option{WithStdin(strings.NewReader("hi\n")), WithEnv("GREETING=golib"), WithDir("/")},
+			wantOut: "hi golib /\n",
+		},
+		{
+			name:     "exit_code",
+			cmd:      "sh",
+			args:     []string{"-c", "echo oops >&2; exit 3"},
+			wantCode: 3,
+			wantErr:  true,
+		},
+		{
+			name:      "truncated",
+			cmd:       "sh",
+			args:      []string{"-c", "echo 0123456789"},
+			opts:      []Option{WithMaxOutput(4)},
+			wantOut:   "0123",
+			truncated: true,
+		},
+		{
+			name:     "not_found",
+			cmd:      "golib-command-not-exists",
+			wantCode: -1,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Run(context.Background(), tt.cmd, tt.args, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(res.Stdout) != tt.wantOut {
+				t.Errorf("Stdout = %q, want %q", res.Stdout, tt.wantOut)
+			}
+			if res.ExitCode != tt.wantCode {
+				t.Errorf("ExitCode = %d, want %d", res.ExitCode, tt.wantCode)
+			}
+			if res.StdoutTruncated != tt.truncated {
+				t.Errorf("StdoutTruncated = %v, want %v", res.StdoutTruncated, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestRunTimeoutKillsGroup(t *testing.T) {
+	start := time.Now()
+	// 子shell派生的sleep也要被杀死, 否则Run会等到sleep结束
+	res, err := Run(context.Background(), "sh", []string{"-c", "sleep 10 & sleep 10; echo done"},
+		WithTimeout(200*time.Millisecond))
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Run() error = %v, want %v", err, ErrTimeout)
+	}
+	if !res.TimedOut {
+		t.Errorf("TimedOut = false, want true")
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Run() took %v, process group was not killed", elapsed)
+	}
+}
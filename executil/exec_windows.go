@@ -0,0 +1,23 @@
+//go:build windows
+// +build windows
+
+package executil
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+func setProcessGroup(_ *exec.Cmd) {}
+
+// killProcessGroup 使用taskkill杀死进程树
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	kill := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid))
+	if err := kill.Run(); err != nil {
+		return cmd.Process.Kill()
+	}
+	return nil
+}
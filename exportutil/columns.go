@@ -0,0 +1,70 @@
+package exportutil
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/ChangSZ/golib/conv"
+	"github.com/ChangSZ/golib/reflectutil"
+	"github.com/ChangSZ/golib/tagparse"
+)
+
+// TagName 结构体标签名, 如`export:"下单时间,layout=2006-01-02"`, "-"表示不导出
+const TagName = "export"
+
+// DefaultTimeLayout 未指定layout时time.Time的格式
+const DefaultTimeLayout = "2006-01-02 15:04:05"
+
+// Column 导出的一列
+type Column[T any] struct {
+	Header string
+	Value  func(row T) string
+}
+
+// StructColumns 根据结构体的export标签生成列, 未设置标签的字段以字段名为表头
+func StructColumns[T any]() []Column[T] {
+	var cols []Column[T]
+	for _, f := range reflectutil.Fields(reflect.TypeOf((*T)(nil)).Elem()) {
+		tag := tagparse.Get(f.StructField, TagName)
+		if tag.Skip {
+			continue
+		}
+		layout, ok := tag.Param("layout")
+		if !ok {
+			layout = DefaultTimeLayout
+		}
+		index := f.Index
+		cols = append(cols, Column[T]{
+			Header: tag.NameOr(f.Name),
+			Value: func(row T) string {
+				v := reflectutil.FieldByIndex(reflect.ValueOf(&row).Elem(), index, false)
+				return format(v, layout)
+			},
+		})
+	}
+	return cols
+}
+
+// format 单元格的文本, nil指针为空
+func format(v reflect.Value, layout string) string {
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ""
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(layout)
+	}
+	s, err := conv.ToString(v.Interface())
+	if err != nil {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,121 @@
+package exportutil
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ChangSZ/golib/progress"
+)
+
+// ErrRowLimit 数据超过WithMaxRows设置的上限
+var ErrRowLimit = errors.New("exportutil: row limit exceeded")
+
+// Option Export的可选参数
+type Option func(*options)
+
+type options struct {
+	maxRows       int64
+	flushBytes    int
+	progressEvery int64
+	onProgress    func(rows int64)
+	job           *progress.Job
+}
+
+// WithMaxRows 最多导出的数据行数(不含表头), 超过时返回ErrRowLimit, 默认不限制
+func WithMaxRows(n int64) Option {
+	return func(o *options) {
+		o.maxRows = n
+	}
+}
+
+// WithFlushBytes 缓冲的数据超过n字节时写到底层, 控制内存占用, 默认1MB
+func WithFlushBytes(n int) Option {
+	return func(o *options) {
+		o.flushBytes = n
+	}
+}
+
+// WithProgress 每导出every行回调一次已导出的行数, 结束时再回调一次
+func WithProgress(every int64, fn func(rows int64)) Option {
+	return func(o *options) {
+		o.progressEvery = every
+		o.onProgress = fn
+	}
+}
+
+// WithJob 将进度上报到progress.Job, 数据源总量已知时应先调用job.SetTotal
+func WithJob(job *progress.Job) Option {
+	return func(o *options) {
+		o.job = job
+	}
+}
+
+// Export 从src逐行读取并写出, 第一行为表头, 返回导出的数据行数; 结束后会调用w.Close
+//
+// 数据不会整体加载到内存中, 导出大量数据时应配合PageSource和流式的响应或文件
+//
+//	w, _ := exportutil.NewCSVWriter(resp, true)
+//	n, err := exportutil.Export(ctx, src, w, exportutil.StructColumns[OrderRow](), exportutil.WithJob(job))
+func Export[T any](ctx context.Context, src Source[T], w RowWriter, cols []Column[T], opts ...Option) (int64, error) {
+	o := &options{flushBytes: 1 << 20, progressEvery: 1000}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	cells := make([]string, len(cols))
+	for i, c := range cols {
+		cells[i] = c.Header
+	}
+	if err := w.WriteRow(cells); err != nil {
+		return 0, err
+	}
+
+	var rows, reported int64
+	report := func() {
+		if o.onProgress != nil {
+			o.onProgress(rows)
+		}
+		if o.job != nil {
+			o.job.Add(rows - reported)
+		}
+		reported = rows
+	}
+	pending := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return rows, err
+		}
+		row, ok, err := src.Next(ctx)
+		if err != nil {
+			return rows, err
+		}
+		if !ok {
+			break
+		}
+		if o.maxRows > 0 && rows >= o.maxRows {
+			return rows, ErrRowLimit
+		}
+		for i, c := range cols {
+			cells[i] = c.Value(row)
+			pending += len(cells[i])
+		}
+		if err := w.WriteRow(cells); err != nil {
+			return rows, err
+		}
+		rows++
+		if pending >= o.flushBytes {
+			if err := w.Flush(); err != nil {
+				return rows, err
+			}
+			pending = 0
+		}
+		if o.progressEvery > 0 && rows%o.progressEvery == 0 {
+			report()
+		}
+	}
+	if err := w.Close(); err != nil {
+		return rows, err
+	}
+	report()
+	return rows, nil
+}
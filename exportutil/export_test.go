@@ -0,0 +1,142 @@
+package exportutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChangSZ/golib/progress"
+)
+
+type order struct {
+	ID        int64
+	Amount    float64
+	Remark    *string
+	Secret    string
+	CreatedAt time.Time
+}
+
+type orderRow struct {
+	ID        int64     `export:"订单号"`
+	Amount    float64   `export:"金额"`
+	Remark    *string   `export:"备注"`
+	Secret    string    `export:"-"`
+	CreatedAt time.Time `export:"下单时间,layout=2006-01-02"`
+}
+
+func orders(n int) []order {
+	out := make([]order, n)
+	remark := "加急, \"尽快\""
+	for i := range out {
+		out[i] = order{ID: int64(i + 1), Amount: 9.5, Secret: "x", CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+		if i == 0 {
+			out[i].Remark = &remark
+		}
+	}
+	return out
+}
+
+func TestExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewCSVWriter(&buf, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := Map(SliceSource(orders(2)), Assign[order, orderRow]())
+
+	var progressed []int64
+	n, err := Export(context.Background(), src, w, StructColumns[orderRow](),
+		WithProgress(1, func(rows int64) { progressed = append(progressed, rows) }))
+	if err != nil || n != 2 {
+		t.Fatalf("Export() = %d, %v", n, err)
+	}
+	want := "\xEF\xBB\xBF订单号,金额,备注,下单时间\n" +
+		"1,9.5,\"加急, \"\"尽快\"\"\",2024-01-02\n" +
+		"2,9.5,,2024-01-02\n"
+	if buf.String() != want {
+		t.Errorf("csv = %q, want %q", buf.String(), want)
+	}
+	if len(progressed) != 3 || progressed[2] != 2 {
+		t.Errorf("progress = %v", progressed)
+	}
+}
+
+func TestExportLimits(t *testing.T) {
+	newSource := func() Source[order] {
+		return PageSource(func(ctx context.Context, cursor string) ([]order, string, error) {
+			start, _ := strconv.Atoi(cursor)
+			if start >= 10 {
+				return nil, "", nil
+			}
+			return orders(3), strconv.Itoa(start + 3), nil
+		})
+	}
+	cols := []Column[order]{{Header: "id", Value: func(o order) string { return strconv.FormatInt(o.ID, 10) }}}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		wantRows int64
+		wantErr  error
+	}{
+		{name: "all pages", wantRows: 12},
+		{name: "row limit", opts: []Option{WithMaxRows(5)}, wantRows: 5, wantErr: ErrRowLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := NewCSVWriter(io.Discard, false)
+			n, err := Export(context.Background(), newSource(), w, cols, tt.opts...)
+			if n != tt.wantRows || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Export() = %d, %v", n, err)
+			}
+		})
+	}
+}
+
+func TestExportXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewXLSXWriter(&buf, "订单")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := progress.NewTracker(progress.NewMemoryStore(), progress.Config{})
+	job := tr.Start(context.Background(), "export")
+	job.SetTotal(3)
+
+	src := Map(SliceSource(orders(3)), Assign[order, orderRow]())
+	if _, err := Export(context.Background(), src, w, StructColumns[orderRow](), WithJob(job), WithFlushBytes(1)); err != nil {
+		t.Fatal(err)
+	}
+	if s := job.Snapshot(); s.Done != 3 || s.Percent != 100 {
+		t.Errorf("job = %+v", s)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+		if err := xml.Unmarshal(b, new(struct{})); err != nil {
+			t.Errorf("%s is not valid xml: %v", f.Name, err)
+		}
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	if strings.Count(sheet, "<row ") != 4 || !strings.Contains(sheet, "加急, &#34;尽快&#34;") {
+		t.Errorf("sheet = %s", sheet)
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `name="订单"`) {
+		t.Errorf("workbook = %s", files["xl/workbook.xml"])
+	}
+}
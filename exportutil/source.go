@@ -0,0 +1,93 @@
+package exportutil
+
+import (
+	"context"
+
+	"github.com/ChangSZ/golib/copy"
+)
+
+// Source 逐行读取的数据源, 没有更多数据时ok为false
+type Source[T any] interface {
+	Next(ctx context.Context) (row T, ok bool, err error)
+}
+
+// SliceSource 从切片读取, 主要用于测试和小数据量
+func SliceSource[T any](rows []T) Source[T] {
+	return &sliceSource[T]{rows: rows}
+}
+
+type sliceSource[T any] struct {
+	rows []T
+	i    int
+}
+
+func (s *sliceSource[T]) Next(ctx context.Context) (T, bool, error) {
+	var zero T
+	if s.i >= len(s.rows) {
+		return zero, false, nil
+	}
+	s.i++
+	return s.rows[s.i-1], true, nil
+}
+
+// PageSource 分页读取, 同一时刻只持有一页数据; fetch返回本页数据和下一页的游标, 游标为空表示结束
+//
+//	src := exportutil.PageSource(func(ctx context.Context, cursor string) ([]Order, string, error) {
+//		return repo.ListAfter(ctx, cursor, 1000)
+//	})
+func PageSource[T any](fetch func(ctx context.Context, cursor string) ([]T, string, error)) Source[T] {
+	return &pageSource[T]{fetch: fetch}
+}
+
+type pageSource[T any] struct {
+	fetch  func(ctx context.Context, cursor string) ([]T, string, error)
+	page   []T
+	i      int
+	cursor string
+	done   bool
+}
+
+func (s *pageSource[T]) Next(ctx context.Context) (T, bool, error) {
+	var zero T
+	for s.i >= len(s.page) {
+		if s.done {
+			return zero, false, nil
+		}
+		page, next, err := s.fetch(ctx, s.cursor)
+		if err != nil {
+			return zero, false, err
+		}
+		s.page, s.i, s.cursor, s.done = page, 0, next, next == ""
+	}
+	s.i++
+	return s.page[s.i-1], true, nil
+}
+
+// Map 逐行转换数据源
+func Map[T, R any](src Source[T], fn func(T) (R, error)) Source[R] {
+	return &mapSource[T, R]{src: src, fn: fn}
+}
+
+type mapSource[T, R any] struct {
+	src Source[T]
+	fn  func(T) (R, error)
+}
+
+func (s *mapSource[T, R]) Next(ctx context.Context) (R, bool, error) {
+	var zero R
+	row, ok, err := s.src.Next(ctx)
+	if err != nil || !ok {
+		return zero, ok, err
+	}
+	out, err := s.fn(row)
+	return out, err == nil, err
+}
+
+// Assign 用copy.AssignStructE将领域对象按字段名转换为导出用的结构体, T和R都应为结构体
+func Assign[T, R any](opts ...copy.AssignOption) func(T) (R, error) {
+	return func(row T) (R, error) {
+		var out R
+		err := copy.AssignStructE(&row, &out, opts...)
+		return out, err
+	}
+}
@@ -0,0 +1,147 @@
+package exportutil
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// RowWriter 按行写出表格
+type RowWriter interface {
+	WriteRow(cells []string) error
+	// Flush 将缓冲的数据写到底层的io.Writer
+	Flush() error
+	// Close 写出剩余数据和文件尾, 不关闭底层的io.Writer
+	Close() error
+}
+
+var (
+	_ RowWriter = (*CSVWriter)(nil)
+	_ RowWriter = (*XLSXWriter)(nil)
+)
+
+// CSVWriter 写出CSV
+type CSVWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVWriter new a CSVWriter, bom为true时先写入UTF-8 BOM, 让Excel正确识别中文
+func NewCSVWriter(w io.Writer, bom bool) (*CSVWriter, error) {
+	if bom {
+		if _, err := io.WriteString(w, "\xEF\xBB\xBF"); err != nil {
+			return nil, err
+		}
+	}
+	return &CSVWriter{w: csv.NewWriter(w)}, nil
+}
+
+func (c *CSVWriter) WriteRow(cells []string) error {
+	return c.w.Write(cells)
+}
+
+func (c *CSVWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *CSVWriter) Close() error {
+	return c.Flush()
+}
+
+// ErrTooManyRows 超过xlsx单个工作表的行数上限
+var ErrTooManyRows = errors.New("exportutil: xlsx sheet exceeds 1048576 rows")
+
+const xlsxMaxRows = 1 << 20
+
+// XLSXWriter 流式写出只有一个工作表的xlsx, 单元格均为文本, 内存占用与行数无关
+type XLSXWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	rows  int
+	buf   strings.Builder
+}
+
+// NewXLSXWriter new a XLSXWriter.
+func NewXLSXWriter(w io.Writer, sheetName string) (*XLSXWriter, error) {
+	if sheetName == "" {
+		sheetName = "Sheet1"
+	}
+	zw := zip.NewWriter(w)
+	var name strings.Builder
+	_ = xml.EscapeText(&name, []byte(sheetName))
+	files := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", strings.Replace(xlsxWorkbook, "{name}", name.String(), 1)},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(fw, xmlHeader+f.body); err != nil {
+			return nil, err
+		}
+	}
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(sheet, xmlHeader+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return nil, err
+	}
+	return &XLSXWriter{zw: zw, sheet: sheet}, nil
+}
+
+func (x *XLSXWriter) WriteRow(cells []string) error {
+	if x.rows >= xlsxMaxRows {
+		return ErrTooManyRows
+	}
+	x.rows++
+	x.buf.Reset()
+	x.buf.WriteString(`<row r="`)
+	x.buf.WriteString(strconv.Itoa(x.rows))
+	x.buf.WriteString(`">`)
+	for _, cell := range cells {
+		x.buf.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		_ = xml.EscapeText(&x.buf, []byte(cell))
+		x.buf.WriteString(`</t></is></c>`)
+	}
+	x.buf.WriteString(`</row>`)
+	_, err := io.WriteString(x.sheet, x.buf.String())
+	return err
+}
+
+func (x *XLSXWriter) Flush() error {
+	return x.zw.Flush()
+}
+
+func (x *XLSXWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+const (
+	xmlHeader        = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+	xlsxContentTypes = `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbook = `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="{name}" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+)
@@ -0,0 +1,83 @@
+package geoutil
+
+import "math"
+
+// 坐标系说明:
+//   - WGS-84: GPS原始坐标, 国际通用
+//   - GCJ-02: 国测局坐标, 高德、腾讯地图使用
+//   - BD-09: 百度坐标, 在GCJ-02基础上再次加密
+//
+// 中国境外的坐标不做偏移
+
+const (
+	krasovskyA  = 6378245.0
+	krasovskyEE = 0.00669342162296594323
+	bdFactor    = math.Pi * 3000.0 / 180.0
+)
+
+// OutOfChina 粗略判断坐标是否在中国境外
+func OutOfChina(lat, lon float64) bool {
+	return lon < 72.004 || lon > 137.8347 || lat < 0.8293 || lat > 55.8271
+}
+
+func gcjDelta(lat, lon float64) (float64, float64) {
+	x, y := lon-105.0, lat-35.0
+	dLat := -100.0 + 2.0*x + 3.0*y + 0.2*y*y + 0.1*x*y + 0.2*math.Sqrt(math.Abs(x))
+	dLat += (20.0*math.Sin(6.0*x*math.Pi) + 20.0*math.Sin(2.0*x*math.Pi)) * 2.0 / 3.0
+	dLat += (20.0*math.Sin(y*math.Pi) + 40.0*math.Sin(y/3.0*math.Pi)) * 2.0 / 3.0
+	dLat += (160.0*math.Sin(y/12.0*math.Pi) + 320*math.Sin(y*math.Pi/30.0)) * 2.0 / 3.0
+
+	dLon := 300.0 + x + 2.0*y + 0.1*x*x + 0.1*x*y + 0.1*math.Sqrt(math.Abs(x))
+	dLon += (20.0*math.Sin(6.0*x*math.Pi) + 20.0*math.Sin(2.0*x*math.Pi)) * 2.0 / 3.0
+	dLon += (20.0*math.Sin(x*math.Pi) + 40.0*math.Sin(x/3.0*math.Pi)) * 2.0 / 3.0
+	dLon += (150.0*math.Sin(x/12.0*math.Pi) + 300.0*math.Sin(x/30.0*math.Pi)) * 2.0 / 3.0
+
+	radLat := rad(lat)
+	magic := math.Sin(radLat)
+	magic = 1 - krasovskyEE*magic*magic
+	sqrtMagic := math.Sqrt(magic)
+	dLat = (dLat * 180.0) / ((krasovskyA * (1 - krasovskyEE)) / (magic * sqrtMagic) * math.Pi)
+	dLon = (dLon * 180.0) / (krasovskyA / sqrtMagic * math.Cos(radLat) * math.Pi)
+	return dLat, dLon
+}
+
+// WGS84ToGCJ02 GPS坐标转换为国测局坐标
+func WGS84ToGCJ02(lat, lon float64) (float64, float64) {
+	if OutOfChina(lat, lon) {
+		return lat, lon
+	}
+	dLat, dLon := gcjDelta(lat, lon)
+	return lat + dLat, lon + dLon
+}
+
+// GCJ02ToWGS84 国测局坐标转换为GPS坐标, 迭代求解, 误差小于0.01米
+func GCJ02ToWGS84(lat, lon float64) (float64, float64) {
+	if OutOfChina(lat, lon) {
+		return lat, lon
+	}
+	wLat, wLon := lat, lon
+	for i := 0; i < 30; i++ {
+		gLat, gLon := WGS84ToGCJ02(wLat, wLon)
+		dLat, dLon := gLat-lat, gLon-lon
+		if math.Abs(dLat) < 1e-9 && math.Abs(dLon) < 1e-9 {
+			break
+		}
+		wLat, wLon = wLat-dLat, wLon-dLon
+	}
+	return wLat, wLon
+}
+
+// GCJ02ToBD09 国测局坐标转换为百度坐标
+func GCJ02ToBD09(lat, lon float64) (float64, float64) {
+	z := math.Sqrt(lon*lon+lat*lat) + 0.00002*math.Sin(lat*bdFactor)
+	theta := math.Atan2(lat, lon) + 0.000003*math.Cos(lon*bdFactor)
+	return z*math.Sin(theta) + 0.006, z*math.Cos(theta) + 0.0065
+}
+
+// BD09ToGCJ02 百度坐标转换为国测局坐标
+func BD09ToGCJ02(lat, lon float64) (float64, float64) {
+	x, y := lon-0.0065, lat-0.006
+	z := math.Sqrt(x*x+y*y) - 0.00002*math.Sin(y*bdFactor)
+	theta := math.Atan2(y, x) - 0.000003*math.Cos(x*bdFactor)
+	return z * math.Sin(theta), z * math.Cos(theta)
+}
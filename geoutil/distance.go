@@ -0,0 +1,68 @@
+package geoutil
+
+import "math"
+
+// EarthRadius 地球平均半径(米)
+const EarthRadius = 6371008.8
+
+// Point 经纬度坐标(度)
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+func rad(d float64) float64 { return d * math.Pi / 180 }
+
+func deg(r float64) float64 { return r * 180 / math.Pi }
+
+// Distance 使用haversine公式计算两点间的球面距离(米)
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := rad(lat2 - lat1)
+	dLon := rad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Box 经纬度矩形范围
+type Box struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+// Contains 点是否在范围内, 跨越180度经线时MinLon大于MaxLon
+func (b Box) Contains(lat, lon float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.MinLon <= b.MaxLon {
+		return lon >= b.MinLon && lon <= b.MaxLon
+	}
+	return lon >= b.MinLon || lon <= b.MaxLon
+}
+
+// BoundingBox 以(lat, lon)为圆心、radius(米)为半径的圆的外接矩形, 用于数据库范围查询的粗筛,
+// 结果需要再用Distance精确过滤; 包含极点时经度范围为[-180, 180]
+func BoundingBox(lat, lon, radius float64) Box {
+	r := radius / EarthRadius
+	latR, lonR := rad(lat), rad(lon)
+	minLat, maxLat := latR-r, latR+r
+
+	var minLon, maxLon float64
+	if minLat > -math.Pi/2 && maxLat < math.Pi/2 {
+		dLon := math.Asin(math.Sin(r) / math.Cos(latR))
+		minLon, maxLon = lonR-dLon, lonR+dLon
+		if minLon < -math.Pi {
+			minLon += 2 * math.Pi
+		}
+		if maxLon > math.Pi {
+			maxLon -= 2 * math.Pi
+		}
+	} else {
+		minLat, maxLat = math.Max(minLat, -math.Pi/2), math.Min(maxLat, math.Pi/2)
+		minLon, maxLon = -math.Pi, math.Pi
+	}
+	return Box{MinLat: deg(minLat), MinLon: deg(minLon), MaxLat: deg(maxLat), MaxLon: deg(maxLon)}
+}
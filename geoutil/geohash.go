@@ -0,0 +1,149 @@
+package geoutil
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+const base32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// ErrInvalidGeohash geohash包含非法字符
+var ErrInvalidGeohash = errors.New("geoutil: invalid geohash")
+
+// Direction 相邻格子的方向
+type Direction int
+
+const (
+	North Direction = iota
+	NorthEast
+	East
+	SouthEast
+	South
+	SouthWest
+	West
+	NorthWest
+)
+
+// Encode 计算geohash, precision为字符数(1-12)
+func Encode(lat, lon float64, precision int) string {
+	if precision <= 0 {
+		precision = 12
+	}
+	latLo, latHi := -90.0, 90.0
+	lonLo, lonHi := -180.0, 180.0
+
+	var sb strings.Builder
+	sb.Grow(precision)
+	bit, ch := 0, 0
+	even := true // 偶数位编码经度
+	for sb.Len() < precision {
+		if even {
+			mid := (lonLo + lonHi) / 2
+			if lon >= mid {
+				ch = ch<<1 | 1
+				lonLo = mid
+			} else {
+				ch <<= 1
+				lonHi = mid
+			}
+		} else {
+			mid := (latLo + latHi) / 2
+			if lat >= mid {
+				ch = ch<<1 | 1
+				latLo = mid
+			} else {
+				ch <<= 1
+				latHi = mid
+			}
+		}
+		even = !even
+		if bit++; bit == 5 {
+			sb.WriteByte(base32[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return sb.String()
+}
+
+// DecodeBox 返回geohash对应的格子范围
+func DecodeBox(hash string) (Box, error) {
+	latLo, latHi := -90.0, 90.0
+	lonLo, lonHi := -180.0, 180.0
+	even := true
+	for _, c := range strings.ToLower(hash) {
+		idx := strings.IndexRune(base32, c)
+		if idx < 0 {
+			return Box{}, ErrInvalidGeohash
+		}
+		for mask := 16; mask > 0; mask >>= 1 {
+			if even {
+				mid := (lonLo + lonHi) / 2
+				if idx&mask != 0 {
+					lonLo = mid
+				} else {
+					lonHi = mid
+				}
+			} else {
+				mid := (latLo + latHi) / 2
+				if idx&mask != 0 {
+					latLo = mid
+				} else {
+					latHi = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return Box{MinLat: latLo, MinLon: lonLo, MaxLat: latHi, MaxLon: lonHi}, nil
+}
+
+// Decode 返回geohash格子的中心点
+func Decode(hash string) (lat, lon float64, err error) {
+	b, err := DecodeBox(hash)
+	if err != nil {
+		return 0, 0, err
+	}
+	return (b.MinLat + b.MaxLat) / 2, (b.MinLon + b.MaxLon) / 2, nil
+}
+
+// Neighbor 返回指定方向上相邻的格子, 跨越180度经线时自动回绕, 超出极点时返回空字符串
+func Neighbor(hash string, dir Direction) (string, error) {
+	b, err := DecodeBox(hash)
+	if err != nil {
+		return "", err
+	}
+	dLat, dLon := b.MaxLat-b.MinLat, b.MaxLon-b.MinLon
+	lat, lon := (b.MinLat+b.MaxLat)/2, (b.MinLon+b.MaxLon)/2
+
+	switch dir {
+	case North, NorthEast, NorthWest:
+		lat += dLat
+	case South, SouthEast, SouthWest:
+		lat -= dLat
+	}
+	switch dir {
+	case East, NorthEast, SouthEast:
+		lon += dLon
+	case West, NorthWest, SouthWest:
+		lon -= dLon
+	}
+	if lat > 90 || lat < -90 {
+		return "", nil
+	}
+	lon = math.Mod(lon+540, 360) - 180
+	return Encode(lat, lon, len(hash)), nil
+}
+
+// Neighbors 返回周围8个格子, 顺序为North、NorthEast、East、SouthEast、South、SouthWest、West、NorthWest
+func Neighbors(hash string) ([8]string, error) {
+	var out [8]string
+	for d := North; d <= NorthWest; d++ {
+		n, err := Neighbor(hash, d)
+		if err != nil {
+			return out, err
+		}
+		out[d] = n
+	}
+	return out, nil
+}
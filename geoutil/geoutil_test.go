@@ -0,0 +1,97 @@
+package geoutil
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b, eps float64) bool { return math.Abs(a-b) <= eps }
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64 // 米
+		eps                    float64
+	}{
+		{name: "same point", lat1: 39.9, lon1: 116.4, lat2: 39.9, lon2: 116.4, want: 0, eps: 1e-6},
+		{name: "beijing to shanghai", lat1: 39.9042, lon1: 116.4074, lat2: 31.2304, lon2: 121.4737, want: 1067e3, eps: 3e3},
+		{name: "one degree on equator", lat1: 0, lon1: 0, lat2: 0, lon2: 1, want: 111195, eps: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Distance(tt.lat1, tt.lon1, tt.lat2, tt.lon2); !near(got, tt.want, tt.eps) {
+				t.Errorf("Distance() = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	b := BoundingBox(39.9, 116.4, 1000)
+	for _, p := range [][2]float64{{39.9, 116.4}, {39.908, 116.4}, {39.9, 116.4115}} {
+		if !b.Contains(p[0], p[1]) {
+			t.Errorf("box %+v should contain %v", b, p)
+		}
+	}
+	if b.Contains(39.92, 116.4) {
+		t.Errorf("box %+v should not contain point 2km away", b)
+	}
+
+	wrap := BoundingBox(0, 179.999, 1000)
+	if wrap.MinLon < wrap.MaxLon || !wrap.Contains(0, -179.999) {
+		t.Errorf("BoundingBox() across antimeridian = %+v", wrap)
+	}
+}
+
+func TestGeohash(t *testing.T) {
+	tests := []struct {
+		lat, lon  float64
+		precision int
+		want      string
+	}{
+		{lat: 57.64911, lon: 10.40744, precision: 11, want: "u4pruydqqvj"},
+		{lat: 39.92324, lon: 116.3906, precision: 5, want: "wx4g0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := Encode(tt.lat, tt.lon, tt.precision); got != tt.want {
+				t.Errorf("Encode() = %s, want %s", got, tt.want)
+			}
+			lat, lon, err := Decode(tt.want)
+			if err != nil || !near(lat, tt.lat, 0.05) || !near(lon, tt.lon, 0.05) {
+				t.Errorf("Decode() = %f, %f, %v", lat, lon, err)
+			}
+		})
+	}
+
+	ns, err := Neighbors("ezzz")
+	want := [8]string{"gbpb", "u000", "spbp", "spbn", "ezzy", "ezzw", "ezzx", "gbp8"}
+	if err != nil || ns != want {
+		t.Errorf("Neighbors() = %v, %v, want %v", ns, err, want)
+	}
+	if _, _, err := Decode("abc"); err != ErrInvalidGeohash {
+		t.Errorf("Decode() error = %v, want ErrInvalidGeohash", err)
+	}
+}
+
+func TestCoordConvert(t *testing.T) {
+	// 天安门 WGS-84
+	lat, lon := 39.908823, 116.397470
+	gLat, gLon := WGS84ToGCJ02(lat, lon)
+	if d := Distance(lat, lon, gLat, gLon); d < 100 || d > 1000 {
+		t.Errorf("WGS84ToGCJ02() offset = %fm, want several hundred meters", d)
+	}
+	wLat, wLon := GCJ02ToWGS84(gLat, gLon)
+	if d := Distance(lat, lon, wLat, wLon); d > 0.01 {
+		t.Errorf("GCJ02ToWGS84() round trip error = %fm", d)
+	}
+	bLat, bLon := GCJ02ToBD09(gLat, gLon)
+	rLat, rLon := BD09ToGCJ02(bLat, bLon)
+	if d := Distance(gLat, gLon, rLat, rLon); d > 1 {
+		t.Errorf("BD09 round trip error = %fm", d)
+	}
+	if a, b := WGS84ToGCJ02(48.8566, 2.3522); a != 48.8566 || b != 2.3522 {
+		t.Errorf("coordinates outside China should not change")
+	}
+}
@@ -0,0 +1,51 @@
+package md
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ChangSZ/golib/authz"
+	"github.com/ChangSZ/golib/log"
+	"github.com/gin-gonic/gin"
+)
+
+// Authz 权限校验, subject返回空时响应401, 无权限时响应403
+//
+// resource中的{name}会被替换为路由参数, 如:
+//
+//	r.GET("/orders/:id", md.Authz(a, subjectOf, "read", "order:{id}"), handler)
+func Authz(a *authz.Authorizer, subject func(ctx *gin.Context) string, action, resource string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sub := subject(ctx)
+		if sub == "" {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		res := expandParams(ctx, resource)
+		if !a.Evaluate(sub, action, res) {
+			log.Warnw("msg", "permission denied", "subject", sub, "action", action, "resource", res)
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// expandParams 将{name}替换为路由参数
+func expandParams(ctx *gin.Context, s string) string {
+	if !strings.Contains(s, "{") {
+		return s
+	}
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(s, '{')
+		end := strings.IndexByte(s, '}')
+		if start < 0 || end < start {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:start])
+		b.WriteString(ctx.Param(s[start+1 : end]))
+		s = s[end+1:]
+	}
+}
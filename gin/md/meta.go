@@ -0,0 +1,19 @@
+package md
+
+import (
+	"github.com/ChangSZ/golib/meta"
+	"github.com/gin-gonic/gin"
+)
+
+// Meta 从请求头提取请求元数据存入Request.Context, 缺少请求ID时自动生成并写回响应头
+func Meta() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		c := meta.ExtractHeader(ctx.Request.Context(), ctx.Request.Header)
+		if meta.RequestID(c) == "" {
+			c = meta.WithRequestID(c, meta.NewRequestID())
+		}
+		ctx.Request = ctx.Request.WithContext(c)
+		ctx.Header(meta.HeaderRequestID, meta.RequestID(c))
+		ctx.Next()
+	}
+}
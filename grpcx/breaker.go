@@ -0,0 +1,76 @@
+package grpcx
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBreakerOpen 熔断器打开, 请求被直接拒绝
+var ErrBreakerOpen = errors.New("grpcx: circuit breaker is open")
+
+// BreakerConfig 熔断器配置
+type BreakerConfig struct {
+	Failures int           `toml:"failures"` // 连续失败多少次后打开, 默认5
+	Cooldown time.Duration `toml:"cooldown"` // 打开后经过多久允许一个探测请求, 默认10s
+}
+
+// Breaker 按连续失败次数熔断:
+// 连续失败达到Failures次后打开, 拒绝所有请求; 经过Cooldown后放行一个探测请求,
+// 探测成功则关闭, 失败则重新打开
+type Breaker struct {
+	cfg BreakerConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewBreaker new a Breaker.
+func NewBreaker(cfg BreakerConfig) *Breaker {
+	if cfg.Failures <= 0 {
+		cfg.Failures = 5
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 10 * time.Second
+	}
+	return &Breaker{cfg: cfg, now: time.Now}
+}
+
+// Allow 是否放行请求, 放行后必须调用Done报告结果
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.cfg.Failures {
+		return nil
+	}
+	if b.probing || b.now().Sub(b.openedAt) < b.cfg.Cooldown {
+		return ErrBreakerOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// Done 报告请求结果
+func (b *Breaker) Done(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if success {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.cfg.Failures {
+		b.openedAt = b.now()
+	}
+}
+
+// Open 熔断器是否处于打开状态
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= b.cfg.Failures
+}
@@ -0,0 +1,144 @@
+package grpcx
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ChangSZ/golib/meta"
+)
+
+var headerRequestID = strings.ToLower(meta.HeaderRequestID)
+
+// ClientConfig 客户端拦截器配置, 零值字段对应的拦截器不启用
+type ClientConfig struct {
+	Timeout   time.Duration `toml:"timeout"`   // 每次调用(含重试)的超时, ctx已有更早的deadline时以ctx为准
+	Retries   int           `toml:"retries"`   // 失败后的重试次数
+	Backoff   time.Duration `toml:"backoff"`   // 重试间隔, 每次翻倍, 默认100ms
+	RateLimit float64       `toml:"rateLimit"` // 每秒请求数
+	Burst     int           `toml:"burst"`     // 突发请求数, 默认同RateLimit
+	Breaker   BreakerConfig `toml:"breaker"`   // 熔断, Failures为0时不启用
+}
+
+// DialOptions 按顺序组装meta、超时、熔断、限流、重试拦截器:
+//
+//	conn, err := grpc.NewClient(target, append(grpcx.DialOptions(cfg), grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+//
+// 熔断在重试之外, 一次调用的多次重试只计一次结果; 限流在重试之内, 每次重试都消耗令牌
+func DialOptions(cfg ClientConfig) []grpc.DialOption {
+	unary := []grpc.UnaryClientInterceptor{UnaryClientMeta()}
+	if cfg.Timeout > 0 {
+		unary = append(unary, UnaryClientTimeout(cfg.Timeout))
+	}
+	if cfg.Breaker.Failures > 0 {
+		unary = append(unary, UnaryClientBreaker(NewBreaker(cfg.Breaker)))
+	}
+	if cfg.Retries > 0 {
+		unary = append(unary, UnaryClientRetry(cfg.Retries, cfg.Backoff))
+	}
+	if cfg.RateLimit > 0 {
+		burst := cfg.Burst
+		if burst <= 0 {
+			burst = max(int(cfg.RateLimit), 1)
+		}
+		unary = append(unary, UnaryClientRateLimit(rate.NewLimiter(rate.Limit(cfg.RateLimit), burst)))
+	}
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(unary...),
+		grpc.WithChainStreamInterceptor(StreamClientMeta()),
+	}
+}
+
+// injectMeta 将ctx中的Meta写入outgoing metadata
+func injectMeta(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	meta.InjectMD(ctx, md)
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// UnaryClientMeta 将ctx中的请求元数据传递给服务端
+func UnaryClientMeta() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(injectMeta(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientMeta 将ctx中的请求元数据传递给服务端
+func StreamClientMeta() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(injectMeta(ctx), desc, cc, method, opts...)
+	}
+}
+
+// UnaryClientTimeout 为没有设置更早deadline的调用设置超时
+func UnaryClientTimeout(d time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// retryable 默认重试的错误码, 这些错误一般是暂时的且请求未被处理
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+// UnaryClientRetry 对Unavailable、ResourceExhausted、Aborted错误重试, 间隔从backoff开始翻倍, 默认100ms
+//
+// 只应用于幂等的方法; ctx取消或超时后不再重试
+func UnaryClientRetry(retries int, backoff time.Duration) grpc.UnaryClientInterceptor {
+	if backoff <= 0 {
+		backoff = 100 * time.Millisecond
+	}
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		wait := backoff
+		for attempt := 0; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || attempt >= retries || !retryable(err) {
+				return err
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+			wait *= 2
+		}
+	}
+}
+
+// UnaryClientRateLimit 等待令牌后再发起调用, ctx结束前拿不到令牌时返回codes.ResourceExhausted
+func UnaryClientRateLimit(limiter *rate.Limiter) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := limiter.Wait(ctx); err != nil {
+			return status.Error(codes.ResourceExhausted, "grpcx: rate limited: "+err.Error())
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// UnaryClientBreaker 熔断打开时直接返回codes.Unavailable; 只有服务端错误计为失败, 参数错误等业务错误不触发熔断
+func UnaryClientBreaker(b *Breaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := b.Allow(); err != nil {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		b.Done(!serverError(status.Code(err)))
+		return err
+	}
+}
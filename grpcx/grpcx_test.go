@@ -0,0 +1,124 @@
+package grpcx
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ChangSZ/golib/meta"
+)
+
+type metaHealth struct {
+	*health.Server
+	got chan meta.Meta
+}
+
+func (h *metaHealth) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	h.got <- meta.FromContext(ctx)
+	if req.Service == "panic" {
+		panic("boom")
+	}
+	return h.Server.Check(ctx, req)
+}
+
+func TestServerAndClient(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(ServerOptions(ServerConfig{Timeout: time.Second})...)
+	h := &metaHealth{Server: health.NewServer(), got: make(chan meta.Meta, 1)}
+	healthpb.RegisterHealthServer(srv, h)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		append(DialOptions(ClientConfig{Timeout: time.Second, Retries: 1, Breaker: BreakerConfig{Failures: 3}}),
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	ctx := meta.NewContext(context.Background(), meta.Meta{RequestID: "req-1", UserID: "u1"})
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-h.got; got.RequestID != "req-1" || got.UserID != "u1" {
+		t.Errorf("server meta = %+v", got)
+	}
+
+	var header metadata.MD
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "panic"}, grpc.Header(&header))
+	if status.Code(err) != codes.Internal {
+		t.Errorf("panic err = %v, want Internal", err)
+	}
+	if got := <-h.got; got.RequestID == "" || header.Get(headerRequestID)[0] != got.RequestID {
+		t.Errorf("generated request id = %q, header = %v", got.RequestID, header)
+	}
+}
+
+func TestUnaryClientRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantCode  codes.Code
+	}{
+		{name: "success", errs: []error{nil}, wantCalls: 1, wantCode: codes.OK},
+		{name: "retry then success", errs: []error{status.Error(codes.Unavailable, ""), nil}, wantCalls: 2, wantCode: codes.OK},
+		{name: "exhausted", errs: []error{status.Error(codes.Unavailable, ""), status.Error(codes.Unavailable, ""), status.Error(codes.Unavailable, "")}, wantCalls: 3, wantCode: codes.Unavailable},
+		{name: "not retryable", errs: []error{status.Error(codes.InvalidArgument, "")}, wantCalls: 1, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			}
+			err := UnaryClientRetry(2, time.Millisecond)(context.Background(), "/m", nil, nil, nil, invoker)
+			if calls != tt.wantCalls || status.Code(err) != tt.wantCode {
+				t.Errorf("calls = %d, err = %v, want %d, %v", calls, err, tt.wantCalls, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewBreaker(BreakerConfig{Failures: 2, Cooldown: time.Second})
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatal(err)
+		}
+		b.Done(false)
+	}
+	if err := b.Allow(); err != ErrBreakerOpen {
+		t.Fatalf("Allow() = %v, want open", err)
+	}
+
+	now = now.Add(time.Second)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe Allow() = %v", err)
+	}
+	if err := b.Allow(); err != ErrBreakerOpen {
+		t.Errorf("second probe Allow() = %v, want open", err)
+	}
+	b.Done(true)
+	if b.Open() || b.Allow() != nil {
+		t.Error("breaker should be closed after successful probe")
+	}
+}
@@ -0,0 +1,184 @@
+package grpcx
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ChangSZ/golib/log"
+	"github.com/ChangSZ/golib/meta"
+)
+
+// ServerConfig 服务端拦截器配置
+type ServerConfig struct {
+	Timeout  time.Duration `toml:"timeout"`  // unary请求的处理超时, 0表示不限制; 调用方的deadline更早时以调用方为准
+	SkipLogs []string      `toml:"skipLogs"` // 只在出错时记录日志的方法, 如"/grpc.health.v1.Health/Check"
+}
+
+// ServerOptions 按顺序组装meta、日志、recovery、超时拦截器:
+//
+//	srv := grpc.NewServer(grpcx.ServerOptions(grpcx.ServerConfig{Timeout: 5 * time.Second})...)
+func ServerOptions(cfg ServerConfig) []grpc.ServerOption {
+	unary := []grpc.UnaryServerInterceptor{
+		UnaryServerMeta(),
+		UnaryServerLogging(cfg.SkipLogs...),
+		UnaryServerRecovery(),
+	}
+	if cfg.Timeout > 0 {
+		unary = append(unary, UnaryServerTimeout(cfg.Timeout))
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(
+			StreamServerMeta(),
+			StreamServerLogging(cfg.SkipLogs...),
+			StreamServerRecovery(),
+		),
+	}
+}
+
+// serverStream 替换ServerStream的Context
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// extractMeta 从incoming metadata读取Meta, 缺少请求ID时自动生成并通过header返回给调用方
+func extractMeta(ctx context.Context) context.Context {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = meta.ExtractMD(ctx, md)
+	}
+	if meta.RequestID(ctx) == "" {
+		ctx = meta.WithRequestID(ctx, meta.NewRequestID())
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(headerRequestID, meta.RequestID(ctx)))
+	return ctx
+}
+
+// UnaryServerMeta 从metadata提取请求元数据存入ctx, 同httpmw.RequestID
+func UnaryServerMeta() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(extractMeta(ctx), req)
+	}
+}
+
+// StreamServerMeta 从metadata提取请求元数据存入ctx, 同httpmw.RequestID
+func StreamServerMeta() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: extractMeta(ss.Context())})
+	}
+}
+
+// recovered 记录panic堆栈并转换为codes.Internal
+func recovered(ctx context.Context, method string, rec interface{}) error {
+	log.Context(ctx).Errorw("msg", "grpc: panic recovered",
+		"method", method, "panic", rec, "stack", string(debug.Stack()))
+	return status.Error(codes.Internal, "internal error")
+}
+
+// UnaryServerRecovery 捕获panic, 记录堆栈并返回codes.Internal
+func UnaryServerRecovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				resp, err = nil, recovered(ctx, info.FullMethod, rec)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerRecovery 捕获panic, 记录堆栈并返回codes.Internal
+func StreamServerRecovery() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = recovered(ss.Context(), info.FullMethod, rec)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// UnaryServerTimeout 限制处理时间, handler应当监听ctx及时退出
+func UnaryServerTimeout(d time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+		resp, err := handler(ctx, req)
+		if err == nil && ctx.Err() == context.DeadlineExceeded {
+			return nil, status.Error(codes.DeadlineExceeded, "request timeout")
+		}
+		return resp, err
+	}
+}
+
+// accessLog 记录访问日志, 出错时以Error级别记录; skip中的方法只在出错时记录
+func accessLog(ctx context.Context, skip map[string]bool, method string, start time.Time, err error) {
+	code := status.Code(err)
+	if skip[method] && code == codes.OK {
+		return
+	}
+	level := log.LevelInfo
+	if serverError(code) {
+		level = log.LevelError
+	}
+	kvs := []interface{}{
+		"Operation", method,
+		"Code", code.String(),
+		"Latency", time.Since(start).Seconds(),
+	}
+	if err != nil {
+		kvs = append(kvs, "Error", status.Convert(err).Message())
+	}
+	log.Context(ctx).Log(level, kvs...)
+}
+
+// serverError 服务端原因导致的错误, 对应HTTP的5xx
+func serverError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal,
+		codes.Unavailable, codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, s := range items {
+		set[s] = true
+	}
+	return set
+}
+
+// UnaryServerLogging 记录访问日志, 服务端错误以Error级别记录; skipMethods中的方法只在出错时记录
+func UnaryServerLogging(skipMethods ...string) grpc.UnaryServerInterceptor {
+	skip := toSet(skipMethods)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		accessLog(ctx, skip, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerLogging 在流结束时记录访问日志, 规则同UnaryServerLogging
+func StreamServerLogging(skipMethods ...string) grpc.StreamServerInterceptor {
+	skip := toSet(skipMethods)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		accessLog(ss.Context(), skip, info.FullMethod, start, err)
+		return err
+	}
+}
@@ -0,0 +1,146 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Checker 健康检查项, 返回nil表示健康
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc 函数形式的Checker
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Status 单个检查项的结果
+type Status struct {
+	Name    string        `json:"name"`
+	Healthy bool          `json:"healthy"`
+	Error   string        `json:"error,omitempty"`
+	Latency time.Duration `json:"latency"`
+}
+
+// Report 所有检查项的汇总结果
+type Report struct {
+	Healthy bool     `json:"healthy"`
+	Checks  []Status `json:"checks"`
+}
+
+// Registry 健康检查项注册表
+type Registry struct {
+	mu       sync.RWMutex
+	checkers map[string]Checker
+	timeout  time.Duration
+}
+
+// DefaultRegistry 默认注册表
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry new a Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		checkers: make(map[string]Checker),
+		timeout:  5 * time.Second,
+	}
+}
+
+// SetTimeout 设置单次检查的超时时间, 默认5s
+func (r *Registry) SetTimeout(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timeout = d
+}
+
+// Register 注册检查项, 同名覆盖
+func (r *Registry) Register(name string, c Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers[name] = c
+}
+
+// Unregister 移除检查项
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.checkers, name)
+}
+
+// Check 并发执行所有检查项, 结果按名称排序
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checkers := make(map[string]Checker, len(r.checkers))
+	for name, c := range r.checkers {
+		checkers[name] = c
+	}
+	timeout := r.timeout
+	r.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		report = Report{Healthy: true}
+	)
+	for name, c := range checkers {
+		wg.Add(1)
+		go func(name string, c Checker) {
+			defer wg.Done()
+			start := time.Now()
+			err := c.Check(ctx)
+			s := Status{Name: name, Healthy: err == nil, Latency: time.Since(start)}
+			if err != nil {
+				s.Error = err.Error()
+			}
+			mu.Lock()
+			report.Checks = append(report.Checks, s)
+			report.Healthy = report.Healthy && s.Healthy
+			mu.Unlock()
+		}(name, c)
+	}
+	wg.Wait()
+	sort.Slice(report.Checks, func(i, j int) bool { return report.Checks[i].Name < report.Checks[j].Name })
+	return report
+}
+
+// Handler 以JSON输出检查结果, 不健康时状态码为503
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Check(req.Context())
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if !report.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
+
+// Register 向DefaultRegistry注册检查项
+func Register(name string, c Checker) {
+	DefaultRegistry.Register(name, c)
+}
+
+// Unregister 从DefaultRegistry移除检查项
+func Unregister(name string) {
+	DefaultRegistry.Unregister(name)
+}
+
+// Check 执行DefaultRegistry的所有检查项
+func Check(ctx context.Context) Report {
+	return DefaultRegistry.Check(ctx)
+}
+
+// Handler DefaultRegistry的HTTP处理器, 可挂载到/system/health
+func Handler() http.Handler {
+	return DefaultRegistry.Handler()
+}
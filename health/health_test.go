@@ -0,0 +1,57 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	ok := CheckerFunc(func(ctx context.Context) error { return nil })
+	bad := CheckerFunc(func(ctx context.Context) error { return errors.New("db down") })
+
+	tests := []struct {
+		name       string
+		checkers   map[string]Checker
+		wantStatus int
+		wantErrs   map[string]string
+	}{
+		{name: "empty", checkers: nil, wantStatus: http.StatusOK},
+		{name: "healthy", checkers: map[string]Checker{"a": ok, "b": ok}, wantStatus: http.StatusOK},
+		{
+			name:       "one unhealthy",
+			checkers:   map[string]Checker{"cache": ok, "db": bad},
+			wantStatus: http.StatusServiceUnavailable,
+			wantErrs:   map[string]string{"db": "db down"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			for name, c := range tt.checkers {
+				r.Register(name, c)
+			}
+			rec := httptest.NewRecorder()
+			r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/system/health", nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			var report Report
+			if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Checks) != len(tt.checkers) {
+				t.Errorf("Checks = %+v", report.Checks)
+			}
+			for _, s := range report.Checks {
+				if s.Error != tt.wantErrs[s.Name] {
+					t.Errorf("check %s error = %q, want %q", s.Name, s.Error, tt.wantErrs[s.Name])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package heartbeat
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ChangSZ/golib/health"
+	"github.com/ChangSZ/golib/log"
+)
+
+var _ health.Checker = (*Monitor)(nil)
+
+type Config struct {
+	Interval  time.Duration `toml:"interval"`  // 默认心跳间隔, 默认10s
+	MaxMissed int           `toml:"maxMissed"` // 连续错过多少个间隔视为卡住, 默认3
+}
+
+// Worker 后台任务的心跳状态
+type Worker struct {
+	Name     string
+	Interval time.Duration
+	LastBeat time.Time
+	Missed   int  // 已错过的间隔数
+	Stuck    bool // Missed达到MaxMissed
+}
+
+// Monitor 后台任务心跳监控, 长期运行的循环定期调用Beat, 错过心跳的任务会被标记为卡住
+//
+//	m := heartbeat.New(heartbeat.Config{Interval: time.Minute})
+//	health.Register("workers", m)
+//	m.Start(ctx)
+//	for {
+//		m.Beat("consumer")
+//		...
+//	}
+type Monitor struct {
+	cfg      Config
+	mu       sync.Mutex
+	workers  map[string]*Worker
+	handlers []func(Worker)
+	now      func() time.Time
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// New new a Monitor.
+func New(cfg Config) *Monitor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Second
+	}
+	if cfg.MaxMissed <= 0 {
+		cfg.MaxMissed = 3
+	}
+	return &Monitor{
+		cfg:     cfg,
+		workers: make(map[string]*Worker),
+		now:     time.Now,
+	}
+}
+
+// OnStuck 注册回调, 任务从正常变为卡住时调用一次, 回调在检查协程中同步执行, 不要阻塞
+func (m *Monitor) OnStuck(fn func(Worker)) *Monitor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, fn)
+	return m
+}
+
+// Register 以指定的心跳间隔注册任务, 注册时视为刚发送过一次心跳; interval<=0时使用默认间隔
+func (m *Monitor) Register(name string, interval time.Duration) {
+	if interval <= 0 {
+		interval = m.cfg.Interval
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.workers[name] = &Worker{Name: name, Interval: interval, LastBeat: m.now()}
+}
+
+// Unregister 移除任务, 任务正常退出时调用
+func (m *Monitor) Unregister(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.workers, name)
+}
+
+// Beat 发送心跳, 未注册的任务以默认间隔自动注册
+func (m *Monitor) Beat(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.workers[name]
+	if !ok {
+		m.workers[name] = &Worker{Name: name, Interval: m.cfg.Interval, LastBeat: m.now()}
+		return
+	}
+	if w.Stuck {
+		log.Infow("msg", "heartbeat: worker recovered", "worker", name, "missed", w.Missed)
+	}
+	w.LastBeat = m.now()
+	w.Missed = 0
+	w.Stuck = false
+}
+
+// Start 启动检查协程, ctx结束或调用Stop时退出
+func (m *Monitor) Start(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cancel != nil {
+		return
+	}
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.done = make(chan struct{})
+
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(m.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.Scan()
+			}
+		}
+	}()
+}
+
+// Stop 停止检查并等待检查协程退出
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel = nil
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Scan 立即检查一次, 返回本次新变为卡住的任务
+func (m *Monitor) Scan() []Worker {
+	m.mu.Lock()
+	now := m.now()
+	var stuck []Worker
+	for _, w := range m.workers {
+		w.Missed = int(now.Sub(w.LastBeat) / w.Interval)
+		if !w.Stuck && w.Missed >= m.cfg.MaxMissed {
+			w.Stuck = true
+			stuck = append(stuck, *w)
+		}
+	}
+	handlers := m.handlers
+	m.mu.Unlock()
+
+	for _, w := range stuck {
+		log.Warnw(
+			"msg", "heartbeat: worker stuck",
+			"worker", w.Name,
+			"lastBeat", w.LastBeat,
+			"missed", w.Missed,
+		)
+		for _, fn := range handlers {
+			fn(w)
+		}
+	}
+	return stuck
+}
+
+// Workers 所有任务的当前状态, 按名称排序
+func (m *Monitor) Workers() []Worker {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Worker, 0, len(m.workers))
+	for _, w := range m.workers {
+		out = append(out, *w)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Check 实现health.Checker, 存在卡住的任务时返回错误
+func (m *Monitor) Check(ctx context.Context) error {
+	m.Scan()
+	var names []string
+	for _, w := range m.Workers() {
+		if w.Stuck {
+			names = append(names, w.Name)
+		}
+	}
+	if len(names) > 0 {
+		return fmt.Errorf("heartbeat: stuck workers: %s", strings.Join(names, ", "))
+	}
+	return nil
+}
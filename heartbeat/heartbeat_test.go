@@ -0,0 +1,69 @@
+package heartbeat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ChangSZ/golib/health"
+)
+
+func TestScan(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := New(Config{Interval: time.Second, MaxMissed: 3})
+	m.now = func() time.Time { return now }
+
+	var stuck []string
+	m.OnStuck(func(w Worker) { stuck = append(stuck, w.Name) })
+	m.Beat("consumer")
+	m.Register("slow", 10*time.Second)
+
+	tests := []struct {
+		name      string
+		advance   time.Duration
+		beat      string
+		wantStuck []string
+	}{
+		{name: "within interval", advance: 2 * time.Second},
+		{name: "consumer missed 3", advance: time.Second, wantStuck: []string{"consumer"}},
+		{name: "reported once", advance: time.Second},
+		{name: "recovered then slow stuck", advance: 26 * time.Second, beat: "consumer", wantStuck: []string{"slow"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stuck = nil
+			now = now.Add(tt.advance)
+			if tt.beat != "" {
+				m.Beat(tt.beat)
+			}
+			m.Scan()
+			if len(stuck) != len(tt.wantStuck) || (len(stuck) > 0 && stuck[0] != tt.wantStuck[0]) {
+				t.Errorf("stuck = %v, want %v", stuck, tt.wantStuck)
+			}
+		})
+	}
+
+	ws := m.Workers()
+	if len(ws) != 2 || ws[0].Name != "consumer" || ws[0].Stuck || !ws[1].Stuck {
+		t.Errorf("Workers() = %+v", ws)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	m := New(Config{Interval: 10 * time.Millisecond, MaxMissed: 1})
+	reg := health.NewRegistry()
+	reg.Register("workers", m)
+
+	m.Beat("loop")
+	if r := reg.Check(context.Background()); !r.Healthy {
+		t.Errorf("Check() = %+v, want healthy", r)
+	}
+
+	m.Start(context.Background())
+	defer m.Stop()
+	time.Sleep(30 * time.Millisecond)
+	r := reg.Check(context.Background())
+	if r.Healthy || r.Checks[0].Error != "heartbeat: stuck workers: loop" {
+		t.Errorf("Check() = %+v, want loop stuck", r)
+	}
+}
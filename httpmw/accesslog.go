@@ -0,0 +1,47 @@
+package httpmw
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ChangSZ/golib/log"
+)
+
+// AccessLog 记录访问日志, 4xx、5xx以Error级别记录; skipPaths中的路径只在出错时记录
+func AccessLog(skipPaths ...string) Middleware {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, p := range skipPaths {
+		skip[p] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+
+			status := sw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if skip[r.URL.Path] && status < 400 {
+				return
+			}
+			operation := r.URL.Path
+			if r.URL.RawQuery != "" {
+				operation += "?" + r.URL.RawQuery
+			}
+			level := log.LevelInfo
+			if status >= 400 {
+				level = log.LevelError
+			}
+			log.Context(r.Context()).Log(level,
+				"ClientIP", r.RemoteAddr,
+				"Operation", operation,
+				"Method", r.Method,
+				"StatusCode", status,
+				"Bytes", sw.bytes,
+				"Latency", time.Since(start).Seconds(),
+			)
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package httpmw
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ChangSZ/golib/bufpool"
+)
+
+type CompressConfig struct {
+	Level        int      `toml:"level"`        // 压缩级别, 默认gzip.DefaultCompression
+	MinSize      int      `toml:"minSize"`      // 小于该字节数的响应不压缩, 默认1024
+	ContentTypes []string `toml:"contentTypes"` // 可压缩的类型, 以"/"结尾时按前缀匹配, 默认文本、JSON、JS、XML和SVG
+}
+
+var defaultCompressTypes = []string{
+	"text/",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/x-ndjson",
+	"image/svg+xml",
+}
+
+func (c CompressConfig) compressible(contentType string) bool {
+	ct, _, _ := strings.Cut(contentType, ";")
+	ct = strings.ToLower(strings.TrimSpace(ct))
+	for _, t := range c.ContentTypes {
+		if ct == t || (strings.HasSuffix(t, "/") && strings.HasPrefix(ct, t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressor gzip.Writer和flate.Writer的公共接口
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// Compress 按Accept-Encoding对响应进行gzip或deflate压缩
+//
+// 响应在达到MinSize前会先缓冲, 以便决定是否压缩; handler调用Flush时立即开始输出.
+// 已设置Content-Encoding、状态码为204/304或类型不在ContentTypes中的响应原样输出
+func Compress(cfg CompressConfig) Middleware {
+	if cfg.Level == 0 {
+		cfg.Level = gzip.DefaultCompression
+	}
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = 1024
+	}
+	if len(cfg.ContentTypes) == 0 {
+		cfg.ContentTypes = defaultCompressTypes
+	}
+	// 级别无效时在这里就暴露出来
+	if _, err := gzip.NewWriterLevel(io.Discard, cfg.Level); err != nil {
+		panic(err)
+	}
+	pools := map[string]*sync.Pool{
+		"gzip": {New: func() interface{} {
+			w, _ := gzip.NewWriterLevel(io.Discard, cfg.Level)
+			return w
+		}},
+		"deflate": {New: func() interface{} {
+			w, _ := flate.NewWriter(io.Discard, cfg.Level)
+			return w
+		}},
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, cfg: &cfg, encoding: encoding, pool: pools[encoding]}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding 选择客户端接受的编码, 优先gzip, q=0表示拒绝
+func negotiateEncoding(accept string) string {
+	var gzipOK, deflateOK bool
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "gzip", "*":
+			gzipOK = true
+		case "deflate":
+			deflateOK = true
+		}
+	}
+	switch {
+	case gzipOK:
+		return "gzip"
+	case deflateOK:
+		return "deflate"
+	}
+	return ""
+}
+
+type compressWriter struct {
+	http.ResponseWriter
+	cfg      *CompressConfig
+	encoding string
+	pool     *sync.Pool
+
+	status   int
+	buf      *bytes.Buffer // 决定是否压缩前的缓冲
+	decided  bool
+	zw       compressor // 为nil时原样输出
+	hijacked bool
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.status != 0 || w.decided {
+		return
+	}
+	w.status = code
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.hijacked {
+		return 0, http.ErrHijacked
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.decided {
+		if w.buf == nil {
+			w.buf = bufpool.Get(w.cfg.MinSize)
+		}
+		w.buf.Write(b)
+		if w.buf.Len() < w.cfg.MinSize {
+			return len(b), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.zw != nil {
+		return w.zw.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// decide 决定是否压缩并写出响应头和已缓冲的数据, bigEnough表示已满足MinSize
+func (w *compressWriter) decide(bigEnough bool) error {
+	w.decided = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	h := w.Header()
+	var buffered []byte
+	if w.buf != nil {
+		buffered = w.buf.Bytes()
+	}
+	if h.Get("Content-Type") == "" && len(buffered) > 0 {
+		h.Set("Content-Type", http.DetectContentType(buffered))
+	}
+	if bigEnough && w.status != http.StatusNoContent && w.status != http.StatusNotModified &&
+		h.Get("Content-Encoding") == "" && w.cfg.compressible(h.Get("Content-Type")) {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", w.encoding)
+		w.zw = w.pool.Get().(compressor)
+		w.zw.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	var err error
+	if len(buffered) > 0 {
+		if w.zw != nil {
+			_, err = w.zw.Write(buffered)
+		} else {
+			_, err = w.ResponseWriter.Write(buffered)
+		}
+	}
+	if w.buf != nil {
+		bufpool.Put(w.buf)
+		w.buf = nil
+	}
+	return err
+}
+
+// Flush 立即输出已写入的数据, 流式响应即使未达到MinSize也会压缩
+func (w *compressWriter) Flush() {
+	if w.hijacked {
+		return
+	}
+	if !w.decided {
+		_ = w.decide(true)
+	}
+	if w.zw != nil {
+		_ = w.zw.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack 只能在写入任何数据之前调用
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.decided || (w.buf != nil && w.buf.Len() > 0) {
+		return nil, nil, errors.New("httpmw: hijack after response written")
+	}
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap 供http.ResponseController访问底层的能力
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *compressWriter) close() {
+	if w.hijacked {
+		return
+	}
+	if !w.decided {
+		if w.status == 0 && w.buf == nil {
+			// handler没有写任何内容, 交给net/http按默认方式处理
+			return
+		}
+		_ = w.decide(false)
+	}
+	if w.zw != nil {
+		_ = w.zw.Close()
+		w.zw.Reset(io.Discard)
+		w.pool.Put(w.zw)
+		w.zw = nil
+	}
+}
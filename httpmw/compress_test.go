@@ -0,0 +1,108 @@
+package httpmw
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompress(t *testing.T) {
+	big := strings.Repeat("hello world ", 200)
+	h := Compress(CompressConfig{MinSize: 100})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `"`+big+`"`)
+		case "/small":
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = io.WriteString(w, "tiny")
+		case "/png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = io.WriteString(w, big)
+		case "/sniff":
+			_, _ = io.WriteString(w, "<html>"+big)
+		case "/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = io.WriteString(w, "data: 1\n\n")
+			w.(http.Flusher).Flush()
+			_, _ = io.WriteString(w, "data: 2\n\n")
+		}
+	}))
+
+	tests := []struct {
+		path         string
+		accept       string
+		wantEncoding string
+		wantBody     string
+	}{
+		{path: "/json", accept: "gzip, deflate", wantEncoding: "gzip", wantBody: `"` + big + `"`},
+		{path: "/json", accept: "deflate", wantEncoding: "deflate", wantBody: `"` + big + `"`},
+		{path: "/json", accept: "gzip;q=0", wantBody: `"` + big + `"`},
+		{path: "/json", accept: "", wantBody: `"` + big + `"`},
+		{path: "/small", accept: "gzip", wantBody: "tiny"},
+		{path: "/png", accept: "gzip", wantBody: big},
+		{path: "/sniff", accept: "gzip", wantEncoding: "gzip", wantBody: "<html>" + big},
+		{path: "/stream", accept: "gzip", wantEncoding: "gzip", wantBody: "data: 1\n\ndata: 2\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.accept, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept-Encoding", tt.accept)
+			}
+			w := serve(h, r)
+			if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("Vary = %q", w.Header().Get("Vary"))
+			}
+			var body io.Reader = w.Body
+			switch tt.wantEncoding {
+			case "gzip":
+				zr, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = zr
+			case "deflate":
+				body = flate.NewReader(w.Body)
+			}
+			got, err := io.ReadAll(body)
+			if err != nil || string(got) != tt.wantBody {
+				t.Errorf("body = %.40q, err = %v", got, err)
+			}
+		})
+	}
+}
+
+func TestCompressHijack(t *testing.T) {
+	h := Compress(CompressConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("Hijack() error = %v", err)
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 2\r\n\r\nhi")
+		_ = rw.Flush()
+	}))
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	if string(b) != "hi" {
+		t.Errorf("body = %q", b)
+	}
+}
@@ -0,0 +1,91 @@
+package httpmw
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type CORSConfig struct {
+	AllowOrigins     []string `toml:"allowOrigins"`     // 允许的来源, 支持"*"和"*.example.com"形式的子域名通配
+	AllowMethods     []string `toml:"allowMethods"`     // 默认GET、POST、PUT、PATCH、DELETE、HEAD
+	AllowHeaders     []string `toml:"allowHeaders"`     // 为空时回显预检请求中的Access-Control-Request-Headers
+	ExposeHeaders    []string `toml:"exposeHeaders"`    // 允许前端读取的响应头
+	AllowCredentials bool     `toml:"allowCredentials"` // 允许携带cookie, 此时不会返回"*"而是回显来源
+	MaxAge           int      `toml:"maxAge"`           // 预检结果缓存秒数
+}
+
+func (c CORSConfig) allowed(origin string) bool {
+	for _, o := range c.AllowOrigins {
+		switch {
+		case o == "*" || strings.EqualFold(o, origin):
+			return true
+		case strings.HasPrefix(o, "*."):
+			// 匹配scheme之后的host部分
+			host := origin
+			if i := strings.Index(host, "://"); i >= 0 {
+				host = host[i+3:]
+			}
+			if strings.HasSuffix(strings.ToLower(host), strings.ToLower(o[1:])) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CORS 跨域资源共享, 预检请求直接返回204; 来源不被允许时不写CORS头, 由浏览器拦截
+func CORS(cfg CORSConfig) Middleware {
+	if len(cfg.AllowMethods) == 0 {
+		cfg.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"}
+	}
+	methods := strings.Join(cfg.AllowMethods, ", ")
+	headers := strings.Join(cfg.AllowHeaders, ", ")
+	expose := strings.Join(cfg.ExposeHeaders, ", ")
+	wildcard := !cfg.AllowCredentials && len(cfg.AllowOrigins) == 1 && cfg.AllowOrigins[0] == "*"
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if origin == "" || !cfg.allowed(origin) {
+				if preflight {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			if wildcard {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+				h.Add("Vary", "Origin")
+			}
+			if cfg.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+			if !preflight {
+				if expose != "" {
+					h.Set("Access-Control-Expose-Headers", expose)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h.Set("Access-Control-Allow-Methods", methods)
+			if headers != "" {
+				h.Set("Access-Control-Allow-Headers", headers)
+			} else if req := r.Header.Get("Access-Control-Request-Headers"); req != "" {
+				h.Set("Access-Control-Allow-Headers", req)
+				h.Add("Vary", "Access-Control-Request-Headers")
+			}
+			if cfg.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package httpmw
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ChangSZ/golib/meta"
+)
+
+// Middleware net/http中间件
+type Middleware func(http.Handler) http.Handler
+
+// Chain 按顺序包装h, 第一个中间件在最外层
+//
+//	h := httpmw.Chain(mux, httpmw.Recovery(), httpmw.RequestID(), httpmw.AccessLog())
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// ErrorResponse 中间件返回错误时的响应体
+type ErrorResponse struct {
+	Code      int    `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// WriteError 以JSON写入错误响应, code为HTTP状态码
+func WriteError(w http.ResponseWriter, r *http.Request, code int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(ErrorResponse{
+		Code:      code,
+		Message:   message,
+		RequestID: meta.RequestID(r.Context()),
+	})
+}
+
+// statusWriter 记录状态码和写入字节数
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Unwrap 供http.ResponseController访问底层的Flush等能力
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,138 @@
+package httpmw
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChangSZ/golib/meta"
+)
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestRecoveryAndRequestID(t *testing.T) {
+	var gotID string
+	h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = meta.RequestID(r.Context())
+		panic("boom")
+	}), RequestID(), Recovery(), AccessLog())
+
+	w := serve(h, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var body ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if gotID == "" || body.RequestID != gotID || w.Header().Get(meta.HeaderRequestID) != gotID {
+		t.Errorf("request id = %q, body = %+v", gotID, body)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	r.Header.Set(meta.HeaderRequestID, "abc")
+	serve(h, r)
+	if gotID != "abc" {
+		t.Errorf("propagated request id = %q, want abc", gotID)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h := CORS(CORSConfig{
+		AllowOrigins:     []string{"https://app.example.com", "*.example.org"},
+		AllowCredentials: true,
+		ExposeHeaders:    []string{"X-Request-Id"},
+		MaxAge:           600,
+	})(ok)
+
+	tests := []struct {
+		name       string
+		method     string
+		origin     string
+		preflight  bool
+		wantOrigin string
+		wantStatus int
+	}{
+		{name: "exact", method: "GET", origin: "https://app.example.com", wantOrigin: "https://app.example.com", wantStatus: 200},
+		{name: "subdomain", method: "GET", origin: "https://a.example.org", wantOrigin: "https://a.example.org", wantStatus: 200},
+		{name: "denied", method: "GET", origin: "https://evil.com", wantStatus: 200},
+		{name: "preflight", method: "OPTIONS", origin: "https://app.example.com", preflight: true, wantOrigin: "https://app.example.com", wantStatus: 204},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/", nil)
+			r.Header.Set("Origin", tt.origin)
+			if tt.preflight {
+				r.Header.Set("Access-Control-Request-Method", "PUT")
+				r.Header.Set("Access-Control-Request-Headers", "X-Token")
+			}
+			w := serve(h, r)
+			if w.Code != tt.wantStatus || w.Header().Get("Access-Control-Allow-Origin") != tt.wantOrigin {
+				t.Errorf("status = %d, headers = %v", w.Code, w.Header())
+			}
+			if tt.preflight && (w.Header().Get("Access-Control-Allow-Headers") != "X-Token" ||
+				w.Header().Get("Access-Control-Max-Age") != "600") {
+				t.Errorf("preflight headers = %v", w.Header())
+			}
+		})
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	var readErr error
+	h := BodyLimit(4)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+	}))
+
+	if w := serve(h, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("123456"))); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", w.Code)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader("123456")))
+	r.ContentLength = -1
+	serve(h, r)
+	var maxErr *http.MaxBytesError
+	if !errors.As(readErr, &maxErr) {
+		t.Errorf("read error = %v, want MaxBytesError", readErr)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	h := Timeout(50 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d, _ := time.ParseDuration(r.URL.Query().Get("sleep"))
+		select {
+		case <-time.After(d):
+		case <-r.Context().Done():
+		}
+		w.Header().Set("X-Done", "1")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	tests := []struct {
+		sleep      string
+		wantStatus int
+		wantBody   string
+	}{
+		{sleep: "1ms", wantStatus: http.StatusCreated, wantBody: "ok"},
+		{sleep: "1s", wantStatus: http.StatusServiceUnavailable, wantBody: "request timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sleep, func(t *testing.T) {
+			w := serve(h, httptest.NewRequest(http.MethodGet, "/?sleep="+tt.sleep, nil))
+			if w.Code != tt.wantStatus || !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("got %d %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package httpmw
+
+import (
+	"net/http"
+)
+
+// BodyLimit 限制请求体大小, Content-Length超过n时直接返回413, 否则读取超过n时handler会收到*http.MaxBytesError
+func BodyLimit(n int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > n {
+				WriteError(w, r, http.StatusRequestEntityTooLarge, http.StatusText(http.StatusRequestEntityTooLarge))
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
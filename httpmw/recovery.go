@@ -0,0 +1,29 @@
+package httpmw
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/ChangSZ/golib/log"
+)
+
+// Recovery 捕获panic, 记录堆栈并返回500; http.ErrAbortHandler按标准库约定继续向上抛出
+func Recovery() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				log.Context(r.Context()).Errorw("msg", "http: panic recovered",
+					"method", r.Method, "path", r.URL.Path, "panic", rec, "stack", string(debug.Stack()))
+				WriteError(w, r, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package httpmw
+
+import (
+	"net/http"
+
+	"github.com/ChangSZ/golib/meta"
+)
+
+// RequestID 从请求头提取请求元数据存入Request.Context, 缺少请求ID时自动生成并写回响应头
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := meta.ExtractHeader(r.Context(), r.Header)
+			if meta.RequestID(ctx) == "" {
+				ctx = meta.WithRequestID(ctx, meta.NewRequestID())
+			}
+			w.Header().Set(meta.HeaderRequestID, meta.RequestID(ctx))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
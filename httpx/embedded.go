@@ -0,0 +1,154 @@
+package httpx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hashedName 文件名中带有构建哈希, 如app.3f2a9c1b.js、index-BxW2k9aQ.css
+var hashedName = regexp.MustCompile(`[.-][0-9a-zA-Z_]{8,}\.[a-z0-9]+$`)
+
+// EmbeddedOption ServeEmbedded的可选参数
+type EmbeddedOption func(*embeddedServer)
+
+// WithIndex 目录首页和SPA回退使用的文件, 默认index.html
+func WithIndex(name string) EmbeddedOption {
+	return func(s *embeddedServer) {
+		s.index = name
+	}
+}
+
+// WithoutFallback 关闭SPA回退, 不存在的路径返回404
+func WithoutFallback() EmbeddedOption {
+	return func(s *embeddedServer) {
+		s.fallback = false
+	}
+}
+
+// WithHashedPattern 自定义判断文件名带哈希的规则, 匹配的文件使用长期缓存
+func WithHashedPattern(re *regexp.Regexp) EmbeddedOption {
+	return func(s *embeddedServer) {
+		s.hashed = re
+	}
+}
+
+type embeddedServer struct {
+	fsys     fs.FS
+	index    string
+	fallback bool
+	hashed   *regexp.Regexp
+	etags    sync.Map // 路径 -> ETag, 嵌入的文件不会变化
+}
+
+// ServeEmbedded 提供嵌入的静态资源, 用于前端构建产物
+//
+//   - 文件名带哈希的资源缓存一年, 其他资源(如index.html)每次协商缓存
+//   - 根据内容生成ETag, 支持If-None-Match和Range
+//   - 客户端支持gzip且存在同名.gz文件时直接返回预压缩的内容
+//   - 不带扩展名的路径不存在时返回index.html, 由前端路由处理
+//
+// 通常配合fs.Sub去掉构建目录前缀:
+//
+//	//go:embed dist
+//	var dist embed.FS
+//	sub, _ := fs.Sub(dist, "dist")
+//	mux.Handle("/", httpx.ServeEmbedded(sub))
+func ServeEmbedded(fsys fs.FS, opts ...EmbeddedOption) http.Handler {
+	s := &embeddedServer{
+		fsys:     fsys,
+		index:    "index.html",
+		fallback: true,
+		hashed:   hashedName,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *embeddedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" || strings.HasSuffix(r.URL.Path, "/") {
+		name = path.Join(name, s.index)
+	}
+
+	if !s.exists(name) {
+		if !s.fallback || path.Ext(name) != "" {
+			http.NotFound(w, r)
+			return
+		}
+		name = s.index
+	}
+	s.serveFile(w, r, name)
+}
+
+func (s *embeddedServer) exists(name string) bool {
+	info, err := fs.Stat(s.fsys, name)
+	return err == nil && !info.IsDir()
+}
+
+func (s *embeddedServer) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	h := w.Header()
+	h.Add("Vary", "Accept-Encoding")
+	if s.hashed.MatchString(path.Base(name)) {
+		h.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		h.Set("Cache-Control", "no-cache")
+	}
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		h.Set("Content-Type", ct)
+	}
+
+	file := name
+	if acceptsGzip(r) && s.exists(name+".gz") {
+		file = name + ".gz"
+		h.Set("Content-Encoding", "gzip")
+	}
+	data, err := fs.ReadFile(s.fsys, file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	h.Set("ETag", s.etag(file, data))
+	// 嵌入的文件没有修改时间, 只依赖ETag协商
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(data))
+}
+
+func (s *embeddedServer) etag(file string, data []byte) string {
+	if v, ok := s.etags.Load(file); ok {
+		return v.(string)
+	}
+	sum := sha256.Sum256(data)
+	tag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	s.etags.Store(file, tag)
+	return tag
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			return strings.ReplaceAll(params, " ", "") != "q=0"
+		}
+	}
+	return false
+}
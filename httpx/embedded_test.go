@@ -0,0 +1,94 @@
+package httpx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func gz(s string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(s))
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+func TestServeEmbedded(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":                {Data: []byte("<html>app</html>")},
+		"assets/app.3f2a9c1b.js":    {Data: []byte("console.log(1)")},
+		"assets/app.3f2a9c1b.js.gz": {Data: gz("console.log(1)")},
+		"favicon.ico":               {Data: []byte("ico")},
+	}
+	h := ServeEmbedded(fsys)
+
+	tests := []struct {
+		name         string
+		path         string
+		gzip         bool
+		wantStatus   int
+		wantBody     string
+		wantCache    string
+		wantEncoding string
+	}{
+		{name: "root", path: "/", wantStatus: 200, wantBody: "<html>app</html>", wantCache: "no-cache"},
+		{name: "hashed", path: "/assets/app.3f2a9c1b.js", wantStatus: 200, wantBody: "console.log(1)", wantCache: "public, max-age=31536000, immutable"},
+		{name: "precompressed", path: "/assets/app.3f2a9c1b.js", gzip: true, wantStatus: 200, wantCache: "public, max-age=31536000, immutable", wantEncoding: "gzip"},
+		{name: "spa fallback", path: "/orders/42", wantStatus: 200, wantBody: "<html>app</html>", wantCache: "no-cache"},
+		{name: "missing asset", path: "/assets/missing.js", wantStatus: 404},
+		{name: "plain file", path: "/favicon.ico", wantStatus: 200, wantBody: "ico", wantCache: "no-cache"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.gzip {
+				r.Header.Set("Accept-Encoding", "gzip, br")
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != 200 {
+				return
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q", w.Body.String())
+			}
+			if got := w.Header().Get("Cache-Control"); got != tt.wantCache {
+				t.Errorf("Cache-Control = %q", got)
+			}
+			if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q", got)
+			}
+			if tt.path == "/assets/app.3f2a9c1b.js" && w.Header().Get("Content-Type") != "text/javascript; charset=utf-8" {
+				t.Errorf("Content-Type = %q", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+
+	t.Run("etag", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		etag := w.Header().Get("ETag")
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if etag == "" || w.Code != http.StatusNotModified {
+			t.Errorf("ETag = %q, status = %d", etag, w.Code)
+		}
+	})
+
+	t.Run("without fallback", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ServeEmbedded(fsys, WithoutFallback()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders/42", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", w.Code)
+		}
+	})
+}
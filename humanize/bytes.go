@@ -0,0 +1,86 @@
+package humanize
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidSize 无法解析的大小字符串
+var ErrInvalidSize = errors.New("humanize: invalid size")
+
+// 字节单位, 统一按1024进位
+const (
+	Byte uint64 = 1 << (10 * iota)
+	KB
+	MB
+	GB
+	TB
+	PB
+	EB
+)
+
+var byteUnits = []string{"B", "KB", "MB", "GB", "TB", "PB", "EB"}
+
+// unitMap ParseBytes支持的单位, KB与KiB都按1024计算, 与Bytes的输出保持一致
+var unitMap = map[string]uint64{
+	"": Byte, "b": Byte,
+	"k": KB, "kb": KB, "kib": KB,
+	"m": MB, "mb": MB, "mib": MB,
+	"g": GB, "gb": GB, "gib": GB,
+	"t": TB, "tb": TB, "tib": TB,
+	"p": PB, "pb": PB, "pib": PB,
+	"e": EB, "eb": EB, "eib": EB,
+}
+
+// Bytes 将字节数格式化为易读的形式, 保留一位小数, 如1536 -> "1.5 KB"
+func Bytes(n uint64) string {
+	if n < KB {
+		return strconv.FormatUint(n, 10) + " B"
+	}
+	exp := int(math.Log(float64(n)) / math.Log(1024))
+	if exp >= len(byteUnits) {
+		exp = len(byteUnits) - 1
+	}
+	v := float64(n) / math.Pow(1024, float64(exp))
+	// 四舍五入后可能进位到下一个单位, 如1023.96 KB -> 1024.0 KB
+	if math.Round(v*10)/10 >= 1024 && exp < len(byteUnits)-1 {
+		exp++
+		v /= 1024
+	}
+	return trimZero(strconv.FormatFloat(v, 'f', 1, 64)) + " " + byteUnits[exp]
+}
+
+// ParseBytes 解析大小字符串, 如"2GiB", "1.5 KB", "512", 单位不区分大小写
+func ParseBytes(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.' && r != ','
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	num := strings.ReplaceAll(s[:i], ",", "")
+	unit := strings.ToLower(strings.TrimSpace(s[i:]))
+
+	mul, ok := unitMap[unit]
+	if !ok || num == "" {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSize, s)
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSize, s)
+	}
+	v := f * float64(mul)
+	if v >= math.MaxUint64 {
+		return 0, fmt.Errorf("%w: %q overflows", ErrInvalidSize, s)
+	}
+	return uint64(v), nil
+}
+
+func trimZero(s string) string {
+	return strings.TrimSuffix(s, ".0")
+}
@@ -0,0 +1,41 @@
+package humanize
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Day 一天, Duration中使用"d"表示
+const Day = 24 * time.Hour
+
+// Duration 将时长格式化为紧凑的形式, 省略为0的单位
+//
+// 超过1秒时精确到秒, 如"1h3m", "2d4h", "1m30s"; 不足1秒时与time.Duration.String一致, 如"150ms"
+func Duration(d time.Duration) string {
+	if d < 0 {
+		return "-" + Duration(-d)
+	}
+	if d < time.Second {
+		return d.String()
+	}
+
+	d = d.Truncate(time.Second)
+	var b strings.Builder
+	for _, u := range []struct {
+		unit time.Duration
+		name string
+	}{
+		{Day, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+	} {
+		if n := d / u.unit; n > 0 {
+			b.WriteString(strconv.FormatInt(int64(n), 10))
+			b.WriteString(u.name)
+			d -= n * u.unit
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package humanize
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBytes(t *testing.T) {
+	tests := []struct {
+		n    uint64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1 KB"},
+		{1536, "1.5 KB"},
+		{1048575, "1 MB"},
+		{5 * GB, "5 GB"},
+		{1<<64 - 1, "16 EB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := Bytes(tt.n); got != tt.want {
+				t.Errorf("Bytes(%d) = %q, want %q", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    uint64
+		wantErr bool
+	}{
+		{in: "512", want: 512},
+		{in: "2GiB", want: 2 * GB},
+		{in: "1.5 KB", want: 1536},
+		{in: "10m", want: 10 * MB},
+		{in: "1,024 B", want: 1024},
+		{in: "1 XB", wantErr: true},
+		{in: "KB", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseBytes(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSize) {
+					t.Errorf("ParseBytes(%q) error = %v, want ErrInvalidSize", tt.in, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseBytes(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{150 * time.Millisecond, "150ms"},
+		{90 * time.Second, "1m30s"},
+		{time.Hour + 3*time.Minute, "1h3m"},
+		{time.Hour + 3*time.Minute + 400*time.Millisecond, "1h3m"},
+		{50*time.Hour + 5*time.Second, "2d2h5s"},
+		{-2 * time.Minute, "-2m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := Duration(tt.d); got != tt.want {
+				t.Errorf("Duration(%v) = %q, want %q", tt.d, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "comma small", got: Comma(999), want: "999"},
+		{name: "comma", got: Comma(1234567), want: "1,234,567"},
+		{name: "comma negative", got: Comma(-1000), want: "-1,000"},
+		{name: "comma float", got: CommaFloat(-1234567.891, 2), want: "-1,234,567.89"},
+		{name: "ordinal 1", got: Ordinal(1), want: "1st"},
+		{name: "ordinal 12", got: Ordinal(12), want: "12th"},
+		{name: "ordinal 23", got: Ordinal(23), want: "23rd"},
+		{name: "ordinal 111", got: Ordinal(111), want: "111th"},
+		{name: "ordinal 102", got: Ordinal(102), want: "102nd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package humanize
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Comma 添加千分位分隔符, 如1234567 -> "1,234,567"
+func Comma(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	if n < 0 {
+		return "-" + groupDigits(s[1:])
+	}
+	return groupDigits(s)
+}
+
+// CommaFloat 保留prec位小数并添加千分位分隔符, 如1234.5 -> "1,234.50"
+func CommaFloat(f float64, prec int) string {
+	s := strconv.FormatFloat(f, 'f', prec, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, frac, hasFrac := strings.Cut(s, ".")
+	out := sign + groupDigits(intPart)
+	if hasFrac {
+		out += "." + frac
+	}
+	return out
+}
+
+func groupDigits(s string) string {
+	if len(s) <= 3 {
+		return s
+	}
+	var b strings.Builder
+	head := len(s) % 3
+	if head > 0 {
+		b.WriteString(s[:head])
+	}
+	for i := head; i < len(s); i += 3 {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(s[i : i+3])
+	}
+	return b.String()
+}
+
+// Ordinal 英文序数词, 如1 -> "1st", 12 -> "12th", 23 -> "23rd"
+func Ordinal(n int) string {
+	suffix := "th"
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	switch abs % 100 {
+	case 11, 12, 13:
+	default:
+		switch abs % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
@@ -0,0 +1,252 @@
+package i18n
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// message 一条消息, 没有复数形式时只有other
+type message map[Plural]*template.Template
+
+// Bundle 多语言消息集合, 可以并发使用
+//
+// 消息文件按语言区域命名, 如zh-CN.yaml、en.json, 嵌套的key以"."连接; 值中可以使用text/template语法引用变量,
+// 只包含zero/one/two/few/many/other键的对象视为复数消息:
+//
+//	hello: "你好, {{.Name}}"
+//	cart:
+//	  items:
+//	    one: "{{.Count}} item"
+//	    other: "{{.Count}} items"
+type Bundle struct {
+	defaultLocale string
+	mu            sync.RWMutex
+	messages      map[string]map[string]message
+}
+
+// NewBundle new a Bundle, defaultLocale为找不到对应语言时的兜底语言
+func NewBundle(defaultLocale string) *Bundle {
+	return &Bundle{
+		defaultLocale: defaultLocale,
+		messages:      make(map[string]map[string]message),
+	}
+}
+
+// DefaultLocale 兜底语言
+func (b *Bundle) DefaultLocale() string {
+	return b.defaultLocale
+}
+
+// Locales 已加载的语言区域
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	out := make([]string, 0, len(b.messages))
+	for l := range b.messages {
+		out = append(out, l)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// LoadDir 加载目录下所有.yaml、.yml和.json文件
+func (b *Bundle) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".yaml", ".yml", ".json":
+			if err := b.LoadFile(filepath.Join(dir, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// LoadFile 加载消息文件, 文件名(不含扩展名)即语言区域
+func (b *Bundle) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	locale := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	var raw map[string]interface{}
+	if ext == ".json" {
+		err = json.Unmarshal(data, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return fmt.Errorf("i18n: parse %s: %w", path, err)
+	}
+	return b.AddMessages(locale, raw)
+}
+
+// AddMessages 添加locale的消息, 同名key覆盖
+func (b *Bundle) AddMessages(locale string, raw map[string]interface{}) error {
+	parsed := make(map[string]message)
+	if err := flatten("", raw, parsed); err != nil {
+		return fmt.Errorf("i18n: locale %s: %w", locale, err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := normalize(locale)
+	if b.messages[key] == nil {
+		b.messages[key] = make(map[string]message)
+	}
+	for id, msg := range parsed {
+		b.messages[key][id] = msg
+	}
+	return nil
+}
+
+func flatten(prefix string, raw map[string]interface{}, out map[string]message) error {
+	for k, v := range raw {
+		id := k
+		if prefix != "" {
+			id = prefix + "." + k
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			if !isPlural(val) {
+				if err := flatten(id, val, out); err != nil {
+					return err
+				}
+				continue
+			}
+			msg := make(message, len(val))
+			for form, text := range val {
+				tpl, err := parse(id, fmt.Sprint(text))
+				if err != nil {
+					return err
+				}
+				msg[Plural(form)] = tpl
+			}
+			out[id] = msg
+		default:
+			tpl, err := parse(id, fmt.Sprint(val))
+			if err != nil {
+				return err
+			}
+			out[id] = message{PluralOther: tpl}
+		}
+	}
+	return nil
+}
+
+func isPlural(m map[string]interface{}) bool {
+	if _, ok := m[string(PluralOther)]; !ok {
+		return false
+	}
+	for k := range m {
+		if !pluralNames[k] {
+			return false
+		}
+	}
+	return true
+}
+
+func parse(id, text string) (*template.Template, error) {
+	tpl, err := template.New(id).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("message %s: %w", id, err)
+	}
+	return tpl, nil
+}
+
+// lookup 依次查找locale、其基础语言、兜底语言
+func (b *Bundle) lookup(locale, id string) (message, string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, l := range []string{normalize(locale), baseLanguage(locale), normalize(b.defaultLocale)} {
+		if msg, ok := b.messages[l][id]; ok {
+			return msg, l, true
+		}
+	}
+	return nil, "", false
+}
+
+// Localize 翻译id, 找不到消息时返回id本身; data为模板变量
+func (b *Bundle) Localize(locale, id string, data interface{}) string {
+	return b.render(locale, id, -1, data)
+}
+
+// LocalizePlural 按数量n选择复数形式后翻译, data为map时自动补充Count变量
+func (b *Bundle) LocalizePlural(locale, id string, n int64, data interface{}) string {
+	return b.render(locale, id, n, data)
+}
+
+// T 使用ctx中的语言区域翻译, 参见Localize
+func (b *Bundle) T(ctx context.Context, id string, data interface{}) string {
+	return b.Localize(Locale(ctx), id, data)
+}
+
+// TN 使用ctx中的语言区域按数量翻译, 参见LocalizePlural
+func (b *Bundle) TN(ctx context.Context, id string, n int64, data interface{}) string {
+	return b.LocalizePlural(Locale(ctx), id, n, data)
+}
+
+func (b *Bundle) render(locale, id string, n int64, data interface{}) string {
+	msg, found, ok := b.lookup(locale, id)
+	if !ok {
+		return id
+	}
+	tpl := msg[PluralOther]
+	if n >= 0 {
+		if m, ok := data.(map[string]interface{}); ok {
+			if _, exists := m["Count"]; !exists {
+				withCount := make(map[string]interface{}, len(m)+1)
+				for k, v := range m {
+					withCount[k] = v
+				}
+				withCount["Count"] = n
+				data = withCount
+			}
+		} else if data == nil {
+			data = map[string]interface{}{"Count": n}
+		}
+		if t, ok := msg[PluralOf(found, n)]; ok {
+			tpl = t
+		}
+	}
+	if tpl == nil {
+		return id
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return id
+	}
+	return buf.String()
+}
+
+// DefaultBundle 默认的消息集合
+var DefaultBundle = NewBundle("zh-CN")
+
+// T 使用DefaultBundle翻译
+func T(ctx context.Context, id string, data interface{}) string {
+	return DefaultBundle.T(ctx, id, data)
+}
+
+// TN 使用DefaultBundle按数量翻译
+func TN(ctx context.Context, id string, n int64, data interface{}) string {
+	return DefaultBundle.TN(ctx, id, n, data)
+}
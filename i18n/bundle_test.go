@@ -0,0 +1,89 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ChangSZ/golib/testingx"
+)
+
+func newTestBundle(t *testing.T) *Bundle {
+	dir := testingx.TempDirWithFiles(t, map[string]string{
+		"zh-CN.yaml": "hello: \"你好, {{.Name}}\"\ncart:\n  items:\n    other: \"{{.Count}}件商品\"\n",
+		"en.json":    `{"hello":"Hello, {{.Name}}","cart":{"items":{"one":"{{.Count}} item","other":"{{.Count}} items"}},"only_en":"fallback"}`,
+		"ru.yaml":    "apples:\n  one: \"{{.Count}} яблоко\"\n  few: \"{{.Count}} яблока\"\n  many: \"{{.Count}} яблок\"\n  other: \"{{.Count}} яблока\"\n",
+		"README.md":  "ignored",
+	})
+	b := NewBundle("en")
+	if err := b.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	return b
+}
+
+func TestLocalize(t *testing.T) {
+	b := newTestBundle(t)
+	tests := []struct {
+		name   string
+		locale string
+		id     string
+		n      int64
+		want   string
+	}{
+		{name: "zh", locale: "zh-CN", id: "hello", n: -1, want: "你好, jack"},
+		{name: "underscore locale", locale: "zh_cn", id: "hello", n: -1, want: "你好, jack"},
+		{name: "region falls back to base", locale: "en-GB", id: "hello", n: -1, want: "Hello, jack"},
+		{name: "unknown falls back to default", locale: "fr", id: "only_en", n: -1, want: "fallback"},
+		{name: "missing id", locale: "en", id: "nope", n: -1, want: "nope"},
+		{name: "en one", locale: "en", id: "cart.items", n: 1, want: "1 item"},
+		{name: "en other", locale: "en", id: "cart.items", n: 3, want: "3 items"},
+		{name: "zh no plural", locale: "zh-CN", id: "cart.items", n: 1, want: "1件商品"},
+		{name: "ru few", locale: "ru", id: "apples", n: 22, want: "22 яблока"},
+		{name: "ru many", locale: "ru", id: "apples", n: 11, want: "11 яблок"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := map[string]interface{}{"Name": "jack"}
+			var got string
+			if tt.n < 0 {
+				got = b.Localize(tt.locale, tt.id, data)
+			} else {
+				got = b.LocalizePlural(tt.locale, tt.id, tt.n, data)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextLocale(t *testing.T) {
+	b := newTestBundle(t)
+	ctx := WithLocale(context.Background(), "zh-CN")
+	if got := b.T(ctx, "hello", map[string]string{"Name": "rose"}); got != "你好, rose" {
+		t.Errorf("T() = %q", got)
+	}
+	if got := b.TN(context.Background(), "cart.items", 2, nil); got != "2 items" {
+		t.Errorf("TN() = %q", got)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{accept: "zh-CN,zh;q=0.9,en;q=0.8", want: "zh-CN"},
+		{accept: "en-US,en;q=0.9", want: "en"},
+		{accept: "zh-TW", want: "zh-CN"},
+		{accept: "fr-FR", want: "en"},
+		{accept: "", want: "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			if got := Negotiate(tt.accept, "en", "zh-CN"); got != tt.want {
+				t.Errorf("Negotiate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
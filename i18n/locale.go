@@ -0,0 +1,54 @@
+package i18n
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/text/language"
+
+	"github.com/ChangSZ/golib/meta"
+)
+
+// WithLocale 设置当前请求的语言区域, 与meta包共用同一个载体
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return meta.WithLocale(ctx, locale)
+}
+
+// Locale 当前请求的语言区域, 未设置时返回空字符串
+func Locale(ctx context.Context) string {
+	return meta.Locale(ctx)
+}
+
+// Negotiate 根据Accept-Language从supported中选出最匹配的语言, 无法匹配时返回supported[0]
+func Negotiate(acceptLanguage string, supported ...string) string {
+	if len(supported) == 0 {
+		return ""
+	}
+	tags := make([]language.Tag, 0, len(supported))
+	for _, s := range supported {
+		tags = append(tags, language.Make(s))
+	}
+	desired, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(desired) == 0 {
+		return supported[0]
+	}
+	_, idx, conf := language.NewMatcher(tags).Match(desired...)
+	if conf == language.No {
+		return supported[0]
+	}
+	return supported[idx]
+}
+
+// baseLanguage 返回小写的基础语言代码, 如"zh-CN"返回"zh"
+func baseLanguage(locale string) string {
+	locale = strings.ToLower(locale)
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		return locale[:i]
+	}
+	return locale
+}
+
+// normalize 统一语言区域的写法, "zh_cn"与"zh-CN"视为相同
+func normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
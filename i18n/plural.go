@@ -0,0 +1,109 @@
+package i18n
+
+import "strings"
+
+// Plural 复数类别, 与CLDR的类别名一致
+type Plural string
+
+const (
+	PluralZero  Plural = "zero"
+	PluralOne   Plural = "one"
+	PluralTwo   Plural = "two"
+	PluralFew   Plural = "few"
+	PluralMany  Plural = "many"
+	PluralOther Plural = "other"
+)
+
+var pluralNames = map[string]bool{
+	string(PluralZero): true, string(PluralOne): true, string(PluralTwo): true,
+	string(PluralFew): true, string(PluralMany): true, string(PluralOther): true,
+}
+
+// PluralRule 根据数量返回复数类别
+type PluralRule func(n int64) Plural
+
+var pluralRules = map[string]PluralRule{
+	// 中日韩等没有复数变化
+	"zh": ruleOther, "ja": ruleOther, "ko": ruleOther, "vi": ruleOther, "th": ruleOther, "id": ruleOther,
+	// 法语、葡萄牙语0和1都是单数
+	"fr": ruleZeroOne, "pt": ruleZeroOne,
+	// 斯拉夫语系
+	"ru": ruleSlavic, "uk": ruleSlavic, "be": ruleSlavic,
+	"pl": rulePolish,
+	"ar": ruleArabic,
+}
+
+// RegisterPluralRule 注册或覆盖某种语言的复数规则, lang为基础语言代码, 如"en"
+func RegisterPluralRule(lang string, rule PluralRule) {
+	pluralRules[strings.ToLower(lang)] = rule
+}
+
+// PluralOf 返回locale下数量n的复数类别, 未知语言按英语规则处理
+func PluralOf(locale string, n int64) Plural {
+	if rule, ok := pluralRules[baseLanguage(locale)]; ok {
+		return rule(n)
+	}
+	return ruleOneOther(n)
+}
+
+func ruleOther(int64) Plural { return PluralOther }
+
+func ruleOneOther(n int64) Plural {
+	if n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func ruleZeroOne(n int64) Plural {
+	if n == 0 || n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func ruleSlavic(n int64) Plural {
+	n = abs(n)
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return PluralOne
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return PluralFew
+	}
+	return PluralMany
+}
+
+func rulePolish(n int64) Plural {
+	n = abs(n)
+	switch {
+	case n == 1:
+		return PluralOne
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return PluralFew
+	}
+	return PluralMany
+}
+
+func ruleArabic(n int64) Plural {
+	n = abs(n)
+	switch {
+	case n == 0:
+		return PluralZero
+	case n == 1:
+		return PluralOne
+	case n == 2:
+		return PluralTwo
+	case n%100 >= 3 && n%100 <= 10:
+		return PluralFew
+	case n%100 >= 11:
+		return PluralMany
+	}
+	return PluralOther
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,121 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInProgress 相同key的请求正在处理中, 调用方应稍后重试
+var ErrInProgress = errors.New("idempotency: request in progress")
+
+// DefaultLockTTL 处理中标记的默认有效期, 进程崩溃后超过该时间可以重新执行
+const DefaultLockTTL = 30 * time.Second
+
+// Option Execute的可选参数
+type Option func(*options)
+
+type options struct {
+	lockTTL  time.Duration
+	replayed *bool
+}
+
+// WithLockTTL 处理中标记的有效期, 应大于fn的最长执行时间
+func WithLockTTL(d time.Duration) Option {
+	return func(o *options) {
+		o.lockTTL = d
+	}
+}
+
+// WithReplayed 通过replayed返回本次结果是否来自之前的执行, 用于设置响应头等
+func WithReplayed(replayed *bool) Option {
+	return func(o *options) {
+		o.replayed = replayed
+	}
+}
+
+// Execute 以key保证fn的幂等: ttl内相同key只执行一次成功的fn, 之后直接返回保存的结果
+//
+// 结果以JSON保存, T需要能被encoding/json完整地序列化; fn返回错误时不保存结果, 相同key可以重新执行;
+// 相同key正在处理时返回ErrInProgress
+//
+//	resp, err := idempotency.Execute(ctx, store, "pay:"+req.RequestID, 24*time.Hour,
+//		func(ctx context.Context) (*PayResp, error) { return pay(ctx, req) })
+func Execute[T any](ctx context.Context, store Store, key string, ttl time.Duration,
+	fn func(ctx context.Context) (T, error), opts ...Option) (T, error) {
+	o := options{lockTTL: DefaultLockTTL}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var zero T
+	setReplayed := func(v bool) {
+		if o.replayed != nil {
+			*o.replayed = v
+		}
+	}
+	setReplayed(false)
+
+	if result, ok, err := load[T](ctx, store, key); err != nil || ok {
+		setReplayed(ok)
+		return result, err
+	}
+
+	token := newToken()
+	locked, err := store.Lock(ctx, key, token, o.lockTTL)
+	if err != nil {
+		return zero, fmt.Errorf("idempotency: lock %s: %w", key, err)
+	}
+	if !locked {
+		return zero, fmt.Errorf("%w: %s", ErrInProgress, key)
+	}
+
+	// 加锁前可能有其他请求刚好完成
+	if result, ok, err := load[T](ctx, store, key); err != nil || ok {
+		_ = store.Unlock(ctx, key, token)
+		setReplayed(ok)
+		return result, err
+	}
+
+	result, err := fn(ctx)
+	if err != nil {
+		// 调用方的ctx可能已经取消, 解锁不应受影响
+		_ = store.Unlock(context.WithoutCancel(ctx), key, token)
+		return result, err
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		_ = store.Unlock(context.WithoutCancel(ctx), key, token)
+		return result, fmt.Errorf("idempotency: marshal result: %w", err)
+	}
+	if err := store.Save(context.WithoutCancel(ctx), key, token, b, ttl); err != nil {
+		return result, fmt.Errorf("idempotency: save %s: %w", key, err)
+	}
+	return result, nil
+}
+
+func load[T any](ctx context.Context, store Store, key string) (T, bool, error) {
+	var result T
+	b, ok, err := store.Get(ctx, key)
+	if err != nil {
+		return result, false, fmt.Errorf("idempotency: get %s: %w", key, err)
+	}
+	if !ok {
+		return result, false, nil
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return result, false, fmt.Errorf("idempotency: unmarshal %s: %w", key, err)
+	}
+	return result, true, nil
+}
+
+// newToken 本次执行持有处理中标记的token, 标记过期后被其他请求占有时, 本次执行不会误删对方的标记
+func newToken() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,121 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type payResp struct {
+	OrderID string
+	Amount  int64
+}
+
+func TestExecute(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	var calls int32
+	pay := func(ctx context.Context) (*payResp, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return &payResp{OrderID: "o1", Amount: int64(n) * 100}, nil
+	}
+
+	var replayed bool
+	first, err := Execute(ctx, store, "pay:1", time.Hour, pay, WithReplayed(&replayed))
+	if err != nil || replayed {
+		t.Fatalf("Execute() = %v, %v, replayed %v", first, err, replayed)
+	}
+	second, err := Execute(ctx, store, "pay:1", time.Hour, pay, WithReplayed(&replayed))
+	if err != nil || !replayed || *second != *first || calls != 1 {
+		t.Errorf("duplicate Execute() = %+v, %v, replayed %v, calls %d", second, err, replayed, calls)
+	}
+
+	// 过期后重新执行
+	store.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	third, _ := Execute(ctx, store, "pay:1", time.Hour, pay)
+	if third.Amount != 200 {
+		t.Errorf("Execute() after ttl = %+v, want re-executed", third)
+	}
+}
+
+func TestExecuteError(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	fail := errors.New("gateway timeout")
+	var calls int
+	fn := func(ctx context.Context) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, fail
+		}
+		return 42, nil
+	}
+
+	if _, err := Execute(ctx, store, "k", time.Hour, fn); !errors.Is(err, fail) {
+		t.Fatalf("Execute() error = %v, want %v", err, fail)
+	}
+	if v, err := Execute(ctx, store, "k", time.Hour, fn); err != nil || v != 42 {
+		t.Errorf("retry Execute() = %d, %v, want 42", v, err)
+	}
+}
+
+func TestExecuteInProgress(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	start, release := make(chan struct{}), make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		Execute(ctx, store, "webhook:1", time.Hour, func(ctx context.Context) (string, error) {
+			close(start)
+			<-release
+			return "done", nil
+		})
+	}()
+	<-start
+	if _, err := Execute(ctx, store, "webhook:1", time.Hour, func(ctx context.Context) (string, error) {
+		return "dup", nil
+	}); !errors.Is(err, ErrInProgress) {
+		t.Errorf("concurrent Execute() error = %v, want ErrInProgress", err)
+	}
+	close(release)
+	wg.Wait()
+
+	if v, err := Execute(ctx, store, "webhook:1", time.Hour, func(ctx context.Context) (string, error) {
+		return "dup", nil
+	}); err != nil || v != "done" {
+		t.Errorf("Execute() after completion = %q, %v", v, err)
+	}
+}
+
+func TestMemoryStoreLockToken(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	if ok, _ := store.Lock(ctx, "k", "a", time.Second); !ok {
+		t.Fatal("Lock(a) = false, want true")
+	}
+	if ok, _ := store.Lock(ctx, "k", "b", time.Second); ok {
+		t.Fatal("Lock(b) while held = true, want false")
+	}
+
+	// a的标记过期后被b占有, a的Unlock和Save不能解除b的标记
+	store.now = func() time.Time { return time.Now().Add(2 * time.Second) }
+	if ok, _ := store.Lock(ctx, "k", "b", time.Minute); !ok {
+		t.Fatal("Lock(b) after expiry = false, want true")
+	}
+	store.Unlock(ctx, "k", "a")
+	store.Save(ctx, "k", "a", []byte(`1`), time.Hour)
+	if ok, _ := store.Lock(ctx, "k", "c", time.Minute); ok {
+		t.Error("Lock(c) after stale holder released = true, want false")
+	}
+
+	store.Unlock(ctx, "k", "b")
+	if ok, _ := store.Lock(ctx, "k", "c", time.Minute); !ok {
+		t.Error("Lock(c) after holder released = false, want true")
+	}
+}
@@ -0,0 +1,143 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Store 幂等结果存储
+type Store interface {
+	// Get 读取已完成的结果
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Lock 以token标记key正在处理, 已被标记时返回false
+	Lock(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	// Save 保存结果, 标记仍由token持有时解除标记
+	Save(ctx context.Context, key, token string, value []byte, ttl time.Duration) error
+	// Unlock 标记仍由token持有时解除, 处理失败时调用, 之后同一key可以重新执行;
+	// 标记已过期并被其他请求占有时不做处理
+	Unlock(ctx context.Context, key, token string) error
+}
+
+var (
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*RedisStore)(nil)
+)
+
+// MemoryStore 进程内存储, 用于测试和单机部署
+type MemoryStore struct {
+	mu      sync.Mutex
+	results map[string]memoryEntry
+	locks   map[string]memoryLock
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	value    []byte
+	expireAt time.Time
+}
+
+type memoryLock struct {
+	token    string
+	expireAt time.Time
+}
+
+// NewMemoryStore new a MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		results: make(map[string]memoryEntry),
+		locks:   make(map[string]memoryLock),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.results[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !s.now().Before(e.expireAt) {
+		delete(s.results, key)
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+func (s *MemoryStore) Lock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if l, ok := s.locks[key]; ok && s.now().Before(l.expireAt) {
+		return false, nil
+	}
+	s.locks[key] = memoryLock{token: token, expireAt: s.now().Add(ttl)}
+	return true, nil
+}
+
+func (s *MemoryStore) Save(ctx context.Context, key, token string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[key] = memoryEntry{value: value, expireAt: s.now().Add(ttl)}
+	s.unlock(key, token)
+	return nil
+}
+
+func (s *MemoryStore) Unlock(ctx context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unlock(key, token)
+	return nil
+}
+
+func (s *MemoryStore) unlock(key, token string) {
+	if l, ok := s.locks[key]; ok && l.token == token {
+		delete(s.locks, key)
+	}
+}
+
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// RedisStore 基于Redis的存储, 结果保存在key, 处理中标记保存在key+":lock", 值为持有者的token
+type RedisStore struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewRedisStore new a RedisStore, prefix会加在所有key之前, 如"idem:"
+func NewRedisStore(client redis.Cmdable, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return b, true, nil
+}
+
+func (s *RedisStore) Lock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, s.prefix+key+":lock", token, ttl).Result()
+}
+
+// Save 结果和标记可能在Cluster的不同slot, 先保存结果再校验token解除标记
+func (s *RedisStore) Save(ctx context.Context, key, token string, value []byte, ttl time.Duration) error {
+	if err := s.client.Set(ctx, s.prefix+key, value, ttl).Err(); err != nil {
+		return err
+	}
+	return s.Unlock(ctx, key, token)
+}
+
+func (s *RedisStore) Unlock(ctx context.Context, key, token string) error {
+	return unlockScript.Run(ctx, s.client, []string{s.prefix + key + ":lock"}, token).Err()
+}
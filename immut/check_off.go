@@ -0,0 +1,6 @@
+//go:build !immutcheck
+// +build !immutcheck
+
+package immut
+
+const checkOnGet = false
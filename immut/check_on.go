@@ -0,0 +1,7 @@
+//go:build immutcheck
+// +build immutcheck
+
+package immut
+
+// checkOnGet 开启immutcheck构建标签时, Get会检查是否被修改
+const checkOnGet = true
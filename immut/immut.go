@@ -0,0 +1,163 @@
+package immut
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/ChangSZ/golib/copy"
+)
+
+// MutationError 被冻结的值发生了修改
+type MutationError struct {
+	Path string // 第一处不同的位置, 如"Servers[1].Addr"
+	Old  interface{}
+	New  interface{}
+}
+
+func (e *MutationError) Error() string {
+	return fmt.Sprintf("immut: frozen value mutated at %s: %v => %v", e.Path, e.Old, e.New)
+}
+
+// Frozen 共享的只读快照, 用于检测对共享配置等结构的意外修改
+//
+// Freeze时保存一份深拷贝, Check将当前值与之比较; 使用immutcheck构建标签编译时, 每次Get都会检查, 发现修改直接panic:
+//
+//	go test -tags immutcheck ./...
+//
+// 与copy.DeepCopy一致, 只比较可导出字段
+type Frozen[T any] struct {
+	value    T
+	snapshot T
+	mu       sync.Mutex
+	err      error
+}
+
+// Freeze 冻结v, v中的指针、切片、map仍与调用方共享, 调用方之后不应再修改它们
+func Freeze[T any](v T) *Frozen[T] {
+	return &Frozen[T]{value: v, snapshot: copy.DeepCopy(v).(T)}
+}
+
+// Get 返回共享的值, 调用方只能读取
+func (f *Frozen[T]) Get() T {
+	if checkOnGet {
+		if err := f.Check(); err != nil {
+			panic(err)
+		}
+	}
+	return f.value
+}
+
+// Check 检查冻结后是否被修改, 返回*MutationError; 发现修改后结果不再变化, 可以并发调用
+func (f *Frozen[T]) Check() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err == nil {
+		f.err = diff(reflect.ValueOf(&f.snapshot).Elem(), reflect.ValueOf(&f.value).Elem(), "")
+	}
+	return f.err
+}
+
+// Check 检查a与b的可导出内容是否一致, 不一致时返回第一处不同的*MutationError
+func Check(a, b interface{}) error {
+	return diff(reflect.ValueOf(a), reflect.ValueOf(b), "")
+}
+
+func diff(a, b reflect.Value, path string) error {
+	mismatch := func() error {
+		return &MutationError{Path: pathOrRoot(path), Old: describe(a), New: describe(b)}
+	}
+	if a.IsValid() != b.IsValid() {
+		return mismatch()
+	}
+	if !a.IsValid() {
+		return nil
+	}
+	if a.Type() != b.Type() {
+		return mismatch()
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				return mismatch()
+			}
+			return nil
+		}
+		return diff(a.Elem(), b.Elem(), path)
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			field := a.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if err := diff(a.Field(i), b.Field(i), joinField(path, field.Name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		if a.Kind() == reflect.Slice && a.IsNil() != b.IsNil() {
+			return mismatch()
+		}
+		if a.Len() != b.Len() {
+			return &MutationError{Path: pathOrRoot(path) + ".len", Old: a.Len(), New: b.Len()}
+		}
+		for i := 0; i < a.Len(); i++ {
+			if err := diff(a.Index(i), b.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if a.IsNil() != b.IsNil() {
+			return mismatch()
+		}
+		if a.Len() != b.Len() {
+			return &MutationError{Path: pathOrRoot(path) + ".len", Old: a.Len(), New: b.Len()}
+		}
+		for _, k := range a.MapKeys() {
+			elemPath := fmt.Sprintf("%s[%v]", path, k.Interface())
+			bv := b.MapIndex(k)
+			if !bv.IsValid() {
+				return &MutationError{Path: elemPath, Old: describe(a.MapIndex(k)), New: nil}
+			}
+			if err := diff(a.MapIndex(k), bv, elemPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if a.Pointer() != b.Pointer() {
+			return mismatch()
+		}
+		return nil
+	}
+
+	if !a.CanInterface() || !b.CanInterface() || a.Interface() != b.Interface() {
+		return mismatch()
+	}
+	return nil
+}
+
+func describe(v reflect.Value) interface{} {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+func joinField(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "<root>"
+	}
+	return path
+}
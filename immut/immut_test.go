@@ -0,0 +1,74 @@
+package immut
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type server struct {
+	Addr    string
+	Timeout time.Duration
+}
+
+type config struct {
+	Name    string
+	Servers []server
+	Labels  map[string]string
+	Limit   *int
+	cache   map[string]string
+}
+
+func newConfig() config {
+	limit := 10
+	return config{
+		Name:    "app",
+		Servers: []server{{Addr: "a:1"}, {Addr: "b:2"}},
+		Labels:  map[string]string{"env": "prod"},
+		Limit:   &limit,
+	}
+}
+
+func TestFrozen(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(c config)
+		wantPath string
+	}{
+		{name: "untouched", mutate: func(c config) {}},
+		{name: "unexported ignored", mutate: func(c config) { c.cache["k"] = "v" }},
+		{name: "slice element", mutate: func(c config) { c.Servers[1].Addr = "c:3" }, wantPath: "Servers[1].Addr"},
+		{name: "map value", mutate: func(c config) { c.Labels["env"] = "dev" }, wantPath: "Labels[env]"},
+		{name: "map insert", mutate: func(c config) { c.Labels["new"] = "x" }, wantPath: "Labels.len"},
+		{name: "pointer target", mutate: func(c config) { *c.Limit = 20 }, wantPath: "Limit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newConfig()
+			c.cache = map[string]string{}
+			f := Freeze(c)
+			tt.mutate(f.Get())
+
+			err := f.Check()
+			if tt.wantPath == "" {
+				if err != nil {
+					t.Errorf("Check() error = %v, want nil", err)
+				}
+				return
+			}
+			var me *MutationError
+			if !errors.As(err, &me) || me.Path != tt.wantPath {
+				t.Errorf("Check() error = %v, want path %s", err, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Check(newConfig(), newConfig()); err != nil {
+		t.Errorf("Check() equal values error = %v", err)
+	}
+	if err := Check(1, 2); err == nil || err.(*MutationError).Path != "<root>" {
+		t.Errorf("Check() = %v", err)
+	}
+}
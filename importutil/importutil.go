@@ -0,0 +1,252 @@
+package importutil
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/ChangSZ/golib/decode"
+	"github.com/ChangSZ/golib/validator"
+)
+
+// TagName 结构体标签名, 值为表头名称, 如`import:"订单号"`; 未设置时按字段名匹配表头
+const TagName = "import"
+
+// ErrTooManyErrors 错误行数达到WithMaxErrors设置的上限, 导入已停止
+var ErrTooManyErrors = errors.New("importutil: too many invalid rows")
+
+// TimeLayouts 解析time.Time字段时依次尝试的格式
+var TimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	time.RFC3339,
+}
+
+// Option Import的可选参数
+type Option func(*options)
+
+type options struct {
+	batchSize int
+	dryRun    bool
+	maxErrors int
+	decode    []decode.Option
+}
+
+// WithBatchSize 每批交给handler的行数, 默认500
+func WithBatchSize(n int) Option {
+	return func(o *options) {
+		o.batchSize = n
+	}
+}
+
+// WithDryRun 只解析和校验, 不调用handler, 用于上传后先预览错误
+func WithDryRun() Option {
+	return func(o *options) {
+		o.dryRun = true
+	}
+}
+
+// WithMaxErrors 错误行数达到n时停止并返回ErrTooManyErrors, 默认1000, <=0表示不限制
+func WithMaxErrors(n int) Option {
+	return func(o *options) {
+		o.maxErrors = n
+	}
+}
+
+// WithDecodeOptions 追加decode的选项, 如自定义钩子
+func WithDecodeOptions(opts ...decode.Option) Option {
+	return func(o *options) {
+		o.decode = append(o.decode, opts...)
+	}
+}
+
+// RowError 一行数据的错误
+type RowError struct {
+	Row      int      // 行号, 表头为第1行
+	Cells    []string // 原始数据
+	Messages []string
+}
+
+// Report 导入结果
+type Report struct {
+	Header    []string
+	Total     int // 数据行数, 不含表头和空行
+	Succeeded int // 交给handler且handler成功的行数, dry-run时为校验通过的行数
+	Errors    []RowError
+}
+
+// Failed 出错的行数
+func (r *Report) Failed() int {
+	return len(r.Errors)
+}
+
+// WriteCSV 写出错误报告: 原始的列加上"错误信息"列, 用户修正后可以直接重新导入
+func (r *Report) WriteCSV(w io.Writer) error {
+	if _, err := io.WriteString(w, "\xEF\xBB\xBF"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	header := append([]string{"行号"}, r.Header...)
+	if err := cw.Write(append(header, "错误信息")); err != nil {
+		return err
+	}
+	for _, e := range r.Errors {
+		rec := make([]string, 0, len(r.Header)+2)
+		rec = append(rec, strconv.Itoa(e.Row))
+		for i := range r.Header {
+			cell := ""
+			if i < len(e.Cells) {
+				cell = e.Cells[i]
+			}
+			rec = append(rec, cell)
+		}
+		if err := cw.Write(append(rec, strings.Join(e.Messages, "; "))); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Import 逐行读取r, 按表头解码到T并用binding标签校验(与gin的参数校验相同), 校验通过的行按批交给handler
+//
+// 出错的行记录在Report中, 不会中断导入; handler返回错误、读取失败或错误行数超过上限时停止并返回错误.
+// 第一行为表头, 全部单元格为空的行被忽略
+//
+//	type OrderRow struct {
+//		ID     int64   `import:"订单号" binding:"required"`
+//		Amount float64 `import:"金额" binding:"gt=0"`
+//	}
+//	report, err := importutil.Import(ctx, r, func(ctx context.Context, rows []OrderRow) error {
+//		return repo.BatchInsert(ctx, rows)
+//	})
+func Import[T any](ctx context.Context, r RowReader, handler func(ctx context.Context, rows []T) error, opts ...Option) (*Report, error) {
+	o := &options{batchSize: 500, maxErrors: 1000}
+	for _, opt := range opts {
+		opt(o)
+	}
+	dec := decode.NewDecoder(append([]decode.Option{
+		decode.WithTagName(TagName),
+		decode.WithWeaklyTyped(),
+		decode.WithHooks(stringToTimeHook, decode.StringToDurationHook()),
+	}, o.decode...)...)
+
+	report := &Report{}
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return report, nil
+	}
+	if err != nil {
+		return report, err
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	report.Header = header
+
+	batch := make([]T, 0, o.batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if !o.dryRun {
+			if err := handler(ctx, batch); err != nil {
+				return err
+			}
+		}
+		report.Succeeded += len(batch)
+		batch = make([]T, 0, o.batchSize)
+		return nil
+	}
+
+	for line := 2; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		cells, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+		if blank(cells) {
+			continue
+		}
+		report.Total++
+
+		row, msgs := parseRow[T](ctx, dec, header, cells)
+		if len(msgs) > 0 {
+			report.Errors = append(report.Errors, RowError{Row: line, Cells: cells, Messages: msgs})
+			if o.maxErrors > 0 && len(report.Errors) >= o.maxErrors {
+				return report, ErrTooManyErrors
+			}
+			continue
+		}
+		batch = append(batch, row)
+		if len(batch) >= o.batchSize {
+			if err := flush(); err != nil {
+				return report, err
+			}
+		}
+	}
+	return report, flush()
+}
+
+func parseRow[T any](ctx context.Context, dec *decode.Decoder, header, cells []string) (T, []string) {
+	var row T
+	m := make(map[string]interface{}, len(header))
+	for i, h := range header {
+		// 空单元格不参与解码, 字段保持零值, 是否必填由binding标签决定
+		if i < len(cells) && h != "" && strings.TrimSpace(cells[i]) != "" {
+			m[h] = strings.TrimSpace(cells[i])
+		}
+	}
+	if err := dec.Decode(m, &row); err != nil {
+		return row, []string{err.Error()}
+	}
+	if err := binding.Validator.ValidateStruct(&row); err != nil {
+		var msgs []string
+		for _, e := range validator.GetValidationErrorsCtx(ctx, err) {
+			msgs = append(msgs, e.Error())
+		}
+		return row, msgs
+	}
+	return row, nil
+}
+
+func blank(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// stringToTimeHook 按TimeLayouts解析时间, 使用time.Local时区
+func stringToTimeHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != timeType {
+		return data, nil
+	}
+	s := reflect.ValueOf(data).String()
+	var err error
+	for _, layout := range TimeLayouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return nil, err
+}
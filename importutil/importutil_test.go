@@ -0,0 +1,165 @@
+package importutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChangSZ/golib/exportutil"
+)
+
+type orderRow struct {
+	ID       int64     `import:"订单号" binding:"required"`
+	Amount   float64   `import:"金额" binding:"gt=0"`
+	Remark   string    `import:"备注"`
+	PaidAt   time.Time `import:"支付时间"`
+	Internal string    `import:"-"`
+}
+
+const ordersCSV = "\xEF\xBB\xBF订单号,金额,备注,支付时间\n" +
+	"1,9.5,加急,2024-01-02\n" +
+	"abc,1,,\n" +
+	",,,\n" +
+	"3,0,,\n" +
+	"4,2,,2024-01-02 10:00:00\n" +
+	"5,3,,\n"
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []Option
+		wantBatches   []int
+		wantSucceeded int
+		wantErr       error
+	}{
+		{name: "batches", opts: []Option{WithBatchSize(2)}, wantBatches: []int{2, 1}, wantSucceeded: 3},
+		{name: "dry run", opts: []Option{WithDryRun()}, wantSucceeded: 3},
+		{name: "max errors", opts: []Option{WithMaxErrors(2)}, wantErr: ErrTooManyErrors},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var batches []int
+			var first orderRow
+			report, err := Import(context.Background(), NewCSVReader(strings.NewReader(ordersCSV)),
+				func(ctx context.Context, rows []orderRow) error {
+					if len(batches) == 0 {
+						first = rows[0]
+					}
+					batches = append(batches, len(rows))
+					return nil
+				}, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Import() error = %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if report.Total != 5 || report.Succeeded != tt.wantSucceeded || report.Failed() != 2 {
+				t.Errorf("report = %+v", report)
+			}
+			if len(batches) != len(tt.wantBatches) {
+				t.Fatalf("batches = %v, want %v", batches, tt.wantBatches)
+			}
+			if len(batches) > 0 && (first.ID != 1 || first.Remark != "加急" || first.PaidAt.Day() != 2) {
+				t.Errorf("first row = %+v", first)
+			}
+			if rows := []int{report.Errors[0].Row, report.Errors[1].Row}; rows[0] != 3 || rows[1] != 5 {
+				t.Errorf("error rows = %v, want [3 5]", rows)
+			}
+		})
+	}
+}
+
+func TestReportCSV(t *testing.T) {
+	report, err := Import(context.Background(), NewCSVReader(strings.NewReader(ordersCSV)),
+		func(ctx context.Context, rows []orderRow) error { return nil }, WithDryRun())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || lines[0] != "\xEF\xBB\xBF行号,订单号,金额,备注,支付时间,错误信息" || !strings.HasPrefix(lines[1], "3,abc,1,,,") {
+		t.Errorf("report = %q", buf.String())
+	}
+
+	// 错误报告本身是合法的CSV, 可以再次读取
+	if _, err := NewReader("errors.csv", buf.Bytes()); err != nil {
+		t.Errorf("NewReader() error = %v", err)
+	}
+}
+
+func TestHandlerError(t *testing.T) {
+	boom := errors.New("db down")
+	report, err := Import(context.Background(), NewCSVReader(strings.NewReader(ordersCSV)),
+		func(ctx context.Context, rows []orderRow) error { return boom })
+	if !errors.Is(err, boom) || report.Succeeded != 0 {
+		t.Errorf("Import() = %+v, %v", report, err)
+	}
+}
+
+func TestXLSXRoundTrip(t *testing.T) {
+	type row struct {
+		ID     int64   `export:"订单号" import:"订单号"`
+		Amount float64 `export:"金额" import:"金额"`
+	}
+	var buf bytes.Buffer
+	w, _ := exportutil.NewXLSXWriter(&buf, "")
+	src := exportutil.SliceSource([]row{{ID: 1, Amount: 1.5}, {ID: 2, Amount: 3}})
+	if _, err := exportutil.Export(context.Background(), src, w, exportutil.StructColumns[row]()); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader("orders.xlsx", buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []row
+	report, err := Import(context.Background(), r, func(ctx context.Context, rows []row) error {
+		got = append(got, rows...)
+		return nil
+	})
+	if err != nil || report.Succeeded != 2 || got[1] != (row{ID: 2, Amount: 3}) {
+		t.Errorf("Import() = %+v, %v, rows = %+v", report, err, got)
+	}
+}
+
+func TestXLSXSharedStrings(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="A" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId3" Type="worksheet" Target="worksheets/data.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>名称</t></si><si><t>数量</t></si><si><r><t>苹</t></r><r><t>果</t></r></si></sst>`,
+		"xl/worksheets/data.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+			`<row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2"><v>12</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	for name, body := range files {
+		fw, _ := zw.Create(name)
+		_, _ = fw.Write([]byte(body))
+	}
+	_ = zw.Close()
+
+	r, err := NewXLSXReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"名称", "", "数量"}, {"苹果", "", "12"}}
+	for i, w := range want {
+		got, err := r.Read()
+		if err != nil || strings.Join(got, "|") != strings.Join(w, "|") {
+			t.Errorf("row %d = %q, %v, want %q", i, got, err, w)
+		}
+	}
+}
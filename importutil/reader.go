@@ -0,0 +1,248 @@
+package importutil
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// RowReader 按行读取表格, 读完时返回io.EOF
+type RowReader interface {
+	Read() ([]string, error)
+}
+
+var (
+	_ RowReader = (*CSVReader)(nil)
+	_ RowReader = (*XLSXReader)(nil)
+)
+
+// CSVReader 读取CSV, 自动去掉Excel写入的UTF-8 BOM, 允许各行列数不同
+type CSVReader struct {
+	r     *csv.Reader
+	first bool
+}
+
+// NewCSVReader new a CSVReader.
+func NewCSVReader(r io.Reader) *CSVReader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = false
+	return &CSVReader{r: cr, first: true}
+}
+
+func (c *CSVReader) Read() ([]string, error) {
+	rec, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	if c.first {
+		c.first = false
+		if len(rec) > 0 {
+			rec[0] = strings.TrimPrefix(rec[0], "\xEF\xBB\xBF")
+		}
+	}
+	return rec, nil
+}
+
+// XLSXReader 流式读取xlsx的第一个工作表, 单元格均按文本返回; 只支持共享字符串、内联字符串和数值
+type XLSXReader struct {
+	dec     *xml.Decoder
+	closer  io.Closer
+	strings []string
+}
+
+// NewXLSXReader new a XLSXReader.
+func NewXLSXReader(r io.ReaderAt, size int64) (*XLSXReader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("importutil: open xlsx: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	x := &XLSXReader{}
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if x.strings, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+	sheet, err := firstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := sheet.Open()
+	if err != nil {
+		return nil, err
+	}
+	x.dec = xml.NewDecoder(rc)
+	x.closer = rc
+	return x, nil
+}
+
+// firstSheet 按workbook中的顺序找到第一个工作表
+func firstSheet(files map[string]*zip.File) (*zip.File, error) {
+	var wb struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := unmarshalFile(files["xl/workbook.xml"], &wb); err != nil {
+		return nil, err
+	}
+	if err := unmarshalFile(files["xl/_rels/workbook.xml.rels"], &rels); err != nil {
+		return nil, err
+	}
+	if len(wb.Sheets) == 0 {
+		return nil, errors.New("importutil: xlsx has no sheet")
+	}
+	for _, rel := range rels.Rels {
+		if rel.ID != wb.Sheets[0].ID {
+			continue
+		}
+		target := strings.TrimPrefix(rel.Target, "/")
+		if !strings.HasPrefix(target, "xl/") {
+			target = path.Join("xl", target)
+		}
+		if f, ok := files[target]; ok {
+			return f, nil
+		}
+	}
+	return nil, errors.New("importutil: xlsx sheet not found")
+}
+
+func unmarshalFile(f *zip.File, v interface{}) error {
+	if f == nil {
+		return errors.New("importutil: invalid xlsx")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+func readSharedStrings(f *zip.File) ([]string, error) {
+	var sst struct {
+		Items []struct {
+			T string `xml:"t"`
+			R []struct {
+				T string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	if err := unmarshalFile(f, &sst); err != nil {
+		return nil, err
+	}
+	out := make([]string, len(sst.Items))
+	for i, si := range sst.Items {
+		if len(si.R) == 0 {
+			out[i] = si.T
+			continue
+		}
+		// 富文本由多段组成
+		var b strings.Builder
+		for _, r := range si.R {
+			b.WriteString(r.T)
+		}
+		out[i] = b.String()
+	}
+	return out, nil
+}
+
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline struct {
+		T string `xml:"t"`
+	} `xml:"is"`
+}
+
+func (x *XLSXReader) Read() ([]string, error) {
+	for {
+		tok, err := x.dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				_ = x.closer.Close()
+			}
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var row struct {
+			Cells []xlsxCell `xml:"c"`
+		}
+		if err := x.dec.DecodeElement(&row, &start); err != nil {
+			return nil, err
+		}
+		return x.cells(row.Cells)
+	}
+}
+
+// cells 按单元格引用放到对应的列, 空单元格在xlsx中会被省略
+func (x *XLSXReader) cells(cells []xlsxCell) ([]string, error) {
+	var out []string
+	for i, c := range cells {
+		col := i
+		if c.Ref != "" {
+			col = columnIndex(c.Ref)
+		}
+		for len(out) <= col {
+			out = append(out, "")
+		}
+		switch c.Type {
+		case "s":
+			n, err := strconv.Atoi(c.Value)
+			if err != nil || n < 0 || n >= len(x.strings) {
+				return nil, fmt.Errorf("importutil: invalid shared string %q at %s", c.Value, c.Ref)
+			}
+			out[col] = x.strings[n]
+		case "inlineStr":
+			out[col] = c.Inline.T
+		default:
+			out[col] = c.Value
+		}
+	}
+	return out, nil
+}
+
+// columnIndex 将"C12"这样的引用转换为从0开始的列号
+func columnIndex(ref string) int {
+	n := 0
+	for _, ch := range []byte(ref) {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		n = n*26 + int(ch-'A'+1)
+	}
+	return n - 1
+}
+
+// NewReader 根据文件扩展名选择读取器, 支持.csv和.xlsx
+func NewReader(name string, data []byte) (RowReader, error) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv":
+		return NewCSVReader(bytes.NewReader(data)), nil
+	case ".xlsx":
+		return NewXLSXReader(bytes.NewReader(data), int64(len(data)))
+	}
+	return nil, fmt.Errorf("importutil: unsupported file %s", name)
+}
@@ -0,0 +1,201 @@
+package intern
+
+import (
+	"hash/maphash"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Config 字符串池配置
+type Config struct {
+	MaxEntries int `toml:"maxEntries"` // 最多保存的字符串个数, 达到上限后新的字符串不再入池, 默认65536
+	MaxLen     int `toml:"maxLen"`     // 超过该长度的字符串不入池, 默认64; 长字符串重复的概率低, 入池收益小
+	Shards     int `toml:"shards"`     // 分片数, 减少并发时的锁竞争, 默认16
+}
+
+// Stats 字符串池统计
+type Stats struct {
+	Entries    int    // 池中字符串个数
+	Hits       uint64 // 命中次数, 每次命中都少保留一份重复的字符串
+	Misses     uint64 // 未命中并入池的次数
+	Rejected   uint64 // 因过长或池已满未入池的次数
+	BytesSaved uint64 // 命中时复用的字节数之和, 即去重节省的内存的估计值
+}
+
+// Pool 字符串驻留池, 相同内容的字符串共享同一份内存
+//
+// 适用于大量重复的短字符串, 如标签值、枚举名、城市名, 在大型内存索引中可以显著降低内存占用:
+//
+//	p := intern.New(intern.Config{})
+//	for _, row := range rows {
+//		idx.Add(p.String(row.Tag), row.ID)
+//	}
+//
+// 池中的字符串不会被淘汰, 只能整体Reset, 不要用于取值范围无界的数据(如用户输入、ID)
+type Pool struct {
+	cfg    Config
+	seed   maphash.Seed
+	shards []shard
+	size   atomic.Int64
+
+	hits, misses, rejected, saved atomic.Uint64
+}
+
+type shard struct {
+	mu sync.RWMutex
+	m  map[string]string
+}
+
+// New new a Pool.
+func New(cfg Config) *Pool {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 65536
+	}
+	if cfg.MaxLen <= 0 {
+		cfg.MaxLen = 64
+	}
+	if cfg.Shards <= 0 {
+		cfg.Shards = 16
+	}
+	p := &Pool{cfg: cfg, seed: maphash.MakeSeed(), shards: make([]shard, cfg.Shards)}
+	for i := range p.shards {
+		p.shards[i].m = make(map[string]string)
+	}
+	return p
+}
+
+// single 单字节的ASCII字符串, 不需要经过map
+var single = func() (t [128]string) {
+	for i := range t {
+		t[i] = string(rune(i))
+	}
+	return t
+}()
+
+// String 返回与s内容相同的池中字符串, s未入池时将s的副本加入池中
+//
+// 入池时复制s, 避免s是大字符串的子串时池中的引用让整个大字符串无法回收
+func (p *Pool) String(s string) string {
+	if v, ok := p.small(s); ok {
+		return v
+	}
+	if len(s) > p.cfg.MaxLen {
+		p.rejected.Add(1)
+		return s
+	}
+	sh := p.shard(s)
+	sh.mu.RLock()
+	v, ok := sh.m[s]
+	sh.mu.RUnlock()
+	if ok {
+		p.hit(v)
+		return v
+	}
+	return p.insert(sh, s)
+}
+
+// Bytes 同String, 命中时不分配内存, 适合解析协议或文件时直接传入缓冲区
+func (p *Pool) Bytes(b []byte) string {
+	if len(b) == 1 && b[0] < 0x80 {
+		p.hits.Add(1)
+		return single[b[0]]
+	}
+	if len(b) == 0 || len(b) > p.cfg.MaxLen {
+		return p.String(string(b))
+	}
+	sh := &p.shards[maphash.Bytes(p.seed, b)%uint64(len(p.shards))]
+	sh.mu.RLock()
+	// 编译器对m[string(b)]做了优化, 查找时不会分配内存
+	v, ok := sh.m[string(b)]
+	sh.mu.RUnlock()
+	if ok {
+		p.hit(v)
+		return v
+	}
+	return p.insert(sh, string(b))
+}
+
+// small 空串和单字节ASCII字符串直接返回静态值
+func (p *Pool) small(s string) (string, bool) {
+	switch {
+	case len(s) == 0:
+		return "", true
+	case len(s) == 1 && s[0] < 0x80:
+		p.hits.Add(1)
+		return single[s[0]], true
+	}
+	return "", false
+}
+
+func (p *Pool) shard(s string) *shard {
+	return &p.shards[maphash.String(p.seed, s)%uint64(len(p.shards))]
+}
+
+func (p *Pool) hit(v string) {
+	p.hits.Add(1)
+	p.saved.Add(uint64(len(v)))
+}
+
+func (p *Pool) insert(sh *shard, s string) string {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	// 加写锁前可能已被其他goroutine插入
+	if v, ok := sh.m[s]; ok {
+		p.hit(v)
+		return v
+	}
+	if p.size.Load() >= int64(p.cfg.MaxEntries) {
+		p.rejected.Add(1)
+		return s
+	}
+	v := strings.Clone(s)
+	sh.m[v] = v
+	p.size.Add(1)
+	p.misses.Add(1)
+	return v
+}
+
+// Len 池中字符串个数
+func (p *Pool) Len() int {
+	return int(p.size.Load())
+}
+
+// Stats 统计信息
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Entries:    p.Len(),
+		Hits:       p.hits.Load(),
+		Misses:     p.misses.Load(),
+		Rejected:   p.rejected.Load(),
+		BytesSaved: p.saved.Load(),
+	}
+}
+
+// Reset 清空池和统计, 已返回的字符串仍然有效
+func (p *Pool) Reset() {
+	for i := range p.shards {
+		sh := &p.shards[i]
+		sh.mu.Lock()
+		p.size.Add(-int64(len(sh.m)))
+		sh.m = make(map[string]string)
+		sh.mu.Unlock()
+	}
+	p.hits.Store(0)
+	p.misses.Store(0)
+	p.rejected.Store(0)
+	p.saved.Store(0)
+}
+
+// Default 默认的字符串池
+var Default = New(Config{})
+
+// String 使用Default驻留s
+func String(s string) string {
+	return Default.String(s)
+}
+
+// Bytes 使用Default驻留b
+func Bytes(b []byte) string {
+	return Default.Bytes(b)
+}
@@ -0,0 +1,91 @@
+package intern
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+func same(a, b string) bool {
+	return unsafe.StringData(a) == unsafe.StringData(b)
+}
+
+func TestPool(t *testing.T) {
+	p := New(Config{MaxEntries: 2, MaxLen: 8})
+	buf := []byte("shanghai,beijing")
+
+	first := p.Bytes(buf[:8])
+	tests := []struct {
+		name     string
+		get      func() string
+		want     string
+		wantSame bool
+	}{
+		{name: "string hit", get: func() string { return p.String(strings.Clone("shanghai")) }, want: "shanghai", wantSame: true},
+		{name: "bytes hit", get: func() string { return p.Bytes([]byte("shanghai")) }, want: "shanghai", wantSame: true},
+		{name: "second entry", get: func() string { return p.String("beijing") }, want: "beijing"},
+		{name: "full", get: func() string { return p.String("chengdu") }, want: "chengdu"},
+		{name: "too long", get: func() string { return p.String("guangzhou") }, want: "guangzhou"},
+		{name: "single byte", get: func() string { return p.Bytes([]byte("a")) }, want: "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.get()
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if tt.wantSame && !same(got, first) {
+				t.Errorf("%q not deduplicated", got)
+			}
+		})
+	}
+
+	// 入池时复制, 不引用调用方的缓冲区
+	if unsafe.StringData(first) == &buf[0] {
+		t.Errorf("pool references caller buffer")
+	}
+	want := Stats{Entries: 2, Hits: 3, Misses: 2, Rejected: 2, BytesSaved: 16}
+	if got := p.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+
+	p.Reset()
+	if got := p.Stats(); got != (Stats{}) {
+		t.Errorf("Stats() after Reset = %+v", got)
+	}
+	if got := p.String("chengdu"); got != "chengdu" || p.Len() != 1 {
+		t.Errorf("String() after Reset = %q, Len = %d", got, p.Len())
+	}
+}
+
+func TestPoolConcurrent(t *testing.T) {
+	p := New(Config{})
+	var wg sync.WaitGroup
+	results := make([]string, 32)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = p.String(strings.Repeat("x", 10))
+		}(i)
+	}
+	wg.Wait()
+	for _, r := range results[1:] {
+		if !same(r, results[0]) {
+			t.Fatalf("concurrent String() returned different copies")
+		}
+	}
+	if s := p.Stats(); s.Entries != 1 || s.Misses != 1 || s.Hits != 31 {
+		t.Errorf("Stats() = %+v", s)
+	}
+}
+
+func BenchmarkBytes(b *testing.B) {
+	p := New(Config{})
+	buf := []byte("status=active")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = p.Bytes(buf[7:])
+	}
+}
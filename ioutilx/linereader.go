@@ -0,0 +1,141 @@
+package ioutilx
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxLineSize 默认的单行最大长度
+const DefaultMaxLineSize = 16 << 20
+
+// ErrLineTooLong 行长度超过限制, 可以用errors.Is判断
+var ErrLineTooLong = errors.New("ioutilx: line too long")
+
+// LineTooLongError 行长度超过限制的详细信息
+type LineTooLongError struct {
+	Line int // 行号, 从1开始
+	Max  int
+}
+
+func (e *LineTooLongError) Error() string {
+	return fmt.Sprintf("ioutilx: line %d exceeds %d bytes", e.Line, e.Max)
+}
+
+// Is 支持errors.Is(err, ErrLineTooLong)
+func (e *LineTooLongError) Is(target error) bool {
+	return target == ErrLineTooLong
+}
+
+// LineReader 按行读取, 与bufio.Scanner不同, 单行长度只受MaxLineSize限制, 适合读取日志、NDJSON等文件
+//
+// 行尾的"\n"和"\r\n"会被去掉, 最后一行没有换行符时同样返回; 超长的行返回*LineTooLongError并被整行跳过, 之后可以继续读取
+type LineReader struct {
+	r       *bufio.Reader
+	max     int
+	line    int
+	buf     []byte
+	onLimit func(line int)
+}
+
+// LineOption LineReader的可选参数
+type LineOption func(*LineReader)
+
+// WithMaxLineSize 单行最大字节数, 不含换行符
+func WithMaxLineSize(n int) LineOption {
+	return func(l *LineReader) {
+		if n > 0 {
+			l.max = n
+		}
+	}
+}
+
+// WithBufferSize 底层bufio.Reader的缓冲区大小
+func WithBufferSize(n int) LineOption {
+	return func(l *LineReader) {
+		if n > 0 {
+			l.r = bufio.NewReaderSize(l.r, n)
+		}
+	}
+}
+
+// NewLineReader new a LineReader.
+func NewLineReader(r io.Reader, opts ...LineOption) *LineReader {
+	l := &LineReader{r: bufio.NewReader(r), max: DefaultMaxLineSize}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Line 最近一次读取的行号, 从1开始
+func (l *LineReader) Line() int {
+	return l.line
+}
+
+// ReadLine 读取下一行, 读完时返回io.EOF
+//
+// 返回的切片在下一次调用前有效, 需要保留时请复制
+func (l *LineReader) ReadLine() ([]byte, error) {
+	l.buf = l.buf[:0]
+	tooLong := false
+	for {
+		chunk, err := l.r.ReadSlice('\n')
+		if !tooLong {
+			if len(l.buf)+len(chunk) > l.max+2 { // 预留"\r\n"
+				tooLong = true
+				l.buf = l.buf[:0]
+			} else {
+				l.buf = append(l.buf, chunk...)
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil && (err != io.EOF || (len(chunk) == 0 && len(l.buf) == 0 && !tooLong)) {
+			return nil, err
+		}
+
+		l.line++
+		if tooLong {
+			return nil, &LineTooLongError{Line: l.line, Max: l.max}
+		}
+		line := dropCRLF(l.buf)
+		if len(line) > l.max {
+			return nil, &LineTooLongError{Line: l.line, Max: l.max}
+		}
+		return line, nil
+	}
+}
+
+// ReadString 同ReadLine, 返回字符串
+func (l *LineReader) ReadString() (string, error) {
+	b, err := l.ReadLine()
+	return string(b), err
+}
+
+// Each 依次处理每一行, fn返回错误时停止; skipLong为true时跳过超长的行而不是返回错误
+func (l *LineReader) Each(skipLong bool, fn func(line []byte) error) error {
+	for {
+		line, err := l.ReadLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if skipLong && errors.Is(err, ErrLineTooLong) {
+				continue
+			}
+			return err
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+}
+
+func dropCRLF(b []byte) []byte {
+	b = bytes.TrimSuffix(b, []byte{'\n'})
+	return bytes.TrimSuffix(b, []byte{'\r'})
+}
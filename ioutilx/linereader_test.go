@@ -0,0 +1,72 @@
+package ioutilx
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLineReader(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	tests := []struct {
+		name  string
+		input string
+		max   int
+		want  []string // "!"表示该行返回ErrLineTooLong
+	}{
+		{name: "basic", input: "a\nb\r\nc", max: 10, want: []string{"a", "b", "c"}},
+		{name: "trailing newline", input: "a\n\n", max: 10, want: []string{"a", ""}},
+		{name: "empty", input: "", max: 10, want: nil},
+		{name: "beyond buffer", input: long + "\nend\n", max: 1000, want: []string{long, "end"}},
+		{name: "too long", input: "ok\n" + long + "\nnext\n", max: 50, want: []string{"ok", "!", "next"}},
+		{name: "too long last", input: "ok\n" + long, max: 50, want: []string{"ok", "!"}},
+		{name: "exact max", input: "abcde\r\n", max: 5, want: []string{"abcde"}},
+		{name: "one over", input: "abcdef\n", max: 5, want: []string{"!"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 缓冲区小于行长度, 覆盖分段读取
+			r := NewLineReader(strings.NewReader(tt.input), WithMaxLineSize(tt.max), WithBufferSize(16))
+			var got []string
+			for {
+				line, err := r.ReadString()
+				if err == io.EOF {
+					break
+				}
+				if errors.Is(err, ErrLineTooLong) {
+					var e *LineTooLongError
+					if !errors.As(err, &e) || e.Line != r.Line() {
+						t.Errorf("error = %v, line %d", err, r.Line())
+					}
+					got = append(got, "!")
+					continue
+				}
+				if err != nil {
+					t.Fatalf("ReadString() error = %v", err)
+				}
+				got = append(got, line)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEach(t *testing.T) {
+	r := NewLineReader(strings.NewReader("{\"a\":1}\n"+strings.Repeat("y", 30)+"\n{\"a\":2}\n"), WithMaxLineSize(10))
+	var lines []string
+	err := r.Each(true, func(line []byte) error {
+		lines = append(lines, string(line))
+		return nil
+	})
+	if err != nil || len(lines) != 2 || lines[1] != `{"a":2}` {
+		t.Errorf("Each() = %q, %v", lines, err)
+	}
+
+	r = NewLineReader(strings.NewReader(strings.Repeat("y", 30)+"\n"), WithMaxLineSize(10))
+	if err := r.Each(false, func([]byte) error { return nil }); !errors.Is(err, ErrLineTooLong) {
+		t.Errorf("Each() error = %v, want ErrLineTooLong", err)
+	}
+}
@@ -0,0 +1,70 @@
+package jsonutil
+
+import "testing"
+
+type signPayload struct {
+	Nonce  string                 `json:"nonce"`
+	Amount float64                `json:"amount"`
+	AppID  int64                  `json:"app_id"`
+	Extra  map[string]interface{} `json:"extra,omitempty"`
+}
+
+func TestCanonical(t *testing.T) {
+	type args struct {
+		v interface{}
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "struct_sorted",
+			args: args{
+				v: signPayload{Nonce: "a<b>&c", Amount: 10.50, AppID: 9007199254740993},
+			},
+			want: `{"amount":10.5,"app_id":9007199254740993,"nonce":"a<b>&c"}`,
+		},
+		{
+			name: "nested_map",
+			args: args{
+				v: map[string]interface{}{
+					"z": []interface{}{1, 2.0, "x"},
+					"a": map[string]interface{}{"d": nil, "c": true},
+				},
+			},
+			want: `{"a":{"c":true,"d":null},"z":[1,2,"x"]}`,
+		},
+		{
+			name: "float_exponent",
+			args: args{
+				v: []float64{1e21, 1e-7, 0.000001, -0.0, 123456789.125},
+			},
+			want: `[1e+21,1e-7,0.000001,0,123456789.125]`,
+		},
+		{
+			name: "raw_message",
+			args: args{
+				v: map[string]interface{}{"b": 1, "a": rawJSON(`{"y":1.50,"x":2}`)},
+			},
+			want: `{"a":{"x":2,"y":1.5},"b":1}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalString(tt.args.v)
+			if err != nil {
+				t.Fatalf("Canonical() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Canonical() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type rawJSON string
+
+func (r rawJSON) MarshalJSON() ([]byte, error) {
+	return []byte(r), nil
+}
@@ -0,0 +1,385 @@
+package kvlite
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ChangSZ/golib/file"
+	"github.com/ChangSZ/golib/log"
+)
+
+// compactBatch 压缩时每条记录包含的key数
+const compactBatch = 1000
+
+// ErrClosed DB已关闭
+var ErrClosed = errors.New("kvlite: db closed")
+
+// ErrTooLarge 批次编码后超过单条记录的最大长度
+var ErrTooLarge = errors.New("kvlite: batch too large")
+
+// Option DB的可选参数
+type Option func(*DB)
+
+// WithSync 每次写入后是否fsync, 默认开启; 关闭后性能更好, 但机器掉电时可能丢失最近的写入
+func WithSync(sync bool) Option {
+	return func(db *DB) {
+		db.sync = sync
+	}
+}
+
+// WithAutoCompact 日志中的无效记录数超过threshold时自动压缩, 0表示不自动压缩, 默认10000
+func WithAutoCompact(threshold int) Option {
+	return func(db *DB) {
+		db.compactThreshold = threshold
+	}
+}
+
+type entry struct {
+	value    []byte
+	expireAt int64
+}
+
+// DB 嵌入式键值存储, 所有数据保存在内存索引中, 写入以追加日志的方式持久化, 可以并发使用
+//
+// 适合数据量不大(能放进内存)、需要在重启后保留状态的场景, 如agent、命令行工具的本地状态
+type DB struct {
+	mu               sync.RWMutex
+	path             string
+	f                *os.File
+	data             map[string]entry
+	sync             bool
+	compactThreshold int
+	garbage          int // 日志中被覆盖、删除或过期的记录数
+	now              func() time.Time
+	closed           bool
+}
+
+// Open 打开或创建数据文件, 末尾写了一半的批次会被丢弃
+func Open(path string, opts ...Option) (*DB, error) {
+	db := &DB{
+		path:             path,
+		data:             make(map[string]entry),
+		sync:             true,
+		compactThreshold: 10000,
+		now:              time.Now,
+	}
+	for _, opt := range opts {
+		opt(db)
+	}
+	if err := file.MakeDirByFile(path); err != nil {
+		return nil, err
+	}
+	valid, err := db.replay()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	// 截掉损坏的尾部, 之后的写入从有效数据末尾开始
+	if err := f.Truncate(valid); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(valid, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	db.f = f
+	return db, nil
+}
+
+// replay 回放日志, 返回有效数据的长度
+func (db *DB) replay() (int64, error) {
+	f, err := os.Open(db.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var valid int64
+	for {
+		rec, n, err := readRecord(r)
+		if err == io.EOF {
+			return valid, nil
+		}
+		if err != nil {
+			log.Warnw("msg", "kvlite: drop corrupted tail", "path", db.path, "offset", valid)
+			return valid, nil
+		}
+		db.apply(rec.Ops)
+		valid += int64(n)
+	}
+}
+
+// apply 将操作应用到内存索引, 调用方需持有写锁
+func (db *DB) apply(ops []op) {
+	for _, o := range ops {
+		if _, ok := db.data[o.Key]; ok {
+			db.garbage++
+		}
+		if o.Del {
+			delete(db.data, o.Key)
+			db.garbage++
+			continue
+		}
+		db.data[o.Key] = entry{value: o.Value, expireAt: o.ExpireAt}
+	}
+}
+
+func (db *DB) expired(e entry) bool {
+	return e.expireAt != 0 && db.now().UnixNano() >= e.expireAt
+}
+
+// Get 读取key, 不存在或已过期时ok为false; 返回值不能修改
+func (db *DB) Get(key string) ([]byte, bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	e, ok := db.data[key]
+	if !ok || db.expired(e) {
+		return nil, false
+	}
+	return e.value, true
+}
+
+// TTL key的剩余有效期, 不过期返回0, 不存在时ok为false
+func (db *DB) TTL(key string) (time.Duration, bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	e, ok := db.data[key]
+	if !ok || db.expired(e) {
+		return 0, false
+	}
+	if e.expireAt == 0 {
+		return 0, true
+	}
+	return time.Duration(e.expireAt - db.now().UnixNano()), true
+}
+
+// Set 写入key, ttl<=0表示不过期
+func (db *DB) Set(key string, value []byte, ttl time.Duration) error {
+	return db.Batch(func(b *Batch) error {
+		b.Set(key, value, ttl)
+		return nil
+	})
+}
+
+// Delete 删除key
+func (db *DB) Delete(key string) error {
+	return db.Batch(func(b *Batch) error {
+		b.Delete(key)
+		return nil
+	})
+}
+
+// Batch 一组原子写入, 要么全部生效要么全部不生效
+type Batch struct {
+	ops []op
+	now time.Time
+}
+
+// Set 同DB.Set
+func (b *Batch) Set(key string, value []byte, ttl time.Duration) {
+	o := op{Key: key, Value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		o.ExpireAt = b.now.Add(ttl).UnixNano()
+	}
+	b.ops = append(b.ops, o)
+}
+
+// Delete 同DB.Delete
+func (b *Batch) Delete(key string) {
+	b.ops = append(b.ops, op{Del: true, Key: key})
+}
+
+// Batch 原子地执行fn中的写入, fn返回错误时不写入任何数据; fn执行期间持有写锁, 不能在fn中调用DB的方法
+func (db *DB) Batch(fn func(b *Batch) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return ErrClosed
+	}
+	b := &Batch{now: db.now()}
+	if err := fn(b); err != nil {
+		return err
+	}
+	if len(b.ops) == 0 {
+		return nil
+	}
+	if err := db.write(record{Ops: b.ops}); err != nil {
+		return err
+	}
+	db.apply(b.ops)
+
+	if db.compactThreshold > 0 && db.garbage > db.compactThreshold && db.garbage > len(db.data) {
+		if err := db.compact(); err != nil {
+			log.Warnw("msg", "kvlite: auto compact failed", "path", db.path, "err", err)
+		}
+	}
+	return nil
+}
+
+func (db *DB) write(rec record) error {
+	buf, err := encodeRecord(rec)
+	if err != nil {
+		return err
+	}
+	off, err := db.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("kvlite: write: %w", err)
+	}
+	if _, err := db.f.Write(buf); err != nil {
+		return db.rollback(off, fmt.Errorf("kvlite: write: %w", err))
+	}
+	if db.sync {
+		if err := db.f.Sync(); err != nil {
+			return db.rollback(off, fmt.Errorf("kvlite: sync: %w", err))
+		}
+	}
+	return nil
+}
+
+// rollback 写入失败时把文件截回写入前的位置, 避免写了一半的记录挡住之后的写入, 导致重启回放时被一起丢弃
+func (db *DB) rollback(off int64, err error) error {
+	if terr := db.f.Truncate(off); terr != nil {
+		return fmt.Errorf("%w (rollback: %v)", err, terr)
+	}
+	if _, serr := db.f.Seek(off, io.SeekStart); serr != nil {
+		return fmt.Errorf("%w (rollback: %v)", err, serr)
+	}
+	return err
+}
+
+// Scan 按key的字典序遍历前缀为prefix的未过期键值, fn返回false时停止; 遍历期间持有读锁, 不能在fn中写入
+func (db *DB) Scan(prefix string, fn func(key string, value []byte) bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	for _, k := range db.keys(prefix) {
+		if !fn(k, db.data[k].value) {
+			return
+		}
+	}
+}
+
+// Keys 前缀为prefix的未过期key, 按字典序排序
+func (db *DB) Keys(prefix string) []string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.keys(prefix)
+}
+
+func (db *DB) keys(prefix string) []string {
+	var keys []string
+	for k, e := range db.data {
+		if strings.HasPrefix(k, prefix) && !db.expired(e) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Len 未过期的key数量
+func (db *DB) Len() int {
+	return len(db.Keys(""))
+}
+
+// writeOps 将ops编码后写入w, 超过单条记录的最大长度时对半拆分
+func writeOps(w io.Writer, ops []op) error {
+	buf, err := encodeRecord(record{Ops: ops})
+	if errors.Is(err, ErrTooLarge) && len(ops) > 1 {
+		if err := writeOps(w, ops[:len(ops)/2]); err != nil {
+			return err
+		}
+		return writeOps(w, ops[len(ops)/2:])
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(buf)
+	return err
+}
+
+// Compact 重写日志, 只保留未过期的数据
+func (db *DB) Compact() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return ErrClosed
+	}
+	return db.compact()
+}
+
+func (db *DB) compact() error {
+	var ops []op
+	for k, e := range db.data {
+		if db.expired(e) {
+			delete(db.data, k)
+			continue
+		}
+		ops = append(ops, op{Key: k, Value: e.value, ExpireAt: e.expireAt})
+	}
+
+	tmp := db.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	// 整个文件通过rename原子替换, 分批写入只是为了限制单条记录的大小
+	w := bufio.NewWriter(f)
+	for start := 0; start < len(ops); start += compactBatch {
+		err := writeOps(w, ops[start:min(start+compactBatch, len(ops))])
+		if err != nil {
+			f.Close()
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, db.path); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if d, err := os.Open(filepath.Dir(db.path)); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+	db.f.Close()
+	db.f = f
+	db.garbage = 0
+	return nil
+}
+
+// Close 关闭数据文件
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return nil
+	}
+	db.closed = true
+	return db.f.Close()
+}
@@ -0,0 +1,190 @@
+package kvlite
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func openTemp(t *testing.T, opts ...Option) (*DB, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "state", "kv.db")
+	db, err := Open(path, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, path
+}
+
+func TestPersistence(t *testing.T) {
+	db, path := openTemp(t)
+	db.Set("user:1", []byte("jack"), 0)
+	db.Set("user:2", []byte("rose"), 0)
+	db.Set("user:1", []byte("jack2"), 0)
+	db.Delete("user:2")
+	db.Set("cfg:mode", []byte("fast"), 0)
+	db.Close()
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	tests := []struct {
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{key: "user:1", want: "jack2", wantOK: true},
+		{key: "user:2", wantOK: false},
+		{key: "cfg:mode", want: "fast", wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			v, ok := db.Get(tt.key)
+			if ok != tt.wantOK || string(v) != tt.want {
+				t.Errorf("Get(%q) = %q, %v, want %q, %v", tt.key, v, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTTL(t *testing.T) {
+	db, path := openTemp(t)
+	now := time.Now()
+	db.now = func() time.Time { return now }
+	db.Set("session", []byte("x"), time.Minute)
+	db.Set("forever", []byte("y"), 0)
+
+	if ttl, ok := db.TTL("session"); !ok || ttl != time.Minute {
+		t.Errorf("TTL() = %v, %v", ttl, ok)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, ok := db.Get("session"); ok {
+		t.Errorf("expired key still readable")
+	}
+	if db.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", db.Len())
+	}
+	db.Close()
+
+	// 过期时间是绝对时间, 重启后依然过期
+	db, _ = Open(path)
+	defer db.Close()
+	db.now = func() time.Time { return now }
+	if _, ok := db.Get("session"); ok {
+		t.Errorf("expired key readable after reopen")
+	}
+}
+
+func TestBatchAndCorruption(t *testing.T) {
+	db, path := openTemp(t)
+	db.Set("a", []byte("1"), 0)
+	err := db.Batch(func(b *Batch) error {
+		b.Set("b", []byte("2"), 0)
+		return errors.New("abort")
+	})
+	if err == nil {
+		t.Fatal("Batch() should return fn error")
+	}
+	if _, ok := db.Get("b"); ok {
+		t.Errorf("aborted batch applied")
+	}
+	db.Batch(func(b *Batch) error {
+		b.Set("b", []byte("2"), 0)
+		b.Set("c", []byte("3"), 0)
+		b.Delete("a")
+		return nil
+	})
+	db.Close()
+
+	// 模拟崩溃时只写入了下一个批次的一部分
+	buf, _ := encodeRecord(record{Ops: []op{{Key: "d", Value: []byte("4")}, {Key: "e", Value: []byte("5")}}})
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.Write(buf[:len(buf)-3])
+	f.Close()
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := db.Keys(""); !reflect.DeepEqual(got, []string{"b", "c"}) {
+		t.Errorf("Keys() = %v, want [b c]", got)
+	}
+	db.Set("f", []byte("6"), 0)
+	db.Close()
+
+	db, _ = Open(path)
+	defer db.Close()
+	if _, ok := db.Get("f"); !ok {
+		t.Errorf("write after truncated tail was lost")
+	}
+}
+
+func TestScanAndCompact(t *testing.T) {
+	db, path := openTemp(t, WithSync(false), WithAutoCompact(50))
+	for i := 0; i < 100; i++ {
+		db.Set(fmt.Sprintf("k:%03d", i%10), []byte(fmt.Sprint(i)), 0)
+	}
+	db.Set("other", nil, 0)
+
+	var keys []string
+	db.Scan("k:", func(key string, value []byte) bool {
+		keys = append(keys, key)
+		return len(keys) < 3
+	})
+	if !reflect.DeepEqual(keys, []string{"k:000", "k:001", "k:002"}) {
+		t.Errorf("Scan() = %v", keys)
+	}
+
+	info, _ := os.Stat(path)
+	if err := db.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := os.Stat(path)
+	if after.Size() > info.Size() {
+		t.Errorf("Compact() grew file from %d to %d", info.Size(), after.Size())
+	}
+	db.Set("k:new", []byte("x"), 0)
+	db.Close()
+
+	db, _ = Open(path)
+	defer db.Close()
+	if v, _ := db.Get("k:009"); string(v) != "99" || db.Len() != 12 {
+		t.Errorf("after compact Get() = %s, Len() = %d", v, db.Len())
+	}
+}
+
+func TestTooLarge(t *testing.T) {
+	defer func(n int) { maxRecordSize = n }(maxRecordSize)
+	maxRecordSize = 64
+
+	db, path := openTemp(t)
+	if err := db.Set("big", make([]byte, 64), 0); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Set() err = %v, want ErrTooLarge", err)
+	}
+	if _, ok := db.Get("big"); ok {
+		t.Errorf("rejected batch applied")
+	}
+	// 单个key能放进一条记录, 多个key需要压缩时拆分成多条记录
+	for _, k := range []string{"a", "b", "c"} {
+		if err := db.Set(k, []byte("0123456789"), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db, _ = Open(path)
+	defer db.Close()
+	if got := db.Keys(""); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("Keys() = %v, want [a b c]", got)
+	}
+}
@@ -0,0 +1,73 @@
+package kvlite
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// 日志记录格式: [crc32(4字节)][payload长度(4字节)][payload]
+// payload为一个批次的JSON, 一个批次是一次原子写入, 校验失败的批次(崩溃时写了一半)整体丢弃
+const headerSize = 8
+
+// maxRecordSize 单条记录的最大长度, 防止损坏的长度字段导致分配过大内存; 写入时超过该长度的批次直接拒绝
+var maxRecordSize = 256 << 20
+
+var errCorrupted = errors.New("kvlite: corrupted record")
+
+type op struct {
+	Del      bool   `json:"d,omitempty"`
+	Key      string `json:"k"`
+	Value    []byte `json:"v,omitempty"`
+	ExpireAt int64  `json:"e,omitempty"` // UnixNano, 0表示不过期
+}
+
+type record struct {
+	Ops []op `json:"ops"`
+}
+
+func encodeRecord(rec record) ([]byte, error) {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > maxRecordSize {
+		return nil, ErrTooLarge
+	}
+	buf := make([]byte, headerSize+len(payload))
+	binary.LittleEndian.PutUint32(buf[0:4], crc32.ChecksumIEEE(payload))
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(len(payload)))
+	copy(buf[headerSize:], payload)
+	return buf, nil
+}
+
+// readRecord 读取下一条记录, 返回记录占用的字节数; 文件结尾返回io.EOF, 不完整或损坏的记录返回errCorrupted
+func readRecord(r *bufio.Reader) (record, int, error) {
+	var rec record
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if err == io.EOF {
+			return rec, 0, io.EOF
+		}
+		return rec, 0, errCorrupted
+	}
+	sum := binary.LittleEndian.Uint32(header[0:4])
+	size := binary.LittleEndian.Uint32(header[4:8])
+	if int(size) > maxRecordSize {
+		return rec, 0, errCorrupted
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return rec, 0, errCorrupted
+	}
+	if crc32.ChecksumIEEE(payload) != sum {
+		return rec, 0, errCorrupted
+	}
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return rec, 0, errCorrupted
+	}
+	return rec, headerSize + int(size), nil
+}
@@ -0,0 +1,186 @@
+package leaderelect
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ChangSZ/golib/log"
+	"github.com/ChangSZ/golib/meta"
+)
+
+// Elector 主节点选举, 保证单例任务只在一个实例上运行
+type Elector interface {
+	// Campaign 参与选举, 阻塞到成为主节点或ctx结束; 之后在后台续期, 失去租约后自动重新参选
+	Campaign(ctx context.Context) error
+	// Resign 主动放弃并停止参选
+	Resign(ctx context.Context) error
+	// IsLeader 当前是否为主节点
+	IsLeader() bool
+	// Notify 主节点身份变化的通知, true表示成为主节点; 消费不及时时只保留最新状态
+	Notify() <-chan bool
+}
+
+var _ Elector = (*LeaseElector)(nil)
+
+type Config struct {
+	Key           string        `toml:"key"`           // 租约key, 同一组实例使用相同的key
+	ID            string        `toml:"id"`            // 实例标识, 默认为hostname-pid-随机串
+	TTL           time.Duration `toml:"ttl"`           // 租约有效期, 默认15s
+	RenewInterval time.Duration `toml:"renewInterval"` // 续期间隔, 默认TTL/3
+	RetryInterval time.Duration `toml:"retryInterval"` // 未当选时的重试间隔, 默认TTL/3
+}
+
+// LeaseElector 基于租约的选举, 租约过期前续期失败即视为失去主节点身份
+type LeaseElector struct {
+	cfg   Config
+	lease Lease
+
+	mu      sync.Mutex
+	leader  bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+	elected chan struct{}
+	notify  chan bool
+}
+
+// New new a LeaseElector.
+func New(lease Lease, cfg Config) *LeaseElector {
+	if cfg.ID == "" {
+		host, _ := os.Hostname()
+		cfg.ID = fmt.Sprintf("%s-%d-%s", host, os.Getpid(), meta.NewRequestID()[:8])
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 15 * time.Second
+	}
+	if cfg.RenewInterval <= 0 {
+		cfg.RenewInterval = cfg.TTL / 3
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = cfg.TTL / 3
+	}
+	return &LeaseElector{
+		cfg:    cfg,
+		lease:  lease,
+		notify: make(chan bool, 1),
+	}
+}
+
+// ID 实例标识
+func (e *LeaseElector) ID() string {
+	return e.cfg.ID
+}
+
+func (e *LeaseElector) Campaign(ctx context.Context) error {
+	e.mu.Lock()
+	if e.cancel == nil {
+		loopCtx, cancel := context.WithCancel(context.Background())
+		e.cancel = cancel
+		e.done = make(chan struct{})
+		e.elected = make(chan struct{})
+		go e.loop(loopCtx, e.done)
+	}
+	elected := e.elected
+	e.mu.Unlock()
+
+	select {
+	case <-elected:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *LeaseElector) Resign(ctx context.Context) error {
+	e.mu.Lock()
+	cancel, done := e.cancel, e.done
+	e.cancel = nil
+	e.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	<-done
+	return e.lease.Release(ctx, e.cfg.Key, e.cfg.ID)
+}
+
+func (e *LeaseElector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+func (e *LeaseElector) Notify() <-chan bool {
+	return e.notify
+}
+
+// loop 未当选时按RetryInterval尝试占有, 当选后按RenewInterval续期
+func (e *LeaseElector) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	defer e.setLeader(false)
+
+	// lastRenew 最近一次成功占有或续期的请求发起时间; 服务端的租约最早可能从请求发出时算起,
+	// 所以本地以发起时间计算租约, 请求本身的耗时不能延长本地认为的有效期
+	var lastRenew time.Time
+	for {
+		var interval time.Duration
+		start := time.Now()
+		if e.IsLeader() {
+			ok, err := e.lease.Renew(ctx, e.cfg.Key, e.cfg.ID, e.cfg.TTL)
+			switch {
+			case ok:
+				lastRenew = start
+			case err != nil && time.Since(lastRenew) < e.cfg.TTL:
+				// 网络抖动, 租约未过期前继续尝试
+				log.Warnw("msg", "leaderelect: renew failed", "key", e.cfg.Key, "err", err)
+			default:
+				log.Warnw("msg", "leaderelect: lost leadership", "key", e.cfg.Key, "id", e.cfg.ID, "err", err)
+				e.setLeader(false)
+			}
+			interval = e.cfg.RenewInterval
+		} else {
+			ok, err := e.lease.Acquire(ctx, e.cfg.Key, e.cfg.ID, e.cfg.TTL)
+			if err != nil && ctx.Err() == nil {
+				log.Warnw("msg", "leaderelect: acquire failed", "key", e.cfg.Key, "err", err)
+			}
+			if ok {
+				lastRenew = start
+				log.Infow("msg", "leaderelect: elected", "key", e.cfg.Key, "id", e.cfg.ID)
+				e.setLeader(true)
+				interval = e.cfg.RenewInterval
+			} else {
+				interval = e.cfg.RetryInterval
+			}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (e *LeaseElector) setLeader(leader bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.leader == leader {
+		return
+	}
+	e.leader = leader
+	if leader {
+		close(e.elected)
+	} else {
+		e.elected = make(chan struct{})
+	}
+	// 只保留最新状态
+	select {
+	case <-e.notify:
+	default:
+	}
+	e.notify <- leader
+}
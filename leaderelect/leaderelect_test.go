@@ -0,0 +1,115 @@
+package leaderelect
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newElector(lease Lease, id string) *LeaseElector {
+	return New(lease, Config{Key: "job", ID: id, TTL: 60 * time.Millisecond, RenewInterval: 10 * time.Millisecond, RetryInterval: 10 * time.Millisecond})
+}
+
+func TestCampaign(t *testing.T) {
+	lease := NewMemoryLease()
+	a, b := newElector(lease, "a"), newElector(lease, "b")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := a.Campaign(ctx); err != nil {
+		t.Fatalf("a.Campaign() error = %v", err)
+	}
+	if !a.IsLeader() || !<-a.Notify() {
+		t.Fatalf("a should be leader")
+	}
+
+	short, cancelShort := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancelShort()
+	if err := b.Campaign(short); err == nil || b.IsLeader() {
+		t.Fatalf("b.Campaign() should time out while a holds the lease")
+	}
+
+	// a放弃后b当选
+	if err := a.Resign(ctx); err != nil {
+		t.Fatalf("a.Resign() error = %v", err)
+	}
+	if a.IsLeader() {
+		t.Errorf("a still leader after Resign")
+	}
+	if err := b.Campaign(ctx); err != nil || lease.Holder("job") != "b" {
+		t.Fatalf("b.Campaign() error = %v, holder = %s", err, lease.Holder("job"))
+	}
+	b.Resign(ctx)
+}
+
+func TestLostLease(t *testing.T) {
+	lease := NewMemoryLease()
+	a := newElector(lease, "a")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := a.Campaign(ctx); err != nil {
+		t.Fatal(err)
+	}
+	<-a.Notify()
+
+	// 模拟租约被其他实例抢占
+	lease.mu.Lock()
+	lease.leases["job"] = memoryEntry{holder: "other", expireAt: time.Now().Add(time.Hour)}
+	lease.mu.Unlock()
+
+	select {
+	case leader := <-a.Notify():
+		if leader || a.IsLeader() {
+			t.Errorf("expected leadership lost")
+		}
+	case <-ctx.Done():
+		t.Fatal("leadership loss not notified")
+	}
+	a.Resign(ctx)
+	if lease.Holder("job") != "other" {
+		t.Errorf("Resign() released a lease it does not hold")
+	}
+}
+
+// slowLease Acquire很慢, Renew总是失败
+type slowLease struct {
+	*MemoryLease
+	renews atomic.Int32
+}
+
+func (l *slowLease) Acquire(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	time.Sleep(2 * ttl)
+	return l.MemoryLease.Acquire(ctx, key, holder, ttl)
+}
+
+func (l *slowLease) Renew(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	l.renews.Add(1)
+	return false, errors.New("unavailable")
+}
+
+func TestLeaseMeasuredFromRequest(t *testing.T) {
+	lease := &slowLease{MemoryLease: NewMemoryLease()}
+	a := newElector(lease, "a")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := a.Campaign(ctx); err != nil {
+		t.Fatal(err)
+	}
+	<-a.Notify()
+
+	// 租约从Acquire发起时算起, Acquire返回时已经过期, 第一次续期失败就应放弃
+	select {
+	case leader := <-a.Notify():
+		if leader {
+			t.Fatalf("expected leadership lost")
+		}
+	case <-ctx.Done():
+		t.Fatal("leadership loss not notified")
+	}
+	if n := lease.renews.Load(); n != 1 {
+		t.Errorf("renew attempts = %d, want 1", n)
+	}
+	a.Resign(ctx)
+}
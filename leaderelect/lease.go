@@ -0,0 +1,118 @@
+package leaderelect
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Lease 带过期时间的租约存储, 同一key同时只有一个holder
+type Lease interface {
+	// Acquire key不存在时以holder占有, 返回是否成功
+	Acquire(ctx context.Context, key, holder string, ttl time.Duration) (bool, error)
+	// Renew holder仍持有key时续期, 返回是否仍持有
+	Renew(ctx context.Context, key, holder string, ttl time.Duration) (bool, error)
+	// Release holder持有key时释放
+	Release(ctx context.Context, key, holder string) error
+}
+
+var (
+	_ Lease = (*RedisLease)(nil)
+	_ Lease = (*MemoryLease)(nil)
+)
+
+var (
+	renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// RedisLease 基于Redis的租约, 使用SET NX PX占有, Lua脚本校验holder后续期和释放
+type RedisLease struct {
+	client redis.Cmdable
+}
+
+// NewRedisLease new a RedisLease, client可以是*redis.Client、*redis.ClusterClient等
+func NewRedisLease(client redis.Cmdable) *RedisLease {
+	return &RedisLease{client: client}
+}
+
+func (l *RedisLease) Acquire(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	return l.client.SetNX(ctx, key, holder, ttl).Result()
+}
+
+func (l *RedisLease) Renew(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	n, err := renewScript.Run(ctx, l.client, []string{key}, holder, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+func (l *RedisLease) Release(ctx context.Context, key, holder string) error {
+	return releaseScript.Run(ctx, l.client, []string{key}, holder).Err()
+}
+
+// MemoryLease 进程内的租约, 用于测试和单机部署
+type MemoryLease struct {
+	mu     sync.Mutex
+	leases map[string]memoryEntry
+	now    func() time.Time
+}
+
+type memoryEntry struct {
+	holder   string
+	expireAt time.Time
+}
+
+// NewMemoryLease new a MemoryLease.
+func NewMemoryLease() *MemoryLease {
+	return &MemoryLease{leases: make(map[string]memoryEntry), now: time.Now}
+}
+
+func (l *MemoryLease) Acquire(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.leases[key]; ok && l.now().Before(e.expireAt) {
+		return false, nil
+	}
+	l.leases[key] = memoryEntry{holder: holder, expireAt: l.now().Add(ttl)}
+	return true, nil
+}
+
+func (l *MemoryLease) Renew(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.leases[key]
+	if !ok || e.holder != holder || !l.now().Before(e.expireAt) {
+		return false, nil
+	}
+	e.expireAt = l.now().Add(ttl)
+	l.leases[key] = e
+	return true, nil
+}
+
+func (l *MemoryLease) Release(ctx context.Context, key, holder string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.leases[key]; ok && e.holder == holder {
+		delete(l.leases, key)
+	}
+	return nil
+}
+
+// Holder 当前持有者, 用于测试
+func (l *MemoryLease) Holder(key string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.leases[key]; ok && l.now().Before(e.expireAt) {
+		return e.holder
+	}
+	return ""
+}
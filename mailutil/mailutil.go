@@ -0,0 +1,274 @@
+package mailutil
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/gomail.v2"
+
+	"github.com/ChangSZ/golib/log"
+	"github.com/ChangSZ/golib/templatex"
+)
+
+type Config struct {
+	Host               string        `toml:"host"`               // SMTP服务器, 如smtp.qq.com
+	Port               int           `toml:"port"`               // 默认465
+	Username           string        `toml:"username"`           // 登录账号
+	Password           string        `toml:"password"`           // 密码或授权码
+	From               string        `toml:"from"`               // 默认发件人, 可以写作"名称 <addr>", 默认为Username
+	SSL                bool          `toml:"ssl"`                // 使用隐式TLS, 端口465时自动开启; 其他端口在服务端支持时使用STARTTLS
+	InsecureSkipVerify bool          `toml:"insecureSkipVerify"` // 跳过证书校验, 仅用于内网自签名证书
+	PoolSize           int           `toml:"poolSize"`           // 最大连接数, 默认2
+	IdleTimeout        time.Duration `toml:"idleTimeout"`        // 空闲连接的最长保留时间, 默认30s
+	DryRun             bool          `toml:"dryRun"`             // 只打印日志不发送, 用于开发环境
+}
+
+// Attachment 附件或内嵌图片, Data和Path二选一
+type Attachment struct {
+	Name        string // 文件名, 内嵌图片在HTML中以cid:Name引用
+	ContentType string // 为空时按文件名推断
+	Data        []byte
+	Path        string
+}
+
+// Message 一封邮件
+type Message struct {
+	From        string // 为空时使用Config.From
+	To          []string
+	Cc          []string
+	Bcc         []string
+	ReplyTo     string
+	Subject     string
+	Text        string // 纯文本正文
+	HTML        string // HTML正文, 与Text同时存在时作为multipart/alternative发送
+	Template    string // 模板名, 非空时渲染已注册的<Template>.subject、<Template>.text、<Template>.html并覆盖对应字段
+	Data        interface{}
+	Attachments []Attachment
+	Inline      []Attachment
+	Headers     map[string]string
+}
+
+// Option Mailer的可选参数
+type Option func(*Mailer)
+
+// WithTemplates 设置渲染Message.Template使用的模板引擎
+//
+// 模板基于text/template, HTML模板中插入用户输入时请使用内置的html函数转义, 如{{.Name | html}}
+func WithTemplates(e *templatex.Engine) Option {
+	return func(m *Mailer) {
+		m.templates = e
+	}
+}
+
+// WithTransport 替换发送方式, 测试中可以使用MemoryTransport或FileTransport
+func WithTransport(t Transport) Option {
+	return func(m *Mailer) {
+		m.transport = t
+	}
+}
+
+// Mailer 邮件发送器, 可以并发使用
+//
+//	m := mailutil.New(cfg, mailutil.WithTemplates(engine))
+//	defer m.Close()
+//	err := m.Send(ctx, &mailutil.Message{To: []string{"a@example.com"}, Template: "welcome", Data: user})
+type Mailer struct {
+	cfg       Config
+	templates *templatex.Engine
+	transport Transport
+}
+
+// New new a Mailer.
+func New(cfg Config, opts ...Option) *Mailer {
+	if cfg.Port == 0 {
+		cfg.Port = 465
+	}
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 2
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = 30 * time.Second
+	}
+	m := &Mailer{cfg: cfg}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.transport == nil {
+		if cfg.DryRun {
+			m.transport = dryRunTransport{}
+		} else {
+			m.transport = newSMTPTransport(cfg)
+		}
+	}
+	return m
+}
+
+// Send 渲染并发送邮件
+func (m *Mailer) Send(ctx context.Context, msg *Message) error {
+	if err := m.render(msg); err != nil {
+		return err
+	}
+	if len(msg.To)+len(msg.Cc)+len(msg.Bcc) == 0 {
+		return errors.New("mailutil: no recipients")
+	}
+	if msg.Text == "" && msg.HTML == "" {
+		return errors.New("mailutil: empty body")
+	}
+	gm, err := m.build(msg)
+	if err != nil {
+		return err
+	}
+	return gomail.Send(gomail.SendFunc(func(from string, to []string, w io.WriterTo) error {
+		return m.transport.Send(ctx, from, to, w)
+	}), gm)
+}
+
+// Close 关闭连接池
+func (m *Mailer) Close() error {
+	return m.transport.Close()
+}
+
+func (m *Mailer) render(msg *Message) error {
+	if msg.Template == "" {
+		return nil
+	}
+	if m.templates == nil {
+		return errors.New("mailutil: template engine not configured")
+	}
+	found := false
+	for _, f := range []struct {
+		suffix string
+		dst    *string
+	}{{"subject", &msg.Subject}, {"text", &msg.Text}, {"html", &msg.HTML}} {
+		name := msg.Template + "." + f.suffix
+		if !m.templates.Has(name) {
+			continue
+		}
+		s, err := m.templates.Render(name, msg.Data)
+		if err != nil {
+			return err
+		}
+		*f.dst = s
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("mailutil: template %s not found", msg.Template)
+	}
+	msg.Subject = strings.TrimSpace(msg.Subject)
+	return nil
+}
+
+func (m *Mailer) build(msg *Message) (*gomail.Message, error) {
+	gm := gomail.NewMessage()
+	from := msg.From
+	if from == "" {
+		from = m.cfg.From
+	}
+	gm.SetHeader("From", from)
+	if len(msg.To) > 0 {
+		gm.SetHeader("To", msg.To...)
+	}
+	if len(msg.Cc) > 0 {
+		gm.SetHeader("Cc", msg.Cc...)
+	}
+	if len(msg.Bcc) > 0 {
+		gm.SetHeader("Bcc", msg.Bcc...)
+	}
+	if msg.ReplyTo != "" {
+		gm.SetHeader("Reply-To", msg.ReplyTo)
+	}
+	for k, v := range msg.Headers {
+		gm.SetHeader(k, v)
+	}
+	gm.SetHeader("Subject", msg.Subject)
+	gm.SetDateHeader("Date", time.Now())
+
+	switch {
+	case msg.Text != "" && msg.HTML != "":
+		gm.SetBody("text/plain", msg.Text)
+		gm.AddAlternative("text/html", msg.HTML)
+	case msg.HTML != "":
+		gm.SetBody("text/html", msg.HTML)
+	default:
+		gm.SetBody("text/plain", msg.Text)
+	}
+
+	for _, a := range msg.Inline {
+		settings, name, err := fileSettings(a)
+		if err != nil {
+			return nil, err
+		}
+		gm.Embed(name, settings...)
+	}
+	for _, a := range msg.Attachments {
+		settings, name, err := fileSettings(a)
+		if err != nil {
+			return nil, err
+		}
+		gm.Attach(name, settings...)
+	}
+	return gm, nil
+}
+
+// fileSettings 附件统一以内存数据或指定路径写入, 文件名与路径无关
+func fileSettings(a Attachment) ([]gomail.FileSetting, string, error) {
+	name := a.Name
+	if name == "" {
+		name = filepath.Base(a.Path)
+	}
+	if name == "" || name == "." {
+		return nil, "", errors.New("mailutil: attachment name is required")
+	}
+	var settings []gomail.FileSetting
+	switch {
+	case a.Data != nil:
+		data := a.Data
+		settings = append(settings, gomail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		}))
+	case a.Path != "":
+		path := a.Path
+		if _, err := os.Stat(path); err != nil {
+			return nil, "", fmt.Errorf("mailutil: attachment %s: %w", name, err)
+		}
+		settings = append(settings, gomail.SetCopyFunc(func(w io.Writer) error {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(w, f)
+			return err
+		}))
+	default:
+		return nil, "", fmt.Errorf("mailutil: attachment %s has no data", name)
+	}
+	if a.ContentType != "" {
+		settings = append(settings, gomail.SetHeader(map[string][]string{"Content-Type": {a.ContentType}}))
+	}
+	return settings, name, nil
+}
+
+func tlsConfig(cfg Config) *tls.Config {
+	return &tls.Config{ServerName: cfg.Host, InsecureSkipVerify: cfg.InsecureSkipVerify}
+}
+
+type dryRunTransport struct{}
+
+func (dryRunTransport) Send(ctx context.Context, from string, to []string, _ io.WriterTo) error {
+	log.Context(ctx).Infow("msg", "mailutil: dry run, mail not sent", "from", from, "to", to)
+	return nil
+}
+
+func (dryRunTransport) Close() error { return nil }
@@ -0,0 +1,189 @@
+package mailutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/gomail.v2"
+
+	"github.com/ChangSZ/golib/templatex"
+)
+
+func TestMailerSend(t *testing.T) {
+	engine := templatex.New()
+	_ = engine.Register("welcome.subject", "欢迎 {{.Name}}")
+	_ = engine.Register("welcome.text", "Hi {{.Name}}")
+	_ = engine.Register("welcome.html", `<p>Hi {{.Name | html}}</p><img src="cid:logo.png">`)
+
+	mem := NewMemoryTransport()
+	m := New(Config{From: "noreply@example.com"}, WithTemplates(engine), WithTransport(mem))
+	err := m.Send(context.Background(), &Message{
+		To:          []string{"jack@example.com"},
+		Bcc:         []string{"audit@example.com"},
+		Template:    "welcome",
+		Data:        map[string]string{"Name": "<Jack>"},
+		Inline:      []Attachment{{Name: "logo.png", Data: []byte("png")}},
+		Attachments: []Attachment{{Name: "report.csv", ContentType: "text/csv", Data: []byte("a,b\n1,2\n")}},
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	sent := mem.Sent()
+	if len(sent) != 1 || sent[0].From != "noreply@example.com" || len(sent[0].To) != 2 {
+		t.Fatalf("Sent() = %+v", sent)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(string(sent[0].Raw)))
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "欢迎 <Jack>" || msg.Header.Get("Bcc") != "" {
+		t.Errorf("Subject = %q, Bcc = %q", subject, msg.Header.Get("Bcc"))
+	}
+
+	// 收集所有叶子part的Content-Type和内容
+	parts := map[string]string{}
+	var walk func(r io.Reader, boundary string)
+	walk = func(r io.Reader, boundary string) {
+		mr := multipart.NewReader(r, boundary)
+		for {
+			p, err := mr.NextPart()
+			if err != nil {
+				return
+			}
+			ct, params, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+			if strings.HasPrefix(ct, "multipart/") {
+				walk(p, params["boundary"])
+				continue
+			}
+			b, _ := io.ReadAll(p)
+			parts[ct] = string(b)
+		}
+	}
+	_, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	walk(msg.Body, params["boundary"])
+
+	if parts["text/plain"] != "Hi <Jack>" || !strings.Contains(parts["text/html"], "Hi &lt;Jack&gt;") {
+		t.Errorf("bodies = %q", parts)
+	}
+	if _, ok := parts["image/png"]; !ok {
+		t.Errorf("inline image missing: %v", parts)
+	}
+	if _, ok := parts["text/csv"]; !ok {
+		t.Errorf("attachment missing: %v", parts)
+	}
+}
+
+func TestMailerValidate(t *testing.T) {
+	m := New(Config{From: "a@example.com"}, WithTransport(NewMemoryTransport()))
+	tests := []struct {
+		name string
+		msg  *Message
+	}{
+		{name: "no recipients", msg: &Message{Text: "hi"}},
+		{name: "empty body", msg: &Message{To: []string{"b@example.com"}}},
+		{name: "no template engine", msg: &Message{To: []string{"b@example.com"}, Template: "x"}},
+		{name: "missing attachment", msg: &Message{To: []string{"b@example.com"}, Text: "hi",
+			Attachments: []Attachment{{Path: "/not/exist.pdf"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := m.Send(context.Background(), tt.msg); err == nil {
+				t.Errorf("Send() want error")
+			}
+		})
+	}
+}
+
+func TestFileTransport(t *testing.T) {
+	dir := t.TempDir()
+	ft, err := NewFileTransport(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New(Config{From: "a@example.com"}, WithTransport(ft))
+	if err := m.Send(context.Background(), &Message{To: []string{"b@example.com"}, Subject: "s", Text: "hi"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("files = %v", files)
+	}
+	b, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(b), "To: b@example.com") {
+		t.Errorf("eml = %s", b)
+	}
+}
+
+type fakeConn struct {
+	sends  int
+	closed bool
+	fail   error
+}
+
+func (c *fakeConn) Send(string, []string, io.WriterTo) error {
+	if c.fail != nil {
+		return c.fail
+	}
+	c.sends++
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestSMTPPool(t *testing.T) {
+	var conns []*fakeConn
+	pool := newPool(func() (gomail.SendCloser, error) {
+		c := &fakeConn{}
+		conns = append(conns, c)
+		return c, nil
+	}, 1, time.Minute)
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	send := func() {
+		t.Helper()
+		if err := pool.Send(ctx, "a", []string{"b"}, strings.NewReader("")); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	send()
+	send()
+	if len(conns) != 1 || conns[0].sends != 2 {
+		t.Fatalf("connection not reused: %d conns", len(conns))
+	}
+
+	// 复用的连接已断开时换新连接重试
+	conns[0].fail = errors.New("broken pipe")
+	send()
+	if len(conns) != 2 || !conns[0].closed || conns[1].sends != 1 {
+		t.Errorf("broken connection not replaced: %+v", conns)
+	}
+
+	// 空闲超时的连接被丢弃
+	now = now.Add(2 * time.Minute)
+	send()
+	if len(conns) != 3 || !conns[1].closed {
+		t.Errorf("idle connection not closed: %d conns", len(conns))
+	}
+
+	_ = pool.Close()
+	if !conns[2].closed {
+		t.Errorf("Close() did not close idle connection")
+	}
+}
@@ -0,0 +1,195 @@
+package mailutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+var (
+	_ Transport = (*smtpTransport)(nil)
+	_ Transport = (*FileTransport)(nil)
+	_ Transport = (*MemoryTransport)(nil)
+)
+
+// Transport 投递已编码的邮件
+type Transport interface {
+	Send(ctx context.Context, from string, to []string, msg io.WriterTo) error
+	Close() error
+}
+
+// smtpTransport 复用SMTP连接, 最多同时保持PoolSize个连接
+type smtpTransport struct {
+	dial        func() (gomail.SendCloser, error)
+	slots       chan struct{}
+	idle        chan *pooledConn
+	idleTimeout time.Duration
+	now         func() time.Time
+}
+
+type pooledConn struct {
+	sc       gomail.SendCloser
+	lastUsed time.Time
+}
+
+func newSMTPTransport(cfg Config) *smtpTransport {
+	d := gomail.NewDialer(cfg.Host, cfg.Port, cfg.Username, cfg.Password)
+	d.SSL = cfg.SSL || cfg.Port == 465
+	d.TLSConfig = tlsConfig(cfg)
+	return newPool(d.Dial, cfg.PoolSize, cfg.IdleTimeout)
+}
+
+func newPool(dial func() (gomail.SendCloser, error), size int, idleTimeout time.Duration) *smtpTransport {
+	return &smtpTransport{
+		dial:        dial,
+		slots:       make(chan struct{}, size),
+		idle:        make(chan *pooledConn, size),
+		idleTimeout: idleTimeout,
+		now:         time.Now,
+	}
+}
+
+// Send 复用的连接可能已被服务端断开, 失败时换新连接重试一次
+func (t *smtpTransport) Send(ctx context.Context, from string, to []string, msg io.WriterTo) error {
+	select {
+	case t.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-t.slots }()
+
+	conn, reused, err := t.get()
+	if err != nil {
+		return err
+	}
+	err = conn.sc.Send(from, to, msg)
+	if err != nil && reused {
+		_ = conn.sc.Close()
+		if conn, err = t.newConn(); err != nil {
+			return err
+		}
+		err = conn.sc.Send(from, to, msg)
+	}
+	if err != nil {
+		_ = conn.sc.Close()
+		return fmt.Errorf("mailutil: send: %w", err)
+	}
+	conn.lastUsed = t.now()
+	select {
+	case t.idle <- conn:
+	default:
+		_ = conn.sc.Close()
+	}
+	return nil
+}
+
+// get 优先使用未超时的空闲连接
+func (t *smtpTransport) get() (*pooledConn, bool, error) {
+	for {
+		select {
+		case conn := <-t.idle:
+			if t.now().Sub(conn.lastUsed) > t.idleTimeout {
+				_ = conn.sc.Close()
+				continue
+			}
+			return conn, true, nil
+		default:
+			conn, err := t.newConn()
+			return conn, false, err
+		}
+	}
+}
+
+func (t *smtpTransport) newConn() (*pooledConn, error) {
+	sc, err := t.dial()
+	if err != nil {
+		return nil, fmt.Errorf("mailutil: dial: %w", err)
+	}
+	return &pooledConn{sc: sc}, nil
+}
+
+func (t *smtpTransport) Close() error {
+	for {
+		select {
+		case conn := <-t.idle:
+			_ = conn.sc.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// FileTransport 将邮件写入目录中的.eml文件, 用于测试和本地预览
+type FileTransport struct {
+	dir string
+	seq atomic.Uint64
+}
+
+// NewFileTransport new a FileTransport.
+func NewFileTransport(dir string) (*FileTransport, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileTransport{dir: dir}, nil
+}
+
+func (t *FileTransport) Send(_ context.Context, _ string, _ []string, msg io.WriterTo) error {
+	name := fmt.Sprintf("%s-%d.eml", time.Now().Format("20060102T150405.000"), t.seq.Add(1))
+	f, err := os.Create(filepath.Join(t.dir, name))
+	if err != nil {
+		return err
+	}
+	if _, err := msg.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (t *FileTransport) Close() error { return nil }
+
+// SentMail MemoryTransport记录的邮件
+type SentMail struct {
+	From string
+	To   []string // 包含抄送和密送
+	Raw  []byte   // 编码后的完整邮件
+}
+
+// MemoryTransport 将邮件保存在内存中, 用于单元测试
+type MemoryTransport struct {
+	mu   sync.Mutex
+	sent []SentMail
+}
+
+// NewMemoryTransport new a MemoryTransport.
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{}
+}
+
+func (t *MemoryTransport) Send(_ context.Context, from string, to []string, msg io.WriterTo) error {
+	var buf bytes.Buffer
+	if _, err := msg.WriteTo(&buf); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sent = append(t.sent, SentMail{From: from, To: append([]string(nil), to...), Raw: buf.Bytes()})
+	return nil
+}
+
+func (t *MemoryTransport) Close() error { return nil }
+
+// Sent 已发送的邮件
+func (t *MemoryTransport) Sent() []SentMail {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]SentMail(nil), t.sent...)
+}
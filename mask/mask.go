@@ -0,0 +1,109 @@
+package mask
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ChangSZ/golib/phoneutil"
+	"github.com/ChangSZ/golib/tagparse"
+)
+
+// TagName 脱敏标签, 如`mask:"phone"`, `mask:"secret"`, `mask:"partial,head=2,tail=2"`
+const TagName = "mask"
+
+// Placeholder secret规则的输出, 不暴露原始长度
+const Placeholder = "******"
+
+// Func 脱敏函数
+type Func func(s string) string
+
+var (
+	mu    sync.RWMutex
+	rules = map[string]Func{
+		"":       Secret,
+		"secret": Secret,
+		"phone":  phoneutil.Mask,
+		"email":  Email,
+		"name":   Name,
+		"idcard": func(s string) string { return Partial(s, 6, 4) },
+		"bank":   func(s string) string { return Partial(s, 4, 4) },
+	}
+)
+
+// Register 注册自定义规则, 同名规则会被覆盖
+func Register(kind string, fn Func) {
+	mu.Lock()
+	defer mu.Unlock()
+	rules[kind] = fn
+}
+
+// Mask 按规则脱敏, 未知规则按secret处理
+func Mask(kind, s string) string {
+	mu.RLock()
+	fn, ok := rules[kind]
+	mu.RUnlock()
+	if !ok {
+		fn = Secret
+	}
+	return fn(s)
+}
+
+// Secret 完全隐藏
+func Secret(s string) string {
+	if s == "" {
+		return ""
+	}
+	return Placeholder
+}
+
+// Partial 保留前head个和后tail个字符, 其余替换为*; 长度不足时全部替换
+func Partial(s string, head, tail int) string {
+	rs := []rune(s)
+	if head < 0 {
+		head = 0
+	}
+	if tail < 0 {
+		tail = 0
+	}
+	if head+tail >= len(rs) {
+		return strings.Repeat("*", len(rs))
+	}
+	return string(rs[:head]) + strings.Repeat("*", len(rs)-head-tail) + string(rs[len(rs)-tail:])
+}
+
+// Email 保留用户名首字符和域名, 如j***@example.com
+func Email(s string) string {
+	user, domain, ok := strings.Cut(s, "@")
+	if !ok {
+		return Partial(s, 1, 0)
+	}
+	rs := []rune(user)
+	if len(rs) == 0 {
+		return s
+	}
+	return string(rs[:1]) + "***@" + domain
+}
+
+// Name 保留姓名首字, 如张**
+func Name(s string) string {
+	return Partial(s, 1, 0)
+}
+
+// FieldFunc 根据字段的mask标签返回脱敏函数, 没有标签时ok为false
+//
+// partial规则通过head、tail参数指定保留的字符数
+func FieldFunc(field reflect.StructField) (Func, bool) {
+	tag, ok := tagparse.Lookup(field, TagName)
+	if !ok || tag.Skip {
+		return nil, false
+	}
+	if tag.Name == "partial" {
+		head, _ := strconv.Atoi(tag.Params["head"])
+		tail, _ := strconv.Atoi(tag.Params["tail"])
+		return func(s string) string { return Partial(s, head, tail) }, true
+	}
+	kind := tag.Name
+	return func(s string) string { return Mask(kind, s) }, true
+}
@@ -0,0 +1,63 @@
+package mask
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		kind string
+		in   string
+		want string
+	}{
+		{kind: "secret", in: "p@ss", want: "******"},
+		{kind: "secret", in: "", want: ""},
+		{kind: "unknown", in: "abc", want: "******"},
+		{kind: "phone", in: "13812345678", want: "138****5678"},
+		{kind: "email", in: "jack@example.com", want: "j***@example.com"},
+		{kind: "name", in: "张三丰", want: "张**"},
+		{kind: "idcard", in: "110101199003071234", want: "110101********1234"},
+		{kind: "bank", in: "123", want: "***"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind+"/"+tt.in, func(t *testing.T) {
+			if got := Mask(tt.kind, tt.in); got != tt.want {
+				t.Errorf("Mask(%q, %q) = %q, want %q", tt.kind, tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldFunc(t *testing.T) {
+	type user struct {
+		Token string `mask:""`
+		Card  string `mask:"partial,head=2,tail=1"`
+		Name  string
+		Skip  string `mask:"-"`
+	}
+	typ := reflect.TypeOf(user{})
+	tests := []struct {
+		field  string
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{field: "Token", in: "abc", want: "******", wantOK: true},
+		{field: "Card", in: "abcdef", want: "ab***f", wantOK: true},
+		{field: "Name", wantOK: false},
+		{field: "Skip", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			f, _ := typ.FieldByName(tt.field)
+			fn, ok := FieldFunc(f)
+			if ok != tt.wantOK {
+				t.Fatalf("FieldFunc() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && fn(tt.in) != tt.want {
+				t.Errorf("fn(%q) = %q, want %q", tt.in, fn(tt.in), tt.want)
+			}
+		})
+	}
+}
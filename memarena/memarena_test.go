@@ -0,0 +1,92 @@
+package memarena
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/ChangSZ/golib/copy"
+)
+
+type item struct {
+	ID   int64
+	Name string
+	Tags []string
+}
+
+type order struct {
+	ID    int64
+	Items []*item
+	Owner *item
+}
+
+func TestTypedAllocator(t *testing.T) {
+	size := int(unsafe.Sizeof(item{}))
+	a := New(WithChunkSize(size * 8))
+	items := NewTyped[item](a)
+
+	first, second := items.New(), items.New()
+	if uintptr(unsafe.Pointer(second))-uintptr(unsafe.Pointer(first)) != uintptr(size) {
+		t.Errorf("objects not allocated from the same chunk")
+	}
+
+	tests := []struct {
+		name     string
+		n, cap   int
+		wantHeap bool
+	}{
+		{name: "from chunk", n: 1, cap: 2},
+		{name: "too large", n: 3, cap: 3, wantHeap: true},
+		{name: "empty", n: 0, cap: 0, wantHeap: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := a.Stats().Allocs
+			s := items.MakeSlice(tt.n, tt.cap)
+			if len(s) != tt.n || cap(s) != tt.cap {
+				t.Fatalf("MakeSlice() len = %d, cap = %d", len(s), cap(s))
+			}
+			if fromChunk := a.Stats().Allocs > before; fromChunk == tt.wantHeap {
+				t.Errorf("from chunk = %v, want %v", fromChunk, !tt.wantHeap)
+			}
+		})
+	}
+
+	// 容量固定, append超过容量时不覆盖相邻的对象
+	s := items.MakeSlice(0, 1)
+	next := items.New()
+	next.Name = "next"
+	s = append(s, item{Name: "a"}, item{Name: "b"})
+	if next.Name != "next" || len(s) != 2 {
+		t.Errorf("append overwrote neighbour: %+v", next)
+	}
+
+	first.Name = "kept"
+	a.Release()
+	if first.Name != "kept" {
+		t.Errorf("object changed after Release")
+	}
+	if items.New() == nil || a.Stats().Chunks != 2 {
+		t.Errorf("Stats() = %+v, want a new chunk after Release", a.Stats())
+	}
+}
+
+func TestDeepCopyWithArena(t *testing.T) {
+	src := &order{
+		ID:    1,
+		Items: []*item{{ID: 1, Name: "a", Tags: []string{"x"}}, {ID: 2, Name: "b"}},
+		Owner: &item{ID: 9},
+	}
+	a := New()
+	got := copy.DeepCopyWithAllocator(src, a).(*order)
+	if !reflect.DeepEqual(got, src) {
+		t.Fatalf("DeepCopyWithAllocator() = %+v, want %+v", got, src)
+	}
+	if got == src || got.Items[0] == src.Items[0] || &got.Items[0].Tags[0] == &src.Items[0].Tags[0] {
+		t.Errorf("copy shares memory with src")
+	}
+	// order、3个item、Items和Tags两个切片
+	if s := a.Stats(); s.Allocs != 6 || s.Chunks != 4 {
+		t.Errorf("Stats() = %+v", s)
+	}
+}
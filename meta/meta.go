@@ -0,0 +1,164 @@
+package meta
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/ChangSZ/golib/ctxutil"
+)
+
+// HTTP头名称, gRPC metadata中使用对应的小写形式
+const (
+	HeaderRequestID = "X-Request-Id"
+	HeaderUserID    = "X-User-Id"
+	HeaderTenantID  = "X-Tenant-Id"
+	HeaderLocale    = "X-Locale"
+)
+
+// Meta 请求级别的元数据, 日志、鉴权、审计等组件从同一个载体读取
+type Meta struct {
+	RequestID string
+	UserID    string
+	TenantID  string
+	Locale    string
+}
+
+var metaKey = ctxutil.NewKey[Meta]("meta")
+
+// NewContext 将m存入ctx
+func NewContext(ctx context.Context, m Meta) context.Context {
+	return ctxutil.Set(ctx, metaKey, m)
+}
+
+// FromContext 从ctx读取Meta, 不存在时返回零值
+func FromContext(ctx context.Context) Meta {
+	if ctx == nil {
+		return Meta{}
+	}
+	m, _ := ctxutil.Get(ctx, metaKey)
+	return m
+}
+
+// RequestID 从ctx读取请求ID
+func RequestID(ctx context.Context) string { return FromContext(ctx).RequestID }
+
+// UserID 从ctx读取用户ID
+func UserID(ctx context.Context) string { return FromContext(ctx).UserID }
+
+// TenantID 从ctx读取租户ID
+func TenantID(ctx context.Context) string { return FromContext(ctx).TenantID }
+
+// Locale 从ctx读取语言区域
+func Locale(ctx context.Context) string { return FromContext(ctx).Locale }
+
+// WithRequestID 设置请求ID, 其它字段保持不变
+func WithRequestID(ctx context.Context, id string) context.Context {
+	m := FromContext(ctx)
+	m.RequestID = id
+	return NewContext(ctx, m)
+}
+
+// WithUserID 设置用户ID, 其它字段保持不变
+func WithUserID(ctx context.Context, id string) context.Context {
+	m := FromContext(ctx)
+	m.UserID = id
+	return NewContext(ctx, m)
+}
+
+// WithTenantID 设置租户ID, 其它字段保持不变
+func WithTenantID(ctx context.Context, id string) context.Context {
+	m := FromContext(ctx)
+	m.TenantID = id
+	return NewContext(ctx, m)
+}
+
+// WithLocale 设置语言区域, 其它字段保持不变
+func WithLocale(ctx context.Context, locale string) context.Context {
+	m := FromContext(ctx)
+	m.Locale = locale
+	return NewContext(ctx, m)
+}
+
+// NewRequestID 生成32位十六进制的随机请求ID
+func NewRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// fields Meta字段与头名称的对应关系
+func (m *Meta) fields() []struct {
+	header string
+	value  *string
+} {
+	return []struct {
+		header string
+		value  *string
+	}{
+		{HeaderRequestID, &m.RequestID},
+		{HeaderUserID, &m.UserID},
+		{HeaderTenantID, &m.TenantID},
+		{HeaderLocale, &m.Locale},
+	}
+}
+
+// InjectHeader 将ctx中的Meta写入HTTP头, 空字段不写入
+func InjectHeader(ctx context.Context, h http.Header) {
+	m := FromContext(ctx)
+	for _, f := range m.fields() {
+		if *f.value != "" {
+			h.Set(f.header, *f.value)
+		}
+	}
+}
+
+// ExtractHeader 从HTTP头读取Meta并存入ctx, 缺少X-Locale时使用Accept-Language的首选语言
+func ExtractHeader(ctx context.Context, h http.Header) context.Context {
+	m := FromContext(ctx)
+	for _, f := range m.fields() {
+		if v := h.Get(f.header); v != "" {
+			*f.value = v
+		}
+	}
+	if m.Locale == "" {
+		m.Locale = preferredLanguage(h.Get("Accept-Language"))
+	}
+	return NewContext(ctx, m)
+}
+
+// InjectMD 将ctx中的Meta写入gRPC metadata, 可以直接传入metadata.MD
+func InjectMD(ctx context.Context, md map[string][]string) {
+	m := FromContext(ctx)
+	for _, f := range m.fields() {
+		if *f.value != "" {
+			md[strings.ToLower(f.header)] = []string{*f.value}
+		}
+	}
+}
+
+// ExtractMD 从gRPC metadata读取Meta并存入ctx, 可以直接传入metadata.MD
+func ExtractMD(ctx context.Context, md map[string][]string) context.Context {
+	m := FromContext(ctx)
+	for _, f := range m.fields() {
+		if vs := md[strings.ToLower(f.header)]; len(vs) > 0 && vs[0] != "" {
+			*f.value = vs[0]
+		}
+	}
+	return NewContext(ctx, m)
+}
+
+// preferredLanguage 返回Accept-Language中第一个语言标签, 如"zh-CN,zh;q=0.9"返回"zh-CN"
+func preferredLanguage(accept string) string {
+	if accept == "" {
+		return ""
+	}
+	tag := strings.Split(accept, ",")[0]
+	tag = strings.TrimSpace(strings.Split(tag, ";")[0])
+	if tag == "*" {
+		return ""
+	}
+	return tag
+}
@@ -0,0 +1,72 @@
+package meta
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestHeaderRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   Meta
+	}{
+		{
+			name: "all fields",
+			header: http.Header{
+				"X-Request-Id": {"r1"},
+				"X-User-Id":    {"u1"},
+				"X-Tenant-Id":  {"t1"},
+				"X-Locale":     {"en-US"},
+			},
+			want: Meta{RequestID: "r1", UserID: "u1", TenantID: "t1", Locale: "en-US"},
+		},
+		{
+			name:   "locale from accept-language",
+			header: http.Header{"Accept-Language": {"zh-CN,zh;q=0.9,en;q=0.8"}},
+			want:   Meta{Locale: "zh-CN"},
+		},
+		{
+			name:   "wildcard language ignored",
+			header: http.Header{"Accept-Language": {"*"}},
+			want:   Meta{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := ExtractHeader(context.Background(), tt.header)
+			if got := FromContext(ctx); got != tt.want {
+				t.Errorf("ExtractHeader() = %+v, want %+v", got, tt.want)
+			}
+
+			out := http.Header{}
+			InjectHeader(ctx, out)
+			if got := FromContext(ExtractHeader(context.Background(), out)); got != tt.want {
+				t.Errorf("InjectHeader() round trip = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMD(t *testing.T) {
+	ctx := WithTenantID(WithRequestID(context.Background(), "r1"), "t1")
+	md := map[string][]string{}
+	InjectMD(ctx, md)
+	if md["x-request-id"][0] != "r1" || md["x-tenant-id"][0] != "t1" || len(md) != 2 {
+		t.Errorf("InjectMD() = %v", md)
+	}
+
+	got := FromContext(ExtractMD(WithUserID(context.Background(), "u1"), md))
+	want := Meta{RequestID: "r1", UserID: "u1", TenantID: "t1"}
+	if got != want {
+		t.Errorf("ExtractMD() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	a, b := NewRequestID(), NewRequestID()
+	if len(a) != 32 || a == b {
+		t.Errorf("NewRequestID() = %s, %s", a, b)
+	}
+}
@@ -0,0 +1,353 @@
+package migrate
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ChangSZ/golib/dbutil"
+	"github.com/ChangSZ/golib/leaderelect"
+	"github.com/ChangSZ/golib/log"
+)
+
+var (
+	// ErrDirty 上一次迁移中途失败, 需要人工确认数据库状态后调用Force
+	ErrDirty = errors.New("migrate: database is dirty")
+	// ErrNoDown 需要回滚的版本没有down迁移
+	ErrNoDown = errors.New("migrate: no down migration")
+	// ErrUnknownVersion 数据库中的版本或目标版本不在迁移列表中
+	ErrUnknownVersion = errors.New("migrate: unknown version")
+)
+
+// DB 执行迁移需要的数据库方法, *sql.DB满足该接口
+type DB interface {
+	dbutil.TxBeginner
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+var _ DB = (*sql.DB)(nil)
+
+// Config 迁移配置
+type Config struct {
+	Table   string        `toml:"table"`   // 版本表, 默认schema_version
+	LockKey string        `toml:"lockKey"` // 锁的key, 默认"migrate:"+Table
+	LockTTL time.Duration `toml:"lockTTL"` // 锁的有效期, 执行期间定期续期, 默认1m
+}
+
+// Option Migrator的可选参数
+type Option func(*Migrator)
+
+// WithLock 使用lease加锁, 多个实例同时启动时只有一个执行迁移, 其他实例等待其完成
+func WithLock(lease leaderelect.Lease) Option {
+	return func(m *Migrator) {
+		m.lease = lease
+	}
+}
+
+// Migrator 按版本顺序执行迁移, 当前版本记录在版本表中:
+//   - 每个迁移在一个事务中执行, 并在同一事务中更新版本号
+//   - 不支持事务DDL的数据库(如MySQL)中途失败时版本被标记为dirty, 之后的操作返回ErrDirty,
+//     需要人工修复后调用Force
+//   - 每个迁移文件作为一条语句执行, MySQL需要在DSN中开启multiStatements
+//
+// 用法:
+//
+//	list, err := migrate.Load(migrations, "migrations")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	m := migrate.New(db, list, migrate.Config{}, migrate.WithLock(leaderelect.NewRedisLease(rdb)))
+//	if err := m.Up(ctx); err != nil {
+//		log.Fatal(err)
+//	}
+type Migrator struct {
+	db         DB
+	migrations []Migration
+	cfg        Config
+	lease      leaderelect.Lease
+}
+
+// New new a Migrator.
+func New(db DB, migrations []Migration, cfg Config, opts ...Option) *Migrator {
+	if cfg.Table == "" {
+		cfg.Table = "schema_version"
+	}
+	if cfg.LockKey == "" {
+		cfg.LockKey = "migrate:" + cfg.Table
+	}
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = time.Minute
+	}
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	m := &Migrator{db: db, migrations: sorted, cfg: cfg}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Version 当前版本, 没有执行过迁移时为0
+func (m *Migrator) Version(ctx context.Context) (version int64, dirty bool, err error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return 0, false, err
+	}
+	return m.version(ctx)
+}
+
+// Up 执行版本号大于当前版本的所有迁移
+func (m *Migrator) Up(ctx context.Context) error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+	return m.To(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down 回滚最近的steps个迁移
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.locked(ctx, func(ctx context.Context) error {
+		current, err := m.clean(ctx)
+		if err != nil {
+			return err
+		}
+		idx := m.index(current)
+		if idx < 0 && current != 0 {
+			return fmt.Errorf("%w: %d", ErrUnknownVersion, current)
+		}
+		for ; steps > 0 && idx >= 0; steps-- {
+			var prev int64
+			if idx > 0 {
+				prev = m.migrations[idx-1].Version
+			}
+			if err := m.down(ctx, m.migrations[idx], prev); err != nil {
+				return err
+			}
+			idx--
+		}
+		return nil
+	})
+}
+
+// To 迁移到target版本, target小于当前版本时依次回滚, 为0时回滚全部
+func (m *Migrator) To(ctx context.Context, target int64) error {
+	if target != 0 && m.index(target) < 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, target)
+	}
+	return m.locked(ctx, func(ctx context.Context) error {
+		current, err := m.clean(ctx)
+		if err != nil {
+			return err
+		}
+		if current != 0 && m.index(current) < 0 {
+			return fmt.Errorf("%w: %d", ErrUnknownVersion, current)
+		}
+		for _, mig := range m.migrations {
+			if mig.Version <= current || mig.Version > target {
+				continue
+			}
+			if err := m.up(ctx, mig); err != nil {
+				return err
+			}
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if mig.Version <= target || mig.Version > current {
+				continue
+			}
+			var prev int64
+			if i > 0 {
+				prev = m.migrations[i-1].Version
+			}
+			if err := m.down(ctx, mig, prev); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Force 将版本设置为version并清除dirty标记, 不执行任何迁移; 用于人工修复失败的迁移后
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	return m.locked(ctx, func(ctx context.Context) error {
+		// 确保版本表中有记录
+		if _, _, err := m.version(ctx); err != nil {
+			return err
+		}
+		return m.setVersion(ctx, m.db, version, false)
+	})
+}
+
+func (m *Migrator) index(version int64) int {
+	for i, mig := range m.migrations {
+		if mig.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// clean 读取当前版本, dirty时返回ErrDirty
+func (m *Migrator) clean(ctx context.Context) (int64, error) {
+	version, dirty, err := m.version(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w: version %d, fix it manually then call Force", ErrDirty, version)
+	}
+	return version, nil
+}
+
+func (m *Migrator) up(ctx context.Context, mig Migration) error {
+	return m.run(ctx, mig, "up", mig.Up, mig.Version, mig.Version)
+}
+
+func (m *Migrator) down(ctx context.Context, mig Migration, prev int64) error {
+	if mig.Down == "" {
+		return fmt.Errorf("%w: version %d (%s)", ErrNoDown, mig.Version, mig.Name)
+	}
+	return m.run(ctx, mig, "down", mig.Down, mig.Version, prev)
+}
+
+// run 先标记dirty, 再在事务中执行迁移并更新版本号
+func (m *Migrator) run(ctx context.Context, mig Migration, direction, query string, dirtyVersion, newVersion int64) error {
+	start := time.Now()
+	if err := m.setVersion(ctx, m.db, dirtyVersion, true); err != nil {
+		return err
+	}
+	err := dbutil.WithTx(ctx, m.db, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+		return m.setVersion(ctx, tx, newVersion, false)
+	})
+	if err != nil {
+		return fmt.Errorf("migrate: %s %d (%s): %w", direction, mig.Version, mig.Name, err)
+	}
+	log.Context(ctx).Infow("msg", "migrate: "+direction, "version", mig.Version, "name", mig.Name,
+		"elapsed", time.Since(start).String())
+	return nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// 版本表的语句不使用参数, 避免不同数据库占位符的差异; 版本号为整数, 没有注入风险
+func (m *Migrator) setVersion(ctx context.Context, db execer, version int64, dirty bool) error {
+	query := fmt.Sprintf("UPDATE %s SET version = %d, dirty = %s", m.cfg.Table, version, sqlBool(dirty))
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("migrate: set version %d: %w", version, err)
+	}
+	return nil
+}
+
+func sqlBool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (version BIGINT NOT NULL, dirty BOOLEAN NOT NULL)", m.cfg.Table)
+	if _, err := m.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("migrate: create %s: %w", m.cfg.Table, err)
+	}
+	return nil
+}
+
+// version 读取版本表, 表为空时插入版本0
+func (m *Migrator) version(ctx context.Context) (int64, bool, error) {
+	var version int64
+	var dirty bool
+	err := m.db.QueryRowContext(ctx, fmt.Sprintf("SELECT version, dirty FROM %s", m.cfg.Table)).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		query := fmt.Sprintf("INSERT INTO %s (version, dirty) VALUES (0, FALSE)", m.cfg.Table)
+		if _, err := m.db.ExecContext(ctx, query); err != nil {
+			return 0, false, fmt.Errorf("migrate: init %s: %w", m.cfg.Table, err)
+		}
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("migrate: read %s: %w", m.cfg.Table, err)
+	}
+	return version, dirty, nil
+}
+
+// locked 持有锁执行fn, 锁续期失败时取消ctx; 没有配置WithLock时直接执行
+func (m *Migrator) locked(ctx context.Context, fn func(ctx context.Context) error) error {
+	if m.lease == nil {
+		if err := m.ensureTable(ctx); err != nil {
+			return err
+		}
+		return fn(ctx)
+	}
+
+	holder := newHolder()
+	for {
+		ok, err := m.lease.Acquire(ctx, m.cfg.LockKey, holder, m.cfg.LockTTL)
+		if err != nil {
+			return fmt.Errorf("migrate: acquire lock: %w", err)
+		}
+		if ok {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+	defer func() {
+		_ = m.lease.Release(context.WithoutCancel(ctx), m.cfg.LockKey, holder)
+	}()
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go func() {
+		ticker := time.NewTicker(m.cfg.LockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ok, err := m.lease.Renew(ctx, m.cfg.LockKey, holder, m.cfg.LockTTL)
+				if err == nil && !ok {
+					err = errors.New("held by others")
+				}
+				if err != nil {
+					cancel(fmt.Errorf("migrate: lost lock: %w", err))
+					return
+				}
+			}
+		}
+	}()
+
+	if err := m.ensureTable(ctx); err != nil {
+		return err
+	}
+	if err := fn(ctx); err != nil {
+		if cause := context.Cause(ctx); cause != nil && cause != err {
+			return fmt.Errorf("%w (%v)", err, cause)
+		}
+		return err
+	}
+	return nil
+}
+
+func newHolder() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
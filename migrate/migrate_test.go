@@ -0,0 +1,234 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ChangSZ/golib/leaderelect"
+)
+
+// fakeState fake驱动的数据库状态, 语句立即生效(类似MySQL的DDL), 用于验证dirty标记
+type fakeState struct {
+	mu      sync.Mutex
+	hasRow  bool
+	version int64
+	dirty   bool
+	applied []string
+}
+
+func (s *fakeState) exec(query string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS schema_version"):
+	case strings.HasPrefix(query, "INSERT INTO schema_version"):
+		s.hasRow = true
+	case strings.HasPrefix(query, "UPDATE schema_version"):
+		var dirty string
+		if _, err := fmt.Sscanf(query, "UPDATE schema_version SET version = %d, dirty = %s", &s.version, &dirty); err != nil {
+			return err
+		}
+		s.dirty = dirty == "TRUE"
+	case strings.Contains(query, "FAIL"):
+		return errors.New("syntax error")
+	default:
+		s.applied = append(s.applied, query)
+	}
+	return nil
+}
+
+type fakeDriver struct{ state *fakeState }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return fakeConn(d), nil }
+
+type fakeConn struct{ state *fakeState }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return fakeTx{}, nil }
+
+func (c fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), c.state.exec(query)
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+	rows := &fakeRows{}
+	if c.state.hasRow {
+		rows.values = [][]driver.Value{{c.state.version, c.state.dirty}}
+	}
+	return rows, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"version", "dirty"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+var driverSeq int
+
+func openFake(t *testing.T) (*sql.DB, *fakeState) {
+	t.Helper()
+	state := &fakeState{}
+	driverSeq++
+	name := fmt.Sprintf("migrate-fake-%d", driverSeq)
+	sql.Register(name, fakeDriver{state: state})
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db, state
+}
+
+var testFS = fstest.MapFS{
+	"migrations/0001_users.up.sql":      {Data: []byte("create users")},
+	"migrations/0001_users.down.sql":    {Data: []byte("drop users")},
+	"migrations/0002_orders.up.sql":     {Data: []byte("create orders")},
+	"migrations/0002_orders.down.sql":   {Data: []byte("drop orders")},
+	"migrations/0010_index.up.sql":      {Data: []byte("create index")},
+	"migrations/README.md":              {Data: []byte("ignored")},
+	"broken/0001_a.down.sql":            {Data: []byte("drop a")},
+	"duplicate/0001_a.up.sql":           {Data: []byte("a")},
+	"duplicate/0001_b.up.sql":           {Data: []byte("b")},
+	"failing/0001_ok.up.sql":            {Data: []byte("ok")},
+	"failing/0002_broken.up.sql":        {Data: []byte("FAIL")},
+	"failing/0002_broken.down.sql":      {Data: []byte("undo broken")},
+	"failing/0003_never_reached.up.sql": {Data: []byte("never")},
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		dir      string
+		versions []int64
+		wantErr  bool
+	}{
+		{dir: "migrations", versions: []int64{1, 2, 10}},
+		{dir: "broken", wantErr: true},
+		{dir: "duplicate", wantErr: true},
+		{dir: "missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			list, err := Load(testFS, tt.dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v", err)
+			}
+			var versions []int64
+			for _, m := range list {
+				versions = append(versions, m.Version)
+			}
+			if fmt.Sprint(versions) != fmt.Sprint(tt.versions) && !tt.wantErr {
+				t.Errorf("versions = %v, want %v", versions, tt.versions)
+			}
+		})
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	db, state := openFake(t)
+	list, err := Load(testFS, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New(db, list, Config{}, WithLock(leaderelect.NewMemoryLease()))
+
+	steps := []struct {
+		name        string
+		run         func() error
+		wantVersion int64
+		wantApplied string
+		wantErr     error
+	}{
+		{name: "up", run: func() error { return m.Up(ctx) }, wantVersion: 10, wantApplied: "create users,create orders,create index"},
+		{name: "up again", run: func() error { return m.Up(ctx) }, wantVersion: 10, wantApplied: ""},
+		{name: "down without down file", run: func() error { return m.Down(ctx, 1) }, wantVersion: 10, wantErr: ErrNoDown},
+		{name: "to 2", run: func() error { return m.Force(ctx, 2) }, wantVersion: 2},
+		{name: "to 0", run: func() error { return m.To(ctx, 0) }, wantVersion: 0, wantApplied: "drop orders,drop users"},
+		{name: "unknown target", run: func() error { return m.To(ctx, 3) }, wantVersion: 0, wantErr: ErrUnknownVersion},
+		{name: "to 1", run: func() error { return m.To(ctx, 1) }, wantVersion: 1, wantApplied: "create users"},
+		{name: "down", run: func() error { return m.Down(ctx, 5) }, wantVersion: 0, wantApplied: "drop users"},
+	}
+	for _, s := range steps {
+		state.applied = nil
+		err := s.run()
+		if !errors.Is(err, s.wantErr) {
+			t.Fatalf("%s: error = %v, want %v", s.name, err, s.wantErr)
+		}
+		version, dirty, err := m.Version(ctx)
+		if err != nil || dirty || version != s.wantVersion {
+			t.Errorf("%s: Version() = %d, %v, %v, want %d", s.name, version, dirty, err, s.wantVersion)
+		}
+		if got := strings.Join(state.applied, ","); got != s.wantApplied {
+			t.Errorf("%s: applied = %q, want %q", s.name, got, s.wantApplied)
+		}
+	}
+}
+
+func TestMigratorDirty(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	db, _ := openFake(t)
+	list, err := Load(testFS, "failing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New(db, list, Config{})
+
+	if err := m.Up(ctx); err == nil {
+		t.Fatal("Up() error = nil")
+	}
+	if version, dirty, _ := m.Version(ctx); version != 2 || !dirty {
+		t.Fatalf("Version() = %d, %v, want 2 dirty", version, dirty)
+	}
+	if err := m.Up(ctx); !errors.Is(err, ErrDirty) {
+		t.Fatalf("Up() on dirty = %v, want ErrDirty", err)
+	}
+	if err := m.Force(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if version, dirty, _ := m.Version(ctx); version != 1 || dirty {
+		t.Errorf("Version() after Force = %d, %v", version, dirty)
+	}
+}
+
+func TestMigratorLockWait(t *testing.T) {
+	db, _ := openFake(t)
+	lease := leaderelect.NewMemoryLease()
+	if ok, _ := lease.Acquire(context.Background(), "migrate:schema_version", "other", time.Minute); !ok {
+		t.Fatal("Acquire() failed")
+	}
+	m := New(db, nil, Config{}, WithLock(lease))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := m.Force(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Force() while locked = %v, want deadline exceeded", err)
+	}
+}
@@ -0,0 +1,117 @@
+package ndjson
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type event struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []WriterOption
+	}{
+		{name: "plain"},
+		{name: "gzip", opts: []WriterOption{WithGzip(gzip.BestSpeed)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 1; i <= 3; i++ {
+				if err := w.Encode(event{ID: i, Name: "<a&b>"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := NewReader(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			var got []event
+			for {
+				var e event
+				if err := r.Decode(&e); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, e)
+			}
+			if len(got) != 3 || got[2].ID != 3 || got[0].Name != "<a&b>" {
+				t.Errorf("decoded = %+v", got)
+			}
+		})
+	}
+}
+
+func TestMalformedLines(t *testing.T) {
+	input := `{"id":1}` + "\n\nnot json\n" + `{"id":"x"}` + "\n" + strings.Repeat("z", 100) + "\n" + `{"id":4}` + "\n"
+
+	r, _ := NewReader(strings.NewReader(input), WithMaxLineSize(50))
+	var e event
+	r.Decode(&e)
+	var lineErr *LineError
+	if err := r.Decode(&e); !errors.As(err, &lineErr) || lineErr.Line != 3 || string(lineErr.Raw) != "not json" {
+		t.Fatalf("Decode() error = %v, want line 3", err)
+	}
+
+	var skipped []int
+	r, _ = NewReader(strings.NewReader(input), WithMaxLineSize(50), WithSkipErrors(func(e *LineError) {
+		skipped = append(skipped, e.Line)
+	}))
+	var ids []int
+	for {
+		var e event
+		if err := r.Decode(&e); err != nil {
+			break
+		}
+		ids = append(ids, e.ID)
+	}
+	if len(ids) != 2 || ids[1] != 4 {
+		t.Errorf("ids = %v, want [1 4]", ids)
+	}
+	if len(skipped) != 3 || skipped[0] != 3 || skipped[1] != 4 || skipped[2] != 5 {
+		t.Errorf("skipped lines = %v, want [3 4 5]", skipped)
+	}
+}
+
+func TestDecodeChan(t *testing.T) {
+	r, _ := NewReader(strings.NewReader("{\"id\":1}\n{\"id\":2}\nbad\n"))
+	items, errc := DecodeChan[event](context.Background(), r)
+	var n int
+	for range items {
+		n++
+	}
+	var lineErr *LineError
+	if err := <-errc; n != 2 || !errors.As(err, &lineErr) {
+		t.Errorf("DecodeChan() n = %d err = %v", n, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r, _ = NewReader(strings.NewReader("{\"id\":1}\n{\"id\":2}\n"))
+	items, errc = DecodeChan[event](ctx, r)
+	<-items
+	cancel()
+	for range items {
+	}
+	if err := <-errc; err != nil && !errors.Is(err, context.Canceled) {
+		t.Errorf("DecodeChan() after cancel err = %v", err)
+	}
+}
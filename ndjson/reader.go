@@ -0,0 +1,157 @@
+package ndjson
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ChangSZ/golib/ioutilx"
+)
+
+// LineError 某一行解析失败
+type LineError struct {
+	Line int    // 行号, 从1开始
+	Raw  []byte // 原始内容, 超长的行为nil
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("ndjson: line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// ReaderOption Reader的可选参数
+type ReaderOption func(*readerOptions)
+
+type readerOptions struct {
+	maxLineSize int
+	onError     func(*LineError)
+}
+
+// WithMaxLineSize 单行最大字节数, 默认为ioutilx.DefaultMaxLineSize
+func WithMaxLineSize(n int) ReaderOption {
+	return func(o *readerOptions) {
+		o.maxLineSize = n
+	}
+}
+
+// WithSkipErrors 跳过格式错误和超长的行, 并通过fn上报; 未设置时遇到错误行Decode直接返回*LineError
+func WithSkipErrors(fn func(*LineError)) ReaderOption {
+	return func(o *readerOptions) {
+		if fn == nil {
+			fn = func(*LineError) {}
+		}
+		o.onError = fn
+	}
+}
+
+// Reader 逐行解码NDJSON, 自动识别gzip压缩的输入, 空行会被忽略
+type Reader struct {
+	lines   *ioutilx.LineReader
+	gz      *gzip.Reader
+	onError func(*LineError)
+}
+
+// NewReader new a Reader, 输入以gzip魔数开头时自动解压
+func NewReader(r io.Reader, opts ...ReaderOption) (*Reader, error) {
+	o := readerOptions{maxLineSize: ioutilx.DefaultMaxLineSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	reader := &Reader{onError: o.onError}
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("ndjson: open gzip: %w", err)
+		}
+		reader.gz = gz
+		r = gz
+	} else {
+		r = br
+	}
+	reader.lines = ioutilx.NewLineReader(r, ioutilx.WithMaxLineSize(o.maxLineSize))
+	return reader, nil
+}
+
+// Decode 将下一行解码到v, 读完时返回io.EOF
+func (r *Reader) Decode(v interface{}) error {
+	for {
+		line, err := r.lines.ReadLine()
+		if err == io.EOF {
+			return io.EOF
+		}
+		var lineErr *LineError
+		switch {
+		case err != nil && !errors.Is(err, ioutilx.ErrLineTooLong):
+			return err
+		case err != nil:
+			lineErr = &LineError{Line: r.lines.Line(), Err: err}
+		default:
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 {
+				continue
+			}
+			if err := json.Unmarshal(line, v); err != nil {
+				lineErr = &LineError{Line: r.lines.Line(), Raw: append([]byte(nil), line...), Err: err}
+			}
+		}
+		if lineErr == nil {
+			return nil
+		}
+		if r.onError == nil {
+			return lineErr
+		}
+		r.onError(lineErr)
+	}
+}
+
+// Line 最近一次读取的行号
+func (r *Reader) Line() int {
+	return r.lines.Line()
+}
+
+// Close 释放gzip解压器, 不会关闭底层的io.Reader
+func (r *Reader) Close() error {
+	if r.gz != nil {
+		return r.gz.Close()
+	}
+	return nil
+}
+
+// DecodeChan 在后台逐行解码并发送到返回的channel, 用于流水线处理
+//
+// 读完或ctx结束时关闭items; 出错时向errc发送一个错误后关闭items, errc带缓冲, 不读取也不会阻塞
+func DecodeChan[T any](ctx context.Context, r *Reader) (<-chan T, <-chan error) {
+	items := make(chan T)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(items)
+		defer close(errc)
+		for {
+			var v T
+			if err := r.Decode(&v); err != nil {
+				if err != io.EOF {
+					errc <- err
+				}
+				return
+			}
+			select {
+			case items <- v:
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			}
+		}
+	}()
+	return items, errc
+}
@@ -0,0 +1,80 @@
+package ndjson
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+)
+
+// WriterOption Writer的可选参数
+type WriterOption func(*writerOptions)
+
+type writerOptions struct {
+	gzip  bool
+	level int
+}
+
+// WithGzip 以gzip压缩输出, level同gzip包的压缩级别
+func WithGzip(level int) WriterOption {
+	return func(o *writerOptions) {
+		o.gzip = true
+		o.level = level
+	}
+}
+
+// Writer 将值逐行编码为NDJSON, 不做HTML转义
+type Writer struct {
+	bw  *bufio.Writer
+	gz  *gzip.Writer
+	enc *json.Encoder
+}
+
+// NewWriter new a Writer.
+func NewWriter(w io.Writer, opts ...WriterOption) (*Writer, error) {
+	var o writerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	writer := &Writer{}
+	if o.gzip {
+		gz, err := gzip.NewWriterLevel(w, o.level)
+		if err != nil {
+			return nil, err
+		}
+		writer.gz = gz
+		w = gz
+	}
+	writer.bw = bufio.NewWriter(w)
+	writer.enc = json.NewEncoder(writer.bw)
+	writer.enc.SetEscapeHTML(false)
+	return writer, nil
+}
+
+// Encode 写入一行, json.Encoder输出的紧凑JSON不含换行, 末尾自动追加"\n"
+func (w *Writer) Encode(v interface{}) error {
+	return w.enc.Encode(v)
+}
+
+// Flush 将缓冲的数据写入底层io.Writer
+func (w *Writer) Flush() error {
+	if err := w.bw.Flush(); err != nil {
+		return err
+	}
+	if w.gz != nil {
+		return w.gz.Flush()
+	}
+	return nil
+}
+
+// Close 刷新缓冲并结束gzip流, 不会关闭底层的io.Writer
+func (w *Writer) Close() error {
+	if err := w.bw.Flush(); err != nil {
+		return err
+	}
+	if w.gz != nil {
+		return w.gz.Close()
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package netutil
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// CIDRMatcher CIDR白名单匹配器, 使用按bit分叉的前缀树存储网段, 匹配复杂度与网段数量无关
+//
+// 可以并发使用
+type CIDRMatcher struct {
+	mu sync.RWMutex
+	v4 *node
+	v6 *node
+}
+
+type node struct {
+	children [2]*node
+	terminal bool // 从根到当前节点构成一个完整网段
+}
+
+// NewCIDRMatcher 使用若干网段创建匹配器, 也可以是单个IP(视为/32或/128)
+func NewCIDRMatcher(cidrs ...string) (*CIDRMatcher, error) {
+	m := &CIDRMatcher{v4: &node{}, v6: &node{}}
+	for _, c := range cidrs {
+		if err := m.Add(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Add 添加网段
+func (m *CIDRMatcher) Add(cidr string) error {
+	ipNet, err := parseCIDR(cidr)
+	if err != nil {
+		return err
+	}
+	ones, _ := ipNet.Mask.Size()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := m.root(ipNet.IP)
+	for i := 0; i < ones; i++ {
+		if n.terminal {
+			return nil // 已被更大的网段覆盖
+		}
+		b := bit(ipNet.IP, i)
+		if n.children[b] == nil {
+			n.children[b] = &node{}
+		}
+		n = n.children[b]
+	}
+	n.terminal = true
+	n.children = [2]*node{} // 更小的网段已被覆盖
+	return nil
+}
+
+// Contains ip是否落在任一网段内
+func (m *CIDRMatcher) Contains(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	n := m.root(ip)
+	for i := 0; n != nil; i++ {
+		if n.terminal {
+			return true
+		}
+		if i == len(ip)*8 {
+			return false
+		}
+		n = n.children[bit(ip, i)]
+	}
+	return false
+}
+
+// ContainsString 同Contains, 非法IP返回false
+func (m *CIDRMatcher) ContainsString(ip string) bool {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return false
+	}
+	return m.Contains(parsed)
+}
+
+func (m *CIDRMatcher) root(ip net.IP) *node {
+	if len(ip) == net.IPv4len {
+		return m.v4
+	}
+	return m.v6
+}
+
+func parseCIDR(s string) (*net.IPNet, error) {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("netutil: invalid ip %q", s)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("netutil: invalid cidr %q: %w", s, err)
+	}
+	if ip4 := ipNet.IP.To4(); ip4 != nil {
+		ipNet.IP = ip4
+	}
+	return ipNet, nil
+}
+
+func bit(ip net.IP, i int) int {
+	return int(ip[i/8]>>(7-uint(i%8))) & 1
+}
@@ -0,0 +1,49 @@
+package netutil
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIP 获取请求的真实客户端IP
+//
+// 只有当直连地址(RemoteAddr)属于可信代理时才会读取 X-Forwarded-For:
+// 从右往左跳过可信代理, 第一个非可信代理的地址即为客户端IP;
+// 没有 X-Forwarded-For 时再尝试 X-Real-IP.
+// trusted为nil时不信任任何代理, 直接返回RemoteAddr, 防止客户端伪造请求头
+func ClientIP(r *http.Request, trusted *CIDRMatcher) string {
+	remote := remoteIP(r.RemoteAddr)
+	if trusted == nil || !trusted.ContainsString(remote) {
+		return remote
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(hops[i])
+			if net.ParseIP(ip) == nil {
+				break
+			}
+			if !trusted.ContainsString(ip) {
+				return ip
+			}
+			remote = ip
+		}
+		// 全部都是可信代理时返回最左侧的地址
+		return remote
+	}
+
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+	return remote
+}
+
+func remoteIP(addr string) string {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(addr))
+	if err != nil {
+		return strings.TrimSpace(addr)
+	}
+	return host
+}
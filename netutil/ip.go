@@ -0,0 +1,67 @@
+package netutil
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// GetLocalIP 返回本机第一个非回环的IPv4地址
+func GetLocalIP() (string, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() {
+			continue
+		}
+		if ip4 := ipNet.IP.To4(); ip4 != nil {
+			return ip4.String(), nil
+		}
+	}
+	return "", errors.New("netutil: no local ipv4 address found")
+}
+
+// GetOutboundIP 返回访问外网时使用的本机IP
+//
+// 通过UDP "连接" 公网地址让内核选择路由, 并不会真正发送数据包
+func GetOutboundIP() (string, error) {
+	conn, err := net.Dial("udp", "8.8.8.8:80")
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
+}
+
+// IsPrivate 是否为内网地址(RFC 1918 / RFC 4193), 回环地址也视为内网
+func IsPrivate(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	return parsed.IsPrivate() || parsed.IsLoopback()
+}
+
+// IPToUint32 IPv4转为uint32, 192.168.1.1 => 3232235777
+func IPToUint32(ip string) (uint32, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return 0, fmt.Errorf("netutil: invalid ip %q", ip)
+	}
+	ip4 := parsed.To4()
+	if ip4 == nil {
+		return 0, fmt.Errorf("netutil: %q is not an ipv4 address", ip)
+	}
+	return binary.BigEndian.Uint32(ip4), nil
+}
+
+// Uint32ToIP uint32转为IPv4, 3232235777 => 192.168.1.1
+func Uint32ToIP(n uint32) string {
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, n)
+	return ip.String()
+}
@@ -0,0 +1,109 @@
+package netutil
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIPToUint32(t *testing.T) {
+	n, err := IPToUint32("192.168.1.1")
+	if err != nil || n != 3232235777 {
+		t.Fatalf("IPToUint32() = %v, %v, want 3232235777", n, err)
+	}
+	if ip := Uint32ToIP(n); ip != "192.168.1.1" {
+		t.Errorf("Uint32ToIP() = %v, want 192.168.1.1", ip)
+	}
+	if _, err := IPToUint32("::1"); err == nil {
+		t.Errorf("IPToUint32() expect error for ipv6")
+	}
+}
+
+func TestIsPrivate(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "10.1.2.3", want: true},
+		{ip: "172.16.0.1", want: true},
+		{ip: "172.32.0.1", want: false},
+		{ip: "192.168.0.1", want: true},
+		{ip: "127.0.0.1", want: true},
+		{ip: "8.8.8.8", want: false},
+		{ip: "fd00::1", want: true},
+		{ip: "invalid", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := IsPrivate(tt.ip); got != tt.want {
+				t.Errorf("IsPrivate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCIDRMatcher(t *testing.T) {
+	m, err := NewCIDRMatcher("10.0.0.0/8", "192.168.1.0/24", "1.2.3.4", "2001:db8::/32", "10.1.0.0/16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "10.255.0.1", want: true},
+		{ip: "10.1.2.3", want: true},
+		{ip: "11.0.0.1", want: false},
+		{ip: "192.168.1.200", want: true},
+		{ip: "192.168.2.1", want: false},
+		{ip: "1.2.3.4", want: true},
+		{ip: "1.2.3.5", want: false},
+		{ip: "::ffff:10.0.0.1", want: true},
+		{ip: "2001:db8:1::1", want: true},
+		{ip: "2001:db9::1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := m.ContainsString(tt.ip); got != tt.want {
+				t.Errorf("ContainsString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewCIDRMatcher("10.0.0.0/33"); err == nil {
+		t.Errorf("NewCIDRMatcher() expect error for invalid cidr")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	trusted, _ := NewCIDRMatcher("10.0.0.0/8")
+	tests := []struct {
+		name    string
+		remote  string
+		xff     string
+		realIP  string
+		trusted *CIDRMatcher
+		want    string
+	}{
+		{name: "no_proxy", remote: "1.1.1.1:1234", xff: "2.2.2.2", trusted: trusted, want: "1.1.1.1"},
+		{name: "untrusted_config", remote: "10.0.0.1:1234", xff: "2.2.2.2", want: "10.0.0.1"},
+		{name: "one_proxy", remote: "10.0.0.1:1234", xff: "2.2.2.2", trusted: trusted, want: "2.2.2.2"},
+		{name: "spoofed", remote: "10.0.0.1:1234", xff: "6.6.6.6, 2.2.2.2, 10.0.0.2", trusted: trusted, want: "2.2.2.2"},
+		{name: "all_trusted", remote: "10.0.0.1:1234", xff: "10.0.0.3, 10.0.0.2", trusted: trusted, want: "10.0.0.3"},
+		{name: "real_ip", remote: "10.0.0.1:1234", realIP: "3.3.3.3", trusted: trusted, want: "3.3.3.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remote
+			if tt.xff != "" {
+				r.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := ClientIP(r, tt.trusted); got != tt.want {
+				t.Errorf("ClientIP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
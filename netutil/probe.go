@@ -0,0 +1,77 @@
+package netutil
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ProbeInterval WaitForPort/WaitForHTTP 的重试间隔
+var ProbeInterval = 200 * time.Millisecond
+
+// IsPortOpen 端口是否可以建立TCP连接
+func IsPortOpen(host string, port int, timeout time.Duration) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), timeout)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// WaitForPort 等待addr(host:port)可以建立TCP连接, 直到ctx结束
+func WaitForPort(ctx context.Context, addr string) error {
+	var dialer net.Dialer
+	return poll(ctx, func() error {
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+}
+
+// WaitForHTTP 等待url返回expectStatus状态码, 直到ctx结束; expectStatus为0时任意2xx均可
+func WaitForHTTP(ctx context.Context, url string, expectStatus int) error {
+	client := &http.Client{Timeout: time.Second}
+	return poll(ctx, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+
+		if expectStatus == 0 && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		if resp.StatusCode == expectStatus {
+			return nil
+		}
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	})
+}
+
+// poll 重复执行probe直到成功或ctx结束, 超时时返回最后一次的错误
+func poll(ctx context.Context, probe func() error) error {
+	ticker := time.NewTicker(ProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		err := probe()
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("netutil: wait canceled: %w, last error: %v", ctx.Err(), err)
+		case <-ticker.C:
+		}
+	}
+}
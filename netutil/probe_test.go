@@ -0,0 +1,79 @@
+package netutil
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIsPortOpen(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	if !IsPortOpen("127.0.0.1", port, time.Second) {
+		t.Errorf("IsPortOpen() = false, want true")
+	}
+	_ = ln.Close()
+	if IsPortOpen("127.0.0.1", port, time.Second) {
+		t.Errorf("IsPortOpen() = true, want false")
+	}
+}
+
+func TestWaitForPort(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if err := WaitForPort(ctx, addr); err == nil {
+		t.Errorf("WaitForPort() expect timeout error")
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ln, err := net.Listen("tcp", addr)
+		if err == nil {
+			time.Sleep(time.Second)
+			_ = ln.Close()
+		}
+	}()
+	ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := WaitForPort(ctx, addr); err != nil {
+		t.Errorf("WaitForPort() error = %v", err)
+	}
+}
+
+func TestWaitForHTTP(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := WaitForHTTP(ctx, srv.URL, 0); err != nil {
+		t.Fatalf("WaitForHTTP() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("calls = %d, want 3", got)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if err := WaitForHTTP(ctx, srv.URL, http.StatusOK); err == nil {
+		t.Errorf("WaitForHTTP() expect error for unexpected status")
+	}
+}
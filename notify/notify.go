@@ -0,0 +1,244 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/ChangSZ/golib/i18n"
+	"github.com/ChangSZ/golib/log"
+)
+
+var (
+	// ErrRateLimited 接收人在限流窗口内的发送次数已达上限
+	ErrRateLimited = errors.New("notify: rate limited")
+	// ErrNoProvider 渠道未注册Provider
+	ErrNoProvider = errors.New("notify: no provider for channel")
+)
+
+// Channel 通知渠道
+type Channel string
+
+const (
+	ChannelSMS   Channel = "sms"
+	ChannelEmail Channel = "email"
+	ChannelPush  Channel = "push"
+	ChannelIM    Channel = "im"
+)
+
+// Message 一条通知
+type Message struct {
+	Channel  Channel           `json:"channel"`
+	To       string            `json:"to"`                 // 手机号、邮箱、设备token等
+	Title    string            `json:"title,omitempty"`    // 标题, 短信一般为空
+	Body     string            `json:"body"`               // 正文
+	Template string            `json:"template,omitempty"` // 模板名, 非空时由Bundle渲染Title和Body
+	Data     interface{}       `json:"data,omitempty"`     // 模板变量
+	Locale   string            `json:"locale,omitempty"`   // 为空时使用ctx中的语言区域
+	Params   map[string]string `json:"params,omitempty"`   // 渠道特有的参数, 如短信平台的模板编号、签名
+}
+
+// Provider 某个渠道的发送实现, 如短信平台、APNs、企业微信
+type Provider interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// ProviderFunc 函数形式的Provider
+type ProviderFunc func(ctx context.Context, msg *Message) error
+
+func (f ProviderFunc) Send(ctx context.Context, msg *Message) error {
+	return f(ctx, msg)
+}
+
+// Permanent 包装后的错误不再重试, 如手机号无效、内容违规
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+type Config struct {
+	RecipientLimit  int           `toml:"recipientLimit"`  // 每个接收人在RecipientWindow内最多发送的条数, 按渠道分别计算, 0表示不限制
+	RecipientWindow time.Duration `toml:"recipientWindow"` // 限流窗口, 默认1m
+	MaxAttempts     int           `toml:"maxAttempts"`     // 最大尝试次数, 默认3
+	Backoff         time.Duration `toml:"backoff"`         // 首次重试间隔, 之后每次翻倍, 默认500ms
+	MaxBackoff      time.Duration `toml:"maxBackoff"`      // 最大重试间隔, 默认10s
+}
+
+// Option Dispatcher的可选参数
+type Option func(*Dispatcher)
+
+// WithBundle 设置渲染模板的消息集合, 默认使用i18n.DefaultBundle
+func WithBundle(b *i18n.Bundle) Option {
+	return func(d *Dispatcher) {
+		d.bundle = b
+	}
+}
+
+// WithProvider 注册渠道的Provider
+func WithProvider(ch Channel, p Provider) Option {
+	return func(d *Dispatcher) {
+		d.providers[ch] = p
+	}
+}
+
+// Dispatcher 按渠道分发通知, 负责模板渲染、接收人限流和失败重试, 可以并发使用
+//
+// 模板按"<Template>.<Channel>.title/body"查找, 找不到时使用"<Template>.title/body", 如:
+//
+//	login_code:
+//	  sms:
+//	    body: "您的验证码为{{.Code}}, 5分钟内有效"
+//	  email:
+//	    title: "登录验证码"
+//	    body: "您好, 您的验证码为{{.Code}}"
+//
+//	d := notify.New(notify.Config{RecipientLimit: 5}, notify.WithProvider(notify.ChannelSMS, sms))
+//	err := d.Send(ctx, &notify.Message{Channel: notify.ChannelSMS, To: phone, Template: "login_code", Data: data})
+type Dispatcher struct {
+	cfg       Config
+	bundle    *i18n.Bundle
+	providers map[Channel]Provider
+
+	mu       sync.Mutex
+	limiters map[string]*limiterEntry
+	lastGC   time.Time
+	now      func() time.Time
+}
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// New new a Dispatcher.
+func New(cfg Config, opts ...Option) *Dispatcher {
+	if cfg.RecipientWindow <= 0 {
+		cfg.RecipientWindow = time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 500 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 10 * time.Second
+	}
+	d := &Dispatcher{
+		cfg:       cfg,
+		bundle:    i18n.DefaultBundle,
+		providers: make(map[Channel]Provider),
+		limiters:  make(map[string]*limiterEntry),
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Send 渲染并发送通知; 超过接收人限流时返回ErrRateLimited, 不会重试
+func (d *Dispatcher) Send(ctx context.Context, msg *Message) error {
+	p, ok := d.providers[msg.Channel]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoProvider, msg.Channel)
+	}
+	if err := d.render(ctx, msg); err != nil {
+		return err
+	}
+	if !d.allow(msg.Channel, msg.To) {
+		return ErrRateLimited
+	}
+
+	var err error
+	backoff := d.cfg.Backoff
+	for attempt := 1; ; attempt++ {
+		if err = p.Send(ctx, msg); err == nil {
+			return nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) || attempt >= d.cfg.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+		log.Context(ctx).Warnw("msg", "notify: send failed, retrying", "channel", msg.Channel,
+			"to", msg.To, "attempt", attempt, "err", err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, d.cfg.MaxBackoff)
+	}
+}
+
+// render 按渠道渲染标题和正文, 只有找到的消息才会覆盖
+func (d *Dispatcher) render(ctx context.Context, msg *Message) error {
+	if msg.Template == "" {
+		return nil
+	}
+	locale := msg.Locale
+	if locale == "" {
+		locale = i18n.Locale(ctx)
+	}
+	found := false
+	for _, f := range []struct {
+		field string
+		dst   *string
+	}{{"title", &msg.Title}, {"body", &msg.Body}} {
+		for _, id := range []string{
+			msg.Template + "." + string(msg.Channel) + "." + f.field,
+			msg.Template + "." + f.field,
+		} {
+			// Localize找不到消息时返回id本身
+			if s := d.bundle.Localize(locale, id, msg.Data); s != id {
+				*f.dst = s
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return fmt.Errorf("notify: template %s not found for channel %s", msg.Template, msg.Channel)
+	}
+	return nil
+}
+
+// allow 按渠道和接收人限流, 定期清理长时间未使用的限流器
+func (d *Dispatcher) allow(ch Channel, to string) bool {
+	if d.cfg.RecipientLimit <= 0 {
+		return true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	if now.Sub(d.lastGC) > d.cfg.RecipientWindow {
+		for k, e := range d.limiters {
+			if now.Sub(e.lastUsed) > d.cfg.RecipientWindow {
+				delete(d.limiters, k)
+			}
+		}
+		d.lastGC = now
+	}
+
+	key := string(ch) + ":" + to
+	e, ok := d.limiters[key]
+	if !ok {
+		every := d.cfg.RecipientWindow / time.Duration(d.cfg.RecipientLimit)
+		e = &limiterEntry{limiter: rate.NewLimiter(rate.Every(every), d.cfg.RecipientLimit)}
+		d.limiters[key] = e
+	}
+	e.lastUsed = now
+	return e.limiter.AllowN(now, 1)
+}
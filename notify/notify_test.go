@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChangSZ/golib/i18n"
+)
+
+func newBundle(t *testing.T) *i18n.Bundle {
+	t.Helper()
+	b := i18n.NewBundle("zh-CN")
+	if err := b.AddMessages("zh-CN", map[string]interface{}{
+		"login_code": map[string]interface{}{
+			"title": "登录验证码",
+			"body":  "您的验证码为{{.Code}}",
+			"sms":   map[string]interface{}{"body": "【示例】验证码{{.Code}}, 5分钟内有效"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.AddMessages("en", map[string]interface{}{
+		"login_code": map[string]interface{}{"body": "Your code is {{.Code}}"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestDispatcherTemplate(t *testing.T) {
+	sms, email := NewMemoryProvider(), NewMemoryProvider()
+	d := New(Config{}, WithBundle(newBundle(t)),
+		WithProvider(ChannelSMS, sms), WithProvider(ChannelEmail, email))
+	data := map[string]string{"Code": "1234"}
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		msg       *Message
+		p         *MemoryProvider
+		wantTitle string
+		wantBody  string
+	}{
+		{
+			name:      "channel specific",
+			ctx:       context.Background(),
+			msg:       &Message{Channel: ChannelSMS, To: "13800000000", Template: "login_code", Data: data},
+			p:         sms,
+			wantTitle: "登录验证码",
+			wantBody:  "【示例】验证码1234, 5分钟内有效",
+		},
+		{
+			name:      "fallback to common",
+			ctx:       context.Background(),
+			msg:       &Message{Channel: ChannelEmail, To: "a@example.com", Template: "login_code", Data: data},
+			p:         email,
+			wantTitle: "登录验证码",
+			wantBody:  "您的验证码为1234",
+		},
+		{
+			name:      "locale from ctx",
+			ctx:       i18n.WithLocale(context.Background(), "en-US"),
+			msg:       &Message{Channel: ChannelEmail, To: "b@example.com", Template: "login_code", Data: data},
+			p:         email,
+			wantTitle: "登录验证码",
+			wantBody:  "Your code is 1234",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := d.Send(tt.ctx, tt.msg); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			sent := tt.p.Sent()
+			got := sent[len(sent)-1]
+			if got.Title != tt.wantTitle || got.Body != tt.wantBody {
+				t.Errorf("Send() = %q %q, want %q %q", got.Title, got.Body, tt.wantTitle, tt.wantBody)
+			}
+		})
+	}
+
+	if err := d.Send(context.Background(), &Message{Channel: ChannelSMS, Template: "missing"}); err == nil {
+		t.Errorf("Send() want error for missing template")
+	}
+	if err := d.Send(context.Background(), &Message{Channel: ChannelPush}); !errors.Is(err, ErrNoProvider) {
+		t.Errorf("Send() error = %v, want ErrNoProvider", err)
+	}
+}
+
+func TestDispatcherRateLimit(t *testing.T) {
+	p := NewMemoryProvider()
+	d := New(Config{RecipientLimit: 2, RecipientWindow: time.Minute}, WithProvider(ChannelSMS, p))
+	now := time.Now()
+	d.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	send := func(to string) error {
+		return d.Send(ctx, &Message{Channel: ChannelSMS, To: to, Body: "hi"})
+	}
+	for i := 0; i < 2; i++ {
+		if err := send("a"); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	if err := send("a"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Send() error = %v, want ErrRateLimited", err)
+	}
+	if err := send("b"); err != nil {
+		t.Errorf("other recipient limited: %v", err)
+	}
+	now = now.Add(30 * time.Second)
+	if err := send("a"); err != nil {
+		t.Errorf("Send() after refill error = %v", err)
+	}
+}
+
+func TestDispatcherRetry(t *testing.T) {
+	errTemp := errors.New("timeout")
+	tests := []struct {
+		name      string
+		errs      []error
+		wantErr   error
+		wantCalls int
+	}{
+		{name: "retry then success", errs: []error{errTemp, errTemp}, wantCalls: 3},
+		{name: "exhausted", errs: []error{errTemp, errTemp, errTemp}, wantErr: errTemp, wantCalls: 3},
+		{name: "permanent", errs: []error{Permanent(errTemp)}, wantErr: errTemp, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			p := ProviderFunc(func(ctx context.Context, msg *Message) error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			d := New(Config{Backoff: time.Millisecond}, WithProvider(ChannelIM, p))
+			err := d.Send(context.Background(), &Message{Channel: ChannelIM, To: "u1", Body: "hi"})
+			if !errors.Is(err, tt.wantErr) || calls != tt.wantCalls {
+				t.Errorf("Send() error = %v, calls = %d, want %v, %d", err, calls, tt.wantErr, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.log")
+	p := NewFileProvider(path)
+	for _, to := range []string{"a", "b"} {
+		if err := p.Send(context.Background(), &Message{Channel: ChannelSMS, To: to, Body: "code 1234"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	var rec map[string]interface{}
+	if len(lines) != 2 || json.Unmarshal([]byte(lines[1]), &rec) != nil || rec["to"] != "b" || rec["time"] == nil {
+		t.Errorf("file = %s", b)
+	}
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	_ Provider = (*MemoryProvider)(nil)
+	_ Provider = (*FileProvider)(nil)
+)
+
+// MemoryProvider 将通知保存在内存中, 用于单元测试
+type MemoryProvider struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+// NewMemoryProvider new a MemoryProvider.
+func NewMemoryProvider() *MemoryProvider {
+	return &MemoryProvider{}
+}
+
+func (p *MemoryProvider) Send(_ context.Context, msg *Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, *msg)
+	return nil
+}
+
+// Sent 已发送的通知
+func (p *MemoryProvider) Sent() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Message(nil), p.sent...)
+}
+
+// FileProvider 将通知按行追加写入JSON文件, 用于本地开发时查看验证码等内容
+type FileProvider struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileProvider new a FileProvider.
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+type fileRecord struct {
+	Time time.Time `json:"time"`
+	*Message
+}
+
+func (p *FileProvider) Send(_ context.Context, msg *Message) error {
+	b, err := json.Marshal(fileRecord{Time: time.Now(), Message: msg})
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f, err := os.OpenFile(p.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,322 @@
+package objstore
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ChangSZ/golib/signurl"
+)
+
+var _ Storage = (*Local)(nil)
+
+// localMetaDir 保存元数据的目录, 位于根目录下, 不会出现在List中
+const localMetaDir = ".objmeta"
+
+// Local 本地文件系统驱动, 用于开发环境、单机部署和测试
+//
+// 对象保存在root/key, 元数据保存在root/.objmeta/key.json; 配置WithPresign后可以生成预签名地址,
+// 并由Local作为http.Handler处理这些地址上的GET和PUT请求:
+//
+//	store, _ := objstore.NewLocal("data/objects", objstore.WithPresign(signer, "http://127.0.0.1:8080/objects"))
+//	http.Handle("/objects/", store)
+type Local struct {
+	root    string
+	signer  *signurl.Signer
+	baseURL *url.URL
+}
+
+// LocalOption NewLocal的可选参数
+type LocalOption func(*Local)
+
+// WithPresign 使用signer生成和校验预签名地址, baseURL为Local作为http.Handler挂载的地址
+func WithPresign(signer *signurl.Signer, baseURL string) LocalOption {
+	return func(l *Local) {
+		u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+		if err != nil {
+			return
+		}
+		l.signer = signer
+		l.baseURL = u
+	}
+}
+
+type localMeta struct {
+	ContentType string            `json:"contentType"`
+	Checksum    string            `json:"checksum"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// NewLocal new a Local.
+func NewLocal(root string, opts ...LocalOption) (*Local, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	l := &Local{root: root}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l, nil
+}
+
+func (l *Local) path(key string) string {
+	return filepath.Join(l.root, filepath.FromSlash(key))
+}
+
+func (l *Local) metaPath(key string) string {
+	return filepath.Join(l.root, localMetaDir, filepath.FromSlash(key)+".json")
+}
+
+func checkLocalKey(key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	if key == localMetaDir || strings.HasPrefix(key, localMetaDir+"/") {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+// Put 先写入临时文件再重命名, 并发写同一个key时不会读到写了一半的内容
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, opts ...PutOption) (*Object, error) {
+	if err := checkLocalKey(key); err != nil {
+		return nil, err
+	}
+	o := newPutOptions(opts)
+	p := l.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	br := bufio.NewReaderSize(r, 512)
+	head, _ := br.Peek(512)
+	ct := detectContentType(o.contentType, key, head)
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), &ctxReader{ctx: ctx, r: br})
+	if err != nil {
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	meta := localMeta{ContentType: ct, Checksum: hex.EncodeToString(h.Sum(nil)), Metadata: o.metadata}
+	if err := l.writeMeta(key, meta); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	return l.object(key, info, meta, size), nil
+}
+
+func (l *Local) writeMeta(key string, meta localMeta) error {
+	mp := l.metaPath(key)
+	if err := os.MkdirAll(filepath.Dir(mp), 0o755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(mp, b, 0o644)
+}
+
+// readMeta 元数据缺失时(如直接拷贝进目录的文件)返回零值
+func (l *Local) readMeta(key string) localMeta {
+	var meta localMeta
+	if b, err := os.ReadFile(l.metaPath(key)); err == nil {
+		_ = json.Unmarshal(b, &meta)
+	}
+	return meta
+}
+
+func (l *Local) object(key string, info fs.FileInfo, meta localMeta, size int64) *Object {
+	if meta.ContentType == "" {
+		meta.ContentType = detectContentType("", key, nil)
+	}
+	return &Object{
+		Key:          key,
+		Size:         size,
+		ContentType:  meta.ContentType,
+		ETag:         meta.Checksum,
+		Checksum:     meta.Checksum,
+		LastModified: info.ModTime(),
+		Metadata:     meta.Metadata,
+	}
+}
+
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	if err := checkLocalKey(key); err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(l.path(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		f.Close()
+		return nil, nil, ErrNotFound
+	}
+	obj := l.object(key, info, l.readMeta(key), info.Size())
+	return newVerifyReader(f, obj.Checksum), obj, nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	if err := checkLocalKey(key); err != nil {
+		return err
+	}
+	if err := os.Remove(l.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Remove(l.metaPath(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List 遍历整个目录后分页, 适合对象数量不大的场景
+func (l *Local) List(ctx context.Context, opts ListOptions) (*ListPage, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 1000
+	}
+	var keys []string
+	err := filepath.WalkDir(l.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(l.root, p)
+		key := filepath.ToSlash(rel)
+		if d.IsDir() {
+			if key == localMetaDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".tmp-") || !strings.HasPrefix(key, opts.Prefix) || key <= opts.Token {
+			return nil
+		}
+		keys = append(keys, key)
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+
+	page := &ListPage{}
+	if len(keys) > opts.Limit {
+		keys = keys[:opts.Limit]
+		page.NextToken = keys[len(keys)-1]
+	}
+	for _, key := range keys {
+		info, err := os.Stat(l.path(key))
+		if err != nil {
+			continue
+		}
+		page.Objects = append(page.Objects, *l.object(key, info, l.readMeta(key), info.Size()))
+	}
+	return page, nil
+}
+
+// PresignURL 未配置WithPresign时返回ErrNotSupported
+func (l *Local) PresignURL(ctx context.Context, method, key string, ttl time.Duration) (string, error) {
+	if l.signer == nil {
+		return "", ErrNotSupported
+	}
+	if err := checkLocalKey(key); err != nil {
+		return "", err
+	}
+	u := *l.baseURL
+	u.Path = l.baseURL.Path + "/" + key
+	u.RawPath = ""
+	// method参与签名, GET的地址不能用于上传
+	u.RawQuery = url.Values{"method": {strings.ToUpper(method)}}.Encode()
+	return l.signer.SignURL(u.String(), ttl)
+}
+
+// ServeHTTP 处理PresignURL生成的地址
+func (l *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if l.signer == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if err := l.signer.VerifyRequest(r); err != nil || r.URL.Query().Get("method") != r.Method {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, l.baseURL.Path+"/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		rc, obj, err := l.Get(r.Context(), key)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		rc.Close()
+		// 直接使用文件以支持Range请求
+		f, err := os.Open(l.path(key))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+		w.Header().Set("Content-Type", obj.ContentType)
+		w.Header().Set("ETag", `"`+obj.ETag+`"`)
+		http.ServeContent(w, r, "", obj.LastModified, f)
+	case http.MethodPut:
+		obj, err := l.Put(r.Context(), key, r.Body, WithContentType(r.Header.Get("Content-Type")))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("ETag", `"`+obj.ETag+`"`)
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ctxReader ctx结束后停止读取
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
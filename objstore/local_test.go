@@ -0,0 +1,120 @@
+package objstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChangSZ/golib/signurl"
+)
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	l, err := NewLocal(root)
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+
+	obj, err := l.Put(ctx, "docs/readme", strings.NewReader("<html><body>hi</body></html>"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if obj.ContentType != "text/html; charset=utf-8" || obj.Size != 28 || obj.Checksum == "" {
+		t.Errorf("Put() = %+v", obj)
+	}
+
+	rc, got, err := l.Get(ctx, "docs/readme")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	body, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil || string(body) != "<html><body>hi</body></html>" || got.Checksum != obj.Checksum {
+		t.Errorf("Get() = %s, %+v, %v", body, got, err)
+	}
+
+	// 文件被改动后校验失败
+	if err := os.WriteFile(filepath.Join(root, "docs", "readme"), []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rc, _, _ = l.Get(ctx, "docs/readme")
+	if _, err := io.ReadAll(rc); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("ReadAll() error = %v, want ErrChecksumMismatch", err)
+	}
+	rc.Close()
+
+	for _, k := range []string{"docs/b.txt", "docs/c.txt", "img/d.png"} {
+		if _, err := l.Put(ctx, k, strings.NewReader(k)); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	page, err := l.List(ctx, ListOptions{Prefix: "docs/", Limit: 2})
+	if err != nil || len(page.Objects) != 2 || page.NextToken != "docs/c.txt" {
+		t.Fatalf("List() = %+v, %v", page, err)
+	}
+	page, err = l.List(ctx, ListOptions{Prefix: "docs/", Token: page.NextToken})
+	if err != nil || len(page.Objects) != 1 || page.Objects[0].Key != "docs/readme" {
+		t.Errorf("List() = %+v, %v", page, err)
+	}
+
+	if err := l.Delete(ctx, "docs/b.txt"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if _, _, err := l.Get(ctx, "docs/b.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+	if err := l.Delete(ctx, "docs/b.txt"); err != nil {
+		t.Errorf("Delete() missing error = %v", err)
+	}
+
+	for _, key := range []string{"", "/etc/passwd", "../x", "a//b", ".objmeta/x.json"} {
+		if _, err := l.Put(ctx, key, strings.NewReader("x")); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
+
+func TestLocalPresign(t *testing.T) {
+	ctx := context.Background()
+	signer := signurl.New([]signurl.Key{{ID: "k1", Secret: []byte("secret")}})
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	l, err := NewLocal(t.TempDir(), WithPresign(signer, srv.URL+"/objects"))
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	mux.Handle("/objects/", l)
+
+	putURL, _ := l.PresignURL(ctx, http.MethodPut, "a/b.json", time.Minute)
+	req, _ := http.NewRequest(http.MethodPut, putURL, strings.NewReader(`{"a":1}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("PUT = %v, %v", resp, err)
+	}
+
+	getURL, _ := l.PresignURL(ctx, http.MethodGet, "a/b.json", time.Minute)
+	resp, err = http.Get(getURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != `{"a":1}` || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("GET = %d %s %s", resp.StatusCode, resp.Header.Get("Content-Type"), body)
+	}
+
+	// GET的地址不能用于上传
+	req, _ = http.NewRequest(http.MethodPut, getURL, strings.NewReader("x"))
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("PUT with GET url = %v, %v", resp, err)
+	}
+}
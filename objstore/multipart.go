@@ -0,0 +1,140 @@
+package objstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ChangSZ/golib/log"
+)
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type completeUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+// putMultipart 分片上传, first为已读取的第一个分片, 其余从r中读取; 失败时中止上传以释放服务端已保存的分片
+func (s *S3) putMultipart(ctx context.Context, key string, first []byte, r io.Reader, ct string, metadata map[string]string) (_ *Object, err error) {
+	uploadID, err := s.createMultipart(ctx, key, ct, metadata)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			s.abortMultipart(ctx, key, uploadID)
+		}
+	}()
+
+	h := sha256.New()
+	var parts []completedPart
+	var size int64
+	buf := first
+	for num := 1; len(buf) > 0; num++ {
+		h.Write(buf)
+		size += int64(len(buf))
+		etag, err := s.uploadPart(ctx, key, uploadID, num, buf)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, completedPart{PartNumber: num, ETag: etag})
+
+		// 已上传的分片交给服务端后可以复用缓冲区
+		next := first[:cap(first)]
+		n, rerr := io.ReadFull(r, next)
+		if rerr != nil && rerr != io.EOF && rerr != io.ErrUnexpectedEOF {
+			return nil, rerr
+		}
+		buf = next[:n]
+	}
+
+	etag, err := s.completeMultipart(ctx, key, uploadID, parts)
+	if err != nil {
+		return nil, err
+	}
+	return &Object{
+		Key:          key,
+		Size:         size,
+		ContentType:  ct,
+		ETag:         etag,
+		Checksum:     hex.EncodeToString(h.Sum(nil)),
+		LastModified: s.now(),
+		Metadata:     metadata,
+	}, nil
+}
+
+func (s *S3) createMultipart(ctx context.Context, key, ct string, metadata map[string]string) (string, error) {
+	resp, err := s.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, objectHeader(ct, metadata), nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil || result.UploadID == "" {
+		return "", fmt.Errorf("objstore: create multipart upload: invalid response: %v", err)
+	}
+	return result.UploadID, nil
+}
+
+// uploadPart 单个分片的重试由do完成
+func (s *S3) uploadPart(ctx context.Context, key, uploadID string, num int, data []byte) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(num)}, "uploadId": {uploadID}}
+	header := http.Header{}
+	header.Set("Content-MD5", md5Base64(data))
+	resp, err := s.do(ctx, http.MethodPut, key, query, header, data)
+	if err != nil {
+		return "", fmt.Errorf("objstore: upload part %d: %w", num, err)
+	}
+	resp.Body.Close()
+	return resp.Header.Get("ETag"), nil
+}
+
+func (s *S3) completeMultipart(ctx context.Context, key, uploadID string, parts []completedPart) (string, error) {
+	body, err := xml.Marshal(completeUpload{Parts: parts})
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.do(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, nil, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	// 服务端可能在200响应中返回错误
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if bytes.Contains(b, []byte("<Error>")) {
+		e := &S3Error{Status: resp.StatusCode}
+		_ = xml.Unmarshal(b, e)
+		return "", e
+	}
+	var result struct {
+		ETag string `xml:"ETag"`
+	}
+	_ = xml.Unmarshal(b, &result)
+	return strings.Trim(result.ETag, `"`), nil
+}
+
+func (s *S3) abortMultipart(ctx context.Context, key, uploadID string) {
+	resp, err := s.do(context.WithoutCancel(ctx), http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, nil)
+	if err != nil {
+		log.Context(ctx).Warnw("msg", "objstore: abort multipart upload failed", "key", key, "uploadId", uploadID, "err", err)
+		return
+	}
+	resp.Body.Close()
+}
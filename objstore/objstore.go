@@ -0,0 +1,149 @@
+package objstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound 对象不存在
+	ErrNotFound = errors.New("objstore: object not found")
+	// ErrChecksumMismatch 读取到的内容与写入时记录的sha256不一致
+	ErrChecksumMismatch = errors.New("objstore: checksum mismatch")
+	// ErrNotSupported 驱动不支持该操作
+	ErrNotSupported = errors.New("objstore: not supported")
+	// ErrInvalidKey key为空、以"/"开头或包含".."
+	ErrInvalidKey = errors.New("objstore: invalid key")
+)
+
+// checksumMeta 保存内容sha256的元数据名
+const checksumMeta = "sha256"
+
+// Object 对象的属性
+type Object struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	Checksum     string // 内容的sha256十六进制, 读取时据此校验; 分片上传或其他工具写入的对象可能为空
+	LastModified time.Time
+	Metadata     map[string]string
+}
+
+// ListOptions List的参数
+type ListOptions struct {
+	Prefix string
+	Token  string // 上一页返回的NextToken, 为空时从头开始
+	Limit  int    // 每页最多返回的对象数, 默认1000
+}
+
+// ListPage List返回的一页结果, 按key的字典序排列
+type ListPage struct {
+	Objects   []Object
+	NextToken string // 为空表示没有更多
+}
+
+// Storage 对象存储的抽象, 上传下载相关的业务代码依赖该接口, 以便在S3、OSS、MinIO和本地文件之间切换
+type Storage interface {
+	// Put 写入对象, 覆盖已有的同名对象
+	Put(ctx context.Context, key string, r io.Reader, opts ...PutOption) (*Object, error)
+	// Get 读取对象, 对象记录了Checksum时读到末尾会校验, 不一致时Read返回ErrChecksumMismatch
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	// Delete 删除对象, 对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// List 分页列出前缀为Prefix的对象
+	List(ctx context.Context, opts ListOptions) (*ListPage, error)
+	// PresignURL 生成有效期为ttl的预签名地址, method为GET或PUT, 供客户端直接下载或上传
+	PresignURL(ctx context.Context, method, key string, ttl time.Duration) (string, error)
+}
+
+// PutOption Put的可选参数
+type PutOption func(*putOptions)
+
+type putOptions struct {
+	contentType string
+	metadata    map[string]string
+}
+
+// WithContentType 设置Content-Type, 默认按扩展名推断, 无法推断时按内容检测
+func WithContentType(ct string) PutOption {
+	return func(o *putOptions) {
+		o.contentType = ct
+	}
+}
+
+// WithMetadata 设置自定义元数据, key建议使用小写
+func WithMetadata(md map[string]string) PutOption {
+	return func(o *putOptions) {
+		o.metadata = md
+	}
+}
+
+func newPutOptions(opts []PutOption) *putOptions {
+	o := &putOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// validKey 校验key, 防止本地驱动的路径穿越
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// detectContentType 优先使用指定值, 其次按扩展名推断, 最后按内容开头检测
+func detectContentType(set, key string, head []byte) string {
+	if set != "" {
+		return set
+	}
+	if ct := mime.TypeByExtension(path.Ext(key)); ct != "" {
+		return ct
+	}
+	return http.DetectContentType(head)
+}
+
+// verifyReader 读到末尾时校验sha256
+type verifyReader struct {
+	rc   io.ReadCloser
+	h    hash.Hash
+	want string
+}
+
+// newVerifyReader want为空时不校验
+func newVerifyReader(rc io.ReadCloser, want string) io.ReadCloser {
+	if want == "" {
+		return rc
+	}
+	return &verifyReader{rc: rc, h: sha256.New(), want: want}
+}
+
+func (v *verifyReader) Read(p []byte) (int, error) {
+	n, err := v.rc.Read(p)
+	v.h.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(v.h.Sum(nil)) != v.want {
+		return n, ErrChecksumMismatch
+	}
+	return n, err
+}
+
+func (v *verifyReader) Close() error {
+	return v.rc.Close()
+}
@@ -0,0 +1,331 @@
+package objstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ChangSZ/golib/log"
+)
+
+var _ Storage = (*S3)(nil)
+
+// metaPrefix 自定义元数据的请求头前缀
+const metaPrefix = "X-Amz-Meta-"
+
+// minPartSize S3要求除最后一个分片外每个分片至少5MB
+const minPartSize = 5 << 20
+
+type S3Config struct {
+	Endpoint   string        `toml:"endpoint"`   // 服务地址, 如https://s3.us-east-1.amazonaws.com、https://oss-cn-hangzhou.aliyuncs.com、http://minio:9000
+	Region     string        `toml:"region"`     // 默认us-east-1
+	Bucket     string        `toml:"bucket"`     // 存储桶
+	AccessKey  string        `toml:"accessKey"`  // 访问密钥ID
+	SecretKey  string        `toml:"secretKey"`  // 访问密钥
+	PathStyle  bool          `toml:"pathStyle"`  // 使用路径风格(endpoint/bucket/key), MinIO一般需要开启; 默认使用虚拟主机风格(bucket.endpoint/key)
+	PartSize   int64         `toml:"partSize"`   // 超过该大小时使用分片上传, 也是每个分片的大小, 默认8MB, 最小5MB
+	MaxRetries int           `toml:"maxRetries"` // 网络错误、5xx和429的重试次数, 默认3
+	Backoff    time.Duration `toml:"backoff"`    // 首次重试间隔, 之后每次翻倍, 默认200ms
+}
+
+// S3Error 服务端返回的错误
+type S3Error struct {
+	Status  int
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func (e *S3Error) Error() string {
+	return fmt.Sprintf("objstore: s3 status %d: %s: %s", e.Status, e.Code, e.Message)
+}
+
+// S3 S3兼容协议的驱动, 适用于AWS S3、阿里云OSS、腾讯云COS、MinIO等
+type S3 struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// S3Option NewS3的可选参数
+type S3Option func(*S3)
+
+// WithHTTPClient 设置http.Client, 默认使用http.DefaultClient
+func WithHTTPClient(c *http.Client) S3Option {
+	return func(s *S3) {
+		s.client = c
+	}
+}
+
+// NewS3 new a S3.
+func NewS3(cfg S3Config, opts ...S3Option) (*S3, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("objstore: bucket is required")
+	}
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("objstore: invalid endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.PartSize <= 0 {
+		cfg.PartSize = 8 << 20
+	}
+	cfg.PartSize = max(cfg.PartSize, minPartSize)
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 200 * time.Millisecond
+	}
+	s := &S3{cfg: cfg, endpoint: u, client: http.DefaultClient, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// target 返回key对应的host和已编码的path, key为空时表示存储桶
+func (s *S3) target(key string) (host, path string) {
+	if s.cfg.PathStyle {
+		return s.endpoint.Host, "/" + uriEncode(s.cfg.Bucket, true) + "/" + uriEncode(key, false)
+	}
+	return s.cfg.Bucket + "." + s.endpoint.Host, "/" + uriEncode(key, false)
+}
+
+// do 签名并发送请求, 网络错误、5xx和429时重试; 状态码不是2xx时返回*S3Error, 404返回ErrNotFound
+func (s *S3) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	if header == nil {
+		header = http.Header{}
+	}
+	payloadHash := emptyPayloadHash
+	if body != nil {
+		payloadHash = sha256Hex(body)
+	}
+	host, path := s.target(key)
+
+	backoff := s.cfg.Backoff
+	for attempt := 1; ; attempt++ {
+		r := &sigRequest{method: method, host: host, path: path, query: query, header: header.Clone(), payloadHash: payloadHash}
+		s.sign(r, s.now())
+		rawURL := s.endpoint.Scheme + "://" + host + path
+		if q := canonicalQuery(query); q != "" {
+			rawURL += "?" + q
+		}
+		req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header = r.header
+		req.ContentLength = int64(len(body))
+
+		resp, err := s.client.Do(req)
+		retry := err != nil
+		if err == nil {
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				return resp, nil
+			}
+			err = parseS3Error(resp)
+			retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		}
+		if !retry || attempt > s.cfg.MaxRetries || ctx.Err() != nil {
+			return nil, err
+		}
+		log.Context(ctx).Warnw("msg", "objstore: s3 request failed, retrying", "method", method, "key", key,
+			"attempt", attempt, "err", err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+func parseS3Error(resp *http.Response) error {
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return ErrNotFound
+	}
+	e := &S3Error{Status: resp.StatusCode}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	_ = xml.Unmarshal(b, e)
+	return e
+}
+
+// Put 不超过PartSize时一次上传, 并在元数据中记录sha256; 超过时分片上传, 每个分片用Content-MD5校验
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, opts ...PutOption) (*Object, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+	o := newPutOptions(opts)
+	buf := make([]byte, s.cfg.PartSize)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	buf = buf[:n]
+	ct := detectContentType(o.contentType, key, buf[:min(n, 512)])
+	if err == nil {
+		return s.putMultipart(ctx, key, buf, r, ct, o.metadata)
+	}
+
+	checksum := sha256Hex(buf)
+	header := objectHeader(ct, o.metadata)
+	header.Set(metaPrefix+checksumMeta, checksum)
+	header.Set("Content-MD5", md5Base64(buf))
+	resp, err := s.do(ctx, http.MethodPut, key, nil, header, buf)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return &Object{
+		Key:          key,
+		Size:         int64(n),
+		ContentType:  ct,
+		ETag:         strings.Trim(resp.Header.Get("ETag"), `"`),
+		Checksum:     checksum,
+		LastModified: s.now(),
+		Metadata:     o.metadata,
+	}, nil
+}
+
+func objectHeader(ct string, metadata map[string]string) http.Header {
+	header := http.Header{}
+	header.Set("Content-Type", ct)
+	for k, v := range metadata {
+		header.Set(metaPrefix+k, v)
+	}
+	return header
+}
+
+func md5Base64(b []byte) string {
+	sum := md5.Sum(b)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	if err := validKey(key); err != nil {
+		return nil, nil, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	obj := &Object{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        strings.Trim(resp.Header.Get("ETag"), `"`),
+	}
+	obj.LastModified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	for k, vs := range resp.Header {
+		name, ok := strings.CutPrefix(http.CanonicalHeaderKey(k), metaPrefix)
+		if !ok || len(vs) == 0 {
+			continue
+		}
+		name = strings.ToLower(name)
+		if name == checksumMeta {
+			obj.Checksum = vs[0]
+			continue
+		}
+		if obj.Metadata == nil {
+			obj.Metadata = make(map[string]string)
+		}
+		obj.Metadata[name] = vs[0]
+	}
+	return newVerifyReader(resp.Body, obj.Checksum), obj, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+type listResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		ETag         string    `xml:"ETag"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List 使用ListObjectsV2, 返回的对象不含ContentType、Checksum和Metadata
+func (s *S3) List(ctx context.Context, opts ListOptions) (*ListPage, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 1000
+	}
+	query := url.Values{"list-type": {"2"}, "max-keys": {strconv.Itoa(opts.Limit)}}
+	if opts.Prefix != "" {
+		query.Set("prefix", opts.Prefix)
+	}
+	if opts.Token != "" {
+		query.Set("continuation-token", opts.Token)
+	}
+	resp, err := s.do(ctx, http.MethodGet, "", query, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var result listResult
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("objstore: decode list result: %w", err)
+	}
+
+	page := &ListPage{}
+	if result.IsTruncated {
+		page.NextToken = result.NextContinuationToken
+	}
+	for _, c := range result.Contents {
+		page.Objects = append(page.Objects, Object{
+			Key:          c.Key,
+			Size:         c.Size,
+			ETag:         strings.Trim(c.ETag, `"`),
+			LastModified: c.LastModified,
+		})
+	}
+	return page, nil
+}
+
+// PresignURL ttl最长7天; 上传时客户端需使用PUT且不要添加额外的签名头
+func (s *S3) PresignURL(ctx context.Context, method, key string, ttl time.Duration) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	if ttl <= 0 || ttl > 7*24*time.Hour {
+		return "", fmt.Errorf("objstore: presign ttl must be in (0, 7d], got %v", ttl)
+	}
+	host, path := s.target(key)
+	r := &sigRequest{method: strings.ToUpper(method), host: host, path: path, query: url.Values{}, header: http.Header{}}
+	s.presign(r, s.now(), ttl)
+	return s.endpoint.Scheme + "://" + host + path + "?" + canonicalQuery(r.query), nil
+}
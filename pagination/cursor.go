@@ -0,0 +1,60 @@
+package pagination
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor 游标格式错误或签名不匹配
+var ErrInvalidCursor = errors.New("pagination: invalid cursor")
+
+// sigSize 截断后的签名长度, 16字节足以防止伪造且游标更短
+const sigSize = 16
+
+// Codec 游标编解码器, 游标为排序键JSON加HMAC-SHA256签名后的base64url编码, 客户端无法解读或篡改
+type Codec struct {
+	secret []byte
+}
+
+// NewCodec new a Codec, secret用于签名, 更换后旧游标全部失效
+func NewCodec(secret []byte) *Codec {
+	return &Codec{secret: secret}
+}
+
+func (c *Codec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)[:sigSize]
+}
+
+// Encode 将排序键编码为游标, keys一般是最后一条记录的排序字段, 如struct{ID int64; CreatedAt time.Time}
+func (c *Codec) Encode(keys interface{}) (string, error) {
+	payload, err := json.Marshal(keys)
+	if err != nil {
+		return "", err
+	}
+	buf := append(c.sign(payload), payload...)
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Decode 校验签名并将游标解码到out
+func (c *Codec) Decode(cursor string, out interface{}) error {
+	buf, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(buf) <= sigSize {
+		return ErrInvalidCursor
+	}
+	sig, payload := buf[:sigSize], buf[sigSize:]
+	if !hmac.Equal(sig, c.sign(payload)) {
+		return ErrInvalidCursor
+	}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(out); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
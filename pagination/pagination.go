@@ -0,0 +1,77 @@
+package pagination
+
+// 默认每页条数与上限
+var (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Request 列表接口的分页参数, 优先使用Cursor, 兼容旧接口的Page/Offset
+type Request struct {
+	Cursor string `form:"cursor" json:"cursor"`
+	Limit  int    `form:"limit" json:"limit"`
+	Page   int    `form:"page" json:"page"`     // 从1开始, 仅在没有Cursor时使用
+	Offset int    `form:"offset" json:"offset"` // 仅在没有Cursor和Page时使用
+}
+
+// GetLimit 规范化后的每页条数: 未设置时为DefaultLimit, 不超过MaxLimit
+func (r Request) GetLimit() int {
+	switch {
+	case r.Limit <= 0:
+		return DefaultLimit
+	case r.Limit > MaxLimit:
+		return MaxLimit
+	}
+	return r.Limit
+}
+
+// GetOffset 没有游标时的偏移量, 由Page或Offset计算
+func (r Request) GetOffset() int {
+	if r.Cursor != "" {
+		return 0
+	}
+	if r.Page > 0 {
+		return (r.Page - 1) * r.GetLimit()
+	}
+	if r.Offset > 0 {
+		return r.Offset
+	}
+	return 0
+}
+
+// FetchLimit 查询数据库时使用的条数, 多查一条用于判断是否还有下一页
+func (r Request) FetchLimit() int {
+	return r.GetLimit() + 1
+}
+
+// PageInfo 返回给客户端的分页信息
+type PageInfo struct {
+	HasNext    bool   `json:"has_next"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Limit      int    `json:"limit"`
+}
+
+// Page 根据按FetchLimit查出的items生成当前页数据和分页信息
+//
+// keyOf返回记录的排序键, 最后一条记录的排序键编码为下一页的游标
+//
+//	items := repo.List(ctx, afterKey, req.FetchLimit())
+//	page, info, err := pagination.Page(codec, req, items, func(u User) interface{} {
+//		return userKey{ID: u.ID, CreatedAt: u.CreatedAt}
+//	})
+func Page[T any](codec *Codec, req Request, items []T, keyOf func(T) interface{}) ([]T, PageInfo, error) {
+	limit := req.GetLimit()
+	info := PageInfo{Limit: limit}
+	if len(items) <= limit {
+		return items, info, nil
+	}
+
+	items = items[:limit]
+	cursor, err := codec.Encode(keyOf(items[limit-1]))
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	info.HasNext = true
+	info.NextCursor = cursor
+	return items, info, nil
+}
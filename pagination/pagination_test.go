@@ -0,0 +1,91 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type userKey struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func TestCodec(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	key := userKey{ID: 42, CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	cursor, err := codec.Encode(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		codec   *Codec
+		cursor  string
+		wantErr error
+	}{
+		{name: "valid", codec: codec, cursor: cursor},
+		{name: "other secret", codec: NewCodec([]byte("other")), cursor: cursor, wantErr: ErrInvalidCursor},
+		{name: "tampered", codec: codec, cursor: cursor[:len(cursor)-2] + "AA", wantErr: ErrInvalidCursor},
+		{name: "garbage", codec: codec, cursor: "!!", wantErr: ErrInvalidCursor},
+		{name: "offset leak", codec: codec, cursor: "MTA", wantErr: ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got userKey
+			err := tt.codec.Decode(tt.cursor, &got)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (got.ID != key.ID || !got.CreatedAt.Equal(key.CreatedAt)) {
+				t.Errorf("Decode() = %+v, want %+v", got, key)
+			}
+		})
+	}
+}
+
+func TestRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        Request
+		wantLimit  int
+		wantOffset int
+	}{
+		{name: "defaults", req: Request{}, wantLimit: 20, wantOffset: 0},
+		{name: "max limit", req: Request{Limit: 1000}, wantLimit: 100},
+		{name: "page", req: Request{Page: 3, Limit: 10}, wantLimit: 10, wantOffset: 20},
+		{name: "offset", req: Request{Offset: 7}, wantLimit: 20, wantOffset: 7},
+		{name: "cursor wins", req: Request{Cursor: "x", Page: 3}, wantLimit: 20, wantOffset: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.req.GetLimit(); got != tt.wantLimit {
+				t.Errorf("GetLimit() = %d, want %d", got, tt.wantLimit)
+			}
+			if got := tt.req.GetOffset(); got != tt.wantOffset {
+				t.Errorf("GetOffset() = %d, want %d", got, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestPage(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	keyOf := func(id int) interface{} { return map[string]int{"id": id} }
+	req := Request{Limit: 2}
+
+	items, info, err := Page(codec, req, []int{1, 2, 3}, keyOf)
+	if err != nil || len(items) != 2 || !info.HasNext || info.NextCursor == "" {
+		t.Fatalf("Page() = %v, %+v, %v", items, info, err)
+	}
+	var next map[string]int
+	if err := codec.Decode(info.NextCursor, &next); err != nil || next["id"] != 2 {
+		t.Errorf("next cursor = %v, %v, want id 2", next, err)
+	}
+
+	items, info, _ = Page(codec, req, []int{4}, keyOf)
+	if len(items) != 1 || info.HasNext || info.NextCursor != "" {
+		t.Errorf("last Page() = %v, %+v", items, info)
+	}
+}
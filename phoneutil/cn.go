@@ -0,0 +1,74 @@
+package phoneutil
+
+// Carrier 中国大陆运营商
+type Carrier string
+
+const (
+	CarrierUnknown  Carrier = ""
+	CarrierMobile   Carrier = "中国移动"
+	CarrierUnicom   Carrier = "中国联通"
+	CarrierTelecom  Carrier = "中国电信"
+	CarrierBroadnet Carrier = "中国广电"
+	CarrierVirtual  Carrier = "虚拟运营商"
+)
+
+// carriers 手机号前三位与运营商的对应关系
+var carriers = map[string]Carrier{
+	"134": CarrierMobile, "135": CarrierMobile, "136": CarrierMobile, "137": CarrierMobile,
+	"138": CarrierMobile, "139": CarrierMobile, "147": CarrierMobile, "148": CarrierMobile,
+	"150": CarrierMobile, "151": CarrierMobile, "152": CarrierMobile, "157": CarrierMobile,
+	"158": CarrierMobile, "159": CarrierMobile, "172": CarrierMobile, "178": CarrierMobile,
+	"182": CarrierMobile, "183": CarrierMobile, "184": CarrierMobile, "187": CarrierMobile,
+	"188": CarrierMobile, "195": CarrierMobile, "197": CarrierMobile, "198": CarrierMobile,
+
+	"130": CarrierUnicom, "131": CarrierUnicom, "132": CarrierUnicom, "145": CarrierUnicom,
+	"146": CarrierUnicom, "155": CarrierUnicom, "156": CarrierUnicom, "166": CarrierUnicom,
+	"175": CarrierUnicom, "176": CarrierUnicom, "185": CarrierUnicom, "186": CarrierUnicom,
+	"196": CarrierUnicom,
+
+	"133": CarrierTelecom, "149": CarrierTelecom, "153": CarrierTelecom, "173": CarrierTelecom,
+	"174": CarrierTelecom, "177": CarrierTelecom, "180": CarrierTelecom, "181": CarrierTelecom,
+	"189": CarrierTelecom, "190": CarrierTelecom, "191": CarrierTelecom, "193": CarrierTelecom,
+	"199": CarrierTelecom,
+
+	"192": CarrierBroadnet,
+
+	"162": CarrierVirtual, "165": CarrierVirtual, "167": CarrierVirtual, "170": CarrierVirtual,
+	"171": CarrierVirtual,
+}
+
+// areaCodes 常见城市的固话区号(不含0)
+var areaCodes = map[string]string{
+	"10": "北京", "20": "广州", "21": "上海", "22": "天津", "23": "重庆",
+	"24": "沈阳", "25": "南京", "27": "武汉", "28": "成都", "29": "西安",
+	"311": "石家庄", "351": "太原", "371": "郑州", "431": "长春", "451": "哈尔滨",
+	"471": "呼和浩特", "512": "苏州", "531": "济南", "532": "青岛", "551": "合肥",
+	"571": "杭州", "574": "宁波", "591": "福州", "592": "厦门", "731": "长沙",
+	"755": "深圳", "757": "佛山", "760": "中山", "769": "东莞", "771": "南宁",
+	"791": "南昌", "851": "贵阳", "871": "昆明", "891": "拉萨", "898": "海口",
+	"931": "兰州", "951": "银川", "971": "西宁", "991": "乌鲁木齐",
+}
+
+// Carrier 中国大陆手机号的运营商, 非手机号或未知号段返回CarrierUnknown
+func (n *Number) Carrier() Carrier {
+	if n.Region != "CN" || n.Type != TypeMobile {
+		return CarrierUnknown
+	}
+	return carriers[n.National[:3]]
+}
+
+// AreaCode 中国大陆固话的区号(含0), 非固话返回空字符串
+func (n *Number) AreaCode() string {
+	if n.Region != "CN" || n.Type != TypeLandline {
+		return ""
+	}
+	return "0" + n.National[:cnAreaCodeLen(n.National)]
+}
+
+// City 中国大陆固话区号对应的城市, 仅收录常见城市, 未收录时返回空字符串
+func (n *Number) City() string {
+	if n.Region != "CN" || n.Type != TypeLandline {
+		return ""
+	}
+	return areaCodes[n.National[:cnAreaCodeLen(n.National)]]
+}
@@ -0,0 +1,229 @@
+package phoneutil
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalid 号码格式错误
+var ErrInvalid = errors.New("phoneutil: invalid phone number")
+
+// Type 号码类型
+type Type int
+
+const (
+	TypeUnknown Type = iota
+	TypeMobile
+	TypeLandline
+	TypeTollFree // 中国的400、800号码
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeMobile:
+		return "mobile"
+	case TypeLandline:
+		return "landline"
+	case TypeTollFree:
+		return "toll_free"
+	}
+	return "unknown"
+}
+
+// country 常见国家和地区的国际区号及国内号码长度
+type country struct {
+	region string
+	code   int
+	minLen int
+	maxLen int
+	trunk  bool // 国内拨号是否带前缀0
+}
+
+var countries = []country{
+	{region: "CN", code: 86, minLen: 10, maxLen: 11, trunk: true},
+	{region: "HK", code: 852, minLen: 8, maxLen: 8},
+	{region: "MO", code: 853, minLen: 8, maxLen: 8},
+	{region: "TW", code: 886, minLen: 8, maxLen: 9, trunk: true},
+	{region: "US", code: 1, minLen: 10, maxLen: 10},
+	{region: "GB", code: 44, minLen: 9, maxLen: 10, trunk: true},
+	{region: "JP", code: 81, minLen: 9, maxLen: 10, trunk: true},
+	{region: "KR", code: 82, minLen: 8, maxLen: 10, trunk: true},
+	{region: "SG", code: 65, minLen: 8, maxLen: 8},
+	{region: "DE", code: 49, minLen: 6, maxLen: 13, trunk: true},
+	{region: "FR", code: 33, minLen: 9, maxLen: 9, trunk: true},
+	{region: "AU", code: 61, minLen: 9, maxLen: 9, trunk: true},
+	{region: "IN", code: 91, minLen: 10, maxLen: 10, trunk: true},
+	{region: "RU", code: 7, minLen: 10, maxLen: 10, trunk: true},
+}
+
+func countryByRegion(region string) (country, bool) {
+	region = strings.ToUpper(region)
+	for _, c := range countries {
+		if c.region == region {
+			return c, true
+		}
+	}
+	return country{}, false
+}
+
+// countryByPrefix 按国际区号前缀匹配, 国际区号不超过3位且互不为前缀
+func countryByPrefix(digits string) (country, bool) {
+	for n := 1; n <= 3 && n <= len(digits); n++ {
+		code, _ := strconv.Atoi(digits[:n])
+		for _, c := range countries {
+			if c.code == code {
+				return c, true
+			}
+		}
+	}
+	return country{}, false
+}
+
+// Number 解析后的号码
+type Number struct {
+	Region      string // 国家或地区代码, 如CN
+	CountryCode int    // 国际区号, 如86
+	National    string // 不含国际区号和国内前缀0的号码
+	Type        Type   // 仅对中国号码判断
+}
+
+// Parse 解析号码, 以"+"或"00"开头时按国际号码解析, 否则按defaultRegion(如"CN")解析;
+// 号码中的空格、"-"、"."和括号会被忽略
+func Parse(s, defaultRegion string) (*Number, error) {
+	raw := s
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')', ' ':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(s))
+
+	international := false
+	switch {
+	case strings.HasPrefix(s, "+"):
+		s, international = s[1:], true
+	case strings.HasPrefix(s, "00"):
+		s, international = s[2:], true
+	}
+	if s == "" || strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalid, raw)
+	}
+
+	var c country
+	var ok bool
+	if international {
+		c, ok = countryByPrefix(s)
+		if ok {
+			s = s[len(strconv.Itoa(c.code)):]
+		}
+	} else {
+		c, ok = countryByRegion(defaultRegion)
+		if ok && c.trunk {
+			s = strings.TrimPrefix(s, "0")
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported region for %q", ErrInvalid, raw)
+	}
+	if international && c.trunk {
+		// 部分用户会写成+86 010-xxx
+		s = strings.TrimPrefix(s, "0")
+	}
+
+	n := &Number{Region: c.region, CountryCode: c.code, National: s}
+	if c.region == "CN" {
+		n.Type = cnType(s)
+		if n.Type == TypeUnknown {
+			return nil, fmt.Errorf("%w: %q", ErrInvalid, raw)
+		}
+		return n, nil
+	}
+	if len(s) < c.minLen || len(s) > c.maxLen {
+		return nil, fmt.Errorf("%w: %q", ErrInvalid, raw)
+	}
+	return n, nil
+}
+
+// cnType 判断中国号码类型, national不含前缀0
+func cnType(s string) Type {
+	switch {
+	case len(s) == 11 && s[0] == '1' && s[1] >= '3' && s[1] <= '9':
+		return TypeMobile
+	case len(s) == 10 && (strings.HasPrefix(s, "400") || strings.HasPrefix(s, "800")):
+		return TypeTollFree
+	}
+	area := cnAreaCodeLen(s)
+	if area == 0 {
+		return TypeUnknown
+	}
+	if sub := len(s) - area; sub == 7 || sub == 8 {
+		return TypeLandline
+	}
+	return TypeUnknown
+}
+
+// cnAreaCodeLen 不含0的区号长度, 10和2x为两位, 其余为三位
+func cnAreaCodeLen(s string) int {
+	switch {
+	case len(s) < 3 || s[0] == '0' || s[0] == '1' && s[1] != '0':
+		return 0
+	case strings.HasPrefix(s, "10") || s[0] == '2':
+		return 2
+	}
+	return 3
+}
+
+// IsValid 是否为有效号码
+func IsValid(s, defaultRegion string) bool {
+	_, err := Parse(s, defaultRegion)
+	return err == nil
+}
+
+// IsCNMobile 是否为中国大陆手机号, 允许+86前缀
+func IsCNMobile(s string) bool {
+	n, err := Parse(s, "CN")
+	return err == nil && n.Region == "CN" && n.Type == TypeMobile
+}
+
+// E164 国际标准格式, 如+8613812345678
+func (n *Number) E164() string {
+	return "+" + strconv.Itoa(n.CountryCode) + n.National
+}
+
+// Format 国内显示格式: 中国手机号为138 1234 5678, 固话为010-12345678, 其它地区返回E164
+func (n *Number) Format() string {
+	if n.Region != "CN" {
+		return n.E164()
+	}
+	s := n.National
+	switch n.Type {
+	case TypeMobile:
+		return s[:3] + " " + s[3:7] + " " + s[7:]
+	case TypeTollFree:
+		return s[:3] + "-" + s[3:6] + "-" + s[6:]
+	case TypeLandline:
+		area := cnAreaCodeLen(s)
+		return "0" + s[:area] + "-" + s[area:]
+	}
+	return s
+}
+
+// Mask 隐藏号码中间部分用于展示, 如138****5678
+func (n *Number) Mask() string {
+	return Mask(n.National)
+}
+
+// Mask 隐藏号码中间部分, 保留前3位和后4位, 过短时只保留后4位
+func Mask(s string) string {
+	rs := []rune(s)
+	switch {
+	case len(rs) >= 11:
+		return string(rs[:3]) + strings.Repeat("*", len(rs)-7) + string(rs[len(rs)-4:])
+	case len(rs) > 4:
+		return strings.Repeat("*", len(rs)-4) + string(rs[len(rs)-4:])
+	}
+	return s
+}
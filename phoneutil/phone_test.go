@@ -0,0 +1,81 @@
+package phoneutil
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         string
+		region     string
+		wantE164   string
+		wantFormat string
+		wantType   Type
+		wantErr    bool
+	}{
+		{name: "cn mobile", in: "138 1234 5678", region: "CN", wantE164: "+8613812345678", wantFormat: "138 1234 5678", wantType: TypeMobile},
+		{name: "cn mobile +86", in: "+86-138-1234-5678", region: "", wantE164: "+8613812345678", wantFormat: "138 1234 5678", wantType: TypeMobile},
+		{name: "cn mobile 0086", in: "008613812345678", region: "US", wantE164: "+8613812345678", wantFormat: "138 1234 5678", wantType: TypeMobile},
+		{name: "beijing landline", in: "010-87654321", region: "CN", wantE164: "+861087654321", wantFormat: "010-87654321", wantType: TypeLandline},
+		{name: "shenzhen landline", in: "(0755) 8765 4321", region: "CN", wantE164: "+8675587654321", wantFormat: "0755-87654321", wantType: TypeLandline},
+		{name: "landline +86 with 0", in: "+86 021 1234 5678", region: "", wantE164: "+862112345678", wantFormat: "021-12345678", wantType: TypeLandline},
+		{name: "toll free", in: "400-123-4567", region: "CN", wantE164: "+864001234567", wantFormat: "400-123-4567", wantType: TypeTollFree},
+		{name: "us", in: "+1 (415) 555-2671", region: "", wantE164: "+14155552671", wantFormat: "+14155552671"},
+		{name: "uk national", in: "020 7946 0958", region: "GB", wantE164: "+442079460958", wantFormat: "+442079460958"},
+		{name: "hk", in: "+852 9123 4567", region: "", wantE164: "+85291234567", wantFormat: "+85291234567"},
+		{name: "cn too short", in: "1381234567", region: "CN", wantErr: true},
+		{name: "letters", in: "138abc45678", region: "CN", wantErr: true},
+		{name: "unknown region", in: "12345678", region: "ZZ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := Parse(tt.in, tt.region)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Errorf("Parse() error = %v, want ErrInvalid", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if n.E164() != tt.wantE164 || n.Format() != tt.wantFormat || n.Type != tt.wantType {
+				t.Errorf("Parse() = %s, %s, %v, want %s, %s, %v", n.E164(), n.Format(), n.Type, tt.wantE164, tt.wantFormat, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestCN(t *testing.T) {
+	tests := []struct {
+		in          string
+		wantCarrier Carrier
+		wantCity    string
+		wantMask    string
+	}{
+		{in: "13812345678", wantCarrier: CarrierMobile, wantMask: "138****5678"},
+		{in: "18612345678", wantCarrier: CarrierUnicom, wantMask: "186****5678"},
+		{in: "19912345678", wantCarrier: CarrierTelecom, wantMask: "199****5678"},
+		{in: "19212345678", wantCarrier: CarrierBroadnet, wantMask: "192****5678"},
+		{in: "057187654321", wantCity: "杭州", wantMask: "571****4321"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			n, err := Parse(tt.in, "CN")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n.Carrier() != tt.wantCarrier || n.City() != tt.wantCity {
+				t.Errorf("Carrier() = %q, City() = %q", n.Carrier(), n.City())
+			}
+			if n.Mask() != tt.wantMask {
+				t.Errorf("Mask() = %s, want %s", n.Mask(), tt.wantMask)
+			}
+		})
+	}
+	if !IsCNMobile("+86 13812345678") || IsCNMobile("+1 4155552671") {
+		t.Errorf("IsCNMobile() mismatch")
+	}
+}
@@ -0,0 +1,27 @@
+//go:build pluginso
+// +build pluginso
+
+package pluginx
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// Open 加载Go plugin(.so)并注册其导出的Plugin变量, 需使用pluginso构建标签;
+// 插件与宿主需使用相同版本的Go和依赖编译, 否则加载失败
+func (r *Registry) Open(path string) error {
+	so, err := plugin.Open(path)
+	if err != nil {
+		return err
+	}
+	sym, err := so.Lookup(Symbol)
+	if err != nil {
+		return err
+	}
+	p, ok := sym.(*Plugin)
+	if !ok {
+		return fmt.Errorf("%w: %s exports %T, want *pluginx.Plugin", ErrType, path, sym)
+	}
+	return r.Register(*p)
+}
@@ -0,0 +1,9 @@
+//go:build !pluginso
+// +build !pluginso
+
+package pluginx
+
+// Open 加载Go plugin(.so), 需使用pluginso构建标签, 否则返回ErrUnsupported
+func (r *Registry) Open(path string) error {
+	return ErrUnsupported
+}
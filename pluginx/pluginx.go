@@ -0,0 +1,154 @@
+package pluginx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ChangSZ/golib/semver"
+)
+
+var (
+	// ErrNotFound 插件未注册
+	ErrNotFound = errors.New("pluginx: plugin not found")
+	// ErrDuplicate 同名插件已注册
+	ErrDuplicate = errors.New("pluginx: duplicate plugin")
+	// ErrIncompatible 插件依据的接口版本与宿主不兼容
+	ErrIncompatible = errors.New("pluginx: incompatible api version")
+	// ErrType 插件的实现不是Lookup要求的类型
+	ErrType = errors.New("pluginx: unexpected plugin type")
+	// ErrUnsupported 未使用pluginso构建标签时不支持加载.so插件
+	ErrUnsupported = errors.New("pluginx: built without pluginso tag")
+)
+
+// Symbol .so插件中导出的插件描述变量名, 类型为pluginx.Plugin, 见Registry.Open:
+//
+//	// go build -buildmode=plugin -o s3.so ./plugins/s3
+//	var Plugin = pluginx.Plugin{Name: "s3", APIVersion: "1.2.0", Impl: &S3{}}
+const Symbol = "Plugin"
+
+// Plugin 插件描述
+type Plugin struct {
+	Name       string      // 插件名, 在同一个Registry中唯一
+	Version    string      // 插件自身的版本, 只用于展示, 可以为空
+	APIVersion string      // 插件实现时依据的接口版本, 语义化版本号, 如"1.2.0"
+	Impl       interface{} // 接口的实现, 通过Lookup按类型取出
+}
+
+// Registry 一个扩展点的插件注册表, 如存储后端、消息通道
+//
+// 宿主声明自己提供的接口版本, 插件声明实现时依据的接口版本, 注册时检查兼容性:
+// 主版本相同且插件依据的版本不高于宿主(主版本为0时次版本也需相同), 即宿主版本满足^APIVersion.
+// 接口只增加方法时升级次版本, 旧插件继续可用; 修改或删除方法时升级主版本, 旧插件注册失败
+type Registry struct {
+	mu      sync.RWMutex
+	api     *semver.Version
+	plugins map[string]Plugin
+}
+
+// NewRegistry new a Registry. api为宿主提供的接口版本, 不是合法的版本号时panic
+//
+//	var Storages = pluginx.NewRegistry("1.3.0")
+//
+//	// 插件包中编译期注册
+//	func init() {
+//		host.Storages.MustRegister(pluginx.Plugin{Name: "s3", APIVersion: "1.2.0", Impl: &S3{}})
+//	}
+//
+//	s, err := pluginx.Lookup[Storage](Storages, "s3")
+func NewRegistry(api string) *Registry {
+	return &Registry{
+		api:     semver.MustParse(api),
+		plugins: make(map[string]Plugin),
+	}
+}
+
+// APIVersion 宿主提供的接口版本
+func (r *Registry) APIVersion() string {
+	return r.api.String()
+}
+
+// Compatible 依据apiVersion实现的插件能否在该宿主中使用
+func (r *Registry) Compatible(apiVersion string) error {
+	v, err := semver.Parse(apiVersion)
+	if err != nil {
+		return err
+	}
+	c, err := semver.NewConstraint("^" + v.String())
+	if err != nil {
+		return err
+	}
+	if !c.Check(r.api) {
+		return fmt.Errorf("%w: plugin requires %s, host provides %s", ErrIncompatible, v, r.api)
+	}
+	return nil
+}
+
+// Register 注册插件, 名称为空、接口版本不兼容或同名插件已存在时返回错误
+func (r *Registry) Register(p Plugin) error {
+	if p.Name == "" || p.Impl == nil {
+		return errors.New("pluginx: plugin name and impl are required")
+	}
+	if err := r.Compatible(p.APIVersion); err != nil {
+		return fmt.Errorf("plugin %s: %w", p.Name, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.plugins[p.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicate, p.Name)
+	}
+	r.plugins[p.Name] = p
+	return nil
+}
+
+// MustRegister 同Register, 出错时panic, 用于init中注册
+func (r *Registry) MustRegister(p Plugin) {
+	if err := r.Register(p); err != nil {
+		panic(err)
+	}
+}
+
+// Get 返回插件描述
+func (r *Registry) Get(name string) (Plugin, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.plugins[name]
+	return p, ok
+}
+
+// Plugins 返回所有插件, 按名称排序
+func (r *Registry) Plugins() []Plugin {
+	r.mu.RLock()
+	plugins := make([]Plugin, 0, len(r.plugins))
+	for _, p := range r.plugins {
+		plugins = append(plugins, p)
+	}
+	r.mu.RUnlock()
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// Names 返回所有插件名, 按名称排序
+func (r *Registry) Names() []string {
+	plugins := r.Plugins()
+	names := make([]string, len(plugins))
+	for i, p := range plugins {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// Lookup 按名称取出插件的实现并转换为T, 通常T为扩展点的接口类型
+func Lookup[T any](r *Registry, name string) (T, error) {
+	var zero T
+	p, ok := r.Get(name)
+	if !ok {
+		return zero, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	impl, ok := p.Impl.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: %s is %T, want %T", ErrType, name, p.Impl, (*T)(nil))
+	}
+	return impl, nil
+}
@@ -0,0 +1,76 @@
+package pluginx
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type greeter interface {
+	Greet(name string) string
+}
+
+type hello struct{}
+
+func (hello) Greet(name string) string { return "hello " + name }
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name    string
+		api     string
+		plugin  Plugin
+		wantErr error
+	}{
+		{name: "same version", api: "1.2.0", plugin: Plugin{Name: "a", APIVersion: "1.2.0", Impl: hello{}}},
+		{name: "older minor", api: "1.3.0", plugin: Plugin{Name: "a", APIVersion: "v1.1.5", Impl: hello{}}},
+		{name: "newer minor", api: "1.2.0", plugin: Plugin{Name: "a", APIVersion: "1.3.0", Impl: hello{}}, wantErr: ErrIncompatible},
+		{name: "older major", api: "2.0.0", plugin: Plugin{Name: "a", APIVersion: "1.9.0", Impl: hello{}}, wantErr: ErrIncompatible},
+		{name: "v0 minor", api: "0.3.0", plugin: Plugin{Name: "a", APIVersion: "0.2.0", Impl: hello{}}, wantErr: ErrIncompatible},
+		{name: "v0 patch", api: "0.3.2", plugin: Plugin{Name: "a", APIVersion: "0.3.1", Impl: hello{}}},
+		{name: "invalid version", api: "1.0.0", plugin: Plugin{Name: "a", APIVersion: "1.x", Impl: hello{}}, wantErr: errors.New("")},
+		{name: "no impl", api: "1.0.0", plugin: Plugin{Name: "a", APIVersion: "1.0.0"}, wantErr: errors.New("")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewRegistry(tt.api).Register(tt.plugin)
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Errorf("Register() error = %v", err)
+			case tt.wantErr != nil && err == nil:
+				t.Errorf("Register() error = nil, want %v", tt.wantErr)
+			case tt.wantErr == ErrIncompatible && !errors.Is(err, ErrIncompatible):
+				t.Errorf("Register() error = %v, want ErrIncompatible", err)
+			}
+		})
+	}
+
+	r := NewRegistry("1.0.0")
+	r.MustRegister(Plugin{Name: "b", APIVersion: "1.0.0", Impl: hello{}})
+	r.MustRegister(Plugin{Name: "a", APIVersion: "1.0.0", Impl: hello{}})
+	if err := r.Register(Plugin{Name: "a", APIVersion: "1.0.0", Impl: hello{}}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Register() duplicate error = %v", err)
+	}
+	if got := r.Names(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Names() = %v", got)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	r := NewRegistry("1.0.0")
+	r.MustRegister(Plugin{Name: "hello", APIVersion: "1.0.0", Impl: hello{}})
+	r.MustRegister(Plugin{Name: "other", APIVersion: "1.0.0", Impl: 1})
+
+	g, err := Lookup[greeter](r, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Greet("go"); got != "hello go" {
+		t.Errorf("Greet() = %q", got)
+	}
+	if _, err := Lookup[greeter](r, "other"); !errors.Is(err, ErrType) {
+		t.Errorf("Lookup() error = %v, want ErrType", err)
+	}
+	if _, err := Lookup[greeter](r, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Lookup() error = %v, want ErrNotFound", err)
+	}
+}
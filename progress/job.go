@@ -0,0 +1,173 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// Job 运行中的任务, 进度上报方法可以并发调用
+type Job struct {
+	tracker *Tracker
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mu   sync.Mutex
+	snap Snapshot
+	subs map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	ch chan Snapshot
+}
+
+// ID 任务ID
+func (j *Job) ID() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.snap.ID
+}
+
+// Context 任务结束或被取消时取消
+func (j *Job) Context() context.Context {
+	return j.ctx
+}
+
+// Snapshot 当前状态
+func (j *Job) Snapshot() Snapshot {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.snap
+}
+
+// SetTotal 设置总量, 之后用Add上报完成量, 百分比自动计算
+func (j *Job) SetTotal(total int64) {
+	j.update(func(s *Snapshot) {
+		s.Total = total
+		s.Percent = percent(s.Done, s.Total)
+	})
+}
+
+// Add 增加完成量
+func (j *Job) Add(n int64) {
+	j.update(func(s *Snapshot) {
+		s.Done += n
+		s.Percent = percent(s.Done, s.Total)
+	})
+}
+
+// SetPercent 直接设置百分比, 用于无法统计总量的任务
+func (j *Job) SetPercent(p float64) {
+	j.update(func(s *Snapshot) {
+		s.Percent = min(max(p, 0), 100)
+	})
+}
+
+// SetStep 设置当前步骤和说明
+func (j *Job) SetStep(step, message string) {
+	j.update(func(s *Snapshot) {
+		s.Step = step
+		s.Message = message
+	})
+}
+
+// Finish 标记成功, result以JSON保存; 任务已结束时返回false
+func (j *Job) Finish(result interface{}) bool {
+	var raw json.RawMessage
+	if result != nil {
+		b, err := json.Marshal(result)
+		if err != nil {
+			return j.end(StatusFailed, nil, "marshal result: "+err.Error())
+		}
+		raw = b
+	}
+	return j.end(StatusSucceeded, raw, "")
+}
+
+// Fail 标记失败; 任务已结束时返回false
+func (j *Job) Fail(err error) bool {
+	msg := ""
+	if err != nil {
+		msg = err.Error()
+	}
+	return j.end(StatusFailed, nil, msg)
+}
+
+func percent(done, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return min(float64(done)*100/float64(total), 100)
+}
+
+// update 修改运行中任务的状态并通知订阅者, 任务已结束时忽略
+func (j *Job) update(fn func(s *Snapshot)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.snap.Status.Done() {
+		return
+	}
+	fn(&j.snap)
+	j.snap.UpdatedAt = j.tracker.now()
+	j.notify()
+}
+
+func (j *Job) end(status Status, result json.RawMessage, errMsg string) bool {
+	j.mu.Lock()
+	if j.snap.Status.Done() {
+		j.mu.Unlock()
+		return false
+	}
+	j.snap.Status = status
+	j.snap.Result = result
+	j.snap.Error = errMsg
+	j.snap.UpdatedAt = j.tracker.now()
+	if status == StatusSucceeded {
+		j.snap.Percent = 100
+	}
+	snap := j.snap
+	j.notify()
+	for sub := range j.subs {
+		close(sub.ch)
+	}
+	j.subs = nil
+	j.mu.Unlock()
+
+	j.cancel()
+	j.tracker.finish(snap)
+	return true
+}
+
+// notify 向订阅者发送最新状态, 丢弃未读取的旧状态, 调用方需持有锁
+func (j *Job) notify() {
+	for sub := range j.subs {
+		select {
+		case <-sub.ch:
+		default:
+		}
+		sub.ch <- j.snap
+	}
+}
+
+func (j *Job) subscribe() (<-chan Snapshot, func(), error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	sub := &subscriber{ch: make(chan Snapshot, 1)}
+	sub.ch <- j.snap
+	if j.snap.Status.Done() {
+		close(sub.ch)
+		return sub.ch, func() {}, nil
+	}
+	if j.subs == nil {
+		j.subs = make(map[*subscriber]struct{})
+	}
+	j.subs[sub] = struct{}{}
+	return sub.ch, func() {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		if _, ok := j.subs[sub]; ok {
+			delete(j.subs, sub)
+			close(sub.ch)
+		}
+	}, nil
+}
@@ -0,0 +1,171 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ChangSZ/golib/log"
+	"github.com/ChangSZ/golib/meta"
+)
+
+// Status 任务状态
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+// Done 是否已结束
+func (s Status) Done() bool {
+	return s != StatusRunning
+}
+
+// Snapshot 任务某一时刻的状态
+type Snapshot struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Status    Status          `json:"status"`
+	Percent   float64         `json:"percent"` // 0-100
+	Step      string          `json:"step,omitempty"`
+	Message   string          `json:"message,omitempty"`
+	Done      int64           `json:"done,omitempty"`
+	Total     int64           `json:"total,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type Config struct {
+	ResultTTL time.Duration `toml:"resultTTL"` // 结束后结果的保存时间, 默认24h
+}
+
+// Tracker 管理长时间运行任务的进度, 可以并发使用
+//
+//	id := tracker.Run(ctx, "export", func(ctx context.Context, job *progress.Job) (interface{}, error) {
+//		job.SetTotal(int64(len(rows)))
+//		for _, row := range rows {
+//			...
+//			job.Add(1)
+//		}
+//		return map[string]string{"url": url}, nil
+//	})
+type Tracker struct {
+	store Store
+	cfg   Config
+	now   func() time.Time
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+// NewTracker new a Tracker.
+func NewTracker(store Store, cfg Config) *Tracker {
+	if cfg.ResultTTL <= 0 {
+		cfg.ResultTTL = 24 * time.Hour
+	}
+	return &Tracker{
+		store: store,
+		cfg:   cfg,
+		now:   time.Now,
+		jobs:  make(map[string]*Job),
+	}
+}
+
+// Start 创建任务, 调用方负责上报进度并最终调用Finish或Fail; ctx在任务结束或Cancel时取消
+func (t *Tracker) Start(ctx context.Context, name string) *Job {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	now := t.now()
+	j := &Job{
+		tracker: t,
+		ctx:     ctx,
+		cancel:  cancel,
+		snap: Snapshot{
+			ID:        meta.NewRequestID(),
+			Name:      name,
+			Status:    StatusRunning,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+	t.mu.Lock()
+	t.jobs[j.snap.ID] = j
+	t.mu.Unlock()
+	return j
+}
+
+// Run 在新的goroutine中执行fn并返回任务ID, fn的返回值作为结果, panic视为失败
+func (t *Tracker) Run(ctx context.Context, name string, fn func(ctx context.Context, job *Job) (interface{}, error)) string {
+	j := t.Start(ctx, name)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Errorw("msg", "progress: job panic", "job", name, "id", j.ID(), "panic", r)
+				j.Fail(fmt.Errorf("panic: %v", r))
+			}
+		}()
+		result, err := fn(j.Context(), j)
+		if err != nil {
+			j.Fail(err)
+			return
+		}
+		j.Finish(result)
+	}()
+	return j.ID()
+}
+
+// Get 查询任务状态, 运行中的任务从内存读取, 已结束的从Store读取
+func (t *Tracker) Get(ctx context.Context, id string) (Snapshot, error) {
+	t.mu.Lock()
+	j, ok := t.jobs[id]
+	t.mu.Unlock()
+	if ok {
+		return j.Snapshot(), nil
+	}
+	return t.store.Get(ctx, id)
+}
+
+// Cancel 取消运行中的任务, 任务的ctx会被取消, 状态变为canceled
+func (t *Tracker) Cancel(id string) bool {
+	t.mu.Lock()
+	j, ok := t.jobs[id]
+	t.mu.Unlock()
+	if !ok {
+		return false
+	}
+	return j.end(StatusCanceled, nil, "canceled")
+}
+
+// Subscribe 订阅任务的进度更新, 通道只保留最新的状态, 任务结束后发送最终状态并关闭;
+// 任务已结束时发送最终状态后立即关闭. 不再需要时调用返回的cancel
+func (t *Tracker) Subscribe(ctx context.Context, id string) (<-chan Snapshot, func(), error) {
+	t.mu.Lock()
+	j, ok := t.jobs[id]
+	t.mu.Unlock()
+	if !ok {
+		s, err := t.store.Get(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		ch := make(chan Snapshot, 1)
+		ch <- s
+		close(ch)
+		return ch, func() {}, nil
+	}
+	return j.subscribe()
+}
+
+func (t *Tracker) finish(s Snapshot) {
+	if err := t.store.Save(context.Background(), s, t.cfg.ResultTTL); err != nil {
+		log.Warnw("msg", "progress: save result failed", "id", s.ID, "err", err)
+	}
+	t.mu.Lock()
+	delete(t.jobs, s.ID)
+	t.mu.Unlock()
+}
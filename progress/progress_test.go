@@ -0,0 +1,156 @@
+package progress
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobLifecycle(t *testing.T) {
+	tr := NewTracker(NewMemoryStore(), Config{})
+	ctx := context.Background()
+	job := tr.Start(ctx, "export")
+
+	ch, cancel, err := tr.Subscribe(ctx, job.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	if s := <-ch; s.Status != StatusRunning || s.Percent != 0 {
+		t.Errorf("initial snapshot = %+v", s)
+	}
+
+	job.SetTotal(4)
+	job.Add(1)
+	job.SetStep("write", "writing rows")
+	// 通道只保留最新状态
+	if s := <-ch; s.Percent != 25 || s.Step != "write" {
+		t.Errorf("latest snapshot = %+v", s)
+	}
+
+	job.Finish(map[string]string{"url": "/files/1.csv"})
+	final, ok := <-ch
+	if !ok || final.Status != StatusSucceeded || final.Percent != 100 || string(final.Result) != `{"url":"/files/1.csv"}` {
+		t.Errorf("final snapshot = %+v", final)
+	}
+	if _, ok := <-ch; ok {
+		t.Errorf("channel not closed after finish")
+	}
+	if job.Fail(errors.New("late")) || job.Context().Err() == nil {
+		t.Errorf("finished job accepted Fail or context not canceled")
+	}
+
+	// 结束后从Store读取
+	got, err := tr.Get(ctx, job.ID())
+	if err != nil || got.Status != StatusSucceeded {
+		t.Errorf("Get() = %+v, %v", got, err)
+	}
+	if _, err := tr.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) error = %v", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	tr := NewTracker(NewMemoryStore(), Config{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		fn         func(ctx context.Context, job *Job) (interface{}, error)
+		cancel     bool
+		wantStatus Status
+		wantError  string
+	}{
+		{
+			name:       "ok",
+			fn:         func(ctx context.Context, job *Job) (interface{}, error) { return 1, nil },
+			wantStatus: StatusSucceeded,
+		},
+		{
+			name:       "error",
+			fn:         func(ctx context.Context, job *Job) (interface{}, error) { return nil, errors.New("boom") },
+			wantStatus: StatusFailed,
+			wantError:  "boom",
+		},
+		{
+			name:       "panic",
+			fn:         func(ctx context.Context, job *Job) (interface{}, error) { panic("oops") },
+			wantStatus: StatusFailed,
+			wantError:  "panic: oops",
+		},
+		{
+			name: "canceled",
+			fn: func(ctx context.Context, job *Job) (interface{}, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			cancel:     true,
+			wantStatus: StatusCanceled,
+			wantError:  "canceled",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := tr.Run(ctx, tt.name, tt.fn)
+			ch, cancel, err := tr.Subscribe(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cancel()
+			if tt.cancel {
+				tr.Cancel(id)
+			}
+			var last Snapshot
+			for s := range ch {
+				last = s
+			}
+			if last.Status != tt.wantStatus || last.Error != tt.wantError {
+				t.Errorf("final = %+v", last)
+			}
+		})
+	}
+}
+
+func TestServeSSE(t *testing.T) {
+	tr := NewTracker(NewMemoryStore(), Config{})
+	job := tr.Start(context.Background(), "import")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tr.ServeSSE(w, r, strings.TrimPrefix(r.URL.Path, "/"))
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/" + job.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", resp.Header.Get("Content-Type"))
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		job.SetPercent(50)
+		time.Sleep(20 * time.Millisecond)
+		job.Finish(nil)
+	}()
+	var events []string
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		if data, ok := strings.CutPrefix(sc.Text(), "data: "); ok {
+			events = append(events, data)
+		}
+	}
+	if len(events) < 2 || !strings.Contains(events[len(events)-1], `"status":"succeeded"`) {
+		t.Errorf("events = %v", events)
+	}
+
+	if resp, _ := http.Get(srv.URL + "/missing"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("missing job status = %d", resp.StatusCode)
+	}
+}
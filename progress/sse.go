@@ -0,0 +1,49 @@
+package progress
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ServeSSE 以Server-Sent Events推送任务进度, 每次更新发送一个progress事件, 任务结束或客户端断开时返回
+//
+//	mux.HandleFunc("/jobs/{id}/events", func(w http.ResponseWriter, r *http.Request) {
+//		tracker.ServeSSE(w, r, r.PathValue("id"))
+//	})
+func (t *Tracker) ServeSSE(w http.ResponseWriter, r *http.Request, id string) {
+	ch, cancel, err := t.Subscribe(r.Context(), id)
+	if errors.Is(err, ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer cancel()
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case s, ok := <-ch:
+			if !ok {
+				return
+			}
+			b, _ := json.Marshal(s)
+			if _, err := fmt.Fprintf(w, "event: progress\ndata: %s\n\n", b); err != nil {
+				return
+			}
+			_ = rc.Flush()
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ChangSZ/golib/cache"
+)
+
+// ErrNotFound 任务不存在或结果已过期
+var ErrNotFound = errors.New("progress: job not found")
+
+// Store 保存已结束任务的最终状态, 供之后轮询
+type Store interface {
+	Save(ctx context.Context, s Snapshot, ttl time.Duration) error
+	// Get 不存在时返回ErrNotFound
+	Get(ctx context.Context, id string) (Snapshot, error)
+}
+
+var (
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*CacheStore)(nil)
+)
+
+// MemoryStore 进程内的Store
+type MemoryStore struct {
+	mu    sync.Mutex
+	items map[string]memoryItem
+	now   func() time.Time
+}
+
+type memoryItem struct {
+	snapshot Snapshot
+	expireAt time.Time
+}
+
+// NewMemoryStore new a MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: make(map[string]memoryItem), now: time.Now}
+}
+
+func (m *MemoryStore) Save(ctx context.Context, s Snapshot, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	// 顺便清理过期的结果
+	for id, item := range m.items {
+		if !now.Before(item.expireAt) {
+			delete(m.items, id)
+		}
+	}
+	m.items[s.ID] = memoryItem{snapshot: s, expireAt: now.Add(ttl)}
+	return nil
+}
+
+func (m *MemoryStore) Get(ctx context.Context, id string) (Snapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item, ok := m.items[id]
+	if !ok || !m.now().Before(item.expireAt) {
+		return Snapshot{}, ErrNotFound
+	}
+	return item.snapshot, nil
+}
+
+// CacheStore 基于cache.Remote的Store, 多实例部署时任一实例都能查询结果
+type CacheStore struct {
+	remote cache.Remote
+	prefix string
+}
+
+// NewCacheStore new a CacheStore, prefix会加在任务ID之前, 如"progress:"
+func NewCacheStore(remote cache.Remote, prefix string) *CacheStore {
+	return &CacheStore{remote: remote, prefix: prefix}
+}
+
+func (c *CacheStore) Save(ctx context.Context, s Snapshot, ttl time.Duration) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return c.remote.Set(ctx, c.prefix+s.ID, b, ttl)
+}
+
+func (c *CacheStore) Get(ctx context.Context, id string) (Snapshot, error) {
+	var s Snapshot
+	b, err := c.remote.Get(ctx, c.prefix+id)
+	if errors.Is(err, cache.ErrNotFound) {
+		return s, ErrNotFound
+	}
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(b, &s)
+	return s, err
+}
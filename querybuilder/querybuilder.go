@@ -0,0 +1,265 @@
+package querybuilder
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ChangSZ/golib/pagination"
+	"github.com/ChangSZ/golib/reflectutil"
+	"github.com/ChangSZ/golib/scanutil"
+	"github.com/ChangSZ/golib/tagparse"
+)
+
+// TagName 过滤条件的结构体标签, 如`filter:"created_at,gte"`, 第一段为列名, 第二段为操作符
+const TagName = "filter"
+
+// 操作符, 未指定时为eq
+const (
+	OpEq     = "eq"     // =
+	OpNe     = "ne"     // <>
+	OpGt     = "gt"     // >
+	OpGte    = "gte"    // >=
+	OpLt     = "lt"     // <
+	OpLte    = "lte"    // <=
+	OpLike   = "like"   // LIKE %v%
+	OpPrefix = "prefix" // LIKE v%
+	OpIn     = "in"     // IN (...), 字段为切片
+	OpNotIn  = "notin"  // NOT IN (...), 字段为切片
+	OpNull   = "null"   // 字段为bool, true为IS NULL, false为IS NOT NULL
+)
+
+var (
+	// ErrInvalidFilter filter不是结构体, 或标签中的操作符不支持
+	ErrInvalidFilter = errors.New("querybuilder: invalid filter")
+	// ErrInvalidSort 排序字段不在允许的范围内
+	ErrInvalidSort = errors.New("querybuilder: invalid sort")
+)
+
+// Placeholder 参数占位符风格
+type Placeholder int
+
+const (
+	// Question MySQL、SQLite使用的?
+	Question Placeholder = iota
+	// Dollar PostgreSQL使用的$1、$2
+	Dollar
+)
+
+// Option Build的可选参数
+type Option func(*options)
+
+type options struct {
+	placeholder Placeholder
+	sort        string
+	columns     map[string]string
+	page        *pagination.Request
+}
+
+// WithPlaceholder 参数占位符风格, 默认Question
+func WithPlaceholder(p Placeholder) Option {
+	return func(o *options) {
+		o.placeholder = p
+	}
+}
+
+// WithSort 排序, 见OrderBy
+func WithSort(sort string, columns map[string]string) Option {
+	return func(o *options) {
+		o.sort = sort
+		o.columns = columns
+	}
+}
+
+// WithPage 分页, 见Limit
+func WithPage(req pagination.Request) Option {
+	return func(o *options) {
+		o.page = &req
+	}
+}
+
+// Build 在base之后拼接WHERE、ORDER BY、LIMIT子句
+//
+//	type UserFilter struct {
+//		Name    string    `filter:"name,like"`
+//		Status  []int     `filter:"status,in"`
+//		Since   time.Time `filter:"created_at,gte"`
+//		Deleted *bool     `filter:"deleted_at,null"`
+//	}
+//
+//	query, args, err := querybuilder.Build("SELECT id, name FROM users", &f,
+//		querybuilder.WithSort(req.Sort, map[string]string{"created": "created_at", "name": "name"}),
+//		querybuilder.WithPage(req.Request))
+//	// SELECT id, name FROM users WHERE name LIKE ? AND status IN (?, ?) ORDER BY created_at DESC LIMIT 20 OFFSET 0
+func Build(base string, filter interface{}, opts ...Option) (string, []interface{}, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	where, args, err := Where(filter, WithPlaceholder(o.placeholder))
+	if err != nil {
+		return "", nil, err
+	}
+	orderBy, err := OrderBy(o.sort, o.columns)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString(base)
+	for _, clause := range []string{where, orderBy} {
+		if clause != "" {
+			b.WriteByte(' ')
+			b.WriteString(clause)
+		}
+	}
+	if o.page != nil {
+		b.WriteByte(' ')
+		b.WriteString(Limit(*o.page))
+	}
+	return b.String(), args, nil
+}
+
+// Where 根据filter的字段生成"WHERE ..."子句和参数, 条件之间为AND; 没有条件时返回空字符串
+//
+// filter为结构体或结构体指针, 规则:
+//   - 零值字段跳过(与copy.AssignStruct一致); 需要按零值过滤时使用指针字段, 如*bool、*int
+//   - in、notin的字段为非nil的空切片时, in生成恒假的"1=0", notin不生成条件
+//   - 列名取filter标签的第一段, 没有时使用字段名的snake_case形式, filter:"-"的字段跳过
+//   - 内嵌结构体的字段会被提升, 可以复用公共的过滤条件
+//
+// 列名来自结构体标签而不是用户输入, 不做转义
+func Where(filter interface{}, opts ...Option) (string, []interface{}, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	v := reflectutil.Indirect(reflect.ValueOf(filter), false)
+	if !v.IsValid() {
+		return "", nil, nil
+	}
+	if v.Kind() != reflect.Struct {
+		return "", nil, fmt.Errorf("%w: %T is not a struct", ErrInvalidFilter, filter)
+	}
+
+	var conds []string
+	var args []interface{}
+	bind := func(arg interface{}) string {
+		args = append(args, arg)
+		if o.placeholder == Dollar {
+			return "$" + strconv.Itoa(len(args))
+		}
+		return "?"
+	}
+	for _, f := range reflectutil.Fields(v.Type()) {
+		tag := tagparse.Get(f.StructField, TagName)
+		if tag.Skip {
+			continue
+		}
+		fv := reflectutil.FieldByIndex(v, f.Index, false)
+		if reflectutil.IsZero(fv) {
+			continue
+		}
+		fv = reflectutil.Indirect(fv, false)
+		column := tag.NameOr(scanutil.Snake(f.Name))
+		op := OpEq
+		if len(tag.Flags) > 0 {
+			op = tag.Flags[0]
+		}
+
+		var cond string
+		switch op {
+		case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte:
+			cond = column + " " + operators[op] + " " + bind(fv.Interface())
+		case OpLike, OpPrefix:
+			pattern := escapeLike(fmt.Sprint(fv.Interface())) + "%"
+			if op == OpLike {
+				pattern = "%" + pattern
+			}
+			cond = column + " LIKE " + bind(pattern)
+		case OpIn, OpNotIn:
+			if fv.Kind() != reflect.Slice && fv.Kind() != reflect.Array {
+				return "", nil, fmt.Errorf("%w: field %s with %s must be a slice", ErrInvalidFilter, f.Name, op)
+			}
+			if fv.Len() == 0 {
+				// 空的IN ()不是合法的SQL; 空集合IN为恒假, NOT IN为恒真, 直接跳过
+				if op == OpNotIn {
+					continue
+				}
+				cond = "1=0"
+				break
+			}
+			holders := make([]string, fv.Len())
+			for i := range holders {
+				holders[i] = bind(fv.Index(i).Interface())
+			}
+			keyword := " IN ("
+			if op == OpNotIn {
+				keyword = " NOT IN ("
+			}
+			cond = column + keyword + strings.Join(holders, ", ") + ")"
+		case OpNull:
+			if fv.Kind() != reflect.Bool {
+				return "", nil, fmt.Errorf("%w: field %s with %s must be a bool", ErrInvalidFilter, f.Name, op)
+			}
+			cond = column + " IS NOT NULL"
+			if fv.Bool() {
+				cond = column + " IS NULL"
+			}
+		default:
+			return "", nil, fmt.Errorf("%w: field %s has unknown operator %q", ErrInvalidFilter, f.Name, op)
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 0 {
+		return "", nil, nil
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args, nil
+}
+
+var operators = map[string]string{
+	OpEq:  "=",
+	OpNe:  "<>",
+	OpGt:  ">",
+	OpGte: ">=",
+	OpLt:  "<",
+	OpLte: "<=",
+}
+
+// escapeLike 转义LIKE中的通配符, 用户输入的%和_按字面匹配; MySQL、PostgreSQL默认的转义符均为\
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// OrderBy 根据"-created,name"形式的排序参数生成"ORDER BY ..."子句, "-"前缀表示降序; sort为空时返回空字符串
+//
+// columns为允许排序的字段到列名的映射, 不在其中的字段返回ErrInvalidSort, 避免SQL注入和按无索引的列排序
+func OrderBy(sort string, columns map[string]string) (string, error) {
+	if strings.TrimSpace(sort) == "" {
+		return "", nil
+	}
+	var parts []string
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		dir := "ASC"
+		if name, ok := strings.CutPrefix(field, "-"); ok {
+			field, dir = name, "DESC"
+		}
+		column, ok := columns[field]
+		if !ok {
+			return "", fmt.Errorf("%w: %q", ErrInvalidSort, field)
+		}
+		parts = append(parts, column+" "+dir)
+	}
+	return "ORDER BY " + strings.Join(parts, ", "), nil
+}
+
+// Limit 根据分页参数生成"LIMIT n OFFSET m"子句; 使用游标分页时只需要LIMIT, 条数为FetchLimit, 用于判断是否还有下一页
+func Limit(req pagination.Request) string {
+	if req.Cursor != "" {
+		return "LIMIT " + strconv.Itoa(req.FetchLimit())
+	}
+	return "LIMIT " + strconv.Itoa(req.GetLimit()) + " OFFSET " + strconv.Itoa(req.GetOffset())
+}
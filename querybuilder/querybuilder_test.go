@@ -0,0 +1,116 @@
+package querybuilder
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ChangSZ/golib/pagination"
+)
+
+type tenantFilter struct {
+	TenantID int64
+}
+
+type userFilter struct {
+	tenantFilter
+	Name    string    `filter:"name,like"`
+	Email   string    `filter:",prefix"`
+	Status  []int     `filter:"status,in"`
+	Since   time.Time `filter:"created_at,gte"`
+	Deleted *bool     `filter:"deleted_at,null"`
+	Age     *int      `filter:"age,ne"`
+	Keyword string    `filter:"-"`
+}
+
+func TestWhere(t *testing.T) {
+	yes, zero := true, 0
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		filter   interface{}
+		opts     []Option
+		want     string
+		wantArgs []interface{}
+		wantErr  error
+	}{
+		{name: "empty", filter: &userFilter{Keyword: "x"}, want: ""},
+		{name: "nil", filter: (*userFilter)(nil), want: ""},
+		{
+			name:     "all",
+			filter:   userFilter{tenantFilter: tenantFilter{TenantID: 3}, Name: "50%_off", Email: "a@", Status: []int{1, 2}, Since: since, Deleted: &yes, Age: &zero},
+			want:     `WHERE name LIKE ? AND email LIKE ? AND status IN (?, ?) AND created_at >= ? AND deleted_at IS NULL AND age <> ? AND tenant_id = ?`,
+			wantArgs: []interface{}{`%50\%\_off%`, "a@%", 1, 2, since, 0, int64(3)},
+		},
+		{
+			name:     "dollar",
+			filter:   &userFilter{Name: "a", Status: []int{1, 2}},
+			opts:     []Option{WithPlaceholder(Dollar)},
+			want:     "WHERE name LIKE $1 AND status IN ($2, $3)",
+			wantArgs: []interface{}{"%a%", 1, 2},
+		},
+		{
+			name:     "empty in",
+			filter:   &userFilter{Name: "a", Status: []int{}},
+			want:     "WHERE name LIKE ? AND 1=0",
+			wantArgs: []interface{}{"%a%"},
+		},
+		{name: "empty not in", filter: struct {
+			Status []int `filter:"status,notin"`
+		}{Status: []int{}}, want: ""},
+		{name: "not struct", filter: 1, wantErr: ErrInvalidFilter},
+		{name: "unknown operator", filter: struct {
+			A int `filter:"a,between"`
+		}{A: 1}, wantErr: ErrInvalidFilter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := Where(tt.filter, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Where() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Where() = %q %v, want %q %v", got, args, tt.want, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	columns := map[string]string{"created": "created_at", "name": "name"}
+	tests := []struct {
+		sort    string
+		want    string
+		wantErr bool
+	}{
+		{sort: "", want: ""},
+		{sort: "-created, name", want: "ORDER BY created_at DESC, name ASC"},
+		{sort: "password", wantErr: true},
+		{sort: "name;DROP TABLE users", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := OrderBy(tt.sort, columns)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("OrderBy(%q) = %q, %v", tt.sort, got, err)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	query, args, err := Build("SELECT id FROM users", &userFilter{Status: []int{1}},
+		WithSort("-created", map[string]string{"created": "created_at"}),
+		WithPage(pagination.Request{Page: 3, Limit: 10}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "SELECT id FROM users WHERE status IN (?) ORDER BY created_at DESC LIMIT 10 OFFSET 20"
+	if query != want || !reflect.DeepEqual(args, []interface{}{1}) {
+		t.Errorf("Build() = %q %v", query, args)
+	}
+
+	if got := Limit(pagination.Request{Cursor: "c", Limit: 10}); got != "LIMIT 11" {
+		t.Errorf("Limit() cursor = %q", got)
+	}
+}
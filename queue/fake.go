@@ -0,0 +1,182 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/ChangSZ/golib/testingx/fake"
+)
+
+// ErrClosed 队列已关闭
+var ErrClosed = errors.New("queue: closed")
+
+// FakeQueue Queue的内存替身, 用于单元测试
+//
+// 消息按优先级从高到低、同优先级先进先出投递; 通过内嵌的fake.Hooks可以查看调用记录、注入错误和延迟,
+// 方法名即Queue中的方法名
+type FakeQueue struct {
+	fake.Hooks
+	mu        sync.Mutex
+	pending   []fakeMessage
+	published []string
+	acked     []string
+	nacked    []string
+	notify    chan struct{}
+	closed    bool
+}
+
+type fakeMessage struct {
+	body     string
+	priority uint8
+}
+
+// NewFakeQueue new a FakeQueue.
+func NewFakeQueue() *FakeQueue {
+	return &FakeQueue{notify: make(chan struct{})}
+}
+
+// Published 所有成功生产的消息
+func (q *FakeQueue) Published() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]string(nil), q.published...)
+}
+
+// Acked 已确认的消息
+func (q *FakeQueue) Acked() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]string(nil), q.acked...)
+}
+
+// Nacked 被拒绝并重新入队的消息, 同一消息每次拒绝记录一次
+func (q *FakeQueue) Nacked() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]string(nil), q.nacked...)
+}
+
+// Len 待消费的消息数
+func (q *FakeQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+func (q *FakeQueue) ProduceWithCtx(ctx context.Context, value string, options PublishOptions) error {
+	if err := q.Before(ctx, "ProduceWithCtx", value, options); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	q.published = append(q.published, value)
+	q.push(fakeMessage{body: value, priority: options.Priority})
+	return nil
+}
+
+// push 入队并唤醒等待的消费者, 调用方需持有锁
+func (q *FakeQueue) push(msg fakeMessage) {
+	q.pending = append(q.pending, msg)
+	sort.SliceStable(q.pending, func(i, j int) bool {
+		return q.pending[i].priority > q.pending[j].priority
+	})
+	close(q.notify)
+	q.notify = make(chan struct{})
+}
+
+// pop 取出一条消息, 队列为空时等待
+func (q *FakeQueue) pop(ctx context.Context) (fakeMessage, error) {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return fakeMessage{}, ErrClosed
+		}
+		if len(q.pending) > 0 {
+			msg := q.pending[0]
+			q.pending = q.pending[1:]
+			q.mu.Unlock()
+			return msg, nil
+		}
+		notify := q.notify
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return fakeMessage{}, ctx.Err()
+		case <-notify:
+		}
+	}
+}
+
+func (q *FakeQueue) ConsumeWithCtx(ctx context.Context) (string, error) {
+	if err := q.Before(ctx, "ConsumeWithCtx"); err != nil {
+		return "", err
+	}
+	msg, err := q.pop(ctx)
+	if err != nil {
+		return "", err
+	}
+	q.mu.Lock()
+	q.acked = append(q.acked, msg.body)
+	q.mu.Unlock()
+	return msg.body, nil
+}
+
+// ConsumeFuncWithCtx f返回false的消息会以原优先级重新入队
+func (q *FakeQueue) ConsumeFuncWithCtx(ctx context.Context, f func(string) bool) error {
+	if err := q.Before(ctx, "ConsumeFuncWithCtx"); err != nil {
+		return err
+	}
+	for {
+		msg, err := q.pop(ctx)
+		if err != nil {
+			return err
+		}
+		ok := f(msg.body)
+		q.mu.Lock()
+		if ok {
+			q.acked = append(q.acked, msg.body)
+		} else {
+			q.nacked = append(q.nacked, msg.body)
+			q.push(msg)
+		}
+		q.mu.Unlock()
+	}
+}
+
+func (q *FakeQueue) Close() error {
+	if err := q.Before(context.Background(), "Close"); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.notify)
+		q.notify = make(chan struct{})
+	}
+	return nil
+}
+
+func (q *FakeQueue) IsClosed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closed
+}
+
+// Delete 清空待消费的消息
+func (q *FakeQueue) Delete() error {
+	if err := q.Before(context.Background(), "Delete"); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = nil
+	return nil
+}
@@ -0,0 +1,89 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFakeQueue(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var q Queue = NewFakeQueue()
+	fq := q.(*FakeQueue)
+	for i, v := range []string{"low", "high", "mid"} {
+		_ = q.ProduceWithCtx(ctx, v, PublishOptions{Priority: []uint8{1, 9, 5}[i]})
+	}
+
+	got, err := q.ConsumeWithCtx(ctx)
+	if err != nil || got != "high" {
+		t.Fatalf("ConsumeWithCtx() = %s, %v, want high", got, err)
+	}
+
+	// mid第一次被拒绝, 重新入队后再次投递
+	rejected := false
+	consumeCtx, stop := context.WithCancel(ctx)
+	var seen []string
+	err = q.ConsumeFuncWithCtx(consumeCtx, func(msg string) bool {
+		seen = append(seen, msg)
+		if msg == "mid" && !rejected {
+			rejected = true
+			return false
+		}
+		if fq.Len() == 0 {
+			stop()
+		}
+		return true
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ConsumeFuncWithCtx() error = %v", err)
+	}
+	if len(seen) != 3 || seen[0] != "mid" || seen[1] != "mid" || seen[2] != "low" {
+		t.Errorf("seen = %v", seen)
+	}
+	if n := fq.Nacked(); len(n) != 1 || n[0] != "mid" {
+		t.Errorf("Nacked() = %v", n)
+	}
+	if a := fq.Acked(); len(a) != 3 {
+		t.Errorf("Acked() = %v", a)
+	}
+}
+
+func TestFakeQueueInjection(t *testing.T) {
+	q := NewFakeQueue()
+	errDown := errors.New("broker down")
+	q.FailNext("ProduceWithCtx", errDown)
+
+	ctx := context.Background()
+	if err := q.ProduceWithCtx(ctx, "a", PublishOptions{}); err != errDown {
+		t.Errorf("ProduceWithCtx() error = %v, want %v", err, errDown)
+	}
+	if err := q.ProduceWithCtx(ctx, "b", PublishOptions{}); err != nil {
+		t.Errorf("ProduceWithCtx() error = %v", err)
+	}
+	if got := q.Published(); len(got) != 1 || got[0] != "b" {
+		t.Errorf("Published() = %v", got)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, _ = q.ConsumeWithCtx(ctx)
+		_, err := q.ConsumeWithCtx(ctx)
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	_ = q.Close()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("ConsumeWithCtx() after Close error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ConsumeWithCtx() not woken by Close")
+	}
+	if !q.IsClosed() || q.CallCount("ConsumeWithCtx") != 2 {
+		t.Errorf("IsClosed() = %v, calls = %d", q.IsClosed(), q.CallCount("ConsumeWithCtx"))
+	}
+}
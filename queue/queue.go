@@ -0,0 +1,21 @@
+package queue
+
+import "context"
+
+var (
+	_ Queue = (*RabbitMQ)(nil)
+	_ Queue = (*FakeQueue)(nil)
+)
+
+// Queue 消息队列的抽象, 业务代码依赖该接口以便在测试中替换为FakeQueue
+type Queue interface {
+	// ProduceWithCtx 生产消息
+	ProduceWithCtx(ctx context.Context, value string, options PublishOptions) error
+	// ConsumeWithCtx 每次消费1个消息
+	ConsumeWithCtx(ctx context.Context) (string, error)
+	// ConsumeFuncWithCtx 持续消费, f返回false时消息重新入队
+	ConsumeFuncWithCtx(ctx context.Context, f func(string) bool) error
+	Close() error
+	IsClosed() bool
+	Delete() error
+}
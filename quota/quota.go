@@ -0,0 +1,166 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidAmount Consume的数量必须大于0
+var ErrInvalidAmount = errors.New("quota: amount must be positive")
+
+// Config 配额配置
+type Config struct {
+	Limit   int64         `toml:"limit"`   // 每个窗口的配额
+	Window  time.Duration `toml:"window"`  // 窗口长度, 默认24h
+	Rolling bool          `toml:"rolling"` // 滚动窗口, 默认固定窗口
+	Prefix  string        `toml:"prefix"`  // key前缀, 默认"quota:"
+}
+
+// Result 配额使用情况
+type Result struct {
+	Allowed   bool
+	Limit     int64
+	Used      int64
+	Remaining int64
+	// ResetAt 当前窗口结束的时间; 固定窗口此时已用量清零, 滚动窗口此后上一窗口的计数开始按比例淡出
+	ResetAt time.Time
+}
+
+// Option Quota的可选参数
+type Option func(*Quota)
+
+// WithLocation 固定窗口按该时区对齐, 如按天的配额在当地零点重置, 默认time.Local
+func WithLocation(loc *time.Location) Option {
+	return func(q *Quota) {
+		q.loc = loc
+	}
+}
+
+// WithClock 替换时钟, 用于测试
+func WithClock(now func() time.Time) Option {
+	return func(q *Quota) {
+		q.now = now
+	}
+}
+
+// Quota 按key统计一个时间窗口内的用量, 如每个用户每天最多导出100次
+//
+// 与限流不同, 配额关注较长周期内的总量, 计数需要在多个实例之间共享并在窗口结束后自动重置:
+//
+//   - 固定窗口: 窗口按时区对齐(如每天零点), 窗口结束后计数清零
+//   - 滚动窗口: 近似的滑动窗口, 已用量 = 当前窗口计数 + 上一窗口计数 * 上一窗口仍在滑动窗口内的比例,
+//     避免固定窗口在边界前后各用满一次配额
+//
+//	q := quota.New(quota.NewRedisStore(client), quota.Config{Limit: 100, Window: 24 * time.Hour})
+//	res, err := q.Consume(ctx, "export:"+userID, 1)
+//	if err == nil && !res.Allowed {
+//		return fmt.Errorf("今日导出次数已用完, 将于%s重置", res.ResetAt.Format(time.DateTime))
+//	}
+type Quota struct {
+	store Store
+	cfg   Config
+	loc   *time.Location
+	now   func() time.Time
+}
+
+// New new a Quota.
+func New(store Store, cfg Config, opts ...Option) *Quota {
+	if cfg.Window <= 0 {
+		cfg.Window = 24 * time.Hour
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "quota:"
+	}
+	q := &Quota{
+		store: store,
+		cfg:   cfg,
+		loc:   time.Local,
+		now:   time.Now,
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// window 当前窗口的开始时间, 按时区偏移对齐
+func (q *Quota) window(now time.Time) time.Time {
+	_, offset := now.In(q.loc).Zone()
+	shift := time.Duration(offset) * time.Second
+	return now.Add(shift).Truncate(q.cfg.Window).Add(-shift)
+}
+
+// keys 当前窗口与上一窗口(仅滚动窗口)的key, 以及上一窗口计数的权重
+//
+// key带hash tag, Redis Cluster中两个窗口的key位于同一slot, 可以在一个脚本中访问
+func (q *Quota) keys(key string, now time.Time) (cur, prev string, weight float64, start time.Time) {
+	start = q.window(now)
+	base := q.cfg.Prefix + "{" + key + "}:"
+	cur = base + strconv.FormatInt(start.Unix(), 10)
+	if q.cfg.Rolling {
+		prev = base + strconv.FormatInt(start.Add(-q.cfg.Window).Unix(), 10)
+		weight = 1 - float64(now.Sub(start))/float64(q.cfg.Window)
+	}
+	return cur, prev, weight, start
+}
+
+// ttl 滚动窗口的计数在下一个窗口中仍然需要
+func (q *Quota) ttl(start, now time.Time) time.Duration {
+	end := start.Add(q.cfg.Window)
+	if q.cfg.Rolling {
+		end = end.Add(q.cfg.Window)
+	}
+	return end.Sub(now)
+}
+
+func (q *Quota) result(allowed bool, used int64, start time.Time) Result {
+	return Result{
+		Allowed:   allowed,
+		Limit:     q.cfg.Limit,
+		Used:      used,
+		Remaining: max(q.cfg.Limit-used, 0),
+		ResetAt:   start.Add(q.cfg.Window),
+	}
+}
+
+// Check 查询配额, 不消耗; 还有剩余时Allowed为true
+func (q *Quota) Check(ctx context.Context, key string) (Result, error) {
+	now := q.now()
+	cur, prev, weight, start := q.keys(key, now)
+	c, p, err := q.store.Get(ctx, cur, prev)
+	if err != nil {
+		return Result{}, fmt.Errorf("quota: check %s: %w", key, err)
+	}
+	u := used(c, p, weight)
+	return q.result(u < q.cfg.Limit, u, start), nil
+}
+
+// Consume 消耗n个配额, 剩余不足时不消耗并返回Allowed为false
+func (q *Quota) Consume(ctx context.Context, key string, n int64) (Result, error) {
+	if n <= 0 {
+		return Result{}, ErrInvalidAmount
+	}
+	now := q.now()
+	cur, prev, weight, start := q.keys(key, now)
+	u, ok, err := q.store.Consume(ctx, cur, prev, weight, n, q.cfg.Limit, q.ttl(start, now))
+	if err != nil {
+		return Result{}, fmt.Errorf("quota: consume %s: %w", key, err)
+	}
+	return q.result(ok, u, start), nil
+}
+
+// Reset 清空key在当前窗口(滚动窗口含上一窗口)的用量, 如客服手动恢复用户的配额
+func (q *Quota) Reset(ctx context.Context, key string) error {
+	cur, prev, _, _ := q.keys(key, q.now())
+	keys := []string{cur}
+	if prev != "" {
+		keys = append(keys, prev)
+	}
+	if err := q.store.Delete(ctx, keys...); err != nil {
+		return fmt.Errorf("quota: reset %s: %w", key, err)
+	}
+	return nil
+}
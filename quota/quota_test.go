@@ -0,0 +1,82 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFixedWindow(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	now := time.Date(2024, 5, 1, 23, 0, 0, 0, loc)
+	q := New(NewMemoryStore(), Config{Limit: 3}, WithLocation(loc), WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		advance     time.Duration
+		n           int64
+		wantAllowed bool
+		wantUsed    int64
+	}{
+		{name: "first", n: 1, wantAllowed: true, wantUsed: 1},
+		{name: "batch", n: 2, wantAllowed: true, wantUsed: 3},
+		{name: "exceeded", n: 1, wantAllowed: false, wantUsed: 3},
+		{name: "reset at local midnight", advance: time.Hour, n: 1, wantAllowed: true, wantUsed: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			res, err := q.Consume(ctx, "u1", tt.n)
+			if err != nil {
+				t.Fatalf("Consume() error = %v", err)
+			}
+			if res.Allowed != tt.wantAllowed || res.Used != tt.wantUsed || res.Remaining != 3-tt.wantUsed {
+				t.Errorf("Consume() = %+v", res)
+			}
+		})
+	}
+
+	res, _ := q.Check(ctx, "u1")
+	if want := time.Date(2024, 5, 3, 0, 0, 0, 0, loc); !res.ResetAt.Equal(want) || res.Used != 1 || !res.Allowed {
+		t.Errorf("Check() = %+v, want reset at %v", res, want)
+	}
+	if err := q.Reset(ctx, "u1"); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := q.Check(ctx, "u1"); res.Used != 0 {
+		t.Errorf("Check() after Reset = %+v", res)
+	}
+	if _, err := q.Consume(ctx, "u1", 0); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Consume(0) error = %v", err)
+	}
+}
+
+func TestRollingWindow(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	q := New(NewMemoryStore(), Config{Limit: 10, Window: time.Hour, Rolling: true},
+		WithLocation(time.UTC), WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	if res, _ := q.Consume(ctx, "u1", 10); !res.Allowed {
+		t.Fatalf("Consume() = %+v", res)
+	}
+	// 下一个窗口过去1/4, 上一窗口的10次按3/4计入
+	now = now.Add(time.Hour + 15*time.Minute)
+	res, _ := q.Check(ctx, "u1")
+	if res.Used != 7 {
+		t.Errorf("Check() used = %d, want 7", res.Used)
+	}
+	if res, _ := q.Consume(ctx, "u1", 4); res.Allowed {
+		t.Errorf("Consume(4) = %+v, want denied", res)
+	}
+	if res, _ := q.Consume(ctx, "u1", 3); !res.Allowed || res.Used != 10 {
+		t.Errorf("Consume(3) = %+v", res)
+	}
+	// 两个窗口之后上一窗口的计数全部淡出
+	now = now.Add(2 * time.Hour)
+	if res, _ := q.Check(ctx, "u1"); res.Used != 0 {
+		t.Errorf("Check() = %+v, want 0", res)
+	}
+}
@@ -0,0 +1,180 @@
+package quota
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Store 计数存储, Consume需要保证原子性, 多个实例共享配额时使用RedisStore
+type Store interface {
+	// Consume 已用量 = cur的计数 + prev的计数*weight(向下取整), 已用量加n不超过limit时cur增加n并设置过期时间ttl
+	//
+	// 返回本次操作后的已用量和是否成功; 失败时计数不变
+	Consume(ctx context.Context, cur, prev string, weight float64, n, limit int64, ttl time.Duration) (used int64, ok bool, err error)
+	// Get 读取cur和prev的计数, 不存在时为0
+	Get(ctx context.Context, cur, prev string) (curCount, prevCount int64, err error)
+	// Delete 删除计数
+	Delete(ctx context.Context, keys ...string) error
+}
+
+var (
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*RedisStore)(nil)
+)
+
+// used 已用量, prev按weight折算后向下取整
+func used(cur, prev int64, weight float64) int64 {
+	return cur + int64(math.Floor(float64(prev)*weight))
+}
+
+// MemoryStore 进程内存储, 用于测试和单机部署, 过期的计数在访问时和定期清理时删除
+type MemoryStore struct {
+	mu     sync.Mutex
+	counts map[string]memoryCount
+	ops    int
+	now    func() time.Time
+}
+
+type memoryCount struct {
+	n        int64
+	expireAt time.Time
+}
+
+// memoryGCEvery 每隔多少次写入清理一次过期计数
+const memoryGCEvery = 1024
+
+// NewMemoryStore new a MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counts: make(map[string]memoryCount),
+		now:    time.Now,
+	}
+}
+
+// load 读取未过期的计数, 调用方需持有锁
+func (s *MemoryStore) load(key string) int64 {
+	c, ok := s.counts[key]
+	if !ok {
+		return 0
+	}
+	if !s.now().Before(c.expireAt) {
+		delete(s.counts, key)
+		return 0
+	}
+	return c.n
+}
+
+func (s *MemoryStore) Consume(ctx context.Context, cur, prev string, weight float64, n, limit int64, ttl time.Duration) (int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gc()
+	c, p := s.load(cur), int64(0)
+	if prev != "" {
+		p = s.load(prev)
+	}
+	u := used(c, p, weight)
+	if u+n > limit {
+		return u, false, nil
+	}
+	s.counts[cur] = memoryCount{n: c + n, expireAt: s.now().Add(ttl)}
+	return u + n, true, nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, cur, prev string) (int64, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, p := s.load(cur), int64(0)
+	if prev != "" {
+		p = s.load(prev)
+	}
+	return c, p, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range keys {
+		delete(s.counts, k)
+	}
+	return nil
+}
+
+// gc 定期清理过期计数, 避免大量一次性key占用内存, 调用方需持有锁
+func (s *MemoryStore) gc() {
+	s.ops++
+	if s.ops < memoryGCEvery {
+		return
+	}
+	s.ops = 0
+	now := s.now()
+	for k, c := range s.counts {
+		if !now.Before(c.expireAt) {
+			delete(s.counts, k)
+		}
+	}
+}
+
+// consumeScript KEYS[1]=cur KEYS[2]=prev, ARGV: weight n limit ttl(ms)
+var consumeScript = redis.NewScript(`
+local cur = tonumber(redis.call("GET", KEYS[1]) or "0")
+local prev = 0
+if KEYS[2] ~= "" then
+	prev = tonumber(redis.call("GET", KEYS[2]) or "0")
+end
+local used = cur + math.floor(prev * tonumber(ARGV[1]))
+local n = tonumber(ARGV[2])
+if used + n > tonumber(ARGV[3]) then
+	return {used, 0}
+end
+redis.call("INCRBY", KEYS[1], n)
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+return {used + n, 1}
+`)
+
+// RedisStore 基于Redis的存储, 使用Lua脚本保证检查与增加的原子性
+type RedisStore struct {
+	client redis.Cmdable
+}
+
+// NewRedisStore new a RedisStore.
+func NewRedisStore(client redis.Cmdable) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Consume(ctx context.Context, cur, prev string, weight float64, n, limit int64, ttl time.Duration) (int64, bool, error) {
+	res, err := consumeScript.Run(ctx, s.client, []string{cur, prev}, weight, n, limit, ttl.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, false, err
+	}
+	return res[0], res[1] == 1, nil
+}
+
+func (s *RedisStore) Get(ctx context.Context, cur, prev string) (int64, int64, error) {
+	keys := []string{cur}
+	if prev != "" {
+		keys = append(keys, prev)
+	}
+	vals, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+	counts := make([]int64, 2)
+	for i, v := range vals {
+		if str, ok := v.(string); ok {
+			counts[i], _ = strconv.ParseInt(str, 10, 64)
+		}
+	}
+	return counts[0], counts[1], nil
+}
+
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
@@ -0,0 +1,62 @@
+package redisx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sep key各段之间的分隔符
+const Sep = ":"
+
+// Keys key构造器, 所有key以相同的命名空间开头, 避免不同业务之间冲突
+//
+//	users := redisx.NewKeys("app", "user")
+//	users.Key(42)                     // app:user:42
+//	users.Sub("email").Key("a@b.com") // app:user:email:a@b.com
+type Keys struct {
+	prefix string
+}
+
+// NewKeys new a Keys, namespace的各段用":"连接
+func NewKeys(namespace ...string) Keys {
+	return Keys{prefix: strings.Join(namespace, Sep)}
+}
+
+// Sub 在当前命名空间下追加一段
+func (k Keys) Sub(name string) Keys {
+	return Keys{prefix: k.join(name)}
+}
+
+// Key 由命名空间和parts拼接出完整的key, parts按fmt.Sprint格式化
+func (k Keys) Key(parts ...interface{}) string {
+	s := make([]string, len(parts))
+	for i, p := range parts {
+		s[i] = fmt.Sprint(p)
+	}
+	return k.join(strings.Join(s, Sep))
+}
+
+// Pattern 匹配当前命名空间下所有key的SCAN模式, 如"app:user:*"
+func (k Keys) Pattern() string {
+	return k.join("*")
+}
+
+// Prefix 命名空间前缀, 为空时返回空字符串, 否则以":"结尾
+func (k Keys) Prefix() string {
+	if k.prefix == "" {
+		return ""
+	}
+	return k.prefix + Sep
+}
+
+// Trim 去掉key的命名空间前缀, 不属于当前命名空间时ok为false
+func (k Keys) Trim(key string) (string, bool) {
+	return strings.CutPrefix(key, k.Prefix())
+}
+
+func (k Keys) join(s string) string {
+	if k.prefix == "" {
+		return s
+	}
+	return k.prefix + Sep + s
+}
@@ -0,0 +1,77 @@
+package redisx
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// DefaultBatchSize Batch默认每个pipeline包含的命令数
+const DefaultBatchSize = 500
+
+// Pipelined 在一个pipeline中执行fn添加的命令, 返回每条命令的结果;
+// 与redis.Pipelined不同, 单条命令返回redis.Nil不视为错误
+func (c *Client) Pipelined(ctx context.Context, fn func(pipe redis.Pipeliner) error) ([]redis.Cmder, error) {
+	cmds, err := c.rdb.Pipelined(ctx, fn)
+	if err == redis.Nil {
+		err = nil
+	}
+	return cmds, err
+}
+
+// Batch 对n个元素分批执行pipeline, 每批最多size条, size<=0时使用DefaultBatchSize;
+// fn为第i个元素添加命令, 需要自行调用Key加命名空间前缀. 某批失败时返回, 之前的批次已经执行
+//
+//	err := rc.Batch(ctx, len(ids), 0, func(pipe redis.Pipeliner, i int) {
+//		pipe.Expire(ctx, rc.Key(keys.Key(ids[i])), time.Hour)
+//	})
+func (c *Client) Batch(ctx context.Context, n, size int, fn func(pipe redis.Pipeliner, i int)) error {
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		_, err := c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i := start; i < end; i++ {
+				fn(pipe, i)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DelPattern 通过SCAN删除匹配pattern的key, pattern会加上命名空间前缀, 返回删除的数量;
+// 适用于清理少量key, 大量key时应考虑设置过期时间
+func (c *Client) DelPattern(ctx context.Context, pattern string) (int64, error) {
+	var deleted int64
+	iter := c.rdb.Scan(ctx, 0, c.Key(pattern), DefaultBatchSize).Iterator()
+	batch := make([]string, 0, DefaultBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := c.rdb.Del(ctx, batch...).Result()
+		deleted += n
+		batch = batch[:0]
+		return err
+	}
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == cap(batch) {
+			if err := flush(); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, err
+	}
+	return deleted, flush()
+}
@@ -0,0 +1,169 @@
+package redisx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrNotFound key不存在
+var ErrNotFound = errors.New("redisx: not found")
+
+// Option Client的可选参数
+type Option func(*Client)
+
+// WithCodec 值的编解码方式, 默认JSON
+func WithCodec(codec Codec) Option {
+	return func(c *Client) {
+		c.codec = codec
+	}
+}
+
+// WithNamespace Client的所有读写都加上命名空间前缀, 见Keys
+func WithNamespace(namespace ...string) Option {
+	return func(c *Client) {
+		c.keys = NewKeys(namespace...)
+	}
+}
+
+// Client 在redis.Cmdable之上提供结构体读写、批量操作和Lua脚本的便捷方法;
+// 需要原生命令时使用Redis(), 注意其不会加命名空间前缀
+//
+//	rc := redisx.New(rdb, redisx.WithNamespace("app"), redisx.WithCodec(redisx.Msgpack))
+//	err := redisx.Set(ctx, rc, "user:42", user, time.Hour)
+//	user, err := redisx.Get[User](ctx, rc, "user:42")
+type Client struct {
+	rdb   redis.Cmdable
+	codec Codec
+	keys  Keys
+}
+
+// New new a Client, rdb可以是*redis.Client、*redis.ClusterClient等
+func New(rdb redis.Cmdable, opts ...Option) *Client {
+	c := &Client{rdb: rdb, codec: JSON}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Redis 原生客户端
+func (c *Client) Redis() redis.Cmdable {
+	return c.rdb
+}
+
+// Key 加上命名空间前缀后的key
+func (c *Client) Key(key string) string {
+	return c.keys.join(key)
+}
+
+// Del 删除keys
+func (c *Client) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.rdb.Del(ctx, c.fullKeys(keys)...).Err()
+}
+
+func (c *Client) fullKeys(keys []string) []string {
+	full := make([]string, len(keys))
+	for i, key := range keys {
+		full[i] = c.Key(key)
+	}
+	return full
+}
+
+// Get 读取key并解码为T, key不存在时返回ErrNotFound
+func Get[T any](ctx context.Context, c *Client, key string) (T, error) {
+	var v T
+	b, err := c.rdb.Get(ctx, c.Key(key)).Bytes()
+	if err == redis.Nil {
+		return v, ErrNotFound
+	}
+	if err != nil {
+		return v, err
+	}
+	if err := c.codec.Unmarshal(b, &v); err != nil {
+		return v, fmt.Errorf("redisx: decode %s: %w", key, err)
+	}
+	return v, nil
+}
+
+// Set 编码v并写入key, ttl为0时不过期
+func Set[T any](ctx context.Context, c *Client, key string, v T, ttl time.Duration) error {
+	b, err := c.codec.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("redisx: encode %s: %w", key, err)
+	}
+	return c.rdb.Set(ctx, c.Key(key), b, ttl).Err()
+}
+
+// SetNX key不存在时写入, 返回是否写入成功
+func SetNX[T any](ctx context.Context, c *Client, key string, v T, ttl time.Duration) (bool, error) {
+	b, err := c.codec.Marshal(v)
+	if err != nil {
+		return false, fmt.Errorf("redisx: encode %s: %w", key, err)
+	}
+	return c.rdb.SetNX(ctx, c.Key(key), b, ttl).Result()
+}
+
+// MGet 批量读取, 返回的map中只包含存在的key
+func MGet[T any](ctx context.Context, c *Client, keys ...string) (map[string]T, error) {
+	result := make(map[string]T, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+	values, err := c.rdb.MGet(ctx, c.fullKeys(keys)...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, raw := range values {
+		s, ok := raw.(string)
+		if !ok {
+			continue
+		}
+		var v T
+		if err := c.codec.Unmarshal([]byte(s), &v); err != nil {
+			return nil, fmt.Errorf("redisx: decode %s: %w", keys[i], err)
+		}
+		result[keys[i]] = v
+	}
+	return result, nil
+}
+
+// MSet 批量写入, 使用pipeline为每个key设置相同的ttl
+func MSet[T any](ctx context.Context, c *Client, values map[string]T, ttl time.Duration) error {
+	if len(values) == 0 {
+		return nil
+	}
+	encoded := make(map[string][]byte, len(values))
+	for key, v := range values {
+		b, err := c.codec.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("redisx: encode %s: %w", key, err)
+		}
+		encoded[key] = b
+	}
+	_, err := c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, b := range encoded {
+			pipe.Set(ctx, c.Key(key), b, ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+// GetOrSet 读取key, 不存在时调用load并写入; load的错误原样返回, 不写入缓存
+func GetOrSet[T any](ctx context.Context, c *Client, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	v, err := Get[T](ctx, c, key)
+	if !errors.Is(err, ErrNotFound) {
+		return v, err
+	}
+	if v, err = load(ctx); err != nil {
+		return v, err
+	}
+	return v, Set(ctx, c, key, v, ttl)
+}
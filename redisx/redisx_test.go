@@ -0,0 +1,92 @@
+package redisx
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKeys(t *testing.T) {
+	users := NewKeys("app", "user")
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "key", got: users.Key(42), want: "app:user:42"},
+		{name: "multi parts", got: users.Key("a", 1), want: "app:user:a:1"},
+		{name: "sub", got: users.Sub("email").Key("a@b.com"), want: "app:user:email:a@b.com"},
+		{name: "pattern", got: users.Pattern(), want: "app:user:*"},
+		{name: "prefix", got: users.Prefix(), want: "app:user:"},
+		{name: "empty namespace", got: NewKeys().Key("x"), want: "x"},
+		{name: "client", got: New(nil, WithNamespace("app")).Key("user:1"), want: "app:user:1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+
+	if id, ok := users.Trim("app:user:42"); !ok || id != "42" {
+		t.Errorf("Trim() = %q, %v", id, ok)
+	}
+	if _, ok := users.Trim("app:order:42"); ok {
+		t.Error("Trim() of other namespace ok = true")
+	}
+}
+
+type user struct {
+	ID      int64     `json:"id" msgpack:"id"`
+	Name    string    `json:"name" msgpack:"name"`
+	Tags    []string  `json:"tags" msgpack:"tags"`
+	Created time.Time `json:"created" msgpack:"created"`
+}
+
+func TestCodec(t *testing.T) {
+	in := user{ID: 1, Name: "a", Tags: []string{"x"}, Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	for name, codec := range map[string]Codec{"json": JSON, "msgpack": Msgpack} {
+		t.Run(name, func(t *testing.T) {
+			b, err := codec.Marshal(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out user
+			if err := codec.Unmarshal(b, &out); err != nil {
+				t.Fatal(err)
+			}
+			out.Created = out.Created.UTC()
+			if !reflect.DeepEqual(in, out) {
+				t.Errorf("round trip = %+v, want %+v", out, in)
+			}
+		})
+	}
+}
+
+func TestScripts(t *testing.T) {
+	s := NewScripts()
+	s.Register("b", "return 1")
+	s.Register("a", "return 2")
+	if got := s.Names(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Names() = %v", got)
+	}
+	// echo -n "return 1" | sha1sum
+	if sha, ok := s.SHA("b"); !ok || sha != "e0e1f9fabfc9d4800c877a703b823ac0578ff8db" {
+		t.Errorf("SHA() = %q, %v", sha, ok)
+	}
+	if _, ok := s.SHA("c"); ok {
+		t.Error("SHA() of unknown script ok = true")
+	}
+	if err := s.Run(context.Background(), nil, "c", nil).Err(); err == nil {
+		t.Error("Run() of unknown script error = nil")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() twice did not panic")
+		}
+	}()
+	s.Register("a", "return 3")
+}
@@ -0,0 +1,91 @@
+package redisx
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Scripts Lua脚本注册表, 按名称管理脚本;
+// 执行时优先使用EVALSHA, 服务端没有缓存该脚本(NOSCRIPT)时自动回退为EVAL
+//
+//	var scripts = redisx.NewScripts()
+//	var incrCapped = scripts.Register("incr_capped", `...`)
+//
+//	_ = scripts.Load(ctx, rdb) // 启动时预加载, 可选
+//	n, err := incrCapped.Run(ctx, rdb, []string{key}, limit).Int64()
+type Scripts struct {
+	mu      sync.RWMutex
+	scripts map[string]*redis.Script
+}
+
+// NewScripts new a Scripts.
+func NewScripts() *Scripts {
+	return &Scripts{scripts: make(map[string]*redis.Script)}
+}
+
+// Register 注册脚本, 名称重复时panic(与http.Handle一致, 通常在初始化时调用)
+func (s *Scripts) Register(name, src string) *redis.Script {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.scripts[name]; ok {
+		panic("redisx: script " + name + " registered twice")
+	}
+	script := redis.NewScript(src)
+	s.scripts[name] = script
+	return script
+}
+
+// Get 按名称获取脚本
+func (s *Scripts) Get(name string) (*redis.Script, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	script, ok := s.scripts[name]
+	return script, ok
+}
+
+// SHA 脚本的SHA1, 与SCRIPT LOAD返回值相同
+func (s *Scripts) SHA(name string) (string, bool) {
+	script, ok := s.Get(name)
+	if !ok {
+		return "", false
+	}
+	return script.Hash(), true
+}
+
+// Names 已注册的脚本名称, 按字典序
+func (s *Scripts) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.scripts))
+	for name := range s.scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load 将所有脚本加载到服务端缓存, 集群模式下会加载到所有主节点
+func (s *Scripts) Load(ctx context.Context, rdb redis.Scripter) error {
+	for _, name := range s.Names() {
+		script, _ := s.Get(name)
+		if err := script.Load(ctx, rdb).Err(); err != nil {
+			return fmt.Errorf("redisx: load script %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Run 按名称执行脚本, keys不会加命名空间前缀
+func (s *Scripts) Run(ctx context.Context, rdb redis.Scripter, name string, keys []string, args ...interface{}) *redis.Cmd {
+	script, ok := s.Get(name)
+	if !ok {
+		cmd := redis.NewCmd(ctx)
+		cmd.SetErr(fmt.Errorf("redisx: script %s not registered", name))
+		return cmd
+	}
+	return script.Run(ctx, rdb, keys, args...)
+}
@@ -0,0 +1,109 @@
+package reflectutil
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Field 结构体的可导出字段, 内嵌结构体的字段会被提升, Index可用于reflect.Value.FieldByIndex
+type Field struct {
+	reflect.StructField
+	Index []int
+}
+
+var fieldCache sync.Map // reflect.Type -> []Field
+
+// Fields 返回结构体类型的全部可导出字段, 结果按类型缓存, 调用方不能修改
+//
+// 内嵌结构体(含指针)的字段按Go的提升规则展开, 浅层字段覆盖深层同名字段; 非结构体类型返回nil
+func Fields(t reflect.Type) []Field {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]Field)
+	}
+	fields := collectFields(t, nil, map[reflect.Type]bool{})
+	actual, _ := fieldCache.LoadOrStore(t, fields)
+	return actual.([]Field)
+}
+
+func collectFields(t reflect.Type, parent []int, visited map[reflect.Type]bool) []Field {
+	visited[t] = true
+	var own, promoted []Field
+	seen := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		index := append(append([]int(nil), parent...), i)
+
+		if sf.Anonymous {
+			et := sf.Type
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct && !visited[et] {
+				promoted = append(promoted, collectFields(et, index, visited)...)
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		own = append(own, Field{StructField: sf, Index: index})
+		seen[sf.Name] = true
+	}
+	delete(visited, t)
+
+	for _, f := range promoted {
+		if !seen[f.Name] {
+			own = append(own, f)
+			seen[f.Name] = true
+		}
+	}
+	return own
+}
+
+// FieldByName 按名称查找Fields中的字段
+func FieldByName(t reflect.Type, name string) (Field, bool) {
+	for _, f := range Fields(t) {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// FieldByIndex 按Index取字段值, alloc为true时为路径上可设置的nil内嵌指针分配内存
+//
+// 路径上存在无法分配的nil指针时返回无效值
+func FieldByIndex(v reflect.Value, index []int, alloc bool) reflect.Value {
+	for i, x := range index {
+		if i > 0 {
+			v = Indirect(v, alloc)
+			if !v.IsValid() {
+				return v
+			}
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// EachField 依次访问结构体v的可导出字段, fn返回false时停止
+func EachField(v reflect.Value, fn func(f Field, fv reflect.Value) bool) {
+	v = Indirect(v, false)
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return
+	}
+	for _, f := range Fields(v.Type()) {
+		fv := FieldByIndex(v, f.Index, false)
+		if !fv.IsValid() {
+			continue
+		}
+		if !fn(f, fv) {
+			return
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package reflectutil
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type Base struct {
+	ID      int
+	Created time.Time
+}
+
+type Profile struct {
+	City string
+}
+
+type User struct {
+	Base
+	*Profile
+	ID     string // 覆盖Base.ID
+	Name   string
+	Tags   []string
+	Attrs  map[string]int
+	secret string
+}
+
+func TestIsZero(t *testing.T) {
+	var nilPtr *User
+	tests := []struct {
+		name string
+		v    reflect.Value
+		want bool
+	}{
+		{name: "invalid", v: reflect.Value{}, want: true},
+		{name: "nil ptr", v: reflect.ValueOf(nilPtr), want: true},
+		{name: "empty slice", v: reflect.ValueOf([]int{}), want: false},
+		{name: "nil map", v: reflect.ValueOf(map[string]int(nil)), want: true},
+		{name: "zero struct", v: reflect.ValueOf(User{}), want: true},
+		{name: "time in other zone", v: reflect.ValueOf(time.Time{}.In(time.FixedZone("x", 3600))), want: true},
+		{name: "string", v: reflect.ValueOf("a"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsZero(tt.v); got != tt.want {
+				t.Errorf("IsZero() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFields(t *testing.T) {
+	var names []string
+	for _, f := range Fields(reflect.TypeOf(&User{})) {
+		names = append(names, f.Name)
+	}
+	want := []string{"Base", "Profile", "ID", "Name", "Tags", "Attrs", "Created", "City"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Fields() = %v, want %v", names, want)
+	}
+	if f, _ := FieldByName(reflect.TypeOf(User{}), "ID"); f.Type.Kind() != reflect.String {
+		t.Errorf("FieldByName(ID) should be the outer field, got %v", f.Type)
+	}
+
+	u := User{}
+	v := reflect.ValueOf(&u).Elem()
+	city, _ := FieldByName(v.Type(), "City")
+	if got := FieldByIndex(v, city.Index, false); got.IsValid() {
+		t.Errorf("FieldByIndex() through nil pointer should be invalid")
+	}
+	FieldByIndex(v, city.Index, true).SetString("hz")
+	if u.Profile == nil || u.City != "hz" {
+		t.Errorf("FieldByIndex(alloc) did not allocate: %+v", u.Profile)
+	}
+
+	count := 0
+	EachField(reflect.ValueOf(u), func(f Field, fv reflect.Value) bool {
+		count++
+		return f.Name != "Name"
+	})
+	if count != 4 {
+		t.Errorf("EachField() visited %d fields before stop, want 4", count)
+	}
+}
+
+func TestFieldByPath(t *testing.T) {
+	u := &User{Name: "jack", Tags: []string{"a", "b"}, Attrs: map[string]int{"age": 18}, Profile: &Profile{City: "sh"}}
+	u.Base.ID = 7
+	tests := []struct {
+		path    string
+		want    interface{}
+		wantErr bool
+	}{
+		{path: "Name", want: "jack"},
+		{path: "Base.ID", want: 7},
+		{path: "City", want: "sh"},
+		{path: "Profile.City", want: "sh"},
+		{path: "Tags.1", want: "b"},
+		{path: "Attrs.age", want: 18},
+		{path: "Tags.5", wantErr: true},
+		{path: "Missing", wantErr: true},
+		{path: "Attrs.none", wantErr: true},
+		{path: "secret", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			v, err := FieldByPath(reflect.ValueOf(u), tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FieldByPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && v.Interface() != tt.want {
+				t.Errorf("FieldByPath() = %v, want %v", v.Interface(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSetValueCoerced(t *testing.T) {
+	var s struct {
+		N   int
+		P   *int64
+		S   string
+		B   bool
+		Any interface{}
+	}
+	v := reflect.ValueOf(&s).Elem()
+	tests := []struct {
+		field   string
+		src     interface{}
+		wantErr bool
+	}{
+		{field: "N", src: "42"},
+		{field: "P", src: 3.0},
+		{field: "S", src: 12},
+		{field: "B", src: "true"},
+		{field: "Any", src: []int{1}},
+		{field: "N", src: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if err := SetValueCoerced(v.FieldByName(tt.field), tt.src); (err != nil) != tt.wantErr {
+				t.Errorf("SetValueCoerced() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if s.N != 42 || s.P == nil || *s.P != 3 || s.S != "12" || !s.B || s.Any == nil {
+		t.Errorf("result = %+v", s)
+	}
+}
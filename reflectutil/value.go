@@ -0,0 +1,139 @@
+package reflectutil
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/ChangSZ/golib/conv"
+)
+
+type zeroer interface {
+	IsZero() bool
+}
+
+// IsZero 判断是否为零值, 适用于所有Kind
+//
+// 无效值和nil视为零值; 实现了IsZero() bool的类型(如time.Time)以其结果为准
+func IsZero(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+		if v.IsNil() {
+			return true
+		}
+	}
+	if v.CanInterface() {
+		if z, ok := v.Interface().(zeroer); ok {
+			return z.IsZero()
+		}
+	}
+	return v.IsZero()
+}
+
+// Indirect 解引用指针直到非指针类型, alloc为true时为可设置的nil指针分配内存
+//
+// 遇到无法分配的nil指针时返回无效值
+func Indirect(v reflect.Value, alloc bool) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if !alloc || !v.CanSet() {
+				return reflect.Value{}
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// SetValueCoerced 将src赋值给dst, 类型不同时按conv.Convert的弱类型规则转换
+//
+// dst为指针时自动分配内存并赋值给指向的值; src为nil时将dst置为零值
+func SetValueCoerced(dst reflect.Value, src interface{}) error {
+	if !dst.CanSet() {
+		return fmt.Errorf("reflectutil: cannot set %v", dst.Type())
+	}
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dst.Type()) {
+		dst.Set(sv)
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		if _, ok := conv.Lookup(sv.Type(), dst.Type()); !ok {
+			if dst.IsNil() {
+				dst.Set(reflect.New(dst.Type().Elem()))
+			}
+			return SetValueCoerced(dst.Elem(), src)
+		}
+	}
+	v, err := conv.Convert(sv, dst.Type(), true)
+	if err != nil {
+		return err
+	}
+	dst.Set(v)
+	return nil
+}
+
+// FieldByPath 按"A.B.0.C"形式的路径取值, 支持结构体字段、字符串key的map和切片/数组下标
+//
+// 路径中的nil指针不会被分配, 此时返回错误
+func FieldByPath(v reflect.Value, path string) (reflect.Value, error) {
+	if path == "" {
+		return v, nil
+	}
+	for _, name := range splitPath(path) {
+		cur := Indirect(v, false)
+		if !cur.IsValid() {
+			return reflect.Value{}, fmt.Errorf("reflectutil: nil pointer before %q in %q", name, path)
+		}
+		switch cur.Kind() {
+		case reflect.Struct:
+			f, ok := FieldByName(cur.Type(), name)
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("reflectutil: %v has no field %q", cur.Type(), name)
+			}
+			fv, err := cur.FieldByIndexErr(f.Index)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("reflectutil: field %q: %w", name, err)
+			}
+			v = fv
+		case reflect.Map:
+			if cur.Type().Key().Kind() != reflect.String {
+				return reflect.Value{}, fmt.Errorf("reflectutil: unsupported map key %v", cur.Type().Key())
+			}
+			mv := cur.MapIndex(reflect.ValueOf(name).Convert(cur.Type().Key()))
+			if !mv.IsValid() {
+				return reflect.Value{}, fmt.Errorf("reflectutil: map key %q not found", name)
+			}
+			v = mv
+		case reflect.Slice, reflect.Array:
+			i, err := strconv.Atoi(name)
+			if err != nil || i < 0 || i >= cur.Len() {
+				return reflect.Value{}, fmt.Errorf("reflectutil: index %q out of range [0, %d)", name, cur.Len())
+			}
+			v = cur.Index(i)
+		default:
+			return reflect.Value{}, fmt.Errorf("reflectutil: cannot traverse %v with %q", cur.Type(), name)
+		}
+	}
+	return v, nil
+}
+
+func splitPath(path string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(path); i++ {
+		if path[i] == '.' {
+			parts = append(parts, path[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, path[start:])
+}
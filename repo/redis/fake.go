@@ -0,0 +1,224 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/ChangSZ/golib/testingx/fake"
+)
+
+var _ Repo = (*FakeRepo)(nil)
+
+// FakeRepo Repo的内存替身, 用于单元测试
+//
+// 通过内嵌的fake.Hooks可以查看调用记录、注入错误和延迟, 方法名即Repo中的方法名:
+//
+//	repo := redis.NewFakeRepo()
+//	repo.FailNext("Get", errors.New("boom"))
+type FakeRepo struct {
+	fake.Hooks
+	mu   sync.Mutex
+	data map[string]fakeItem
+	now  func() time.Time
+}
+
+type fakeItem struct {
+	value    string
+	expireAt time.Time // 零值表示不过期
+}
+
+// NewFakeRepo new a FakeRepo.
+func NewFakeRepo() *FakeRepo {
+	return &FakeRepo{
+		data: make(map[string]fakeItem),
+		now:  time.Now,
+	}
+}
+
+// SetClock 替换时钟, 用于测试过期逻辑
+func (f *FakeRepo) SetClock(now func() time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// Keys 当前未过期的全部key
+func (f *FakeRepo) Keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]string, 0, len(f.data))
+	for k := range f.data {
+		if _, ok := f.load(k); ok {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func (f *FakeRepo) i() {}
+
+// load 读取未过期的值, 调用方需持有锁
+func (f *FakeRepo) load(key string) (fakeItem, bool) {
+	item, ok := f.data[key]
+	if !ok {
+		return fakeItem{}, false
+	}
+	if !item.expireAt.IsZero() && !f.now().Before(item.expireAt) {
+		delete(f.data, key)
+		return fakeItem{}, false
+	}
+	return item, true
+}
+
+func (f *FakeRepo) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if err := f.Before(ctx, "Set", key, value, ttl); err != nil {
+		return fmt.Errorf("redis set key: %s err: %w", key, err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item := fakeItem{value: format(value)}
+	if ttl > 0 {
+		item.expireAt = f.now().Add(ttl)
+	}
+	f.data[key] = item
+	return nil
+}
+
+// Get key不存在时与go-redis一致返回redis.Nil
+func (f *FakeRepo) Get(ctx context.Context, key string) (string, error) {
+	if err := f.Before(ctx, "Get", key); err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, ok := f.load(key)
+	if !ok {
+		return "", redis.Nil
+	}
+	return item.value, nil
+}
+
+// TTL 与go-redis一致, key不存在返回-2, 未设置过期时间返回-1
+func (f *FakeRepo) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := f.Before(ctx, "TTL", key); err != nil {
+		return -1, fmt.Errorf("redis get key: %s err: %w", key, err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, ok := f.load(key)
+	if !ok {
+		return -2, nil
+	}
+	if item.expireAt.IsZero() {
+		return -1, nil
+	}
+	return item.expireAt.Sub(f.now()).Truncate(time.Second), nil
+}
+
+func (f *FakeRepo) Expire(ctx context.Context, key string, ttl time.Duration) bool {
+	if err := f.Before(ctx, "Expire", key, ttl); err != nil {
+		return false
+	}
+	return f.expireAt(key, f.now().Add(ttl))
+}
+
+func (f *FakeRepo) ExpireAt(ctx context.Context, key string, ttl time.Time) bool {
+	if err := f.Before(ctx, "ExpireAt", key, ttl); err != nil {
+		return false
+	}
+	return f.expireAt(key, ttl)
+}
+
+func (f *FakeRepo) expireAt(key string, at time.Time) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, ok := f.load(key)
+	if !ok {
+		return false
+	}
+	item.expireAt = at
+	f.data[key] = item
+	return true
+}
+
+func (f *FakeRepo) Del(ctx context.Context, key string) bool {
+	if err := f.Before(ctx, "Del", key); err != nil {
+		return false
+	}
+	if key == "" {
+		return true
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.load(key)
+	delete(f.data, key)
+	return ok
+}
+
+func (f *FakeRepo) Exists(ctx context.Context, keys ...string) bool {
+	if err := f.Before(ctx, "Exists", keys); err != nil {
+		return false
+	}
+	if len(keys) == 0 {
+		return true
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, k := range keys {
+		if _, ok := f.load(k); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Incr 值不是整数或注入错误时返回0
+func (f *FakeRepo) Incr(ctx context.Context, key string) int64 {
+	if err := f.Before(ctx, "Incr", key); err != nil {
+		return 0
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, _ := f.load(key)
+	n := int64(0)
+	if item.value != "" {
+		var err error
+		if n, err = strconv.ParseInt(item.value, 10, 64); err != nil {
+			return 0
+		}
+	}
+	n++
+	item.value = strconv.FormatInt(n, 10)
+	f.data[key] = item
+	return n
+}
+
+func (f *FakeRepo) Close() error {
+	return f.Before(context.Background(), "Close")
+}
+
+func (f *FakeRepo) Version() string {
+	return "fake"
+}
+
+// format 与go-redis写入参数的格式化方式保持一致
+func format(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case nil:
+		return ""
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return val.String()
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestFakeRepo(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := NewFakeRepo()
+	repo.SetClock(func() time.Time { return now })
+
+	_ = repo.Set(ctx, "name", "jack", time.Minute)
+	_ = repo.Set(ctx, "count", 41, 0)
+
+	tests := []struct {
+		name    string
+		key     string
+		advance time.Duration
+		want    string
+		wantTTL time.Duration
+		wantErr error
+	}{
+		{name: "with ttl", key: "name", want: "jack", wantTTL: time.Minute},
+		{name: "no ttl", key: "count", want: "41", wantTTL: -1},
+		{name: "missing", key: "none", wantTTL: -2, wantErr: redis.Nil},
+		{name: "expired", key: "name", advance: 2 * time.Minute, wantTTL: -2, wantErr: redis.Nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			got, err := repo.Get(ctx, tt.key)
+			if got != tt.want || err != tt.wantErr {
+				t.Errorf("Get() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+			if ttl, _ := repo.TTL(ctx, tt.key); ttl != tt.wantTTL {
+				t.Errorf("TTL() = %v, want %v", ttl, tt.wantTTL)
+			}
+		})
+	}
+
+	if n := repo.Incr(ctx, "count"); n != 42 {
+		t.Errorf("Incr() = %d, want 42", n)
+	}
+	if !repo.Exists(ctx, "none", "count") || !repo.Del(ctx, "count") || repo.Exists(ctx, "count") {
+		t.Errorf("Exists/Del mismatch")
+	}
+}
+
+func TestFakeRepoInjection(t *testing.T) {
+	ctx := context.Background()
+	repo := NewFakeRepo()
+	errDown := errors.New("conn refused")
+	repo.FailAlways("Set", errDown)
+
+	if err := repo.Set(ctx, "k", "v", 0); !errors.Is(err, errDown) {
+		t.Errorf("Set() error = %v, want %v", err, errDown)
+	}
+	if repo.Exists(ctx, "k") {
+		t.Errorf("failed Set should not write")
+	}
+	if calls := repo.Calls("Set"); len(calls) != 1 || calls[0].Args[0] != "k" {
+		t.Errorf("Calls() = %+v", calls)
+	}
+}
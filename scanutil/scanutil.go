@@ -0,0 +1,220 @@
+package scanutil
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ChangSZ/golib/reflectutil"
+	"github.com/ChangSZ/golib/tagparse"
+)
+
+// TagName 列名对应的结构体标签
+const TagName = "db"
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// Rows ScanAll需要的sql.Rows方法
+type Rows interface {
+	Columns() ([]string, error)
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+	Close() error
+}
+
+var _ Rows = (*sql.Rows)(nil)
+
+// ScanAll 读取rows中的全部行写入dest, 完成后关闭rows
+//
+// dest可以是:
+//   - *[]T、*[]*T: T为结构体时按列名映射字段, 否则要求结果只有一列
+//   - *T: 只读取第一行, 没有数据时返回sql.ErrNoRows
+//
+// 列名与字段的匹配规则: 优先使用db标签, 没有标签时使用字段名的snake_case形式, 均忽略大小写;
+// 内嵌结构体的字段会被提升, db:"-"的字段跳过, 没有对应字段的列丢弃。
+// 可以为NULL的列请使用指针或sql.NullString等类型的字段
+//
+//	var users []User
+//	rows, err := db.QueryContext(ctx, "SELECT id, user_name, deleted_at FROM user")
+//	if err != nil {
+//		return err
+//	}
+//	err = scanutil.ScanAll(rows, &users)
+func ScanAll(rows Rows, dest interface{}) error {
+	defer rows.Close()
+
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return fmt.Errorf("scanutil: dest must be a non-nil pointer, got %T", dest)
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	dv = dv.Elem()
+	if dv.Kind() != reflect.Slice || dv.Type().Elem().Kind() == reflect.Uint8 {
+		// 非切片或[]byte视为单行
+		p, err := planFor(dv.Type(), columns)
+		if err != nil {
+			return err
+		}
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return err
+			}
+			return sql.ErrNoRows
+		}
+		if err := p.scan(rows, dv); err != nil {
+			return err
+		}
+		return rows.Err()
+	}
+
+	elemType := dv.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	baseType := elemType
+	if isPtr {
+		baseType = elemType.Elem()
+	}
+	p, err := planFor(baseType, columns)
+	if err != nil {
+		return err
+	}
+
+	out := reflect.MakeSlice(dv.Type(), 0, 0)
+	for rows.Next() {
+		elem := reflect.New(baseType)
+		if err := p.scan(rows, elem.Elem()); err != nil {
+			return err
+		}
+		if isPtr {
+			out = reflect.Append(out, elem)
+		} else {
+			out = reflect.Append(out, elem.Elem())
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	dv.Set(out)
+	return nil
+}
+
+// plan 某个类型在某组列上的扫描计划, fields[i]为第i列对应字段的Index, nil表示丢弃该列
+type plan struct {
+	scalar bool
+	fields [][]int
+}
+
+type planKey struct {
+	t       reflect.Type
+	columns string
+}
+
+var planCache sync.Map // planKey -> *plan
+
+func planFor(t reflect.Type, columns []string) (*plan, error) {
+	key := planKey{t: t, columns: strings.Join(columns, ",")}
+	if cached, ok := planCache.Load(key); ok {
+		return cached.(*plan), nil
+	}
+	p, err := buildPlan(t, columns)
+	if err != nil {
+		return nil, err
+	}
+	planCache.Store(key, p)
+	return p, nil
+}
+
+func buildPlan(t reflect.Type, columns []string) (*plan, error) {
+	if !isStruct(t) {
+		if len(columns) != 1 {
+			return nil, fmt.Errorf("scanutil: scan %d columns into non-struct type %v", len(columns), t)
+		}
+		return &plan{scalar: true}, nil
+	}
+
+	byName := make(map[string][]int)
+	for _, f := range reflectutil.Fields(t) {
+		tag := tagparse.Get(f.StructField, TagName)
+		if tag.Skip {
+			continue
+		}
+		// 内嵌结构体本身不作为列, 其字段已被提升
+		if f.Anonymous && tag.Name == "" && isStruct(indirectType(f.Type)) {
+			continue
+		}
+		name := strings.ToLower(tag.NameOr(Snake(f.Name)))
+		if _, ok := byName[name]; !ok {
+			byName[name] = f.Index
+		}
+	}
+
+	p := &plan{fields: make([][]int, len(columns))}
+	matched := false
+	for i, col := range columns {
+		if index, ok := byName[strings.ToLower(col)]; ok {
+			p.fields[i] = index
+			matched = true
+		}
+	}
+	if !matched && len(columns) > 0 {
+		return nil, errors.New("scanutil: no column matches any field of " + t.String())
+	}
+	return p, nil
+}
+
+func (p *plan) scan(rows Rows, v reflect.Value) error {
+	if p.scalar {
+		return rows.Scan(v.Addr().Interface())
+	}
+	targets := make([]interface{}, len(p.fields))
+	for i, index := range p.fields {
+		if index == nil {
+			targets[i] = new(interface{})
+			continue
+		}
+		targets[i] = reflectutil.FieldByIndex(v, index, true).Addr().Interface()
+	}
+	return rows.Scan(targets...)
+}
+
+// isStruct 是否按字段映射, 实现了sql.Scanner的结构体(如sql.NullString)和time.Time作为单个值
+func isStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || reflect.PtrTo(t).Implements(scannerType) {
+		return false
+	}
+	return t.PkgPath() != "time" || t.Name() != "Time"
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// Snake 将字段名转为snake_case, 连续的大写视为一个单词, 如UserID为user_id, HTTPServer为http_server
+func Snake(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	b.Grow(len(name) + 4)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
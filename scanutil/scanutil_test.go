@@ -0,0 +1,165 @@
+package scanutil
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeDriver 每次查询都返回固定的结果
+type fakeDriver struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{d: c.d}, nil
+}
+
+type fakeRows struct {
+	d *fakeDriver
+	i int
+}
+
+func (r *fakeRows) Columns() []string { return r.d.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.d.rows) {
+		return io.EOF
+	}
+	copy(dest, r.d.rows[r.i])
+	r.i++
+	return nil
+}
+
+var driverSeq int
+
+func query(t *testing.T, columns []string, rows ...[]driver.Value) *sql.Rows {
+	t.Helper()
+	driverSeq++
+	name := "scanutil-fake-" + string(rune('a'+driverSeq))
+	sql.Register(name, &fakeDriver{columns: columns, rows: rows})
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	rs, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	return rs
+}
+
+type Base struct {
+	ID        int64
+	CreatedAt time.Time
+}
+
+type user struct {
+	Base
+	UserName  string
+	Nickname  *string `db:"nick"`
+	Email     sql.NullString
+	DeletedAt *time.Time
+	Ignored   string `db:"-"`
+}
+
+func TestScanAll(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	nick := "J"
+	columns := []string{"id", "user_name", "NICK", "email", "deleted_at", "created_at", "unknown"}
+	data := [][]driver.Value{
+		{int64(1), "jack", "J", "jack@example.com", nil, now, "x"},
+		{int64(2), []byte("rose"), nil, nil, now, now, "y"},
+	}
+	want := []user{
+		{Base: Base{ID: 1, CreatedAt: now}, UserName: "jack", Nickname: &nick,
+			Email: sql.NullString{String: "jack@example.com", Valid: true}},
+		{Base: Base{ID: 2, CreatedAt: now}, UserName: "rose", DeletedAt: &now},
+	}
+
+	t.Run("slice", func(t *testing.T) {
+		var got []user
+		if err := ScanAll(query(t, columns, data...), &got); err != nil {
+			t.Fatalf("ScanAll() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ScanAll() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("pointer slice", func(t *testing.T) {
+		var got []*user
+		if err := ScanAll(query(t, columns, data...), &got); err != nil {
+			t.Fatalf("ScanAll() error = %v", err)
+		}
+		if len(got) != 2 || !reflect.DeepEqual(*got[1], want[1]) {
+			t.Errorf("ScanAll() = %+v", got)
+		}
+	})
+
+	t.Run("single", func(t *testing.T) {
+		var got user
+		if err := ScanAll(query(t, columns, data...), &got); err != nil || !reflect.DeepEqual(got, want[0]) {
+			t.Errorf("ScanAll() = %+v, %v", got, err)
+		}
+		if err := ScanAll(query(t, columns), &got); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("ScanAll() error = %v, want sql.ErrNoRows", err)
+		}
+	})
+
+	t.Run("scalar", func(t *testing.T) {
+		var ids []int64
+		if err := ScanAll(query(t, []string{"id"}, []driver.Value{int64(1)}, []driver.Value{int64(2)}), &ids); err != nil ||
+			!reflect.DeepEqual(ids, []int64{1, 2}) {
+			t.Errorf("ScanAll() = %v, %v", ids, err)
+		}
+		var names []string
+		if err := ScanAll(query(t, []string{"id", "name"}), &names); err == nil {
+			t.Errorf("ScanAll() want error for multiple columns")
+		}
+	})
+
+	t.Run("null into value", func(t *testing.T) {
+		var got []user
+		err := ScanAll(query(t, []string{"user_name"}, []driver.Value{nil}), &got)
+		if err == nil {
+			t.Errorf("ScanAll() want error for NULL into string")
+		}
+	})
+}
+
+func TestSnake(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"ID", "id"},
+		{"UserID", "user_id"},
+		{"HTTPServer", "http_server"},
+		{"CreatedAt", "created_at"},
+		{"Addr2Line", "addr2_line"},
+		{"name", "name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Snake(tt.in); got != tt.want {
+				t.Errorf("Snake() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package scheduler
+
+import "time"
+
+// Overlap 上一次执行尚未结束时再次触发的处理方式
+type Overlap int
+
+const (
+	// OverlapSkip 跳过本次触发, 默认
+	OverlapSkip Overlap = iota
+	// OverlapQueue 等上一次结束后立即再执行一次, 多次触发只合并为一次
+	OverlapQueue
+)
+
+// JobOption 任务的可选参数
+type JobOption func(*jobOptions)
+
+type jobOptions struct {
+	overlap     Overlap
+	attempts    int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	jitter      time.Duration
+	timeout     time.Duration
+	maxFailures int
+	leader      Leader
+}
+
+func defaultJobOptions() jobOptions {
+	return jobOptions{
+		overlap:     OverlapSkip,
+		attempts:    1,
+		maxFailures: 1,
+	}
+}
+
+// WithOverlap 设置重叠执行的处理方式
+func WithOverlap(o Overlap) JobOption {
+	return func(opts *jobOptions) {
+		opts.overlap = o
+	}
+}
+
+// WithRetry 失败后重试, attempts为总尝试次数, 重试间隔从backoff开始每次翻倍, 最大不超过maxBackoff(<=0时不限制)
+func WithRetry(attempts int, backoff, maxBackoff time.Duration) JobOption {
+	return func(opts *jobOptions) {
+		if attempts > 0 {
+			opts.attempts = attempts
+		}
+		opts.backoff = backoff
+		opts.maxBackoff = maxBackoff
+	}
+}
+
+// WithJitter 每次触发前随机延迟[0, d), 避免多个实例同时执行
+func WithJitter(d time.Duration) JobOption {
+	return func(opts *jobOptions) {
+		opts.jitter = d
+	}
+}
+
+// WithTimeout 单次尝试的超时时间
+func WithTimeout(d time.Duration) JobOption {
+	return func(opts *jobOptions) {
+		opts.timeout = d
+	}
+}
+
+// WithMaxFailures 连续失败多少次后健康检查返回错误, 默认1
+func WithMaxFailures(n int) JobOption {
+	return func(opts *jobOptions) {
+		if n > 0 {
+			opts.maxFailures = n
+		}
+	}
+}
+
+// Leader 主节点判断, leaderelect.Elector实现了该接口
+type Leader interface {
+	IsLeader() bool
+}
+
+// WithLeader 只在主节点上执行, 非主节点的触发被忽略并计入Status.NotLeader, 用于多实例部署的单例任务
+func WithLeader(l Leader) JobOption {
+	return func(opts *jobOptions) {
+		opts.leader = l
+	}
+}
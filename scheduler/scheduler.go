@@ -0,0 +1,397 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ChangSZ/golib/health"
+	"github.com/ChangSZ/golib/log"
+)
+
+var _ health.Checker = (*Scheduler)(nil)
+
+// ErrJobExists 任务名称重复
+var ErrJobExists = errors.New("scheduler: job already exists")
+
+// ErrJobNotFound 任务不存在
+var ErrJobNotFound = errors.New("scheduler: job not found")
+
+// Job 定时执行的任务, ctx在调度器停止或单次超时时取消
+type Job func(ctx context.Context) error
+
+// Schedule 计算下一次执行时间
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// ScheduleFunc 函数形式的Schedule
+type ScheduleFunc func(t time.Time) time.Time
+
+func (f ScheduleFunc) Next(t time.Time) time.Time {
+	return f(t)
+}
+
+// Every 固定间隔执行
+func Every(d time.Duration) Schedule {
+	return ScheduleFunc(func(t time.Time) time.Time { return t.Add(d) })
+}
+
+// Status 任务的运行状态
+type Status struct {
+	Name                string
+	Running             bool
+	Runs                int // 执行次数, 一次执行包含所有重试
+	Failures            int // 失败的执行次数
+	ConsecutiveFailures int
+	Skipped             int // 因重叠被跳过的触发次数
+	NotLeader           int // 因不是主节点被忽略的触发次数
+	LastStart           time.Time
+	LastEnd             time.Time
+	LastError           string
+	NextRun             time.Time
+}
+
+type job struct {
+	name     string
+	schedule Schedule
+	fn       Job
+	opts     jobOptions
+
+	mu      sync.Mutex
+	status  Status
+	pending bool
+	stop    chan struct{}
+}
+
+// Scheduler 定时任务调度器, 每个任务可以配置重叠策略、失败重试和启动抖动
+//
+//	s := scheduler.New()
+//	s.Add("sync", scheduler.Every(time.Minute), syncJob,
+//		scheduler.WithRetry(3, time.Second, 0), scheduler.WithJitter(5*time.Second))
+//	health.Register("scheduler", s)
+//	s.Start(ctx)
+//	defer s.Stop()
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    map[string]*job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	rnd     *rand.Rand
+	rndLock sync.Mutex
+}
+
+// New new a Scheduler.
+func New() *Scheduler {
+	return &Scheduler{
+		jobs: make(map[string]*job),
+		rnd:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Add 添加任务, 调度器已启动时立即开始调度
+func (s *Scheduler) Add(name string, schedule Schedule, fn Job, opts ...JobOption) error {
+	o := defaultJobOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	j := &job{
+		name:     name,
+		schedule: schedule,
+		fn:       fn,
+		opts:     o,
+		status:   Status{Name: name},
+		stop:     make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("%w: %s", ErrJobExists, name)
+	}
+	s.jobs[name] = j
+	if s.ctx != nil {
+		s.loop(s.ctx, j)
+	}
+	return nil
+}
+
+// Remove 移除任务, 正在执行的不会被中断
+func (s *Scheduler) Remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j, ok := s.jobs[name]; ok {
+		close(j.stop)
+		delete(s.jobs, name)
+	}
+}
+
+// Start 开始调度, ctx结束或调用Stop时停止
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx != nil {
+		return
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	for _, j := range s.jobs {
+		s.loop(s.ctx, j)
+	}
+}
+
+// Stop 停止调度, 取消正在执行的任务的ctx并等待其退出
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.ctx, s.cancel = nil, nil
+	for _, j := range s.jobs {
+		close(j.stop)
+		j.stop = make(chan struct{})
+	}
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+// RunNow 立即触发一次, 遵循任务的重叠策略
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	ctx := s.ctx
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	s.trigger(ctx, j)
+	return nil
+}
+
+// loop 任务的调度协程, 调用方需持有s.mu
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	stop := j.stop
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			next := j.schedule.Next(time.Now())
+			if next.IsZero() {
+				return
+			}
+			next = next.Add(s.jitter(j.opts.jitter))
+			j.mu.Lock()
+			j.status.NextRun = next
+			j.mu.Unlock()
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+				s.trigger(ctx, j)
+			}
+		}
+	}()
+}
+
+func (s *Scheduler) jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	s.rndLock.Lock()
+	defer s.rndLock.Unlock()
+	return time.Duration(s.rnd.Int63n(int64(d)))
+}
+
+// trigger 按重叠策略执行一次
+func (s *Scheduler) trigger(ctx context.Context, j *job) {
+	j.mu.Lock()
+	if j.opts.leader != nil && !j.opts.leader.IsLeader() {
+		j.status.NotLeader++
+		j.mu.Unlock()
+		return
+	}
+	if j.status.Running {
+		if j.opts.overlap == OverlapQueue {
+			j.pending = true
+		} else {
+			j.status.Skipped++
+			log.Warnw("msg", "scheduler: job still running, skipped", "job", j.name)
+		}
+		j.mu.Unlock()
+		return
+	}
+	j.status.Running = true
+	j.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			s.run(ctx, j)
+
+			j.mu.Lock()
+			if !j.pending || ctx.Err() != nil {
+				j.pending = false
+				j.status.Running = false
+				j.mu.Unlock()
+				return
+			}
+			j.pending = false
+			j.mu.Unlock()
+		}
+	}()
+}
+
+// run 执行一次, 包含重试
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	j.mu.Lock()
+	j.status.LastStart = time.Now()
+	j.mu.Unlock()
+
+	var err error
+	backoff := j.opts.backoff
+	for attempt := 1; attempt <= j.opts.attempts; attempt++ {
+		if err = s.attempt(ctx, j); err == nil || ctx.Err() != nil {
+			break
+		}
+		if attempt == j.opts.attempts {
+			break
+		}
+		log.Warnw("msg", "scheduler: job failed, retrying", "job", j.name, "attempt", attempt, "err", err)
+		if backoff > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+			case <-timer.C:
+			}
+			if ctx.Err() != nil {
+				break
+			}
+			backoff *= 2
+			if j.opts.maxBackoff > 0 && backoff > j.opts.maxBackoff {
+				backoff = j.opts.maxBackoff
+			}
+		}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status.Runs++
+	j.status.LastEnd = time.Now()
+	if err != nil {
+		j.status.Failures++
+		j.status.ConsecutiveFailures++
+		j.status.LastError = err.Error()
+		log.Errorf("scheduler: job %s failed: %v", j.name, err)
+		return
+	}
+	j.status.ConsecutiveFailures = 0
+	j.status.LastError = ""
+}
+
+func (s *Scheduler) attempt(ctx context.Context, j *job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("scheduler: job %s panic: %v", j.name, r)
+		}
+	}()
+	if j.opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.opts.timeout)
+		defer cancel()
+	}
+	return j.fn(ctx)
+}
+
+// Status 任务的当前状态
+func (s *Scheduler) Status(name string) (Status, bool) {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return Status{}, false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status, true
+}
+
+// Statuses 所有任务的当前状态, 按名称排序
+func (s *Scheduler) Statuses() []Status {
+	s.mu.Lock()
+	jobs := make([]*job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+
+	out := make([]Status, 0, len(jobs))
+	for _, j := range jobs {
+		j.mu.Lock()
+		out = append(out, j.status)
+		j.mu.Unlock()
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Check 实现health.Checker, 存在连续失败次数达到WithMaxFailures的任务时返回错误
+func (s *Scheduler) Check(ctx context.Context) error {
+	s.mu.Lock()
+	jobs := make([]*job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].name < jobs[b].name })
+
+	var failing []string
+	for _, j := range jobs {
+		if err := j.check(); err != nil {
+			failing = append(failing, err.Error())
+		}
+	}
+	if len(failing) > 0 {
+		return fmt.Errorf("scheduler: failing jobs: %s", strings.Join(failing, "; "))
+	}
+	return nil
+}
+
+// JobChecker 单个任务的health.Checker, 用于分别注册到健康检查
+func (s *Scheduler) JobChecker(name string) health.Checker {
+	return health.CheckerFunc(func(ctx context.Context) error {
+		s.mu.Lock()
+		j, ok := s.jobs[name]
+		s.mu.Unlock()
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrJobNotFound, name)
+		}
+		return j.check()
+	})
+}
+
+func (j *job) check() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status.ConsecutiveFailures >= j.opts.maxFailures {
+		return fmt.Errorf("%s failed %d times: %s", j.name, j.status.ConsecutiveFailures, j.status.LastError)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	tests := []struct {
+		name        string
+		overlap     Overlap
+		wantRuns    int
+		wantSkipped int
+	}{
+		{name: "skip", overlap: OverlapSkip, wantRuns: 1, wantSkipped: 2},
+		{name: "queue", overlap: OverlapQueue, wantRuns: 2, wantSkipped: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			release := make(chan struct{})
+			var calls int32
+			s.Add("job", Every(time.Hour), func(ctx context.Context) error {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					<-release
+				}
+				return nil
+			}, WithOverlap(tt.overlap))
+
+			s.RunNow("job")
+			waitFor(t, func() bool { st, _ := s.Status("job"); return st.Running })
+			s.RunNow("job")
+			s.RunNow("job")
+			close(release)
+			waitFor(t, func() bool { st, _ := s.Status("job"); return !st.Running })
+
+			st, _ := s.Status("job")
+			if st.Runs != tt.wantRuns || st.Skipped != tt.wantSkipped {
+				t.Errorf("Status() runs = %d skipped = %d, want %d %d", st.Runs, st.Skipped, tt.wantRuns, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestRetryAndHealth(t *testing.T) {
+	s := New()
+	var calls int32
+	fail := errors.New("boom")
+	s.Add("flaky", Every(time.Hour), func(ctx context.Context) error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return fail
+		}
+		return nil
+	}, WithRetry(3, time.Millisecond, 0))
+	s.Add("broken", Every(time.Hour), func(ctx context.Context) error { return fail }, WithMaxFailures(2))
+
+	s.RunNow("flaky")
+	s.RunNow("broken")
+	waitFor(t, func() bool {
+		a, _ := s.Status("flaky")
+		b, _ := s.Status("broken")
+		return a.Runs == 1 && b.Runs == 1
+	})
+	if st, _ := s.Status("flaky"); calls != 3 || st.Failures != 0 {
+		t.Errorf("flaky calls = %d failures = %d, want 3 0", calls, st.Failures)
+	}
+	if err := s.Check(context.Background()); err != nil {
+		t.Errorf("Check() after one failure = %v, want nil", err)
+	}
+
+	s.RunNow("broken")
+	waitFor(t, func() bool { st, _ := s.Status("broken"); return st.Runs == 2 })
+	if err := s.Check(context.Background()); err == nil {
+		t.Errorf("Check() after two failures = nil, want error")
+	}
+	if err := s.JobChecker("flaky").Check(context.Background()); err != nil {
+		t.Errorf("JobChecker(flaky) = %v", err)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	s := New()
+	var calls int32
+	s.Add("tick", Every(10*time.Millisecond), func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}, WithJitter(5*time.Millisecond))
+	if err := s.Add("tick", Every(time.Second), nil); !errors.Is(err, ErrJobExists) {
+		t.Errorf("Add() duplicate error = %v", err)
+	}
+
+	s.Start(context.Background())
+	waitFor(t, func() bool { return atomic.LoadInt32(&calls) >= 3 })
+	s.Stop()
+
+	n := atomic.LoadInt32(&calls)
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt32(&calls) != n {
+		t.Errorf("job still running after Stop")
+	}
+	if err := s.RunNow("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("RunNow() error = %v", err)
+	}
+}
+
+func TestCron(t *testing.T) {
+	// 2024-01-31是周三
+	from := time.Date(2024, 1, 31, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{expr: "* * * * *", want: time.Date(2024, 1, 31, 10, 8, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", want: time.Date(2024, 1, 31, 10, 15, 0, 0, time.UTC)},
+		{expr: "0 9-18 * * *", want: time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC)},
+		{expr: "30 8 * * mon-fri", want: time.Date(2024, 2, 1, 8, 30, 0, 0, time.UTC)},
+		{expr: "0 0 * * 7", want: time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 feb *", want: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 15 * fri", want: time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)}, // 日和周满足任意一个即可
+		{expr: "@monthly", want: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 30 2 *", want: time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Cron(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "5-1 * * * *", "*/0 * * * *", "* * * foo *"} {
+		if _, err := Cron(expr); err == nil {
+			t.Errorf("Cron(%q) should fail", expr)
+		}
+	}
+}
+
+func TestPanicAndTimeout(t *testing.T) {
+	s := New()
+	s.Add("panic", Every(time.Hour), func(ctx context.Context) error { panic("oops") })
+	s.Add("slow", Every(time.Hour), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithTimeout(10*time.Millisecond))
+
+	s.RunNow("panic")
+	s.RunNow("slow")
+	waitFor(t, func() bool {
+		a, _ := s.Status("panic")
+		b, _ := s.Status("slow")
+		return a.Runs == 1 && b.Runs == 1
+	})
+	for _, st := range s.Statuses() {
+		if st.LastError == "" {
+			t.Errorf("%s LastError empty", st.Name)
+		}
+	}
+}
+
+type fakeLeader struct{ leader atomic.Bool }
+
+func (f *fakeLeader) IsLeader() bool { return f.leader.Load() }
+
+func TestWithLeader(t *testing.T) {
+	s := New()
+	l := &fakeLeader{}
+	var calls int32
+	s.Add("singleton", Every(time.Hour), func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}, WithLeader(l))
+
+	s.RunNow("singleton")
+	if st, _ := s.Status("singleton"); st.NotLeader != 1 || st.Running {
+		t.Errorf("Status() = %+v, want NotLeader 1", st)
+	}
+	l.leader.Store(true)
+	s.RunNow("singleton")
+	waitFor(t, func() bool { return atomic.LoadInt32(&calls) == 1 })
+}
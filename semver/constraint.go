@@ -0,0 +1,225 @@
+package semver
+
+import (
+	"fmt"
+	"strings"
+)
+
+type operator int
+
+const (
+	opEQ operator = iota
+	opNE
+	opGT
+	opGE
+	opLT
+	opLE
+)
+
+type comparator struct {
+	op operator
+	v  *Version
+}
+
+func (c comparator) match(v *Version) bool {
+	n := v.Compare(c.v)
+	switch c.op {
+	case opEQ:
+		return n == 0
+	case opNE:
+		return n != 0
+	case opGT:
+		return n > 0
+	case opGE:
+		return n >= 0
+	case opLT:
+		return n < 0
+	case opLE:
+		return n <= 0
+	}
+	return false
+}
+
+// Constraint 版本约束, 支持以下写法, 逗号或空格表示且, "||"表示或:
+//
+//	^1.2.3  >=1.2.3, <2.0.0 (主版本为0时锁定次版本, 如^0.2.3即>=0.2.3, <0.3.0)
+//	~1.2.3  >=1.2.3, <1.3.0
+//	1.2.x   >=1.2.0, <1.3.0, 同"1.2"
+//	>=2, <3 以及 >、<、<=、=、!=
+//	*       任意版本
+//
+// 与npm一致, 预发布版本只有在同一组约束中出现相同主次修订号的预发布版本时才会匹配
+type Constraint struct {
+	raw    string
+	groups [][]comparator
+}
+
+// NewConstraint 解析版本约束
+func NewConstraint(s string) (*Constraint, error) {
+	c := &Constraint{raw: s}
+	for _, alt := range strings.Split(s, "||") {
+		var group []comparator
+		for _, term := range splitTerms(alt) {
+			cs, err := parseTerm(term)
+			if err != nil {
+				return nil, fmt.Errorf("semver: invalid constraint %q: %w", s, err)
+			}
+			group = append(group, cs...)
+		}
+		if len(group) == 0 {
+			// 空约束等同于"*"
+			group = []comparator{{op: opGE, v: &Version{}}}
+		}
+		c.groups = append(c.groups, group)
+	}
+	return c, nil
+}
+
+// MustConstraint 同NewConstraint, 出错时panic
+func MustConstraint(s string) *Constraint {
+	c, err := NewConstraint(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// String 返回原始约束
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+// Check v是否满足约束
+func (c *Constraint) Check(v *Version) bool {
+	for _, group := range c.groups {
+		if matchGroup(group, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckString 解析并检查版本号, 无法解析时返回false
+func (c *Constraint) CheckString(s string) bool {
+	v, err := Parse(s)
+	return err == nil && c.Check(v)
+}
+
+// Latest 返回vs中满足约束的最高版本, 不存在时返回nil
+func (c *Constraint) Latest(vs []*Version) *Version {
+	var best *Version
+	for _, v := range vs {
+		if c.Check(v) && (best == nil || v.GreaterThan(best)) {
+			best = v
+		}
+	}
+	return best
+}
+
+func matchGroup(group []comparator, v *Version) bool {
+	for _, c := range group {
+		if !c.match(v) {
+			return false
+		}
+	}
+	if !v.IsPrerelease() {
+		return true
+	}
+	for _, c := range group {
+		if c.v.IsPrerelease() && c.v.Major == v.Major && c.v.Minor == v.Minor && c.v.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTerms 按逗号和空格切分, 并把">= 1.2"这种运算符与版本号之间有空格的写法合并
+func splitTerms(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	var terms []string
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if strings.Trim(f, "<>=!^~") == "" && i+1 < len(fields) {
+			f += fields[i+1]
+			i++
+		}
+		terms = append(terms, f)
+	}
+	return terms
+}
+
+func parseTerm(term string) ([]comparator, error) {
+	op := ""
+	for _, p := range []string{">=", "<=", "!=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(term, p) {
+			op, term = p, term[len(p):]
+			break
+		}
+	}
+	if term == "*" || term == "x" || term == "X" {
+		if op == "" || op == ">=" || op == "=" {
+			return []comparator{{op: opGE, v: &Version{}}}, nil
+		}
+		return nil, fmt.Errorf("operator %s with wildcard", op)
+	}
+	v, parts, err := parse(term)
+	if err != nil {
+		return nil, err
+	}
+	if parts == 0 {
+		return []comparator{{op: opGE, v: &Version{}}}, nil
+	}
+
+	// next 部分版本号的下一个区间起点, 如1.2的下一个是1.3.0
+	next := func() *Version {
+		if parts == 1 {
+			return &Version{Major: v.Major + 1}
+		}
+		return &Version{Major: v.Major, Minor: v.Minor + 1}
+	}
+
+	switch op {
+	case "^":
+		var upper *Version
+		switch {
+		case v.Major > 0 || parts == 1:
+			upper = &Version{Major: v.Major + 1}
+		case v.Minor > 0 || parts == 2:
+			upper = &Version{Minor: v.Minor + 1}
+		default:
+			upper = &Version{Patch: v.Patch + 1}
+		}
+		return []comparator{{op: opGE, v: v}, {op: opLT, v: upper}}, nil
+	case "~":
+		upper := &Version{Major: v.Major, Minor: v.Minor + 1}
+		if parts == 1 {
+			upper = &Version{Major: v.Major + 1}
+		}
+		return []comparator{{op: opGE, v: v}, {op: opLT, v: upper}}, nil
+	case "", "=":
+		if parts == 3 {
+			return []comparator{{op: opEQ, v: v}}, nil
+		}
+		return []comparator{{op: opGE, v: v}, {op: opLT, v: next()}}, nil
+	case "!=":
+		if parts != 3 {
+			return nil, fmt.Errorf("!= requires a full version")
+		}
+		return []comparator{{op: opNE, v: v}}, nil
+	case ">=":
+		return []comparator{{op: opGE, v: v}}, nil
+	case "<":
+		return []comparator{{op: opLT, v: v}}, nil
+	case ">":
+		if parts == 3 {
+			return []comparator{{op: opGT, v: v}}, nil
+		}
+		return []comparator{{op: opGE, v: next()}}, nil
+	case "<=":
+		if parts == 3 {
+			return []comparator{{op: opLE, v: v}}, nil
+		}
+		return []comparator{{op: opLT, v: next()}}, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
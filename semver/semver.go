@@ -0,0 +1,237 @@
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalid 版本号格式错误
+var ErrInvalid = errors.New("semver: invalid version")
+
+// Version 语义化版本, 参见https://semver.org
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string // 预发布标识, 如1.0.0-rc.1中的[rc 1]
+	Build      string   // 构建元数据, 不参与比较
+}
+
+// Parse 解析版本号, 允许"v"前缀
+func Parse(s string) (*Version, error) {
+	v, parts, err := parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if parts != 3 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+	return v, nil
+}
+
+// MustParse 同Parse, 出错时panic
+func MustParse(s string) *Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// parse 解析完整或部分版本号(如"1"、"1.2"), 返回实际给出的段数; "x"、"X"、"*"视为未给出
+func parse(s string) (*Version, int, error) {
+	raw := s
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if s == "" {
+		return nil, 0, fmt.Errorf("%w: %q", ErrInvalid, raw)
+	}
+
+	v := &Version{}
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		v.Build = s[i+1:]
+		s = s[:i]
+		if v.Build == "" {
+			return nil, 0, fmt.Errorf("%w: %q", ErrInvalid, raw)
+		}
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		pre := s[i+1:]
+		s = s[:i]
+		if pre == "" {
+			return nil, 0, fmt.Errorf("%w: %q", ErrInvalid, raw)
+		}
+		v.Prerelease = strings.Split(pre, ".")
+		for _, id := range v.Prerelease {
+			if id == "" || (isNumeric(id) && len(id) > 1 && id[0] == '0') {
+				return nil, 0, fmt.Errorf("%w: %q", ErrInvalid, raw)
+			}
+		}
+	}
+
+	fields := strings.Split(s, ".")
+	if len(fields) > 3 {
+		return nil, 0, fmt.Errorf("%w: %q", ErrInvalid, raw)
+	}
+	nums := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	parts := 0
+	wildcard := false
+	for i, f := range fields {
+		if f == "x" || f == "X" || f == "*" {
+			wildcard = true
+			continue
+		}
+		if wildcard || f == "" || !isNumeric(f) || (len(f) > 1 && f[0] == '0') {
+			return nil, 0, fmt.Errorf("%w: %q", ErrInvalid, raw)
+		}
+		n, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: %q", ErrInvalid, raw)
+		}
+		*nums[i] = n
+		parts++
+	}
+	return v, parts, nil
+}
+
+func isNumeric(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// String 返回规范格式, 不含"v"前缀
+func (v *Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare 比较v与o, v<o返回-1, v==o返回0, v>o返回1; 构建元数据不参与比较
+func (v *Version) Compare(o *Version) int {
+	if c := cmpUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := cmpUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := cmpUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+// LessThan v<o
+func (v *Version) LessThan(o *Version) bool { return v.Compare(o) < 0 }
+
+// GreaterThan v>o
+func (v *Version) GreaterThan(o *Version) bool { return v.Compare(o) > 0 }
+
+// Equal v==o
+func (v *Version) Equal(o *Version) bool { return v.Compare(o) == 0 }
+
+// IsPrerelease 是否为预发布版本
+func (v *Version) IsPrerelease() bool { return len(v.Prerelease) > 0 }
+
+func cmpUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// comparePrerelease 没有预发布标识的版本更大; 数字标识按数值比较且小于字母标识
+func comparePrerelease(a, b []string) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, y := a[i], b[i]
+		if x == y {
+			continue
+		}
+		xNum, yNum := isNumeric(x), isNumeric(y)
+		switch {
+		case xNum && yNum:
+			if len(x) != len(y) {
+				return cmpUint(uint64(len(x)), uint64(len(y)))
+			}
+			if x < y {
+				return -1
+			}
+			return 1
+		case xNum:
+			return -1
+		case yNum:
+			return 1
+		case x < y:
+			return -1
+		default:
+			return 1
+		}
+	}
+	return cmpUint(uint64(len(a)), uint64(len(b)))
+}
+
+// Compare 解析并比较两个版本号字符串
+func Compare(a, b string) (int, error) {
+	va, err := Parse(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := Parse(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
+
+// Sort 按版本从低到高排序
+func Sort(vs []*Version) {
+	sort.SliceStable(vs, func(i, j int) bool { return vs[i].LessThan(vs[j]) })
+}
+
+// SortStrings 按版本从低到高排序字符串形式的版本号, 无法解析的排在最后并保持原有顺序
+func SortStrings(vs []string) {
+	parsed := make([]*Version, len(vs))
+	for i, s := range vs {
+		parsed[i], _ = Parse(s)
+	}
+	idx := make([]int, len(vs))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		a, b := parsed[idx[i]], parsed[idx[j]]
+		switch {
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		}
+		return a.LessThan(b)
+	})
+	out := make([]string, len(vs))
+	for i, j := range idx {
+		out[i] = vs[j]
+	}
+	copy(vs, out)
+}
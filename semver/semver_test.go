@@ -0,0 +1,95 @@
+package semver
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "1.2.3", want: "1.2.3"},
+		{in: "v1.2.3-rc.1+build.5", want: "1.2.3-rc.1+build.5"},
+		{in: "1.2", wantErr: true},
+		{in: "01.2.3", wantErr: true},
+		{in: "1.2.3-", wantErr: true},
+		{in: "1.2.3-01", wantErr: true},
+		{in: "1.2.a", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			v, err := Parse(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Errorf("Parse() error = %v, want ErrInvalid", err)
+				}
+				return
+			}
+			if err != nil || v.String() != tt.want {
+				t.Errorf("Parse() = %v, %v, want %s", v, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortStrings(t *testing.T) {
+	vs := []string{"1.0.0", "bad", "1.0.0-rc.1", "1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-beta.11", "1.0.0-beta.2", "0.9.10", "1.0.0-alpha.beta"}
+	SortStrings(vs)
+	want := []string{"0.9.10", "1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "bad"}
+	if !reflect.DeepEqual(vs, want) {
+		t.Errorf("SortStrings() = %v, want %v", vs, want)
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		match      []string
+		noMatch    []string
+	}{
+		{constraint: "^1.2.0", match: []string{"1.2.0", "1.9.9"}, noMatch: []string{"1.1.9", "2.0.0", "1.3.0-rc.1"}},
+		{constraint: "^0.2.3", match: []string{"0.2.3", "0.2.9"}, noMatch: []string{"0.3.0"}},
+		{constraint: "^0.0.3", match: []string{"0.0.3"}, noMatch: []string{"0.0.4"}},
+		{constraint: "~1.2.3", match: []string{"1.2.3", "1.2.10"}, noMatch: []string{"1.3.0"}},
+		{constraint: ">=2, <3", match: []string{"2.0.0", "2.99.0"}, noMatch: []string{"1.9.9", "3.0.0"}},
+		{constraint: ">= 2 < 3", match: []string{"2.5.0"}, noMatch: []string{"3.0.0"}},
+		{constraint: "1.2.x", match: []string{"1.2.0", "1.2.7"}, noMatch: []string{"1.3.0"}},
+		{constraint: ">1.2", match: []string{"1.3.0"}, noMatch: []string{"1.2.9"}},
+		{constraint: "<=1.2", match: []string{"1.2.9"}, noMatch: []string{"1.3.0"}},
+		{constraint: "^1.0.0 || ^3.0.0", match: []string{"1.5.0", "3.1.0"}, noMatch: []string{"2.0.0"}},
+		{constraint: ">=1.0.0-rc.1", match: []string{"1.0.0-rc.2", "1.0.0", "2.0.0"}, noMatch: []string{"1.1.0-rc.1"}},
+		{constraint: "!=1.0.0", match: []string{"1.0.1"}, noMatch: []string{"1.0.0"}},
+		{constraint: "*", match: []string{"0.0.1", "9.9.9"}, noMatch: []string{"1.0.0-rc.1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			c, err := NewConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("NewConstraint() error = %v", err)
+			}
+			for _, v := range tt.match {
+				if !c.CheckString(v) {
+					t.Errorf("%s should match %s", tt.constraint, v)
+				}
+			}
+			for _, v := range tt.noMatch {
+				if c.CheckString(v) {
+					t.Errorf("%s should not match %s", tt.constraint, v)
+				}
+			}
+		})
+	}
+
+	if _, err := NewConstraint(">=1.x.2"); err == nil {
+		t.Errorf("NewConstraint() should reject malformed versions")
+	}
+	c := MustConstraint("~1.2")
+	latest := c.Latest([]*Version{MustParse("1.2.1"), MustParse("1.3.0"), MustParse("1.2.5")})
+	if latest == nil || latest.String() != "1.2.5" {
+		t.Errorf("Latest() = %v, want 1.2.5", latest)
+	}
+}
@@ -0,0 +1,93 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidCookie cookie格式错误或签名校验失败
+	ErrInvalidCookie = errors.New("session: invalid cookie")
+	// ErrCookieExpired cookie签发时间超过maxAge
+	ErrCookieExpired = errors.New("session: cookie expired")
+)
+
+const (
+	macSize       = sha256.Size
+	timestampSize = 8
+)
+
+// Codec cookie的安全编码, 先AES-CTR加密再HMAC-SHA256签名(encrypt-then-MAC)
+//
+// 签名覆盖cookie名称、签发时间和密文, 防止值被篡改或挪用到其他cookie
+type Codec struct {
+	hashKey []byte
+	block   cipher.Block
+	now     func() time.Time
+}
+
+// NewCodec new a Codec. hashKey用于签名, 建议32或64字节; blockKey用于加密, 必须为16、24或32字节
+func NewCodec(hashKey, blockKey []byte) (*Codec, error) {
+	if len(hashKey) == 0 {
+		return nil, errors.New("session: hash key is required")
+	}
+	block, err := aes.NewCipher(blockKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Codec{hashKey: hashKey, block: block, now: time.Now}, nil
+}
+
+// Encode 加密并签名value, 返回可直接作为cookie值的字符串
+func (c *Codec) Encode(name string, value []byte) (string, error) {
+	iv := make([]byte, c.block.BlockSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	// 布局: timestamp | iv | ciphertext | mac
+	buf := make([]byte, timestampSize+len(iv)+len(value), timestampSize+len(iv)+len(value)+macSize)
+	binary.BigEndian.PutUint64(buf, uint64(c.now().Unix()))
+	copy(buf[timestampSize:], iv)
+	cipher.NewCTR(c.block, iv).XORKeyStream(buf[timestampSize+len(iv):], value)
+	buf = append(buf, c.mac(name, buf)...)
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Decode 校验并解密cookie值, maxAge>0时拒绝签发时间早于maxAge的cookie
+func (c *Codec) Decode(name, cookie string, maxAge time.Duration) ([]byte, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(cookie)
+	if err != nil {
+		return nil, ErrInvalidCookie
+	}
+	ivSize := c.block.BlockSize()
+	if len(buf) < timestampSize+ivSize+macSize {
+		return nil, ErrInvalidCookie
+	}
+	body, sum := buf[:len(buf)-macSize], buf[len(buf)-macSize:]
+	if !hmac.Equal(sum, c.mac(name, body)) {
+		return nil, ErrInvalidCookie
+	}
+	issued := time.Unix(int64(binary.BigEndian.Uint64(body)), 0)
+	if maxAge > 0 && c.now().Sub(issued) > maxAge {
+		return nil, ErrCookieExpired
+	}
+	iv, ciphertext := body[timestampSize:timestampSize+ivSize], body[timestampSize+ivSize:]
+	value := make([]byte, len(ciphertext))
+	cipher.NewCTR(c.block, iv).XORKeyStream(value, ciphertext)
+	return value, nil
+}
+
+func (c *Codec) mac(name string, body []byte) []byte {
+	h := hmac.New(sha256.New, c.hashKey)
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write(body)
+	return h.Sum(nil)
+}
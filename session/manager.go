@@ -0,0 +1,167 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ChangSZ/golib/log"
+)
+
+type Config struct {
+	CookieName      string        `toml:"cookieName"`      // 默认golib_session
+	IdleTimeout     time.Duration `toml:"idleTimeout"`     // 无访问超过该时间后过期, 默认30m
+	AbsoluteTimeout time.Duration `toml:"absoluteTimeout"` // 自创建起超过该时间后过期, 默认24h
+	Path            string        `toml:"path"`            // 默认/
+	Domain          string        `toml:"domain"`
+	Secure          bool          `toml:"secure"`   // 只通过HTTPS发送, 生产环境应开启
+	SameSite        http.SameSite `toml:"sameSite"` // 默认Lax
+}
+
+// Manager 从请求中加载会话并写回响应, cookie中只保存加密的会话ID, 数据保存在Store中
+//
+//	s, err := m.Load(r)
+//	s.Set("uid", 1)
+//	err = m.Save(w, r, s) // 必须在写响应体之前调用
+type Manager struct {
+	store Store
+	codec *Codec
+	cfg   Config
+	now   func() time.Time
+}
+
+// New new a Manager.
+func New(store Store, codec *Codec, cfg Config) *Manager {
+	if cfg.CookieName == "" {
+		cfg.CookieName = "golib_session"
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = 30 * time.Minute
+	}
+	if cfg.AbsoluteTimeout <= 0 {
+		cfg.AbsoluteTimeout = 24 * time.Hour
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/"
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	return &Manager{store: store, codec: codec, cfg: cfg, now: time.Now}
+}
+
+// Load 加载请求对应的会话, cookie不存在、无效或会话已过期时返回新会话; 只有Store故障时返回错误
+func (m *Manager) Load(r *http.Request) (*Session, error) {
+	now := m.now()
+	c, err := r.Cookie(m.cfg.CookieName)
+	if err != nil {
+		return newSession(now), nil
+	}
+	id, err := m.codec.Decode(m.cfg.CookieName, c.Value, m.cfg.AbsoluteTimeout)
+	if err != nil {
+		return newSession(now), nil
+	}
+
+	ctx := r.Context()
+	data, err := m.store.Get(ctx, string(id))
+	if errors.Is(err, ErrNotFound) {
+		return newSession(now), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s, err := unmarshal(string(id), data)
+	if err != nil {
+		log.Warnw("msg", "session: corrupted data", "err", err)
+		return newSession(now), nil
+	}
+	if m.expired(s, now) {
+		if err := m.store.Del(ctx, s.id); err != nil {
+			log.Warnw("msg", "session: delete expired failed", "err", err)
+		}
+		return newSession(now), nil
+	}
+	return s, nil
+}
+
+func (m *Manager) expired(s *Session, now time.Time) bool {
+	return !now.Before(s.lastAccess.Add(m.cfg.IdleTimeout)) || !now.Before(s.createdAt.Add(m.cfg.AbsoluteTimeout))
+}
+
+// Save 保存会话并刷新空闲过期时间, 写入cookie; 已Destroy的会话会被删除并清除cookie
+func (m *Manager) Save(w http.ResponseWriter, r *http.Request, s *Session) error {
+	ctx := r.Context()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.oldID != "" {
+		if err := m.store.Del(ctx, s.oldID); err != nil {
+			return err
+		}
+		s.oldID = ""
+	}
+
+	now := m.now()
+	remaining := s.createdAt.Add(m.cfg.AbsoluteTimeout).Sub(now)
+	if s.destroyed || remaining <= 0 {
+		m.clearCookie(w)
+		return m.store.Del(ctx, s.id)
+	}
+
+	s.lastAccess = now
+	data, err := s.marshal()
+	if err != nil {
+		return err
+	}
+	if err := m.store.Set(ctx, s.id, data, min(m.cfg.IdleTimeout, remaining)); err != nil {
+		return err
+	}
+	value, err := m.codec.Encode(m.cfg.CookieName, []byte(s.id))
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, m.cookie(value, int(remaining/time.Second)))
+	s.isNew = false
+	return nil
+}
+
+// Renew 更换会话ID并保留数据, 登录等权限变化时调用以防止会话固定攻击, 旧ID在Save时删除
+func (m *Manager) Renew(s *Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.oldID == "" && !s.isNew {
+		s.oldID = s.id
+	}
+	s.id = newID()
+}
+
+// Destroy 标记会话为销毁, Save时删除数据并清除cookie
+func (m *Manager) Destroy(s *Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.destroyed = true
+	s.values = make(map[string]interface{})
+}
+
+// Delete 直接删除会话, 如管理员强制下线
+func (m *Manager) Delete(ctx context.Context, id string) error {
+	return m.store.Del(ctx, id)
+}
+
+func (m *Manager) clearCookie(w http.ResponseWriter) {
+	http.SetCookie(w, m.cookie("", -1))
+}
+
+func (m *Manager) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     m.cfg.CookieName,
+		Value:    value,
+		Path:     m.cfg.Path,
+		Domain:   m.cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   m.cfg.Secure,
+		HttpOnly: true,
+		SameSite: m.cfg.SameSite,
+	}
+}
@@ -0,0 +1,168 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ChangSZ/golib/copy"
+)
+
+// Session 一次会话, 可以并发使用
+//
+// 值在Set时会深拷贝, 之后修改原对象不会影响会话; 用Get读取为具体类型:
+//
+//	s.Set("user", User{ID: 1})
+//	u, ok, err := session.Get[User](s, "user")
+type Session struct {
+	mu         sync.Mutex
+	id         string
+	oldID      string // Renew前的ID, 保存时删除
+	createdAt  time.Time
+	lastAccess time.Time
+	values     map[string]interface{}
+	isNew      bool
+	destroyed  bool
+}
+
+// record 会话在Store中的格式
+type record struct {
+	CreatedAt  int64                      `json:"c"`
+	LastAccess int64                      `json:"l"`
+	Values     map[string]json.RawMessage `json:"v,omitempty"`
+}
+
+func newSession(now time.Time) *Session {
+	return &Session{
+		id:         newID(),
+		createdAt:  now,
+		lastAccess: now,
+		values:     make(map[string]interface{}),
+		isNew:      true,
+	}
+}
+
+// newID 32字节随机数, 不可预测
+func newID() string {
+	var b [32]byte
+	_, _ = rand.Read(b[:])
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+// ID 会话ID
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id
+}
+
+// IsNew 是否为本次请求新建的会话
+func (s *Session) IsNew() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isNew
+}
+
+// CreatedAt 会话的创建时间
+func (s *Session) CreatedAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.createdAt
+}
+
+// Set 设置值, v会被深拷贝
+func (s *Session) Set(key string, v interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = copy.DeepCopy(v)
+}
+
+// Delete 删除值
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
+
+// Clear 删除全部值
+func (s *Session) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = make(map[string]interface{})
+}
+
+// Keys 全部key, 按字典序
+func (s *Session) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get 读取key对应的值, 不存在时ok为false, 类型不匹配时返回错误
+//
+// 从Store加载的值在第一次读取时按T反序列化, 返回的是深拷贝, 修改后需要重新Set
+func Get[T any](s *Session, key string) (value T, ok bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	if !ok {
+		return value, false, nil
+	}
+	switch val := v.(type) {
+	case T:
+		return copy.DeepCopy(val).(T), true, nil
+	case json.RawMessage:
+		if err := json.Unmarshal(val, &value); err != nil {
+			return value, true, fmt.Errorf("session: decode %q: %w", key, err)
+		}
+		s.values[key] = value
+		return copy.DeepCopy(value).(T), true, nil
+	}
+	return value, true, fmt.Errorf("session: value of %q is %T, not %T", key, v, value)
+}
+
+func (s *Session) marshal() ([]byte, error) {
+	rec := record{
+		CreatedAt:  s.createdAt.Unix(),
+		LastAccess: s.lastAccess.Unix(),
+		Values:     make(map[string]json.RawMessage, len(s.values)),
+	}
+	for k, v := range s.values {
+		if raw, ok := v.(json.RawMessage); ok {
+			rec.Values[k] = raw
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("session: encode %q: %w", k, err)
+		}
+		rec.Values[k] = b
+	}
+	return json.Marshal(rec)
+}
+
+func unmarshal(id string, data []byte) (*Session, error) {
+	var rec record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	s := &Session{
+		id:         id,
+		createdAt:  time.Unix(rec.CreatedAt, 0),
+		lastAccess: time.Unix(rec.LastAccess, 0),
+		values:     make(map[string]interface{}, len(rec.Values)),
+	}
+	for k, v := range rec.Values {
+		s.values[k] = v
+	}
+	return s, nil
+}
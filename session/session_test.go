@@ -0,0 +1,209 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type user struct {
+	ID    int
+	Name  string
+	Roles []string
+}
+
+func newTestCodec(t *testing.T) *Codec {
+	t.Helper()
+	c, err := NewCodec([]byte(strings.Repeat("h", 32)), []byte(strings.Repeat("b", 32)))
+	if err != nil {
+		t.Fatalf("NewCodec() error = %v", err)
+	}
+	return c
+}
+
+func TestCodec(t *testing.T) {
+	c := newTestCodec(t)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	enc, err := c.Encode("sid", []byte("secret-id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(enc, "secret") {
+		t.Errorf("Encode() leaks plaintext: %s", enc)
+	}
+
+	tampered := []byte(enc)
+	tampered[10] ^= 1
+	tests := []struct {
+		name    string
+		cookie  string
+		cname   string
+		advance time.Duration
+		wantErr error
+	}{
+		{name: "ok", cookie: enc, cname: "sid"},
+		{name: "tampered", cookie: string(tampered), cname: "sid", wantErr: ErrInvalidCookie},
+		{name: "other cookie name", cookie: enc, cname: "other", wantErr: ErrInvalidCookie},
+		{name: "garbage", cookie: "!!", cname: "sid", wantErr: ErrInvalidCookie},
+		{name: "expired", cookie: enc, cname: "sid", advance: 2 * time.Hour, wantErr: ErrCookieExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.now = func() time.Time { return now.Add(tt.advance) }
+			got, err := c.Decode(tt.cname, tt.cookie, time.Hour)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && string(got) != "secret-id" {
+				t.Errorf("Decode() = %s", got)
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	s := newSession(time.Now())
+	u := user{ID: 1, Name: "jack", Roles: []string{"admin"}}
+	s.Set("user", u)
+	u.Roles[0] = "guest" // Set之后修改原值不影响会话
+
+	got, ok, err := Get[user](s, "user")
+	if err != nil || !ok || got.Roles[0] != "admin" {
+		t.Errorf("Get() = %+v, %v, %v", got, ok, err)
+	}
+	if _, ok, _ := Get[user](s, "missing"); ok {
+		t.Errorf("Get(missing) ok = true")
+	}
+	if _, _, err := Get[int](s, "user"); err == nil {
+		t.Errorf("Get[int]() want type error")
+	}
+
+	// 经过序列化后仍可按类型读取
+	data, err := s.marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := unmarshal(s.id, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err = Get[user](loaded, "user")
+	if err != nil || !ok || got.Name != "jack" || got.Roles[0] != "admin" {
+		t.Errorf("Get() after load = %+v, %v, %v", got, ok, err)
+	}
+}
+
+// roundTrip 模拟一次请求, 返回响应中的cookie
+func roundTrip(t *testing.T, m *Manager, cookie *http.Cookie, fn func(s *Session)) (*Session, *http.Cookie) {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	s, err := m.Load(r)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if fn != nil {
+		fn(s)
+	}
+	w := httptest.NewRecorder()
+	if err := m.Save(w, r, s); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Save() set %d cookies", len(cookies))
+	}
+	return s, cookies[0]
+}
+
+func TestManager(t *testing.T) {
+	store := NewMemoryStore()
+	m := New(store, newTestCodec(t), Config{IdleTimeout: time.Minute, AbsoluteTimeout: time.Hour})
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	store.now = m.now
+	m.codec.now = m.now
+
+	s, cookie := roundTrip(t, m, nil, func(s *Session) { s.Set("uid", 7) })
+	if !cookie.HttpOnly || cookie.Value == s.ID() {
+		t.Errorf("cookie = %+v", cookie)
+	}
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		wantNew bool
+	}{
+		{name: "within idle", advance: 30 * time.Second},
+		{name: "idle refreshed", advance: 50 * time.Second},
+		{name: "idle expired", advance: 2 * time.Minute, wantNew: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			var isNew bool
+			var uid int
+			_, cookie = roundTrip(t, m, cookie, func(s *Session) {
+				isNew = s.IsNew()
+				uid, _, _ = Get[int](s, "uid")
+			})
+			if isNew != tt.wantNew || (!tt.wantNew && uid != 7) {
+				t.Errorf("isNew = %v, uid = %d", isNew, uid)
+			}
+		})
+	}
+}
+
+func TestManagerAbsoluteTimeout(t *testing.T) {
+	store := NewMemoryStore()
+	m := New(store, newTestCodec(t), Config{IdleTimeout: time.Hour, AbsoluteTimeout: 90 * time.Minute})
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	store.now = m.now
+	m.codec.now = m.now
+
+	_, cookie := roundTrip(t, m, nil, nil)
+	for i := 0; i < 3; i++ {
+		now = now.Add(40 * time.Minute)
+		var isNew bool
+		_, c := roundTrip(t, m, cookie, func(s *Session) { isNew = s.IsNew() })
+		// 虽然一直在访问, 超过AbsoluteTimeout后仍会过期
+		if wantNew := i == 2; isNew != wantNew {
+			t.Errorf("round %d: IsNew() = %v, want %v", i, isNew, wantNew)
+		}
+		cookie = c
+	}
+}
+
+func TestManagerRenewAndDestroy(t *testing.T) {
+	store := NewMemoryStore()
+	m := New(store, newTestCodec(t), Config{})
+
+	s, cookie := roundTrip(t, m, nil, func(s *Session) { s.Set("step", "login") })
+	oldID := s.ID()
+	s, cookie = roundTrip(t, m, cookie, func(s *Session) { m.Renew(s) })
+	if s.ID() == oldID {
+		t.Errorf("Renew() kept the same id")
+	}
+	if _, err := store.Get(context.Background(), oldID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("old session still stored, err = %v", err)
+	}
+	if v, _, _ := Get[string](s, "step"); v != "login" {
+		t.Errorf("Renew() lost data, step = %q", v)
+	}
+
+	_, cookie = roundTrip(t, m, cookie, func(s *Session) { m.Destroy(s) })
+	if cookie.MaxAge >= 0 {
+		t.Errorf("Destroy() cookie MaxAge = %d, want < 0", cookie.MaxAge)
+	}
+	if _, err := store.Get(context.Background(), s.ID()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("destroyed session still stored, err = %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ChangSZ/golib/cache"
+)
+
+// ErrNotFound 会话不存在或已过期
+var ErrNotFound = errors.New("session: not found")
+
+// Store 会话数据的存储
+type Store interface {
+	// Get 会话不存在时返回ErrNotFound
+	Get(ctx context.Context, id string) ([]byte, error)
+	Set(ctx context.Context, id string, data []byte, ttl time.Duration) error
+	Del(ctx context.Context, id string) error
+}
+
+var (
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*CacheStore)(nil)
+)
+
+// MemoryStore 进程内的Store, 用于单实例部署和测试
+type MemoryStore struct {
+	mu    sync.Mutex
+	items map[string]memoryItem
+	now   func() time.Time
+}
+
+type memoryItem struct {
+	data     []byte
+	expireAt time.Time
+}
+
+// NewMemoryStore new a MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: make(map[string]memoryItem), now: time.Now}
+}
+
+func (m *MemoryStore) Get(ctx context.Context, id string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item, ok := m.items[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if !m.now().Before(item.expireAt) {
+		delete(m.items, id)
+		return nil, ErrNotFound
+	}
+	return item.data, nil
+}
+
+func (m *MemoryStore) Set(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items[id] = memoryItem{data: data, expireAt: m.now().Add(ttl)}
+	return nil
+}
+
+func (m *MemoryStore) Del(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, id)
+	return nil
+}
+
+// GC 清理已过期的会话, 可以定时调用
+func (m *MemoryStore) GC() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for id, item := range m.items {
+		if !m.now().Before(item.expireAt) {
+			delete(m.items, id)
+			n++
+		}
+	}
+	return n
+}
+
+// CacheStore 基于cache.Remote的Store, 多实例共享会话时使用
+type CacheStore struct {
+	remote cache.Remote
+	prefix string
+}
+
+// NewCacheStore new a CacheStore, prefix会加在会话ID之前, 如"session:"
+func NewCacheStore(remote cache.Remote, prefix string) *CacheStore {
+	return &CacheStore{remote: remote, prefix: prefix}
+}
+
+func (c *CacheStore) Get(ctx context.Context, id string) ([]byte, error) {
+	b, err := c.remote.Get(ctx, c.prefix+id)
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return b, err
+}
+
+func (c *CacheStore) Set(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	return c.remote.Set(ctx, c.prefix+id, data, ttl)
+}
+
+func (c *CacheStore) Del(ctx context.Context, id string) error {
+	return c.remote.Del(ctx, c.prefix+id)
+}
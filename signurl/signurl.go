@@ -0,0 +1,97 @@
+package signurl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrMalformed 签名参数缺失或格式错误
+	ErrMalformed = errors.New("signurl: malformed")
+	// ErrInvalidSignature 签名不匹配
+	ErrInvalidSignature = errors.New("signurl: invalid signature")
+	// ErrExpired 已过期
+	ErrExpired = errors.New("signurl: expired")
+	// ErrUnknownKey 签名使用的密钥已不存在
+	ErrUnknownKey = errors.New("signurl: unknown key")
+)
+
+// Key 签名密钥, ID会随签名一起传递, 用于轮换时找到对应的密钥
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Option Signer的可选参数
+type Option func(*Signer)
+
+// WithSkew 校验过期时间时容忍的时钟偏差, 默认30s
+func WithSkew(d time.Duration) Option {
+	return func(s *Signer) {
+		s.skew = d
+	}
+}
+
+// Signer 生成和校验带过期时间的HMAC签名, 可以并发使用
+//
+// 密钥轮换: 新密钥放在第一个用于签名, 旧密钥保留在后面直到它签出的链接全部过期
+//
+//	s := signurl.New([]signurl.Key{{ID: "2", Secret: newKey}, {ID: "1", Secret: oldKey}})
+type Signer struct {
+	keys []Key
+	skew time.Duration
+	now  func() time.Time
+}
+
+// New new a Signer, keys不能为空, 第一个用于签名
+func New(keys []Key, opts ...Option) *Signer {
+	if len(keys) == 0 {
+		panic("signurl: no keys")
+	}
+	s := &Signer{
+		keys: keys,
+		skew: 30 * time.Second,
+		now:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Signer) key(id string) (Key, bool) {
+	for _, k := range s.keys {
+		if k.ID == id {
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+func sign(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// verify 校验签名和过期时间
+func (s *Signer) verify(kid string, payload []byte, sig string, expires int64) error {
+	key, ok := s.key(kid)
+	if !ok {
+		return ErrUnknownKey
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return ErrMalformed
+	}
+	if !hmac.Equal(got, sign(key.Secret, payload)) {
+		return ErrInvalidSignature
+	}
+	if s.now().After(time.Unix(expires, 0).Add(s.skew)) {
+		return ErrExpired
+	}
+	return nil
+}
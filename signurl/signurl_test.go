@@ -0,0 +1,110 @@
+package signurl
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSigner(now *time.Time, keys ...Key) *Signer {
+	s := New(keys, WithSkew(5*time.Second))
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestURL(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	k1 := Key{ID: "1", Secret: []byte("old-secret")}
+	k2 := Key{ID: "2", Secret: []byte("new-secret")}
+	old := newSigner(&now, k1)
+	s := newSigner(&now, k2, k1)
+
+	signed, err := s.SignURL("https://cdn.local/files/a.pdf?name=a&b=1", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldSigned, _ := old.SignURL("https://cdn.local/files/a.pdf", time.Minute)
+	tampered := strings.Replace(signed, "name=a", "name=b", 1)
+	reordered := func() string {
+		u, _ := url.Parse(signed)
+		q := u.Query()
+		u.RawQuery = "sig=" + q.Get("sig") + "&b=1&name=a&kid=2&expires=" + q.Get("expires")
+		return "http://proxy.internal" + u.RequestURI()
+	}()
+
+	tests := []struct {
+		name    string
+		url     string
+		advance time.Duration
+		signer  *Signer
+		wantErr error
+	}{
+		{name: "ok", url: signed},
+		{name: "reordered behind proxy", url: reordered},
+		{name: "rotated key still valid", url: oldSigned},
+		{name: "within skew", url: signed, advance: time.Minute + 3*time.Second},
+		{name: "expired", url: signed, advance: time.Minute + 6*time.Second, wantErr: ErrExpired},
+		{name: "tampered", url: tampered, wantErr: ErrInvalidSignature},
+		{name: "unknown key", url: signed, signer: old, wantErr: ErrUnknownKey},
+		{name: "unsigned", url: "https://cdn.local/files/a.pdf", wantErr: ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := now
+			defer func() { now = base }()
+			now = now.Add(tt.advance)
+			signer := s
+			if tt.signer != nil {
+				signer = tt.signer
+			}
+			if err := signer.VerifyURL(tt.url); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyURL() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	r := httptest.NewRequest("GET", signed, nil)
+	if err := s.VerifyRequest(r); err != nil {
+		t.Errorf("VerifyRequest() error = %v", err)
+	}
+}
+
+func TestToken(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := newSigner(&now, Key{ID: "k", Secret: []byte("secret")})
+	token, err := s.Token("webhook:order", time.Hour, map[string]string{"order": "42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := s.ParseToken(token, "webhook:order")
+	if err != nil || c.Extra["order"] != "42" {
+		t.Fatalf("ParseToken() = %+v, %v", c, err)
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		resource string
+		wantErr  error
+	}{
+		{name: "other resource", token: token, resource: "webhook:user", wantErr: ErrInvalidSignature},
+		{name: "bad signature", token: token[:len(token)-2] + "xx", resource: "webhook:order", wantErr: ErrInvalidSignature},
+		{name: "malformed", token: "abc", resource: "webhook:order", wantErr: ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.ParseToken(tt.token, tt.resource); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, err := s.ParseToken(token, "webhook:order"); !errors.Is(err, ErrExpired) {
+		t.Errorf("ParseToken() error = %v, want ErrExpired", err)
+	}
+}
@@ -0,0 +1,58 @@
+package signurl
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Claims 令牌的内容
+type Claims struct {
+	Resource  string            `json:"r"`
+	ExpiresAt int64             `json:"e"`
+	Extra     map[string]string `json:"x,omitempty"`
+}
+
+// Token 生成访问resource的令牌, 格式为kid.payload.sig, 适合放在回调地址或请求头中
+func (s *Signer) Token(resource string, ttl time.Duration, extra map[string]string) (string, error) {
+	payload, err := json.Marshal(Claims{
+		Resource:  resource,
+		ExpiresAt: s.now().Add(ttl).Unix(),
+		Extra:     extra,
+	})
+	if err != nil {
+		return "", err
+	}
+	key := s.keys[0]
+	body := key.ID + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + base64.RawURLEncoding.EncodeToString(sign(key.Secret, []byte(body))), nil
+}
+
+// ParseToken 校验令牌并返回内容, resource不匹配时返回ErrInvalidSignature
+func (s *Signer) ParseToken(token, resource string) (Claims, error) {
+	var c Claims
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return c, ErrMalformed
+	}
+	body, sig := token[:i], token[i+1:]
+	kid, encoded, ok := strings.Cut(body, ".")
+	if !ok {
+		return c, ErrMalformed
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return c, ErrMalformed
+	}
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return c, ErrMalformed
+	}
+	if err := s.verify(kid, []byte(body), sig, c.ExpiresAt); err != nil {
+		return Claims{}, err
+	}
+	if c.Resource != resource {
+		return Claims{}, ErrInvalidSignature
+	}
+	return c, nil
+}
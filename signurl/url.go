@@ -0,0 +1,64 @@
+package signurl
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// URL中签名相关的查询参数
+const (
+	ParamExpires   = "expires"
+	ParamKeyID     = "kid"
+	ParamSignature = "sig"
+)
+
+// SignURL 为URL追加过期时间和签名, 签名覆盖path和全部查询参数, 不含scheme和host以便经过代理后仍可校验
+//
+//	link, _ := s.SignURL("https://cdn.example.com/files/a.pdf?name=a", 10*time.Minute)
+func (s *Signer) SignURL(rawURL string, ttl time.Duration) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Del(ParamSignature)
+	q.Set(ParamExpires, strconv.FormatInt(s.now().Add(ttl).Unix(), 10))
+	q.Set(ParamKeyID, s.keys[0].ID)
+	sig := sign(s.keys[0].Secret, canonical(u.EscapedPath(), q))
+	q.Set(ParamSignature, base64.RawURLEncoding.EncodeToString(sig))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// VerifyURL 校验SignURL生成的URL
+func (s *Signer) VerifyURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ErrMalformed
+	}
+	return s.verifyURL(u)
+}
+
+// VerifyRequest 校验请求的URL, 用于下载接口或回调接口
+func (s *Signer) VerifyRequest(r *http.Request) error {
+	return s.verifyURL(r.URL)
+}
+
+func (s *Signer) verifyURL(u *url.URL) error {
+	q := u.Query()
+	sig := q.Get(ParamSignature)
+	expires, err := strconv.ParseInt(q.Get(ParamExpires), 10, 64)
+	if sig == "" || err != nil {
+		return ErrMalformed
+	}
+	q.Del(ParamSignature)
+	return s.verify(q.Get(ParamKeyID), canonical(u.EscapedPath(), q), sig, expires)
+}
+
+// canonical 签名内容, url.Values.Encode按key排序, 参数顺序变化不影响签名
+func canonical(path string, q url.Values) []byte {
+	return []byte(path + "?" + q.Encode())
+}
@@ -0,0 +1,172 @@
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Transliterator 将单个字符转写为ASCII, 无法处理时返回false
+//
+// 中文转拼音等依赖字典的转写通过该接口接入, 例如:
+//
+//	slug.SetTransliterator(func(r rune) (string, bool) {
+//		py := pinyin.LazyConvert(string(r), nil)
+//		if len(py) == 0 {
+//			return "", false
+//		}
+//		return py[0], true
+//	})
+type Transliterator func(r rune) (string, bool)
+
+var (
+	mu                    sync.RWMutex
+	defaultTransliterator Transliterator
+)
+
+// SetTransliterator 设置全局的转写函数, 对所有未指定WithTransliterator的调用生效
+func SetTransliterator(fn Transliterator) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultTransliterator = fn
+}
+
+type options struct {
+	separator      string
+	maxLength      int
+	transliterator Transliterator
+}
+
+type Option func(*options)
+
+// WithSeparator 单词分隔符, 默认"-"
+func WithSeparator(sep string) Option {
+	return func(o *options) {
+		o.separator = sep
+	}
+}
+
+// WithMaxLength 最大长度, 超出时在单词边界截断, 0表示不限制
+func WithMaxLength(n int) Option {
+	return func(o *options) {
+		o.maxLength = n
+	}
+}
+
+// WithTransliterator 本次调用使用的转写函数
+func WithTransliterator(fn Transliterator) Option {
+	return func(o *options) {
+		o.transliterator = fn
+	}
+}
+
+// special 不能通过去除变音符号得到的拉丁字母
+var special = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "ae", 'ø': "o", 'Ø': "o", 'œ': "oe", 'Œ': "oe",
+	'đ': "d", 'Đ': "d", 'ł': "l", 'Ł': "l", 'þ': "th", 'Þ': "th", 'ð': "d", 'Ð': "d",
+}
+
+// Make 生成URL友好的slug: 只包含小写字母、数字和分隔符
+//
+// 带变音符号的拉丁字母会被还原(é => e), 汉字等其它文字通过Transliterator转写, 无法转写的字符被丢弃
+func Make(s string, opts ...Option) string {
+	o := &options{separator: "-"}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.transliterator == nil {
+		mu.RLock()
+		o.transliterator = defaultTransliterator
+		mu.RUnlock()
+	}
+
+	var words []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			words = append(words, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range norm.NFKD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// 分解后的变音符号
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			cur.WriteRune(unicode.ToLower(r))
+		case special[r] != "":
+			cur.WriteString(special[r])
+		case r == '&':
+			flush()
+			words = append(words, "and")
+		case r > unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			// 转写结果单独成词, 如"你好" => "ni-hao"
+			flush()
+			if o.transliterator == nil {
+				continue
+			}
+			if t, ok := o.transliterator(r); ok {
+				if w := Make(t, WithSeparator(o.separator), WithTransliterator(noTransliterate)); w != "" {
+					words = append(words, w)
+				}
+			}
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	out := strings.Join(words, o.separator)
+	if o.maxLength > 0 && len(out) > o.maxLength {
+		out = truncate(words, o.separator, o.maxLength)
+	}
+	return out
+}
+
+func noTransliterate(rune) (string, bool) { return "", false }
+
+// truncate 在单词边界截断, 第一个单词就超长时直接截断
+func truncate(words []string, sep string, max int) string {
+	var b strings.Builder
+	for _, w := range words {
+		n := len(w)
+		if b.Len() > 0 {
+			n += len(sep)
+		}
+		if b.Len()+n > max {
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(w)
+	}
+	if b.Len() == 0 && len(words) > 0 {
+		return words[0][:max]
+	}
+	return b.String()
+}
+
+// Unique 当slug已存在时追加数字后缀(post、post-2、post-3...), exists用于查询是否已被占用
+func Unique(slug string, exists func(string) bool, opts ...Option) string {
+	o := &options{separator: "-"}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !exists(slug) {
+		return slug
+	}
+	for i := 2; ; i++ {
+		suffix := o.separator + strconv.Itoa(i)
+		base := slug
+		if o.maxLength > 0 && len(base)+len(suffix) > o.maxLength {
+			base = strings.TrimRight(base[:o.maxLength-len(suffix)], o.separator)
+		}
+		if candidate := base + suffix; !exists(candidate) {
+			return candidate
+		}
+	}
+}
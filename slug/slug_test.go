@@ -0,0 +1,58 @@
+package slug
+
+import "testing"
+
+func TestMake(t *testing.T) {
+	pinyin := map[rune]string{'你': "nǐ", '好': "hǎo", '世': "shì", '界': "jiè"}
+	fakePinyin := func(r rune) (string, bool) {
+		s, ok := pinyin[r]
+		return s, ok
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		opts []Option
+		want string
+	}{
+		{name: "ascii", in: "Hello, World!", want: "hello-world"},
+		{name: "diacritics", in: "Crème Brûlée à la Straße", want: "creme-brulee-a-la-strasse"},
+		{name: "ampersand", in: "Tom & Jerry", want: "tom-and-jerry"},
+		{name: "collapse separators", in: "  --a__b--  ", want: "a-b"},
+		{name: "fullwidth", in: "ＧＯ１２３", want: "go123"},
+		{name: "han dropped without transliterator", in: "Go语言", want: "go"},
+		{name: "pinyin", in: "你好, 世界 2024", opts: []Option{WithTransliterator(fakePinyin)}, want: "ni-hao-shi-jie-2024"},
+		{name: "separator", in: "a b c", opts: []Option{WithSeparator("_")}, want: "a_b_c"},
+		{name: "max length word boundary", in: "the quick brown fox", opts: []Option{WithMaxLength(14)}, want: "the-quick"},
+		{name: "max length long word", in: "internationalization", opts: []Option{WithMaxLength(5)}, want: "inter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Make(tt.in, tt.opts...); got != tt.want {
+				t.Errorf("Make() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnique(t *testing.T) {
+	taken := map[string]bool{"post": true, "post-2": true, "abcde": true}
+	exists := func(s string) bool { return taken[s] }
+
+	tests := []struct {
+		in   string
+		opts []Option
+		want string
+	}{
+		{in: "fresh", want: "fresh"},
+		{in: "post", want: "post-3"},
+		{in: "abcde", opts: []Option{WithMaxLength(5)}, want: "abc-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Unique(tt.in, exists, tt.opts...); got != tt.want {
+				t.Errorf("Unique() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package sortedslice
+
+import (
+	"cmp"
+	"sort"
+)
+
+// 所有函数都假设输入已按升序(或cmp给出的顺序)排列, 不做校验
+
+// Search 返回第一个 >= v 的下标, found表示该位置的元素是否等于v
+func Search[T cmp.Ordered](s []T, v T) (idx int, found bool) {
+	return SearchBy(s, v, cmp.Compare[T])
+}
+
+// SearchBy 使用自定义比较函数的Search, cmp(a, b)<0表示a排在b之前
+func SearchBy[T, K any](s []T, target K, cmp func(T, K) int) (idx int, found bool) {
+	idx = sort.Search(len(s), func(i int) bool { return cmp(s[i], target) >= 0 })
+	return idx, idx < len(s) && cmp(s[idx], target) == 0
+}
+
+// InsertSorted 插入v并保持有序, 相等元素插入到已有元素之后
+func InsertSorted[T cmp.Ordered](s []T, v T) []T {
+	return InsertSortedFunc(s, v, cmp.Compare[T])
+}
+
+// InsertSortedFunc 使用自定义比较函数的InsertSorted
+func InsertSortedFunc[T any](s []T, v T, cmp func(a, b T) int) []T {
+	idx := sort.Search(len(s), func(i int) bool { return cmp(s[i], v) > 0 })
+	var zero T
+	s = append(s, zero)
+	copy(s[idx+1:], s[idx:])
+	s[idx] = v
+	return s
+}
+
+// RangeBetween 返回闭区间[lo, hi]内的元素, 结果与s共享底层数组
+func RangeBetween[T cmp.Ordered](s []T, lo, hi T) []T {
+	return RangeBetweenFunc(s, lo, hi, cmp.Compare[T])
+}
+
+// RangeBetweenFunc 使用自定义比较函数的RangeBetween, 如按时间戳查询时间序列
+func RangeBetweenFunc[T, K any](s []T, lo, hi K, cmp func(T, K) int) []T {
+	start := sort.Search(len(s), func(i int) bool { return cmp(s[i], lo) >= 0 })
+	end := sort.Search(len(s), func(i int) bool { return cmp(s[i], hi) > 0 })
+	if start >= end {
+		return nil
+	}
+	return s[start:end]
+}
+
+// Merge 合并两个有序切片, 返回新切片, 相等元素a在前(稳定)
+func Merge[T cmp.Ordered](a, b []T) []T {
+	return MergeFunc(a, b, cmp.Compare[T])
+}
+
+// MergeFunc 使用自定义比较函数的Merge
+func MergeFunc[T any](a, b []T, cmp func(a, b T) int) []T {
+	out := make([]T, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if cmp(b[j], a[i]) < 0 {
+			out = append(out, b[j])
+			j++
+		} else {
+			out = append(out, a[i])
+			i++
+		}
+	}
+	out = append(out, a[i:]...)
+	return append(out, b[j:]...)
+}
@@ -0,0 +1,114 @@
+package sortedslice
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	s := []int{1, 3, 3, 5, 9}
+	tests := []struct {
+		name      string
+		v         int
+		wantIdx   int
+		wantFound bool
+	}{
+		{name: "first", v: 1, wantIdx: 0, wantFound: true},
+		{name: "duplicate", v: 3, wantIdx: 1, wantFound: true},
+		{name: "missing", v: 4, wantIdx: 3, wantFound: false},
+		{name: "after end", v: 10, wantIdx: 5, wantFound: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx, found := Search(s, tt.v)
+			if idx != tt.wantIdx || found != tt.wantFound {
+				t.Errorf("Search() = %d, %v, want %d, %v", idx, found, tt.wantIdx, tt.wantFound)
+			}
+		})
+	}
+}
+
+type point struct {
+	ts    int64
+	value float64
+}
+
+func TestSearchByAndRange(t *testing.T) {
+	points := []point{{10, 1}, {20, 2}, {30, 3}, {40, 4}}
+	byTS := func(p point, ts int64) int {
+		switch {
+		case p.ts < ts:
+			return -1
+		case p.ts > ts:
+			return 1
+		}
+		return 0
+	}
+
+	if idx, found := SearchBy(points, 30, byTS); idx != 2 || !found {
+		t.Errorf("SearchBy() = %d, %v", idx, found)
+	}
+	if got := RangeBetweenFunc(points, 15, 30, byTS); !reflect.DeepEqual(got, points[1:3]) {
+		t.Errorf("RangeBetweenFunc() = %v", got)
+	}
+}
+
+func TestRangeBetween(t *testing.T) {
+	s := []int{1, 3, 5, 7, 9}
+	tests := []struct {
+		name   string
+		lo, hi int
+		want   []int
+	}{
+		{name: "inclusive", lo: 3, hi: 7, want: []int{3, 5, 7}},
+		{name: "between values", lo: 2, hi: 8, want: []int{3, 5, 7}},
+		{name: "empty", lo: 4, hi: 4, want: nil},
+		{name: "reversed", lo: 7, hi: 3, want: nil},
+		{name: "all", lo: 0, hi: 100, want: s},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RangeBetween(s, tt.lo, tt.hi); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RangeBetween() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsertSorted(t *testing.T) {
+	var s []int
+	for _, v := range []int{5, 1, 3, 3, 9, 0} {
+		s = InsertSorted(s, v)
+	}
+	if want := []int{0, 1, 3, 3, 5, 9}; !reflect.DeepEqual(s, want) {
+		t.Errorf("InsertSorted() = %v, want %v", s, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []int
+		want []int
+	}{
+		{name: "interleaved", a: []int{1, 4, 6}, b: []int{2, 3, 7, 8}, want: []int{1, 2, 3, 4, 6, 7, 8}},
+		{name: "empty a", a: nil, b: []int{1}, want: []int{1}},
+		{name: "both empty", a: nil, b: nil, want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Merge(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	type kv struct {
+		k int
+		s string
+	}
+	got := MergeFunc([]kv{{1, "a"}}, []kv{{1, "b"}}, func(x, y kv) int { return x.k - y.k })
+	if got[0].s != "a" || got[1].s != "b" {
+		t.Errorf("MergeFunc() not stable: %v", got)
+	}
+}
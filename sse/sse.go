@@ -0,0 +1,320 @@
+package sse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config Hub配置
+type Config struct {
+	Buffer      int           `toml:"buffer"`      // 每个客户端的发送队列长度, 默认64; 队列满时断开该客户端, 由客户端重连补发
+	History     int           `toml:"history"`     // 用于断线重连补发的最近事件数, 默认256
+	Heartbeat   time.Duration `toml:"heartbeat"`   // 心跳间隔, 避免代理关闭空闲连接, 默认15s
+	Retry       time.Duration `toml:"retry"`       // 建议浏览器的重连间隔, 0表示使用浏览器的默认值
+	PollTimeout time.Duration `toml:"pollTimeout"` // 长轮询最长等待时间, 默认30s
+}
+
+// Event 事件
+type Event struct {
+	ID    string `json:"id"`
+	Event string `json:"event,omitempty"`
+	Data  string `json:"data"`
+
+	group string
+	seq   uint64
+}
+
+type client struct {
+	ch     chan *Event
+	groups map[string]bool
+	done   chan struct{} // Hub关闭或发送队列满时关闭
+}
+
+func (c *client) accept(e *Event) bool {
+	return e.group == "" || c.groups[e.group]
+}
+
+// Hub 管理SSE连接和长轮询请求, 按分组推送事件:
+//
+//   - 事件ID在Hub内单调递增, 客户端重连时通过Last-Event-ID补发断开期间的事件
+//   - 分组为空的事件发送给所有客户端, 其他事件只发送给订阅了该分组的客户端
+//   - 不支持SSE的环境(如部分代理)可以使用Poll长轮询, 共享同一份事件
+//
+// 事件只保存在内存中, 多实例部署时需要会话保持, 或者各实例订阅同一个消息队列后各自Publish
+//
+//	hub := sse.New(sse.Config{})
+//	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+//		hub.Serve(w, r, "user:"+meta.UserID(r.Context()))
+//	})
+//	hub.Publish("user:42", "progress", map[string]int{"percent": 50})
+type Hub struct {
+	cfg Config
+
+	mu      sync.Mutex
+	seq     uint64
+	history []*Event // 环形缓冲区
+	next    int
+	clients map[*client]struct{}
+	closed  bool
+}
+
+// New new a Hub.
+func New(cfg Config) *Hub {
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 64
+	}
+	if cfg.History <= 0 {
+		cfg.History = 256
+	}
+	if cfg.Heartbeat <= 0 {
+		cfg.Heartbeat = 15 * time.Second
+	}
+	if cfg.PollTimeout <= 0 {
+		cfg.PollTimeout = 30 * time.Second
+	}
+	return &Hub{
+		cfg:     cfg,
+		history: make([]*Event, 0, cfg.History),
+		clients: make(map[*client]struct{}),
+	}
+}
+
+// Publish 向分组发送事件, group为空时发送给所有客户端; data为string或[]byte时原样发送, 否则编码为JSON
+func (h *Hub) Publish(group, event string, data interface{}) error {
+	var s string
+	switch v := data.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("sse: marshal %s: %w", event, err)
+		}
+		s = string(b)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	h.seq++
+	e := &Event{ID: strconv.FormatUint(h.seq, 10), Event: event, Data: s, group: group, seq: h.seq}
+	if len(h.history) < h.cfg.History {
+		h.history = append(h.history, e)
+	} else {
+		h.history[h.next] = e
+		h.next = (h.next + 1) % h.cfg.History
+	}
+	for c := range h.clients {
+		if !c.accept(e) {
+			continue
+		}
+		select {
+		case c.ch <- e:
+		default:
+			// 客户端处理不过来, 断开后由客户端带Last-Event-ID重连补发
+			h.drop(c)
+		}
+	}
+	return nil
+}
+
+// Broadcast 向所有客户端发送事件, 同Publish("", event, data)
+func (h *Hub) Broadcast(event string, data interface{}) error {
+	return h.Publish("", event, data)
+}
+
+// Clients 当前连接的客户端数, 包括等待中的长轮询请求
+func (h *Hub) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// Close 断开所有客户端, 之后的Publish被忽略, 新的请求返回503; 用于优雅退出
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for c := range h.clients {
+		h.drop(c)
+	}
+}
+
+// drop 移除客户端, 调用方需持有锁
+func (h *Hub) drop(c *client) {
+	delete(h.clients, c)
+	close(c.done)
+}
+
+// subscribe 注册客户端并返回lastID之后需要补发的事件, 两者在同一把锁内完成, 不会遗漏事件
+func (h *Hub) subscribe(lastID string, groups []string) (*client, []*Event, bool) {
+	c := &client{
+		ch:     make(chan *Event, h.cfg.Buffer),
+		groups: make(map[string]bool, len(groups)),
+		done:   make(chan struct{}),
+	}
+	for _, g := range groups {
+		c.groups[g] = true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, nil, false
+	}
+	h.clients[c] = struct{}{}
+	if lastID == "" {
+		return c, nil, true
+	}
+	last, err := strconv.ParseUint(lastID, 10, 64)
+	if err != nil {
+		return c, nil, true
+	}
+	var replay []*Event
+	for i := range h.history {
+		e := h.history[(h.next+i)%len(h.history)]
+		if e.seq > last && c.accept(e) {
+			replay = append(replay, e)
+		}
+	}
+	return c, replay, true
+}
+
+func (h *Hub) unsubscribe(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[c]; ok {
+		h.drop(c)
+	}
+}
+
+// lastEventID 浏览器重连时通过Last-Event-ID头传递, 手动重连或长轮询使用查询参数lastEventId
+func lastEventID(r *http.Request) string {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("lastEventId")
+}
+
+// Serve 建立SSE连接并订阅groups, 先补发Last-Event-ID之后的事件, 客户端断开或Hub关闭时返回
+func (h *Hub) Serve(w http.ResponseWriter, r *http.Request, groups ...string) {
+	c, replay, ok := h.subscribe(lastEventID(r), groups)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	defer h.unsubscribe(c)
+
+	hdr := w.Header()
+	hdr.Set("Content-Type", "text/event-stream")
+	hdr.Set("Cache-Control", "no-cache")
+	hdr.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+
+	if h.cfg.Retry > 0 {
+		fmt.Fprintf(w, "retry: %d\n\n", h.cfg.Retry.Milliseconds())
+	}
+	for _, e := range replay {
+		if writeEvent(w, e) != nil {
+			return
+		}
+	}
+	if rc.Flush() != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.cfg.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case <-c.done:
+			return
+		case e := <-c.ch:
+			err = writeEvent(w, e)
+		case <-heartbeat.C:
+			_, err = io.WriteString(w, ": ping\n\n")
+		}
+		if err != nil || rc.Flush() != nil {
+			return
+		}
+	}
+}
+
+// writeEvent 按SSE格式写入事件, 多行数据拆分为多个data字段
+func writeEvent(w io.Writer, e *Event) error {
+	var b strings.Builder
+	b.WriteString("id: ")
+	b.WriteString(e.ID)
+	b.WriteByte('\n')
+	if e.Event != "" {
+		b.WriteString("event: ")
+		b.WriteString(e.Event)
+		b.WriteByte('\n')
+	}
+	for _, line := range strings.Split(e.Data, "\n") {
+		b.WriteString("data: ")
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Poll 长轮询, 返回lastEventId之后的事件(JSON数组); 没有新事件时最多等待PollTimeout, 超时返回空数组
+//
+// 客户端使用最后一个事件的id作为下一次请求的lastEventId:
+//
+//	GET /poll?lastEventId=42
+func (h *Hub) Poll(w http.ResponseWriter, r *http.Request, groups ...string) {
+	c, events, ok := h.subscribe(lastEventID(r), groups)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	defer h.unsubscribe(c)
+
+	if len(events) == 0 {
+		timer := time.NewTimer(h.cfg.PollTimeout)
+		defer timer.Stop()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-c.done:
+		case <-timer.C:
+		case e := <-c.ch:
+			events = append(events, e)
+		}
+	}
+	// 取出已经到达的其他事件, 减少请求次数
+	for len(events) < h.cfg.Buffer {
+		select {
+		case e := <-c.ch:
+			events = append(events, e)
+			continue
+		default:
+		}
+		break
+	}
+
+	if events == nil {
+		events = []*Event{}
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	_ = json.NewEncoder(w).Encode(events)
+}
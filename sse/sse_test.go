@@ -0,0 +1,124 @@
+package sse
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readEvents 读取n个事件的id和data
+func readEvents(t *testing.T, sc *bufio.Scanner, n int) []string {
+	t.Helper()
+	var events []string
+	var id string
+	for len(events) < n && sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			events = append(events, id+":"+strings.TrimPrefix(line, "data: "))
+		}
+	}
+	return events
+}
+
+func TestServe(t *testing.T) {
+	hub := New(Config{Heartbeat: time.Hour})
+	defer hub.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hub.Serve(w, r, r.URL.Query().Get("group"))
+	}))
+	defer srv.Close()
+
+	_ = hub.Publish("a", "msg", "one")
+	_ = hub.Publish("b", "msg", "other group")
+	_ = hub.Broadcast("msg", "two")
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"?group=a", nil)
+	req.Header.Set("Last-Event-ID", "0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	sc := bufio.NewScanner(resp.Body)
+	if got := readEvents(t, sc, 2); strings.Join(got, ",") != "1:one,3:two" {
+		t.Fatalf("replay = %v", got)
+	}
+
+	for hub.Clients() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	_ = hub.Publish("b", "msg", "skip")
+	_ = hub.Publish("a", "progress", map[string]int{"percent": 50})
+	if got := readEvents(t, sc, 1); len(got) != 1 || got[0] != `5:{"percent":50}` {
+		t.Errorf("live = %v", got)
+	}
+}
+
+func TestPoll(t *testing.T) {
+	hub := New(Config{PollTimeout: 50 * time.Millisecond})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hub.Poll(w, r, "a")
+	}))
+	defer srv.Close()
+
+	poll := func(lastID string) []Event {
+		resp, err := http.Get(srv.URL + "?lastEventId=" + lastID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var events []Event
+		if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+			t.Fatal(err)
+		}
+		return events
+	}
+
+	tests := []struct {
+		name    string
+		publish func()
+		lastID  string
+		want    int
+	}{
+		{name: "timeout", lastID: "0", want: 0},
+		{name: "replay", publish: func() { _ = hub.Publish("a", "", "x"); _ = hub.Publish("a", "", "y") }, lastID: "1", want: 1},
+		{name: "wait", publish: func() {
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				_ = hub.Publish("a", "", "z")
+			}()
+		}, lastID: "2", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.publish != nil {
+				tt.publish()
+			}
+			if got := poll(tt.lastID); len(got) != tt.want {
+				t.Errorf("Poll() = %+v, want %d events", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlowClientDropped(t *testing.T) {
+	hub := New(Config{Buffer: 1})
+	c, _, _ := hub.subscribe("", nil)
+	_ = hub.Broadcast("", "1")
+	_ = hub.Broadcast("", "2")
+	select {
+	case <-c.done:
+	default:
+		t.Fatal("slow client not dropped")
+	}
+	if hub.Clients() != 0 {
+		t.Errorf("Clients() = %d", hub.Clients())
+	}
+}
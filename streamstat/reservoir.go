@@ -0,0 +1,85 @@
+package streamstat
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ReservoirSample 蓄水池抽样, 从未知长度的数据流中等概率抽取K个元素, 可以并发使用
+type ReservoirSample[T any] struct {
+	mu     sync.Mutex
+	k      int
+	seen   int64
+	sample []T
+	rnd    *rand.Rand
+}
+
+// ReservoirOption ReservoirSample的可选参数
+type ReservoirOption func(*reservoirOptions)
+
+type reservoirOptions struct {
+	seed int64
+}
+
+// WithSeed 指定随机种子, 用于测试中得到可复现的结果
+func WithSeed(seed int64) ReservoirOption {
+	return func(o *reservoirOptions) {
+		o.seed = seed
+	}
+}
+
+// NewReservoirSample new a ReservoirSample.
+func NewReservoirSample[T any](k int, opts ...ReservoirOption) *ReservoirSample[T] {
+	o := reservoirOptions{seed: time.Now().UnixNano()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if k < 0 {
+		k = 0
+	}
+	return &ReservoirSample[T]{
+		k:      k,
+		sample: make([]T, 0, k),
+		rnd:    rand.New(rand.NewSource(o.seed)),
+	}
+}
+
+// Add 加入一个元素
+func (r *ReservoirSample[T]) Add(v T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seen++
+	if len(r.sample) < r.k {
+		r.sample = append(r.sample, v)
+		return
+	}
+	// 第n个元素以k/n的概率替换样本中的随机一个
+	if j := r.rnd.Int63n(r.seen); j < int64(r.k) {
+		r.sample[j] = v
+	}
+}
+
+// Seen 已加入的元素总数
+func (r *ReservoirSample[T]) Seen() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.seen
+}
+
+// Sample 当前样本的副本, 元素个数为min(k, Seen())
+func (r *ReservoirSample[T]) Sample() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]T, len(r.sample))
+	copy(out, r.sample)
+	return out
+}
+
+// Reset 清空样本与计数
+func (r *ReservoirSample[T]) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seen = 0
+	r.sample = r.sample[:0]
+}
@@ -0,0 +1,76 @@
+package streamstat
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTopK(t *testing.T) {
+	intLess := func(a, b int) bool { return a < b }
+	tests := []struct {
+		name  string
+		k     int
+		input []int
+		want  []int
+	}{
+		{name: "fewer than k", k: 5, input: []int{3, 1, 2}, want: []int{3, 2, 1}},
+		{name: "stream", k: 3, input: []int{5, 1, 9, 3, 7, 2, 8}, want: []int{9, 8, 7}},
+		{name: "duplicates", k: 2, input: []int{4, 4, 4, 1}, want: []int{4, 4}},
+		{name: "zero k", k: 0, input: []int{1, 2}, want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			top := NewTopK(tt.k, intLess)
+			for _, v := range tt.input {
+				top.Add(v)
+			}
+			if got := top.Items(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Items() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopKStruct(t *testing.T) {
+	type player struct {
+		name  string
+		score int
+	}
+	top := NewTopK(2, func(a, b player) bool { return a.score < b.score })
+	top.Add(player{"a", 10})
+	top.Add(player{"b", 30})
+	if !top.Add(player{"c", 20}) || top.Add(player{"d", 5}) {
+		t.Errorf("Add() membership incorrect")
+	}
+	if min, _ := top.Min(); min.name != "c" {
+		t.Errorf("Min() = %v, want c", min)
+	}
+}
+
+func TestReservoirSample(t *testing.T) {
+	r := NewReservoirSample[int](3, WithSeed(1))
+	r.Add(1)
+	if got := r.Sample(); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("Sample() = %v, want [1]", got)
+	}
+
+	// 统计每个元素被抽中的频率, 应接近k/n
+	const n, k, rounds = 10, 3, 20000
+	counts := make([]int, n)
+	for i := 0; i < rounds; i++ {
+		r := NewReservoirSample[int](k, WithSeed(int64(i)))
+		for v := 0; v < n; v++ {
+			r.Add(v)
+		}
+		for _, v := range r.Sample() {
+			counts[v]++
+		}
+	}
+	want := float64(rounds) * k / n
+	for v, c := range counts {
+		if math.Abs(float64(c)-want)/want > 0.05 {
+			t.Errorf("value %d sampled %d times, want about %.0f", v, c, want)
+		}
+	}
+}
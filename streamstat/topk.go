@@ -0,0 +1,93 @@
+package streamstat
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+)
+
+// TopK 在数据流中保留最大的K个元素, 内存占用为O(K), 每次Add为O(logK), 可以并发使用
+type TopK[T any] struct {
+	mu sync.Mutex
+	k  int
+	h  *minHeap[T]
+}
+
+// NewTopK new a TopK, less(a, b)为true表示a小于b
+func NewTopK[T any](k int, less func(a, b T) bool) *TopK[T] {
+	if k < 0 {
+		k = 0
+	}
+	return &TopK[T]{k: k, h: &minHeap[T]{less: less}}
+}
+
+// Add 加入一个元素, 返回该元素当前是否在TopK中
+func (t *TopK[T]) Add(v T) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.k == 0 {
+		return false
+	}
+	if t.h.Len() < t.k {
+		heap.Push(t.h, v)
+		return true
+	}
+	// 堆顶是当前第K大的元素, 不比它大的直接丢弃
+	if !t.h.less(t.h.items[0], v) {
+		return false
+	}
+	t.h.items[0] = v
+	heap.Fix(t.h, 0)
+	return true
+}
+
+// Len 当前保留的元素个数
+func (t *TopK[T]) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.h.Len()
+}
+
+// Min 当前TopK中最小的元素, 即进入TopK的门槛
+func (t *TopK[T]) Min() (T, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.h.Len() == 0 {
+		var zero T
+		return zero, false
+	}
+	return t.h.items[0], true
+}
+
+// Items 按从大到小的顺序返回当前的TopK, 不影响后续Add
+func (t *TopK[T]) Items() []T {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]T, len(t.h.items))
+	copy(out, t.h.items)
+	sort.SliceStable(out, func(i, j int) bool { return t.h.less(out[j], out[i]) })
+	return out
+}
+
+// Reset 清空
+func (t *TopK[T]) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.h.items = nil
+}
+
+type minHeap[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+func (h *minHeap[T]) Len() int           { return len(h.items) }
+func (h *minHeap[T]) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+func (h *minHeap[T]) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *minHeap[T]) Push(x any)         { h.items = append(h.items, x.(T)) }
+func (h *minHeap[T]) Pop() any {
+	n := len(h.items) - 1
+	v := h.items[n]
+	h.items = h.items[:n]
+	return v
+}
@@ -0,0 +1,117 @@
+package syncx
+
+import (
+	"context"
+	"sync"
+)
+
+// KeyedLimiter 按key限制并发数, 例如同一用户最多同时执行n个操作; 空闲的key会被自动清理
+//
+// 每个key对应一个Semaphore, 同一key的等待者按先进先出的顺序获取
+type KeyedLimiter struct {
+	limit int64
+	mu    sync.Mutex
+	keys  map[string]*keyedEntry
+}
+
+type keyedEntry struct {
+	sem  *Semaphore
+	refs int // 持有和等待中的数量, 为0时从map中删除
+}
+
+// NewKeyedLimiter new a KeyedLimiter, limit为每个key的最大并发数
+func NewKeyedLimiter(limit int64) *KeyedLimiter {
+	if limit <= 0 {
+		limit = 1
+	}
+	return &KeyedLimiter{limit: limit, keys: make(map[string]*keyedEntry)}
+}
+
+func (l *KeyedLimiter) ref(key string) *keyedEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.keys[key]
+	if !ok {
+		e = &keyedEntry{sem: NewSemaphore(l.limit)}
+		l.keys[key] = e
+	}
+	e.refs++
+	return e
+}
+
+func (l *KeyedLimiter) unref(key string, e *keyedEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e.refs--
+	if e.refs == 0 {
+		delete(l.keys, key)
+	}
+}
+
+// Acquire 获取key的一个并发名额, 阻塞直到成功或ctx结束; 成功时返回的release必须调用且只能调用一次
+func (l *KeyedLimiter) Acquire(ctx context.Context, key string) (release func(), err error) {
+	e := l.ref(key)
+	if err := e.sem.Acquire(ctx, 1); err != nil {
+		l.unref(key, e)
+		return nil, err
+	}
+	return l.releaser(key, e), nil
+}
+
+// TryAcquire 不阻塞地尝试获取key的一个并发名额
+func (l *KeyedLimiter) TryAcquire(key string) (release func(), ok bool) {
+	e := l.ref(key)
+	if !e.sem.TryAcquire(1) {
+		l.unref(key, e)
+		return nil, false
+	}
+	return l.releaser(key, e), true
+}
+
+func (l *KeyedLimiter) releaser(key string, e *keyedEntry) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			e.sem.Release(1)
+			l.unref(key, e)
+		})
+	}
+}
+
+// Len 当前活跃(有持有者或等待者)的key数量
+func (l *KeyedLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.keys)
+}
+
+// KeyedMutex 按key互斥, 同一key的操作串行执行, 不同key之间互不影响
+//
+//	unlock, err := km.Lock(ctx, userID)
+//	if err != nil {
+//		return err
+//	}
+//	defer unlock()
+type KeyedMutex struct {
+	l *KeyedLimiter
+}
+
+// NewKeyedMutex new a KeyedMutex.
+func NewKeyedMutex() *KeyedMutex {
+	return &KeyedMutex{l: NewKeyedLimiter(1)}
+}
+
+// Lock 获取key的锁, 阻塞直到成功或ctx结束
+func (m *KeyedMutex) Lock(ctx context.Context, key string) (unlock func(), err error) {
+	return m.l.Acquire(ctx, key)
+}
+
+// TryLock 不阻塞地尝试获取key的锁, 常用于拒绝重复提交
+func (m *KeyedMutex) TryLock(key string) (unlock func(), ok bool) {
+	return m.l.TryAcquire(key)
+}
+
+// Len 当前被持有或等待中的key数量
+func (m *KeyedMutex) Len() int {
+	return m.l.Len()
+}
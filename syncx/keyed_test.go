@@ -0,0 +1,74 @@
+package syncx
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	km := NewKeyedMutex()
+	ctx := context.Background()
+
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := km.Lock(ctx, "user-1")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer unlock()
+			n := atomic.AddInt32(&running, 1)
+			if n > atomic.LoadInt32(&maxRunning) {
+				atomic.StoreInt32(&maxRunning, n)
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+
+	// 不同key互不阻塞
+	unlock, ok := km.TryLock("user-2")
+	if !ok {
+		t.Errorf("TryLock(user-2) should succeed")
+	}
+	unlock()
+	unlock() // 重复调用无副作用
+
+	wg.Wait()
+	if maxRunning != 1 {
+		t.Errorf("max concurrent holders = %d, want 1", maxRunning)
+	}
+	if km.Len() != 0 {
+		t.Errorf("Len() = %d, idle keys should be cleaned", km.Len())
+	}
+}
+
+func TestKeyedLimiter(t *testing.T) {
+	l := NewKeyedLimiter(2)
+	r1, ok1 := l.TryAcquire("k")
+	_, ok2 := l.TryAcquire("k")
+	_, ok3 := l.TryAcquire("k")
+	if !ok1 || !ok2 || ok3 {
+		t.Fatalf("TryAcquire() = %v, %v, %v, want true, true, false", ok1, ok2, ok3)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx, "k"); err == nil {
+		t.Errorf("Acquire() should time out when limit reached")
+	}
+	r1()
+	if _, ok := l.TryAcquire("k"); !ok {
+		t.Errorf("TryAcquire() after release should succeed")
+	}
+	if l.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", l.Len())
+	}
+}
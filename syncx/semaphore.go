@@ -0,0 +1,48 @@
+package syncx
+
+import (
+	"context"
+	"sync/atomic"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// Semaphore 带权重的信号量, 等待者按先进先出的顺序获取, 基于semaphore.Weighted
+type Semaphore struct {
+	sem  *semaphore.Weighted
+	held atomic.Int64
+}
+
+// NewSemaphore new a Semaphore, size为最大权重
+func NewSemaphore(size int64) *Semaphore {
+	return &Semaphore{sem: semaphore.NewWeighted(size)}
+}
+
+// Acquire 获取权重n, 阻塞直到成功或ctx结束; n超过size时等待ctx结束
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	if err := s.sem.Acquire(ctx, n); err != nil {
+		return err
+	}
+	s.held.Add(n)
+	return nil
+}
+
+// TryAcquire 不阻塞地尝试获取权重n
+func (s *Semaphore) TryAcquire(n int64) bool {
+	if !s.sem.TryAcquire(n) {
+		return false
+	}
+	s.held.Add(n)
+	return true
+}
+
+// Release 归还权重n, 归还超过已获取的权重时panic
+func (s *Semaphore) Release(n int64) {
+	s.held.Add(-n)
+	s.sem.Release(n)
+}
+
+// Held 当前已被获取的权重
+func (s *Semaphore) Held() int64 {
+	return s.held.Load()
+}
@@ -0,0 +1,69 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSemaphore(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int64
+		held    int64
+		acquire int64
+		wantOk  bool
+	}{
+		{name: "fits", size: 3, held: 1, acquire: 2, wantOk: true},
+		{name: "full", size: 3, held: 2, acquire: 2, wantOk: false},
+		{name: "larger than size", size: 3, acquire: 4, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSemaphore(tt.size)
+			if tt.held > 0 && !s.TryAcquire(tt.held) {
+				t.Fatalf("TryAcquire(%d) failed", tt.held)
+			}
+			if got := s.TryAcquire(tt.acquire); got != tt.wantOk {
+				t.Errorf("TryAcquire(%d) = %v, want %v", tt.acquire, got, tt.wantOk)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			if !tt.wantOk {
+				if err := s.Acquire(ctx, tt.acquire); !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("Acquire() error = %v, want deadline exceeded", err)
+				}
+				if s.Held() != tt.held {
+					t.Errorf("Held() = %d after canceled Acquire, want %d", s.Held(), tt.held)
+				}
+			}
+		})
+	}
+}
+
+func TestSemaphoreFIFO(t *testing.T) {
+	s := NewSemaphore(2)
+	_ = s.Acquire(context.Background(), 2)
+
+	order := make(chan int64, 2)
+	for _, n := range []int64{2, 1} {
+		n := n
+		go func() {
+			_ = s.Acquire(context.Background(), n)
+			order <- n
+			s.Release(n)
+		}()
+		time.Sleep(10 * time.Millisecond) // 保证入队顺序
+	}
+	s.Release(2)
+
+	if first := <-order; first != 2 {
+		t.Errorf("first acquired weight = %d, want 2", first)
+	}
+	<-order
+	if s.Held() != 0 {
+		t.Errorf("Held() = %d, want 0", s.Held())
+	}
+}
@@ -0,0 +1,115 @@
+package sysutil
+
+import (
+	"bufio"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	cgroupRoot     = "/sys/fs/cgroup"
+	procSelfCgroup = "/proc/self/cgroup"
+)
+
+// v1中未限制内存时 memory.limit_in_bytes 为一个接近int64上限的值
+const memoryUnlimitedV1 = math.MaxInt64 / 4096 * 4096
+
+// IsCgroupV2 是否为cgroup v2(unified)
+func IsCgroupV2() bool {
+	_, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers"))
+	return err == nil
+}
+
+// CPUQuota 容器的CPU核数限制, 如 1.5 表示1.5核, 未限制时ok为false
+func CPUQuota() (quota float64, ok bool) {
+	if IsCgroupV2() {
+		// cpu.max: "$MAX $PERIOD", 未限制时 $MAX 为 "max"
+		fields := strings.Fields(readCgroupFile("", "cpu.max"))
+		if len(fields) != 2 || fields[0] == "max" {
+			return 0, false
+		}
+		return ratio(fields[0], fields[1])
+	}
+	q := readCgroupFile("cpu", "cpu.cfs_quota_us")
+	p := readCgroupFile("cpu", "cpu.cfs_period_us")
+	if q == "" || p == "" || strings.HasPrefix(q, "-") {
+		return 0, false
+	}
+	return ratio(q, p)
+}
+
+// MemoryLimit 容器的内存限制(字节), 未限制时ok为false
+func MemoryLimit() (limit uint64, ok bool) {
+	var s string
+	if IsCgroupV2() {
+		s = readCgroupFile("", "memory.max")
+	} else {
+		s = readCgroupFile("memory", "memory.limit_in_bytes")
+	}
+	if s == "" || s == "max" {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || n >= memoryUnlimitedV1 {
+		return 0, false
+	}
+	return n, true
+}
+
+func ratio(a, b string) (float64, bool) {
+	x, err1 := strconv.ParseFloat(a, 64)
+	y, err2 := strconv.ParseFloat(b, 64)
+	if err1 != nil || err2 != nil || x <= 0 || y <= 0 {
+		return 0, false
+	}
+	return x / y, true
+}
+
+// readCgroupFile 读取当前进程所在cgroup的文件, 找不到时回退到挂载根目录(容器内通常如此)
+//
+// controller为空表示cgroup v2
+func readCgroupFile(controller, name string) string {
+	base := filepath.Join(cgroupRoot, controller)
+	candidates := []string{base}
+	if p := selfCgroupPath(controller); p != "" && p != "/" {
+		candidates = append([]string{filepath.Join(base, p)}, candidates...)
+	}
+	for _, dir := range candidates {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return strings.TrimSpace(string(b))
+		}
+	}
+	return ""
+}
+
+// selfCgroupPath 从 /proc/self/cgroup 中解析controller对应的路径
+//
+// v1: "4:memory:/docker/xxx", v2: "0::/kubepods/xxx"
+func selfCgroupPath(controller string) string {
+	f, err := os.Open(procSelfCgroup)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if controller == "" && parts[0] == "0" && parts[1] == "" {
+			return parts[2]
+		}
+		for _, c := range strings.Split(parts[1], ",") {
+			if controller != "" && c == controller {
+				return parts[2]
+			}
+		}
+	}
+	return ""
+}
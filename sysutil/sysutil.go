@@ -0,0 +1,81 @@
+package sysutil
+
+import (
+	"math"
+	"os"
+	"runtime"
+	"strings"
+)
+
+var (
+	dockerEnvFile  = "/.dockerenv"
+	k8sTokenFile   = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	procInitCgroup = "/proc/1/cgroup"
+)
+
+// InDocker 是否运行在容器中(docker/containerd/podman)
+func InDocker() bool {
+	if _, err := os.Stat(dockerEnvFile); err == nil {
+		return true
+	}
+	if _, err := os.Stat("/run/.containerenv"); err == nil {
+		return true
+	}
+	b, err := os.ReadFile(procInitCgroup)
+	if err != nil {
+		return false
+	}
+	s := string(b)
+	return strings.Contains(s, "docker") || strings.Contains(s, "containerd") ||
+		strings.Contains(s, "kubepods") || strings.Contains(s, "libpod")
+}
+
+// InKubernetes 是否运行在Kubernetes中
+func InKubernetes() bool {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return true
+	}
+	_, err := os.Stat(k8sTokenFile)
+	return err == nil
+}
+
+// Hostname 主机名, 获取失败时返回空字符串
+func Hostname() string {
+	name, _ := os.Hostname()
+	return name
+}
+
+// PodName Kubernetes中的Pod名称, 优先读取Downward API注入的POD_NAME, 否则使用主机名; 不在Kubernetes中时返回空字符串
+func PodName() string {
+	if name := os.Getenv("POD_NAME"); name != "" {
+		return name
+	}
+	if InKubernetes() {
+		return Hostname()
+	}
+	return ""
+}
+
+// AutoMaxProcs 根据容器CPU限制设置GOMAXPROCS, 返回设置前后的值
+//
+// Go运行时默认按宿主机核数设置GOMAXPROCS, 在限制了CPU的容器中会导致频繁的CFS限流.
+// 向下取整且最小为1; 未限制CPU、或已通过环境变量GOMAXPROCS指定时不做修改
+func AutoMaxProcs() (prev, cur int) {
+	prev = runtime.GOMAXPROCS(0)
+	if os.Getenv("GOMAXPROCS") != "" {
+		return prev, prev
+	}
+	quota, ok := CPUQuota()
+	if !ok {
+		return prev, prev
+	}
+	cur = int(math.Floor(quota))
+	if cur < 1 {
+		cur = 1
+	}
+	if cur >= runtime.NumCPU() {
+		return prev, prev
+	}
+	runtime.GOMAXPROCS(cur)
+	return prev, cur
+}
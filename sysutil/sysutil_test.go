@@ -0,0 +1,114 @@
+package sysutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeCgroup 在临时目录中构造cgroup文件
+func fakeCgroup(t *testing.T, self string, files map[string]string) {
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	selfFile := filepath.Join(t.TempDir(), "cgroup")
+	_ = os.WriteFile(selfFile, []byte(self), 0644)
+
+	oldRoot, oldSelf := cgroupRoot, procSelfCgroup
+	cgroupRoot, procSelfCgroup = root, selfFile
+	t.Cleanup(func() {
+		cgroupRoot, procSelfCgroup = oldRoot, oldSelf
+	})
+}
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		self      string
+		files     map[string]string
+		wantCPU   float64
+		wantCPUOK bool
+		wantMem   uint64
+		wantMemOK bool
+	}{
+		{
+			name: "v2_limited",
+			self: "0::/\n",
+			files: map[string]string{
+				"cgroup.controllers": "cpu memory",
+				"cpu.max":            "150000 100000\n",
+				"memory.max":         "536870912\n",
+			},
+			wantCPU: 1.5, wantCPUOK: true,
+			wantMem: 536870912, wantMemOK: true,
+		},
+		{
+			name: "v2_unlimited",
+			self: "0::/\n",
+			files: map[string]string{
+				"cgroup.controllers": "cpu memory",
+				"cpu.max":            "max 100000\n",
+				"memory.max":         "max\n",
+			},
+		},
+		{
+			name: "v2_nested",
+			self: "0::/kubepods/pod1\n",
+			files: map[string]string{
+				"cgroup.controllers":       "cpu memory",
+				"kubepods/pod1/cpu.max":    "200000 100000",
+				"kubepods/pod1/memory.max": "1073741824",
+				"cpu.max":                  "max 100000",
+			},
+			wantCPU: 2, wantCPUOK: true,
+			wantMem: 1073741824, wantMemOK: true,
+		},
+		{
+			name: "v1_limited",
+			self: "4:memory:/docker/abc\n2:cpu,cpuacct:/docker/abc\n",
+			files: map[string]string{
+				"cpu/docker/abc/cpu.cfs_quota_us":         "50000",
+				"cpu/docker/abc/cpu.cfs_period_us":        "100000",
+				"memory/docker/abc/memory.limit_in_bytes": "268435456",
+			},
+			wantCPU: 0.5, wantCPUOK: true,
+			wantMem: 268435456, wantMemOK: true,
+		},
+		{
+			name: "v1_unlimited",
+			self: "4:memory:/\n2:cpu,cpuacct:/\n",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":         "-1",
+				"cpu/cpu.cfs_period_us":        "100000",
+				"memory/memory.limit_in_bytes": "9223372036854771712",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeCgroup(t, tt.self, tt.files)
+			cpu, ok := CPUQuota()
+			if cpu != tt.wantCPU || ok != tt.wantCPUOK {
+				t.Errorf("CPUQuota() = %v, %v, want %v, %v", cpu, ok, tt.wantCPU, tt.wantCPUOK)
+			}
+			mem, ok := MemoryLimit()
+			if mem != tt.wantMem || ok != tt.wantMemOK {
+				t.Errorf("MemoryLimit() = %v, %v, want %v, %v", mem, ok, tt.wantMem, tt.wantMemOK)
+			}
+		})
+	}
+}
+
+func TestPodName(t *testing.T) {
+	t.Setenv("POD_NAME", "api-7d9f-x2")
+	if got := PodName(); got != "api-7d9f-x2" {
+		t.Errorf("PodName() = %v, want api-7d9f-x2", got)
+	}
+}
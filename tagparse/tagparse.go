@@ -0,0 +1,100 @@
+package tagparse
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Tag 解析后的结构体标签, 语法为 `key:"name,flag,k=v"`:
+//
+//   - 第一段为名称, 可以为空, 表示使用字段名
+//   - 后续不含"="的段为开关, 如omitempty
+//   - 含"="的段为参数, 如convert=unix; 值中的逗号需写作"\,"
+//   - 整个标签为"-"时表示跳过该字段, 需要名称为"-"时写作"-,"
+type Tag struct {
+	Name   string
+	Skip   bool
+	Flags  []string
+	Params map[string]string
+}
+
+// Parse 解析标签的值
+func Parse(tag string) Tag {
+	if tag == "-" {
+		return Tag{Skip: true}
+	}
+	parts := split(tag)
+	t := Tag{Name: parts[0]}
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		if k, v, ok := strings.Cut(part, "="); ok {
+			if t.Params == nil {
+				t.Params = make(map[string]string)
+			}
+			t.Params[strings.TrimSpace(k)] = v
+			continue
+		}
+		t.Flags = append(t.Flags, strings.TrimSpace(part))
+	}
+	return t
+}
+
+// split 按未转义的逗号分隔, 并去掉转义符
+func split(tag string) []string {
+	var parts []string
+	var b strings.Builder
+	for i := 0; i < len(tag); i++ {
+		switch c := tag[i]; {
+		case c == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			b.WriteByte(',')
+			i++
+		case c == ',':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return append(parts, b.String())
+}
+
+// Lookup 读取并解析字段上key对应的标签, ok表示标签是否存在
+func Lookup(field reflect.StructField, key string) (Tag, bool) {
+	raw, ok := field.Tag.Lookup(key)
+	if !ok {
+		return Tag{}, false
+	}
+	return Parse(raw), true
+}
+
+// Get 读取并解析字段上key对应的标签, 不存在时返回零值
+func Get(field reflect.StructField, key string) Tag {
+	t, _ := Lookup(field, key)
+	return t
+}
+
+// Has 是否包含开关flag
+func (t Tag) Has(flag string) bool {
+	for _, f := range t.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// Param 参数key的值
+func (t Tag) Param(key string) (string, bool) {
+	v, ok := t.Params[key]
+	return v, ok
+}
+
+// NameOr 名称为空时返回def, 一般传入字段名
+func (t Tag) NameOr(def string) string {
+	if t.Name == "" {
+		return def
+	}
+	return t.Name
+}
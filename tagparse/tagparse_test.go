@@ -0,0 +1,57 @@
+package tagparse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want Tag
+	}{
+		{tag: "", want: Tag{}},
+		{tag: "-", want: Tag{Skip: true}},
+		{tag: "-,", want: Tag{Name: "-"}},
+		{tag: "name", want: Tag{Name: "name"}},
+		{tag: ",omitempty", want: Tag{Flags: []string{"omitempty"}}},
+		{
+			tag:  "created,omitempty,convert=unix",
+			want: Tag{Name: "created", Flags: []string{"omitempty"}, Params: map[string]string{"convert": "unix"}},
+		},
+		{
+			tag:  `tags,default=a\,b,inline`,
+			want: Tag{Name: "tags", Flags: []string{"inline"}, Params: map[string]string{"default": "a,b"}},
+		},
+		{tag: "x,format=", want: Tag{Name: "x", Params: map[string]string{"format": ""}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := Parse(tt.tag); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	type user struct {
+		ID   int `copy:"uid,omitempty,convert=string"`
+		Name string
+	}
+	typ := reflect.TypeOf(user{})
+
+	tag, ok := Lookup(typ.Field(0), "copy")
+	if !ok || tag.Name != "uid" || !tag.Has("omitempty") || tag.Has("inline") {
+		t.Errorf("Lookup() = %+v, %v", tag, ok)
+	}
+	if v, ok := tag.Param("convert"); !ok || v != "string" {
+		t.Errorf("Param() = %q, %v", v, ok)
+	}
+	if _, ok := Lookup(typ.Field(1), "copy"); ok {
+		t.Errorf("Lookup() on untagged field ok = true")
+	}
+	if got := Get(typ.Field(1), "copy").NameOr("Name"); got != "Name" {
+		t.Errorf("NameOr() = %q", got)
+	}
+}
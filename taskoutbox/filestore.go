@@ -0,0 +1,204 @@
+package taskoutbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ChangSZ/golib/file"
+	"github.com/ChangSZ/golib/log"
+	"github.com/ChangSZ/golib/ndjson"
+)
+
+var _ Store = (*FileStore)(nil)
+
+// walRecord WAL中的一条记录, 每次写入都是任务的完整状态, 回放时后写的覆盖先写的
+type walRecord struct {
+	Op   string `json:"op"` // append或update
+	Task Task   `json:"task"`
+}
+
+// FileStore 基于追加写文件(WAL)的存储, 每次写入后fsync, 打开时回放文件恢复状态
+//
+// 已完成的任务保留在文件中用于去重, 可以定期调用Compact清理
+type FileStore struct {
+	mem  *MemoryStore
+	path string
+	f    *os.File
+	w    *ndjson.Writer
+}
+
+// OpenFileStore 打开或创建WAL文件, 末尾因崩溃写坏的记录会被跳过
+func OpenFileStore(path string) (*FileStore, error) {
+	if err := file.MakeDirByFile(path); err != nil {
+		return nil, err
+	}
+	s := &FileStore{mem: NewMemoryStore(), path: path}
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileStore) replay() error {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := ndjson.NewReader(f, ndjson.WithSkipErrors(func(e *ndjson.LineError) {
+		log.Warnw("msg", "taskoutbox: skip corrupted wal record", "path", s.path, "line", e.Line, "err", e.Err)
+	}))
+	if err != nil {
+		return err
+	}
+	for {
+		var rec walRecord
+		if err := r.Decode(&rec); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("taskoutbox: replay %s: %w", s.path, err)
+		}
+		s.mem.tasks[rec.Task.ID] = rec.Task
+	}
+}
+
+func (s *FileStore) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	// 崩溃时最后一条记录可能没写完, 补上换行避免与之后的记录连在一起
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			if _, err := f.Write([]byte{'\n'}); err != nil {
+				f.Close()
+				return err
+			}
+		}
+	}
+	w, err := ndjson.NewWriter(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.f, s.w = f, w
+	return nil
+}
+
+// write 写入一条记录并落盘, 调用方需持有s.mem.mu
+func (s *FileStore) write(op string, task Task) error {
+	if err := s.w.Encode(walRecord{Op: op, Task: task}); err != nil {
+		return err
+	}
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	return s.f.Sync()
+}
+
+func (s *FileStore) Append(ctx context.Context, task Task) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+	if _, ok := s.mem.tasks[task.ID]; ok {
+		return ErrDuplicate
+	}
+	if err := s.write("append", task); err != nil {
+		return fmt.Errorf("taskoutbox: append %s: %w", task.ID, err)
+	}
+	return s.mem.append(task)
+}
+
+func (s *FileStore) Due(ctx context.Context, now time.Time, limit int) ([]Task, error) {
+	return s.mem.Due(ctx, now, limit)
+}
+
+func (s *FileStore) Update(ctx context.Context, task Task) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+	if _, ok := s.mem.tasks[task.ID]; !ok {
+		return ErrNotFound
+	}
+	if err := s.write("update", task); err != nil {
+		return fmt.Errorf("taskoutbox: update %s: %w", task.ID, err)
+	}
+	return s.mem.update(task)
+}
+
+func (s *FileStore) Get(ctx context.Context, id string) (Task, error) {
+	return s.mem.Get(ctx, id)
+}
+
+// Compact 重写WAL, 只保留未完成的任务和retain时间内完成的任务(用于去重)
+func (s *FileStore) Compact(retain time.Duration) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	// 新文件写完并落盘、rename成功后才替换当前的文件句柄, 任何一步失败都不影响原WAL
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	fail := func(err error) error {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	w, err := ndjson.NewWriter(f)
+	if err != nil {
+		return fail(err)
+	}
+	cutoff := time.Now().Add(-retain)
+	keep := make(map[string]Task, len(s.mem.tasks))
+	for id, t := range s.mem.tasks {
+		if t.State != StatePending && t.UpdatedAt.Before(cutoff) {
+			continue
+		}
+		keep[id] = t
+		if err := w.Encode(walRecord{Op: "append", Task: t}); err != nil {
+			return fail(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fail(err)
+	}
+	if err := f.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fail(err)
+	}
+	syncDir(filepath.Dir(s.path))
+	s.f.Close()
+	s.f, s.w = f, w
+	s.mem.tasks = keep
+	return nil
+}
+
+// Close 关闭WAL文件
+func (s *FileStore) Close() error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+	return s.f.Close()
+}
+
+// syncDir 确保rename落盘
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,219 @@
+package taskoutbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ChangSZ/golib/log"
+	"github.com/ChangSZ/golib/meta"
+)
+
+// Handler 任务处理函数
+//
+// 进程在处理成功后、状态落盘前崩溃时任务会被再次投递, 有外部副作用的处理应以Task.ID作为幂等key(见idempotency包)
+type Handler func(ctx context.Context, task Task) error
+
+// Permanent 包装后的错误不再重试, 任务直接标记为dead
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+type Config struct {
+	PollInterval time.Duration `toml:"pollInterval"` // 轮询间隔, 默认1s
+	BatchSize    int           `toml:"batchSize"`    // 每次轮询最多处理的任务数, 默认100
+	MaxAttempts  int           `toml:"maxAttempts"`  // 最大尝试次数, 默认10
+	Backoff      time.Duration `toml:"backoff"`      // 首次重试间隔, 之后每次翻倍, 默认1s
+	MaxBackoff   time.Duration `toml:"maxBackoff"`   // 最大重试间隔, 默认10m
+}
+
+// Outbox 可靠任务记录器: 先持久化再异步执行, 进程崩溃后重启可以继续执行未完成的任务
+//
+//	store, _ := taskoutbox.OpenFileStore("data/outbox.wal")
+//	ob := taskoutbox.New(store, handle, taskoutbox.Config{})
+//	ob.Start(ctx)
+//	defer ob.Stop()
+//	ob.Add(ctx, "order.notify", payload)
+type Outbox struct {
+	cfg     Config
+	store   Store
+	handler Handler
+	now     func() time.Time
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+	wake   chan struct{}
+}
+
+// New new a Outbox.
+func New(store Store, handler Handler, cfg Config) *Outbox {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 10
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 10 * time.Minute
+	}
+	return &Outbox{
+		cfg:     cfg,
+		store:   store,
+		handler: handler,
+		now:     time.Now,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Add 以随机ID添加任务, payload为[]byte时原样保存, 否则编码为JSON
+func (o *Outbox) Add(ctx context.Context, typ string, payload interface{}) (string, error) {
+	id := meta.NewRequestID()
+	return id, o.AddWithID(ctx, id, typ, payload)
+}
+
+// AddWithID 以指定ID添加任务, 相同ID的任务只会被记录一次, 重复添加返回ErrDuplicate
+func (o *Outbox) AddWithID(ctx context.Context, id, typ string, payload interface{}) error {
+	b, ok := payload.([]byte)
+	if !ok {
+		var err error
+		if b, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("taskoutbox: marshal payload: %w", err)
+		}
+	}
+	now := o.now()
+	err := o.store.Append(ctx, Task{
+		ID:        id,
+		Type:      typ,
+		Payload:   b,
+		State:     StatePending,
+		NextAt:    now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+	// 唤醒轮询协程尽快执行
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start 启动轮询协程, ctx结束或调用Stop时退出
+func (o *Outbox) Start(ctx context.Context) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.cancel != nil {
+		return
+	}
+	ctx, o.cancel = context.WithCancel(ctx)
+	o.done = make(chan struct{})
+
+	go func() {
+		defer close(o.done)
+		ticker := time.NewTicker(o.cfg.PollInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := o.Poll(ctx); err != nil && ctx.Err() == nil {
+				log.Errorf("taskoutbox: poll failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-o.wake:
+			}
+		}
+	}()
+}
+
+// Stop 停止轮询并等待正在处理的任务结束
+func (o *Outbox) Stop() {
+	o.mu.Lock()
+	cancel, done := o.cancel, o.done
+	o.cancel = nil
+	o.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Poll 立即处理一批到期的任务, 返回处理的任务数
+func (o *Outbox) Poll(ctx context.Context) (int, error) {
+	tasks, err := o.store.Due(ctx, o.now(), o.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for i, task := range tasks {
+		if ctx.Err() != nil {
+			return i, ctx.Err()
+		}
+		if err := o.dispatch(ctx, task); err != nil {
+			return i, err
+		}
+	}
+	return len(tasks), nil
+}
+
+// dispatch 执行任务并记录结果, 只有写存储失败时返回错误
+func (o *Outbox) dispatch(ctx context.Context, task Task) error {
+	err := o.call(ctx, task)
+	task.Attempts++
+	task.UpdatedAt = o.now()
+
+	var perm *permanentError
+	switch {
+	case err == nil:
+		task.State = StateDone
+		task.LastError = ""
+	case errors.As(err, &perm) || task.Attempts >= o.cfg.MaxAttempts:
+		task.State = StateDead
+		task.LastError = err.Error()
+		log.Errorf("taskoutbox: task %s(%s) dead after %d attempts: %v", task.ID, task.Type, task.Attempts, err)
+	default:
+		task.LastError = err.Error()
+		task.NextAt = o.now().Add(o.backoff(task.Attempts))
+		log.Warnw("msg", "taskoutbox: task failed, will retry", "id", task.ID, "type", task.Type,
+			"attempts", task.Attempts, "nextAt", task.NextAt, "err", err)
+	}
+	// 即使ctx已取消也要记录结果, 避免成功的任务被重复投递
+	return o.store.Update(context.WithoutCancel(ctx), task)
+}
+
+func (o *Outbox) call(ctx context.Context, task Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("taskoutbox: handler panic: %v", r)
+		}
+	}()
+	return o.handler(ctx, task)
+}
+
+func (o *Outbox) backoff(attempts int) time.Duration {
+	d := o.cfg.Backoff
+	for i := 1; i < attempts && d < o.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, o.cfg.MaxBackoff)
+}
@@ -0,0 +1,121 @@
+package taskoutbox
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrDuplicate 相同ID的任务已存在或已完成
+var ErrDuplicate = errors.New("taskoutbox: duplicate task")
+
+// ErrNotFound 任务不存在
+var ErrNotFound = errors.New("taskoutbox: task not found")
+
+// State 任务状态
+type State string
+
+const (
+	StatePending State = "pending"
+	StateDone    State = "done"
+	StateDead    State = "dead" // 超过最大重试次数
+)
+
+// Task 待执行的任务
+type Task struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Payload   []byte    `json:"payload"`
+	State     State     `json:"state"`
+	Attempts  int       `json:"attempts"`
+	NextAt    time.Time `json:"nextAt"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// Store 任务的持久化存储, 可以基于文件WAL(FileStore)或数据库表实现
+type Store interface {
+	// Append 写入新任务, ID已存在(包括已完成的)时返回ErrDuplicate
+	Append(ctx context.Context, task Task) error
+	// Due 返回NextAt不晚于now的待执行任务, 最多limit个, 按NextAt排序
+	Due(ctx context.Context, now time.Time, limit int) ([]Task, error)
+	// Update 更新任务的状态、重试次数、下次执行时间和错误信息
+	Update(ctx context.Context, task Task) error
+	// Get 按ID查询任务
+	Get(ctx context.Context, id string) (Task, error)
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore 内存存储, 不能在进程崩溃后恢复, 用于测试
+type MemoryStore struct {
+	mu    sync.Mutex
+	tasks map[string]Task
+}
+
+// NewMemoryStore new a MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tasks: make(map[string]Task)}
+}
+
+func (s *MemoryStore) Append(ctx context.Context, task Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.append(task)
+}
+
+func (s *MemoryStore) append(task Task) error {
+	if _, ok := s.tasks[task.ID]; ok {
+		return ErrDuplicate
+	}
+	s.tasks[task.ID] = task
+	return nil
+}
+
+func (s *MemoryStore) Due(ctx context.Context, now time.Time, limit int) ([]Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []Task
+	for _, t := range s.tasks {
+		if t.State == StatePending && !t.NextAt.After(now) {
+			due = append(due, t)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAt.Equal(due[j].NextAt) {
+			return due[i].NextAt.Before(due[j].NextAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (s *MemoryStore) Update(ctx context.Context, task Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.update(task)
+}
+
+func (s *MemoryStore) update(task Task) error {
+	if _, ok := s.tasks[task.ID]; !ok {
+		return ErrNotFound
+	}
+	s.tasks[task.ID] = task
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks[id]
+	if !ok {
+		return Task{}, ErrNotFound
+	}
+	return t, nil
+}
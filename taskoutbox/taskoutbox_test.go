@@ -0,0 +1,174 @@
+package taskoutbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOutboxRetry(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var calls int
+	ob := New(store, func(ctx context.Context, task Task) error {
+		calls++
+		if calls < 3 {
+			return errors.New("downstream unavailable")
+		}
+		return nil
+	}, Config{Backoff: time.Second, MaxAttempts: 5})
+	ob.now = func() time.Time { return now }
+
+	if err := ob.AddWithID(ctx, "t1", "notify", map[string]int{"order": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ob.AddWithID(ctx, "t1", "notify", nil); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("duplicate AddWithID() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		advance   time.Duration
+		wantN     int
+		wantState State
+	}{
+		{name: "first attempt fails", advance: 0, wantN: 1, wantState: StatePending},
+		{name: "not due yet", advance: 500 * time.Millisecond, wantN: 0, wantState: StatePending},
+		{name: "second attempt fails", advance: 500 * time.Millisecond, wantN: 1, wantState: StatePending},
+		{name: "backoff doubled", advance: time.Second, wantN: 0, wantState: StatePending},
+		{name: "third attempt succeeds", advance: time.Second, wantN: 1, wantState: StateDone},
+		{name: "done is not redelivered", advance: time.Hour, wantN: 0, wantState: StateDone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			n, err := ob.Poll(ctx)
+			if err != nil || n != tt.wantN {
+				t.Fatalf("Poll() = %d, %v, want %d", n, err, tt.wantN)
+			}
+			if task, _ := store.Get(ctx, "t1"); task.State != tt.wantState {
+				t.Errorf("state = %s, want %s", task.State, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestOutboxPermanent(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	ob := New(store, func(ctx context.Context, task Task) error {
+		return Permanent(errors.New("bad payload"))
+	}, Config{})
+	id, _ := ob.Add(ctx, "x", []byte("raw"))
+	ob.Poll(ctx)
+	if task, _ := store.Get(ctx, id); task.State != StateDead || task.Attempts != 1 || string(task.Payload) != "raw" {
+		t.Errorf("task = %+v, want dead after one attempt", task)
+	}
+}
+
+func TestFileStoreRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox", "tasks.wal")
+	ctx := context.Background()
+
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ob := New(store, func(ctx context.Context, task Task) error {
+		if task.ID == "fail" {
+			return errors.New("boom")
+		}
+		return nil
+	}, Config{})
+	ob.AddWithID(ctx, "ok", "a", 1)
+	ob.AddWithID(ctx, "fail", "b", 2)
+	ob.Poll(ctx)
+	store.Close()
+
+	// 模拟崩溃时写了一半的记录
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"op":"append","task":{"id":"ha`)
+	f.Close()
+
+	store, err = OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if task, _ := store.Get(ctx, "ok"); task.State != StateDone {
+		t.Errorf("ok state = %s, want done", task.State)
+	}
+	if task, _ := store.Get(ctx, "fail"); task.State != StatePending || task.Attempts != 1 {
+		t.Errorf("fail task = %+v, want pending with 1 attempt", task)
+	}
+	if err := store.Append(ctx, Task{ID: "ok"}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Append() completed id error = %v, want ErrDuplicate", err)
+	}
+
+	store.Append(ctx, Task{ID: "after-crash", State: StatePending})
+	store.Close()
+	if store, err = OpenFileStore(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, "after-crash"); err != nil {
+		t.Errorf("record written after a torn record was lost: %v", err)
+	}
+
+	if err := store.Compact(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, "ok"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("completed task should be compacted, err = %v", err)
+	}
+	if task, _ := store.Get(ctx, "fail"); task.State != StatePending {
+		t.Errorf("pending task lost after Compact")
+	}
+
+	// Compact失败时原WAL继续可用, 成功后的写入落在新文件上
+	os.Mkdir(path+".tmp", 0755)
+	if err := store.Compact(0); err == nil {
+		t.Fatal("Compact() should fail when temp file cannot be created")
+	}
+	os.Remove(path + ".tmp")
+	if err := store.Append(ctx, Task{ID: "after-failed-compact", State: StatePending}); err != nil {
+		t.Fatalf("Append() after failed Compact: %v", err)
+	}
+	if err := store.Compact(0); err != nil {
+		t.Fatal(err)
+	}
+	store.Append(ctx, Task{ID: "after-compact", State: StatePending})
+	store.Close()
+	if store, err = OpenFileStore(path); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"fail", "after-failed-compact", "after-compact"} {
+		if _, err := store.Get(ctx, id); err != nil {
+			t.Errorf("%s lost after reopen: %v", id, err)
+		}
+	}
+}
+
+func TestOutboxStart(t *testing.T) {
+	done := make(chan string, 1)
+	ob := New(NewMemoryStore(), func(ctx context.Context, task Task) error {
+		done <- task.Type
+		return nil
+	}, Config{PollInterval: time.Hour})
+	ob.Start(context.Background())
+	defer ob.Stop()
+
+	ob.Add(context.Background(), "wakeup", nil)
+	select {
+	case typ := <-done:
+		if typ != "wakeup" {
+			t.Errorf("handled %s", typ)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("task not dispatched after Add")
+	}
+}
@@ -0,0 +1,147 @@
+package templatex
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ChangSZ/golib/conv"
+	"github.com/ChangSZ/golib/timeutil"
+)
+
+// FuncMap 内置的模板函数, 被处理的值都是最后一个参数, 便于在管道中使用:
+//
+//	{{ .CreatedAt | date "2006-01-02" }}
+//	{{ .Amount | money "¥" }}
+//	{{ .Count | number 0 }}
+//	{{ .Nickname | default "匿名用户" }}
+//	{{ .Tags | join ", " }}
+//	{{ .Content | truncate 20 }}
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"date":     Date,
+		"number":   Number,
+		"money":    Money,
+		"default":  Default,
+		"join":     Join,
+		"truncate": Truncate,
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+		"trim":     strings.TrimSpace,
+	}
+}
+
+// Date 按layout格式化时间, layout为空时使用timeutil.CSTLayout; v可以是time.Time、*time.Time或Unix秒
+func Date(layout string, v interface{}) (string, error) {
+	if layout == "" {
+		layout = timeutil.CSTLayout
+	}
+	switch t := v.(type) {
+	case time.Time:
+		return t.In(time.Local).Format(layout), nil
+	case *time.Time:
+		if t == nil {
+			return "", nil
+		}
+		return t.In(time.Local).Format(layout), nil
+	}
+	sec, err := conv.ToInt64(v)
+	if err != nil {
+		return "", fmt.Errorf("date: unsupported value %T", v)
+	}
+	return time.Unix(sec, 0).Format(layout), nil
+}
+
+// Number 保留decimals位小数并添加千分位分隔符, 如1234.5 => 1,234.50
+func Number(decimals int, v interface{}) (string, error) {
+	f, err := conv.ToFloat64(v)
+	if err != nil {
+		return "", fmt.Errorf("number: %w", err)
+	}
+	return groupThousands(strconv.FormatFloat(f, 'f', decimals, 64)), nil
+}
+
+// Money 保留两位小数并添加货币符号, 负数的符号在货币符号之前, 如-5 => -¥5.00
+func Money(symbol string, v interface{}) (string, error) {
+	s, err := Number(2, v)
+	if err != nil {
+		return "", fmt.Errorf("money: %w", err)
+	}
+	if strings.HasPrefix(s, "-") {
+		return "-" + symbol + s[1:], nil
+	}
+	return symbol + s, nil
+}
+
+func groupThousands(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, frac = s[:i], s[i:]
+	}
+	var b strings.Builder
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return sign + b.String() + frac
+}
+
+// Default v为零值(nil、空字符串、0、空切片等)时返回def
+func Default(def, v interface{}) interface{} {
+	if v == nil {
+		return def
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		if rv.Len() == 0 {
+			return def
+		}
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return def
+		}
+	default:
+		if rv.IsZero() {
+			return def
+		}
+	}
+	return v
+}
+
+// Join 以sep连接切片或数组的元素
+func Join(sep string, v interface{}) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	if ss, ok := v.([]string); ok {
+		return strings.Join(ss, sep), nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: unsupported value %T", v)
+	}
+	parts := make([]string, rv.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(rv.Index(i).Interface())
+	}
+	return strings.Join(parts, sep), nil
+}
+
+// Truncate 按字符截取前n个字符, 被截断时追加"..."
+func Truncate(n int, s string) string {
+	rs := []rune(s)
+	if n < 0 || len(rs) <= n {
+		return s
+	}
+	return string(rs[:n]) + "..."
+}
@@ -0,0 +1,133 @@
+package templatex
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/ChangSZ/golib/bufpool"
+)
+
+// Engine 带缓存的模板渲染器, 用于通知、邮件等文本的渲染, 可以并发使用
+type Engine struct {
+	mu      sync.RWMutex
+	cache   map[string]*template.Template
+	funcs   template.FuncMap
+	lenient bool
+}
+
+type Option func(*Engine)
+
+// WithFuncs 追加或覆盖模板函数
+func WithFuncs(funcs template.FuncMap) Option {
+	return func(e *Engine) {
+		for k, v := range funcs {
+			e.funcs[k] = v
+		}
+	}
+}
+
+// WithLenient 允许引用不存在的map key, 默认严格模式下会返回错误
+func WithLenient() Option {
+	return func(e *Engine) {
+		e.lenient = true
+	}
+}
+
+// New new an Engine.
+func New(opts ...Option) *Engine {
+	e := &Engine{
+		cache: make(map[string]*template.Template),
+		funcs: FuncMap(),
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	return e
+}
+
+func (e *Engine) parse(name, text string) (*template.Template, error) {
+	missingKey := "missingkey=error"
+	if e.lenient {
+		missingKey = "missingkey=zero"
+	}
+	return template.New(name).Funcs(e.funcs).Option(missingKey).Parse(text)
+}
+
+// Register 解析并缓存模板, 同名覆盖
+func (e *Engine) Register(name, text string) error {
+	tpl, err := e.parse(name, text)
+	if err != nil {
+		return fmt.Errorf("templatex: parse %s: %w", name, err)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cache[name] = tpl
+	return nil
+}
+
+// LoadDir 加载目录下匹配pattern(如"*.tmpl")的文件, 模板名为不含扩展名的文件名
+func (e *Engine) LoadDir(dir, pattern string) error {
+	files, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.Base(f), filepath.Ext(f))
+		if err := e.Register(name, string(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Has 模板是否已注册
+func (e *Engine) Has(name string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	_, ok := e.cache[name]
+	return ok
+}
+
+// Render 渲染已注册的模板, 出错时不会返回部分结果
+func (e *Engine) Render(name string, data interface{}) (string, error) {
+	e.mu.RLock()
+	tpl, ok := e.cache[name]
+	e.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("templatex: template %s not found", name)
+	}
+	return execute(tpl, data)
+}
+
+// RenderText 以name为缓存key渲染text, 首次调用时解析, 之后复用
+func (e *Engine) RenderText(name, text string, data interface{}) (string, error) {
+	if !e.Has(name) {
+		if err := e.Register(name, text); err != nil {
+			return "", err
+		}
+	}
+	return e.Render(name, data)
+}
+
+func execute(tpl *template.Template, data interface{}) (s string, err error) {
+	// 模板函数中的panic不应影响调用方
+	defer func() {
+		if r := recover(); r != nil {
+			s, err = "", fmt.Errorf("templatex: render %s panic: %v", tpl.Name(), r)
+		}
+	}()
+	buf := bufpool.Get(0)
+	defer bufpool.Put(buf)
+	if err := tpl.Execute(buf, data); err != nil {
+		return "", fmt.Errorf("templatex: render %s: %w", tpl.Name(), err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,61 @@
+package templatex
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestFuncs(t *testing.T) {
+	at := time.Date(2024, 3, 5, 14, 30, 0, 0, time.Local)
+	tests := []struct {
+		name string
+		text string
+		data interface{}
+		want string
+	}{
+		{name: "date", text: `{{ .At | date "2006-01-02" }}`, data: map[string]interface{}{"At": at}, want: "2024-03-05"},
+		{name: "date default layout", text: `{{ .At | date "" }}`, data: map[string]interface{}{"At": at}, want: "2024-03-05 14:30:00"},
+		{name: "number", text: `{{ .N | number 2 }}`, data: map[string]interface{}{"N": 1234567.891}, want: "1,234,567.89"},
+		{name: "number string", text: `{{ .N | number 0 }}`, data: map[string]interface{}{"N": "-1000"}, want: "-1,000"},
+		{name: "money", text: `{{ .N | money "¥" }}`, data: map[string]interface{}{"N": -5}, want: "-¥5.00"},
+		{name: "default empty", text: `{{ .Name | default "匿名" }}`, data: map[string]interface{}{"Name": ""}, want: "匿名"},
+		{name: "default set", text: `{{ .Name | default "匿名" }}`, data: map[string]interface{}{"Name": "jack"}, want: "jack"},
+		{name: "join", text: `{{ .Tags | join ", " }}`, data: map[string]interface{}{"Tags": []int{1, 2}}, want: "1, 2"},
+		{name: "truncate", text: `{{ .S | truncate 4 }}`, data: map[string]interface{}{"S": "你好世界啊"}, want: "你好世界..."},
+	}
+	e := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.RenderText(tt.name, tt.text, tt.data)
+			if err != nil || got != tt.want {
+				t.Errorf("RenderText() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrictAndSafe(t *testing.T) {
+	e := New(WithFuncs(template.FuncMap{"boom": func() string { panic("bad func") }}))
+	if err := e.Register("greet", "Hi {{.Name}}, {{.Missing}}"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Render("greet", map[string]string{"Name": "jack"}); err == nil {
+		t.Errorf("Render() strict mode should fail on missing key")
+	}
+
+	lenient := New(WithLenient())
+	got, err := lenient.RenderText("greet", "Hi {{.Name}}{{.Missing}}", map[string]string{"Name": "jack"})
+	if err != nil || got != "Hi jack" {
+		t.Errorf("lenient Render() = %q, %v", got, err)
+	}
+
+	_ = e.Register("panic", "{{ boom }}")
+	if _, err := e.Render("panic", nil); err == nil || !strings.Contains(err.Error(), "bad func") {
+		t.Errorf("Render() error = %v, want recovered panic", err)
+	}
+	if _, err := e.Render("unknown", nil); err == nil {
+		t.Errorf("Render() of unknown template should fail")
+	}
+}
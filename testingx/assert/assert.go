@@ -0,0 +1,90 @@
+package assert
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Equal 断言want与got深度相等, 不相等时按字段路径输出差异, 而非打印两个完整的%+v
+func Equal(t testing.TB, want, got interface{}, msgAndArgs ...interface{}) bool {
+	t.Helper()
+	if reflect.DeepEqual(want, got) {
+		return true
+	}
+	lines := diff(want, got)
+	t.Errorf("%sNot equal:\n%s", message(msgAndArgs), strings.Join(lines, "\n"))
+	return false
+}
+
+// NotEqual 断言want与got不相等
+func NotEqual(t testing.TB, want, got interface{}, msgAndArgs ...interface{}) bool {
+	t.Helper()
+	if !reflect.DeepEqual(want, got) {
+		return true
+	}
+	t.Errorf("%sShould not be equal: %#v", message(msgAndArgs), got)
+	return false
+}
+
+// NoError 断言err为nil
+func NoError(t testing.TB, err error, msgAndArgs ...interface{}) bool {
+	t.Helper()
+	if err == nil {
+		return true
+	}
+	t.Errorf("%sUnexpected error: %v", message(msgAndArgs), err)
+	return false
+}
+
+// ErrorIs 断言errors.Is(err, target)
+func ErrorIs(t testing.TB, err, target error, msgAndArgs ...interface{}) bool {
+	t.Helper()
+	if errors.Is(err, target) {
+		return true
+	}
+	t.Errorf("%sError chain does not contain target:\n  err:    %v\n  target: %v", message(msgAndArgs), err, target)
+	return false
+}
+
+// ErrorAs 断言errors.As(err, target), target必须是指向error实现类型的非nil指针
+func ErrorAs(t testing.TB, err error, target interface{}, msgAndArgs ...interface{}) bool {
+	t.Helper()
+	if errors.As(err, target) {
+		return true
+	}
+	t.Errorf("%sError chain does not contain %v:\n  err: %v",
+		message(msgAndArgs), reflect.TypeOf(target).Elem(), err)
+	return false
+}
+
+// Eventually 断言cond在timeout内返回true, 每10ms检查一次
+func Eventually(t testing.TB, cond func() bool, timeout time.Duration, msgAndArgs ...interface{}) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if cond() {
+			return true
+		}
+		if time.Now().After(deadline) {
+			t.Errorf("%sCondition never satisfied within %v", message(msgAndArgs), timeout)
+			return false
+		}
+		<-ticker.C
+	}
+}
+
+func message(msgAndArgs []interface{}) string {
+	if len(msgAndArgs) == 0 {
+		return ""
+	}
+	if format, ok := msgAndArgs[0].(string); ok {
+		return fmt.Sprintf(format, msgAndArgs[1:]...) + "\n"
+	}
+	return fmt.Sprint(msgAndArgs...) + "\n"
+}
@@ -0,0 +1,177 @@
+package assert
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recorder 记录断言失败信息而不让测试失败
+type recorder struct {
+	testing.TB
+	msgs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.msgs = append(r.msgs, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.msgs = append(r.msgs, fmt.Sprintf(format, args...))
+}
+
+type address struct {
+	City string
+	Tags []string
+}
+
+type user struct {
+	Name    string
+	Address *address
+	Extra   map[string]int
+}
+
+type node struct {
+	Val  int
+	Next *node
+}
+
+type counter struct {
+	hits map[string]int
+}
+
+func cycle(a, b int) *node {
+	n := &node{Val: a, Next: &node{Val: b}}
+	n.Next.Next = n
+	return n
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name      string
+		want      interface{}
+		got       interface{}
+		ok        bool
+		wantLines []string
+	}{
+		{
+			name: "equal",
+			want: user{Name: "a", Address: &address{City: "sh"}},
+			got:  user{Name: "a", Address: &address{City: "sh"}},
+			ok:   true,
+		},
+		{
+			name: "field_path",
+			want: user{Name: "a", Address: &address{City: "sh", Tags: []string{"x"}}, Extra: map[string]int{"k": 1}},
+			got:  user{Name: "b", Address: &address{City: "bj", Tags: []string{"x", "y"}}, Extra: map[string]int{"k": 2}},
+			wantLines: []string{
+				`.Name: want "a", got "b"`,
+				`.Address.City: want "sh", got "bj"`,
+				`.Address.Tags[1]: unexpected "y"`,
+				`.Extra["k"]: want 1, got 2`,
+			},
+		},
+		{
+			name:      "nil_pointer",
+			want:      user{Address: &address{}},
+			got:       user{},
+			wantLines: []string{".Address: want &assert.address"},
+		},
+		{
+			name:      "cycle",
+			want:      cycle(1, 2),
+			got:       cycle(1, 3),
+			wantLines: []string{".Next.Val: want 2, got 3"},
+		},
+		{
+			name:      "unexported_map",
+			want:      counter{hits: map[string]int{"a": 1}},
+			got:       counter{hits: map[string]int{"a": 2, "b": 1}},
+			wantLines: []string{`.hits["a"]: want 1, got 2`, `.hits["b"]: unexpected 1`},
+		},
+		{
+			name:      "type_mismatch",
+			want:      1,
+			got:       int64(1),
+			wantLines: []string{"(root): want 1, got 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			if got := Equal(r, tt.want, tt.got); got != tt.ok {
+				t.Fatalf("Equal() = %v, want %v", got, tt.ok)
+			}
+			out := strings.Join(r.msgs, "\n")
+			for _, line := range tt.wantLines {
+				if !strings.Contains(out, line) {
+					t.Errorf("output missing %q:\n%s", line, out)
+				}
+			}
+		})
+	}
+}
+
+type codeError struct{ code int }
+
+func (e *codeError) Error() string { return fmt.Sprintf("code %d", e.code) }
+
+func TestErrors(t *testing.T) {
+	err := fmt.Errorf("open config: %w", fs.ErrNotExist)
+	r := &recorder{TB: t}
+	if !ErrorIs(r, err, fs.ErrNotExist) || ErrorIs(r, err, os.ErrClosed) {
+		t.Errorf("ErrorIs() result mismatch")
+	}
+
+	var ce *codeError
+	if !ErrorAs(r, fmt.Errorf("wrap: %w", &codeError{code: 3}), &ce) || ce.code != 3 {
+		t.Errorf("ErrorAs() should match codeError")
+	}
+	if ErrorAs(r, errors.New("plain"), &ce) {
+		t.Errorf("ErrorAs() should not match")
+	}
+	if len(r.msgs) != 2 {
+		t.Errorf("failures = %d, want 2", len(r.msgs))
+	}
+}
+
+func TestEventually(t *testing.T) {
+	var n int32
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		atomic.StoreInt32(&n, 1)
+	}()
+	Eventually(t, func() bool { return atomic.LoadInt32(&n) == 1 }, time.Second)
+
+	r := &recorder{TB: t}
+	if Eventually(r, func() bool { return false }, 20*time.Millisecond) {
+		t.Errorf("Eventually() = true, want false")
+	}
+}
+
+func TestGolden(t *testing.T) {
+	old := GoldenDir
+	GoldenDir = t.TempDir()
+	defer func() { GoldenDir = old }()
+
+	*update = true
+	Golden(t, "report", []byte("line1\nline2\n"))
+	*update = false
+
+	Golden(t, "report", []byte("line1\nline2\n"))
+
+	r := &recorder{TB: t}
+	if Golden(r, "report", []byte("line1\nchanged\n")) {
+		t.Fatalf("Golden() = true, want false")
+	}
+	if !strings.Contains(r.msgs[0], "line 2:\n  - line2\n  + changed") {
+		t.Errorf("unexpected output: %s", r.msgs[0])
+	}
+}
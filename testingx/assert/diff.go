@@ -0,0 +1,187 @@
+package assert
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/ChangSZ/golib/copy"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// maxDiffLines 最多输出的差异行数
+const maxDiffLines = 30
+
+// diff 按字段路径列出want与got的差异, 每行形如 "  .Address.City: want "a", got "b""
+//
+// 结构体先用copy.Diff找出变化的字段, 切片、map等字段再展开到元素; copy.Diff不比较未导出字段,
+// 也不区分nil指针与零值结构体, 这时退化为直接比较两个值
+func diff(want, got interface{}) []string {
+	var lines []string
+	w := &walker{lines: &lines, visited: make(map[visit]bool)}
+	for _, c := range fieldChanges(want, got) {
+		w.walk("."+c.Path, reflect.ValueOf(c.Before), reflect.ValueOf(c.After))
+	}
+	if len(lines) == 0 {
+		w.walk("", reflect.ValueOf(want), reflect.ValueOf(got))
+	}
+	if len(lines) == 0 {
+		// DeepEqual不等但没有找到叶子差异, 比如NaN
+		lines = append(lines, fmt.Sprintf("  want: %#v\n  got:  %#v", want, got))
+	}
+	if len(lines) > maxDiffLines {
+		more := len(lines) - maxDiffLines
+		lines = append(lines[:maxDiffLines], fmt.Sprintf("  ... and %d more", more))
+	}
+	return lines
+}
+
+// fieldChanges want、got为同一结构体类型(或其指针)时, 返回copy.Diff找到的字段变化
+func fieldChanges(want, got interface{}) []copy.Change {
+	t := reflect.TypeOf(want)
+	if t == nil || t != reflect.TypeOf(got) {
+		return nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	changes, err := copy.Diff(want, got)
+	if err != nil {
+		return nil
+	}
+	return changes
+}
+
+// visit 正在比较的一对指针, 再次遇到说明存在环
+type visit struct {
+	want, got uintptr
+	typ       reflect.Type
+}
+
+type walker struct {
+	lines   *[]string
+	visited map[visit]bool
+}
+
+func (w *walker) walk(path string, want, got reflect.Value) {
+	lines := w.lines
+	if len(*lines) > maxDiffLines {
+		return
+	}
+	if !want.IsValid() || !got.IsValid() || want.Type() != got.Type() {
+		if want.IsValid() != got.IsValid() || (want.IsValid() && want.Type() != got.Type()) {
+			report(lines, path, want, got)
+		}
+		return
+	}
+
+	if want.Type() == timeType {
+		if !valueEqual(want, got) {
+			report(lines, path, want, got)
+		}
+		return
+	}
+
+	switch want.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if want.IsNil() || got.IsNil() {
+			if want.IsNil() != got.IsNil() {
+				report(lines, path, want, got)
+			}
+			return
+		}
+		if want.Kind() == reflect.Ptr {
+			// 自引用的结构体, 同一对指针只比较一次
+			v := visit{want: want.Pointer(), got: got.Pointer(), typ: want.Type()}
+			if w.visited[v] {
+				return
+			}
+			w.visited[v] = true
+		}
+		w.walk(path, want.Elem(), got.Elem())
+	case reflect.Struct:
+		for i := 0; i < want.NumField(); i++ {
+			w.walk(path+"."+want.Type().Field(i).Name, want.Field(i), got.Field(i))
+		}
+	case reflect.Slice, reflect.Array:
+		if want.Kind() == reflect.Slice && want.IsNil() != got.IsNil() {
+			report(lines, path, want, got)
+			return
+		}
+		n := want.Len()
+		if got.Len() > n {
+			n = got.Len()
+		}
+		for i := 0; i < n; i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= want.Len():
+				*lines = append(*lines, fmt.Sprintf("  %s: unexpected %s", p, format(got.Index(i))))
+			case i >= got.Len():
+				*lines = append(*lines, fmt.Sprintf("  %s: missing %s", p, format(want.Index(i))))
+			default:
+				w.walk(p, want.Index(i), got.Index(i))
+			}
+		}
+	case reflect.Map:
+		if want.IsNil() != got.IsNil() {
+			report(lines, path, want, got)
+			return
+		}
+		keys := append(want.MapKeys(), got.MapKeys()...)
+		sort.Slice(keys, func(i, j int) bool { return format(keys[i]) < format(keys[j]) })
+		// 经未导出字段取得的key不能Interface, 按格式化结果去重
+		seen := make(map[string]bool, len(keys))
+		for _, k := range keys {
+			key := format(k)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			p := fmt.Sprintf("%s[%s]", path, key)
+			wv, gv := want.MapIndex(k), got.MapIndex(k)
+			switch {
+			case !wv.IsValid():
+				*lines = append(*lines, fmt.Sprintf("  %s: unexpected %s", p, format(gv)))
+			case !gv.IsValid():
+				*lines = append(*lines, fmt.Sprintf("  %s: missing %s", p, format(wv)))
+			default:
+				w.walk(p, wv, gv)
+			}
+		}
+	default:
+		if !valueEqual(want, got) {
+			report(lines, path, want, got)
+		}
+	}
+}
+
+func report(lines *[]string, path string, want, got reflect.Value) {
+	if path == "" {
+		path = "(root)"
+	}
+	*lines = append(*lines, fmt.Sprintf("  %s: want %s, got %s", path, format(want), format(got)))
+}
+
+func valueEqual(a, b reflect.Value) bool {
+	if a.CanInterface() && b.CanInterface() {
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+	// 未导出字段无法Interface, 退化为格式化后比较
+	return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+}
+
+func format(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<nil>"
+	}
+	if v.CanInterface() {
+		return fmt.Sprintf("%#v", v.Interface())
+	}
+	return fmt.Sprintf("%#v", v)
+}
@@ -0,0 +1,63 @@
+package assert
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update 使用 go test -update 重新生成golden文件
+var update = flag.Bool("update", false, "update golden files")
+
+// GoldenDir golden文件所在目录, 相对于测试所在包
+var GoldenDir = "testdata"
+
+// Golden 将got与 testdata/<name>.golden 比较, 带 -update 参数运行测试时用got覆盖golden文件
+func Golden(t testing.TB, name string, got []byte) bool {
+	t.Helper()
+	path := filepath.Join(GoldenDir, name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("create golden dir: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("update golden file: %v", err)
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("read golden file %s: %v (run with -update to create it)", path, err)
+		return false
+	}
+	if bytes.Equal(want, got) {
+		return true
+	}
+	t.Errorf("Golden file %s mismatch:\n%s", path, lineDiff(string(want), string(got)))
+	return false
+}
+
+// lineDiff 输出第一处不同的行
+func lineDiff(want, got string) string {
+	wl := strings.Split(want, "\n")
+	gl := strings.Split(got, "\n")
+	for i := 0; i < len(wl) || i < len(gl); i++ {
+		if i >= len(wl) || i >= len(gl) || wl[i] != gl[i] {
+			var w, g string
+			if i < len(wl) {
+				w = wl[i]
+			}
+			if i < len(gl) {
+				g = gl[i]
+			}
+			return fmt.Sprintf("  line %d:\n  - %s\n  + %s", i+1, w, g)
+		}
+	}
+	return ""
+}
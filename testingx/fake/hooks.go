@@ -0,0 +1,129 @@
+package fake
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Call 一次方法调用记录
+type Call struct {
+	Method string
+	Args   []interface{}
+	Time   time.Time
+}
+
+// Hooks 内存替身共用的观测与注入能力: 记录调用、注入错误、注入延迟
+//
+// 替身在每个方法开头调用Before, 零值可直接使用, 可以并发使用
+type Hooks struct {
+	mu       sync.Mutex
+	calls    []Call
+	failNext map[string][]error
+	failAll  map[string]error
+	latency  map[string]time.Duration
+}
+
+// Any 作为method参数时匹配所有方法
+const Any = "*"
+
+// FailNext 下一次调用method时返回err, 多次调用按顺序依次生效
+func (h *Hooks) FailNext(method string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.failNext == nil {
+		h.failNext = make(map[string][]error)
+	}
+	h.failNext[method] = append(h.failNext[method], err)
+}
+
+// FailAlways 之后每次调用method都返回err, err为nil时取消
+func (h *Hooks) FailAlways(method string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.failAll == nil {
+		h.failAll = make(map[string]error)
+	}
+	if err == nil {
+		delete(h.failAll, method)
+		return
+	}
+	h.failAll[method] = err
+}
+
+// SetLatency 调用method时先等待d, ctx结束时提前返回ctx.Err()
+func (h *Hooks) SetLatency(method string, d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.latency == nil {
+		h.latency = make(map[string]time.Duration)
+	}
+	h.latency[method] = d
+}
+
+// Calls 返回method的调用记录, method为Any时返回全部
+func (h *Hooks) Calls(method string) []Call {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var out []Call
+	for _, c := range h.calls {
+		if method == Any || c.Method == method {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// CallCount 返回method的调用次数
+func (h *Hooks) CallCount(method string) int {
+	return len(h.Calls(method))
+}
+
+// ResetHooks 清空调用记录与注入的错误、延迟
+func (h *Hooks) ResetHooks() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls = nil
+	h.failNext = nil
+	h.failAll = nil
+	h.latency = nil
+}
+
+// Before 记录调用并执行注入的延迟和错误, 由替身实现在方法开头调用
+func (h *Hooks) Before(ctx context.Context, method string, args ...interface{}) error {
+	h.mu.Lock()
+	h.calls = append(h.calls, Call{Method: method, Args: args, Time: time.Now()})
+	delay, ok := h.latency[method]
+	if !ok {
+		delay = h.latency[Any]
+	}
+	err := h.popError(method)
+	h.mu.Unlock()
+
+	if delay > 0 {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return err
+}
+
+func (h *Hooks) popError(method string) error {
+	for _, m := range []string{method, Any} {
+		if errs := h.failNext[m]; len(errs) > 0 {
+			h.failNext[m] = errs[1:]
+			return errs[0]
+		}
+	}
+	if err, ok := h.failAll[method]; ok {
+		return err
+	}
+	return h.failAll[Any]
+}
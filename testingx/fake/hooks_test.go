@@ -0,0 +1,91 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHooks(t *testing.T) {
+	errBoom := errors.New("boom")
+	errDown := errors.New("down")
+
+	tests := []struct {
+		name  string
+		setup func(h *Hooks)
+		calls []string
+		want  []error
+	}{
+		{
+			name:  "no injection",
+			setup: func(h *Hooks) {},
+			calls: []string{"Get", "Set"},
+			want:  []error{nil, nil},
+		},
+		{
+			name:  "fail next once",
+			setup: func(h *Hooks) { h.FailNext("Get", errBoom) },
+			calls: []string{"Get", "Get"},
+			want:  []error{errBoom, nil},
+		},
+		{
+			name: "fail next before fail always",
+			setup: func(h *Hooks) {
+				h.FailAlways("Get", errDown)
+				h.FailNext("Get", errBoom)
+			},
+			calls: []string{"Get", "Get", "Set"},
+			want:  []error{errBoom, errDown, nil},
+		},
+		{
+			name:  "any method",
+			setup: func(h *Hooks) { h.FailAlways(Any, errDown) },
+			calls: []string{"Get", "Set"},
+			want:  []error{errDown, errDown},
+		},
+		{
+			name: "fail always cleared",
+			setup: func(h *Hooks) {
+				h.FailAlways("Get", errDown)
+				h.FailAlways("Get", nil)
+			},
+			calls: []string{"Get"},
+			want:  []error{nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h Hooks
+			tt.setup(&h)
+			for i, m := range tt.calls {
+				if err := h.Before(context.Background(), m, i); err != tt.want[i] {
+					t.Errorf("call %d %s error = %v, want %v", i, m, err, tt.want[i])
+				}
+			}
+			if got := h.CallCount(Any); got != len(tt.calls) {
+				t.Errorf("CallCount() = %d, want %d", got, len(tt.calls))
+			}
+		})
+	}
+}
+
+func TestHooksLatency(t *testing.T) {
+	var h Hooks
+	h.SetLatency("Get", time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := h.Before(ctx, "Get"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Before() error = %v, want deadline exceeded", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("latency did not honor context")
+	}
+
+	h.ResetHooks()
+	if err := h.Before(ctx, "Get"); err != nil || h.CallCount("Get") != 1 {
+		t.Errorf("after ResetHooks error = %v, calls = %d", err, h.CallCount("Get"))
+	}
+}
@@ -0,0 +1,109 @@
+package testingx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ChangSZ/golib/decode"
+)
+
+// TempDirWithFiles 创建临时目录并写入文件, key为相对路径(可包含子目录), value为文件内容, 测试结束后自动删除
+func TempDirWithFiles(t testing.TB, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("testingx: mkdir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("testingx: write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+// FreePort 获取一个当前可用的TCP端口
+//
+// 端口在返回后即被释放, 极端情况下可能被其他进程抢占, 能直接监听 ":0" 时优先监听 ":0"
+func FreePort() (int, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port, nil
+}
+
+// MustFreePort 同FreePort, 失败时终止测试
+func MustFreePort(t testing.TB) int {
+	t.Helper()
+	port, err := FreePort()
+	if err != nil {
+		t.Fatalf("testingx: get free port: %v", err)
+	}
+	return port
+}
+
+// SetEnv 设置环境变量, 测试结束后恢复原值(原来不存在则删除)
+func SetEnv(t testing.TB, key, value string) {
+	t.Helper()
+	old, existed := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatalf("testingx: setenv %s: %v", key, err)
+	}
+	t.Cleanup(func() {
+		if existed {
+			_ = os.Setenv(key, old)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
+
+// LoadFixture 读取YAML/JSON测试数据(按扩展名区分)并解码到out中
+//
+// 解码使用decode包, 默认开启弱类型转换, 并支持 "1h30m" => time.Duration、RFC3339字符串 => time.Time
+func LoadFixture(t testing.TB, path string, out interface{}, opts ...decode.Option) {
+	t.Helper()
+	if err := loadFixture(path, out, opts...); err != nil {
+		t.Fatalf("testingx: load fixture %s: %v", path, err)
+	}
+}
+
+func loadFixture(path string, out interface{}, opts ...decode.Option) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var data interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(raw, &data)
+	case ".json":
+		err = json.Unmarshal(raw, &data)
+	default:
+		return fmt.Errorf("unsupported fixture format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return err
+	}
+
+	opts = append([]decode.Option{
+		decode.WithWeaklyTyped(),
+		decode.WithHooks(
+			decode.StringToDurationHook(),
+			decode.StringToTimeHook(time.RFC3339),
+		),
+	}, opts...)
+	return decode.Decode(data, out, opts...)
+}
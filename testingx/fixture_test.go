@@ -0,0 +1,79 @@
+package testingx
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTempDirWithFiles(t *testing.T) {
+	dir := TempDirWithFiles(t, map[string]string{
+		"a.txt":          "hello",
+		"conf/app.yaml":  "name: demo",
+		"conf/empty.txt": "",
+	})
+	b, err := os.ReadFile(filepath.Join(dir, "conf", "app.yaml"))
+	if err != nil || string(b) != "name: demo" {
+		t.Errorf("ReadFile() = %s, %v", b, err)
+	}
+}
+
+func TestFreePort(t *testing.T) {
+	port := MustFreePort(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(port))
+	if err != nil {
+		t.Fatalf("port %d not free: %v", port, err)
+	}
+	_ = ln.Close()
+}
+
+func TestSetEnv(t *testing.T) {
+	const key = "GOLIB_TESTINGX_ENV"
+	t.Run("scoped", func(t *testing.T) {
+		SetEnv(t, key, "1")
+		if os.Getenv(key) != "1" {
+			t.Errorf("Getenv() = %q, want 1", os.Getenv(key))
+		}
+	})
+	if _, ok := os.LookupEnv(key); ok {
+		t.Errorf("env %s should be unset after subtest", key)
+	}
+}
+
+type fixtureUser struct {
+	Name     string        `json:"name"`
+	Age      int           `json:"age"`
+	Timeout  time.Duration `json:"timeout"`
+	Birthday time.Time     `json:"birthday"`
+	Roles    []string      `json:"roles"`
+}
+
+func TestLoadFixture(t *testing.T) {
+	dir := TempDirWithFiles(t, map[string]string{
+		"user.yaml": "name: jack\nage: \"18\"\ntimeout: 1m\nbirthday: \"2000-01-02T03:04:05Z\"\nroles: [admin, ops]\n",
+		"user.json": `{"name":"rose","age":20,"timeout":"2s","roles":["dev"]}`,
+	})
+
+	var u fixtureUser
+	LoadFixture(t, filepath.Join(dir, "user.yaml"), &u)
+	want := fixtureUser{
+		Name:     "jack",
+		Age:      18,
+		Timeout:  time.Minute,
+		Birthday: time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC),
+		Roles:    []string{"admin", "ops"},
+	}
+	if u.Name != want.Name || u.Age != want.Age || u.Timeout != want.Timeout ||
+		!u.Birthday.Equal(want.Birthday) || len(u.Roles) != 2 {
+		t.Errorf("LoadFixture() = %+v, want %+v", u, want)
+	}
+
+	var j fixtureUser
+	LoadFixture(t, filepath.Join(dir, "user.json"), &j)
+	if j.Name != "rose" || j.Age != 20 || j.Timeout != 2*time.Second || j.Roles[0] != "dev" {
+		t.Errorf("LoadFixture() = %+v", j)
+	}
+}
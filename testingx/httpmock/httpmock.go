@@ -0,0 +1,252 @@
+package httpmock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Response 预设的响应
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	Delay  time.Duration // 返回前等待的时间, 用于模拟慢接口和超时
+}
+
+// Request 录制的请求
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   []byte
+}
+
+// Route 一条路由规则, 多次Reply按顺序返回, 用完后重复最后一个
+type Route struct {
+	method    string
+	path      string
+	responses []Response
+	calls     int
+}
+
+// Reply 追加一个响应
+func (r *Route) Reply(status int, body string) *Route {
+	r.responses = append(r.responses, Response{Status: status, Body: []byte(body)})
+	return r
+}
+
+// ReplyJSON 追加一个JSON响应
+func (r *Route) ReplyJSON(status int, v interface{}) *Route {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("httpmock: marshal reply: %v", err))
+	}
+	r.responses = append(r.responses, Response{
+		Status: status,
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body:   body,
+	})
+	return r
+}
+
+// ReplyWith 追加一个完整定义的响应
+func (r *Route) ReplyWith(resp Response) *Route {
+	r.responses = append(r.responses, resp)
+	return r
+}
+
+// WithDelay 为最近一次Reply设置延迟
+func (r *Route) WithDelay(d time.Duration) *Route {
+	if len(r.responses) == 0 {
+		r.responses = append(r.responses, Response{Status: http.StatusOK})
+	}
+	r.responses[len(r.responses)-1].Delay = d
+	return r
+}
+
+// WithHeader 为最近一次Reply设置响应头
+func (r *Route) WithHeader(key, value string) *Route {
+	if len(r.responses) == 0 {
+		r.responses = append(r.responses, Response{Status: http.StatusOK})
+	}
+	last := &r.responses[len(r.responses)-1]
+	if last.Header == nil {
+		last.Header = http.Header{}
+	}
+	last.Header.Set(key, value)
+	return r
+}
+
+func (r *Route) match(method, path string) bool {
+	if r.method != "" && r.method != "*" && !strings.EqualFold(r.method, method) {
+		return false
+	}
+	if strings.HasSuffix(r.path, "*") {
+		return strings.HasPrefix(path, strings.TrimSuffix(r.path, "*"))
+	}
+	return r.path == path
+}
+
+// next 返回本次调用的响应
+func (r *Route) next() Response {
+	i := r.calls
+	r.calls++
+	if len(r.responses) == 0 {
+		return Response{Status: http.StatusOK}
+	}
+	if i >= len(r.responses) {
+		i = len(r.responses) - 1
+	}
+	return r.responses[i]
+}
+
+// Mock 声明式的HTTP替身, 可以作为真实的测试服务器, 也可以作为http.RoundTripper直接注入客户端
+//
+//	m := httpmock.New()
+//	m.On("GET", "/users/1").Reply(503, "busy").ReplyJSON(200, user)
+//	client := m.Client() // 或 srv := m.Server(t)
+type Mock struct {
+	mu       sync.Mutex
+	routes   []*Route
+	requests []Request
+}
+
+// New new a Mock.
+func New() *Mock {
+	return &Mock{}
+}
+
+// On 注册路由, method为空或"*"匹配任意方法, path以"*"结尾时按前缀匹配; 先注册的优先
+func (m *Mock) On(method, path string) *Route {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := &Route{method: method, path: path}
+	m.routes = append(m.routes, r)
+	return r
+}
+
+// ServeHTTP 实现http.Handler, 未匹配的请求返回404
+func (m *Mock) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	m.mu.Lock()
+	m.requests = append(m.requests, Request{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.RawQuery,
+		Header: req.Header.Clone(),
+		Body:   body,
+	})
+	var resp *Response
+	for _, r := range m.routes {
+		if r.match(req.Method, req.URL.Path) {
+			next := r.next()
+			resp = &next
+			break
+		}
+	}
+	m.mu.Unlock()
+
+	if resp == nil {
+		http.Error(w, fmt.Sprintf("httpmock: no route for %s %s", req.Method, req.URL.Path), http.StatusNotFound)
+		return
+	}
+
+	if resp.Delay > 0 {
+		timer := time.NewTimer(resp.Delay)
+		defer timer.Stop()
+		select {
+		case <-req.Context().Done():
+			return
+		case <-timer.C:
+		}
+	}
+	for k, vs := range resp.Header {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(resp.Body)
+}
+
+// Server 启动真实的测试服务器, 测试结束后自动关闭
+func (m *Mock) Server(t testing.TB) *httptest.Server {
+	srv := httptest.NewServer(m)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// Transport 返回不经过网络的RoundTripper, 请求直接交给Mock处理
+func (m *Mock) Transport() http.RoundTripper {
+	return roundTripper{m: m}
+}
+
+// Client 返回使用Transport的http.Client
+func (m *Mock) Client() *http.Client {
+	return &http.Client{Transport: m.Transport()}
+}
+
+// Requests 已收到的请求
+func (m *Mock) Requests() []Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Request, len(m.requests))
+	copy(out, m.requests)
+	return out
+}
+
+// Calls 匹配某条路由的请求次数
+func (m *Mock) Calls(method, path string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, req := range m.requests {
+		if strings.EqualFold(req.Method, method) && req.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset 清空路由与录制的请求
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.routes = nil
+	m.requests = nil
+}
+
+type roundTripper struct {
+	m *Mock
+}
+
+func (rt roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil {
+		req.Body = http.NoBody
+	}
+	rec := httptest.NewRecorder()
+	rt.m.ServeHTTP(rec, req)
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	resp := rec.Result()
+	resp.Request = req
+	// 让响应体可以被多次关闭且不持有recorder
+	resp.Body = io.NopCloser(bytes.NewReader(rec.Body.Bytes()))
+	return resp, nil
+}
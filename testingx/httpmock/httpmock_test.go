@@ -0,0 +1,102 @@
+package httpmock
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, client *http.Client, url string) (int, string) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestStatusSequence(t *testing.T) {
+	m := New()
+	m.On("GET", "/users/1").
+		Reply(http.StatusServiceUnavailable, "busy").
+		ReplyJSON(http.StatusOK, map[string]string{"name": "jack"}).WithHeader("X-Trace", "1")
+
+	tests := []struct {
+		name       string
+		client     func() (*http.Client, string)
+		wantStatus []int
+	}{
+		{
+			name:       "transport",
+			client:     func() (*http.Client, string) { return m.Client(), "http://api.local" },
+			wantStatus: []int{503, 200, 200},
+		},
+		{
+			name: "server",
+			client: func() (*http.Client, string) {
+				return http.DefaultClient, m.Server(t).URL
+			},
+			wantStatus: []int{200},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, base := tt.client()
+			for i, want := range tt.wantStatus {
+				status, body := get(t, client, base+"/users/1")
+				if status != want {
+					t.Errorf("call %d status = %d, want %d", i, status, want)
+				}
+				if status == http.StatusOK && body != `{"name":"jack"}` {
+					t.Errorf("call %d body = %s", i, body)
+				}
+			}
+		})
+	}
+
+	if got := m.Calls("GET", "/users/1"); got != 4 {
+		t.Errorf("Calls() = %d, want 4", got)
+	}
+}
+
+func TestUnmatchedAndRecording(t *testing.T) {
+	m := New()
+	m.On("POST", "/hooks/*").Reply(http.StatusAccepted, "")
+
+	resp, err := m.Client().Post("http://api.local/hooks/order?id=1", "application/json", strings.NewReader(`{"a":1}`))
+	if err != nil || resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("Post() = %v, %v", resp, err)
+	}
+	if status, _ := get(t, m.Client(), "http://api.local/unknown"); status != http.StatusNotFound {
+		t.Errorf("unmatched status = %d, want 404", status)
+	}
+
+	reqs := m.Requests()
+	if len(reqs) != 2 || reqs[0].Query != "id=1" || string(reqs[0].Body) != `{"a":1}` ||
+		reqs[0].Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Requests() = %+v", reqs)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	m := New()
+	m.On("GET", "/slow").Reply(http.StatusOK, "ok").WithDelay(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://api.local/slow", nil)
+	start := time.Now()
+	_, err := m.Client().Do(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do() error = %v, want deadline exceeded", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("delay did not honor request context")
+	}
+}
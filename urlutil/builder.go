@@ -0,0 +1,193 @@
+package urlutil
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/ChangSZ/golib/conv"
+)
+
+// Builder 链式构造URL, 出错时记录第一个错误并在Build时返回
+//
+//	u, err := urlutil.NewBuilder("https://api.example.com/v1/").
+//		Path("users", userID, "orders").
+//		Set("page", "1").
+//		SetStruct(filter).
+//		Build()
+type Builder struct {
+	u     *url.URL
+	query url.Values
+	err   error
+}
+
+// NewBuilder 以base为基础构造URL
+func NewBuilder(base string) *Builder {
+	u, err := url.Parse(base)
+	if err != nil {
+		return &Builder{u: &url.URL{}, query: url.Values{}, err: err}
+	}
+	return &Builder{u: u, query: u.Query()}
+}
+
+// Path 在已有路径后追加路径段, 段中的"/"视为分隔符, 多余的斜杠会被去掉, 其它特殊字符会被转义
+func (b *Builder) Path(segments ...string) *Builder {
+	var parts []string
+	for _, seg := range segments {
+		for _, p := range strings.Split(seg, "/") {
+			if p != "" {
+				parts = append(parts, p)
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return b
+	}
+
+	base := strings.TrimRight(b.u.EscapedPath(), "/")
+	escaped := make([]string, len(parts))
+	for i, p := range parts {
+		escaped[i] = url.PathEscape(p)
+	}
+	raw := base + "/" + strings.Join(escaped, "/")
+	path, err := url.PathUnescape(raw)
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	b.u.Path, b.u.RawPath = path, raw
+	return b
+}
+
+// Add 追加查询参数
+func (b *Builder) Add(key, value string) *Builder {
+	b.query.Add(key, value)
+	return b
+}
+
+// Set 设置查询参数, 覆盖已有的值
+func (b *Builder) Set(key, value string) *Builder {
+	b.query.Set(key, value)
+	return b
+}
+
+// Del 删除查询参数
+func (b *Builder) Del(key string) *Builder {
+	b.query.Del(key)
+	return b
+}
+
+// SetMap 按map设置查询参数, 值使用conv.ToString转换, 切片展开为多个值
+func (b *Builder) SetMap(m map[string]interface{}) *Builder {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch vs := m[k].(type) {
+		case []string:
+			b.query[k] = append([]string(nil), vs...)
+		default:
+			s, err := conv.ToString(vs)
+			if err != nil {
+				b.setErr(fmt.Errorf("urlutil: query %s: %w", k, err))
+				continue
+			}
+			b.query.Set(k, s)
+		}
+	}
+	return b
+}
+
+// AddValues 追加url.Values中的所有参数
+func (b *Builder) AddValues(values url.Values) *Builder {
+	for k, vs := range values {
+		for _, v := range vs {
+			b.query.Add(k, v)
+		}
+	}
+	return b
+}
+
+// SetStruct 按结构体设置查询参数, 规则参见EncodeValues
+func (b *Builder) SetStruct(v interface{}) *Builder {
+	values, err := EncodeValues(v)
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	for k, vs := range values {
+		b.query[k] = vs
+	}
+	return b
+}
+
+// Fragment 设置锚点
+func (b *Builder) Fragment(f string) *Builder {
+	b.u.Fragment = f
+	return b
+}
+
+func (b *Builder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Build 返回最终的URL
+func (b *Builder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	u := *b.u
+	u.RawQuery = b.query.Encode()
+	return u.String(), nil
+}
+
+// String 返回最终的URL, 出错时返回空字符串
+func (b *Builder) String() string {
+	s, _ := b.Build()
+	return s
+}
+
+// JoinPath 在base后追加路径段, 参见Builder.Path
+func JoinPath(base string, segments ...string) (string, error) {
+	return NewBuilder(base).Path(segments...).Build()
+}
+
+// SetQueryParam 设置rawURL中的单个查询参数, 其它部分保持不变
+func SetQueryParam(rawURL, key, value string) (string, error) {
+	return modifyQuery(rawURL, func(q url.Values) { q.Set(key, value) })
+}
+
+// AddQueryParam 向rawURL追加单个查询参数
+func AddQueryParam(rawURL, key, value string) (string, error) {
+	return modifyQuery(rawURL, func(q url.Values) { q.Add(key, value) })
+}
+
+// DelQueryParam 删除rawURL中的单个查询参数
+func DelQueryParam(rawURL, key string) (string, error) {
+	return modifyQuery(rawURL, func(q url.Values) { q.Del(key) })
+}
+
+// GetQueryParam 读取rawURL中的查询参数, 无法解析时返回空字符串
+func GetQueryParam(rawURL, key string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get(key)
+}
+
+func modifyQuery(rawURL string, fn func(url.Values)) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	fn(q)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
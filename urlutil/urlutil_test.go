@@ -0,0 +1,115 @@
+package urlutil
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type pageQuery struct {
+	Page  int `url:"page"`
+	Size  int `url:"size,omitempty"`
+	Order string
+}
+
+type listQuery struct {
+	pageQuery
+	Keyword string        `url:"q,omitempty"`
+	Tags    []string      `url:"tag"`
+	Since   time.Time     `url:"since,omitempty"`
+	Timeout time.Duration `url:"timeout,omitempty"`
+	Secret  string        `url:"-"`
+}
+
+func TestBuilder(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func() *Builder
+		want  string
+	}{
+		{
+			name:  "path join",
+			build: func() *Builder { return NewBuilder("https://api.local/v1/").Path("/users/", "a b", "orders/") },
+			want:  "https://api.local/v1/users/a%20b/orders",
+		},
+		{
+			name: "keep existing query",
+			build: func() *Builder {
+				return NewBuilder("https://api.local/search?lang=zh").Set("q", "go & rust").Add("tag", "a").Add("tag", "b")
+			},
+			want: "https://api.local/search?lang=zh&q=go+%26+rust&tag=a&tag=b",
+		},
+		{
+			name: "struct and map",
+			build: func() *Builder {
+				return NewBuilder("http://h/list").
+					SetStruct(listQuery{pageQuery: pageQuery{Page: 2}, Tags: []string{"x", "y"}, Secret: "s"}).
+					SetMap(map[string]interface{}{"debug": true, "ids": []string{"1", "2"}})
+			},
+			want: "http://h/list?Order=&debug=true&ids=1&ids=2&page=2&tag=x&tag=y",
+		},
+		{
+			name:  "invalid base",
+			build: func() *Builder { return NewBuilder("http://[::1").Set("a", "b") },
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.build().String(); got != tt.want {
+				t.Errorf("Build() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryParam(t *testing.T) {
+	raw := "https://h/p?a=1&b=2#top"
+	got, err := SetQueryParam(raw, "a", "9")
+	if err != nil || got != "https://h/p?a=9&b=2#top" {
+		t.Errorf("SetQueryParam() = %s, %v", got, err)
+	}
+	if got, _ := DelQueryParam(raw, "b"); got != "https://h/p?a=1#top" {
+		t.Errorf("DelQueryParam() = %s", got)
+	}
+	if got := GetQueryParam(raw, "b"); got != "2" {
+		t.Errorf("GetQueryParam() = %s", got)
+	}
+}
+
+func TestValuesRoundTrip(t *testing.T) {
+	since := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	in := listQuery{
+		pageQuery: pageQuery{Page: 3, Size: 20, Order: "desc"},
+		Keyword:   "golib",
+		Tags:      []string{"a", "b"},
+		Since:     since,
+		Timeout:   90 * time.Second,
+		Secret:    "hidden",
+	}
+	values, err := EncodeValues(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := url.Values{
+		"page": {"3"}, "size": {"20"}, "Order": {"desc"}, "q": {"golib"},
+		"tag": {"a", "b"}, "since": {"2024-05-01T08:00:00Z"}, "timeout": {"1m30s"},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("EncodeValues() = %v, want %v", values, want)
+	}
+
+	var out listQuery
+	if err := DecodeValues(values, &out); err != nil {
+		t.Fatalf("DecodeValues() error = %v", err)
+	}
+	in.Secret = ""
+	if !out.Since.Equal(in.Since) {
+		t.Errorf("Since = %v, want %v", out.Since, in.Since)
+	}
+	out.Since = in.Since
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("DecodeValues() = %+v, want %+v", out, in)
+	}
+}
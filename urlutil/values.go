@@ -0,0 +1,137 @@
+package urlutil
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/ChangSZ/golib/conv"
+	"github.com/ChangSZ/golib/decode"
+	"github.com/ChangSZ/golib/tagparse"
+)
+
+// TagName 结构体与url.Values互转时读取的struct tag
+const TagName = "url"
+
+// EncodeValues 将结构体转换为url.Values
+//
+// 字段名取自`url:"name"`, 未设置时使用字段名; 支持`url:"-"`跳过、`url:",omitempty"`忽略零值,
+// 切片展开为同名的多个值, time.Time格式化为RFC3339, 内嵌结构体展开
+func EncodeValues(v interface{}) (url.Values, error) {
+	values := url.Values{}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if !rv.IsValid() {
+		return values, nil
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("urlutil: expected a struct, got %T", v)
+	}
+	if err := encodeStruct(values, rv); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func encodeStruct(values url.Values, rv reflect.Value) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := tagparse.Get(field, TagName)
+		if tag.Skip || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+		name, omitempty := tag.Name, tag.Has("omitempty")
+
+		fv := rv.Field(i)
+		if field.Anonymous && name == "" {
+			fv = reflect.Indirect(fv)
+			if fv.Kind() == reflect.Struct {
+				if err := encodeStruct(values, fv); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if omitempty && fv.IsZero() {
+			continue
+		}
+		for fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				break
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Ptr {
+			continue
+		}
+
+		if (fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array) && fv.Type().Elem().Kind() != reflect.Uint8 {
+			for j := 0; j < fv.Len(); j++ {
+				s, err := format(fv.Index(j).Interface())
+				if err != nil {
+					return fmt.Errorf("urlutil: field %s: %w", field.Name, err)
+				}
+				values.Add(name, s)
+			}
+			continue
+		}
+		s, err := format(fv.Interface())
+		if err != nil {
+			return fmt.Errorf("urlutil: field %s: %w", field.Name, err)
+		}
+		values.Set(name, s)
+	}
+	return nil
+}
+
+func format(v interface{}) (string, error) {
+	switch val := v.(type) {
+	case time.Time:
+		return val.Format(time.RFC3339), nil
+	case time.Duration:
+		return val.String(), nil
+	case fmt.Stringer:
+		return val.String(), nil
+	}
+	return conv.ToString(v)
+}
+
+// DecodeValues 将url.Values解码到out指向的结构体, tag规则与EncodeValues一致
+//
+// 非切片字段取第一个值, 字符串按弱类型转换为数字、布尔、time.Time(RFC3339)和time.Duration
+func DecodeValues(values url.Values, out interface{}) error {
+	m := make(map[string]interface{}, len(values))
+	for k, vs := range values {
+		m[k] = vs
+	}
+	return decode.Decode(m, out,
+		decode.WithTagName(TagName),
+		decode.WithWeaklyTyped(),
+		decode.WithHooks(firstValueHook, decode.StringToTimeHook(time.RFC3339), decode.StringToDurationHook()),
+	)
+}
+
+// firstValueHook 目标不是切片时只取第一个值
+func firstValueHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	vs, ok := data.([]string)
+	if !ok {
+		return data, nil
+	}
+	for to.Kind() == reflect.Ptr {
+		to = to.Elem()
+	}
+	if to.Kind() == reflect.Slice || to.Kind() == reflect.Array {
+		return data, nil
+	}
+	if len(vs) == 0 {
+		return nil, nil
+	}
+	return vs[0], nil
+}
@@ -4,11 +4,17 @@ import (
 	"reflect"
 
 	"github.com/go-playground/validator/v10"
+
+	"github.com/ChangSZ/golib/tagparse"
 )
 
 // RegisterTagName 调整报错信息中的字段提示
 func RegisterTagName(v *validator.Validate, name string) {
 	v.RegisterTagNameFunc(func(field reflect.StructField) string {
-		return field.Tag.Get(name)
+		tag := tagparse.Get(field, name)
+		if tag.Skip {
+			return ""
+		}
+		return tag.Name
 	})
 }
@@ -3,6 +3,7 @@ package validator
 import (
 	"fmt"
 	"log"
+	"sync"
 
 	"github.com/go-playground/locales/en"
 	"github.com/go-playground/locales/zh"
@@ -14,25 +15,36 @@ import (
 
 var trans ut.Translator
 
+var (
+	// translators 已初始化的各语言翻译器, 供按请求语言翻译错误信息; TransInit可能与请求并发调用, 读写需持有translatorsMu
+	translators   = map[string]ut.Translator{}
+	translatorsMu sync.RWMutex
+)
+
 func TransInit(v *validator.Validate, local string) (err error) {
 	zhT := zh.New()
 	enT := en.New()
-	uni := ut.New(enT, zhT)
+	uni := ut.New(enT, enT, zhT)
 	tran, ok := uni.GetTranslator(local)
+	translatorsMu.Lock()
 	trans = tran
+	if ok {
+		translators[local] = tran
+	}
+	translatorsMu.Unlock()
 	if !ok {
 		return fmt.Errorf("uni.GetTranslator(%s) failed", local)
 	}
 	switch local {
 	case "en":
-		err = enTranslations.RegisterDefaultTranslations(v, trans)
+		err = enTranslations.RegisterDefaultTranslations(v, tran)
 	case "zh":
-		if err = zhTranslations.RegisterDefaultTranslations(v, trans); err != nil {
+		if err = zhTranslations.RegisterDefaultTranslations(v, tran); err != nil {
 			return
 		}
-		err = extendZhTrans(v, trans)
+		err = extendZhTrans(v, tran)
 	default:
-		err = enTranslations.RegisterDefaultTranslations(v, trans)
+		err = enTranslations.RegisterDefaultTranslations(v, tran)
 	}
 	return
 }
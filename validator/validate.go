@@ -1,14 +1,22 @@
 package validator
 
 import (
+	"context"
 	"errors"
+	"strings"
 
+	"github.com/ChangSZ/golib/i18n"
 	"github.com/gin-gonic/gin/binding"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 )
 
 func init() {
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		// 先注册en, 最后注册的zh作为默认语言
+		if err := TransInit(v, "en"); err != nil {
+			return
+		}
 		if err := TransInit(v, "zh"); err != nil {
 			return
 		}
@@ -44,3 +52,40 @@ func GetValidationErrors(err error) []error {
 		return []error{err}
 	}
 }
+
+// translatorFor 按ctx中的语言区域选择翻译器, 未初始化该语言时使用默认翻译器
+func translatorFor(ctx context.Context) ut.Translator {
+	locale := strings.ToLower(i18n.Locale(ctx))
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+	translatorsMu.RLock()
+	defer translatorsMu.RUnlock()
+	if t, ok := translators[locale]; ok {
+		return t
+	}
+	return trans
+}
+
+// GetValidationErrorCtx 同GetValidationError, 使用ctx中的语言区域翻译
+func GetValidationErrorCtx(ctx context.Context, err error) error {
+	errs, ok := err.(validator.ValidationErrors)
+	if !ok || len(errs) == 0 {
+		return err
+	}
+	return errors.New(errs[0].Translate(translatorFor(ctx)))
+}
+
+// GetValidationErrorsCtx 同GetValidationErrors, 使用ctx中的语言区域翻译
+func GetValidationErrorsCtx(ctx context.Context, err error) []error {
+	errs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []error{err}
+	}
+	t := translatorFor(ctx)
+	resErrs := make([]error, 0, len(errs))
+	for _, fe := range errs {
+		resErrs = append(resErrs, errors.New(fe.Translate(t)))
+	}
+	return resErrs
+}
@@ -0,0 +1,65 @@
+package validator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ChangSZ/golib/i18n"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func TestGetValidationErrorCtx(t *testing.T) {
+	type req struct {
+		Name string `binding:"required"`
+	}
+	err := binding.Validator.ValidateStruct(&req{})
+
+	tests := []struct {
+		name   string
+		locale string
+		want   string
+	}{
+		{name: "default zh", locale: "", want: "Name为必填字段"},
+		{name: "en region", locale: "en-US", want: "Name is a required field"},
+		{name: "unsupported falls back", locale: "fr", want: "Name为必填字段"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := i18n.WithLocale(context.Background(), tt.locale)
+			if got := GetValidationErrorCtx(ctx, err); got.Error() != tt.want {
+				t.Errorf("GetValidationErrorCtx() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransInitConcurrent(t *testing.T) {
+	type req struct {
+		Name string `binding:"required"`
+	}
+	err := binding.Validator.ValidateStruct(&req{})
+	ctx := i18n.WithLocale(context.Background(), "zh")
+	translatorsMu.RLock()
+	def, en := trans, translators["en"]
+	translatorsMu.RUnlock()
+	t.Cleanup(func() {
+		translatorsMu.Lock()
+		trans, translators["en"] = def, en
+		translatorsMu.Unlock()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			if err := TransInit(validator.New(), "en"); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		GetValidationErrorCtx(ctx, err)
+	}
+	<-done
+}
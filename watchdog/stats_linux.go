@@ -0,0 +1,35 @@
+//go:build linux
+// +build linux
+
+package watchdog
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readRSS 读取 /proc/self/statm 的第二列(常驻内存页数)
+func readRSS() uint64 {
+	b, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return pages * uint64(os.Getpagesize())
+}
+
+func countFDs() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(entries)
+}
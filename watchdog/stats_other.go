@@ -0,0 +1,17 @@
+//go:build !linux
+// +build !linux
+
+package watchdog
+
+import "runtime"
+
+// readRSS 非linux平台没有统一的读取方式, 使用Go运行时向系统申请的内存近似
+func readRSS() uint64 {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.Sys - m.HeapReleased
+}
+
+func countFDs() int {
+	return -1
+}
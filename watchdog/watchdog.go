@@ -0,0 +1,221 @@
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/ChangSZ/golib/log"
+)
+
+// Kind 告警类型
+type Kind string
+
+const (
+	KindRSS        Kind = "rss"
+	KindGoroutines Kind = "goroutines"
+	KindFDs        Kind = "fds"
+)
+
+// Stats 进程资源采样
+type Stats struct {
+	Time       time.Time
+	RSS        uint64 // 常驻内存(字节), 不支持的平台为0
+	Goroutines int
+	FDs        int // 打开的文件描述符数, 不支持的平台为-1
+}
+
+// Alert 超过阈值时的告警信息
+type Alert struct {
+	Kind      Kind
+	Value     uint64
+	Threshold uint64
+	Stats     Stats
+	DumpFile  string // 生成的pprof文件路径, 未生成时为空
+}
+
+type Config struct {
+	Interval      time.Duration `toml:"interval"`      // 采样间隔, 默认10s
+	MaxRSS        uint64        `toml:"maxRSS"`        // RSS阈值(字节), 0表示不检查
+	MaxGoroutines int           `toml:"maxGoroutines"` // 协程数阈值, 0表示不检查
+	MaxFDs        int           `toml:"maxFDs"`        // 文件描述符阈值, 0表示不检查
+	DumpDir       string        `toml:"dumpDir"`       // 超过阈值时pprof的保存目录, 为空不生成
+	Cooldown      time.Duration `toml:"cooldown"`      // 同一类型告警的最小间隔, 默认10m
+}
+
+// Watchdog 定时采样进程资源, 超过阈值时回调并生成pprof, 用于排查长期运行服务的泄漏
+type Watchdog struct {
+	cfg      Config
+	mu       sync.Mutex
+	handlers []func(Alert)
+	last     map[Kind]time.Time
+	latest   Stats
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// New new a Watchdog.
+func New(cfg Config) *Watchdog {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Second
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 10 * time.Minute
+	}
+	return &Watchdog{
+		cfg:  cfg,
+		last: make(map[Kind]time.Time),
+	}
+}
+
+// OnAlert 注册告警回调, 回调在采样协程中同步执行, 不要阻塞
+func (w *Watchdog) OnAlert(fn func(Alert)) *Watchdog {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers = append(w.handlers, fn)
+	return w
+}
+
+// Start 启动采样协程, ctx结束或调用Stop时退出
+func (w *Watchdog) Start(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		return
+	}
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(w.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			w.Check()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止采样并等待采样协程退出
+func (w *Watchdog) Stop() {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel = nil
+	w.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Latest 最近一次的采样结果
+func (w *Watchdog) Latest() Stats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.latest
+}
+
+// Check 立即采样一次并检查阈值, 返回本次触发的告警
+func (w *Watchdog) Check() []Alert {
+	stats := Sample()
+
+	w.mu.Lock()
+	w.latest = stats
+	handlers := w.handlers
+	w.mu.Unlock()
+
+	var alerts []Alert
+	if w.cfg.MaxRSS > 0 && stats.RSS > w.cfg.MaxRSS {
+		alerts = append(alerts, Alert{Kind: KindRSS, Value: stats.RSS, Threshold: w.cfg.MaxRSS})
+	}
+	if w.cfg.MaxGoroutines > 0 && stats.Goroutines > w.cfg.MaxGoroutines {
+		alerts = append(alerts, Alert{Kind: KindGoroutines, Value: uint64(stats.Goroutines), Threshold: uint64(w.cfg.MaxGoroutines)})
+	}
+	if w.cfg.MaxFDs > 0 && stats.FDs > w.cfg.MaxFDs {
+		alerts = append(alerts, Alert{Kind: KindFDs, Value: uint64(stats.FDs), Threshold: uint64(w.cfg.MaxFDs)})
+	}
+
+	fired := alerts[:0]
+	for _, alert := range alerts {
+		if !w.allow(alert.Kind, stats.Time) {
+			continue
+		}
+		alert.Stats = stats
+		if w.cfg.DumpDir != "" {
+			file, err := w.dump(alert.Kind, stats.Time)
+			if err != nil {
+				log.Errorf("watchdog: dump %s profile err: %v", alert.Kind, err)
+			}
+			alert.DumpFile = file
+		}
+		log.Warnw(
+			"msg", "watchdog: threshold exceeded",
+			"kind", alert.Kind,
+			"value", alert.Value,
+			"threshold", alert.Threshold,
+			"dump", alert.DumpFile,
+		)
+		for _, fn := range handlers {
+			fn(alert)
+		}
+		fired = append(fired, alert)
+	}
+	return fired
+}
+
+// allow 冷却时间内同一类型只告警一次
+func (w *Watchdog) allow(kind Kind, now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if last, ok := w.last[kind]; ok && now.Sub(last) < w.cfg.Cooldown {
+		return false
+	}
+	w.last[kind] = now
+	return true
+}
+
+// dump 内存告警生成heap profile, 其余生成goroutine profile
+func (w *Watchdog) dump(kind Kind, now time.Time) (string, error) {
+	profile := "goroutine"
+	if kind == KindRSS {
+		profile = "heap"
+	}
+	if err := os.MkdirAll(w.cfg.DumpDir, os.ModePerm); err != nil {
+		return "", err
+	}
+	name := filepath.Join(w.cfg.DumpDir, fmt.Sprintf("%s-%s-%d.pprof", profile, now.Format("20060102-150405"), os.Getpid()))
+	f, err := os.Create(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if kind == KindRSS {
+		runtime.GC()
+	}
+	if err := pprof.Lookup(profile).WriteTo(f, 0); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// Sample 采样当前进程资源
+func Sample() Stats {
+	return Stats{
+		Time:       time.Now(),
+		RSS:        readRSS(),
+		Goroutines: runtime.NumGoroutine(),
+		FDs:        countFDs(),
+	}
+}
@@ -0,0 +1,47 @@
+package watchdog
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	var got []Alert
+	w := New(Config{MaxGoroutines: 1, DumpDir: dir}).OnAlert(func(a Alert) {
+		got = append(got, a)
+	})
+
+	alerts := w.Check()
+	if len(alerts) != 1 || alerts[0].Kind != KindGoroutines {
+		t.Fatalf("Check() = %+v, want one goroutines alert", alerts)
+	}
+	if len(got) != 1 {
+		t.Errorf("OnAlert called %d times, want 1", len(got))
+	}
+	if _, err := os.Stat(alerts[0].DumpFile); err != nil {
+		t.Errorf("dump file not found: %v", err)
+	}
+
+	// 冷却时间内不重复告警
+	if alerts := w.Check(); len(alerts) != 0 {
+		t.Errorf("Check() = %+v, want no alert during cooldown", alerts)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	w := New(Config{Interval: 10 * time.Millisecond})
+	w.Start(context.Background())
+	time.Sleep(30 * time.Millisecond)
+	w.Stop()
+
+	s := w.Latest()
+	if s.Time.IsZero() || s.Goroutines == 0 {
+		t.Errorf("Latest() = %+v, want sampled stats", s)
+	}
+	if s.RSS == 0 {
+		t.Errorf("Latest().RSS = 0, want > 0")
+	}
+}
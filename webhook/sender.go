@@ -0,0 +1,189 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ChangSZ/golib/meta"
+)
+
+type Config struct {
+	MaxAttempts int           `toml:"maxAttempts"` // 总尝试次数, 默认5
+	Backoff     time.Duration `toml:"backoff"`     // 首次重试间隔, 之后每次翻倍, 默认1s
+	MaxBackoff  time.Duration `toml:"maxBackoff"`  // 最大重试间隔, 默认1m
+	Timeout     time.Duration `toml:"timeout"`     // 单次请求超时, 默认10s
+}
+
+// Attempt 一次投递尝试的记录
+type Attempt struct {
+	ID         string
+	URL        string
+	Event      string
+	Number     int // 从1开始
+	StatusCode int // 未收到响应时为0
+	Duration   time.Duration
+	Err        error
+	Retry      bool // 是否还会重试
+}
+
+// DeliveryError 最终投递失败
+type DeliveryError struct {
+	ID         string
+	Attempts   int
+	StatusCode int
+	Err        error
+}
+
+func (e *DeliveryError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("webhook: delivery %s failed after %d attempts: %v", e.ID, e.Attempts, e.Err)
+	}
+	return fmt.Sprintf("webhook: delivery %s failed after %d attempts: status %d", e.ID, e.Attempts, e.StatusCode)
+}
+
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// Option Sender的可选参数
+type Option func(*Sender)
+
+// WithClient 设置http.Client, 默认http.DefaultClient
+func WithClient(c *http.Client) Option {
+	return func(s *Sender) {
+		s.client = c
+	}
+}
+
+// WithAttemptLog 每次尝试后回调, 用于记录投递日志
+func WithAttemptLog(fn func(Attempt)) Option {
+	return func(s *Sender) {
+		s.onAttempt = fn
+	}
+}
+
+// Sender 签名并投递webhook, 网络错误、5xx和429会按指数退避重试, 其他4xx不重试
+type Sender struct {
+	secret    []byte
+	cfg       Config
+	client    *http.Client
+	onAttempt func(Attempt)
+	now       func() time.Time
+	sleep     func(ctx context.Context, d time.Duration) error
+}
+
+// New new a Sender.
+func New(secret []byte, cfg Config, opts ...Option) *Sender {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = time.Minute
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	s := &Sender{
+		secret: secret,
+		cfg:    cfg,
+		client: http.DefaultClient,
+		now:    time.Now,
+		sleep:  sleep,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Deliver 以POST投递body, 返回投递ID; 全部尝试失败时返回*DeliveryError
+func (s *Sender) Deliver(ctx context.Context, url, event string, body []byte) (string, error) {
+	id := meta.NewRequestID()
+	backoff := s.cfg.Backoff
+	for n := 1; ; n++ {
+		start := s.now()
+		status, retryAfter, err := s.send(ctx, id, url, event, body)
+		ok := err == nil && status >= 200 && status < 300
+		retry := !ok && n < s.cfg.MaxAttempts && retryable(status, err) && ctx.Err() == nil
+		if s.onAttempt != nil {
+			s.onAttempt(Attempt{
+				ID:         id,
+				URL:        url,
+				Event:      event,
+				Number:     n,
+				StatusCode: status,
+				Duration:   s.now().Sub(start),
+				Err:        err,
+				Retry:      retry,
+			})
+		}
+		if ok {
+			return id, nil
+		}
+		if !retry {
+			return id, &DeliveryError{ID: id, Attempts: n, StatusCode: status, Err: err}
+		}
+
+		wait := backoff
+		if retryAfter > wait {
+			wait = min(retryAfter, s.cfg.MaxBackoff)
+		}
+		if err := s.sleep(ctx, wait); err != nil {
+			return id, &DeliveryError{ID: id, Attempts: n, StatusCode: status, Err: err}
+		}
+		backoff = min(backoff*2, s.cfg.MaxBackoff)
+	}
+}
+
+func (s *Sender) send(ctx context.Context, id, url, event string, body []byte) (status int, retryAfter time.Duration, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, id)
+	if event != "" {
+		req.Header.Set(HeaderEvent, event)
+	}
+	req.Header.Set(HeaderSignature, Sign(s.secret, s.now(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+		retryAfter = time.Duration(secs) * time.Second
+	}
+	return resp.StatusCode, retryAfter, nil
+}
+
+// retryable 网络错误、5xx、408和429可以重试
+func retryable(status int, err error) bool {
+	if err != nil {
+		return true
+	}
+	return status >= 500 || status == http.StatusTooManyRequests || status == http.StatusRequestTimeout
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 请求头
+const (
+	HeaderSignature = "X-Webhook-Signature" // t=<unix时间>,v1=<hex签名>
+	HeaderID        = "X-Webhook-ID"        // 投递ID, 重试时不变, 接收方可据此去重
+	HeaderEvent     = "X-Webhook-Event"
+)
+
+var (
+	// ErrNoSignature 缺少签名头或格式错误
+	ErrNoSignature = errors.New("webhook: missing signature")
+	// ErrInvalidSignature 签名不匹配
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	// ErrTimestamp 签名时间超出容忍范围, 可能是重放
+	ErrTimestamp = errors.New("webhook: timestamp outside tolerance")
+)
+
+// Sign 计算签名头的值, 签名内容为"时间戳.请求体"
+func Sign(secret []byte, ts time.Time, body []byte) string {
+	t := strconv.FormatInt(ts.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(secret, t, body))
+}
+
+func mac(secret []byte, t string, body []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(t))
+	h.Write([]byte{'.'})
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// Verify 校验签名头, 任一secret匹配即通过(用于密钥轮换), tolerance>0时拒绝时间偏差超过tolerance的请求
+func Verify(header string, body []byte, tolerance time.Duration, secrets ...[]byte) error {
+	return verify(header, body, tolerance, time.Now(), secrets)
+}
+
+func verify(header string, body []byte, tolerance time.Duration, now time.Time, secrets [][]byte) error {
+	var t string
+	var sigs [][]byte
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			t = v
+		case "v1":
+			if b, err := hex.DecodeString(v); err == nil {
+				sigs = append(sigs, b)
+			}
+		}
+	}
+	ts, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return ErrNoSignature
+	}
+	if tolerance > 0 {
+		if d := now.Sub(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+			return ErrTimestamp
+		}
+	}
+	for _, secret := range secrets {
+		want := mac(secret, t, body)
+		for _, sig := range sigs {
+			if hmac.Equal(sig, want) {
+				return nil
+			}
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// VerifyRequest 校验接收到的请求并返回请求体, 请求体会被重置以便后续继续读取
+func VerifyRequest(r *http.Request, tolerance time.Duration, secrets ...[]byte) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err := Verify(r.Header.Get(HeaderSignature), body, tolerance, secrets...); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
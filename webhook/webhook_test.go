@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChangSZ/golib/testingx/httpmock"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":1}`)
+	header := Sign([]byte("k1"), now, body)
+
+	tests := []struct {
+		name    string
+		header  string
+		body    string
+		now     time.Time
+		secrets [][]byte
+		wantErr error
+	}{
+		{name: "ok", header: header, body: `{"id":1}`, now: now, secrets: [][]byte{[]byte("k1")}},
+		{name: "rotated", header: header, body: `{"id":1}`, now: now, secrets: [][]byte{[]byte("k2"), []byte("k1")}},
+		{name: "tampered body", header: header, body: `{"id":2}`, now: now, secrets: [][]byte{[]byte("k1")}, wantErr: ErrInvalidSignature},
+		{name: "wrong secret", header: header, body: `{"id":1}`, now: now, secrets: [][]byte{[]byte("k2")}, wantErr: ErrInvalidSignature},
+		{name: "replayed", header: header, body: `{"id":1}`, now: now.Add(10 * time.Minute), secrets: [][]byte{[]byte("k1")}, wantErr: ErrTimestamp},
+		{name: "missing", header: "", body: `{"id":1}`, now: now, secrets: [][]byte{[]byte("k1")}, wantErr: ErrNoSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verify(tt.header, []byte(tt.body), 5*time.Minute, tt.now, tt.secrets)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "first try", statuses: []int{200}, wantAttempts: 1},
+		{name: "retry 5xx then ok", statuses: []int{503, 500, 204}, wantAttempts: 3},
+		{name: "4xx not retried", statuses: []int{400}, wantAttempts: 1, wantErr: true},
+		{name: "give up", statuses: []int{500}, wantAttempts: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := httpmock.New()
+			route := m.On("POST", "/hook")
+			for _, s := range tt.statuses {
+				route.Reply(s, "")
+			}
+			var attempts []Attempt
+			var waits []time.Duration
+			s := New([]byte("secret"), Config{MaxAttempts: 3, Backoff: time.Second},
+				WithClient(m.Client()),
+				WithAttemptLog(func(a Attempt) { attempts = append(attempts, a) }))
+			s.sleep = func(ctx context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+
+			id, err := s.Deliver(context.Background(), "http://hooks.local/hook", "order.paid", []byte(`{"id":1}`))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Deliver() error = %v", err)
+			}
+			var de *DeliveryError
+			if tt.wantErr && (!errors.As(err, &de) || de.Attempts != tt.wantAttempts) {
+				t.Errorf("Deliver() error = %#v", err)
+			}
+			if len(attempts) != tt.wantAttempts || attempts[len(attempts)-1].Retry {
+				t.Errorf("attempts = %+v", attempts)
+			}
+			for i, w := range waits {
+				if want := time.Second << i; w != want {
+					t.Errorf("wait %d = %v, want %v", i, w, want)
+				}
+			}
+
+			for _, req := range m.Requests() {
+				if req.Header.Get(HeaderID) != id || req.Header.Get(HeaderEvent) != "order.paid" {
+					t.Errorf("headers = %v", req.Header)
+				}
+				if err := Verify(req.Header.Get(HeaderSignature), req.Body, time.Minute, []byte("secret")); err != nil {
+					t.Errorf("Verify() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	body := `{"id":1}`
+	r := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+	r.Header.Set(HeaderSignature, Sign([]byte("k"), time.Now(), []byte(body)))
+	got, err := VerifyRequest(r, time.Minute, []byte("k"))
+	if err != nil || string(got) != body {
+		t.Fatalf("VerifyRequest() = %s, %v", got, err)
+	}
+	again := make([]byte, len(body))
+	if n, _ := r.Body.Read(again); string(again[:n]) != body {
+		t.Errorf("body not restored")
+	}
+}
@@ -0,0 +1,151 @@
+package wsutil
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// close帧的内容: 2字节状态码
+var (
+	closeNormal    = []byte{0x03, 0xe8} // 1000 Normal Closure
+	closeGoingAway = []byte{0x03, 0xe9} // 1001 Going Away
+)
+
+type message struct {
+	typ  int
+	data []byte
+}
+
+// Client Hub中的一个连接
+type Client struct {
+	id      string
+	hub     *Hub
+	conn    Conn
+	send    chan message
+	done    chan struct{}
+	once    sync.Once
+	reason  []byte
+	dropped atomic.Int64
+	rooms   map[string]bool // 由hub.mu保护
+}
+
+// ID 连接ID, Hub内唯一
+func (c *Client) ID() string {
+	return c.id
+}
+
+// Join 加入房间
+func (c *Client) Join(room string) {
+	h := c.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	c.rooms[room] = true
+	if h.rooms[room] == nil {
+		h.rooms[room] = make(map[*Client]struct{})
+	}
+	h.rooms[room][c] = struct{}{}
+}
+
+// Leave 离开房间
+func (c *Client) Leave(room string) {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	c.hub.leave(c, room)
+}
+
+// Rooms 已加入的房间
+func (c *Client) Rooms() []string {
+	c.hub.mu.RLock()
+	defer c.hub.mu.RUnlock()
+	rooms := make([]string, 0, len(c.rooms))
+	for room := range c.rooms {
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
+
+// Send 将消息放入发送队列, 不等待写入完成; 队列满时按SlowPolicy丢弃消息或断开连接
+func (c *Client) Send(messageType int, data []byte) error {
+	select {
+	case <-c.done:
+		return ErrClosed
+	default:
+	}
+	select {
+	case c.send <- message{typ: messageType, data: data}:
+		return nil
+	default:
+	}
+	c.dropped.Add(1)
+	if c.hub.cfg.SlowPolicy == SlowDisconnect {
+		// 写goroutine可能阻塞在慢客户端上, 直接关闭连接而不是等待队列发送完
+		c.closeWith(nil)
+		_ = c.conn.Close()
+		return ErrClosed
+	}
+	return ErrQueueFull
+}
+
+// Dropped 因发送队列满而丢弃的消息数
+func (c *Client) Dropped() int64 {
+	return c.dropped.Load()
+}
+
+// Close 发送完队列中的消息后关闭连接, 可以重复调用
+func (c *Client) Close() {
+	c.closeWith(closeNormal)
+}
+
+func (c *Client) closeWith(reason []byte) {
+	c.once.Do(func() {
+		c.reason = reason
+		close(c.done)
+	})
+}
+
+// writeLoop 连接上唯一的写goroutine, 负责发送消息和ping, 关闭时发送close帧并关闭连接
+func (c *Client) writeLoop() {
+	ping := time.NewTicker(c.hub.cfg.PingInterval)
+	defer ping.Stop()
+	defer c.conn.Close()
+
+	for {
+		select {
+		case m := <-c.send:
+			if c.write(m.typ, m.data) != nil {
+				c.Close()
+				return
+			}
+		case <-ping.C:
+			if c.write(PingMessage, nil) != nil {
+				c.Close()
+				return
+			}
+		case <-c.done:
+			for {
+				select {
+				case m := <-c.send:
+					if c.write(m.typ, m.data) != nil {
+						return
+					}
+					continue
+				default:
+				}
+				break
+			}
+			if c.reason != nil {
+				_ = c.write(CloseMessage, c.reason)
+			}
+			return
+		}
+	}
+}
+
+func (c *Client) write(messageType int, data []byte) error {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.hub.cfg.WriteTimeout))
+	return c.conn.WriteMessage(messageType, data)
+}
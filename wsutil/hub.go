@@ -0,0 +1,230 @@
+package wsutil
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 消息类型, 与RFC 6455及gorilla/websocket的取值一致
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+var (
+	// ErrClosed 连接或Hub已关闭
+	ErrClosed = errors.New("wsutil: closed")
+	// ErrQueueFull 发送队列已满, 消息被丢弃(SlowDrop)
+	ErrQueueFull = errors.New("wsutil: send queue full")
+)
+
+// Conn WebSocket连接, *websocket.Conn(gorilla)可以直接使用, 其他实现需要简单适配
+//
+// Hub保证同一时刻最多一个goroutine读、一个goroutine写; Close可能与读写并发调用, 用于断开慢客户端
+type Conn interface {
+	ReadMessage() (messageType int, p []byte, err error)
+	WriteMessage(messageType int, data []byte) error
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+	SetPongHandler(h func(appData string) error)
+	Close() error
+}
+
+// SlowPolicy 发送队列满时的处理方式
+type SlowPolicy int
+
+const (
+	// SlowDisconnect 断开连接, 由客户端重连后重新同步状态, 适用于消息不能丢失的场景
+	SlowDisconnect SlowPolicy = iota
+	// SlowDrop 丢弃新消息, 适用于行情、位置等只关心最新值的场景
+	SlowDrop
+)
+
+// Config Hub配置
+type Config struct {
+	SendQueue    int           `toml:"sendQueue"`    // 每个连接的发送队列长度, 默认64
+	SlowPolicy   SlowPolicy    `toml:"slowPolicy"`   // 发送队列满时的处理方式, 默认断开连接
+	PingInterval time.Duration `toml:"pingInterval"` // 发送ping的间隔, 默认30s
+	PongWait     time.Duration `toml:"pongWait"`     // 多久没有收到任何消息(含pong)时断开, 默认PingInterval的2倍
+	WriteTimeout time.Duration `toml:"writeTimeout"` // 单条消息的写超时, 默认10s
+}
+
+// Handler 处理客户端发来的消息, 在连接的读goroutine中调用, 耗时操作需自行异步处理
+type Handler func(c *Client, messageType int, data []byte)
+
+// Hub 管理WebSocket连接和房间:
+//
+//   - 每个连接一个读goroutine和一个写goroutine, 所有写操作经由有界的发送队列, 慢客户端不会阻塞广播
+//   - 定时发送ping, 超过PongWait没有收到消息时断开
+//   - Shutdown时发送close帧并等待所有连接退出
+//
+//	hub := wsutil.New(wsutil.Config{})
+//	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+//		conn, err := upgrader.Upgrade(w, r, nil)
+//		if err != nil {
+//			return
+//		}
+//		hub.Serve(conn, func(c *wsutil.Client, _ int, data []byte) {
+//			c.Join(string(data))
+//		})
+//	})
+//	hub.Broadcast("room:1", wsutil.TextMessage, []byte("hello"))
+type Hub struct {
+	cfg Config
+	seq atomic.Uint64
+	wg  sync.WaitGroup
+
+	mu      sync.RWMutex
+	clients map[*Client]struct{}
+	rooms   map[string]map[*Client]struct{}
+	closed  bool
+}
+
+// New new a Hub.
+func New(cfg Config) *Hub {
+	if cfg.SendQueue <= 0 {
+		cfg.SendQueue = 64
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = 30 * time.Second
+	}
+	if cfg.PongWait <= cfg.PingInterval {
+		cfg.PongWait = 2 * cfg.PingInterval
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 10 * time.Second
+	}
+	return &Hub{
+		cfg:     cfg,
+		clients: make(map[*Client]struct{}),
+		rooms:   make(map[string]map[*Client]struct{}),
+	}
+}
+
+// Serve 接管连接, 阻塞至连接断开; onMessage可以为nil
+func (h *Hub) Serve(conn Conn, onMessage Handler) {
+	c := &Client{
+		id:    strconv.FormatUint(h.seq.Add(1), 10),
+		hub:   h,
+		conn:  conn,
+		send:  make(chan message, h.cfg.SendQueue),
+		done:  make(chan struct{}),
+		rooms: make(map[string]bool),
+	}
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		_ = conn.Close()
+		return
+	}
+	h.clients[c] = struct{}{}
+	h.wg.Add(1)
+	h.mu.Unlock()
+	defer h.wg.Done()
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		c.writeLoop()
+	}()
+
+	_ = conn.SetReadDeadline(time.Now().Add(h.cfg.PongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(h.cfg.PongWait))
+	})
+	for {
+		mt, data, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(h.cfg.PongWait))
+		if onMessage != nil {
+			onMessage(c, mt, data)
+		}
+	}
+
+	c.Close()
+	<-written
+	h.remove(c)
+}
+
+// remove 移除连接及其房间
+func (h *Hub) remove(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients, c)
+	for room := range c.rooms {
+		h.leave(c, room)
+	}
+}
+
+// leave 调用方需持有锁
+func (h *Hub) leave(c *Client, room string) {
+	delete(c.rooms, room)
+	if members := h.rooms[room]; members != nil {
+		delete(members, c)
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+}
+
+// Broadcast 向房间中的所有连接发送消息, room为空时发送给所有连接; 返回成功入队的连接数
+func (h *Hub) Broadcast(room string, messageType int, data []byte) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	members := h.clients
+	if room != "" {
+		members = h.rooms[room]
+	}
+	n := 0
+	for c := range members {
+		if c.Send(messageType, data) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Len 当前连接数
+func (h *Hub) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// RoomLen 房间中的连接数
+func (h *Hub) RoomLen(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Shutdown 拒绝新连接, 发送完队列中的消息后向所有连接发送close帧, 等待连接退出或ctx结束
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	for c := range h.clients {
+		c.closeWith(closeGoingAway)
+	}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
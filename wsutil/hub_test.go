@@ -0,0 +1,157 @@
+package wsutil
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeConn 内存连接, in模拟客户端发来的消息, written记录写出的消息
+type fakeConn struct {
+	in      chan []byte
+	closed  chan struct{}
+	once    sync.Once
+	block   chan struct{} // 非nil时写入阻塞直到关闭, 模拟慢客户端
+	mu      sync.Mutex
+	written []message
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{in: make(chan []byte), closed: make(chan struct{})}
+}
+
+func (c *fakeConn) ReadMessage() (int, []byte, error) {
+	select {
+	case b := <-c.in:
+		return TextMessage, b, nil
+	case <-c.closed:
+		return 0, nil, errors.New("closed")
+	}
+}
+
+func (c *fakeConn) WriteMessage(messageType int, data []byte) error {
+	if c.block != nil {
+		select {
+		case <-c.block:
+		case <-c.closed:
+			return errors.New("closed")
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.written = append(c.written, message{typ: messageType, data: data})
+	return nil
+}
+
+func (c *fakeConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *fakeConn) SetWriteDeadline(time.Time) error { return nil }
+func (c *fakeConn) SetPongHandler(func(string) error) {}
+
+func (c *fakeConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *fakeConn) messages() []message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]message(nil), c.written...)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHub(t *testing.T) {
+	hub := New(Config{})
+	join := func(c *Client, _ int, data []byte) { c.Join(string(data)) }
+	a, b := newFakeConn(), newFakeConn()
+	go hub.Serve(a, join)
+	go hub.Serve(b, join)
+	a.in <- []byte("room1")
+	b.in <- []byte("room2")
+	waitFor(t, func() bool { return hub.RoomLen("room1") == 1 && hub.RoomLen("room2") == 1 })
+
+	tests := []struct {
+		room  string
+		want  int
+		wantA int
+		wantB int
+	}{
+		{room: "room1", want: 1, wantA: 1, wantB: 0},
+		{room: "", want: 2, wantA: 2, wantB: 1},
+		{room: "missing", want: 0, wantA: 2, wantB: 1},
+	}
+	for _, tt := range tests {
+		if n := hub.Broadcast(tt.room, TextMessage, []byte("hi")); n != tt.want {
+			t.Errorf("Broadcast(%q) = %d, want %d", tt.room, n, tt.want)
+		}
+		waitFor(t, func() bool { return len(a.messages()) == tt.wantA && len(b.messages()) == tt.wantB })
+	}
+
+	_ = b.Close()
+	waitFor(t, func() bool { return hub.Len() == 1 && hub.RoomLen("room2") == 0 })
+
+	if err := hub.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	msgs := a.messages()
+	if last := msgs[len(msgs)-1]; last.typ != CloseMessage || string(last.data) != string(closeGoingAway) {
+		t.Errorf("last message = %+v, want close going away", last)
+	}
+	if hub.Len() != 0 {
+		t.Errorf("Len() = %d after shutdown", hub.Len())
+	}
+}
+
+func TestSlowPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     SlowPolicy
+		wantErr    error
+		wantClosed bool
+	}{
+		{name: "drop", policy: SlowDrop, wantErr: ErrQueueFull},
+		{name: "disconnect", policy: SlowDisconnect, wantErr: ErrClosed, wantClosed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := New(Config{SendQueue: 1, SlowPolicy: tt.policy})
+			conn := newFakeConn()
+			conn.block = make(chan struct{})
+			var client *Client
+			ready := make(chan struct{})
+			go hub.Serve(conn, func(c *Client, _ int, _ []byte) {
+				client = c
+				close(ready)
+			})
+			conn.in <- nil
+			<-ready
+
+			// 第一条被写goroutine取走并阻塞, 第二条占满队列
+			_ = client.Send(TextMessage, []byte("1"))
+			waitFor(t, func() bool { return len(client.send) == 0 })
+			_ = client.Send(TextMessage, []byte("2"))
+			if err := client.Send(TextMessage, []byte("3")); err != tt.wantErr {
+				t.Errorf("Send() = %v, want %v", err, tt.wantErr)
+			}
+			if client.Dropped() != 1 {
+				t.Errorf("Dropped() = %d", client.Dropped())
+			}
+			if tt.wantClosed {
+				waitFor(t, func() bool { return hub.Len() == 0 })
+			}
+			_ = conn.Close()
+			_ = hub.Shutdown(context.Background())
+		})
+	}
+}
@@ -2,12 +2,12 @@ package zip
 
 import (
 	"archive/zip"
-	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/ChangSZ/golib/bufpool"
 	"github.com/ChangSZ/golib/file"
 	"github.com/ChangSZ/golib/log"
 )
@@ -59,7 +59,7 @@ func compressDir(dir string, archive *zip.Writer) error {
 				return err
 			}
 			defer srcFile.Close()
-			io.Copy(writer, srcFile)
+			bufpool.Copy(writer, srcFile)
 		}
 		return nil
 	})